@@ -15,9 +15,11 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/galaxyerp/galaxyErp/internal/container"
+	"github.com/galaxyerp/galaxyErp/internal/dto"
 	"github.com/galaxyerp/galaxyErp/internal/middleware"
 	"github.com/galaxyerp/galaxyErp/internal/models"
 	"github.com/galaxyerp/galaxyErp/internal/routes"
+	"github.com/galaxyerp/galaxyErp/internal/services"
 
 	"github.com/galaxyerp/galaxyErp/internal/utils"
 )
@@ -82,25 +84,57 @@ func main() {
 		&models.CostCenter{},
 		&models.BankAccount{},
 		&models.PaymentEntry{},
+		&models.PaymentAllocation{},
 		&models.Budget{},
 		&models.ExchangeRateHistory{},
 		&models.TaxTemplate{},
 		&models.FiscalYear{},
 		&models.AccountingPeriod{},
+		&models.IntercompanyTransaction{},
 		&models.Product{},
+		&models.UOMCategory{},
+		&models.UOM{},
 		&models.Item{},
+		&models.ItemUOMConversion{},
+		&models.ItemAttribute{},
+		&models.ItemAttributeValue{},
+		&models.ItemTemplateAttribute{},
+		&models.ItemVariantAttribute{},
 		&models.Warehouse{},
 		&models.Location{},
 		&models.Stock{},
 		&models.StockMovement{},
 		&models.StockTransfer{},
+		&models.StockTransferItem{},
+		&models.StockCostLayer{},
+		&models.Batch{},
+		&models.BatchStock{},
+		&models.SerialNumber{},
+		&models.MovementSerialNo{},
+		&models.LocationStock{},
+		&models.PutawayRule{},
+		&models.LocationMove{},
+		&models.StockReservation{},
+		&models.StockCount{},
+		&models.StockCountItem{},
+		&models.LandedCostVoucher{},
+		&models.LandedCostReceipt{},
+		&models.LandedCostCharge{},
+		&models.LandedCostItem{},
+		&models.ItemBarcode{},
+		&models.PriceList{},
+		&models.ItemPrice{},
+		&models.PriceListAssignment{},
 		&models.Customer{},
 		&models.Quotation{},
 		&models.QuotationItem{},
 		&models.SalesOrder{},
 		&models.SalesOrderItem{},
+		&models.SalesOrderStatusLog{},
 		&models.DeliveryNote{},
 		&models.DeliveryNoteItem{},
+		&models.DunningLevel{},
+		&models.DunningLetter{},
 		&models.Supplier{},
 		&models.PurchaseRequest{},
 		&models.PurchaseRequestItem{},
@@ -146,6 +180,56 @@ func main() {
 	// 初始化依赖注入容器
 	appContainer := container.NewContainer(utils.GetDB(), viper.GetString("jwt.secret"), viper.GetInt("jwt.expiry"))
 
+	// 确保存在默认公司，未指定公司的科目、凭证与单据归属于默认公司
+	if err := appContainer.CompanyService.EnsureDefaultCompany(context.Background()); err != nil {
+		zap.L().Fatal("Failed to ensure default company", zap.Error(err))
+	}
+
+	// 为启用库存台账前已存在的库存补录期初移动，使台账余额与库存余额一致
+	if _, err := appContainer.StockService.EnsureOpeningBalances(context.Background()); err != nil {
+		zap.L().Fatal("Failed to ensure opening stock balances", zap.Error(err))
+	}
+
+	// 启动定时催款任务
+	var dunningScheduler *services.PeriodicJob
+	if viper.GetBool("dunning.enabled") {
+		dunningScheduler = services.NewDunningScheduler(appContainer.DunningService, viper.GetDuration("dunning.interval"))
+		dunningScheduler.Start()
+	}
+
+	// 启动库存对账任务
+	var stockReconcileScheduler *services.PeriodicJob
+	if viper.GetBool("stock_reconcile.enabled") {
+		stockReconcileScheduler = services.NewStockReconcileScheduler(appContainer.StockService, viper.GetDuration("stock_reconcile.interval"), viper.GetBool("stock_reconcile.auto_fix"))
+		stockReconcileScheduler.Start()
+	}
+
+	// 启动补货任务
+	var replenishmentScheduler *services.PeriodicJob
+	if viper.GetBool("replenishment.enabled") {
+		replenishmentScheduler = services.NewReplenishmentScheduler(appContainer.ReplenishmentService, viper.GetDuration("replenishment.interval"))
+		replenishmentScheduler.Start()
+	}
+
+	// 启动循环盘点任务
+	var cycleCountScheduler *services.PeriodicJob
+	if viper.GetBool("cycle_count.enabled") {
+		cycleCountScheduler = services.NewCycleCountScheduler(appContainer.StockCountService, viper.GetDuration("cycle_count.interval"), dto.CycleCountRequest{
+			ADays:    viper.GetInt("cycle_count.a_days"),
+			BDays:    viper.GetInt("cycle_count.b_days"),
+			CDays:    viper.GetInt("cycle_count.c_days"),
+			MaxItems: viper.GetInt("cycle_count.max_items"),
+		})
+		cycleCountScheduler.Start()
+	}
+
+	// 启动过期批次隔离任务
+	var expiryQuarantineScheduler *services.PeriodicJob
+	if viper.GetBool("expiry_quarantine.enabled") {
+		expiryQuarantineScheduler = services.NewExpiryQuarantineScheduler(appContainer.LocationService, viper.GetDuration("expiry_quarantine.interval"))
+		expiryQuarantineScheduler.Start()
+	}
+
 	// Create server
 	r := gin.Default()
 
@@ -180,6 +264,22 @@ func main() {
 
 	zap.L().Info("Shutting down server...")
 
+	if dunningScheduler != nil {
+		dunningScheduler.Stop()
+	}
+	if stockReconcileScheduler != nil {
+		stockReconcileScheduler.Stop()
+	}
+	if replenishmentScheduler != nil {
+		replenishmentScheduler.Stop()
+	}
+	if cycleCountScheduler != nil {
+		cycleCountScheduler.Stop()
+	}
+	if expiryQuarantineScheduler != nil {
+		expiryQuarantineScheduler.Stop()
+	}
+
 	// The context is used to inform the server it has 5 seconds to finish
 	// the request it is currently handling
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
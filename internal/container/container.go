@@ -20,6 +20,20 @@ type Container struct {
 	StockRepository        repositories.StockRepository
 	WarehouseRepository    repositories.WarehouseRepository
 	MovementRepository     repositories.MovementRepository
+	StockLedgerRepository  repositories.StockLedgerRepository
+	StockTransferRepository repositories.StockTransferRepository
+	StockValuationRepository repositories.StockValuationRepository
+	InventoryReportRepository repositories.InventoryReportRepository
+	BatchRepository        repositories.BatchRepository
+	LocationRepository     repositories.LocationRepository
+	ReplenishmentRepository repositories.ReplenishmentRepository
+	ReservationRepository  repositories.ReservationRepository
+	StockCountRepository   repositories.StockCountRepository
+	UOMRepository          repositories.UOMRepository
+	ItemVariantRepository  repositories.ItemVariantRepository
+	LandedCostRepository   repositories.LandedCostRepository
+	BarcodeRepository      repositories.BarcodeRepository
+	PriceListRepository    repositories.PriceListRepository
 	CustomerRepository     repositories.CustomerRepository
 	SalesOrderRepository   repositories.SalesOrderRepository
 	QuotationRepository    repositories.QuotationRepository
@@ -30,10 +44,13 @@ type Container struct {
 	TaskRepository         repositories.TaskRepository
 	EmployeeRepository     repositories.EmployeeRepository
 	AccountRepository      repositories.AccountRepository
+	CompanyRepository      repositories.CompanyRepository
+	IntercompanyRepository repositories.IntercompanyRepository
 	AuditLogRepository     repositories.AuditLogRepository
 	ProductRepository      repositories.ProductRepository
 	SalesInvoiceRepository repositories.SalesInvoiceRepository
 	DeliveryNoteRepository repositories.DeliveryNoteRepository
+	DunningRepository      repositories.DunningRepository
 
 	// Service Interfaces (服务层接口)
 	AuditLogService          services.AuditLogService
@@ -42,6 +59,19 @@ type Container struct {
 	StockService             services.StockService
 	WarehouseService         services.WarehouseService
 	MovementService          services.MovementService
+	StockTransferService     services.StockTransferService
+	StockValuationService    services.StockValuationService
+	InventoryReportService   services.InventoryReportService
+	BatchTrackingService     services.BatchTrackingService
+	LocationService          services.LocationService
+	ReplenishmentService     services.ReplenishmentService
+	ReservationService       services.ReservationService
+	StockCountService        services.StockCountService
+	UOMService               services.UOMService
+	ItemAttributeService     services.ItemAttributeService
+	LandedCostService        services.LandedCostService
+	BarcodeService           services.BarcodeService
+	PriceListService         services.PriceListService
 	CustomerService          services.CustomerService
 	SalesOrderService        services.SalesOrderService
 	QuotationService         services.QuotationService
@@ -49,6 +79,7 @@ type Container struct {
 	QuotationVersionService  services.QuotationVersionService
 	SalesInvoiceService      services.SalesInvoiceService
 	DeliveryNoteService      services.DeliveryNoteServiceInterface
+	DunningService           services.DunningService
 	ProductService           services.ProductService
 
 	// Purchase Services
@@ -72,6 +103,10 @@ type Container struct {
 	AccountService      services.AccountService
 	JournalEntryService services.JournalEntryService
 	PaymentEntryService services.PaymentEntryService
+	IntercompanyService services.IntercompanyService
+
+	// System Services
+	CompanyService services.CompanyService
 
 	// Handlers
 	AuditLogHandler *handlers.AuditLogHandler
@@ -79,13 +114,29 @@ type Container struct {
 	// Controllers
 	UserController         *controllers.UserController
 	InventoryController    *controllers.InventoryController
+	StockTransferController *controllers.StockTransferController
+	StockValuationController *controllers.StockValuationController
+	BatchController        *controllers.BatchController
+	LocationController     *controllers.LocationController
+	ReplenishmentController *controllers.ReplenishmentController
+	ReservationController  *controllers.ReservationController
+	StockCountController   *controllers.StockCountController
+	UOMController          *controllers.UOMController
+	ItemVariantController  *controllers.ItemVariantController
+	LandedCostController   *controllers.LandedCostController
+	BarcodeController      *controllers.BarcodeController
+	PriceListController    *controllers.PriceListController
 	SalesController        *controllers.SalesController
 	DeliveryNoteController *controllers.DeliveryNoteController
+	DunningController      *controllers.DunningController
 	ProductionController   *controllers.ProductionController
 	SystemController       *controllers.SystemController
+	CompanyController      *controllers.CompanyController
 	PurchaseController     *controllers.PurchaseController
 	ProjectController      *controllers.ProjectController
 	AccountingController   *controllers.AccountingController
+	IntercompanyController *controllers.IntercompanyController
+	PaymentEntryController *controllers.PaymentEntryController
 	HRController           *controllers.HRController
 }
 
@@ -115,11 +166,26 @@ func (c *Container) initRepositories() {
 	c.StockRepository = repositories.NewStockRepository(c.DB)
 	c.WarehouseRepository = repositories.NewWarehouseRepository(c.DB)
 	c.MovementRepository = repositories.NewMovementRepository(c.DB)
+	c.StockLedgerRepository = repositories.NewStockLedgerRepository(c.DB)
+	c.StockTransferRepository = repositories.NewStockTransferRepository(c.DB)
+	c.StockValuationRepository = repositories.NewStockValuationRepository(c.DB)
+	c.InventoryReportRepository = repositories.NewInventoryReportRepository(c.DB)
+	c.BatchRepository = repositories.NewBatchRepository(c.DB)
+	c.LocationRepository = repositories.NewLocationRepository(c.DB)
+	c.ReplenishmentRepository = repositories.NewReplenishmentRepository(c.DB)
+	c.ReservationRepository = repositories.NewReservationRepository(c.DB)
+	c.StockCountRepository = repositories.NewStockCountRepository(c.DB)
+	c.UOMRepository = repositories.NewUOMRepository(c.DB)
+	c.ItemVariantRepository = repositories.NewItemVariantRepository(c.DB)
+	c.LandedCostRepository = repositories.NewLandedCostRepository(c.DB)
+	c.BarcodeRepository = repositories.NewBarcodeRepository(c.DB)
+	c.PriceListRepository = repositories.NewPriceListRepository(c.DB)
 	c.CustomerRepository = repositories.NewCustomerRepository(c.DB)
 	c.SalesOrderRepository = repositories.NewSalesOrderRepository(c.DB)
 	c.QuotationRepository = repositories.NewQuotationRepository(c.DB)
 	c.SalesInvoiceRepository = repositories.NewSalesInvoiceRepository(c.DB)
 	c.DeliveryNoteRepository = repositories.NewDeliveryNoteRepository(c.DB)
+	c.DunningRepository = repositories.NewDunningRepository(c.DB)
 	c.ProductRepository = repositories.NewProductRepository(c.DB)
 
 	// Purchase repositories
@@ -136,6 +202,8 @@ func (c *Container) initRepositories() {
 
 	// Accounting repositories
 	c.AccountRepository = repositories.NewAccountRepository(c.DB)
+	c.CompanyRepository = repositories.NewCompanyRepository(c.DB)
+	c.IntercompanyRepository = repositories.NewIntercompanyRepository(c.DB)
 
 	// Audit log repository
 	c.AuditLogRepository = repositories.NewAuditLogRepository(c.DB)
@@ -159,30 +227,46 @@ func (c *Container) initServices(jwtSecret string, jwtExpiryHours int) {
 
 	// 初始化服务（使用容器中的仓储接口）
 	c.UserService = services.NewUserService(c.UserRepository, c.AuditLogService, jwtSecret, jwtExpiryHours)
-	c.ItemService = services.NewItemService(c.ItemRepository)
-	c.StockService = services.NewStockService(c.StockRepository)
+	c.UOMService = services.NewUOMService(c.UOMRepository, c.ItemRepository)
+	c.ItemAttributeService = services.NewItemAttributeService(c.ItemVariantRepository)
+	c.ItemService = services.NewItemService(c.ItemRepository, c.UOMRepository, c.ItemVariantRepository)
+	c.StockService = services.NewStockService(c.StockRepository, c.StockLedgerRepository, c.ReservationRepository)
 	c.WarehouseService = services.NewWarehouseService(c.WarehouseRepository)
-	c.MovementService = services.NewMovementService(c.MovementRepository, c.StockRepository, c.ItemRepository, c.WarehouseRepository)
+	c.MovementService = services.NewMovementService(c.MovementRepository, c.StockRepository, c.StockLedgerRepository, c.ItemRepository, c.WarehouseRepository, c.BatchRepository, c.UOMService)
+	c.StockTransferService = services.NewStockTransferService(c.StockTransferRepository, c.ItemRepository, c.WarehouseRepository, c.UOMService)
+	c.StockValuationService = services.NewStockValuationService(c.StockValuationRepository, journalEntryRepo, c.CompanyRepository)
+	c.InventoryReportService = services.NewInventoryReportService(c.InventoryReportRepository, c.AccountRepository, c.CompanyRepository)
+	c.BatchTrackingService = services.NewBatchTrackingService(c.BatchRepository, c.ItemRepository)
+	c.LocationService = services.NewLocationService(c.LocationRepository, c.WarehouseRepository, c.ItemRepository, c.BatchRepository, c.DeliveryNoteRepository)
+	c.ReplenishmentService = services.NewReplenishmentService(c.ReplenishmentRepository)
+	c.ReservationService = services.NewReservationService(c.ReservationRepository, c.SalesOrderRepository)
+	c.StockCountService = services.NewStockCountService(c.StockCountRepository, c.LocationRepository, c.AccountRepository, c.CompanyRepository, c.InventoryReportService)
+	c.LandedCostService = services.NewLandedCostService(c.LandedCostRepository, c.AccountRepository, c.CompanyRepository)
+	c.BarcodeService = services.NewBarcodeService(c.BarcodeRepository, c.BatchRepository, c.ItemRepository, c.UOMService)
+	c.PriceListService = services.NewPriceListService(c.PriceListRepository, c.ItemRepository, c.UOMService)
 	c.CustomerService = services.NewCustomerService(c.CustomerRepository)
 	c.ProductService = services.NewProductService(c.ProductRepository)
 
 	// Accounting services (需要先初始化，因为其他服务可能依赖)
-	c.AccountService = services.NewAccountService(c.AccountRepository)
-	c.JournalEntryService = services.NewJournalEntryService(journalEntryRepo, c.AccountRepository)
-	c.PaymentEntryService = services.NewPaymentEntryService(paymentEntryRepo)
+	c.CompanyService = services.NewCompanyService(c.CompanyRepository)
+	c.AccountService = services.NewAccountService(c.AccountRepository, c.CompanyRepository)
+	c.JournalEntryService = services.NewJournalEntryService(journalEntryRepo, c.AccountRepository, c.CompanyRepository)
+	c.PaymentEntryService = services.NewPaymentEntryService(paymentEntryRepo, c.AccountRepository, c.CompanyRepository)
+	c.IntercompanyService = services.NewIntercompanyService(c.IntercompanyRepository, c.CompanyRepository, journalEntryRepo)
 
 	// Sales services (依赖会计服务)
-	c.SalesOrderService = services.NewSalesOrderService(c.SalesOrderRepository, c.CustomerRepository)
-	c.QuotationService = services.NewQuotationService(c.QuotationRepository, c.CustomerRepository)
+	c.SalesOrderService = services.NewSalesOrderService(c.SalesOrderRepository, c.CustomerRepository, c.ReservationRepository, c.PriceListService, c.AuditLogService)
+	c.QuotationService = services.NewQuotationService(c.QuotationRepository, c.CustomerRepository, c.PriceListService, c.SalesOrderService)
 	c.QuotationTemplateService = services.NewQuotationTemplateService(quotationTemplateRepo, c.QuotationRepository)
 	c.QuotationVersionService = services.NewQuotationVersionService(quotationVersionRepo, c.QuotationRepository)
-	c.SalesInvoiceService = services.NewSalesInvoiceService(c.SalesInvoiceRepository, c.CustomerRepository, c.SalesOrderRepository, c.PaymentEntryService)
-	c.DeliveryNoteService = services.NewDeliveryNoteService(c.DeliveryNoteRepository, c.SalesOrderRepository, c.CustomerRepository)
+	c.SalesInvoiceService = services.NewSalesInvoiceService(c.SalesInvoiceRepository, c.CustomerRepository, c.SalesOrderRepository, c.PaymentEntryService, c.UOMService, c.DeliveryNoteRepository, c.SalesOrderService)
+	c.DeliveryNoteService = services.NewDeliveryNoteService(c.DeliveryNoteRepository, c.SalesOrderRepository, c.CustomerRepository, c.ItemRepository, c.BatchRepository, c.ReservationRepository, c.UOMService, c.SalesOrderService)
+	c.DunningService = services.NewDunningService(c.DunningRepository, c.CustomerRepository)
 
 	// Purchase services
 	c.SupplierService = services.NewSupplierService(c.SupplierRepository)
-	c.PurchaseRequestService = services.NewPurchaseRequestService(c.PurchaseRequestRepository)
-	c.PurchaseOrderService = services.NewPurchaseOrderService(c.PurchaseOrderRepository)
+	c.PurchaseRequestService = services.NewPurchaseRequestService(c.PurchaseRequestRepository, c.UOMService)
+	c.PurchaseOrderService = services.NewPurchaseOrderService(c.PurchaseOrderRepository, c.PriceListService)
 
 	// Project services
 	c.ProjectService = services.NewProjectService(c.ProjectRepository)
@@ -206,11 +290,25 @@ func (c *Container) initControllers() {
 	c.AuditLogHandler = handlers.NewAuditLogHandler(c.AuditLogService, zap.L())
 
 	c.UserController = controllers.NewUserController(c.UserService)
-	c.InventoryController = controllers.NewInventoryController(c.ItemService, c.StockService, c.WarehouseService, c.MovementService)
+	c.InventoryController = controllers.NewInventoryController(c.ItemService, c.StockService, c.WarehouseService, c.MovementService, c.InventoryReportService)
+	c.StockTransferController = controllers.NewStockTransferController(c.StockTransferService)
+	c.StockValuationController = controllers.NewStockValuationController(c.StockValuationService)
+	c.BatchController = controllers.NewBatchController(c.BatchTrackingService)
+	c.LocationController = controllers.NewLocationController(c.LocationService)
+	c.ReplenishmentController = controllers.NewReplenishmentController(c.ReplenishmentService)
+	c.ReservationController = controllers.NewReservationController(c.ReservationService)
+	c.StockCountController = controllers.NewStockCountController(c.StockCountService)
+	c.UOMController = controllers.NewUOMController(c.UOMService)
+	c.ItemVariantController = controllers.NewItemVariantController(c.ItemAttributeService, c.ItemService)
+	c.LandedCostController = controllers.NewLandedCostController(c.LandedCostService)
+	c.BarcodeController = controllers.NewBarcodeController(c.BarcodeService)
+	c.PriceListController = controllers.NewPriceListController(c.PriceListService)
 	c.SalesController = controllers.NewSalesController(c.CustomerService, c.SalesOrderService, c.QuotationService, c.QuotationTemplateService, c.SalesInvoiceService, c.QuotationVersionService)
 	c.DeliveryNoteController = controllers.NewDeliveryNoteController(c.DeliveryNoteService)
+	c.DunningController = controllers.NewDunningController(c.DunningService)
 	c.ProductionController = controllers.NewProductionController(c.ProductService)
 	c.SystemController = controllers.NewSystemController()
+	c.CompanyController = controllers.NewCompanyController(c.CompanyService)
 
 	// Purchase Controller
 	c.PurchaseController = controllers.NewPurchaseController(
@@ -233,6 +331,8 @@ func (c *Container) initControllers() {
 		c.AccountService,
 		c.JournalEntryService,
 	)
+	c.IntercompanyController = controllers.NewIntercompanyController(c.IntercompanyService)
+	c.PaymentEntryController = controllers.NewPaymentEntryController(c.PaymentEntryService)
 
 	// HR Controller
 	c.HRController = controllers.NewHRController(
@@ -1,6 +1,11 @@
 package controllers
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
 	"github.com/galaxyerp/galaxyErp/internal/dto"
 	"github.com/galaxyerp/galaxyErp/internal/models"
 	"github.com/galaxyerp/galaxyErp/internal/services"
@@ -47,6 +52,7 @@ func (c *AccountingController) CreateAccount(ctx *gin.Context) {
 
 	// 转换为模型
 	account := models.Account{
+		CompanyID:   req.CompanyID,
 		Code:        req.Code,
 		Name:        req.Name,
 		AccountType: req.Type,
@@ -63,6 +69,7 @@ func (c *AccountingController) CreateAccount(ctx *gin.Context) {
 	// 转换为响应 DTO
 	response := dto.AccountResponse{
 		ID:        account.ID,
+		CompanyID: account.CompanyID,
 		Code:      account.Code,
 		Name:      account.Name,
 		Type:      account.AccountType,
@@ -86,12 +93,17 @@ func (c *AccountingController) CreateAccount(ctx *gin.Context) {
 // @Param page_size query int false "每页数量" default(10)
 // @Param account_type query string false "科目类型"
 // @Param keyword query string false "搜索关键字"
+// @Param company_id query int false "公司ID"
 // @Success 200 {object} dto.PaginatedResponse{data=[]models.Account}
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /api/v1/accounting/accounts [get]
 func (c *AccountingController) GetAccountList(ctx *gin.Context) {
 	pagination := c.utils.ParsePaginationParams(ctx)
+	companyID, ok := c.utils.ParseCompanyIDQuery(ctx)
+	if !ok {
+		return
+	}
 	accountType := ctx.Query("account_type")
 	keyword := ctx.Query("keyword")
 
@@ -104,10 +116,10 @@ func (c *AccountingController) GetAccountList(ctx *gin.Context) {
 		accounts, total, err = c.accountService.SearchAccounts(ctx.Request.Context(), keyword, pagination.Page, pagination.PageSize)
 	} else if accountType != "" {
 		// 按类型筛选
-		accounts, total, err = c.accountService.GetAccountsByType(ctx.Request.Context(), accountType, pagination.Page, pagination.PageSize)
+		accounts, total, err = c.accountService.GetAccountsByType(ctx.Request.Context(), companyID, accountType, pagination.Page, pagination.PageSize)
 	} else {
 		// 获取所有科目
-		accounts, total, err = c.accountService.ListAccounts(ctx.Request.Context(), pagination.Page, pagination.PageSize)
+		accounts, total, err = c.accountService.ListAccounts(ctx.Request.Context(), companyID, pagination.Page, pagination.PageSize)
 	}
 
 	if err != nil {
@@ -240,6 +252,7 @@ func (c *AccountingController) DeleteAccount(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param code path string true "科目编码"
+// @Param company_id query int false "公司ID，默认为默认公司"
 // @Success 200 {object} dto.SuccessResponse{data=models.Account}
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
@@ -252,7 +265,12 @@ func (c *AccountingController) GetAccountByCode(ctx *gin.Context) {
 		return
 	}
 
-	account, err := c.accountService.GetAccountByCode(ctx.Request.Context(), code)
+	companyID, ok := c.utils.ParseCompanyIDQuery(ctx)
+	if !ok {
+		return
+	}
+
+	account, err := c.accountService.GetAccountByCode(ctx.Request.Context(), companyID, code)
 	if err != nil {
 		if err.Error() == "科目不存在" {
 			c.utils.RespondNotFound(ctx, "科目不存在")
@@ -291,6 +309,132 @@ func (c *AccountingController) GetAccountChildren(ctx *gin.Context) {
 	c.utils.RespondOK(ctx, children)
 }
 
+// GetChartTemplates 获取科目表模板
+// @Summary 获取科目表模板
+// @Description 获取内置的科目表模板列表
+// @Tags 会计科目
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.SuccessResponse{data=[]dto.ChartTemplateResponse}
+// @Router /api/v1/accounts/templates [get]
+func (c *AccountingController) GetChartTemplates(ctx *gin.Context) {
+	c.utils.RespondOK(ctx, c.accountService.ListChartTemplates())
+}
+
+// ApplyChartTemplate 应用科目表模板
+// @Summary 应用科目表模板
+// @Description 将内置科目表模板导入到公司，dry_run 为 true 时只返回冲突检查结果
+// @Tags 会计科目
+// @Accept json
+// @Produce json
+// @Param request body dto.ChartTemplateApplyRequest true "模板信息"
+// @Success 200 {object} dto.SuccessResponse{data=dto.AccountImportResult}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/accounts/templates/apply [post]
+func (c *AccountingController) ApplyChartTemplate(ctx *gin.Context) {
+	var req dto.ChartTemplateApplyRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.accountService.ApplyChartTemplate(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, result)
+}
+
+// ImportAccounts 导入科目表
+// @Summary 导入科目表
+// @Description 从 CSV 或 JSON 导入科目，父科目以 parent_code 指定；可上传 file 表单文件或直接提交请求体
+// @Tags 会计科目
+// @Accept multipart/form-data,text/csv,json
+// @Produce json
+// @Param company_id query int false "公司ID"
+// @Param format query string false "文件格式(csv/json)，默认根据文件名判断"
+// @Param dry_run query bool false "仅检查冲突，不写入"
+// @Param skip_existing query bool false "跳过已存在的科目编码"
+// @Param file formData file false "科目文件"
+// @Success 200 {object} dto.SuccessResponse{data=dto.AccountImportResult}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/accounts/import [post]
+func (c *AccountingController) ImportAccounts(ctx *gin.Context) {
+	var req dto.AccountImportRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	var data []byte
+	var err error
+	if fileHeader, fileErr := ctx.FormFile("file"); fileErr == nil {
+		if req.Format == "" && strings.HasSuffix(strings.ToLower(fileHeader.Filename), ".json") {
+			req.Format = "json"
+		}
+		file, openErr := fileHeader.Open()
+		if openErr != nil {
+			c.utils.RespondBadRequest(ctx, "读取上传文件失败: "+openErr.Error())
+			return
+		}
+		defer file.Close()
+		data, err = io.ReadAll(file)
+	} else {
+		if req.Format == "" && strings.Contains(ctx.ContentType(), "json") {
+			req.Format = "json"
+		}
+		data, err = io.ReadAll(ctx.Request.Body)
+	}
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, "读取导入数据失败: "+err.Error())
+		return
+	}
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+
+	result, err := c.accountService.ImportAccounts(ctx.Request.Context(), &req, data)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, result)
+}
+
+// ExportAccounts 导出科目表
+// @Summary 导出科目表
+// @Description 以导入相同的 CSV 或 JSON 格式导出公司科目表
+// @Tags 会计科目
+// @Produce text/csv,json
+// @Param company_id query int false "公司ID"
+// @Param format query string false "文件格式(csv/json)" default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/accounts/export [get]
+func (c *AccountingController) ExportAccounts(ctx *gin.Context) {
+	var req dto.AccountExportRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+
+	data, err := c.accountService.ExportAccounts(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if req.Format == "json" {
+		contentType = "application/json; charset=utf-8"
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=chart_of_accounts.%s", req.Format))
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
 // CreateJournalEntry 创建会计分录
 // @Summary 创建会计分录
 // @Description 创建新的会计分录
@@ -309,7 +453,7 @@ func (c *AccountingController) CreateJournalEntry(ctx *gin.Context) {
 	}
 
 	// 验证借贷平衡
-	var totalDebit, totalCredit float64
+	var totalDebit, totalCredit models.Money
 	for _, item := range req.Items {
 		totalDebit += item.DebitAmount
 		totalCredit += item.CreditAmount
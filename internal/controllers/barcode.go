@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// BarcodeController 物料条码、扫描与标签控制器
+type BarcodeController struct {
+	barcodeService services.BarcodeService
+	utils          *ControllerUtils
+}
+
+// NewBarcodeController 创建物料条码控制器实例
+func NewBarcodeController(barcodeService services.BarcodeService) *BarcodeController {
+	return &BarcodeController{
+		barcodeService: barcodeService,
+		utils:          NewControllerUtils(),
+	}
+}
+
+// RegisterBarcode 登记物料条码
+// @Summary 登记物料条码
+// @Description 为物料登记条码，一个物料可按不同单位登记多个条码；EAN-13、UPC-A、EAN-8、GTIN-14 校验校验位
+// @Tags 条码
+// @Accept json
+// @Produce json
+// @Param id path int true "物料ID"
+// @Param request body dto.ItemBarcodeCreateRequest true "条码信息"
+// @Success 201 {object} dto.ItemBarcodeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/items/{id}/barcodes [post]
+func (c *BarcodeController) RegisterBarcode(ctx *gin.Context) {
+	itemID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.ItemBarcodeCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	barcode, err := c.barcodeService.RegisterBarcode(ctx.Request.Context(), itemID, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, barcode)
+}
+
+// ListItemBarcodes 获取物料条码
+// @Summary 获取物料条码
+// @Description 获取物料登记的全部条码及其单位换算系数，主条码在前
+// @Tags 条码
+// @Accept json
+// @Produce json
+// @Param id path int true "物料ID"
+// @Success 200 {array} dto.ItemBarcodeResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/items/{id}/barcodes [get]
+func (c *BarcodeController) ListItemBarcodes(ctx *gin.Context) {
+	itemID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	barcodes, err := c.barcodeService.ListItemBarcodes(ctx.Request.Context(), itemID)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, barcodes)
+}
+
+// DeleteBarcode 删除物料条码
+// @Summary 删除物料条码
+// @Description 删除物料登记的条码
+// @Tags 条码
+// @Accept json
+// @Produce json
+// @Param id path int true "物料ID"
+// @Param barcode_id path int true "条码ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/items/{id}/barcodes/{barcode_id} [delete]
+func (c *BarcodeController) DeleteBarcode(ctx *gin.Context) {
+	itemID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	id, ok := c.utils.ParseIDParam(ctx, "barcode_id")
+	if !ok {
+		return
+	}
+
+	if err := c.barcodeService.DeleteBarcode(ctx.Request.Context(), itemID, id); err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, gin.H{"message": "物料条码删除成功"})
+}
+
+// Scan 解析扫描条码
+// @Summary 解析扫描条码
+// @Description 解析 GS1-128 应用标识符（GTIN、批次、有效期、序列号、数量）与 EAN-13/UPC 条码，识别物料、批次与库位，预填库存移动及采购收货明细
+// @Tags 条码
+// @Accept json
+// @Produce json
+// @Param request body dto.BarcodeScanRequest true "扫描内容"
+// @Success 200 {object} dto.BarcodeScanResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/barcodes/scan [post]
+func (c *BarcodeController) Scan(ctx *gin.Context) {
+	var req dto.BarcodeScanRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.barcodeService.Scan(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, result)
+}
+
+// GenerateLabels 生成标签
+// @Summary 生成物料或库位标签
+// @Description 生成 ZPL 指令或 PDF 标签文件；物料标签指定批次且物料有 GTIN 条码时打印 GS1-128 条码
+// @Tags 条码
+// @Accept json
+// @Produce application/octet-stream
+// @Param request body dto.BarcodeLabelRequest true "标签请求"
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/barcodes/labels [post]
+func (c *BarcodeController) GenerateLabels(ctx *gin.Context) {
+	var req dto.BarcodeLabelRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	data, err := c.barcodeService.GenerateLabels(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	contentType, extension := "application/octet-stream", "zpl"
+	if req.Format == "pdf" {
+		contentType, extension = "application/pdf", "pdf"
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s_labels.%s", req.Type, extension))
+	ctx.Data(http.StatusOK, contentType, data)
+}
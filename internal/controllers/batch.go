@@ -0,0 +1,310 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// BatchController 批次与序列号控制器
+type BatchController struct {
+	batchService services.BatchTrackingService
+	utils        *ControllerUtils
+}
+
+// NewBatchController 创建批次控制器实例
+func NewBatchController(batchService services.BatchTrackingService) *BatchController {
+	return &BatchController{
+		batchService: batchService,
+		utils:        NewControllerUtils(),
+	}
+}
+
+// CreateBatch 创建批次
+// @Summary 创建批次
+// @Description 为启用批次或序列号跟踪的物料创建批次，记录生产日期、有效期与供应商批号
+// @Tags 批次与序列号
+// @Accept json
+// @Produce json
+// @Param batch body dto.BatchCreateRequest true "批次信息"
+// @Success 201 {object} dto.BatchResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/batches [post]
+func (c *BatchController) CreateBatch(ctx *gin.Context) {
+	var req dto.BatchCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	batch, err := c.batchService.CreateBatch(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, batch)
+}
+
+// GetBatch 获取批次详情
+// @Summary 获取批次详情
+// @Description 根据ID获取批次主数据
+// @Tags 批次与序列号
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Success 200 {object} dto.BatchResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/batches/{id} [get]
+func (c *BatchController) GetBatch(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	batch, err := c.batchService.GetBatch(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, batch)
+}
+
+// UpdateBatch 更新批次
+// @Summary 更新批次
+// @Description 更新批次的生产日期、有效期、供应商批号与启用状态
+// @Tags 批次与序列号
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param batch body dto.BatchUpdateRequest true "批次信息"
+// @Success 200 {object} dto.BatchResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/batches/{id} [put]
+func (c *BatchController) UpdateBatch(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.BatchUpdateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	batch, err := c.batchService.UpdateBatch(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, batch)
+}
+
+// ListBatches 获取批次列表
+// @Summary 获取批次列表
+// @Description 分页获取批次，可按物料和批次号筛选
+// @Tags 批次与序列号
+// @Accept json
+// @Produce json
+// @Param item_id query int false "物料ID"
+// @Param batch_no query string false "批次号"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PaginatedResponse[dto.BatchResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/batches [get]
+func (c *BatchController) ListBatches(ctx *gin.Context) {
+	var req dto.BatchListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	batches, total, err := c.batchService.ListBatches(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, batches, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取批次列表成功")
+}
+
+// GetBatchStocks 获取批次库存
+// @Summary 获取批次库存
+// @Description 按物料、仓库获取各批次的库存余额
+// @Tags 批次与序列号
+// @Accept json
+// @Produce json
+// @Param item_id query int false "物料ID"
+// @Param warehouse_id query int false "仓库ID"
+// @Param include_zero query bool false "包含零库存批次"
+// @Success 200 {object} dto.BatchStockResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/batches/stock [get]
+func (c *BatchController) GetBatchStocks(ctx *gin.Context) {
+	var req dto.BatchStockRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	stock, err := c.batchService.GetBatchStock(ctx.Request.Context(), 0, &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, stock)
+}
+
+// GetExpiryReport 获取近效期报表
+// @Summary 获取近效期报表
+// @Description 列出已过期及在预警天数内到期的批次库存，按有效期先后排列
+// @Tags 批次与序列号
+// @Accept json
+// @Produce json
+// @Param item_id query int false "物料ID"
+// @Param warehouse_id query int false "仓库ID"
+// @Param days query int false "预警天数，默认 30 天"
+// @Param exclude_expired query bool false "只列出尚未过期的批次"
+// @Success 200 {object} dto.ExpiryReportResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/batches/expiring [get]
+func (c *BatchController) GetExpiryReport(ctx *gin.Context) {
+	var req dto.ExpiryReportRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	report, err := c.batchService.GetExpiryReport(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, report)
+}
+
+// GetBatchStock 获取单个批次的库存
+// @Summary 获取单个批次的库存
+// @Description 获取批次在各仓库的库存余额
+// @Tags 批次与序列号
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param warehouse_id query int false "仓库ID"
+// @Param include_zero query bool false "包含零库存仓库"
+// @Success 200 {object} dto.BatchStockResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/batches/{id}/stock [get]
+func (c *BatchController) GetBatchStock(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.BatchStockRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	stock, err := c.batchService.GetBatchStock(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, stock)
+}
+
+// TraceBatch 批次追溯
+// @Summary 批次追溯
+// @Description 正向追溯批次经生产订单产出的批次及客户送货，反向追溯投料批次及采购入库
+// @Tags 批次与序列号
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param direction query string false "追溯方向：forward、backward、both" default(both)
+// @Success 200 {object} dto.TraceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/batches/{id}/trace [get]
+func (c *BatchController) TraceBatch(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.TraceRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	trace, err := c.batchService.TraceBatch(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, trace)
+}
+
+// ListSerialNumbers 获取序列号列表
+// @Summary 获取序列号列表
+// @Description 分页获取序列号，可按物料、仓库、批次和状态筛选
+// @Tags 批次与序列号
+// @Accept json
+// @Produce json
+// @Param item_id query int false "物料ID"
+// @Param warehouse_id query int false "仓库ID"
+// @Param batch_id query int false "批次ID"
+// @Param status query string false "状态"
+// @Param serial_no query string false "序列号"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PaginatedResponse[dto.SerialNumberResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/serial-numbers [get]
+func (c *BatchController) ListSerialNumbers(ctx *gin.Context) {
+	var req dto.SerialNumberListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	serials, total, err := c.batchService.ListSerialNumbers(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, serials, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取序列号列表成功")
+}
+
+// TraceSerialNumber 序列号追溯
+// @Summary 序列号追溯
+// @Description 追溯序列号经历的采购入库、生产订单与客户送货
+// @Tags 批次与序列号
+// @Accept json
+// @Produce json
+// @Param id path int true "序列号ID"
+// @Param direction query string false "追溯方向：forward、backward、both" default(both)
+// @Success 200 {object} dto.TraceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/serial-numbers/{id}/trace [get]
+func (c *BatchController) TraceSerialNumber(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.TraceRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	trace, err := c.batchService.TraceSerialNumber(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, trace)
+}
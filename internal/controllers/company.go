@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CompanyController 公司与财政日历控制器
+type CompanyController struct {
+	companyService services.CompanyService
+	utils          *ControllerUtils
+}
+
+// NewCompanyController 创建公司控制器实例
+func NewCompanyController(companyService services.CompanyService) *CompanyController {
+	return &CompanyController{
+		companyService: companyService,
+		utils:          NewControllerUtils(),
+	}
+}
+
+// CreateCompany 创建公司
+// @Summary 创建公司
+// @Description 创建公司，可指定上级公司组成集团合并范围
+// @Tags 公司管理
+// @Accept json
+// @Produce json
+// @Param company body dto.CompanyCreateRequest true "公司信息"
+// @Success 201 {object} dto.CompanyResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/system/companies [post]
+func (c *CompanyController) CreateCompany(ctx *gin.Context) {
+	var req dto.CompanyCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	userID := utils.GetUserIDFromContext(ctx)
+	company, err := c.companyService.CreateCompany(ctx.Request.Context(), &req, userID)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, company)
+}
+
+// GetCompanies 获取公司列表
+// @Summary 获取公司列表
+// @Description 分页获取公司列表
+// @Tags 公司管理
+// @Accept json
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PaginatedResponse[dto.CompanyResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/system/companies [get]
+func (c *CompanyController) GetCompanies(ctx *gin.Context) {
+	pagination := c.utils.ParsePaginationParams(ctx)
+
+	result, err := c.companyService.ListCompanies(ctx.Request.Context(), pagination)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, result.Data, c.utils.CreatePagination(pagination.Page, pagination.PageSize, result.Total), "获取公司列表成功")
+}
+
+// GetCompany 获取公司
+// @Summary 获取公司详情
+// @Description 根据ID获取公司详情
+// @Tags 公司管理
+// @Accept json
+// @Produce json
+// @Param id path int true "公司ID"
+// @Success 200 {object} dto.CompanyResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/system/companies/{id} [get]
+func (c *CompanyController) GetCompany(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	company, err := c.companyService.GetCompany(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, company)
+}
+
+// UpdateCompany 更新公司
+// @Summary 更新公司
+// @Description 更新公司信息及上级公司
+// @Tags 公司管理
+// @Accept json
+// @Produce json
+// @Param id path int true "公司ID"
+// @Param company body dto.CompanyUpdateRequest true "公司信息"
+// @Success 200 {object} dto.CompanyResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/system/companies/{id} [put]
+func (c *CompanyController) UpdateCompany(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CompanyUpdateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	userID := utils.GetUserIDFromContext(ctx)
+	company, err := c.companyService.UpdateCompany(ctx.Request.Context(), id, &req, userID)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, company)
+}
+
+// DeleteCompany 删除公司
+// @Summary 删除公司
+// @Description 删除没有科目、凭证及下级公司的公司
+// @Tags 公司管理
+// @Accept json
+// @Produce json
+// @Param id path int true "公司ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/system/companies/{id} [delete]
+func (c *CompanyController) DeleteCompany(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.companyService.DeleteCompany(ctx.Request.Context(), id); err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondSuccess(ctx, "公司删除成功")
+}
+
+// GetFiscalYears 获取公司财政年度
+// @Summary 获取公司财政年度
+// @Description 获取公司的财政年度及其会计期间
+// @Tags 公司管理
+// @Accept json
+// @Produce json
+// @Param id path int true "公司ID"
+// @Success 200 {array} dto.FiscalYearResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/system/companies/{id}/fiscal-years [get]
+func (c *CompanyController) GetFiscalYears(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	fiscalYears, err := c.companyService.ListFiscalYears(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, fiscalYears)
+}
+
+// CreateFiscalYear 创建公司财政年度
+// @Summary 创建公司财政年度
+// @Description 创建公司财政年度并按月生成会计期间
+// @Tags 公司管理
+// @Accept json
+// @Produce json
+// @Param id path int true "公司ID"
+// @Param fiscal_year body dto.FiscalYearCreateRequest true "财政年度信息"
+// @Success 201 {object} dto.FiscalYearResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/system/companies/{id}/fiscal-years [post]
+func (c *CompanyController) CreateFiscalYear(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.FiscalYearCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	fiscalYear, err := c.companyService.CreateFiscalYear(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, fiscalYear)
+}
+
+// CloseAccountingPeriod 关闭会计期间
+// @Summary 关闭会计期间
+// @Description 关闭会计期间，关闭后该期间不允许过账
+// @Tags 公司管理
+// @Accept json
+// @Produce json
+// @Param id path int true "会计期间ID"
+// @Success 200 {object} dto.AccountingPeriodResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/system/accounting-periods/{id}/close [put]
+func (c *CompanyController) CloseAccountingPeriod(ctx *gin.Context) {
+	c.setPeriodClosed(ctx, true)
+}
+
+// ReopenAccountingPeriod 重新打开会计期间
+// @Summary 重新打开会计期间
+// @Description 重新打开已关闭的会计期间
+// @Tags 公司管理
+// @Accept json
+// @Produce json
+// @Param id path int true "会计期间ID"
+// @Success 200 {object} dto.AccountingPeriodResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/system/accounting-periods/{id}/reopen [put]
+func (c *CompanyController) ReopenAccountingPeriod(ctx *gin.Context) {
+	c.setPeriodClosed(ctx, false)
+}
+
+// setPeriodClosed 设置会计期间关闭状态
+func (c *CompanyController) setPeriodClosed(ctx *gin.Context, closed bool) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	userID := utils.GetUserIDFromContext(ctx)
+	period, err := c.companyService.SetPeriodClosed(ctx.Request.Context(), id, closed, userID)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, period)
+}
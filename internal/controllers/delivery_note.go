@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
 	"github.com/galaxyerp/galaxyErp/internal/services"
 	"github.com/gin-gonic/gin"
 )
@@ -200,6 +202,7 @@ func (c *DeliveryNoteController) List(ctx *gin.Context) {
 // @Success 200 {object} utils.Response{data=models.DeliveryNote}
 // @Failure 400 {object} utils.Response
 // @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /api/delivery-notes/{id}/status [patch]
 func (c *DeliveryNoteController) UpdateStatus(ctx *gin.Context) {
@@ -213,9 +216,28 @@ func (c *DeliveryNoteController) UpdateStatus(ctx *gin.Context) {
 		return
 	}
 
-	deliveryNote, err := c.deliveryNoteService.UpdateStatus(id, &req)
+	// 获取当前用户ID
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		c.utils.RespondUnauthorized(ctx, "用户未登录")
+		return
+	}
+
+	deliveryNote, err := c.deliveryNoteService.UpdateStatus(id, &req, userID.(uint))
 	if err != nil {
-		c.utils.RespondInternalError(ctx, "更新状态失败")
+		switch {
+		case errors.Is(err, services.ErrDeliveryNoteNotFound):
+			c.utils.RespondNotFound(ctx, "发货单")
+		case errors.Is(err, services.ErrDeliveryNoteInvalid):
+			c.utils.RespondBadRequest(ctx, err.Error())
+		case errors.Is(err, services.ErrDeliveryNoteStatusConflict),
+			errors.Is(err, repositories.ErrOverDelivery),
+			errors.Is(err, repositories.ErrInsufficientStock),
+			errors.Is(err, repositories.ErrBatchExpired):
+			c.utils.RespondConflict(ctx, err.Error())
+		default:
+			c.utils.RespondInternalError(ctx, "更新发货单状态失败")
+		}
 		return
 	}
 
@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DunningController 对账单与催款控制器
+type DunningController struct {
+	dunningService services.DunningService
+	utils          *ControllerUtils
+}
+
+// NewDunningController 创建对账单与催款控制器实例
+func NewDunningController(dunningService services.DunningService) *DunningController {
+	return &DunningController{
+		dunningService: dunningService,
+		utils:          NewControllerUtils(),
+	}
+}
+
+// GetCustomerStatement 获取客户对账单
+// @Summary 获取客户对账单
+// @Description 生成客户在指定期间内的发票与收款对账单
+// @Tags 客户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "客户ID"
+// @Param start_date query string true "开始日期(YYYY-MM-DD)"
+// @Param end_date query string true "结束日期(YYYY-MM-DD)"
+// @Success 200 {object} dto.CustomerStatementResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/customers/{id}/statement [get]
+func (c *DunningController) GetCustomerStatement(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CustomerStatementRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	statement, err := c.dunningService.GetCustomerStatement(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, "生成客户对账单失败: "+err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, statement)
+}
+
+// ListDunningLevels 获取催款级别列表
+// @Summary 获取催款级别列表
+// @Description 获取所有催款级别配置
+// @Tags 催款管理
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.DunningLevelResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/dunning/levels [get]
+func (c *DunningController) ListDunningLevels(ctx *gin.Context) {
+	levels, err := c.dunningService.ListLevels(ctx.Request.Context())
+	if err != nil {
+		c.utils.RespondInternalError(ctx, "获取催款级别失败")
+		return
+	}
+
+	c.utils.RespondOK(ctx, levels)
+}
+
+// CreateDunningLevel 创建催款级别
+// @Summary 创建催款级别
+// @Description 创建催款级别，包括触发天数、手续费、利率与催款函模板
+// @Tags 催款管理
+// @Accept json
+// @Produce json
+// @Param level body dto.DunningLevelCreateRequest true "催款级别信息"
+// @Success 201 {object} dto.DunningLevelResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/dunning/levels [post]
+func (c *DunningController) CreateDunningLevel(ctx *gin.Context) {
+	var req dto.DunningLevelCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	level, err := c.dunningService.CreateLevel(ctx.Request.Context(), &req, utils.GetUserIDFromContext(ctx))
+	if err != nil {
+		c.utils.RespondInternalError(ctx, "创建催款级别失败: "+err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, level)
+}
+
+// UpdateDunningLevel 更新催款级别
+// @Summary 更新催款级别
+// @Description 更新催款级别配置
+// @Tags 催款管理
+// @Accept json
+// @Produce json
+// @Param id path int true "催款级别ID"
+// @Param level body dto.DunningLevelUpdateRequest true "催款级别信息"
+// @Success 200 {object} dto.DunningLevelResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/dunning/levels/{id} [put]
+func (c *DunningController) UpdateDunningLevel(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.DunningLevelUpdateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	level, err := c.dunningService.UpdateLevel(ctx.Request.Context(), id, &req, utils.GetUserIDFromContext(ctx))
+	if err != nil {
+		c.utils.RespondInternalError(ctx, "更新催款级别失败: "+err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, level)
+}
+
+// DeleteDunningLevel 删除催款级别
+// @Summary 删除催款级别
+// @Description 删除催款级别配置
+// @Tags 催款管理
+// @Accept json
+// @Produce json
+// @Param id path int true "催款级别ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/dunning/levels/{id} [delete]
+func (c *DunningController) DeleteDunningLevel(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.dunningService.DeleteLevel(ctx.Request.Context(), id); err != nil {
+		c.utils.RespondInternalError(ctx, "删除催款级别失败: "+err.Error())
+		return
+	}
+
+	c.utils.RespondSuccess(ctx, "删除催款级别成功")
+}
+
+// RunDunning 手动执行催款
+// @Summary 手动执行催款
+// @Description 标记逾期发票并按催款级别生成催款函，dry_run 为 true 时仅预览不保存
+// @Tags 催款管理
+// @Accept json
+// @Produce json
+// @Param request body dto.DunningRunRequest true "催款执行参数"
+// @Success 200 {object} dto.DunningRunResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/dunning/run [post]
+func (c *DunningController) RunDunning(ctx *gin.Context) {
+	var req dto.DunningRunRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.dunningService.RunDunning(ctx.Request.Context(), &req, services.DunningTriggerManual, utils.GetUserIDFromContext(ctx))
+	if err != nil {
+		c.utils.RespondInternalError(ctx, "执行催款失败: "+err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, result)
+}
+
+// ListDunningLetters 获取催款函发送记录
+// @Summary 获取催款函发送记录
+// @Description 分页查询催款函发送记录
+// @Tags 催款管理
+// @Accept json
+// @Produce json
+// @Param customer_id query int false "客户ID"
+// @Param sales_invoice_id query int false "销售发票ID"
+// @Param level query int false "催款级别"
+// @Param trigger_type query string false "触发方式(manual/scheduled)"
+// @Param start_date query string false "开始日期(YYYY-MM-DD)"
+// @Param end_date query string false "结束日期(YYYY-MM-DD)"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} dto.PaginatedResponse[dto.DunningLetterResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/dunning/letters [get]
+func (c *DunningController) ListDunningLetters(ctx *gin.Context) {
+	var req dto.DunningLetterListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.utils.RespondBadRequest(ctx, "查询参数错误: "+err.Error())
+		return
+	}
+	req.PaginationRequest = *c.utils.ParsePaginationParams(ctx)
+
+	response, err := c.dunningService.ListLetters(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, "获取催款函列表失败")
+		return
+	}
+
+	pagination := c.utils.CreatePagination(response.Page, response.Limit, response.Total)
+	c.utils.RespondPaginated(ctx, response.Data, pagination, "获取催款函列表成功")
+}
+
+// GetDunningLetter 获取催款函详情
+// @Summary 获取催款函详情
+// @Description 根据ID获取催款函内容
+// @Tags 催款管理
+// @Accept json
+// @Produce json
+// @Param id path int true "催款函ID"
+// @Success 200 {object} dto.DunningLetterResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/dunning/letters/{id} [get]
+func (c *DunningController) GetDunningLetter(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	letter, err := c.dunningService.GetLetter(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, "催款函不存在")
+		return
+	}
+
+	c.utils.RespondOK(ctx, letter)
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// IntercompanyController 内部交易与合并报表控制器
+type IntercompanyController struct {
+	intercompanyService services.IntercompanyService
+	utils               *ControllerUtils
+}
+
+// NewIntercompanyController 创建内部交易控制器实例
+func NewIntercompanyController(intercompanyService services.IntercompanyService) *IntercompanyController {
+	return &IntercompanyController{
+		intercompanyService: intercompanyService,
+		utils:               NewControllerUtils(),
+	}
+}
+
+// MirrorSalesOrder 生成内部采购订单
+// @Summary 生成内部采购订单
+// @Description 将发给内部客户的销售订单在对方公司生成对应的采购订单
+// @Tags 内部交易
+// @Accept json
+// @Produce json
+// @Param id path int true "销售订单ID"
+// @Success 201 {object} dto.IntercompanyTransactionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/intercompany/sales-orders/{id}/mirror [post]
+func (c *IntercompanyController) MirrorSalesOrder(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	userID := utils.GetUserIDFromContext(ctx)
+	link, err := c.intercompanyService.MirrorSalesOrder(ctx.Request.Context(), id, userID)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, link)
+}
+
+// MirrorPurchaseOrder 生成内部销售订单
+// @Summary 生成内部销售订单
+// @Description 将向内部供应商下达的采购订单在对方公司生成对应的销售订单
+// @Tags 内部交易
+// @Accept json
+// @Produce json
+// @Param id path int true "采购订单ID"
+// @Success 201 {object} dto.IntercompanyTransactionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/intercompany/purchase-orders/{id}/mirror [post]
+func (c *IntercompanyController) MirrorPurchaseOrder(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	userID := utils.GetUserIDFromContext(ctx)
+	link, err := c.intercompanyService.MirrorPurchaseOrder(ctx.Request.Context(), id, userID)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, link)
+}
+
+// ListTransactions 获取内部交易列表
+// @Summary 获取内部交易列表
+// @Description 分页获取内部交易记录，可按公司、源单据类型与状态筛选
+// @Tags 内部交易
+// @Accept json
+// @Produce json
+// @Param company_id query int false "公司ID"
+// @Param source_doc_type query string false "源单据类型"
+// @Param status query string false "状态"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PaginatedResponse[dto.IntercompanyTransactionResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/intercompany/transactions [get]
+func (c *IntercompanyController) ListTransactions(ctx *gin.Context) {
+	var req dto.IntercompanyTransactionListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	links, total, err := c.intercompanyService.ListTransactions(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, links, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取内部交易列表成功")
+}
+
+// GetTrialBalance 获取试算平衡表
+// @Summary 获取试算平衡表
+// @Description 获取单个公司在指定期间的试算平衡表
+// @Tags 财务报表
+// @Accept json
+// @Produce json
+// @Param company_id query int false "公司ID，默认为默认公司"
+// @Param start_date query string true "开始日期(YYYY-MM-DD)"
+// @Param end_date query string true "结束日期(YYYY-MM-DD)"
+// @Success 200 {object} dto.TrialBalanceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/reports/trial-balance [get]
+func (c *IntercompanyController) GetTrialBalance(ctx *gin.Context) {
+	var req dto.TrialBalanceRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	report, err := c.intercompanyService.GetTrialBalance(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, report)
+}
+
+// GetConsolidatedTrialBalance 获取合并试算平衡表
+// @Summary 获取合并试算平衡表
+// @Description 汇总合并范围内各公司的试算平衡表，并抵销公司之间的内部往来
+// @Tags 财务报表
+// @Accept json
+// @Produce json
+// @Param parent_company_id query int false "上级公司ID，合并其全部下级公司"
+// @Param company_ids query []int false "合并公司ID列表"
+// @Param start_date query string true "开始日期(YYYY-MM-DD)"
+// @Param end_date query string true "结束日期(YYYY-MM-DD)"
+// @Success 200 {object} dto.ConsolidatedTrialBalanceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/reports/consolidated-trial-balance [get]
+func (c *IntercompanyController) GetConsolidatedTrialBalance(ctx *gin.Context) {
+	var req dto.ConsolidatedTrialBalanceRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	report, err := c.intercompanyService.GetConsolidatedTrialBalance(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, report)
+}
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/galaxyerp/galaxyErp/internal/dto"
@@ -14,6 +16,7 @@ type InventoryController struct {
 	stockService     services.StockService
 	warehouseService services.WarehouseService
 	movementService  services.MovementService
+	reportService    services.InventoryReportService
 	utils            *ControllerUtils
 }
 
@@ -23,12 +26,14 @@ func NewInventoryController(
 	stockService services.StockService,
 	warehouseService services.WarehouseService,
 	movementService services.MovementService,
+	reportService services.InventoryReportService,
 ) *InventoryController {
 	return &InventoryController{
 		itemService:      itemService,
 		stockService:     stockService,
 		warehouseService: warehouseService,
 		movementService:  movementService,
+		reportService:    reportService,
 		utils:            NewControllerUtils(),
 	}
 }
@@ -174,12 +179,15 @@ func (c *InventoryController) ListItems(ctx *gin.Context) {
 
 // SearchItems 搜索物料
 // @Summary 搜索物料
-// @Description 搜索物料
+// @Description 按关键字、模板与变体属性取值搜索物料，属性筛选形如 attributes[color]=红
 // @Tags 物料管理
 // @Accept json
 // @Produce json
 // @Param keyword query string false "搜索关键词"
-// @Param category query string false "物料分类"
+// @Param template_id query int false "模板物料ID，只返回其变体"
+// @Param has_variants query bool false "true 只返回模板物料，false 排除模板物料"
+// @Param is_active query bool false "是否启用"
+// @Param attributes query object false "属性编码与取值"
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(10)
 // @Success 200 {object} dto.PaginatedResponse[dto.ItemResponse]
@@ -195,6 +203,17 @@ func (c *InventoryController) SearchItems(ctx *gin.Context) {
 			PaginationRequest: *req,
 			Keyword:          keyword,
 		},
+		Attributes: ctx.QueryMap("attributes"),
+	}
+	if templateID, err := strconv.ParseUint(ctx.Query("template_id"), 10, 32); err == nil {
+		id := uint(templateID)
+		searchReq.TemplateID = &id
+	}
+	if hasVariants, err := strconv.ParseBool(ctx.Query("has_variants")); err == nil {
+		searchReq.HasVariants = &hasVariants
+	}
+	if isActive, err := strconv.ParseBool(ctx.Query("is_active")); err == nil {
+		searchReq.IsActive = &isActive
 	}
 
 	response, err := c.itemService.SearchItems(ctx.Request.Context(), searchReq)
@@ -321,6 +340,60 @@ func (c *InventoryController) DeleteStock(ctx *gin.Context) {
 	c.utils.RespondSuccess(ctx, "删除库存成功")
 }
 
+// CheckStockReconciliation 库存对账检查
+// @Summary 库存对账检查
+// @Description 按库存台账汇总重算库存余额，报告与库存表不一致的记录，不做修改
+// @Tags 库存
+// @Accept json
+// @Produce json
+// @Param item_id query int false "物料ID"
+// @Param warehouse_id query int false "仓库ID"
+// @Success 200 {object} dto.StockReconcileResult
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/stock/reconcile [get]
+func (c *InventoryController) CheckStockReconciliation(ctx *gin.Context) {
+	var req dto.StockReconcileRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+	req.Apply = false
+
+	result, err := c.stockService.ReconcileStock(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, result)
+}
+
+// ReconcileStock 执行库存对账
+// @Summary 执行库存对账
+// @Description 按库存台账汇总重算库存余额，apply 为 true 时修正不一致的库存记录
+// @Tags 库存
+// @Accept json
+// @Produce json
+// @Param request body dto.StockReconcileRequest true "对账范围"
+// @Success 200 {object} dto.StockReconcileResult
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/stock/reconcile [post]
+func (c *InventoryController) ReconcileStock(ctx *gin.Context) {
+	var req dto.StockReconcileRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.stockService.ReconcileStock(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, result)
+}
+
 // 库存移动相关方法 - 占位符实现
 // ListStockMovements 获取库存移动列表
 // @Summary 获取库存移动列表
@@ -493,35 +566,6 @@ func (c *InventoryController) StockAdjustment(ctx *gin.Context) {
 	c.utils.RespondCreated(ctx, movement)
 }
 
-// StockTransfer 库存调拨
-// @Summary 库存调拨
-// @Description 执行库存调拨操作
-// @Tags 库存移动
-// @Accept json
-// @Produce json
-// @Param movement body dto.MovementCreateRequest true "库存调拨信息"
-// @Success 201 {object} dto.MovementResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
-// @Router /api/v1/stock-movements/transfer [post]
-func (c *InventoryController) StockTransfer(ctx *gin.Context) {
-	var req dto.MovementCreateRequest
-	if !c.utils.BindAndValidateJSON(ctx, &req) {
-		return
-	}
-
-	// 强制设置为调拨类型
-	req.Type = "transfer"
-
-	movement, err := c.movementService.CreateMovement(ctx, req)
-	if err != nil {
-		c.utils.RespondInternalError(ctx, "库存调拨失败: "+err.Error())
-		return
-	}
-
-	c.utils.RespondCreated(ctx, movement)
-}
-
 // 仓库管理相关方法
 func (c *InventoryController) ListWarehouses(ctx *gin.Context) {
 	req := c.utils.ParsePaginationParams(ctx)
@@ -602,19 +646,261 @@ func (c *InventoryController) DeleteWarehouse(ctx *gin.Context) {
 	c.utils.RespondSuccess(ctx, "删除仓库成功")
 }
 
-// 库存报告和统计相关方法
+// GetInventoryStats 获取库存统计
+// @Summary 获取库存统计
+// @Description 按仓库统计物料数、库存数量、库存金额和低库存物料数
+// @Tags 库存报表
+// @Accept json
+// @Produce json
+// @Param warehouse_id query int false "仓库ID"
+// @Success 200 {object} dto.InventoryStatsResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/inventory-reports/stats [get]
 func (c *InventoryController) GetInventoryStats(ctx *gin.Context) {
-	c.utils.RespondNotImplemented(ctx, "功能暂未实现")
+	var req dto.InventoryStatsRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	stats, err := c.reportService.GetInventoryStats(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, stats)
 }
 
+// GetInventoryReport 获取库存报表
+// @Summary 获取库存报表
+// @Description 分页获取物料在各仓库的库存数量与金额，可按物料、仓库、类别、关键字和低库存筛选
+// @Tags 库存报表
+// @Accept json
+// @Produce json
+// @Param item_id query int false "物料ID"
+// @Param warehouse_id query int false "仓库ID"
+// @Param category query string false "物料类别"
+// @Param keyword query string false "物料编码或名称"
+// @Param low_stock_only query bool false "仅低库存"
+// @Param include_zero query bool false "包含零库存"
+// @Param group_by_template query bool false "变体库存汇总到模板物料"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PaginatedResponse[dto.InventoryReportLine]
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/inventory-reports/report [get]
 func (c *InventoryController) GetInventoryReport(ctx *gin.Context) {
-	c.utils.RespondNotImplemented(ctx, "功能暂未实现")
+	var req dto.InventoryReportRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	lines, total, err := c.reportService.GetInventoryReport(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, lines, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取库存报表成功")
 }
 
+// GetABCAnalysis 获取ABC分析
+// @Summary 获取ABC分析
+// @Description 按统计期间的出库成本对物料进行 ABC 分类，期间与分类阈值可配置
+// @Tags 库存报表
+// @Accept json
+// @Produce json
+// @Param start_date query string false "开始日期(YYYY-MM-DD)"
+// @Param end_date query string false "结束日期(YYYY-MM-DD)，默认今天"
+// @Param days query int false "未指定开始日期时的统计天数" default(365)
+// @Param warehouse_id query int false "仓库ID"
+// @Param category query string false "物料类别"
+// @Param a_threshold query number false "A类累计占比阈值(%)" default(80)
+// @Param b_threshold query number false "B类累计占比阈值(%)" default(95)
+// @Param group_by_template query bool false "变体消耗汇总到模板物料"
+// @Success 200 {object} dto.ABCAnalysisResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/inventory-reports/abc-analysis [get]
 func (c *InventoryController) GetABCAnalysis(ctx *gin.Context) {
-	c.utils.RespondNotImplemented(ctx, "功能暂未实现")
+	var req dto.ABCAnalysisRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	analysis, err := c.reportService.GetABCAnalysis(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, analysis)
 }
 
+// GetInventoryAging 获取库存账龄
+// @Summary 获取库存账龄
+// @Description 按入库时间将物料在各仓库的在库库存划入账龄段，在库数量按先进先出归属到最近的入库
+// @Tags 库存报表
+// @Accept json
+// @Produce json
+// @Param item_id query int false "物料ID"
+// @Param warehouse_id query int false "仓库ID"
+// @Param category query string false "物料类别"
+// @Param keyword query string false "物料编码或名称"
+// @Param buckets query string false "逗号分隔的账龄段上限天数" default(30,60,90,180)
+// @Success 200 {object} dto.InventoryAgingResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/inventory-reports/aging [get]
+func (c *InventoryController) GetInventoryAging(ctx *gin.Context) {
+	var req dto.InventoryAgingRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	aging, err := c.reportService.GetInventoryAging(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, aging)
+}
+
+// GetSlowMovingStock 获取呆滞库存
+// @Summary 获取呆滞库存
+// @Description 列出超过滞销天数没有出库的在库库存及其账面金额，超过呆滞天数的标记为呆滞
+// @Tags 库存报表
+// @Accept json
+// @Produce json
+// @Param item_id query int false "物料ID"
+// @Param warehouse_id query int false "仓库ID"
+// @Param category query string false "物料类别"
+// @Param keyword query string false "物料编码或名称"
+// @Param slow_days query int false "滞销天数" default(90)
+// @Param dead_days query int false "呆滞天数" default(180)
+// @Success 200 {object} dto.SlowMovingResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/inventory-reports/slow-moving [get]
+func (c *InventoryController) GetSlowMovingStock(ctx *gin.Context) {
+	var req dto.SlowMovingRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	slowMoving, err := c.reportService.GetSlowMovingStock(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, slowMoving)
+}
+
+// GetWriteDownProposal 获取存货减值建议
+// @Summary 获取存货减值建议
+// @Description 按滞销、呆滞库存的账面金额与减值比例计算减值金额，并给出可提交到会计凭证接口的凭证草稿
+// @Tags 库存报表
+// @Accept json
+// @Produce json
+// @Param item_id query int false "物料ID"
+// @Param warehouse_id query int false "仓库ID"
+// @Param category query string false "物料类别"
+// @Param keyword query string false "物料编码或名称"
+// @Param slow_days query int false "滞销天数" default(90)
+// @Param dead_days query int false "呆滞天数" default(180)
+// @Param slow_percent query number false "滞销库存减值比例(%)" default(50)
+// @Param dead_percent query number false "呆滞库存减值比例(%)" default(100)
+// @Param company_id query int false "公司ID"
+// @Param expense_account_code query string false "减值损失科目编码" default(5711)
+// @Param provision_account_code query string false "存货跌价准备科目编码" default(1471)
+// @Success 200 {object} dto.InventoryWriteDownResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/inventory-reports/write-down-proposal [get]
+func (c *InventoryController) GetWriteDownProposal(ctx *gin.Context) {
+	var req dto.InventoryWriteDownRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	proposal, err := c.reportService.GetWriteDownProposal(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, proposal)
+}
+
+// ExportInventoryReport 导出库存报表
+// @Summary 导出库存报表
+// @Description 将库存统计、库存报表、ABC 分析、库存账龄、呆滞库存或存货减值建议导出为 CSV 或 XLSX，筛选参数与对应报表相同
+// @Tags 库存报表
+// @Produce octet-stream
+// @Param type query string false "报表类型(stats/report/abc/aging/slow_moving/write_down)" default(report)
+// @Param format query string false "导出格式(csv/xlsx)" default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/inventory-reports/export [get]
 func (c *InventoryController) ExportInventoryReport(ctx *gin.Context) {
-	c.utils.RespondNotImplemented(ctx, "功能暂未实现")
+	var req dto.InventoryExportRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+	if req.Type == "" {
+		req.Type = "report"
+	}
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+
+	var data []byte
+	var err error
+	switch req.Type {
+	case "stats":
+		var statsReq dto.InventoryStatsRequest
+		if !c.utils.BindAndValidateQuery(ctx, &statsReq) {
+			return
+		}
+		data, err = c.reportService.ExportStats(ctx.Request.Context(), &statsReq, req.Format)
+	case "abc":
+		var abcReq dto.ABCAnalysisRequest
+		if !c.utils.BindAndValidateQuery(ctx, &abcReq) {
+			return
+		}
+		data, err = c.reportService.ExportABCAnalysis(ctx.Request.Context(), &abcReq, req.Format)
+	case "aging":
+		var agingReq dto.InventoryAgingRequest
+		if !c.utils.BindAndValidateQuery(ctx, &agingReq) {
+			return
+		}
+		data, err = c.reportService.ExportAging(ctx.Request.Context(), &agingReq, req.Format)
+	case "slow_moving":
+		var slowReq dto.SlowMovingRequest
+		if !c.utils.BindAndValidateQuery(ctx, &slowReq) {
+			return
+		}
+		data, err = c.reportService.ExportSlowMoving(ctx.Request.Context(), &slowReq, req.Format)
+	case "write_down":
+		var writeDownReq dto.InventoryWriteDownRequest
+		if !c.utils.BindAndValidateQuery(ctx, &writeDownReq) {
+			return
+		}
+		data, err = c.reportService.ExportWriteDownProposal(ctx.Request.Context(), &writeDownReq, req.Format)
+	default:
+		var filter dto.InventoryReportFilter
+		if !c.utils.BindAndValidateQuery(ctx, &filter) {
+			return
+		}
+		data, err = c.reportService.ExportReport(ctx.Request.Context(), &filter, req.Format)
+	}
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if req.Format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=inventory_%s.%s", req.Type, req.Format))
+	ctx.Data(http.StatusOK, contentType, data)
 }
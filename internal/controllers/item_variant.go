@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ItemVariantController 物料属性与变体控制器
+type ItemVariantController struct {
+	attributeService services.ItemAttributeService
+	itemService      services.ItemService
+	utils            *ControllerUtils
+}
+
+// NewItemVariantController 创建物料属性与变体控制器实例
+func NewItemVariantController(attributeService services.ItemAttributeService, itemService services.ItemService) *ItemVariantController {
+	return &ItemVariantController{
+		attributeService: attributeService,
+		itemService:      itemService,
+		utils:            NewControllerUtils(),
+	}
+}
+
+// CreateAttribute 创建物料属性
+// @Summary 创建物料属性
+// @Description 创建尺码、颜色、材质等物料属性及其取值，取值缩写用于拼接变体编码
+// @Tags 物料变体
+// @Accept json
+// @Produce json
+// @Param request body dto.ItemAttributeCreateRequest true "物料属性信息"
+// @Success 201 {object} dto.ItemAttributeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/item-attributes [post]
+func (c *ItemVariantController) CreateAttribute(ctx *gin.Context) {
+	var req dto.ItemAttributeCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	attribute, err := c.attributeService.CreateAttribute(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, attribute)
+}
+
+// ListAttributes 获取物料属性列表
+// @Summary 获取物料属性列表
+// @Description 获取全部物料属性及其取值
+// @Tags 物料变体
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.ItemAttributeResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/item-attributes [get]
+func (c *ItemVariantController) ListAttributes(ctx *gin.Context) {
+	attributes, err := c.attributeService.ListAttributes(ctx.Request.Context())
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, attributes)
+}
+
+// GetAttribute 获取物料属性详情
+// @Summary 获取物料属性详情
+// @Description 根据ID获取物料属性及其取值
+// @Tags 物料变体
+// @Accept json
+// @Produce json
+// @Param id path int true "物料属性ID"
+// @Success 200 {object} dto.ItemAttributeResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/item-attributes/{id} [get]
+func (c *ItemVariantController) GetAttribute(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	attribute, err := c.attributeService.GetAttribute(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, attribute)
+}
+
+// UpdateAttribute 更新物料属性
+// @Summary 更新物料属性
+// @Description 更新物料属性名称与描述，属性编码不能修改
+// @Tags 物料变体
+// @Accept json
+// @Produce json
+// @Param id path int true "物料属性ID"
+// @Param request body dto.ItemAttributeUpdateRequest true "物料属性信息"
+// @Success 200 {object} dto.ItemAttributeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/item-attributes/{id} [put]
+func (c *ItemVariantController) UpdateAttribute(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.ItemAttributeUpdateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	attribute, err := c.attributeService.UpdateAttribute(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, attribute)
+}
+
+// DeleteAttribute 删除物料属性
+// @Summary 删除物料属性
+// @Description 删除未被模板物料使用的物料属性及其取值
+// @Tags 物料变体
+// @Accept json
+// @Produce json
+// @Param id path int true "物料属性ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/item-attributes/{id} [delete]
+func (c *ItemVariantController) DeleteAttribute(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.attributeService.DeleteAttribute(ctx.Request.Context(), id); err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, gin.H{"message": "物料属性删除成功"})
+}
+
+// AddAttributeValue 增加物料属性取值
+// @Summary 增加物料属性取值
+// @Description 为物料属性增加取值，模板物料可再次生成变体以补充新取值的组合
+// @Tags 物料变体
+// @Accept json
+// @Produce json
+// @Param id path int true "物料属性ID"
+// @Param request body dto.ItemAttributeValueRequest true "属性取值"
+// @Success 200 {object} dto.ItemAttributeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/item-attributes/{id}/values [post]
+func (c *ItemVariantController) AddAttributeValue(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.ItemAttributeValueRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	attribute, err := c.attributeService.AddAttributeValue(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, attribute)
+}
+
+// DeleteAttributeValue 删除物料属性取值
+// @Summary 删除物料属性取值
+// @Description 删除未被变体使用的物料属性取值
+// @Tags 物料变体
+// @Accept json
+// @Produce json
+// @Param id path int true "物料属性ID"
+// @Param value_id path int true "属性取值ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/item-attributes/{id}/values/{value_id} [delete]
+func (c *ItemVariantController) DeleteAttributeValue(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	valueID, ok := c.utils.ParseIDParam(ctx, "value_id")
+	if !ok {
+		return
+	}
+
+	if err := c.attributeService.DeleteAttributeValue(ctx.Request.Context(), id, valueID); err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, gin.H{"message": "物料属性取值删除成功"})
+}
+
+// SetTemplateAttributes 设置模板属性
+// @Summary 设置模板属性
+// @Description 设置物料作为模板使用的属性，属性顺序决定变体编码中的顺序；已有变体的模板不能修改
+// @Tags 物料变体
+// @Accept json
+// @Produce json
+// @Param id path int true "物料ID"
+// @Param request body dto.ItemTemplateAttributesRequest true "模板属性"
+// @Success 200 {object} dto.ItemTemplateResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/items/{id}/template-attributes [put]
+func (c *ItemVariantController) SetTemplateAttributes(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.ItemTemplateAttributesRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	template, err := c.itemService.SetTemplateAttributes(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, template)
+}
+
+// GetVariants 获取模板物料的变体
+// @Summary 获取模板物料的变体
+// @Description 获取模板物料的属性、全部变体及各变体的库存合计
+// @Tags 物料变体
+// @Accept json
+// @Produce json
+// @Param id path int true "模板物料ID"
+// @Success 200 {object} dto.ItemTemplateResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/items/{id}/variants [get]
+func (c *ItemVariantController) GetVariants(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	template, err := c.itemService.GetItemTemplate(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, template)
+}
+
+// GenerateVariants 生成变体物料
+// @Summary 生成变体物料
+// @Description 按模板属性取值的组合生成变体物料，未指定的属性使用全部取值，已存在的组合跳过
+// @Tags 物料变体
+// @Accept json
+// @Produce json
+// @Param id path int true "模板物料ID"
+// @Param request body dto.ItemVariantGenerateRequest false "取值范围与价格"
+// @Success 201 {object} dto.ItemVariantGenerateResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/items/{id}/variants [post]
+func (c *ItemVariantController) GenerateVariants(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.ItemVariantGenerateRequest
+	if ctx.Request.ContentLength != 0 && !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.itemService.GenerateVariants(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, result)
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LandedCostController 到岸成本控制器
+type LandedCostController struct {
+	landedCostService services.LandedCostService
+	utils             *ControllerUtils
+}
+
+// NewLandedCostController 创建到岸成本控制器实例
+func NewLandedCostController(landedCostService services.LandedCostService) *LandedCostController {
+	return &LandedCostController{
+		landedCostService: landedCostService,
+		utils:             NewControllerUtils(),
+	}
+}
+
+// CreateLandedCost 创建到岸成本单
+// @Summary 创建到岸成本单
+// @Description 将运费、关税、保险等费用按数量、金额、重量或手工金额分摊到一张或多张采购收货单的收货明细
+// @Tags 到岸成本
+// @Accept json
+// @Produce json
+// @Param request body dto.LandedCostCreateRequest true "到岸成本单信息"
+// @Success 201 {object} dto.LandedCostResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/landed-cost-vouchers [post]
+func (c *LandedCostController) CreateLandedCost(ctx *gin.Context) {
+	var req dto.LandedCostCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	voucher, err := c.landedCostService.CreateLandedCost(ctx.Request.Context(), &req, utils.GetUserIDFromContext(ctx))
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, voucher)
+}
+
+// ListLandedCosts 获取到岸成本单列表
+// @Summary 获取到岸成本单列表
+// @Description 分页获取到岸成本单，可按状态与采购收货单筛选
+// @Tags 到岸成本
+// @Accept json
+// @Produce json
+// @Param status query string false "状态 draft/submitted/cancelled"
+// @Param purchase_receipt_id query int false "采购收货单ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PaginatedResponse[dto.LandedCostResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/landed-cost-vouchers [get]
+func (c *LandedCostController) ListLandedCosts(ctx *gin.Context) {
+	var req dto.LandedCostListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	vouchers, total, err := c.landedCostService.ListLandedCosts(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, vouchers, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取到岸成本单成功")
+}
+
+// GetLandedCost 获取到岸成本单详情
+// @Summary 获取到岸成本单详情
+// @Description 获取到岸成本单的费用、各收货明细的分摊金额以及提交后计入库存与销售成本的金额
+// @Tags 到岸成本
+// @Accept json
+// @Produce json
+// @Param id path int true "到岸成本单ID"
+// @Success 200 {object} dto.LandedCostResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/landed-cost-vouchers/{id} [get]
+func (c *LandedCostController) GetLandedCost(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	voucher, err := c.landedCostService.GetLandedCost(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, voucher)
+}
+
+// SubmitLandedCost 提交到岸成本单
+// @Summary 提交到岸成本单
+// @Description 仍在库的分摊金额计入库存金额并提高先进先出成本层单价，已出库部分计入销售成本，同时生成总账凭证
+// @Tags 到岸成本
+// @Accept json
+// @Produce json
+// @Param id path int true "到岸成本单ID"
+// @Param request body dto.LandedCostSubmitRequest false "过账科目"
+// @Success 200 {object} dto.LandedCostResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/landed-cost-vouchers/{id}/submit [post]
+func (c *LandedCostController) SubmitLandedCost(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.LandedCostSubmitRequest
+	if ctx.Request.ContentLength != 0 && !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	voucher, err := c.landedCostService.SubmitLandedCost(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, voucher)
+}
+
+// CancelLandedCost 取消到岸成本单
+// @Summary 取消到岸成本单
+// @Description 取消草稿状态的到岸成本单
+// @Tags 到岸成本
+// @Accept json
+// @Produce json
+// @Param id path int true "到岸成本单ID"
+// @Success 200 {object} dto.LandedCostResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/landed-cost-vouchers/{id}/cancel [post]
+func (c *LandedCostController) CancelLandedCost(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	voucher, err := c.landedCostService.CancelLandedCost(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, voucher)
+}
@@ -0,0 +1,450 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// LocationController 库位、上架与拣货控制器
+type LocationController struct {
+	locationService services.LocationService
+	utils           *ControllerUtils
+}
+
+// NewLocationController 创建库位控制器实例
+func NewLocationController(locationService services.LocationService) *LocationController {
+	return &LocationController{
+		locationService: locationService,
+		utils:           NewControllerUtils(),
+	}
+}
+
+// CreateLocation 创建库位
+// @Summary 创建库位
+// @Description 在仓库下创建库位，编码在仓库内唯一，容量为 0 表示不限
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param id path int true "仓库ID"
+// @Param location body dto.LocationCreateRequest true "库位信息"
+// @Success 201 {object} dto.LocationResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/warehouses/{id}/locations [post]
+func (c *LocationController) CreateLocation(ctx *gin.Context) {
+	warehouseID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.LocationCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	location, err := c.locationService.CreateLocation(ctx.Request.Context(), warehouseID, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, location)
+}
+
+// ListLocations 获取仓库的库位列表
+// @Summary 获取仓库的库位列表
+// @Description 按顺序号获取仓库的库位及已占用数量
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param id path int true "仓库ID"
+// @Success 200 {array} dto.LocationResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/warehouses/{id}/locations [get]
+func (c *LocationController) ListLocations(ctx *gin.Context) {
+	warehouseID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	locations, err := c.locationService.ListLocations(ctx.Request.Context(), warehouseID)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, locations)
+}
+
+// GetLocation 获取库位详情
+// @Summary 获取库位详情
+// @Description 获取仓库下的库位及已占用数量
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param id path int true "仓库ID"
+// @Param location_id path int true "库位ID"
+// @Success 200 {object} dto.LocationResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/warehouses/{id}/locations/{location_id} [get]
+func (c *LocationController) GetLocation(ctx *gin.Context) {
+	warehouseID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	id, ok := c.utils.ParseIDParam(ctx, "location_id")
+	if !ok {
+		return
+	}
+
+	location, err := c.locationService.GetLocation(ctx.Request.Context(), warehouseID, id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, location)
+}
+
+// UpdateLocation 更新库位
+// @Summary 更新库位
+// @Description 更新库位名称、类型、容量、顺序号与启用状态，容量不能小于已占用数量
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param id path int true "仓库ID"
+// @Param location_id path int true "库位ID"
+// @Param location body dto.LocationUpdateRequest true "库位信息"
+// @Success 200 {object} dto.LocationResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/warehouses/{id}/locations/{location_id} [put]
+func (c *LocationController) UpdateLocation(ctx *gin.Context) {
+	warehouseID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	id, ok := c.utils.ParseIDParam(ctx, "location_id")
+	if !ok {
+		return
+	}
+
+	var req dto.LocationUpdateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	location, err := c.locationService.UpdateLocation(ctx.Request.Context(), warehouseID, id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, location)
+}
+
+// DeleteLocation 删除库位
+// @Summary 删除库位
+// @Description 删除没有库存且未被上架规则引用的库位
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param id path int true "仓库ID"
+// @Param location_id path int true "库位ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/warehouses/{id}/locations/{location_id} [delete]
+func (c *LocationController) DeleteLocation(ctx *gin.Context) {
+	warehouseID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	id, ok := c.utils.ParseIDParam(ctx, "location_id")
+	if !ok {
+		return
+	}
+
+	if err := c.locationService.DeleteLocation(ctx.Request.Context(), warehouseID, id); err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, gin.H{"message": "库位删除成功"})
+}
+
+// GetLocationStock 获取库位库存
+// @Summary 获取库位库存
+// @Description 按库位、批次获取仓库库存，未指定库位时附带尚未分配库位的库存
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param id path int true "仓库ID"
+// @Param location_id query int false "库位ID"
+// @Param item_id query int false "物料ID"
+// @Success 200 {object} dto.LocationStockResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/warehouses/{id}/location-stock [get]
+func (c *LocationController) GetLocationStock(ctx *gin.Context) {
+	warehouseID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.LocationStockRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	stock, err := c.locationService.GetLocationStock(ctx.Request.Context(), warehouseID, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, stock)
+}
+
+// SuggestPutaway 获取上架建议
+// @Summary 获取上架建议
+// @Description 按固定库位、物料类别与通用上架规则及库位剩余容量为入库数量分配库位
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param id path int true "仓库ID"
+// @Param request body dto.PutawaySuggestionRequest true "入库物料与数量"
+// @Success 200 {object} dto.PutawaySuggestionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/warehouses/{id}/putaway-suggestions [post]
+func (c *LocationController) SuggestPutaway(ctx *gin.Context) {
+	warehouseID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.PutawaySuggestionRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	suggestion, err := c.locationService.SuggestPutaway(ctx.Request.Context(), warehouseID, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, suggestion)
+}
+
+// CreatePutawayRule 创建上架规则
+// @Summary 创建上架规则
+// @Description 创建固定库位、物料类别或通用上架规则
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param rule body dto.PutawayRuleCreateRequest true "上架规则"
+// @Success 201 {object} dto.PutawayRuleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/putaway-rules [post]
+func (c *LocationController) CreatePutawayRule(ctx *gin.Context) {
+	var req dto.PutawayRuleCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	rule, err := c.locationService.CreatePutawayRule(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, rule)
+}
+
+// ListPutawayRules 获取上架规则列表
+// @Summary 获取上架规则列表
+// @Description 获取上架规则，可按仓库筛选
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param warehouse_id query int false "仓库ID"
+// @Success 200 {array} dto.PutawayRuleResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/putaway-rules [get]
+func (c *LocationController) ListPutawayRules(ctx *gin.Context) {
+	var req dto.PutawayRuleListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	rules, err := c.locationService.ListPutawayRules(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, rules)
+}
+
+// UpdatePutawayRule 更新上架规则
+// @Summary 更新上架规则
+// @Description 更新上架规则的库位、优先级、启用状态与备注
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param id path int true "上架规则ID"
+// @Param rule body dto.PutawayRuleUpdateRequest true "上架规则"
+// @Success 200 {object} dto.PutawayRuleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/putaway-rules/{id} [put]
+func (c *LocationController) UpdatePutawayRule(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.PutawayRuleUpdateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	rule, err := c.locationService.UpdatePutawayRule(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, rule)
+}
+
+// DeletePutawayRule 删除上架规则
+// @Summary 删除上架规则
+// @Description 删除上架规则
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param id path int true "上架规则ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/putaway-rules/{id} [delete]
+func (c *LocationController) DeletePutawayRule(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.locationService.DeletePutawayRule(ctx.Request.Context(), id); err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, gin.H{"message": "上架规则删除成功"})
+}
+
+// MoveStock 库位间移库
+// @Summary 库位间移库
+// @Description 在同一仓库内将库存从一个库位移到另一库位，调出库位为空表示上架未分配库存，调入库位为空表示下架
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param move body dto.LocationMoveRequest true "移库信息"
+// @Success 201 {object} dto.LocationMoveResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/location-moves [post]
+func (c *LocationController) MoveStock(ctx *gin.Context) {
+	var req dto.LocationMoveRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	move, err := c.locationService.MoveStock(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, move)
+}
+
+// QuarantineExpired 过期批次移入隔离库位
+// @Summary 过期批次移入隔离库位
+// @Description 将已过期批次在隔离库位以外的库存（含未分配库位的库存）移入所在仓库的隔离库位，未设置隔离库位的仓库跳过
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param request body dto.ExpiryQuarantineRequest false "仓库范围"
+// @Success 200 {object} dto.ExpiryQuarantineResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/location-moves/quarantine-expired [post]
+func (c *LocationController) QuarantineExpired(ctx *gin.Context) {
+	var req dto.ExpiryQuarantineRequest
+	if ctx.Request.ContentLength != 0 && !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.locationService.QuarantineExpiredStock(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, result)
+}
+
+// ListLocationMoves 获取移库记录
+// @Summary 获取移库记录
+// @Description 分页获取库位间移库记录，可按仓库、物料与库位筛选
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param warehouse_id query int false "仓库ID"
+// @Param item_id query int false "物料ID"
+// @Param location_id query int false "库位ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PaginatedResponse[dto.LocationMoveResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/location-moves [get]
+func (c *LocationController) ListLocationMoves(ctx *gin.Context) {
+	var req dto.LocationMoveListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	moves, total, err := c.locationService.ListLocationMoves(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, moves, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取移库记录成功")
+}
+
+// GetPickingList 获取送货单拣货建议
+// @Summary 获取送货单拣货建议
+// @Description 按 FIFO 或 FEFO 策略跨库位为送货单各行分配拣货库位与批次
+// @Tags 库位管理
+// @Accept json
+// @Produce json
+// @Param id path int true "送货单ID"
+// @Param strategy query string false "拣货策略 fifo/fefo，默认批次物料按 fefo，其余按 fifo"
+// @Success 200 {object} dto.PickingListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/delivery-notes/{id}/picking-list [get]
+func (c *LocationController) GetPickingList(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.PickingListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	pickingList, err := c.locationService.GetPickingList(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, pickingList)
+}
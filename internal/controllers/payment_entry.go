@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentEntryController 收付款控制器
+type PaymentEntryController struct {
+	paymentEntryService services.PaymentEntryService
+	utils               *ControllerUtils
+}
+
+// NewPaymentEntryController 创建收付款控制器实例
+func NewPaymentEntryController(paymentEntryService services.PaymentEntryService) *PaymentEntryController {
+	return &PaymentEntryController{
+		paymentEntryService: paymentEntryService,
+		utils:               NewControllerUtils(),
+	}
+}
+
+// CreatePaymentEntry 创建付款
+// @Summary 创建付款
+// @Description 创建草稿状态的收款或付款，可同时指定核销的发票或应付单据
+// @Tags 收付款
+// @Accept json
+// @Produce json
+// @Param payment body dto.PaymentEntryCreateRequest true "付款信息"
+// @Success 201 {object} dto.PaymentEntryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/payment-entries [post]
+func (c *PaymentEntryController) CreatePaymentEntry(ctx *gin.Context) {
+	var req dto.PaymentEntryCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	payment, err := c.paymentEntryService.CreatePaymentEntryFromDTO(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, payment)
+}
+
+// GetPaymentEntry 获取付款详情
+// @Summary 获取付款详情
+// @Description 获取付款及其分配明细
+// @Tags 收付款
+// @Accept json
+// @Produce json
+// @Param id path int true "付款ID"
+// @Success 200 {object} dto.PaymentEntryResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/payment-entries/{id} [get]
+func (c *PaymentEntryController) GetPaymentEntry(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	payment, err := c.paymentEntryService.GetPaymentEntryDetail(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, payment)
+}
+
+// ListPaymentEntries 获取付款列表
+// @Summary 获取付款列表
+// @Description 分页获取付款列表，可按关联方筛选
+// @Tags 收付款
+// @Accept json
+// @Produce json
+// @Param party_type query string false "关联方类型(Customer/Supplier)"
+// @Param party_id query int false "关联方ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PaginatedResponse[dto.PaymentEntryResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/payment-entries [get]
+func (c *PaymentEntryController) ListPaymentEntries(ctx *gin.Context) {
+	var req dto.PaymentEntryListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	payments, total, err := c.paymentEntryService.ListPaymentEntriesFromDTO(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, payments, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取付款列表成功")
+}
+
+// AllocatePayment 分配付款
+// @Summary 分配付款
+// @Description 将付款金额分配到多张发票或应付单据，支持部分核销；已提交付款的未核销余额立即核销
+// @Tags 收付款
+// @Accept json
+// @Produce json
+// @Param id path int true "付款ID"
+// @Param allocations body dto.PaymentAllocateRequest true "分配明细"
+// @Success 200 {object} dto.PaymentEntryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /api/v1/payment-entries/{id}/allocations [post]
+func (c *PaymentEntryController) AllocatePayment(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.PaymentAllocateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	payment, err := c.paymentEntryService.AllocatePayment(ctx.Request.Context(), id, &req)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentOverAllocated) {
+			c.utils.RespondConflict(ctx, err.Error())
+			return
+		}
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, payment)
+}
+
+// SubmitPaymentEntry 提交付款
+// @Summary 提交付款
+// @Description 生成过账凭证，并将待核销分配更新到发票未付金额与应付单据已付金额
+// @Tags 收付款
+// @Accept json
+// @Produce json
+// @Param id path int true "付款ID"
+// @Success 200 {object} dto.PaymentEntryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /api/v1/payment-entries/{id}/submit [post]
+func (c *PaymentEntryController) SubmitPaymentEntry(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	payment, err := c.paymentEntryService.SubmitPaymentEntry(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentEntryStatusChanged) {
+			c.utils.RespondConflict(ctx, err.Error())
+			return
+		}
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, payment)
+}
+
+// CancelPaymentEntry 取消付款
+// @Summary 取消付款
+// @Description 取消付款，已提交的付款冲回凭证及已核销金额
+// @Tags 收付款
+// @Accept json
+// @Produce json
+// @Param id path int true "付款ID"
+// @Success 200 {object} dto.PaymentEntryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /api/v1/payment-entries/{id}/cancel [post]
+func (c *PaymentEntryController) CancelPaymentEntry(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	payment, err := c.paymentEntryService.CancelPaymentEntry(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentEntryStatusChanged) {
+			c.utils.RespondConflict(ctx, err.Error())
+			return
+		}
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, payment)
+}
+
+// ListUnallocatedPayments 获取未核销付款
+// @Summary 获取未核销付款
+// @Description 获取客户预收或供应商预付中尚未核销的余额，可用于后续发票核销
+// @Tags 收付款
+// @Accept json
+// @Produce json
+// @Param company_id query int false "公司ID"
+// @Param party_type query string true "关联方类型(Customer/Supplier)"
+// @Param party_id query int true "关联方ID"
+// @Success 200 {array} dto.PaymentEntryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/payment-entries/unallocated [get]
+func (c *PaymentEntryController) ListUnallocatedPayments(ctx *gin.Context) {
+	var req dto.UnallocatedPaymentsRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	payments, err := c.paymentEntryService.ListUnallocatedPayments(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, payments)
+}
@@ -0,0 +1,401 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// PriceListController 价格表控制器
+type PriceListController struct {
+	priceListService services.PriceListService
+	utils            *ControllerUtils
+}
+
+// NewPriceListController 创建价格表控制器实例
+func NewPriceListController(priceListService services.PriceListService) *PriceListController {
+	return &PriceListController{
+		priceListService: priceListService,
+		utils:            NewControllerUtils(),
+	}
+}
+
+// CreatePriceList 创建价格表
+// @Summary 创建价格表
+// @Description 创建销售或采购价格表；同类型只能有一个默认价格表，设为默认时取消其他默认
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param request body dto.PriceListCreateRequest true "价格表信息"
+// @Success 201 {object} dto.PriceListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists [post]
+func (c *PriceListController) CreatePriceList(ctx *gin.Context) {
+	var req dto.PriceListCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	priceList, err := c.priceListService.CreatePriceList(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, priceList)
+}
+
+// ListPriceLists 获取价格表列表
+// @Summary 获取价格表列表
+// @Description 按类型与启用状态分页获取价格表
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param price_list_type query string false "价格表类型" Enums(selling, buying)
+// @Param is_active query bool false "是否启用"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} dto.PaginatedResponse[dto.PriceListResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists [get]
+func (c *PriceListController) ListPriceLists(ctx *gin.Context) {
+	var req dto.PriceListListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	priceLists, total, err := c.priceListService.ListPriceLists(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, priceLists, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取价格表成功")
+}
+
+// GetPriceList 获取价格表
+// @Summary 获取价格表
+// @Description 获取价格表详情
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param id path int true "价格表ID"
+// @Success 200 {object} dto.PriceListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists/{id} [get]
+func (c *PriceListController) GetPriceList(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	priceList, err := c.priceListService.GetPriceList(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, priceList)
+}
+
+// UpdatePriceList 更新价格表
+// @Summary 更新价格表
+// @Description 更新价格表名称、币种、有效期、默认与启用状态
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param id path int true "价格表ID"
+// @Param request body dto.PriceListUpdateRequest true "价格表信息"
+// @Success 200 {object} dto.PriceListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists/{id} [put]
+func (c *PriceListController) UpdatePriceList(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.PriceListUpdateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	priceList, err := c.priceListService.UpdatePriceList(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, priceList)
+}
+
+// DeletePriceList 删除价格表
+// @Summary 删除价格表
+// @Description 删除价格表及其物料价格与分配；已被单据引用的价格表不能删除
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param id path int true "价格表ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists/{id} [delete]
+func (c *PriceListController) DeletePriceList(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.priceListService.DeletePriceList(ctx.Request.Context(), id); err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, gin.H{"message": "价格表删除成功"})
+}
+
+// ListItemPrices 获取物料价格
+// @Summary 获取物料价格
+// @Description 分页获取价格表中的物料价格，可按物料筛选
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param id path int true "价格表ID"
+// @Param item_id query int false "物料ID"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} dto.PaginatedResponse[dto.ItemPriceResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists/{id}/prices [get]
+func (c *PriceListController) ListItemPrices(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.ItemPriceListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	prices, total, err := c.priceListService.ListItemPrices(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, prices, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取物料价格成功")
+}
+
+// AddItemPrice 添加物料价格
+// @Summary 添加物料价格
+// @Description 按单位与起订数量添加物料价格，同一物料可登记多档数量价格
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param id path int true "价格表ID"
+// @Param request body dto.ItemPriceRequest true "物料价格"
+// @Success 201 {object} dto.ItemPriceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists/{id}/prices [post]
+func (c *PriceListController) AddItemPrice(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.ItemPriceRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	price, err := c.priceListService.AddItemPrice(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, price)
+}
+
+// UpdateItemPrice 更新物料价格
+// @Summary 更新物料价格
+// @Description 更新价格表中的物料价格
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param id path int true "价格表ID"
+// @Param price_id path int true "物料价格ID"
+// @Param request body dto.ItemPriceRequest true "物料价格"
+// @Success 200 {object} dto.ItemPriceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists/{id}/prices/{price_id} [put]
+func (c *PriceListController) UpdateItemPrice(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	priceID, ok := c.utils.ParseIDParam(ctx, "price_id")
+	if !ok {
+		return
+	}
+
+	var req dto.ItemPriceRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	price, err := c.priceListService.UpdateItemPrice(ctx.Request.Context(), id, priceID, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, price)
+}
+
+// DeleteItemPrice 删除物料价格
+// @Summary 删除物料价格
+// @Description 删除价格表中的物料价格
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param id path int true "价格表ID"
+// @Param price_id path int true "物料价格ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists/{id}/prices/{price_id} [delete]
+func (c *PriceListController) DeleteItemPrice(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	priceID, ok := c.utils.ParseIDParam(ctx, "price_id")
+	if !ok {
+		return
+	}
+
+	if err := c.priceListService.DeleteItemPrice(ctx.Request.Context(), id, priceID); err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, gin.H{"message": "物料价格删除成功"})
+}
+
+// ListAssignments 获取价格表分配
+// @Summary 获取价格表分配
+// @Description 获取价格表分配的客户、客户组或供应商
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param id path int true "价格表ID"
+// @Success 200 {array} dto.PriceListAssignmentResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists/{id}/assignments [get]
+func (c *PriceListController) ListAssignments(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	assignments, err := c.priceListService.ListAssignments(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, assignments)
+}
+
+// AssignPriceList 分配价格表
+// @Summary 分配价格表
+// @Description 将销售价格表分配给客户或客户组、采购价格表分配给供应商；已分配同类型价格表的对象改用新价格表
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param id path int true "价格表ID"
+// @Param request body dto.PriceListAssignmentRequest true "分配对象"
+// @Success 201 {object} dto.PriceListAssignmentResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists/{id}/assignments [post]
+func (c *PriceListController) AssignPriceList(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.PriceListAssignmentRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	assignment, err := c.priceListService.AssignPriceList(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, assignment)
+}
+
+// DeleteAssignment 取消价格表分配
+// @Summary 取消价格表分配
+// @Description 取消价格表对客户、客户组或供应商的分配
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param id path int true "价格表ID"
+// @Param assignment_id path int true "分配ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists/{id}/assignments/{assignment_id} [delete]
+func (c *PriceListController) DeleteAssignment(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	assignmentID, ok := c.utils.ParseIDParam(ctx, "assignment_id")
+	if !ok {
+		return
+	}
+
+	if err := c.priceListService.DeleteAssignment(ctx.Request.Context(), id, assignmentID); err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, gin.H{"message": "价格表分配取消成功"})
+}
+
+// ResolvePrice 取价
+// @Summary 取价
+// @Description 按客户（客户组）或供应商、单据日期、数量与单位取价：依次尝试指定价格表、分配的价格表与默认价格表，数量满足的最大起订数量价格优先
+// @Tags 价格表
+// @Accept json
+// @Produce json
+// @Param price_list_type query string true "价格表类型" Enums(selling, buying)
+// @Param item_id query int true "物料ID"
+// @Param customer_id query int false "客户ID"
+// @Param supplier_id query int false "供应商ID"
+// @Param price_list_id query int false "价格表ID"
+// @Param uom query string false "单位"
+// @Param quantity query number false "数量"
+// @Param date query string false "日期"
+// @Param currency query string false "币种"
+// @Success 200 {object} dto.PriceResolveResult
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/price-lists/resolve [get]
+func (c *PriceListController) ResolvePrice(ctx *gin.Context) {
+	var req dto.PriceResolveRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	result, err := c.priceListService.ResolvePrice(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, result)
+}
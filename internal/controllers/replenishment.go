@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ReplenishmentController 补货控制器
+type ReplenishmentController struct {
+	replenishmentService services.ReplenishmentService
+	utils                *ControllerUtils
+}
+
+// NewReplenishmentController 创建补货控制器实例
+func NewReplenishmentController(replenishmentService services.ReplenishmentService) *ReplenishmentController {
+	return &ReplenishmentController{
+		replenishmentService: replenishmentService,
+		utils:                NewControllerUtils(),
+	}
+}
+
+// PreviewReplenishment 预览补货建议
+// @Summary 预览补货建议
+// @Description 按仓库计算预计库存（现有库存 + 采购在途 + 未转单采购申请 − 销售订单占用），与再订货点比较给出建议补货数量，按首选供应商分组
+// @Tags 补货管理
+// @Accept json
+// @Produce json
+// @Param warehouse_id query int false "仓库ID"
+// @Param item_id query int false "物料ID"
+// @Param supplier_id query int false "首选供应商ID"
+// @Success 200 {object} dto.ReplenishmentPreviewResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/replenishment/preview [get]
+func (c *ReplenishmentController) PreviewReplenishment(ctx *gin.Context) {
+	var req dto.ReplenishmentRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	preview, err := c.replenishmentService.PreviewReplenishment(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, preview)
+}
+
+// RunReplenishment 执行补货
+// @Summary 执行补货
+// @Description 按补货建议为每个首选供应商生成一张草稿采购申请
+// @Tags 补货管理
+// @Accept json
+// @Produce json
+// @Param request body dto.ReplenishmentRequest true "补货范围"
+// @Success 201 {object} dto.ReplenishmentRunResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/replenishment/run [post]
+func (c *ReplenishmentController) RunReplenishment(ctx *gin.Context) {
+	var req dto.ReplenishmentRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.replenishmentService.RunReplenishment(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, result)
+}
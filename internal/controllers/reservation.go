@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ReservationController 库存预留控制器
+type ReservationController struct {
+	reservationService services.ReservationService
+	utils              *ControllerUtils
+}
+
+// NewReservationController 创建库存预留控制器实例
+func NewReservationController(reservationService services.ReservationService) *ReservationController {
+	return &ReservationController{
+		reservationService: reservationService,
+		utils:              NewControllerUtils(),
+	}
+}
+
+// ListReservations 获取库存预留列表
+// @Summary 获取库存预留列表
+// @Description 分页获取销售订单的库存预留，可按物料、仓库、订单与状态筛选，或仅显示库存不足的预留
+// @Tags 库存预留
+// @Accept json
+// @Produce json
+// @Param item_id query int false "物料ID"
+// @Param warehouse_id query int false "仓库ID"
+// @Param sales_order_id query int false "销售订单ID"
+// @Param status query string false "状态 active/released/fulfilled"
+// @Param shortage_only query bool false "仅显示库存不足的预留"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PaginatedResponse[dto.StockReservationResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/stock-reservations [get]
+func (c *ReservationController) ListReservations(ctx *gin.Context) {
+	var req dto.StockReservationListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	reservations, total, err := c.reservationService.ListReservations(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, reservations, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取库存预留成功")
+}
+
+// GetSalesOrderReservations 获取销售订单的库存预留
+// @Summary 获取销售订单的库存预留
+// @Description 获取销售订单各行的需求数量、已分配数量与缺货数量
+// @Tags 库存预留
+// @Accept json
+// @Produce json
+// @Param id path int true "销售订单ID"
+// @Success 200 {array} dto.StockReservationResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/sales-orders/{id}/reservations [get]
+func (c *ReservationController) GetSalesOrderReservations(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	reservations, err := c.reservationService.GetSalesOrderReservations(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, reservations)
+}
+
+// Reallocate 重新分配库存预留
+// @Summary 重新分配库存预留
+// @Description 按订单优先级、交货日期与确认先后将现有库存重新分配给有效预留
+// @Tags 库存预留
+// @Accept json
+// @Produce json
+// @Param request body dto.StockReservationReallocateRequest true "分配范围"
+// @Success 200 {object} dto.StockReservationReallocateResult
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-reservations/reallocate [post]
+func (c *ReservationController) Reallocate(ctx *gin.Context) {
+	var req dto.StockReservationReallocateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.reservationService.Reallocate(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, result)
+}
@@ -364,16 +364,15 @@ func (c *SalesController) ListSalesOrders(ctx *gin.Context) {
 
 // UpdateOrderStatus 更新订单状态
 // @Summary 更新订单状态
-// @Description 更新销售订单状态
+// @Description 按销售订单状态机变更订单状态，校验前置条件并记录状态日志
 // @Tags 销售订单
 // @Accept json
 // @Produce json
 // @Param id path int true "订单ID"
-// @Param status body map[string]string true "状态信息"
+// @Param status body dto.SalesOrderStatusUpdateRequest true "状态信息"
 // @Success 200 {object} dto.BaseResponse
 // @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
 // @Router /api/v1/sales/orders/{id}/status [put]
 func (c *SalesController) UpdateOrderStatus(ctx *gin.Context) {
 	id, ok := c.utils.ParseIDParam(ctx, "id")
@@ -381,24 +380,48 @@ func (c *SalesController) UpdateOrderStatus(ctx *gin.Context) {
 		return
 	}
 
-	var req map[string]string
+	var req dto.SalesOrderStatusUpdateRequest
 	if !c.utils.BindAndValidateJSON(ctx, &req) {
 		return
 	}
 
-	status, ok := req["status"]
+	userID := utils.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		c.utils.RespondUnauthorized(ctx, "用户未认证")
+		return
+	}
+
+	if err := c.salesOrderService.UpdateOrderStatus(ctx.Request.Context(), id, &req, userID); err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondSuccess(ctx, "更新订单状态成功")
+}
+
+// GetOrderStatusLogs 获取订单状态日志
+// @Summary 获取订单状态日志
+// @Description 获取销售订单的状态变更历史
+// @Tags 销售订单
+// @Produce json
+// @Param id path int true "订单ID"
+// @Success 200 {object} []dto.SalesOrderStatusLogResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/sales/orders/{id}/status-logs [get]
+func (c *SalesController) GetOrderStatusLogs(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
 	if !ok {
-		c.utils.RespondBadRequest(ctx, "缺少状态参数")
 		return
 	}
 
-	err := c.salesOrderService.UpdateOrderStatus(ctx.Request.Context(), id, status)
+	logs, err := c.salesOrderService.GetStatusLogs(ctx.Request.Context(), id)
 	if err != nil {
-		c.utils.RespondInternalError(ctx, "更新订单状态失败")
+		c.utils.RespondInternalError(ctx, err.Error())
 		return
 	}
 
-	c.utils.RespondSuccess(ctx, "更新订单状态成功")
+	c.utils.RespondOK(ctx, logs)
 }
 
 // CreateQuotation 创建报价单
@@ -733,6 +756,44 @@ func (c *SalesController) ListQuotations(ctx *gin.Context) {
 	c.utils.RespondPaginated(ctx, response.Data, pagination, "获取报价单列表成功")
 }
 
+// ConvertQuotationToOrder 报价单转销售订单
+// @Summary 报价单转销售订单
+// @Description 按报价单活跃版本的明细、价格与条款生成销售订单，可只转入部分明细与数量；报价单标记为已下单或部分下单，已过期的报价单不能转换
+// @Tags 报价管理
+// @Accept json
+// @Produce json
+// @Param id path int true "报价单ID"
+// @Param request body dto.QuotationConvertRequest false "转入的明细与数量"
+// @Success 201 {object} dto.SalesOrderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /api/v1/quotations/{id}/convert-to-order [post]
+func (c *SalesController) ConvertQuotationToOrder(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.QuotationConvertRequest
+	if ctx.Request.ContentLength != 0 && !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	userID := utils.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		c.utils.RespondUnauthorized(ctx, "用户未认证")
+		return
+	}
+
+	order, err := c.quotationService.ConvertToSalesOrder(ctx.Request.Context(), id, &req, userID)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, order)
+}
+
 // SearchQuotations 搜索报价单
 // @Summary 搜索报价单
 // @Description 根据关键词搜索报价单
@@ -797,6 +858,70 @@ func (c *SalesController) CreateSalesInvoice(ctx *gin.Context) {
 	c.utils.RespondCreated(ctx, invoice)
 }
 
+// CreateSalesInvoiceFromSalesOrder 按销售订单开具销售发票
+// @Summary 按销售订单开具销售发票
+// @Description 按销售订单未开票数量生成草稿发票，可只开具部分明细与数量；带出订单明细的单价、折扣、税率与付款条款
+// @Tags 销售发票管理
+// @Accept json
+// @Produce json
+// @Param request body dto.SalesInvoiceFromSalesOrderRequest true "销售订单与开票明细"
+// @Success 201 {object} dto.SalesInvoiceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /api/v1/sales-invoices/from-sales-order [post]
+func (c *SalesController) CreateSalesInvoiceFromSalesOrder(ctx *gin.Context) {
+	var req dto.SalesInvoiceFromSalesOrderRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	userID := utils.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		c.utils.RespondUnauthorized(ctx, "用户未认证")
+		return
+	}
+
+	invoice, err := c.salesInvoiceService.CreateFromSalesOrder(ctx.Request.Context(), &req, userID)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, invoice)
+}
+
+// CreateSalesInvoiceFromDeliveryNote 按送货单开具销售发票
+// @Summary 按送货单开具销售发票
+// @Description 按已提交送货单的未开票数量生成草稿发票，同一客户的多张送货单合并开具一张发票；价格与付款条款取自关联的销售订单
+// @Tags 销售发票管理
+// @Accept json
+// @Produce json
+// @Param request body dto.SalesInvoiceFromDeliveryNoteRequest true "送货单与开票明细"
+// @Success 201 {object} dto.SalesInvoiceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /api/v1/sales-invoices/from-delivery-note [post]
+func (c *SalesController) CreateSalesInvoiceFromDeliveryNote(ctx *gin.Context) {
+	var req dto.SalesInvoiceFromDeliveryNoteRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	userID := utils.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		c.utils.RespondUnauthorized(ctx, "用户未认证")
+		return
+	}
+
+	invoice, err := c.salesInvoiceService.CreateFromDeliveryNotes(ctx.Request.Context(), &req, userID)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, invoice)
+}
+
 // GetSalesInvoice 获取销售发票详情
 // @Summary 获取销售发票详情
 // @Description 根据ID获取销售发票详情
@@ -993,7 +1118,7 @@ func (c *SalesController) SubmitSalesInvoice(ctx *gin.Context) {
 
 	invoice, err := c.salesInvoiceService.SubmitSalesInvoice(ctx, id)
 	if err != nil {
-		c.utils.RespondInternalError(ctx, "提交销售发票失败")
+		c.utils.RespondBadRequest(ctx, err.Error())
 		return
 	}
 
@@ -1020,7 +1145,7 @@ func (c *SalesController) CancelSalesInvoice(ctx *gin.Context) {
 
 	invoice, err := c.salesInvoiceService.CancelSalesInvoice(ctx, id)
 	if err != nil {
-		c.utils.RespondInternalError(ctx, "取消销售发票失败")
+		c.utils.RespondBadRequest(ctx, err.Error())
 		return
 	}
 
@@ -0,0 +1,392 @@
+package controllers
+
+import (
+	"io"
+	"strings"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// StockCountController 盘点控制器
+type StockCountController struct {
+	stockCountService services.StockCountService
+	utils             *ControllerUtils
+}
+
+// NewStockCountController 创建盘点控制器实例
+func NewStockCountController(stockCountService services.StockCountService) *StockCountController {
+	return &StockCountController{
+		stockCountService: stockCountService,
+		utils:             NewControllerUtils(),
+	}
+}
+
+// CreateStockCount 生成盘点单
+// @Summary 生成盘点单
+// @Description 按整仓、库区或 ABC 分类生成盘点行并冻结账面数量与单位成本
+// @Tags 库存盘点
+// @Accept json
+// @Produce json
+// @Param request body dto.StockCountCreateRequest true "盘点范围"
+// @Success 201 {object} dto.StockCountResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts [post]
+func (c *StockCountController) CreateStockCount(ctx *gin.Context) {
+	var req dto.StockCountCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	count, err := c.stockCountService.CreateStockCount(ctx.Request.Context(), &req, utils.GetUserIDFromContext(ctx))
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, count)
+}
+
+// ListStockCounts 获取盘点单列表
+// @Summary 获取盘点单列表
+// @Description 分页获取盘点单，可按仓库、盘点范围与状态筛选
+// @Tags 库存盘点
+// @Accept json
+// @Produce json
+// @Param warehouse_id query int false "仓库ID"
+// @Param count_type query string false "盘点范围 full/zone/abc/cycle"
+// @Param status query string false "状态 counting/pending_approval/approved/posted/cancelled"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PaginatedResponse[dto.StockCountResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts [get]
+func (c *StockCountController) ListStockCounts(ctx *gin.Context) {
+	var req dto.StockCountListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	counts, total, err := c.stockCountService.ListStockCounts(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, counts, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取盘点单成功")
+}
+
+// GetStockCount 获取盘点单详情
+// @Summary 获取盘点单详情
+// @Description 获取盘点单及各盘点行的冻结数量、实盘数量与差异
+// @Tags 库存盘点
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Success 200 {object} dto.StockCountResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts/{id} [get]
+func (c *StockCountController) GetStockCount(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	count, err := c.stockCountService.GetStockCount(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, count)
+}
+
+// EnterCounts 录入实盘数量
+// @Summary 录入实盘数量
+// @Description 按盘点行ID或物料、库位与批次录入实盘数量，匹配不到的物料追加为账外盘点行
+// @Tags 库存盘点
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Param request body dto.StockCountEntryRequest true "实盘数量"
+// @Success 200 {object} dto.StockCountResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts/{id}/counts [put]
+func (c *StockCountController) EnterCounts(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.StockCountEntryRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	count, err := c.stockCountService.EnterCounts(ctx.Request.Context(), id, &req, utils.GetUserIDFromContext(ctx))
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, count)
+}
+
+// ImportScans 上传扫描数据
+// @Summary 上传扫描数据
+// @Description 上传扫描枪导出的 CSV（item_code、location_code、batch_no、quantity 列）或 JSON 记录，按物料、库位与批次汇总为实盘数量
+// @Tags 库存盘点
+// @Accept multipart/form-data,text/csv,json
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Param format query string false "文件格式(csv/json)，默认根据文件名判断"
+// @Param mode query string false "add 累加到已录入数量，replace 覆盖已录入数量" default(add)
+// @Param file formData file false "扫描数据文件"
+// @Success 200 {object} dto.StockCountScanResult
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts/{id}/scans [post]
+func (c *StockCountController) ImportScans(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.StockCountScanRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	var data []byte
+	var err error
+	if fileHeader, fileErr := ctx.FormFile("file"); fileErr == nil {
+		if req.Format == "" && strings.HasSuffix(strings.ToLower(fileHeader.Filename), ".json") {
+			req.Format = "json"
+		}
+		file, openErr := fileHeader.Open()
+		if openErr != nil {
+			c.utils.RespondBadRequest(ctx, "读取上传文件失败: "+openErr.Error())
+			return
+		}
+		defer file.Close()
+		data, err = io.ReadAll(file)
+	} else {
+		if req.Format == "" && strings.Contains(ctx.ContentType(), "json") {
+			req.Format = "json"
+		}
+		data, err = io.ReadAll(ctx.Request.Body)
+	}
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, "读取扫描数据失败: "+err.Error())
+		return
+	}
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+
+	result, err := c.stockCountService.ImportScans(ctx.Request.Context(), id, &req, data, utils.GetUserIDFromContext(ctx))
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, result)
+}
+
+// SubmitStockCount 提交盘点结果
+// @Summary 提交盘点结果
+// @Description 计算差异金额，差异超过审批阈值时进入待审批，否则自动确认
+// @Tags 库存盘点
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Success 200 {object} dto.StockCountResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts/{id}/submit [post]
+func (c *StockCountController) SubmitStockCount(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	count, err := c.stockCountService.SubmitStockCount(ctx.Request.Context(), id, utils.GetUserIDFromContext(ctx))
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, count)
+}
+
+// ApproveStockCount 审批盘点差异
+// @Summary 审批盘点差异
+// @Description 审批通过超过阈值的盘点差异
+// @Tags 库存盘点
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Param request body dto.StockCountApprovalRequest true "审批意见"
+// @Success 200 {object} dto.StockCountResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts/{id}/approve [post]
+func (c *StockCountController) ApproveStockCount(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.StockCountApprovalRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	count, err := c.stockCountService.ApproveStockCount(ctx.Request.Context(), id, &req, utils.GetUserIDFromContext(ctx))
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, count)
+}
+
+// RejectStockCount 驳回盘点差异
+// @Summary 驳回盘点差异
+// @Description 驳回盘点差异，盘点单退回盘点中以便复盘
+// @Tags 库存盘点
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Param request body dto.StockCountApprovalRequest true "驳回意见"
+// @Success 200 {object} dto.StockCountResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts/{id}/reject [post]
+func (c *StockCountController) RejectStockCount(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.StockCountApprovalRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	count, err := c.stockCountService.RejectStockCount(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, count)
+}
+
+// PostStockCount 盘点差异过账
+// @Summary 盘点差异过账
+// @Description 将已确认的盘点差异过账为库存调整移动，并按实际调整金额生成盘盈、盘亏总账凭证
+// @Tags 库存盘点
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Param request body dto.StockCountPostRequest true "过账科目"
+// @Success 200 {object} dto.StockCountResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts/{id}/post [post]
+func (c *StockCountController) PostStockCount(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.StockCountPostRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	count, err := c.stockCountService.PostStockCount(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, count)
+}
+
+// CancelStockCount 取消盘点单
+// @Summary 取消盘点单
+// @Description 取消未过账的盘点单
+// @Tags 库存盘点
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Success 200 {object} dto.StockCountResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts/{id}/cancel [post]
+func (c *StockCountController) CancelStockCount(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	count, err := c.stockCountService.CancelStockCount(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, count)
+}
+
+// PreviewCycleCount 预览循环盘点
+// @Summary 预览循环盘点
+// @Description 按 ABC 分类的盘点周期列出各仓库到期应盘点的物料
+// @Tags 库存盘点
+// @Accept json
+// @Produce json
+// @Param warehouse_id query int false "仓库ID"
+// @Param a_days query int false "A 类盘点周期（天）" default(30)
+// @Param b_days query int false "B 类盘点周期（天）" default(90)
+// @Param c_days query int false "C 类盘点周期（天）" default(180)
+// @Param max_items query int false "每张盘点单最多物料数" default(50)
+// @Success 200 {object} dto.CycleCountResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts/cycle/preview [get]
+func (c *StockCountController) PreviewCycleCount(ctx *gin.Context) {
+	var req dto.CycleCountRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	plan, err := c.stockCountService.PreviewCycleCount(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, plan)
+}
+
+// GenerateCycleCounts 生成循环盘点单
+// @Summary 生成循环盘点单
+// @Description 为每个有到期物料的仓库生成一张循环盘点单
+// @Tags 库存盘点
+// @Accept json
+// @Produce json
+// @Param request body dto.CycleCountRequest true "循环盘点参数"
+// @Success 201 {object} dto.CycleCountResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-counts/cycle [post]
+func (c *StockCountController) GenerateCycleCounts(ctx *gin.Context) {
+	var req dto.CycleCountRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	result, err := c.stockCountService.GenerateCycleCounts(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, result)
+}
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// StockTransferController 库存调拨控制器
+type StockTransferController struct {
+	transferService services.StockTransferService
+	utils           *ControllerUtils
+}
+
+// NewStockTransferController 创建库存调拨控制器实例
+func NewStockTransferController(transferService services.StockTransferService) *StockTransferController {
+	return &StockTransferController{
+		transferService: transferService,
+		utils:           NewControllerUtils(),
+	}
+}
+
+// CreateTransfer 创建调拨单
+// @Summary 创建调拨单
+// @Description 创建草稿状态的多行调拨单，指定调出、调入仓库及库位
+// @Tags 库存调拨
+// @Accept json
+// @Produce json
+// @Param transfer body dto.StockTransferCreateRequest true "调拨单信息"
+// @Success 201 {object} dto.StockTransferResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-transfers [post]
+func (c *StockTransferController) CreateTransfer(ctx *gin.Context) {
+	var req dto.StockTransferCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	transfer, err := c.transferService.CreateTransfer(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, transfer)
+}
+
+// GetTransfer 获取调拨单详情
+// @Summary 获取调拨单详情
+// @Description 获取调拨单及明细的发货、收货与差异数量
+// @Tags 库存调拨
+// @Accept json
+// @Produce json
+// @Param id path int true "调拨单ID"
+// @Success 200 {object} dto.StockTransferResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/stock-transfers/{id} [get]
+func (c *StockTransferController) GetTransfer(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := c.transferService.GetTransfer(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, transfer)
+}
+
+// ListTransfers 获取调拨单列表
+// @Summary 获取调拨单列表
+// @Description 分页获取调拨单，可按状态和仓库筛选
+// @Tags 库存调拨
+// @Accept json
+// @Produce json
+// @Param status query string false "状态"
+// @Param from_warehouse_id query int false "调出仓库ID"
+// @Param to_warehouse_id query int false "调入仓库ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PaginatedResponse[dto.StockTransferResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/stock-transfers [get]
+func (c *StockTransferController) ListTransfers(ctx *gin.Context) {
+	var req dto.StockTransferListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	transfers, total, err := c.transferService.ListTransfers(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondPaginated(ctx, transfers, c.utils.CreatePagination(req.Page, req.GetLimit(), total), "获取调拨单列表成功")
+}
+
+// ShipTransfer 调拨发货
+// @Summary 调拨发货
+// @Description 将调拨明细从调出仓转入在途仓库
+// @Tags 库存调拨
+// @Accept json
+// @Produce json
+// @Param id path int true "调拨单ID"
+// @Success 200 {object} dto.StockTransferResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-transfers/{id}/ship [post]
+func (c *StockTransferController) ShipTransfer(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := c.transferService.ShipTransfer(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, transfer)
+}
+
+// ReceiveTransfer 调拨收货
+// @Summary 调拨收货
+// @Description 将在途数量转入调入仓，支持分批收货；结束收货时短收数量报损或退回调出仓
+// @Tags 库存调拨
+// @Accept json
+// @Produce json
+// @Param id path int true "调拨单ID"
+// @Param receipt body dto.StockTransferReceiveRequest true "收货信息"
+// @Success 200 {object} dto.StockTransferResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-transfers/{id}/receive [post]
+func (c *StockTransferController) ReceiveTransfer(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.StockTransferReceiveRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	transfer, err := c.transferService.ReceiveTransfer(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, transfer)
+}
+
+// CancelTransfer 取消调拨单
+// @Summary 取消调拨单
+// @Description 取消草稿状态的调拨单
+// @Tags 库存调拨
+// @Accept json
+// @Produce json
+// @Param id path int true "调拨单ID"
+// @Success 200 {object} dto.StockTransferResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-transfers/{id}/cancel [post]
+func (c *StockTransferController) CancelTransfer(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := c.transferService.CancelTransfer(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, transfer)
+}
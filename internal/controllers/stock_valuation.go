@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// StockValuationController 库存估值控制器
+type StockValuationController struct {
+	valuationService services.StockValuationService
+	utils            *ControllerUtils
+}
+
+// NewStockValuationController 创建库存估值控制器实例
+func NewStockValuationController(valuationService services.StockValuationService) *StockValuationController {
+	return &StockValuationController{
+		valuationService: valuationService,
+		utils:            NewControllerUtils(),
+	}
+}
+
+// GetValuation 获取库存估值
+// @Summary 获取库存估值
+// @Description 按库存台账计算指定日期各物料、仓库的库存数量、单位成本与金额
+// @Tags 库存估值
+// @Accept json
+// @Produce json
+// @Param as_of query string false "估值日期(YYYY-MM-DD)，为空时为当前"
+// @Param item_id query int false "物料ID"
+// @Param warehouse_id query int false "仓库ID"
+// @Param category query string false "物料类别"
+// @Success 200 {object} dto.StockValuationResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/stock-valuation [get]
+func (c *StockValuationController) GetValuation(ctx *gin.Context) {
+	var req dto.StockValuationRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	valuation, err := c.valuationService.GetValuation(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, valuation)
+}
+
+// GetValuationReport 获取库存估值报表
+// @Summary 获取库存估值报表
+// @Description 按仓库和物料类别汇总库存金额，并与存货科目的总账余额核对
+// @Tags 库存估值
+// @Accept json
+// @Produce json
+// @Param as_of query string false "估值日期(YYYY-MM-DD)，为空时为当前"
+// @Param warehouse_id query int false "仓库ID"
+// @Param category query string false "物料类别"
+// @Param company_id query int false "公司ID"
+// @Param account_codes query string false "存货科目编码，逗号分隔，默认 1405"
+// @Success 200 {object} dto.StockValuationReportResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-valuation/report [get]
+func (c *StockValuationController) GetValuationReport(ctx *gin.Context) {
+	var req dto.StockValuationReportRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	report, err := c.valuationService.GetValuationReport(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, report)
+}
+
+// GetCostOfGoodsSold 获取发货成本
+// @Summary 获取发货成本
+// @Description 按送货单汇总出库时按计价方法结转的销售成本
+// @Tags 库存估值
+// @Accept json
+// @Produce json
+// @Param delivery_note_id query int false "送货单ID"
+// @Param start_date query string false "开始日期(YYYY-MM-DD)"
+// @Param end_date query string false "结束日期(YYYY-MM-DD)"
+// @Success 200 {object} dto.CostOfGoodsSoldResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/stock-valuation/cogs [get]
+func (c *StockValuationController) GetCostOfGoodsSold(ctx *gin.Context) {
+	var req dto.CostOfGoodsSoldRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	cogs, err := c.valuationService.GetCostOfGoodsSold(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, cogs)
+}
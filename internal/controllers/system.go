@@ -81,31 +81,6 @@ func (c *SystemController) DeleteDataPermission(ctx *gin.Context) {
 	c.utils.RespondNotImplemented(ctx, "功能暂未实现")
 }
 
-// CreateCompany 创建公司
-func (c *SystemController) CreateCompany(ctx *gin.Context) {
-	c.utils.RespondNotImplemented(ctx, "功能暂未实现")
-}
-
-// GetCompanies 获取公司列表
-func (c *SystemController) GetCompanies(ctx *gin.Context) {
-	c.utils.RespondNotImplemented(ctx, "功能暂未实现")
-}
-
-// GetCompany 获取公司
-func (c *SystemController) GetCompany(ctx *gin.Context) {
-	c.utils.RespondNotImplemented(ctx, "功能暂未实现")
-}
-
-// UpdateCompany 更新公司
-func (c *SystemController) UpdateCompany(ctx *gin.Context) {
-	c.utils.RespondNotImplemented(ctx, "功能暂未实现")
-}
-
-// DeleteCompany 删除公司
-func (c *SystemController) DeleteCompany(ctx *gin.Context) {
-	c.utils.RespondNotImplemented(ctx, "功能暂未实现")
-}
-
 // CreateDepartment 创建部门
 func (c *SystemController) CreateDepartment(ctx *gin.Context) {
 	c.utils.RespondNotImplemented(ctx, "功能暂未实现")
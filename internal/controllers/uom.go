@@ -0,0 +1,363 @@
+package controllers
+
+import (
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// UOMController 计量单位控制器
+type UOMController struct {
+	uomService services.UOMService
+	utils      *ControllerUtils
+}
+
+// NewUOMController 创建计量单位控制器实例
+func NewUOMController(uomService services.UOMService) *UOMController {
+	return &UOMController{
+		uomService: uomService,
+		utils:      NewControllerUtils(),
+	}
+}
+
+// CreateCategory 创建单位类别
+// @Summary 创建单位类别
+// @Description 创建数量、重量、长度等单位类别，同一类别的单位之间按基准系数换算
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param request body dto.UOMCategoryCreateRequest true "单位类别信息"
+// @Success 201 {object} dto.UOMCategoryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/uom-categories [post]
+func (c *UOMController) CreateCategory(ctx *gin.Context) {
+	var req dto.UOMCategoryCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	category, err := c.uomService.CreateCategory(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, category)
+}
+
+// ListCategories 获取单位类别列表
+// @Summary 获取单位类别列表
+// @Description 获取全部单位类别
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.UOMCategoryResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/uom-categories [get]
+func (c *UOMController) ListCategories(ctx *gin.Context) {
+	categories, err := c.uomService.ListCategories(ctx.Request.Context())
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, categories)
+}
+
+// UpdateCategory 更新单位类别
+// @Summary 更新单位类别
+// @Description 更新单位类别名称与描述，类别编码不能修改
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param id path int true "单位类别ID"
+// @Param request body dto.UOMCategoryUpdateRequest true "单位类别信息"
+// @Success 200 {object} dto.UOMCategoryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/uom-categories/{id} [put]
+func (c *UOMController) UpdateCategory(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UOMCategoryUpdateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	category, err := c.uomService.UpdateCategory(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, category)
+}
+
+// DeleteCategory 删除单位类别
+// @Summary 删除单位类别
+// @Description 删除没有计量单位的单位类别
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param id path int true "单位类别ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/uom-categories/{id} [delete]
+func (c *UOMController) DeleteCategory(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.uomService.DeleteCategory(ctx.Request.Context(), id); err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, gin.H{"message": "单位类别删除成功"})
+}
+
+// CreateUOM 创建计量单位
+// @Summary 创建计量单位
+// @Description 创建计量单位，设置折合类别基准单位的系数、小数位数与舍入方式
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param request body dto.UOMCreateRequest true "计量单位信息"
+// @Success 201 {object} dto.UOMResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/uoms [post]
+func (c *UOMController) CreateUOM(ctx *gin.Context) {
+	var req dto.UOMCreateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	uom, err := c.uomService.CreateUOM(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondCreated(ctx, uom)
+}
+
+// ListUOMs 获取计量单位列表
+// @Summary 获取计量单位列表
+// @Description 获取计量单位，可按类别筛选或只返回启用的单位
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param category_id query int false "单位类别ID"
+// @Param active_only query bool false "只返回启用的单位"
+// @Success 200 {array} dto.UOMResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/uoms [get]
+func (c *UOMController) ListUOMs(ctx *gin.Context) {
+	var req dto.UOMListRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	uoms, err := c.uomService.ListUOMs(ctx.Request.Context(), &req)
+	if err != nil {
+		c.utils.RespondInternalError(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, uoms)
+}
+
+// GetUOM 获取计量单位详情
+// @Summary 获取计量单位详情
+// @Description 根据ID获取计量单位
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param id path int true "计量单位ID"
+// @Success 200 {object} dto.UOMResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/uoms/{id} [get]
+func (c *UOMController) GetUOM(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	uom, err := c.uomService.GetUOM(ctx.Request.Context(), id)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, uom)
+}
+
+// UpdateUOM 更新计量单位
+// @Summary 更新计量单位
+// @Description 更新计量单位的名称、系数、小数位数、舍入方式与启用状态，编码与类别不能修改
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param id path int true "计量单位ID"
+// @Param request body dto.UOMUpdateRequest true "计量单位信息"
+// @Success 200 {object} dto.UOMResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/uoms/{id} [put]
+func (c *UOMController) UpdateUOM(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UOMUpdateRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	uom, err := c.uomService.UpdateUOM(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, uom)
+}
+
+// DeleteUOM 删除计量单位
+// @Summary 删除计量单位
+// @Description 删除未被物料库存单位或物料单位换算引用的计量单位
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param id path int true "计量单位ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/uoms/{id} [delete]
+func (c *UOMController) DeleteUOM(ctx *gin.Context) {
+	id, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.uomService.DeleteUOM(ctx.Request.Context(), id); err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, gin.H{"message": "计量单位删除成功"})
+}
+
+// Convert 换算为库存单位
+// @Summary 换算为库存单位
+// @Description 按物料单位换算或同类别基准系数，将单据单位数量换算为物料库存单位数量
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param item_id query int true "物料ID"
+// @Param uom query string true "单据单位"
+// @Param quantity query number true "数量"
+// @Success 200 {object} dto.UOMConversionResult
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/uoms/convert [get]
+func (c *UOMController) Convert(ctx *gin.Context) {
+	var req dto.UOMConvertRequest
+	if !c.utils.BindAndValidateQuery(ctx, &req) {
+		return
+	}
+
+	result, err := c.uomService.ConvertToStock(ctx.Request.Context(), req.ItemID, req.UOM, req.Quantity)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, result)
+}
+
+// ListItemConversions 获取物料单位换算
+// @Summary 获取物料单位换算
+// @Description 获取物料定义的全部单位换算
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param id path int true "物料ID"
+// @Success 200 {array} dto.ItemUOMConversionResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/items/{id}/uom-conversions [get]
+func (c *UOMController) ListItemConversions(ctx *gin.Context) {
+	itemID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	conversions, err := c.uomService.ListItemConversions(ctx.Request.Context(), itemID)
+	if err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, conversions)
+}
+
+// SetItemConversion 设置物料单位换算
+// @Summary 设置物料单位换算
+// @Description 设置 1 个单据单位折合的库存单位数量，同一单位重复提交时更新换算系数
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param id path int true "物料ID"
+// @Param request body dto.ItemUOMConversionRequest true "单位换算"
+// @Success 200 {object} dto.ItemUOMConversionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/v1/items/{id}/uom-conversions [post]
+func (c *UOMController) SetItemConversion(ctx *gin.Context) {
+	itemID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.ItemUOMConversionRequest
+	if !c.utils.BindAndValidateJSON(ctx, &req) {
+		return
+	}
+
+	conversion, err := c.uomService.SetItemConversion(ctx.Request.Context(), itemID, &req)
+	if err != nil {
+		c.utils.RespondBadRequest(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, conversion)
+}
+
+// DeleteItemConversion 删除物料单位换算
+// @Summary 删除物料单位换算
+// @Description 删除物料的单位换算
+// @Tags 计量单位
+// @Accept json
+// @Produce json
+// @Param id path int true "物料ID"
+// @Param conversion_id path int true "单位换算ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/items/{id}/uom-conversions/{conversion_id} [delete]
+func (c *UOMController) DeleteItemConversion(ctx *gin.Context) {
+	itemID, ok := c.utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	id, ok := c.utils.ParseIDParam(ctx, "conversion_id")
+	if !ok {
+		return
+	}
+
+	if err := c.uomService.DeleteItemConversion(ctx.Request.Context(), itemID, id); err != nil {
+		c.utils.RespondNotFound(ctx, err.Error())
+		return
+	}
+
+	c.utils.RespondOK(ctx, gin.H{"message": "单位换算删除成功"})
+}
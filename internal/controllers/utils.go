@@ -91,6 +91,20 @@ func (u *ControllerUtils) ParsePaginationParams(ctx *gin.Context) *dto.Paginatio
 	}
 }
 
+// ParseCompanyIDQuery 解析 company_id 查询参数，未指定时返回 0
+func (u *ControllerUtils) ParseCompanyIDQuery(ctx *gin.Context) (uint, bool) {
+	companyIDStr := ctx.Query("company_id")
+	if companyIDStr == "" {
+		return 0, true
+	}
+	companyID, err := strconv.ParseUint(companyIDStr, 10, 32)
+	if err != nil {
+		u.RespondBadRequest(ctx, "无效的公司ID")
+		return 0, false
+	}
+	return uint(companyID), true
+}
+
 // RespondBadRequest returns 400 error response
 func (u *ControllerUtils) RespondBadRequest(ctx *gin.Context, message string) {
 	common.APIBadRequestResponse(ctx, message)
@@ -112,6 +126,12 @@ func (u *ControllerUtils) RespondNotFound(ctx *gin.Context, message string) {
 	common.APINotFoundResponse(ctx, message)
 }
 
+// RespondConflict 返回409冲突响应
+func (u *ControllerUtils) RespondConflict(ctx *gin.Context, message string) {
+	helper := common.NewAPIResponseHelper(ctx)
+	helper.Conflict(message)
+}
+
 // RespondSuccess returns success response
 func (u *ControllerUtils) RespondSuccess(ctx *gin.Context, message string) {
 	common.APISuccessResponse(ctx, nil, message)
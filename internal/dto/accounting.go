@@ -2,35 +2,39 @@ package dto
 
 import (
 	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
 )
 
 // AccountCreateRequest 账户创建请求
 type AccountCreateRequest struct {
-	Code     string  `json:"code" validate:"required,max=50,account_code"`
-	Name     string  `json:"name" validate:"required,max=100"`
-	Type     string  `json:"type" validate:"required,oneof=asset liability equity revenue expense"`
-	ParentID *uint   `json:"parent_id,omitempty"`
-	Balance  float64 `json:"balance,omitempty" validate:"min=0"`
-	Status   string  `json:"status" validate:"required,oneof=active inactive"`
+	CompanyID uint         `json:"company_id,omitempty"`
+	Code      string       `json:"code" validate:"required,max=50,account_code"`
+	Name      string       `json:"name" validate:"required,max=100"`
+	Type      string       `json:"type" validate:"required,oneof=asset liability equity revenue expense"`
+	ParentID  *uint        `json:"parent_id,omitempty"`
+	Balance   models.Money `json:"balance,omitempty" validate:"min=0"`
+	Status    string       `json:"status" validate:"required,oneof=active inactive"`
 }
 
 // AccountUpdateRequest 账户更新请求
 type AccountUpdateRequest struct {
-	Name     string   `json:"name,omitempty" validate:"omitempty,max=100"`
-	Type     string   `json:"type,omitempty" validate:"omitempty,oneof=asset liability equity revenue expense"`
-	ParentID *uint    `json:"parent_id,omitempty"`
-	Balance  *float64 `json:"balance,omitempty" validate:"omitempty,min=0"`
-	Status   string   `json:"status,omitempty" validate:"omitempty,oneof=active inactive"`
+	Name     string        `json:"name,omitempty" validate:"omitempty,max=100"`
+	Type     string        `json:"type,omitempty" validate:"omitempty,oneof=asset liability equity revenue expense"`
+	ParentID *uint         `json:"parent_id,omitempty"`
+	Balance  *models.Money `json:"balance,omitempty" validate:"omitempty,min=0"`
+	Status   string        `json:"status,omitempty" validate:"omitempty,oneof=active inactive"`
 }
 
 // AccountResponse 账户响应
 type AccountResponse struct {
 	ID        uint              `json:"id"`
+	CompanyID uint              `json:"company_id"`
 	Code      string            `json:"code"`
 	Name      string            `json:"name"`
 	Type      string            `json:"type"`
 	ParentID  *uint             `json:"parent_id,omitempty"`
-	Balance   float64           `json:"balance"`
+	Balance   models.Money      `json:"balance"`
 	Status    string            `json:"status"`
 	Parent    *AccountResponse  `json:"parent,omitempty"`
 	Children  []AccountResponse `json:"children,omitempty"`
@@ -40,17 +44,19 @@ type AccountResponse struct {
 
 // AccountListResponse 账户列表响应
 type AccountListResponse struct {
-	ID       uint    `json:"id"`
-	Code     string  `json:"code"`
-	Name     string  `json:"name"`
-	Type     string  `json:"type"`
-	Balance  float64 `json:"balance"`
-	Status   string  `json:"status"`
-	ParentID *uint   `json:"parent_id,omitempty"`
+	ID        uint         `json:"id"`
+	CompanyID uint         `json:"company_id"`
+	Code      string       `json:"code"`
+	Name      string       `json:"name"`
+	Type      string       `json:"type"`
+	Balance   models.Money `json:"balance"`
+	Status    string       `json:"status"`
+	ParentID  *uint        `json:"parent_id,omitempty"`
 }
 
 // JournalEntryCreateRequest 日记账分录创建请求
 type JournalEntryCreateRequest struct {
+	CompanyID   uint                      `json:"company_id,omitempty"`
 	Date        time.Time                 `json:"date" validate:"required"`
 	Reference   string                    `json:"reference,omitempty"`
 	Description string                    `json:"description" validate:"required"`
@@ -59,21 +65,23 @@ type JournalEntryCreateRequest struct {
 
 // JournalEntryItemRequest 日记账分录项请求
 type JournalEntryItemRequest struct {
-	AccountID    uint    `json:"account_id" validate:"required"`
-	DebitAmount  float64 `json:"debit_amount,omitempty" validate:"min=0"`
-	CreditAmount float64 `json:"credit_amount,omitempty" validate:"min=0"`
-	Description  string  `json:"description,omitempty"`
+	AccountID             uint         `json:"account_id" validate:"required"`
+	DebitAmount           models.Money `json:"debit_amount,omitempty" validate:"min=0"`
+	CreditAmount          models.Money `json:"credit_amount,omitempty" validate:"min=0"`
+	Description           string       `json:"description,omitempty"`
+	IntercompanyCompanyID *uint        `json:"intercompany_company_id,omitempty"`
 }
 
 // JournalEntryResponse 日记账分录响应
 type JournalEntryResponse struct {
 	ID          uint                       `json:"id"`
+	CompanyID   uint                       `json:"company_id"`
 	Number      string                     `json:"number"`
 	Date        time.Time                  `json:"date"`
 	Reference   string                     `json:"reference,omitempty"`
 	Description string                     `json:"description"`
-	TotalDebit  float64                    `json:"total_debit"`
-	TotalCredit float64                    `json:"total_credit"`
+	TotalDebit  models.Money               `json:"total_debit"`
+	TotalCredit models.Money               `json:"total_credit"`
 	Status      string                     `json:"status"`
 	Items       []JournalEntryItemResponse `json:"items"`
 	CreatedBy   UserResponse               `json:"created_by"`
@@ -83,26 +91,27 @@ type JournalEntryResponse struct {
 
 // JournalEntryItemResponse 日记账分录项响应
 type JournalEntryItemResponse struct {
-	ID           uint            `json:"id"`
-	DebitAmount  float64         `json:"debit_amount"`
-	CreditAmount float64         `json:"credit_amount"`
-	Description  string          `json:"description,omitempty"`
-	Account      AccountResponse `json:"account"`
+	ID                    uint            `json:"id"`
+	DebitAmount           models.Money    `json:"debit_amount"`
+	CreditAmount          models.Money    `json:"credit_amount"`
+	Description           string          `json:"description,omitempty"`
+	IntercompanyCompanyID *uint           `json:"intercompany_company_id,omitempty"`
+	Account               AccountResponse `json:"account"`
 }
 
 // PaymentCreateRequest 付款创建请求
 type PaymentCreateRequest struct {
-	Type          string    `json:"type" validate:"required,oneof=payment receipt"`
-	Amount        float64   `json:"amount" validate:"required,gt=0"`
-	Date          time.Time `json:"date" validate:"required"`
-	Reference     string    `json:"reference,omitempty"`
-	Description   string    `json:"description,omitempty"`
-	AccountID     uint      `json:"account_id" validate:"required"`
-	PaymentMethod string    `json:"payment_method" validate:"required,oneof=cash bank_transfer check credit_card"`
-	CheckNumber   string    `json:"check_number,omitempty"`
-	BankAccount   string    `json:"bank_account,omitempty"`
-	CustomerID    *uint     `json:"customer_id,omitempty"`
-	SupplierID    *uint     `json:"supplier_id,omitempty"`
+	Type          string       `json:"type" validate:"required,oneof=payment receipt"`
+	Amount        models.Money `json:"amount" validate:"required,gt=0"`
+	Date          time.Time    `json:"date" validate:"required"`
+	Reference     string       `json:"reference,omitempty"`
+	Description   string       `json:"description,omitempty"`
+	AccountID     uint         `json:"account_id" validate:"required"`
+	PaymentMethod string       `json:"payment_method" validate:"required,oneof=cash bank_transfer check credit_card"`
+	CheckNumber   string       `json:"check_number,omitempty"`
+	BankAccount   string       `json:"bank_account,omitempty"`
+	CustomerID    *uint        `json:"customer_id,omitempty"`
+	SupplierID    *uint        `json:"supplier_id,omitempty"`
 }
 
 // PaymentResponse 付款响应
@@ -110,7 +119,7 @@ type PaymentResponse struct {
 	ID            uint              `json:"id"`
 	Number        string            `json:"number"`
 	Type          string            `json:"type"`
-	Amount        float64           `json:"amount"`
+	Amount        models.Money      `json:"amount"`
 	Date          time.Time         `json:"date"`
 	Reference     string            `json:"reference,omitempty"`
 	Description   string            `json:"description,omitempty"`
@@ -135,14 +144,14 @@ type AccountSearchRequest struct {
 // PaymentSearchRequest 付款搜索请求
 type PaymentSearchRequest struct {
 	SearchRequest
-	Type       string     `json:"type,omitempty" form:"type"`
-	AccountID  *uint      `json:"account_id,omitempty" form:"account_id"`
-	CustomerID *uint      `json:"customer_id,omitempty" form:"customer_id"`
-	SupplierID *uint      `json:"supplier_id,omitempty" form:"supplier_id"`
-	StartDate  *time.Time `json:"start_date,omitempty" form:"start_date"`
-	EndDate    *time.Time `json:"end_date,omitempty" form:"end_date"`
-	MinAmount  *float64   `json:"min_amount,omitempty" form:"min_amount"`
-	MaxAmount  *float64   `json:"max_amount,omitempty" form:"max_amount"`
+	Type       string        `json:"type,omitempty" form:"type"`
+	AccountID  *uint         `json:"account_id,omitempty" form:"account_id"`
+	CustomerID *uint         `json:"customer_id,omitempty" form:"customer_id"`
+	SupplierID *uint         `json:"supplier_id,omitempty" form:"supplier_id"`
+	StartDate  *time.Time    `json:"start_date,omitempty" form:"start_date"`
+	EndDate    *time.Time    `json:"end_date,omitempty" form:"end_date"`
+	MinAmount  *models.Money `json:"min_amount,omitempty" form:"min_amount"`
+	MaxAmount  *models.Money `json:"max_amount,omitempty" form:"max_amount"`
 }
 
 // FinancialReportRequest 财务报表请求
@@ -152,3 +161,61 @@ type ReportGenerateRequest struct {
 	EndDate   time.Time `json:"end_date" validate:"required"`
 	Format    string    `json:"format" form:"format" validate:"required,oneof=excel pdf csv"`
 }
+
+// AccountImportRow 科目导入/导出行，父科目以编码表示
+type AccountImportRow struct {
+	Code        string `json:"code" validate:"required,max=50,account_code"`
+	Name        string `json:"name" validate:"required,max=100"`
+	Type        string `json:"type" validate:"required,oneof=asset liability equity revenue expense"`
+	ParentCode  string `json:"parent_code,omitempty"`
+	Currency    string `json:"currency,omitempty" validate:"omitempty,max=10"`
+	Description string `json:"description,omitempty"`
+}
+
+// AccountImportRequest 科目导入请求参数
+type AccountImportRequest struct {
+	CompanyID    uint   `json:"company_id" form:"company_id"`
+	Format       string `json:"format" form:"format" validate:"omitempty,oneof=csv json"`
+	DryRun       bool   `json:"dry_run" form:"dry_run"`
+	SkipExisting bool   `json:"skip_existing" form:"skip_existing"`
+}
+
+// ChartTemplateApplyRequest 应用科目表模板请求
+type ChartTemplateApplyRequest struct {
+	CompanyID    uint   `json:"company_id"`
+	Template     string `json:"template" validate:"required"`
+	DryRun       bool   `json:"dry_run"`
+	SkipExisting bool   `json:"skip_existing"`
+}
+
+// AccountExportRequest 科目表导出请求
+type AccountExportRequest struct {
+	CompanyID uint   `json:"company_id" form:"company_id"`
+	Format    string `json:"format" form:"format" validate:"omitempty,oneof=csv json"`
+}
+
+// AccountImportConflict 科目导入冲突或校验失败的行
+type AccountImportConflict struct {
+	Row     int    `json:"row"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// AccountImportResult 科目导入结果；存在冲突或试运行时不写入任何科目
+type AccountImportResult struct {
+	CompanyID uint                    `json:"company_id"`
+	DryRun    bool                    `json:"dry_run"`
+	Imported  bool                    `json:"imported"`
+	Total     int                     `json:"total"`
+	Created   int                     `json:"created"`
+	Skipped   int                     `json:"skipped"`
+	Conflicts []AccountImportConflict `json:"conflicts"`
+}
+
+// ChartTemplateResponse 科目表模板响应
+type ChartTemplateResponse struct {
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	AccountCount int    `json:"account_count"`
+}
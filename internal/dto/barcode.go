@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// ItemBarcodeCreateRequest 物料条码登记请求
+type ItemBarcodeCreateRequest struct {
+	Barcode     string `json:"barcode" validate:"required,max=100"`
+	BarcodeType string `json:"barcode_type,omitempty" validate:"omitempty,oneof=ean13 upca ean8 gtin14 code128 other"` // 为空时按条码内容识别
+	UOM         string `json:"uom,omitempty" validate:"max=50"`                                                        // 条码对应的单位，为空时为库存单位
+	IsPrimary   bool   `json:"is_primary,omitempty"`
+	Description string `json:"description,omitempty" validate:"max=255"`
+}
+
+// ItemBarcodeResponse 物料条码响应
+type ItemBarcodeResponse struct {
+	ID               uint      `json:"id"`
+	ItemID           uint      `json:"item_id"`
+	Barcode          string    `json:"barcode"`
+	GTIN             string    `json:"gtin,omitempty"`
+	BarcodeType      string    `json:"barcode_type"`
+	UOM              string    `json:"uom,omitempty"`
+	ConversionFactor float64   `json:"conversion_factor"` // 1 个条码单位折合的库存单位数量
+	IsPrimary        bool      `json:"is_primary"`
+	Description      string    `json:"description,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// BarcodeScanRequest 扫描解析请求；一次可提交同一作业中扫描的多个条码，如库位标签与物料 GS1 标签
+type BarcodeScanRequest struct {
+	Codes           []string `json:"codes" validate:"required,min=1,dive,required"`
+	WarehouseID     uint     `json:"warehouse_id,omitempty"`                                               // 为空时在全部仓库中查找库位
+	MovementType    string   `json:"movement_type,omitempty" validate:"omitempty,oneof=in out adjustment"` // 预填库存移动的类型，默认 in
+	PurchaseOrderID uint     `json:"purchase_order_id,omitempty"`                                          // 不为空时按订单预填收货明细
+}
+
+// BarcodeScanCode 单个条码的识别结果
+type BarcodeScanCode struct {
+	Code   string         `json:"code"`
+	Format string         `json:"format"`          // gs1, gtin, barcode, item_code, location, unknown
+	GS1    *utils.GS1Data `json:"gs1,omitempty"`   // GS1 条码的应用标识符解析结果
+	Match  string         `json:"match,omitempty"` // 匹配到的物料或库位编码
+}
+
+// BarcodeScanItem 扫描识别的物料
+type BarcodeScanItem struct {
+	ID           uint   `json:"id"`
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	Unit         string `json:"unit,omitempty"`
+	TrackingMode string `json:"tracking_mode"`
+}
+
+// BarcodeScanBatch 扫描识别的批次；Exists 为 false 表示批次尚未登记，入库时自动创建
+type BarcodeScanBatch struct {
+	ID         uint       `json:"id,omitempty"`
+	BatchNo    string     `json:"batch_no"`
+	ExpiryDate *time.Time `json:"expiry_date,omitempty"`
+	Exists     bool       `json:"exists"`
+	IsExpired  bool       `json:"is_expired"`
+}
+
+// BarcodeScanLocation 扫描识别的库位
+type BarcodeScanLocation struct {
+	ID          uint   `json:"id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	WarehouseID uint   `json:"warehouse_id"`
+}
+
+// BarcodeScanResponse 扫描解析结果，识别出物料时预填库存移动，指定采购订单时预填收货明细
+type BarcodeScanResponse struct {
+	Codes         []BarcodeScanCode           `json:"codes"`
+	Item          *BarcodeScanItem            `json:"item,omitempty"`
+	Batch         *BarcodeScanBatch           `json:"batch,omitempty"`
+	SerialNo      string                      `json:"serial_no,omitempty"`
+	Location      *BarcodeScanLocation        `json:"location,omitempty"`
+	Quantity      float64                     `json:"quantity"` // 条码单位数量，GS1 条码取 (30)/(37)，否则按扫描次数
+	UOM           string                      `json:"uom,omitempty"`
+	StockQuantity float64                     `json:"stock_quantity"`
+	Movement      *MovementCreateRequest      `json:"movement,omitempty"`
+	ReceiptLine   *PurchaseReceiptItemRequest `json:"receipt_line,omitempty"`
+	Warnings      []string                    `json:"warnings,omitempty"`
+}
+
+// BarcodeLabelRequest 标签打印请求
+type BarcodeLabelRequest struct {
+	Type    string `json:"type" validate:"required,oneof=item location"`
+	IDs     []uint `json:"ids" validate:"required,min=1,max=500"`
+	Format  string `json:"format,omitempty" validate:"omitempty,oneof=zpl pdf"` // 默认 zpl
+	Copies  int    `json:"copies,omitempty" validate:"min=0,max=1000"`          // 每张标签的份数，默认 1
+	BatchNo string `json:"batch_no,omitempty" validate:"max=100"`               // 物料标签的批次；物料有 GTIN 条码时打印含批次与有效期的 GS1-128 条码
+}
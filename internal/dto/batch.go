@@ -0,0 +1,183 @@
+package dto
+
+import "time"
+
+// BatchCreateRequest 创建批次请求
+type BatchCreateRequest struct {
+	ItemID          uint       `json:"item_id" validate:"required"`
+	BatchNo         string     `json:"batch_no" validate:"required,max=100"`
+	ManufactureDate *time.Time `json:"manufacture_date,omitempty"`
+	ExpiryDate      *time.Time `json:"expiry_date,omitempty"`
+	SupplierID      *uint      `json:"supplier_id,omitempty"`
+	SupplierLotNo   string     `json:"supplier_lot_no,omitempty" validate:"max=100"`
+	Notes           string     `json:"notes,omitempty"`
+}
+
+// BatchUpdateRequest 更新批次请求，批次号与物料不可修改
+type BatchUpdateRequest struct {
+	ManufactureDate *time.Time `json:"manufacture_date,omitempty"`
+	ExpiryDate      *time.Time `json:"expiry_date,omitempty"`
+	SupplierID      *uint      `json:"supplier_id,omitempty"`
+	SupplierLotNo   *string    `json:"supplier_lot_no,omitempty" validate:"omitempty,max=100"`
+	Notes           *string    `json:"notes,omitempty"`
+	IsActive        *bool      `json:"is_active,omitempty"`
+}
+
+// BatchListRequest 批次列表请求
+type BatchListRequest struct {
+	PaginationRequest
+	ItemID  uint   `json:"item_id,omitempty" form:"item_id"`
+	BatchNo string `json:"batch_no,omitempty" form:"batch_no"`
+}
+
+// BatchResponse 批次响应
+type BatchResponse struct {
+	ID              uint       `json:"id"`
+	ItemID          uint       `json:"item_id"`
+	ItemCode        string     `json:"item_code,omitempty"`
+	ItemName        string     `json:"item_name,omitempty"`
+	BatchNo         string     `json:"batch_no"`
+	ManufactureDate *time.Time `json:"manufacture_date,omitempty"`
+	ExpiryDate      *time.Time `json:"expiry_date,omitempty"`
+	SupplierID      *uint      `json:"supplier_id,omitempty"`
+	SupplierLotNo   string     `json:"supplier_lot_no,omitempty"`
+	Notes           string     `json:"notes,omitempty"`
+	IsActive        bool       `json:"is_active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// BatchStockRequest 批次库存查询请求
+type BatchStockRequest struct {
+	ItemID      uint `json:"item_id,omitempty" form:"item_id"`
+	WarehouseID uint `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	IncludeZero bool `json:"include_zero,omitempty" form:"include_zero"`
+}
+
+// BatchStockLine 批次在仓库的库存
+type BatchStockLine struct {
+	ItemID        uint       `json:"item_id"`
+	ItemCode      string     `json:"item_code"`
+	ItemName      string     `json:"item_name"`
+	WarehouseID   uint       `json:"warehouse_id"`
+	WarehouseCode string     `json:"warehouse_code"`
+	WarehouseName string     `json:"warehouse_name"`
+	BatchID       uint       `json:"batch_id"`
+	BatchNo       string     `json:"batch_no"`
+	ExpiryDate    *time.Time `json:"expiry_date,omitempty"`
+	Quantity      float64    `json:"quantity"`
+}
+
+// BatchStockResponse 批次库存响应
+type BatchStockResponse struct {
+	Lines         []BatchStockLine `json:"lines"`
+	TotalQuantity float64          `json:"total_quantity"`
+}
+
+// ExpiryReportRequest 近效期报表请求，Days 为空时使用默认预警天数
+type ExpiryReportRequest struct {
+	ItemID         uint `json:"item_id,omitempty" form:"item_id"`
+	WarehouseID    uint `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	Days           int  `json:"days,omitempty" form:"days" validate:"omitempty,min=1,max=3650"`
+	ExcludeExpired bool `json:"exclude_expired,omitempty" form:"exclude_expired"` // 只列出尚未过期的批次
+}
+
+// ExpiryReportLine 近效期报表行，Status 为 expired 或 near_expiry
+type ExpiryReportLine struct {
+	BatchStockLine
+	DaysToExpiry int    `json:"days_to_expiry"` // 已过期时为负数
+	Status       string `json:"status"`
+}
+
+// ExpiryReportResponse 近效期报表响应
+type ExpiryReportResponse struct {
+	AsOf               time.Time          `json:"as_of"`
+	Days               int                `json:"days"`
+	Lines              []ExpiryReportLine `json:"lines"`
+	ExpiredQuantity    float64            `json:"expired_quantity"`
+	NearExpiryQuantity float64            `json:"near_expiry_quantity"`
+}
+
+// SerialNumberListRequest 序列号列表请求
+type SerialNumberListRequest struct {
+	PaginationRequest
+	ItemID      uint   `json:"item_id,omitempty" form:"item_id"`
+	WarehouseID uint   `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	BatchID     uint   `json:"batch_id,omitempty" form:"batch_id"`
+	Status      string `json:"status,omitempty" form:"status" validate:"omitempty,oneof=in_stock issued inactive"`
+	SerialNo    string `json:"serial_no,omitempty" form:"serial_no"`
+}
+
+// SerialNumberResponse 序列号响应
+type SerialNumberResponse struct {
+	ID              uint       `json:"id"`
+	ItemID          uint       `json:"item_id"`
+	ItemCode        string     `json:"item_code,omitempty"`
+	ItemName        string     `json:"item_name,omitempty"`
+	SerialNo        string     `json:"serial_no"`
+	BatchID         *uint      `json:"batch_id,omitempty"`
+	BatchNo         string     `json:"batch_no,omitempty"`
+	WarehouseID     *uint      `json:"warehouse_id,omitempty"`
+	Status          string     `json:"status"`
+	ManufactureDate *time.Time `json:"manufacture_date,omitempty"`
+	ExpiryDate      *time.Time `json:"expiry_date,omitempty"`
+	SupplierID      *uint      `json:"supplier_id,omitempty"`
+	LastMovementID  *uint      `json:"last_movement_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TraceRequest 追溯请求；forward 正向追溯到生产领用与客户发货，backward 反向追溯到采购入库与生产投料
+type TraceRequest struct {
+	Direction string `json:"direction,omitempty" form:"direction" validate:"omitempty,oneof=forward backward both"`
+}
+
+// TraceMovement 追溯涉及的库存移动及其来源单据
+type TraceMovement struct {
+	MovementID      uint      `json:"movement_id"`
+	ItemID          uint      `json:"item_id"`
+	ItemCode        string    `json:"item_code"`
+	WarehouseID     uint      `json:"warehouse_id"`
+	BatchID         *uint     `json:"batch_id,omitempty"`
+	BatchNo         string    `json:"batch_no,omitempty"`
+	SerialNo        string    `json:"serial_no,omitempty"`
+	MovementType    string    `json:"movement_type"`
+	QuantityChange  float64   `json:"quantity_change"`
+	ReferenceType   string    `json:"reference_type,omitempty"`
+	ReferenceID     *uint     `json:"reference_id,omitempty"`
+	ReferenceNumber string    `json:"reference_number,omitempty"`
+	PartyID         *uint     `json:"party_id,omitempty"`
+	PartyName       string    `json:"party_name,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// TraceDocument 追溯涉及的业务单据（采购入库单、生产订单、送货单）
+type TraceDocument struct {
+	ReferenceType   string   `json:"reference_type"`
+	ReferenceID     uint     `json:"reference_id"`
+	ReferenceNumber string   `json:"reference_number,omitempty"`
+	PartyID         *uint    `json:"party_id,omitempty"`
+	PartyName       string   `json:"party_name,omitempty"`
+	BatchNos        []string `json:"batch_nos"`
+}
+
+// TraceBatch 追溯链路上的批次，Level 为与起点批次的距离，反向为负
+type TraceBatch struct {
+	BatchID  uint   `json:"batch_id"`
+	BatchNo  string `json:"batch_no"`
+	ItemID   uint   `json:"item_id"`
+	ItemCode string `json:"item_code"`
+	Level    int    `json:"level"`
+}
+
+// TraceResponse 批次或序列号追溯结果
+type TraceResponse struct {
+	Direction        string                `json:"direction"`
+	Batch            *BatchResponse        `json:"batch,omitempty"`
+	SerialNumber     *SerialNumberResponse `json:"serial_number,omitempty"`
+	Batches          []TraceBatch          `json:"batches"`
+	Receipts         []TraceDocument       `json:"receipts"`
+	ProductionOrders []TraceDocument       `json:"production_orders"`
+	Deliveries       []TraceDocument       `json:"deliveries"`
+	Movements        []TraceMovement       `json:"movements"`
+}
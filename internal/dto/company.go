@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"time"
+)
+
+// CompanyCreateRequest 公司创建请求
+type CompanyCreateRequest struct {
+	Code            string `json:"code" validate:"required,max=50"`
+	Name            string `json:"name" validate:"required,max=255"`
+	Description     string `json:"description,omitempty"`
+	Address         string `json:"address,omitempty"`
+	Phone           string `json:"phone,omitempty" validate:"max=20"`
+	Email           string `json:"email,omitempty" validate:"omitempty,email"`
+	ParentID        *uint  `json:"parent_id,omitempty"`
+	DefaultCurrency string `json:"default_currency,omitempty" validate:"omitempty,len=3"`
+}
+
+// CompanyUpdateRequest 公司更新请求
+type CompanyUpdateRequest struct {
+	Name            *string `json:"name,omitempty" validate:"omitempty,max=255"`
+	Description     *string `json:"description,omitempty"`
+	Address         *string `json:"address,omitempty"`
+	Phone           *string `json:"phone,omitempty" validate:"omitempty,max=20"`
+	Email           *string `json:"email,omitempty" validate:"omitempty,email"`
+	ParentID        *uint   `json:"parent_id,omitempty"`
+	DefaultCurrency *string `json:"default_currency,omitempty" validate:"omitempty,len=3"`
+	IsActive        *bool   `json:"is_active,omitempty"`
+}
+
+// CompanyResponse 公司响应
+type CompanyResponse struct {
+	ID              uint      `json:"id"`
+	Code            string    `json:"code"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description,omitempty"`
+	Address         string    `json:"address,omitempty"`
+	Phone           string    `json:"phone,omitempty"`
+	Email           string    `json:"email,omitempty"`
+	ParentID        *uint     `json:"parent_id,omitempty"`
+	DefaultCurrency string    `json:"default_currency"`
+	IsActive        bool      `json:"is_active"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// FiscalYearCreateRequest 财政年度创建请求，按月自动生成会计期间
+type FiscalYearCreateRequest struct {
+	Year      int       `json:"year" validate:"required,min=1900,max=9999"`
+	StartDate time.Time `json:"start_date" validate:"required"`
+	EndDate   time.Time `json:"end_date" validate:"required"`
+	IsCurrent bool      `json:"is_current"`
+}
+
+// FiscalYearResponse 财政年度响应
+type FiscalYearResponse struct {
+	ID        uint                       `json:"id"`
+	CompanyID uint                       `json:"company_id"`
+	Year      int                        `json:"year"`
+	StartDate time.Time                  `json:"start_date"`
+	EndDate   time.Time                  `json:"end_date"`
+	IsCurrent bool                       `json:"is_current"`
+	Status    string                     `json:"status"`
+	Periods   []AccountingPeriodResponse `json:"periods,omitempty"`
+	CreatedAt time.Time                  `json:"created_at"`
+}
+
+// AccountingPeriodResponse 会计期间响应
+type AccountingPeriodResponse struct {
+	ID           uint       `json:"id"`
+	CompanyID    uint       `json:"company_id"`
+	FiscalYearID *uint      `json:"fiscal_year_id,omitempty"`
+	Name         string     `json:"name"`
+	StartDate    time.Time  `json:"start_date"`
+	EndDate      time.Time  `json:"end_date"`
+	FiscalYear   string     `json:"fiscal_year"`
+	IsClosed     bool       `json:"is_closed"`
+	ClosedBy     *uint      `json:"closed_by,omitempty"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+}
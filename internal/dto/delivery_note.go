@@ -127,6 +127,7 @@ type DeliveryNoteBatchCreateRequest struct {
 type DeliveryNoteBatchItem struct {
 	SalesOrderItemID uint    `json:"sales_order_item_id" validate:"required"`
 	Quantity         float64 `json:"quantity" validate:"required,gt=0"`
+	UOM              string  `json:"uom,omitempty"`
 	BatchNo          string  `json:"batch_no,omitempty"`
 	SerialNo         string  `json:"serial_no,omitempty"`
 	WarehouseID      *uint   `json:"warehouse_id,omitempty"`
@@ -0,0 +1,135 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// CustomerStatementRequest 客户对账单请求
+type CustomerStatementRequest struct {
+	StartDate time.Time `json:"start_date" form:"start_date" time_format:"2006-01-02" validate:"required"`
+	EndDate   time.Time `json:"end_date" form:"end_date" time_format:"2006-01-02" validate:"required"`
+}
+
+// CustomerStatementLine 对账单明细行
+type CustomerStatementLine struct {
+	Date          time.Time    `json:"date"`
+	Type          string       `json:"type"` // invoice, payment
+	Reference     string       `json:"reference"`
+	Description   string       `json:"description,omitempty"`
+	DueDate       *time.Time   `json:"due_date,omitempty"`
+	Debit         models.Money `json:"debit"`
+	Credit        models.Money `json:"credit"`
+	Balance       models.Money `json:"balance"`
+	PaymentStatus string       `json:"payment_status,omitempty"`
+}
+
+// CustomerStatementResponse 客户对账单响应
+type CustomerStatementResponse struct {
+	CustomerID     uint                    `json:"customer_id"`
+	CustomerCode   string                  `json:"customer_code"`
+	CustomerName   string                  `json:"customer_name"`
+	StartDate      time.Time               `json:"start_date"`
+	EndDate        time.Time               `json:"end_date"`
+	OpeningBalance models.Money            `json:"opening_balance"`
+	TotalInvoiced  models.Money            `json:"total_invoiced"`
+	TotalReceived  models.Money            `json:"total_received"`
+	ClosingBalance models.Money            `json:"closing_balance"`
+	OverdueAmount  models.Money            `json:"overdue_amount"`
+	Lines          []CustomerStatementLine `json:"lines"`
+	GeneratedAt    time.Time               `json:"generated_at"`
+}
+
+// DunningLevelCreateRequest 催款级别创建请求
+type DunningLevelCreateRequest struct {
+	Level           int          `json:"level" validate:"required,min=1"`
+	Name            string       `json:"name" validate:"required,max=100"`
+	DaysOverdue     int          `json:"days_overdue" validate:"min=0"`
+	FeeAmount       models.Money `json:"fee_amount" validate:"min=0"`
+	InterestRate    float64      `json:"interest_rate" validate:"min=0,max=100"`
+	SubjectTemplate string       `json:"subject_template,omitempty" validate:"max=500"`
+	BodyTemplate    string       `json:"body_template,omitempty"`
+	IsActive        *bool        `json:"is_active,omitempty"`
+}
+
+// DunningLevelUpdateRequest 催款级别更新请求
+type DunningLevelUpdateRequest struct {
+	Name            *string       `json:"name,omitempty" validate:"omitempty,max=100"`
+	DaysOverdue     *int          `json:"days_overdue,omitempty" validate:"omitempty,min=0"`
+	FeeAmount       *models.Money `json:"fee_amount,omitempty" validate:"omitempty,min=0"`
+	InterestRate    *float64      `json:"interest_rate,omitempty" validate:"omitempty,min=0,max=100"`
+	SubjectTemplate *string       `json:"subject_template,omitempty" validate:"omitempty,max=500"`
+	BodyTemplate    *string       `json:"body_template,omitempty"`
+	IsActive        *bool         `json:"is_active,omitempty"`
+}
+
+// DunningLevelResponse 催款级别响应
+type DunningLevelResponse struct {
+	ID              uint         `json:"id"`
+	Level           int          `json:"level"`
+	Name            string       `json:"name"`
+	DaysOverdue     int          `json:"days_overdue"`
+	FeeAmount       models.Money `json:"fee_amount"`
+	InterestRate    float64      `json:"interest_rate"`
+	SubjectTemplate string       `json:"subject_template"`
+	BodyTemplate    string       `json:"body_template"`
+	IsActive        bool         `json:"is_active"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// DunningRunRequest 催款执行请求
+type DunningRunRequest struct {
+	AsOfDate   *time.Time `json:"as_of_date,omitempty"`
+	CustomerID *uint      `json:"customer_id,omitempty"`
+	DryRun     bool       `json:"dry_run"`
+}
+
+// DunningRunResponse 催款执行结果
+type DunningRunResponse struct {
+	AsOfDate        time.Time               `json:"as_of_date"`
+	TriggerType     string                  `json:"trigger_type"`
+	DryRun          bool                    `json:"dry_run"`
+	MarkedOverdue   int64                   `json:"marked_overdue"`
+	InvoicesChecked int                     `json:"invoices_checked"`
+	LettersCreated  int                     `json:"letters_created"`
+	Letters         []DunningLetterResponse `json:"letters"`
+}
+
+// DunningLetterListRequest 催款函列表请求
+type DunningLetterListRequest struct {
+	PaginationRequest
+	CustomerID     *uint      `json:"customer_id,omitempty" form:"customer_id"`
+	SalesInvoiceID *uint      `json:"sales_invoice_id,omitempty" form:"sales_invoice_id"`
+	Level          *int       `json:"level,omitempty" form:"level"`
+	TriggerType    string     `json:"trigger_type,omitempty" form:"trigger_type"`
+	StartDate      *time.Time `json:"start_date,omitempty" form:"start_date" time_format:"2006-01-02"`
+	EndDate        *time.Time `json:"end_date,omitempty" form:"end_date" time_format:"2006-01-02"`
+}
+
+// DunningLetterResponse 催款函响应
+type DunningLetterResponse struct {
+	ID                uint         `json:"id"`
+	LetterNumber      string       `json:"letter_number"`
+	CustomerID        uint         `json:"customer_id"`
+	CustomerName      string       `json:"customer_name,omitempty"`
+	SalesInvoiceID    uint         `json:"sales_invoice_id"`
+	InvoiceNumber     string       `json:"invoice_number,omitempty"`
+	DunningLevelID    uint         `json:"dunning_level_id"`
+	Level             int          `json:"level"`
+	LevelName         string       `json:"level_name,omitempty"`
+	LetterDate        time.Time    `json:"letter_date"`
+	OverdueDays       int          `json:"overdue_days"`
+	Currency          string       `json:"currency"`
+	OutstandingAmount models.Money `json:"outstanding_amount"`
+	FeeAmount         models.Money `json:"fee_amount"`
+	InterestAmount    models.Money `json:"interest_amount"`
+	TotalDue          models.Money `json:"total_due"`
+	Subject           string       `json:"subject"`
+	Content           string       `json:"content"`
+	TriggerType       string       `json:"trigger_type"`
+	Status            string       `json:"status"`
+	SentAt            *time.Time   `json:"sent_at,omitempty"`
+	CreatedAt         time.Time    `json:"created_at"`
+}
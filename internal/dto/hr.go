@@ -2,6 +2,8 @@ package dto
 
 import (
 	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
 )
 
 // EmployeeCreateRequest 员工创建请求
@@ -174,33 +176,33 @@ type AttendanceResponse struct {
 
 // PayrollCreateRequest 工资单创建请求
 type PayrollCreateRequest struct {
-	EmployeeID      uint      `json:"employee_id" validate:"required"`
-	PayPeriodStart  time.Time `json:"pay_period_start" validate:"required"`
-	PayPeriodEnd    time.Time `json:"pay_period_end" validate:"required"`
-	BasicSalary     float64   `json:"basic_salary" validate:"required,min=0"`
-	OvertimePay     float64   `json:"overtime_pay,omitempty" validate:"omitempty,min=0"`
-	Allowance       float64   `json:"allowance,omitempty" validate:"omitempty,min=0"`
-	Bonus           float64   `json:"bonus,omitempty" validate:"omitempty,min=0"`
-	Deductions      float64   `json:"deductions,omitempty" validate:"omitempty,min=0"`
-	SocialInsurance float64   `json:"social_insurance,omitempty" validate:"omitempty,min=0"`
-	HousingFund     float64   `json:"housing_fund,omitempty" validate:"omitempty,min=0"`
-	Tax             float64   `json:"tax,omitempty" validate:"omitempty,min=0"`
-	NetPay          float64   `json:"net_pay" validate:"required,min=0"`
-	Status          string    `json:"status" validate:"required,oneof=draft confirmed paid"`
+	EmployeeID      uint         `json:"employee_id" validate:"required"`
+	PayPeriodStart  time.Time    `json:"pay_period_start" validate:"required"`
+	PayPeriodEnd    time.Time    `json:"pay_period_end" validate:"required"`
+	BasicSalary     models.Money `json:"basic_salary" validate:"required,min=0"`
+	OvertimePay     models.Money `json:"overtime_pay,omitempty" validate:"omitempty,min=0"`
+	Allowance       models.Money `json:"allowance,omitempty" validate:"omitempty,min=0"`
+	Bonus           models.Money `json:"bonus,omitempty" validate:"omitempty,min=0"`
+	Deductions      models.Money `json:"deductions,omitempty" validate:"omitempty,min=0"`
+	SocialInsurance models.Money `json:"social_insurance,omitempty" validate:"omitempty,min=0"`
+	HousingFund     models.Money `json:"housing_fund,omitempty" validate:"omitempty,min=0"`
+	Tax             models.Money `json:"tax,omitempty" validate:"omitempty,min=0"`
+	NetPay          models.Money `json:"net_pay" validate:"required,min=0"`
+	Status          string       `json:"status" validate:"required,oneof=draft confirmed paid"`
 }
 
 // PayrollUpdateRequest 工资单更新请求
 type PayrollUpdateRequest struct {
-	BasicSalary     *float64 `json:"basic_salary,omitempty" validate:"omitempty,min=0"`
-	OvertimePay     *float64 `json:"overtime_pay,omitempty" validate:"omitempty,min=0"`
-	Allowance       *float64 `json:"allowance,omitempty" validate:"omitempty,min=0"`
-	Bonus           *float64 `json:"bonus,omitempty" validate:"omitempty,min=0"`
-	Deductions      *float64 `json:"deductions,omitempty" validate:"omitempty,min=0"`
-	SocialInsurance *float64 `json:"social_insurance,omitempty" validate:"omitempty,min=0"`
-	HousingFund     *float64 `json:"housing_fund,omitempty" validate:"omitempty,min=0"`
-	Tax             *float64 `json:"tax,omitempty" validate:"omitempty,min=0"`
-	NetPay          *float64 `json:"net_pay,omitempty" validate:"omitempty,min=0"`
-	Status          string   `json:"status,omitempty" validate:"omitempty,oneof=draft confirmed paid"`
+	BasicSalary     *models.Money `json:"basic_salary,omitempty" validate:"omitempty,min=0"`
+	OvertimePay     *models.Money `json:"overtime_pay,omitempty" validate:"omitempty,min=0"`
+	Allowance       *models.Money `json:"allowance,omitempty" validate:"omitempty,min=0"`
+	Bonus           *models.Money `json:"bonus,omitempty" validate:"omitempty,min=0"`
+	Deductions      *models.Money `json:"deductions,omitempty" validate:"omitempty,min=0"`
+	SocialInsurance *models.Money `json:"social_insurance,omitempty" validate:"omitempty,min=0"`
+	HousingFund     *models.Money `json:"housing_fund,omitempty" validate:"omitempty,min=0"`
+	Tax             *models.Money `json:"tax,omitempty" validate:"omitempty,min=0"`
+	NetPay          *models.Money `json:"net_pay,omitempty" validate:"omitempty,min=0"`
+	Status          string        `json:"status,omitempty" validate:"omitempty,oneof=draft confirmed paid"`
 }
 
 // PayrollResponse 薪资响应
@@ -209,15 +211,15 @@ type PayrollResponse struct {
 	EmployeeID      uint                  `json:"employee_id"`
 	PayPeriodStart  time.Time             `json:"pay_period_start"`
 	PayPeriodEnd    time.Time             `json:"pay_period_end"`
-	BasicSalary     float64               `json:"basic_salary"`
-	OvertimePay     float64               `json:"overtime_pay"`
-	Allowance       float64               `json:"allowance"`
-	Bonus           float64               `json:"bonus"`
-	Deductions      float64               `json:"deductions"`
-	SocialInsurance float64               `json:"social_insurance"`
-	HousingFund     float64               `json:"housing_fund"`
-	Tax             float64               `json:"tax"`
-	NetPay          float64               `json:"net_pay"`
+	BasicSalary     models.Money          `json:"basic_salary"`
+	OvertimePay     models.Money          `json:"overtime_pay"`
+	Allowance       models.Money          `json:"allowance"`
+	Bonus           models.Money          `json:"bonus"`
+	Deductions      models.Money          `json:"deductions"`
+	SocialInsurance models.Money          `json:"social_insurance"`
+	HousingFund     models.Money          `json:"housing_fund"`
+	Tax             models.Money          `json:"tax"`
+	NetPay          models.Money          `json:"net_pay"`
 	Status          string                `json:"status"`
 	PaidAt          *time.Time            `json:"paid_at,omitempty"`
 	Employee        *EmployeeListResponse `json:"employee,omitempty"`
@@ -0,0 +1,119 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// IntercompanyTransactionListRequest 内部交易列表请求
+type IntercompanyTransactionListRequest struct {
+	PaginationRequest
+	CompanyID     *uint  `json:"company_id,omitempty" form:"company_id"`
+	SourceDocType string `json:"source_doc_type,omitempty" form:"source_doc_type"`
+	Status        string `json:"status,omitempty" form:"status"`
+}
+
+// IntercompanyTransactionResponse 内部交易响应
+type IntercompanyTransactionResponse struct {
+	ID                uint         `json:"id"`
+	SourceCompanyID   uint         `json:"source_company_id"`
+	SourceCompanyName string       `json:"source_company_name,omitempty"`
+	TargetCompanyID   uint         `json:"target_company_id"`
+	TargetCompanyName string       `json:"target_company_name,omitempty"`
+	SourceDocType     string       `json:"source_doc_type"`
+	SourceDocID       uint         `json:"source_doc_id"`
+	SourceDocNumber   string       `json:"source_doc_number"`
+	TargetDocType     string       `json:"target_doc_type"`
+	TargetDocID       uint         `json:"target_doc_id"`
+	TargetDocNumber   string       `json:"target_doc_number"`
+	Amount            models.Money `json:"amount"`
+	Currency          string       `json:"currency"`
+	TransactionDate   time.Time    `json:"transaction_date"`
+	Status            string       `json:"status"`
+	CreatedAt         time.Time    `json:"created_at"`
+}
+
+// TrialBalanceRequest 试算平衡表请求
+type TrialBalanceRequest struct {
+	CompanyID uint      `json:"company_id" form:"company_id"`
+	StartDate time.Time `json:"start_date" form:"start_date" time_format:"2006-01-02" validate:"required"`
+	EndDate   time.Time `json:"end_date" form:"end_date" time_format:"2006-01-02" validate:"required"`
+}
+
+// ConsolidatedTrialBalanceRequest 合并试算平衡表请求
+// 指定 parent_company_id 时合并范围为该公司及其全部下级公司，否则使用 company_ids
+type ConsolidatedTrialBalanceRequest struct {
+	ParentCompanyID *uint     `json:"parent_company_id,omitempty" form:"parent_company_id"`
+	CompanyIDs      []uint    `json:"company_ids,omitempty" form:"company_ids"`
+	StartDate       time.Time `json:"start_date" form:"start_date" time_format:"2006-01-02" validate:"required"`
+	EndDate         time.Time `json:"end_date" form:"end_date" time_format:"2006-01-02" validate:"required"`
+}
+
+// TrialBalanceRow 试算平衡汇总行（按公司、科目编码及是否内部往来分组）
+type TrialBalanceRow struct {
+	CompanyID      uint         `json:"company_id"`
+	AccountCode    string       `json:"account_code"`
+	AccountName    string       `json:"account_name"`
+	AccountType    string       `json:"account_type"`
+	Intercompany   bool         `json:"intercompany"`
+	OpeningBalance models.Money `json:"opening_balance"`
+	Debit          models.Money `json:"debit"`
+	Credit         models.Money `json:"credit"`
+}
+
+// TrialBalanceLine 试算平衡表明细行
+type TrialBalanceLine struct {
+	AccountCode    string       `json:"account_code"`
+	AccountName    string       `json:"account_name"`
+	AccountType    string       `json:"account_type"`
+	OpeningBalance models.Money `json:"opening_balance"`
+	Debit          models.Money `json:"debit"`
+	Credit         models.Money `json:"credit"`
+	ClosingBalance models.Money `json:"closing_balance"`
+}
+
+// TrialBalanceResponse 试算平衡表响应
+type TrialBalanceResponse struct {
+	CompanyID   uint               `json:"company_id"`
+	CompanyName string             `json:"company_name"`
+	StartDate   time.Time          `json:"start_date"`
+	EndDate     time.Time          `json:"end_date"`
+	Lines       []TrialBalanceLine `json:"lines"`
+	TotalDebit  models.Money       `json:"total_debit"`
+	TotalCredit models.Money       `json:"total_credit"`
+	IsBalanced  bool               `json:"is_balanced"`
+}
+
+// ConsolidatedTrialBalanceLine 合并试算平衡表明细行
+type ConsolidatedTrialBalanceLine struct {
+	AccountCode            string                `json:"account_code"`
+	AccountName            string                `json:"account_name"`
+	AccountType            string                `json:"account_type"`
+	OpeningBalance         models.Money          `json:"opening_balance"`
+	Debit                  models.Money          `json:"debit"`
+	Credit                 models.Money          `json:"credit"`
+	EliminationOpening     models.Money          `json:"elimination_opening"`
+	EliminationDebit       models.Money          `json:"elimination_debit"`
+	EliminationCredit      models.Money          `json:"elimination_credit"`
+	ConsolidatedOpening    models.Money          `json:"consolidated_opening"`
+	ConsolidatedDebit      models.Money          `json:"consolidated_debit"`
+	ConsolidatedCredit     models.Money          `json:"consolidated_credit"`
+	ConsolidatedClosing    models.Money          `json:"consolidated_closing"`
+	CompanyClosingBalances map[uint]models.Money `json:"company_closing_balances"`
+}
+
+// ConsolidatedTrialBalanceResponse 合并试算平衡表响应
+type ConsolidatedTrialBalanceResponse struct {
+	CompanyIDs              []uint                         `json:"company_ids"`
+	StartDate               time.Time                      `json:"start_date"`
+	EndDate                 time.Time                      `json:"end_date"`
+	Lines                   []ConsolidatedTrialBalanceLine `json:"lines"`
+	TotalDebit              models.Money                   `json:"total_debit"`
+	TotalCredit             models.Money                   `json:"total_credit"`
+	TotalEliminationDebit   models.Money                   `json:"total_elimination_debit"`
+	TotalEliminationCredit  models.Money                   `json:"total_elimination_credit"`
+	ConsolidatedTotalDebit  models.Money                   `json:"consolidated_total_debit"`
+	ConsolidatedTotalCredit models.Money                   `json:"consolidated_total_credit"`
+	IsBalanced              bool                           `json:"is_balanced"`
+}
@@ -2,72 +2,98 @@ package dto
 
 import (
 	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
 )
 
 // ItemCreateRequest 物料创建请求
 type ItemCreateRequest struct {
-	Code        string  `json:"code" validate:"required,max=50"`
-	Name        string  `json:"name" validate:"required,max=100"`
-	Description string  `json:"description,omitempty"`
-	CategoryID  uint    `json:"category_id" validate:"required"`
-	UnitID      uint    `json:"unit_id" validate:"required"`
-	Type        string  `json:"type" validate:"required,oneof=raw_material finished_goods semi_finished consumable"`
-	MinStock    float64 `json:"min_stock,omitempty" validate:"min=0"`
-	MaxStock    float64 `json:"max_stock,omitempty" validate:"min=0"`
-	UnitCost    float64 `json:"unit_cost,omitempty" validate:"min=0"`
-	SalePrice   float64 `json:"sale_price,omitempty" validate:"min=0"`
-	Barcode     string  `json:"barcode,omitempty"`
-	ImageURL    string  `json:"image_url,omitempty"`
+	Code                  string       `json:"code" validate:"required,max=50"`
+	Name                  string       `json:"name" validate:"required,max=100"`
+	Description           string       `json:"description,omitempty"`
+	CategoryID            uint         `json:"category_id" validate:"required"`
+	UnitID                uint         `json:"unit_id" validate:"required"`
+	Type                  string       `json:"type" validate:"required,oneof=raw_material finished_goods semi_finished consumable"`
+	MinStock              float64      `json:"min_stock,omitempty" validate:"min=0"` // 再订货点
+	MaxStock              float64      `json:"max_stock,omitempty" validate:"min=0"` // 最高库存，补货时补至该库存
+	ReorderQty            float64      `json:"reorder_qty,omitempty" validate:"min=0"`
+	PreferredSupplierID   *uint        `json:"preferred_supplier_id,omitempty"`
+	UnitCost              models.Money `json:"unit_cost,omitempty" validate:"min=0"`
+	SalePrice             models.Money `json:"sale_price,omitempty" validate:"min=0"`
+	Barcode               string       `json:"barcode,omitempty"`
+	ImageURL              string       `json:"image_url,omitempty"`
+	ValuationMethod       string       `json:"valuation_method,omitempty" validate:"omitempty,oneof=fifo moving_average standard"`
+	TrackingMode          string       `json:"tracking_mode,omitempty" validate:"omitempty,oneof=none batch serial"`
+	ShelfLifeDays         int          `json:"shelf_life_days,omitempty" validate:"min=0"`                 // 保质期天数，入库新建批次未填有效期时按入库日期推算
+	Weight                float64      `json:"weight,omitempty" validate:"min=0"`                          // 单位重量（千克）
+	OverDeliveryAllowance float64      `json:"over_delivery_allowance,omitempty" validate:"min=0,max=100"` // 允许超出销售订单数量发货的百分比
 }
 
 // ItemUpdateRequest 物料更新请求
 type ItemUpdateRequest struct {
-	Name        string   `json:"name,omitempty" validate:"omitempty,max=100"`
-	Description string   `json:"description,omitempty"`
-	CategoryID  *uint    `json:"category_id,omitempty"`
-	UnitID      *uint    `json:"unit_id,omitempty"`
-	MinStock    *float64 `json:"min_stock,omitempty" validate:"omitempty,min=0"`
-	MaxStock    *float64 `json:"max_stock,omitempty" validate:"omitempty,min=0"`
-	UnitCost    *float64 `json:"unit_cost,omitempty" validate:"omitempty,min=0"`
-	SalePrice   *float64 `json:"sale_price,omitempty" validate:"omitempty,min=0"`
-	Barcode     string   `json:"barcode,omitempty"`
-	ImageURL    string   `json:"image_url,omitempty"`
-	IsActive    *bool    `json:"is_active,omitempty"`
+	Name                  string        `json:"name,omitempty" validate:"omitempty,max=100"`
+	Description           string        `json:"description,omitempty"`
+	CategoryID            *uint         `json:"category_id,omitempty"`
+	UnitID                *uint         `json:"unit_id,omitempty"`
+	MinStock              *float64      `json:"min_stock,omitempty" validate:"omitempty,min=0"`
+	MaxStock              *float64      `json:"max_stock,omitempty" validate:"omitempty,min=0"`
+	ReorderQty            *float64      `json:"reorder_qty,omitempty" validate:"omitempty,min=0"`
+	PreferredSupplierID   *uint         `json:"preferred_supplier_id,omitempty"` // 为 0 时清除首选供应商
+	UnitCost              *models.Money `json:"unit_cost,omitempty" validate:"omitempty,min=0"`
+	SalePrice             *models.Money `json:"sale_price,omitempty" validate:"omitempty,min=0"`
+	Barcode               string        `json:"barcode,omitempty"`
+	ImageURL              string        `json:"image_url,omitempty"`
+	IsActive              *bool         `json:"is_active,omitempty"`
+	ValuationMethod       string        `json:"valuation_method,omitempty" validate:"omitempty,oneof=fifo moving_average standard"` // 仅物料无库存时可修改
+	TrackingMode          string        `json:"tracking_mode,omitempty" validate:"omitempty,oneof=none batch serial"`               // 仅物料无库存时可修改
+	ShelfLifeDays         *int          `json:"shelf_life_days,omitempty" validate:"omitempty,min=0"`
+	Weight                *float64      `json:"weight,omitempty" validate:"omitempty,min=0"`
+	OverDeliveryAllowance *float64      `json:"over_delivery_allowance,omitempty" validate:"omitempty,min=0,max=100"`
 }
 
 // ItemResponse 物料响应
 type ItemResponse struct {
-	ID          uint             `json:"id"`
-	Code        string           `json:"code"`
-	Name        string           `json:"name"`
-	Description string           `json:"description,omitempty"`
-	Type        string           `json:"type"`
-	MinStock    float64          `json:"min_stock"`
-	MaxStock    float64          `json:"max_stock"`
-	UnitCost    float64          `json:"unit_cost"`
-	SalePrice   float64          `json:"sale_price"`
-	Barcode     string           `json:"barcode,omitempty"`
-	ImageURL    string           `json:"image_url,omitempty"`
-	IsActive    bool             `json:"is_active"`
-	Category    CategoryResponse `json:"category"`
-	Unit        UnitResponse     `json:"unit"`
-	Stock       []StockResponse  `json:"stock,omitempty"`
-	CreatedAt   time.Time        `json:"created_at"`
-	UpdatedAt   time.Time        `json:"updated_at"`
+	ID                    uint                           `json:"id"`
+	Code                  string                         `json:"code"`
+	Name                  string                         `json:"name"`
+	Description           string                         `json:"description,omitempty"`
+	Type                  string                         `json:"type"`
+	MinStock              float64                        `json:"min_stock"`
+	MaxStock              float64                        `json:"max_stock"`
+	ReorderQty            float64                        `json:"reorder_qty"`
+	PreferredSupplierID   *uint                          `json:"preferred_supplier_id,omitempty"`
+	UnitCost              models.Money                   `json:"unit_cost"`
+	SalePrice             models.Money                   `json:"sale_price"`
+	Barcode               string                         `json:"barcode,omitempty"`
+	ImageURL              string                         `json:"image_url,omitempty"`
+	IsActive              bool                           `json:"is_active"`
+	ValuationMethod       string                         `json:"valuation_method,omitempty"`
+	TrackingMode          string                         `json:"tracking_mode,omitempty"`
+	ShelfLifeDays         int                            `json:"shelf_life_days"`
+	Weight                float64                        `json:"weight"`
+	OverDeliveryAllowance float64                        `json:"over_delivery_allowance"`
+	HasVariants           bool                           `json:"has_variants"`
+	VariantOf             *uint                          `json:"variant_of,omitempty"`
+	Attributes            []ItemVariantAttributeResponse `json:"attributes,omitempty"` // 变体的属性取值
+	Category              CategoryResponse               `json:"category"`
+	Unit                  UnitResponse                   `json:"unit"`
+	Stock                 []StockResponse                `json:"stock,omitempty"`
+	CreatedAt             time.Time                      `json:"created_at"`
+	UpdatedAt             time.Time                      `json:"updated_at"`
 }
 
 // ItemListResponse 物料列表响应
 type ItemListResponse struct {
-	ID         uint    `json:"id"`
-	Code       string  `json:"code"`
-	Name       string  `json:"name"`
-	Type       string  `json:"type"`
-	Category   string  `json:"category"`
-	Unit       string  `json:"unit"`
-	UnitCost   float64 `json:"unit_cost"`
-	SalePrice  float64 `json:"sale_price"`
-	TotalStock float64 `json:"total_stock"`
-	IsActive   bool    `json:"is_active"`
+	ID         uint         `json:"id"`
+	Code       string       `json:"code"`
+	Name       string       `json:"name"`
+	Type       string       `json:"type"`
+	Category   string       `json:"category"`
+	Unit       string       `json:"unit"`
+	UnitCost   models.Money `json:"unit_cost"`
+	SalePrice  models.Money `json:"sale_price"`
+	TotalStock float64      `json:"total_stock"`
+	IsActive   bool         `json:"is_active"`
 }
 
 
@@ -168,32 +194,43 @@ type WarehouseResponse struct {
 
 // LocationCreateRequest 库位创建请求
 type LocationCreateRequest struct {
-	Name        string `json:"name" validate:"required,max=100"`
-	Code        string `json:"code" validate:"required,max=50"`
-	WarehouseID uint   `json:"warehouse_id" validate:"required"`
-	Type        string `json:"type" validate:"required,oneof=storage picking shipping receiving"`
-	Description string `json:"description,omitempty"`
+	Name        string  `json:"name" validate:"required,max=100"`
+	Code        string  `json:"code" validate:"required,max=50"`
+	WarehouseID uint    `json:"warehouse_id,omitempty"` // 由路径中的仓库ID指定
+	Type        string  `json:"type" validate:"required,oneof=storage picking shipping receiving quarantine"`
+	Description string  `json:"description,omitempty"`
+	Capacity    float64 `json:"capacity,omitempty" validate:"min=0"` // 0 表示不限容量
+	Sequence    int     `json:"sequence,omitempty"`
+	Zone        string  `json:"zone,omitempty" validate:"max=50"`
 }
 
 // LocationUpdateRequest 库位更新请求
 type LocationUpdateRequest struct {
-	Name        string `json:"name,omitempty" validate:"omitempty,max=100"`
-	Type        string `json:"type,omitempty" validate:"omitempty,oneof=storage picking shipping receiving"`
-	Description string `json:"description,omitempty"`
-	IsActive    *bool  `json:"is_active,omitempty"`
+	Name        string   `json:"name,omitempty" validate:"omitempty,max=100"`
+	Type        string   `json:"type,omitempty" validate:"omitempty,oneof=storage picking shipping receiving quarantine"`
+	Description string   `json:"description,omitempty"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+	Capacity    *float64 `json:"capacity,omitempty" validate:"omitempty,min=0"`
+	Sequence    *int     `json:"sequence,omitempty"`
+	Zone        *string  `json:"zone,omitempty" validate:"omitempty,max=50"`
 }
 
 // LocationResponse 库位响应
 type LocationResponse struct {
-	ID          uint              `json:"id"`
-	Name        string            `json:"name"`
-	Code        string            `json:"code"`
-	Type        string            `json:"type"`
-	Description string            `json:"description,omitempty"`
-	IsActive    bool              `json:"is_active"`
-	Warehouse   WarehouseResponse `json:"warehouse"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
+	ID           uint               `json:"id"`
+	Name         string             `json:"name"`
+	Code         string             `json:"code"`
+	Type         string             `json:"type"`
+	Description  string             `json:"description,omitempty"`
+	IsActive     bool               `json:"is_active"`
+	WarehouseID  uint               `json:"warehouse_id"`
+	Capacity     float64            `json:"capacity"`
+	UsedQuantity float64            `json:"used_quantity"`
+	Sequence     int                `json:"sequence"`
+	Zone         string             `json:"zone,omitempty"`
+	Warehouse    *WarehouseResponse `json:"warehouse,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
 }
 
 // StockCreateRequest 库存创建请求
@@ -210,39 +247,70 @@ type StockUpdateRequest struct {
 
 // StockResponse 库存响应
 type StockResponse struct {
-	ID           uint              `json:"id"`
-	Quantity     float64           `json:"quantity"`
-	ReservedQty  float64           `json:"reserved_qty"`
-	AvailableQty float64           `json:"available_qty"`
-	Item         ItemResponse      `json:"item"`
-	Warehouse    WarehouseResponse `json:"warehouse"`
-	Location     LocationResponse  `json:"location"`
-	UpdatedAt    time.Time         `json:"updated_at"`
+	ID            uint              `json:"id"`
+	Quantity      float64           `json:"quantity"`
+	ReservedQty   float64           `json:"reserved_qty"`
+	AvailableQty  float64           `json:"available_qty"`
+	StockValue    models.Money      `json:"stock_value"`
+	ValuationRate models.Money      `json:"valuation_rate"`
+	Item          ItemResponse      `json:"item"`
+	Warehouse     WarehouseResponse `json:"warehouse"`
+	Location      LocationResponse  `json:"location"`
+	UpdatedAt     time.Time         `json:"updated_at"`
 }
 
 // MovementCreateRequest 库存移动创建请求
 type MovementCreateRequest struct {
-	ItemID      uint    `json:"item_id" validate:"required"`
-	WarehouseID uint    `json:"warehouse_id" validate:"required"`
-	LocationID  uint    `json:"location_id" validate:"required"`
-	Type        string  `json:"type" validate:"required,oneof=in out transfer adjustment"`
-	Quantity    float64 `json:"quantity" validate:"required,gt=0"`
-	Reference   string  `json:"reference,omitempty"`
-	Notes       string  `json:"notes,omitempty"`
+	ItemID          uint         `json:"item_id" validate:"required"`
+	WarehouseID     uint         `json:"warehouse_id" validate:"required"`
+	LocationID      uint         `json:"location_id,omitempty"` // 为空时入库计入未分配库存，出库先扣减未分配库存
+	Type            string       `json:"type" validate:"required,oneof=in out adjustment"`
+	Quantity        float64      `json:"quantity" validate:"required,gt=0"`
+	UOM             string       `json:"uom,omitempty" validate:"max=50"` // 数量与单位成本的单位，为空时为库存单位
+	Reference       string       `json:"reference,omitempty"`
+	Notes           string       `json:"notes,omitempty"`
+	UnitCost        models.Money `json:"unit_cost,omitempty" validate:"min=0"` // 入库单位成本，为空时按物料计价方法取成本
+	ReferenceType   string       `json:"reference_type,omitempty"`
+	ReferenceID     *uint        `json:"reference_id,omitempty"`
+	ReferenceLineID *uint        `json:"reference_line_id,omitempty"`
+	IdempotencyKey  string       `json:"idempotency_key,omitempty" validate:"max=191"` // 为空时按来源单据行生成
+	BatchNo         string       `json:"batch_no,omitempty" validate:"max=100"`        // 批次管理物料入库必填，批次不存在则自动创建；出库为空时按先到期先出分配
+	SerialNo        string       `json:"serial_no,omitempty"`                          // 序列号管理物料必填，多个序列号以逗号分隔
+	ExpiryDate      *time.Time   `json:"expiry_date,omitempty"`                        // 新批次的有效期
 }
 
 // MovementResponse 库存移动响应
 type MovementResponse struct {
-	ID        uint              `json:"id"`
-	Type      string            `json:"type"`
-	Quantity  float64           `json:"quantity"`
-	Reference string            `json:"reference,omitempty"`
-	Notes     string            `json:"notes,omitempty"`
-	Item      ItemResponse      `json:"item"`
-	Warehouse WarehouseResponse `json:"warehouse"`
-	Location  LocationResponse  `json:"location"`
-	CreatedBy UserResponse      `json:"created_by"`
-	CreatedAt time.Time         `json:"created_at"`
+	ID               uint                      `json:"id"`
+	Type             string                    `json:"type"`
+	Quantity         float64                   `json:"quantity"` // 库存单位数量
+	UOM              string                    `json:"uom,omitempty"`
+	UOMQuantity      float64                   `json:"uom_quantity,omitempty"`
+	ConversionFactor float64                   `json:"conversion_factor,omitempty"`
+	QuantityChange   float64                   `json:"quantity_change"`
+	BalanceAfter     float64                   `json:"balance_after"`
+	UnitCost         models.Money              `json:"unit_cost"`
+	TotalCost        models.Money              `json:"total_cost"`
+	ValueChange      models.Money              `json:"value_change"`
+	ValueAfter       models.Money              `json:"value_after"`
+	BatchNo          string                    `json:"batch_no,omitempty"`
+	SerialNo         string                    `json:"serial_no,omitempty"`
+	Reference        string                    `json:"reference,omitempty"`
+	Notes            string                    `json:"notes,omitempty"`
+	BatchAllocations []MovementBatchAllocation `json:"batch_allocations,omitempty"` // 未指定批次的出库按先到期先出分配的批次
+	Item             ItemResponse              `json:"item"`
+	Warehouse        WarehouseResponse         `json:"warehouse"`
+	Location         LocationResponse          `json:"location"`
+	CreatedBy        UserResponse              `json:"created_by"`
+	CreatedAt        time.Time                 `json:"created_at"`
+}
+
+// MovementBatchAllocation 出库按先到期先出分配到的批次及对应的库存移动
+type MovementBatchAllocation struct {
+	MovementID uint       `json:"movement_id"`
+	BatchNo    string     `json:"batch_no"`
+	ExpiryDate *time.Time `json:"expiry_date,omitempty"`
+	Quantity   float64    `json:"quantity"`
 }
 
 // StockAdjustmentCreateRequest 库存调整创建请求
@@ -290,11 +358,14 @@ type StockAdjustmentItemResponse struct {
 // ItemSearchRequest 物料搜索请求
 type ItemSearchRequest struct {
 	SearchRequest
-	CategoryID *uint    `json:"category_id,omitempty" form:"category_id"`
-	Type       string   `json:"type,omitempty" form:"type"`
-	IsActive   *bool    `json:"is_active,omitempty" form:"is_active"`
-	MinPrice   *float64 `json:"min_price,omitempty" form:"min_price"`
-	MaxPrice   *float64 `json:"max_price,omitempty" form:"max_price"`
+	CategoryID  *uint             `json:"category_id,omitempty" form:"category_id"`
+	Type        string            `json:"type,omitempty" form:"type"`
+	IsActive    *bool             `json:"is_active,omitempty" form:"is_active"`
+	MinPrice    *models.Money     `json:"min_price,omitempty" form:"min_price"`
+	MaxPrice    *models.Money     `json:"max_price,omitempty" form:"max_price"`
+	TemplateID  *uint             `json:"template_id,omitempty" form:"template_id"`
+	HasVariants *bool             `json:"has_variants,omitempty" form:"has_variants"`
+	Attributes  map[string]string `json:"attributes,omitempty" form:"-"` // 属性编码 -> 取值
 }
 
 // StockSearchRequest 库存搜索请求
@@ -313,3 +384,28 @@ type StockReportRequest struct {
 	AsOfDate    time.Time `json:"as_of_date,omitempty" form:"as_of_date"`
 	Format      string    `json:"format" form:"format" validate:"required,oneof=excel pdf csv"`
 }
+
+// StockReconcileRequest 库存对账请求，按台账重算库存余额并报告差异
+type StockReconcileRequest struct {
+	ItemID      uint `json:"item_id,omitempty" form:"item_id"`
+	WarehouseID uint `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	Apply       bool `json:"apply" form:"apply"` // 为 true 时按台账修正库存余额
+}
+
+// StockDiscrepancy 库存余额与台账不一致的记录
+type StockDiscrepancy struct {
+	ItemID         uint    `json:"item_id"`
+	WarehouseID    uint    `json:"warehouse_id"`
+	StockQuantity  float64 `json:"stock_quantity"`
+	LedgerQuantity float64 `json:"ledger_quantity"`
+	Difference     float64 `json:"difference"`
+	Fixed          bool    `json:"fixed"`
+}
+
+// StockReconcileResult 库存对账结果
+type StockReconcileResult struct {
+	CheckedAt     time.Time          `json:"checked_at"`
+	Checked       int                `json:"checked"`
+	Applied       bool               `json:"applied"`
+	Discrepancies []StockDiscrepancy `json:"discrepancies"`
+}
@@ -0,0 +1,227 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// InventoryStatsRequest 库存统计请求
+type InventoryStatsRequest struct {
+	WarehouseID uint `json:"warehouse_id,omitempty" form:"warehouse_id"`
+}
+
+// WarehouseStockStats 单个仓库的库存统计
+type WarehouseStockStats struct {
+	WarehouseID   uint         `json:"warehouse_id"`
+	WarehouseCode string       `json:"warehouse_code"`
+	WarehouseName string       `json:"warehouse_name"`
+	SKUCount      int          `json:"sku_count"`
+	TotalQuantity float64      `json:"total_quantity"`
+	TotalValue    models.Money `json:"total_value"`
+	LowStockCount int          `json:"low_stock_count"` // 库存不高于再订货点的物料数
+}
+
+// InventoryStatsResponse 库存统计响应
+type InventoryStatsResponse struct {
+	SKUCount      int                   `json:"sku_count"` // 有库存的物料数
+	TotalQuantity float64               `json:"total_quantity"`
+	TotalValue    models.Money          `json:"total_value"`
+	LowStockCount int                   `json:"low_stock_count"` // 全部仓库合计库存不高于再订货点的物料数
+	Warehouses    []WarehouseStockStats `json:"warehouses"`
+}
+
+// InventoryReportFilter 库存报表筛选条件
+type InventoryReportFilter struct {
+	ItemID          uint   `json:"item_id,omitempty" form:"item_id"`
+	WarehouseID     uint   `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	Category        string `json:"category,omitempty" form:"category"`
+	Keyword         string `json:"keyword,omitempty" form:"keyword"` // 匹配物料编码或名称
+	LowStockOnly    bool   `json:"low_stock_only,omitempty" form:"low_stock_only"`
+	IncludeZero     bool   `json:"include_zero,omitempty" form:"include_zero"`
+	GroupByTemplate bool   `json:"group_by_template,omitempty" form:"group_by_template"` // 变体库存汇总到模板物料
+}
+
+// InventoryReportRequest 库存报表请求
+type InventoryReportRequest struct {
+	PaginationRequest
+	InventoryReportFilter
+}
+
+// InventoryReportLine 库存报表明细行
+type InventoryReportLine struct {
+	ItemID        uint         `json:"item_id"`
+	ItemCode      string       `json:"item_code"`
+	ItemName      string       `json:"item_name"`
+	Category      string       `json:"category,omitempty"`
+	Unit          string       `json:"unit,omitempty"`
+	WarehouseID   uint         `json:"warehouse_id"`
+	WarehouseCode string       `json:"warehouse_code"`
+	WarehouseName string       `json:"warehouse_name"`
+	Quantity      float64      `json:"quantity"`
+	ReorderLevel  int          `json:"reorder_level"`
+	ValuationRate models.Money `json:"valuation_rate"`
+	StockValue    models.Money `json:"stock_value"`
+	IsLowStock    bool         `json:"is_low_stock"`
+}
+
+// ABCAnalysisRequest ABC 分析请求；未指定日期时按截止今天的 days 天统计，
+// 消耗金额累计占比不超过 a_threshold 为 A 类，不超过 b_threshold 为 B 类，其余为 C 类
+type ABCAnalysisRequest struct {
+	StartDate       time.Time `json:"start_date" form:"start_date" time_format:"2006-01-02"`
+	EndDate         time.Time `json:"end_date" form:"end_date" time_format:"2006-01-02"`
+	Days            int       `json:"days,omitempty" form:"days" validate:"omitempty,min=1,max=3660"`
+	WarehouseID     uint      `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	Category        string    `json:"category,omitempty" form:"category"`
+	AThreshold      float64   `json:"a_threshold,omitempty" form:"a_threshold" validate:"omitempty,gt=0,lt=100"`
+	BThreshold      float64   `json:"b_threshold,omitempty" form:"b_threshold" validate:"omitempty,gt=0,lte=100"`
+	GroupByTemplate bool      `json:"group_by_template,omitempty" form:"group_by_template"` // 变体消耗汇总到模板物料
+}
+
+// ABCAnalysisItem 物料 ABC 分类结果
+type ABCAnalysisItem struct {
+	ItemID           uint         `json:"item_id"`
+	ItemCode         string       `json:"item_code"`
+	ItemName         string       `json:"item_name"`
+	Category         string       `json:"category,omitempty"`
+	ConsumedQty      float64      `json:"consumed_qty"`
+	ConsumptionValue models.Money `json:"consumption_value"`
+	Share            float64      `json:"share"`            // 占总消耗金额的百分比
+	CumulativeShare  float64      `json:"cumulative_share"` // 按消耗金额降序的累计百分比
+	Class            string       `json:"class"`
+}
+
+// ABCClassSummary ABC 分类汇总
+type ABCClassSummary struct {
+	Class            string       `json:"class"`
+	ItemCount        int          `json:"item_count"`
+	ConsumptionValue models.Money `json:"consumption_value"`
+	Share            float64      `json:"share"`
+}
+
+// ABCAnalysisResponse ABC 分析响应
+type ABCAnalysisResponse struct {
+	StartDate  time.Time         `json:"start_date"`
+	EndDate    time.Time         `json:"end_date"`
+	AThreshold float64           `json:"a_threshold"`
+	BThreshold float64           `json:"b_threshold"`
+	TotalValue models.Money      `json:"total_value"`
+	Summary    []ABCClassSummary `json:"summary"`
+	Items      []ABCAnalysisItem `json:"items"`
+}
+
+// InventoryAgingRequest 库存账龄请求；buckets 为逗号分隔的各账龄段上限天数，
+// 如 30,60,90,180 划分为 0-30、31-60、61-90、91-180 与 180 天以上
+type InventoryAgingRequest struct {
+	ItemID      uint   `json:"item_id,omitempty" form:"item_id"`
+	WarehouseID uint   `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	Category    string `json:"category,omitempty" form:"category"`
+	Keyword     string `json:"keyword,omitempty" form:"keyword"` // 匹配物料编码或名称
+	Buckets     string `json:"buckets,omitempty" form:"buckets"`
+}
+
+// InventoryAgingBucket 账龄段的在库数量与金额，MaxDays 为 0 表示不设上限
+type InventoryAgingBucket struct {
+	Label    string       `json:"label"`
+	MinDays  int          `json:"min_days"`
+	MaxDays  int          `json:"max_days,omitempty"`
+	Quantity float64      `json:"quantity"`
+	Value    models.Money `json:"value"`
+}
+
+// InventoryAgingLine 物料在仓库的库存账龄；在库数量按先进先出归属到最近的入库，
+// 入库记录不足以覆盖的数量计入最长账龄段
+type InventoryAgingLine struct {
+	ItemID            uint                   `json:"item_id"`
+	ItemCode          string                 `json:"item_code"`
+	ItemName          string                 `json:"item_name"`
+	Category          string                 `json:"category,omitempty"`
+	WarehouseID       uint                   `json:"warehouse_id"`
+	WarehouseCode     string                 `json:"warehouse_code"`
+	WarehouseName     string                 `json:"warehouse_name"`
+	Quantity          float64                `json:"quantity"`
+	ValuationRate     models.Money           `json:"valuation_rate"`
+	StockValue        models.Money           `json:"stock_value"`
+	OldestReceiptDate *time.Time             `json:"oldest_receipt_date,omitempty"` // 在库库存中最早的入库时间
+	AverageAgeDays    float64                `json:"average_age_days"`              // 按数量加权的平均库龄，不含入库记录不足以覆盖的数量
+	Buckets           []InventoryAgingBucket `json:"buckets"`
+}
+
+// InventoryAgingResponse 库存账龄响应
+type InventoryAgingResponse struct {
+	AsOf          time.Time              `json:"as_of"`
+	Buckets       []InventoryAgingBucket `json:"buckets"` // 各账龄段合计
+	Lines         []InventoryAgingLine   `json:"lines"`
+	TotalQuantity float64                `json:"total_quantity"`
+	TotalValue    models.Money           `json:"total_value"`
+}
+
+// SlowMovingRequest 呆滞库存请求；超过 slow_days 天没有出库为滞销，超过 dead_days 天为呆滞
+type SlowMovingRequest struct {
+	ItemID      uint   `json:"item_id,omitempty" form:"item_id"`
+	WarehouseID uint   `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	Category    string `json:"category,omitempty" form:"category"`
+	Keyword     string `json:"keyword,omitempty" form:"keyword"`
+	SlowDays    int    `json:"slow_days,omitempty" form:"slow_days" validate:"omitempty,min=1,max=3650"`
+	DeadDays    int    `json:"dead_days,omitempty" form:"dead_days" validate:"omitempty,min=1,max=3650"`
+}
+
+// SlowMovingLine 滞销或呆滞的库存，Status 为 slow_moving 或 dead
+type SlowMovingLine struct {
+	ItemID         uint         `json:"item_id"`
+	ItemCode       string       `json:"item_code"`
+	ItemName       string       `json:"item_name"`
+	Category       string       `json:"category,omitempty"`
+	WarehouseID    uint         `json:"warehouse_id"`
+	WarehouseCode  string       `json:"warehouse_code"`
+	WarehouseName  string       `json:"warehouse_name"`
+	Quantity       float64      `json:"quantity"`
+	ValuationRate  models.Money `json:"valuation_rate"`
+	CarryingValue  models.Money `json:"carrying_value"`
+	LastOutboundAt *time.Time   `json:"last_outbound_at,omitempty"`
+	IdleDays       int          `json:"idle_days"` // 距最近一次出库的天数，从未出库时按在库最早的入库时间计算
+	Status         string       `json:"status"`
+}
+
+// SlowMovingResponse 呆滞库存响应
+type SlowMovingResponse struct {
+	AsOf            time.Time        `json:"as_of"`
+	SlowDays        int              `json:"slow_days"`
+	DeadDays        int              `json:"dead_days"`
+	Lines           []SlowMovingLine `json:"lines"`
+	SlowMovingValue models.Money     `json:"slow_moving_value"`
+	DeadStockValue  models.Money     `json:"dead_stock_value"`
+}
+
+// InventoryWriteDownRequest 存货减值建议请求；按呆滞库存分类计提减值，
+// 科目编码为空时使用默认的减值损失科目与存货跌价准备科目
+type InventoryWriteDownRequest struct {
+	SlowMovingRequest
+	CompanyID            uint    `json:"company_id,omitempty" form:"company_id"`
+	SlowPercent          float64 `json:"slow_percent,omitempty" form:"slow_percent" validate:"omitempty,gt=0,lte=100"` // 滞销库存减值比例(%)
+	DeadPercent          float64 `json:"dead_percent,omitempty" form:"dead_percent" validate:"omitempty,gt=0,lte=100"` // 呆滞库存减值比例(%)
+	ExpenseAccountCode   string  `json:"expense_account_code,omitempty" form:"expense_account_code"`
+	ProvisionAccountCode string  `json:"provision_account_code,omitempty" form:"provision_account_code"`
+}
+
+// InventoryWriteDownLine 存货减值建议明细
+type InventoryWriteDownLine struct {
+	SlowMovingLine
+	WriteDownPercent float64      `json:"write_down_percent"`
+	WriteDownAmount  models.Money `json:"write_down_amount"`
+}
+
+// InventoryWriteDownResponse 存货减值建议，Journal 可直接提交到会计凭证接口过账
+type InventoryWriteDownResponse struct {
+	AsOf               time.Time                  `json:"as_of"`
+	Lines              []InventoryWriteDownLine   `json:"lines"`
+	TotalCarryingValue models.Money               `json:"total_carrying_value"`
+	TotalWriteDown     models.Money               `json:"total_write_down"`
+	Journal            *JournalEntryCreateRequest `json:"journal,omitempty"` // 无减值金额时为空
+}
+
+// InventoryExportRequest 库存报表导出请求，其余查询参数按报表类型解析为对应的筛选条件
+type InventoryExportRequest struct {
+	Type   string `json:"type" form:"type" validate:"omitempty,oneof=stats report abc aging slow_moving write_down"`
+	Format string `json:"format" form:"format" validate:"omitempty,oneof=csv xlsx"`
+}
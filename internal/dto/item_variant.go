@@ -0,0 +1,102 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// ItemAttributeValueRequest 物料属性取值请求
+type ItemAttributeValueRequest struct {
+	Value        string `json:"value" validate:"required,max=100"`
+	Abbreviation string `json:"abbreviation,omitempty" validate:"max=20"` // 拼接变体编码，为空时取值本身
+	SortOrder    int    `json:"sort_order,omitempty"`
+}
+
+// ItemAttributeCreateRequest 物料属性创建请求
+type ItemAttributeCreateRequest struct {
+	Code        string                      `json:"code" validate:"required,max=50"`
+	Name        string                      `json:"name" validate:"required,max=100"`
+	Description string                      `json:"description,omitempty"`
+	Values      []ItemAttributeValueRequest `json:"values,omitempty" validate:"dive"`
+}
+
+// ItemAttributeUpdateRequest 物料属性更新请求，属性编码不能修改
+type ItemAttributeUpdateRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,max=100"`
+	Description *string `json:"description,omitempty"`
+}
+
+// ItemAttributeValueResponse 物料属性取值响应
+type ItemAttributeValueResponse struct {
+	ID           uint   `json:"id"`
+	Value        string `json:"value"`
+	Abbreviation string `json:"abbreviation"`
+	SortOrder    int    `json:"sort_order"`
+}
+
+// ItemAttributeResponse 物料属性响应
+type ItemAttributeResponse struct {
+	ID          uint                         `json:"id"`
+	Code        string                       `json:"code"`
+	Name        string                       `json:"name"`
+	Description string                       `json:"description,omitempty"`
+	Values      []ItemAttributeValueResponse `json:"values"`
+	CreatedAt   time.Time                    `json:"created_at"`
+	UpdatedAt   time.Time                    `json:"updated_at"`
+}
+
+// ItemTemplateAttributesRequest 设置模板物料使用的属性，顺序决定变体编码中的属性顺序
+type ItemTemplateAttributesRequest struct {
+	AttributeIDs []uint `json:"attribute_ids" validate:"required,min=1"`
+}
+
+// ItemVariantSelection 生成变体时某个属性选用的取值
+type ItemVariantSelection struct {
+	AttributeID uint   `json:"attribute_id" validate:"required"`
+	ValueIDs    []uint `json:"value_ids" validate:"required,min=1"`
+}
+
+// ItemVariantGenerateRequest 生成变体请求；未列出的模板属性使用其全部取值，已存在的取值组合跳过
+type ItemVariantGenerateRequest struct {
+	Selections []ItemVariantSelection `json:"selections,omitempty" validate:"dive"`
+	SalePrice  *models.Money          `json:"sale_price,omitempty" validate:"omitempty,min=0"` // 为空时沿用模板售价
+	UnitCost   *models.Money          `json:"unit_cost,omitempty" validate:"omitempty,min=0"`  // 为空时沿用模板成本
+}
+
+// ItemVariantAttributeResponse 变体的属性取值
+type ItemVariantAttributeResponse struct {
+	AttributeID   uint   `json:"attribute_id"`
+	AttributeCode string `json:"attribute_code"`
+	AttributeName string `json:"attribute_name"`
+	ValueID       uint   `json:"value_id"`
+	Value         string `json:"value"`
+}
+
+// ItemVariantResponse 变体物料响应
+type ItemVariantResponse struct {
+	ID         uint                           `json:"id"`
+	Code       string                         `json:"code"`
+	Name       string                         `json:"name"`
+	SalePrice  models.Money                   `json:"sale_price"`
+	UnitCost   models.Money                   `json:"unit_cost"`
+	IsActive   bool                           `json:"is_active"`
+	StockQty   float64                        `json:"stock_qty"` // 全部仓库库存合计
+	Attributes []ItemVariantAttributeResponse `json:"attributes"`
+}
+
+// ItemTemplateResponse 模板物料的属性与变体
+type ItemTemplateResponse struct {
+	TemplateID   uint                    `json:"template_id"`
+	TemplateCode string                  `json:"template_code"`
+	TemplateName string                  `json:"template_name"`
+	Attributes   []ItemAttributeResponse `json:"attributes"`
+	Variants     []ItemVariantResponse   `json:"variants"`
+	TotalStock   float64                 `json:"total_stock"` // 全部变体库存合计
+}
+
+// ItemVariantGenerateResponse 生成变体结果
+type ItemVariantGenerateResponse struct {
+	Created []ItemVariantResponse `json:"created"`
+	Skipped int                   `json:"skipped"` // 已存在而跳过的取值组合数
+}
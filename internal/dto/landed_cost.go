@@ -0,0 +1,104 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// LandedCostChargeRequest 到岸成本费用
+type LandedCostChargeRequest struct {
+	ChargeType  string       `json:"charge_type" validate:"required,oneof=freight customs insurance other"`
+	Description string       `json:"description,omitempty"`
+	Amount      models.Money `json:"amount" validate:"gt=0"`
+	AccountCode string       `json:"account_code,omitempty" validate:"max=50"` // 贷方科目编码，默认应付账款
+	SupplierID  *uint        `json:"supplier_id,omitempty"`
+}
+
+// LandedCostManualAllocation 手工分摊时指定收货明细承担的金额，收货明细入库到多个仓库时按数量拆分
+type LandedCostManualAllocation struct {
+	PurchaseReceiptItemID uint         `json:"purchase_receipt_item_id" validate:"required"`
+	Amount                models.Money `json:"amount" validate:"min=0"`
+}
+
+// LandedCostCreateRequest 创建到岸成本单请求，创建时按分摊方式计算各收货明细的分摊金额
+type LandedCostCreateRequest struct {
+	CompanyID          uint                         `json:"company_id,omitempty"`
+	PurchaseReceiptIDs []uint                       `json:"purchase_receipt_ids" validate:"required,min=1"`
+	AllocationMethod   string                       `json:"allocation_method" validate:"required,oneof=quantity value weight manual"`
+	PostingDate        *time.Time                   `json:"posting_date,omitempty"` // 过账日期，默认今天
+	Charges            []LandedCostChargeRequest    `json:"charges" validate:"required,min=1,dive"`
+	Allocations        []LandedCostManualAllocation `json:"allocations,omitempty" validate:"dive"` // 手工分摊时必填，合计须等于费用合计
+	Notes              string                       `json:"notes,omitempty"`
+}
+
+// LandedCostListRequest 到岸成本单列表请求
+type LandedCostListRequest struct {
+	PaginationRequest
+	Status            string `json:"status,omitempty" form:"status" validate:"omitempty,oneof=draft submitted cancelled"`
+	PurchaseReceiptID uint   `json:"purchase_receipt_id,omitempty" form:"purchase_receipt_id"`
+}
+
+// LandedCostSubmitRequest 提交到岸成本单请求，科目编码默认为库存商品与主营业务成本
+type LandedCostSubmitRequest struct {
+	InventoryAccountCode string `json:"inventory_account_code,omitempty"`
+	ExpenseAccountCode   string `json:"expense_account_code,omitempty"`
+}
+
+// LandedCostReceiptResponse 到岸成本单引用的采购收货单
+type LandedCostReceiptResponse struct {
+	PurchaseReceiptID uint      `json:"purchase_receipt_id"`
+	ReceiptNumber     string    `json:"receipt_number"`
+	SupplierID        uint      `json:"supplier_id"`
+	Date              time.Time `json:"date"`
+}
+
+// LandedCostChargeResponse 到岸成本费用响应
+type LandedCostChargeResponse struct {
+	ID          uint         `json:"id"`
+	ChargeType  string       `json:"charge_type"`
+	Description string       `json:"description,omitempty"`
+	Amount      models.Money `json:"amount"`
+	AccountCode string       `json:"account_code"`
+	SupplierID  *uint        `json:"supplier_id,omitempty"`
+}
+
+// LandedCostItemResponse 到岸成本分摊行响应
+type LandedCostItemResponse struct {
+	ID                    uint         `json:"id"`
+	PurchaseReceiptID     uint         `json:"purchase_receipt_id"`
+	PurchaseReceiptItemID uint         `json:"purchase_receipt_item_id"`
+	ItemID                uint         `json:"item_id"`
+	ItemCode              string       `json:"item_code,omitempty"`
+	ItemName              string       `json:"item_name,omitempty"`
+	WarehouseID           uint         `json:"warehouse_id"`
+	Quantity              float64      `json:"quantity"`
+	Amount                models.Money `json:"amount"`
+	Weight                float64      `json:"weight"`
+	AllocatedAmount       models.Money `json:"allocated_amount"`
+	CapitalizedAmount     models.Money `json:"capitalized_amount"` // 提交后计入库存金额的部分
+	ExpensedAmount        models.Money `json:"expensed_amount"`    // 提交后计入销售成本的部分
+	LandedUnitCost        models.Money `json:"landed_unit_cost"`   // 入库单价 + 分摊单价
+	MovementID            *uint        `json:"movement_id,omitempty"`
+}
+
+// LandedCostResponse 到岸成本单响应
+type LandedCostResponse struct {
+	ID                uint                        `json:"id"`
+	CompanyID         uint                        `json:"company_id"`
+	VoucherNumber     string                      `json:"voucher_number"`
+	PostingDate       time.Time                   `json:"posting_date"`
+	AllocationMethod  string                      `json:"allocation_method"`
+	Status            string                      `json:"status"`
+	TotalCharges      models.Money                `json:"total_charges"`
+	CapitalizedAmount models.Money                `json:"capitalized_amount"`
+	ExpensedAmount    models.Money                `json:"expensed_amount"`
+	SubmittedAt       *time.Time                  `json:"submitted_at,omitempty"`
+	TransactionID     *uint                       `json:"transaction_id,omitempty"`
+	Notes             string                      `json:"notes,omitempty"`
+	Receipts          []LandedCostReceiptResponse `json:"receipts"`
+	Charges           []LandedCostChargeResponse  `json:"charges,omitempty"`
+	Items             []LandedCostItemResponse    `json:"items,omitempty"`
+	CreatedAt         time.Time                   `json:"created_at"`
+	UpdatedAt         time.Time                   `json:"updated_at"`
+}
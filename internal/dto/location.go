@@ -0,0 +1,192 @@
+package dto
+
+import "time"
+
+// LocationStockRequest 库位库存查询请求
+type LocationStockRequest struct {
+	LocationID uint `json:"location_id,omitempty" form:"location_id"`
+	ItemID     uint `json:"item_id,omitempty" form:"item_id"`
+}
+
+// LocationStockLine 库位库存明细，LocationID 为 0 表示尚未分配库位
+type LocationStockLine struct {
+	ItemID          uint       `json:"item_id"`
+	ItemCode        string     `json:"item_code"`
+	ItemName        string     `json:"item_name"`
+	LocationID      uint       `json:"location_id"`
+	LocationCode    string     `json:"location_code,omitempty"`
+	LocationName    string     `json:"location_name,omitempty"`
+	BatchID         uint       `json:"batch_id,omitempty"`
+	BatchNo         string     `json:"batch_no,omitempty"`
+	ExpiryDate      *time.Time `json:"expiry_date,omitempty"`
+	Quantity        float64    `json:"quantity"`
+	FirstReceivedAt *time.Time `json:"first_received_at,omitempty"`
+}
+
+// LocationStockResponse 仓库的库位库存
+type LocationStockResponse struct {
+	WarehouseID        uint                `json:"warehouse_id"`
+	Lines              []LocationStockLine `json:"lines"`
+	LocatedQuantity    float64             `json:"located_quantity"`
+	UnassignedQuantity float64             `json:"unassigned_quantity"`
+}
+
+// PutawayRuleCreateRequest 创建上架规则请求；指定物料为固定库位，指定类别按物料类别匹配，均为空时适用于仓库全部物料
+type PutawayRuleCreateRequest struct {
+	WarehouseID uint   `json:"warehouse_id" validate:"required"`
+	LocationID  uint   `json:"location_id" validate:"required"`
+	ItemID      *uint  `json:"item_id,omitempty"`
+	Category    string `json:"category,omitempty" validate:"max=100"`
+	Priority    int    `json:"priority,omitempty"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+// PutawayRuleUpdateRequest 更新上架规则请求
+type PutawayRuleUpdateRequest struct {
+	LocationID *uint   `json:"location_id,omitempty"`
+	Priority   *int    `json:"priority,omitempty"`
+	IsActive   *bool   `json:"is_active,omitempty"`
+	Notes      *string `json:"notes,omitempty"`
+}
+
+// PutawayRuleListRequest 上架规则列表请求
+type PutawayRuleListRequest struct {
+	WarehouseID uint `json:"warehouse_id,omitempty" form:"warehouse_id"`
+}
+
+// PutawayRuleResponse 上架规则响应
+type PutawayRuleResponse struct {
+	ID           uint      `json:"id"`
+	WarehouseID  uint      `json:"warehouse_id"`
+	LocationID   uint      `json:"location_id"`
+	LocationCode string    `json:"location_code,omitempty"`
+	ItemID       *uint     `json:"item_id,omitempty"`
+	ItemCode     string    `json:"item_code,omitempty"`
+	Category     string    `json:"category,omitempty"`
+	RuleType     string    `json:"rule_type"` // fixed_bin, category, general
+	Priority     int       `json:"priority"`
+	IsActive     bool      `json:"is_active"`
+	Notes        string    `json:"notes,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PutawaySuggestionRequest 上架建议请求
+type PutawaySuggestionRequest struct {
+	ItemID   uint    `json:"item_id" validate:"required"`
+	Quantity float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+// PutawayAllocation 上架建议的库位分配
+type PutawayAllocation struct {
+	LocationID   uint    `json:"location_id"`
+	LocationCode string  `json:"location_code"`
+	Quantity     float64 `json:"quantity"`
+	RuleID       *uint   `json:"rule_id,omitempty"`
+	RuleType     string  `json:"rule_type"`
+	Available    float64 `json:"available"` // 分配前的剩余容量，-1 表示不限
+}
+
+// PutawaySuggestionResponse 上架建议
+type PutawaySuggestionResponse struct {
+	WarehouseID    uint                `json:"warehouse_id"`
+	ItemID         uint                `json:"item_id"`
+	Quantity       float64             `json:"quantity"`
+	Allocations    []PutawayAllocation `json:"allocations"`
+	UnallocatedQty float64             `json:"unallocated_qty"`
+}
+
+// LocationMoveRequest 库位间移库请求；调出库位为空表示从未分配库存上架，调入库位为空表示下架
+type LocationMoveRequest struct {
+	WarehouseID    uint    `json:"warehouse_id" validate:"required"`
+	ItemID         uint    `json:"item_id" validate:"required"`
+	FromLocationID *uint   `json:"from_location_id,omitempty"`
+	ToLocationID   *uint   `json:"to_location_id,omitempty"`
+	BatchNo        string  `json:"batch_no,omitempty" validate:"max=100"`
+	Quantity       float64 `json:"quantity" validate:"required,gt=0"`
+	Reference      string  `json:"reference,omitempty" validate:"max=100"`
+	Notes          string  `json:"notes,omitempty"`
+}
+
+// LocationMoveListRequest 移库记录列表请求
+type LocationMoveListRequest struct {
+	PaginationRequest
+	WarehouseID uint `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	ItemID      uint `json:"item_id,omitempty" form:"item_id"`
+	LocationID  uint `json:"location_id,omitempty" form:"location_id"`
+}
+
+// LocationMoveResponse 移库记录响应
+type LocationMoveResponse struct {
+	ID               uint      `json:"id"`
+	WarehouseID      uint      `json:"warehouse_id"`
+	ItemID           uint      `json:"item_id"`
+	ItemCode         string    `json:"item_code,omitempty"`
+	FromLocationID   *uint     `json:"from_location_id,omitempty"`
+	FromLocationCode string    `json:"from_location_code,omitempty"`
+	ToLocationID     *uint     `json:"to_location_id,omitempty"`
+	ToLocationCode   string    `json:"to_location_code,omitempty"`
+	BatchID          uint      `json:"batch_id,omitempty"`
+	Quantity         float64   `json:"quantity"`
+	Reference        string    `json:"reference,omitempty"`
+	Notes            string    `json:"notes,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ExpiryQuarantineRequest 过期批次隔离请求，WarehouseID 为 0 时处理全部仓库
+type ExpiryQuarantineRequest struct {
+	WarehouseID uint `json:"warehouse_id,omitempty"`
+}
+
+// ExpiryQuarantineFailure 未能移入隔离库位的过期批次库存
+type ExpiryQuarantineFailure struct {
+	WarehouseID    uint    `json:"warehouse_id"`
+	ItemCode       string  `json:"item_code"`
+	BatchNo        string  `json:"batch_no"`
+	FromLocationID *uint   `json:"from_location_id,omitempty"`
+	Quantity       float64 `json:"quantity"`
+	Error          string  `json:"error"`
+}
+
+// ExpiryQuarantineResponse 过期批次隔离结果，未设置隔离库位的仓库列入 SkippedWarehouses
+type ExpiryQuarantineResponse struct {
+	AsOf              time.Time                 `json:"as_of"`
+	Moves             []LocationMoveResponse    `json:"moves"`
+	Failed            []ExpiryQuarantineFailure `json:"failed,omitempty"`
+	SkippedWarehouses []uint                    `json:"skipped_warehouses,omitempty"`
+	TotalQuantity     float64                   `json:"total_quantity"`
+}
+
+// PickingListRequest 拣货单请求，strategy 为空时批次管理物料按先到期先拣，其他物料按先入库先拣
+type PickingListRequest struct {
+	Strategy string `json:"strategy,omitempty" form:"strategy" validate:"omitempty,oneof=fifo fefo"`
+}
+
+// PickingSuggestion 拣货建议，LocationID 为 0 表示从未分配库位的库存拣货
+type PickingSuggestion struct {
+	LocationID   uint       `json:"location_id"`
+	LocationCode string     `json:"location_code,omitempty"`
+	BatchID      uint       `json:"batch_id,omitempty"`
+	BatchNo      string     `json:"batch_no,omitempty"`
+	ExpiryDate   *time.Time `json:"expiry_date,omitempty"`
+	Quantity     float64    `json:"quantity"`
+}
+
+// PickingLine 送货单明细的拣货建议
+type PickingLine struct {
+	LineID      uint                `json:"line_id"`
+	ItemID      uint                `json:"item_id"`
+	ItemCode    string              `json:"item_code,omitempty"`
+	WarehouseID uint                `json:"warehouse_id"`
+	Quantity    float64             `json:"quantity"`
+	Strategy    string              `json:"strategy"`
+	Suggestions []PickingSuggestion `json:"suggestions"`
+	ShortageQty float64             `json:"shortage_qty"`
+}
+
+// PickingListResponse 送货单拣货单
+type PickingListResponse struct {
+	DeliveryNoteID uint          `json:"delivery_note_id"`
+	Lines          []PickingLine `json:"lines"`
+	Complete       bool          `json:"complete"` // 全部明细均有足够库存
+}
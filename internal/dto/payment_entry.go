@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// PaymentEntryCreateRequest 创建付款请求
+type PaymentEntryCreateRequest struct {
+	CompanyID      uint                       `json:"company_id"`
+	PaymentType    string                     `json:"payment_type" validate:"required,oneof=Receive Pay"`
+	PartyType      string                     `json:"party_type" validate:"required,oneof=Customer Supplier"`
+	PartyID        uint                       `json:"party_id" validate:"required"`
+	PostingDate    time.Time                  `json:"posting_date" validate:"required"`
+	Amount         models.Money               `json:"amount" validate:"required,gt=0"`
+	Currency       string                     `json:"currency,omitempty"`
+	ExchangeRate   float64                    `json:"exchange_rate,omitempty"`
+	PaymentMethod  string                     `json:"payment_method,omitempty" validate:"max=50"`
+	BankAccountID  *uint                      `json:"bank_account_id,omitempty"`
+	CashAccountID  *uint                      `json:"cash_account_id,omitempty"`
+	PartyAccountID *uint                      `json:"party_account_id,omitempty"`
+	Reference      string                     `json:"reference,omitempty"`
+	Remarks        string                     `json:"remarks,omitempty"`
+	Allocations    []PaymentAllocationRequest `json:"allocations,omitempty" validate:"dive"`
+}
+
+// PaymentAllocationRequest 付款分配请求项
+type PaymentAllocationRequest struct {
+	ReferenceType string       `json:"reference_type" validate:"required,oneof=sales_invoice payable"`
+	ReferenceID   uint         `json:"reference_id" validate:"required"`
+	Amount        models.Money `json:"amount" validate:"required,gt=0"`
+}
+
+// PaymentAllocateRequest 付款核销请求，已提交付款的未核销余额可继续分配
+type PaymentAllocateRequest struct {
+	Allocations []PaymentAllocationRequest `json:"allocations" validate:"required,min=1,dive"`
+}
+
+// PaymentEntryListRequest 付款列表请求
+type PaymentEntryListRequest struct {
+	PaginationRequest
+	PartyType string `json:"party_type,omitempty" form:"party_type"`
+	PartyID   uint   `json:"party_id,omitempty" form:"party_id"`
+}
+
+// UnallocatedPaymentsRequest 未核销付款查询请求
+type UnallocatedPaymentsRequest struct {
+	CompanyID uint   `json:"company_id" form:"company_id"`
+	PartyType string `json:"party_type" form:"party_type" validate:"required,oneof=Customer Supplier"`
+	PartyID   uint   `json:"party_id" form:"party_id" validate:"required"`
+}
+
+// PaymentAllocationResponse 付款分配响应
+type PaymentAllocationResponse struct {
+	ID              uint         `json:"id"`
+	ReferenceType   string       `json:"reference_type"`
+	ReferenceID     uint         `json:"reference_id"`
+	ReferenceNumber string       `json:"reference_number"`
+	AllocatedAmount models.Money `json:"allocated_amount"`
+	AllocationDate  time.Time    `json:"allocation_date"`
+	Status          string       `json:"status"`
+}
+
+// PaymentEntryResponse 付款响应
+type PaymentEntryResponse struct {
+	ID                uint                        `json:"id"`
+	CompanyID         uint                        `json:"company_id"`
+	PaymentNumber     string                      `json:"payment_number"`
+	PaymentType       string                      `json:"payment_type"`
+	PartyType         string                      `json:"party_type"`
+	PartyID           uint                        `json:"party_id"`
+	PostingDate       time.Time                   `json:"posting_date"`
+	Amount            models.Money                `json:"amount"`
+	AllocatedAmount   models.Money                `json:"allocated_amount"`
+	UnallocatedAmount models.Money                `json:"unallocated_amount"`
+	Currency          string                      `json:"currency"`
+	PaymentMethod     string                      `json:"payment_method,omitempty"`
+	BankAccountID     *uint                       `json:"bank_account_id,omitempty"`
+	CashAccountID     *uint                       `json:"cash_account_id,omitempty"`
+	PartyAccountID    *uint                       `json:"party_account_id,omitempty"`
+	TransactionID     *uint                       `json:"transaction_id,omitempty"`
+	Reference         string                      `json:"reference,omitempty"`
+	Remarks           string                      `json:"remarks,omitempty"`
+	Status            string                      `json:"status"`
+	IsPosted          bool                        `json:"is_posted"`
+	PostedAt          *time.Time                  `json:"posted_at,omitempty"`
+	Allocations       []PaymentAllocationResponse `json:"allocations"`
+	CreatedAt         time.Time                   `json:"created_at"`
+	UpdatedAt         time.Time                   `json:"updated_at"`
+}
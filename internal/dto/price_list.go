@@ -0,0 +1,130 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// PriceListCreateRequest 价格表创建请求
+type PriceListCreateRequest struct {
+	Code          string     `json:"code" validate:"required,max=50"`
+	Name          string     `json:"name" validate:"required,max=100"`
+	PriceListType string     `json:"price_list_type" validate:"required,oneof=selling buying"`
+	Currency      string     `json:"currency,omitempty" validate:"omitempty,len=3"` // 默认本位币
+	ValidFrom     *time.Time `json:"valid_from,omitempty"`
+	ValidTo       *time.Time `json:"valid_to,omitempty"`
+	IsDefault     bool       `json:"is_default,omitempty"`
+	Description   string     `json:"description,omitempty"`
+}
+
+// PriceListUpdateRequest 价格表更新请求，编码与类型不能修改
+type PriceListUpdateRequest struct {
+	Name        *string    `json:"name,omitempty" validate:"omitempty,max=100"`
+	Currency    *string    `json:"currency,omitempty" validate:"omitempty,len=3"`
+	ValidFrom   *time.Time `json:"valid_from,omitempty"`
+	ValidTo     *time.Time `json:"valid_to,omitempty"`
+	IsDefault   *bool      `json:"is_default,omitempty"`
+	IsActive    *bool      `json:"is_active,omitempty"`
+	Description *string    `json:"description,omitempty"`
+}
+
+// PriceListListRequest 价格表列表请求
+type PriceListListRequest struct {
+	PaginationRequest
+	PriceListType string `json:"price_list_type,omitempty" form:"price_list_type" validate:"omitempty,oneof=selling buying"`
+	IsActive      *bool  `json:"is_active,omitempty" form:"is_active"`
+}
+
+// PriceListResponse 价格表响应
+type PriceListResponse struct {
+	ID            uint       `json:"id"`
+	Code          string     `json:"code"`
+	Name          string     `json:"name"`
+	PriceListType string     `json:"price_list_type"`
+	Currency      string     `json:"currency"`
+	ValidFrom     *time.Time `json:"valid_from,omitempty"`
+	ValidTo       *time.Time `json:"valid_to,omitempty"`
+	IsDefault     bool       `json:"is_default"`
+	IsActive      bool       `json:"is_active"`
+	Description   string     `json:"description,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ItemPriceRequest 物料价格请求
+type ItemPriceRequest struct {
+	ItemID    uint         `json:"item_id" validate:"required"`
+	UOM       string       `json:"uom,omitempty" validate:"max=50"` // 为空时为库存单位
+	MinQty    float64      `json:"min_qty,omitempty" validate:"min=0"`
+	Rate      models.Money `json:"rate" validate:"required,gt=0,currency"`
+	ValidFrom *time.Time   `json:"valid_from,omitempty"`
+	ValidTo   *time.Time   `json:"valid_to,omitempty"`
+}
+
+// ItemPriceListRequest 物料价格列表请求
+type ItemPriceListRequest struct {
+	PaginationRequest
+	ItemID uint `json:"item_id,omitempty" form:"item_id"`
+}
+
+// ItemPriceResponse 物料价格响应
+type ItemPriceResponse struct {
+	ID          uint         `json:"id"`
+	PriceListID uint         `json:"price_list_id"`
+	ItemID      uint         `json:"item_id"`
+	ItemCode    string       `json:"item_code,omitempty"`
+	ItemName    string       `json:"item_name,omitempty"`
+	UOM         string       `json:"uom"`
+	MinQty      float64      `json:"min_qty"`
+	Rate        models.Money `json:"rate"`
+	ValidFrom   *time.Time   `json:"valid_from,omitempty"`
+	ValidTo     *time.Time   `json:"valid_to,omitempty"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// PriceListAssignmentRequest 价格表分配请求；客户与供应商填写 party_id，客户组填写 customer_group
+type PriceListAssignmentRequest struct {
+	PartyType     string `json:"party_type" validate:"required,oneof=customer customer_group supplier"`
+	PartyID       uint   `json:"party_id,omitempty"`
+	CustomerGroup string `json:"customer_group,omitempty" validate:"max=100"`
+}
+
+// PriceListAssignmentResponse 价格表分配响应
+type PriceListAssignmentResponse struct {
+	ID            uint      `json:"id"`
+	PriceListID   uint      `json:"price_list_id"`
+	PartyType     string    `json:"party_type"`
+	PartyID       *uint     `json:"party_id,omitempty"`
+	PartyName     string    `json:"party_name,omitempty"`
+	CustomerGroup string    `json:"customer_group,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PriceResolveRequest 取价请求：销售按客户取价，采购按供应商取价；指定价格表时直接使用该价格表
+type PriceResolveRequest struct {
+	PriceListType string     `json:"price_list_type" form:"price_list_type" validate:"required,oneof=selling buying"`
+	ItemID        uint       `json:"item_id" form:"item_id" validate:"required"`
+	CustomerID    uint       `json:"customer_id,omitempty" form:"customer_id"`
+	SupplierID    uint       `json:"supplier_id,omitempty" form:"supplier_id"`
+	PriceListID   uint       `json:"price_list_id,omitempty" form:"price_list_id"`
+	UOM           string     `json:"uom,omitempty" form:"uom" validate:"max=50"`
+	Quantity      float64    `json:"quantity,omitempty" form:"quantity" validate:"min=0"` // 默认 1
+	Date          *time.Time `json:"date,omitempty" form:"date" time_format:"2006-01-02"` // 默认当天
+	Currency      string     `json:"currency,omitempty" form:"currency"`                  // 单据币种，默认本位币
+}
+
+// PriceResolveResult 取价结果；Found 为 false 表示没有适用的价格表或价格
+type PriceResolveResult struct {
+	Found         bool         `json:"found"`
+	PriceListID   uint         `json:"price_list_id,omitempty"`
+	PriceListCode string       `json:"price_list_code,omitempty"`
+	Source        string       `json:"source,omitempty"` // price_list, customer, customer_group, supplier, default, item
+	Currency      string       `json:"currency,omitempty"`
+	ItemPriceID   uint         `json:"item_price_id,omitempty"`
+	UOM           string       `json:"uom,omitempty"`
+	Rate          models.Money `json:"rate"` // 请求单位的价格
+	PriceUOM      string       `json:"price_uom,omitempty"`
+	PriceRate     models.Money `json:"price_rate"` // 价格表中登记的价格（价格单位）
+	MinQty        float64      `json:"min_qty"`
+}
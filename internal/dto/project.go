@@ -2,35 +2,37 @@ package dto
 
 import (
 	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
 )
 
 // ProjectCreateRequest 项目创建请求
 type ProjectCreateRequest struct {
-	ProjectCode string    `json:"project_code,omitempty"`
-	ProjectName string    `json:"project_name" validate:"required"`
-	Name        string    `json:"name" validate:"required"`
-	Description string    `json:"description,omitempty"`
-	StartDate   time.Time `json:"start_date" validate:"required"`
-	EndDate     time.Time `json:"end_date" validate:"required"`
-	Status      string    `json:"status" validate:"required,oneof=Planning Active Completed Cancelled"`
-	Priority    string    `json:"priority" validate:"required,oneof=Low Medium High"`
-	Budget      float64   `json:"budget" validate:"min=0"`
-	ClientID    *uint     `json:"client_id,omitempty"`
-	ManagerID   uint      `json:"manager_id" validate:"required"`
+	ProjectCode string       `json:"project_code,omitempty"`
+	ProjectName string       `json:"project_name" validate:"required"`
+	Name        string       `json:"name" validate:"required"`
+	Description string       `json:"description,omitempty"`
+	StartDate   time.Time    `json:"start_date" validate:"required"`
+	EndDate     time.Time    `json:"end_date" validate:"required"`
+	Status      string       `json:"status" validate:"required,oneof=Planning Active Completed Cancelled"`
+	Priority    string       `json:"priority" validate:"required,oneof=Low Medium High"`
+	Budget      models.Money `json:"budget" validate:"min=0"`
+	ClientID    *uint        `json:"client_id,omitempty"`
+	ManagerID   uint         `json:"manager_id" validate:"required"`
 }
 
 // ProjectUpdateRequest 项目更新请求
 type ProjectUpdateRequest struct {
-	ProjectName string     `json:"project_name,omitempty"`
-	Name        string     `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
-	Description string     `json:"description,omitempty"`
-	StartDate   *time.Time `json:"start_date,omitempty"`
-	EndDate     *time.Time `json:"end_date,omitempty"`
-	Status      string     `json:"status,omitempty" validate:"omitempty,oneof=Planning Active Completed Cancelled"`
-	Priority    string     `json:"priority,omitempty" validate:"omitempty,oneof=Low Medium High Critical"`
-	Budget      *float64   `json:"budget,omitempty" validate:"omitempty,min=0"`
-	ClientID    *uint      `json:"client_id,omitempty"`
-	ManagerID   *uint      `json:"manager_id,omitempty"`
+	ProjectName string        `json:"project_name,omitempty"`
+	Name        string        `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description string        `json:"description,omitempty"`
+	StartDate   *time.Time    `json:"start_date,omitempty"`
+	EndDate     *time.Time    `json:"end_date,omitempty"`
+	Status      string        `json:"status,omitempty" validate:"omitempty,oneof=Planning Active Completed Cancelled"`
+	Priority    string        `json:"priority,omitempty" validate:"omitempty,oneof=Low Medium High Critical"`
+	Budget      *models.Money `json:"budget,omitempty" validate:"omitempty,min=0"`
+	ClientID    *uint         `json:"client_id,omitempty"`
+	ManagerID   *uint         `json:"manager_id,omitempty"`
 }
 
 // TaskCreateRequest 任务创建请求
@@ -67,32 +69,32 @@ type TaskUpdateRequest struct {
 
 // TimeEntryCreateRequest 时间记录创建请求
 type TimeEntryCreateRequest struct {
-	EmployeeID  uint      `json:"employee_id" validate:"required"`
-	ProjectID   uint      `json:"project_id" validate:"required"`
-	TaskID      *uint     `json:"task_id,omitempty"`
-	Date        time.Time `json:"date" validate:"required"`
-	StartTime   time.Time `json:"start_time" validate:"required"`
-	EndTime     time.Time `json:"end_time" validate:"required"`
-	Hours       float64   `json:"hours" validate:"required,gt=0,lte=24"`
-	Description string    `json:"description,omitempty"`
-	IsBillable  bool      `json:"is_billable"`
-	HourlyRate  float64   `json:"hourly_rate,omitempty"`
-	Status      string    `json:"status" validate:"required,oneof=draft submitted approved billed"`
+	EmployeeID  uint         `json:"employee_id" validate:"required"`
+	ProjectID   uint         `json:"project_id" validate:"required"`
+	TaskID      *uint        `json:"task_id,omitempty"`
+	Date        time.Time    `json:"date" validate:"required"`
+	StartTime   time.Time    `json:"start_time" validate:"required"`
+	EndTime     time.Time    `json:"end_time" validate:"required"`
+	Hours       float64      `json:"hours" validate:"required,gt=0,lte=24"`
+	Description string       `json:"description,omitempty"`
+	IsBillable  bool         `json:"is_billable"`
+	HourlyRate  models.Money `json:"hourly_rate,omitempty"`
+	Status      string       `json:"status" validate:"required,oneof=draft submitted approved billed"`
 }
 
 // TimeEntryUpdateRequest 时间记录更新请求
 type TimeEntryUpdateRequest struct {
-	EmployeeID  *uint      `json:"employee_id,omitempty"`
-	ProjectID   *uint      `json:"project_id,omitempty"`
-	TaskID      *uint      `json:"task_id,omitempty"`
-	Date        *time.Time `json:"date,omitempty"`
-	StartTime   *time.Time `json:"start_time,omitempty"`
-	EndTime     *time.Time `json:"end_time,omitempty"`
-	Hours       *float64   `json:"hours,omitempty" validate:"omitempty,gt=0,lte=24"`
-	Description *string    `json:"description,omitempty"`
-	IsBillable  *bool      `json:"is_billable,omitempty"`
-	HourlyRate  *float64   `json:"hourly_rate,omitempty"`
-	Status      *string    `json:"status,omitempty" validate:"omitempty,oneof=draft submitted approved billed"`
+	EmployeeID  *uint         `json:"employee_id,omitempty"`
+	ProjectID   *uint         `json:"project_id,omitempty"`
+	TaskID      *uint         `json:"task_id,omitempty"`
+	Date        *time.Time    `json:"date,omitempty"`
+	StartTime   *time.Time    `json:"start_time,omitempty"`
+	EndTime     *time.Time    `json:"end_time,omitempty"`
+	Hours       *float64      `json:"hours,omitempty" validate:"omitempty,gt=0,lte=24"`
+	Description *string       `json:"description,omitempty"`
+	IsBillable  *bool         `json:"is_billable,omitempty"`
+	HourlyRate  *models.Money `json:"hourly_rate,omitempty"`
+	Status      *string       `json:"status,omitempty" validate:"omitempty,oneof=draft submitted approved billed"`
 }
 
 // ProjectMemberCreateRequest 项目成员创建请求
@@ -118,25 +120,25 @@ type ProjectReportRequest struct {
 
 // ProjectResponse 项目响应
 type ProjectResponse struct {
-	ID              uint       `json:"id"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
-	ProjectCode     string     `json:"project_code"`
-	ProjectName     string     `json:"project_name"`
-	Name            string     `json:"name"`
-	Description     string     `json:"description"`
-	StartDate       time.Time  `json:"start_date"`
-	EndDate         time.Time  `json:"end_date"`
-	ActualStartDate *time.Time `json:"actual_start_date"`
-	ActualEndDate   *time.Time `json:"actual_end_date"`
-	Status          string     `json:"status"`
-	Priority        string     `json:"priority"`
-	Budget          float64    `json:"budget"`
-	ActualCost      float64    `json:"actual_cost"`
-	Progress        float64    `json:"progress"`
-	ClientID        uint       `json:"client_id"`
-	CustomerID      uint       `json:"customer_id"`
-	ManagerID       uint       `json:"manager_id"`
+	ID              uint         `json:"id"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+	ProjectCode     string       `json:"project_code"`
+	ProjectName     string       `json:"project_name"`
+	Name            string       `json:"name"`
+	Description     string       `json:"description"`
+	StartDate       time.Time    `json:"start_date"`
+	EndDate         time.Time    `json:"end_date"`
+	ActualStartDate *time.Time   `json:"actual_start_date"`
+	ActualEndDate   *time.Time   `json:"actual_end_date"`
+	Status          string       `json:"status"`
+	Priority        string       `json:"priority"`
+	Budget          models.Money `json:"budget"`
+	ActualCost      models.Money `json:"actual_cost"`
+	Progress        float64      `json:"progress"`
+	ClientID        uint         `json:"client_id"`
+	CustomerID      uint         `json:"customer_id"`
+	ManagerID       uint         `json:"manager_id"`
 }
 
 // ProjectFilter 项目过滤器
@@ -220,19 +222,19 @@ type MilestoneResponse struct {
 
 // TimeEntryResponse 时间条目响应
 type TimeEntryResponse struct {
-	ID          uint      `json:"id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	EmployeeID  uint      `json:"employee_id"`
-	ProjectID   uint      `json:"project_id"`
-	TaskID      *uint     `json:"task_id,omitempty"`
-	Date        time.Time `json:"date"`
-	StartTime   time.Time `json:"start_time"`
-	EndTime     time.Time `json:"end_time"`
-	Hours       float64   `json:"hours"`
-	Description string    `json:"description"`
-	IsBillable  bool      `json:"is_billable"`
-	HourlyRate  float64   `json:"hourly_rate"`
-	Amount      float64   `json:"amount"`
-	Status      string    `json:"status"`
+	ID          uint         `json:"id"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	EmployeeID  uint         `json:"employee_id"`
+	ProjectID   uint         `json:"project_id"`
+	TaskID      *uint        `json:"task_id,omitempty"`
+	Date        time.Time    `json:"date"`
+	StartTime   time.Time    `json:"start_time"`
+	EndTime     time.Time    `json:"end_time"`
+	Hours       float64      `json:"hours"`
+	Description string       `json:"description"`
+	IsBillable  bool         `json:"is_billable"`
+	HourlyRate  models.Money `json:"hourly_rate"`
+	Amount      models.Money `json:"amount"`
+	Status      string       `json:"status"`
 }
@@ -2,65 +2,74 @@ package dto
 
 import (
 	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
 )
 
 // SupplierCreateRequest 供应商创建请求
 type SupplierCreateRequest struct {
-	Name         string  `json:"name" validate:"required,max=100"`
-	Code         string  `json:"code" validate:"required,max=50"`
-	ContactName  string  `json:"contact_name,omitempty" validate:"max=50"`
-	Email        string  `json:"email,omitempty" validate:"omitempty,email"`
-	Phone        string  `json:"phone,omitempty" validate:"max=20"`
-	Address      string  `json:"address,omitempty"`
-	TaxNumber    string  `json:"tax_number,omitempty" validate:"max=50"`
-	PaymentTerms string  `json:"payment_terms,omitempty"`
-	CreditLimit  float64 `json:"credit_limit,omitempty" validate:"min=0"`
+	RepresentsCompanyID *uint        `json:"represents_company_id,omitempty"`
+	Name                string       `json:"name" validate:"required,max=100"`
+	Code                string       `json:"code" validate:"required,max=50"`
+	ContactName         string       `json:"contact_name,omitempty" validate:"max=50"`
+	Email               string       `json:"email,omitempty" validate:"omitempty,email"`
+	Phone               string       `json:"phone,omitempty" validate:"max=20"`
+	Address             string       `json:"address,omitempty"`
+	TaxNumber           string       `json:"tax_number,omitempty" validate:"max=50"`
+	PaymentTerms        string       `json:"payment_terms,omitempty"`
+	CreditLimit         models.Money `json:"credit_limit,omitempty" validate:"min=0"`
 }
 
 // SupplierUpdateRequest 供应商更新请求
 type SupplierUpdateRequest struct {
-	Code         *string  `json:"code,omitempty" validate:"omitempty,max=50"`
-	Name         *string  `json:"name,omitempty" validate:"omitempty,max=100"`
-	ContactName  *string  `json:"contact_name,omitempty" validate:"omitempty,max=50"`
-	Email        *string  `json:"email,omitempty" validate:"omitempty,email"`
-	Phone        *string  `json:"phone,omitempty" validate:"omitempty,max=20"`
-	Address      *string  `json:"address,omitempty"`
-	TaxNumber    *string  `json:"tax_number,omitempty" validate:"omitempty,max=50"`
-	PaymentTerms *string  `json:"payment_terms,omitempty"`
-	CreditLimit  *float64 `json:"credit_limit,omitempty" validate:"omitempty,min=0"`
-	IsActive     *bool    `json:"is_active,omitempty"`
+	RepresentsCompanyID *uint         `json:"represents_company_id,omitempty"`
+	Code                *string       `json:"code,omitempty" validate:"omitempty,max=50"`
+	Name                *string       `json:"name,omitempty" validate:"omitempty,max=100"`
+	ContactName         *string       `json:"contact_name,omitempty" validate:"omitempty,max=50"`
+	Email               *string       `json:"email,omitempty" validate:"omitempty,email"`
+	Phone               *string       `json:"phone,omitempty" validate:"omitempty,max=20"`
+	Address             *string       `json:"address,omitempty"`
+	TaxNumber           *string       `json:"tax_number,omitempty" validate:"omitempty,max=50"`
+	PaymentTerms        *string       `json:"payment_terms,omitempty"`
+	CreditLimit         *models.Money `json:"credit_limit,omitempty" validate:"omitempty,min=0"`
+	IsActive            *bool         `json:"is_active,omitempty"`
 }
 
 // SupplierResponse 供应商响应
 type SupplierResponse struct {
 	BaseModel
-	Name         string  `json:"name"`
-	Code         string  `json:"code"`
-	ContactName  string  `json:"contact_name,omitempty"`
-	Email        string  `json:"email,omitempty"`
-	Phone        string  `json:"phone,omitempty"`
-	Address      string  `json:"address,omitempty"`
-	TaxNumber    string  `json:"tax_number,omitempty"`
-	PaymentTerms string  `json:"payment_terms,omitempty"`
-	CreditLimit  float64 `json:"credit_limit"`
-	IsActive     bool    `json:"is_active"`
+	RepresentsCompanyID *uint        `json:"represents_company_id,omitempty"`
+	Name                string       `json:"name"`
+	Code                string       `json:"code"`
+	ContactName         string       `json:"contact_name,omitempty"`
+	Email               string       `json:"email,omitempty"`
+	Phone               string       `json:"phone,omitempty"`
+	Address             string       `json:"address,omitempty"`
+	TaxNumber           string       `json:"tax_number,omitempty"`
+	PaymentTerms        string       `json:"payment_terms,omitempty"`
+	CreditLimit         models.Money `json:"credit_limit"`
+	IsActive            bool         `json:"is_active"`
 }
 
 // PurchaseRequestCreateRequest 采购申请创建请求
 type PurchaseRequestCreateRequest struct {
+	CompanyID    uint                         `json:"company_id,omitempty"`
 	Title        string                       `json:"title" validate:"required,max=200"`
 	Description  string                       `json:"description,omitempty"`
 	Priority     string                       `json:"priority" validate:"required,oneof=low medium high urgent"`
 	RequiredDate time.Time                    `json:"required_date" validate:"required"`
+	SupplierID   *uint                        `json:"supplier_id,omitempty"`
 	Items        []PurchaseRequestItemRequest `json:"items" validate:"required,min=1"`
 }
 
 // PurchaseRequestItemRequest 采购申请项目请求
 type PurchaseRequestItemRequest struct {
-	ItemID    uint    `json:"item_id" validate:"required"`
-	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
-	UnitPrice float64 `json:"unit_price,omitempty" validate:"min=0"`
-	Notes     string  `json:"notes,omitempty"`
+	ItemID      uint         `json:"item_id" validate:"required"`
+	Quantity    float64      `json:"quantity" validate:"required,gt=0"`
+	UOM         string       `json:"uom,omitempty" validate:"max=50"` // 数量与单价的单位，为空时为库存单位
+	UnitPrice   models.Money `json:"unit_price,omitempty" validate:"min=0"`
+	WarehouseID *uint        `json:"warehouse_id,omitempty"`
+	Notes       string       `json:"notes,omitempty"`
 }
 
 // PurchaseRequestUpdateRequest 采购申请更新请求
@@ -75,14 +84,17 @@ type PurchaseRequestUpdateRequest struct {
 // PurchaseRequestResponse 采购申请响应
 type PurchaseRequestResponse struct {
 	ID           uint                          `json:"id"`
+	CompanyID    uint                          `json:"company_id,omitempty"`
 	Number       string                        `json:"number"`
 	Title        string                        `json:"title"`
 	Description  string                        `json:"description,omitempty"`
 	Priority     string                        `json:"priority"`
 	Status       string                        `json:"status"`
+	Source       string                        `json:"source"`
+	SupplierID   *uint                         `json:"supplier_id,omitempty"`
 	Department   string                        `json:"department,omitempty"`
 	RequiredDate time.Time                     `json:"required_date"`
-	TotalAmount  float64                       `json:"total_amount"`
+	TotalAmount  models.Money                  `json:"total_amount"`
 	Items        []PurchaseRequestItemResponse `json:"items"`
 	CreatedBy    UserResponse                  `json:"created_by"`
 	ApprovedBy   *UserResponse                 `json:"approved_by,omitempty"`
@@ -92,16 +104,20 @@ type PurchaseRequestResponse struct {
 
 // PurchaseRequestItemResponse 采购申请项目响应
 type PurchaseRequestItemResponse struct {
-	ID        uint         `json:"id"`
-	Quantity  float64      `json:"quantity"`
-	UnitPrice float64      `json:"unit_price"`
-	Amount    float64      `json:"amount"`
-	Notes     string       `json:"notes,omitempty"`
-	Item      ItemResponse `json:"item"`
+	ID               uint         `json:"id"`
+	Quantity         float64      `json:"quantity"`
+	UOM              string       `json:"uom,omitempty"`
+	ConversionFactor float64      `json:"conversion_factor,omitempty"`
+	UnitPrice        models.Money `json:"unit_price"`
+	Amount           models.Money `json:"amount"`
+	WarehouseID      *uint        `json:"warehouse_id,omitempty"`
+	Notes            string       `json:"notes,omitempty"`
+	Item             ItemResponse `json:"item"`
 }
 
 // PurchaseOrderCreateRequest 采购订单创建请求
 type PurchaseOrderCreateRequest struct {
+	CompanyID    uint                       `json:"company_id,omitempty"`
 	SupplierID   uint                       `json:"supplier_id" validate:"required"`
 	RequestID    *uint                      `json:"request_id,omitempty"`
 	OrderDate    time.Time                  `json:"order_date" validate:"required"`
@@ -111,16 +127,17 @@ type PurchaseOrderCreateRequest struct {
 	PaymentTerms string                     `json:"payment_terms,omitempty"`
 	Terms        string                     `json:"terms,omitempty"`
 	Notes        string                     `json:"notes,omitempty"`
+	PriceListID  *uint                      `json:"price_list_id,omitempty"` // 指定采购价格表，为空时按供应商与默认价格表取价
 	Items        []PurchaseOrderItemRequest `json:"items" validate:"required,min=1"`
 }
 
 // PurchaseOrderItemRequest 采购订单项目请求
 type PurchaseOrderItemRequest struct {
-	ItemID    uint    `json:"item_id" validate:"required"`
-	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
-	UnitPrice float64 `json:"unit_price" validate:"required,gt=0"`
-	TaxRate   float64 `json:"tax_rate,omitempty" validate:"min=0,max=100"`
-	Notes     string  `json:"notes,omitempty"`
+	ItemID    uint         `json:"item_id" validate:"required"`
+	Quantity  float64      `json:"quantity" validate:"required,gt=0"`
+	UnitPrice models.Money `json:"unit_price,omitempty" validate:"omitempty,gt=0"` // 为空时按价格表取价
+	TaxRate   float64      `json:"tax_rate,omitempty" validate:"min=0,max=100"`
+	Notes     string       `json:"notes,omitempty"`
 }
 
 // PurchaseOrderUpdateRequest 采购订单更新请求
@@ -139,6 +156,7 @@ type PurchaseOrderUpdateRequest struct {
 // PurchaseOrderResponse 采购订单响应
 type PurchaseOrderResponse struct {
 	BaseModel
+	CompanyID         uint                        `json:"company_id,omitempty"`
 	OrderNumber       string                      `json:"order_number"`
 	SupplierID        uint                        `json:"supplier_id"`
 	OrderDate         time.Time                   `json:"order_date"`
@@ -153,10 +171,11 @@ type PurchaseOrderResponse struct {
 	Terms             string                      `json:"terms,omitempty"`
 	Notes             string                      `json:"notes,omitempty"`
 	PurchaseRequestID *uint                       `json:"purchase_request_id,omitempty"`
-	SubTotal          float64                     `json:"sub_total"`
-	TotalDiscount     float64                     `json:"total_discount"`
-	TotalTax          float64                     `json:"total_tax"`
-	TotalAmount       float64                     `json:"total_amount"`
+	PriceListID       *uint                       `json:"price_list_id,omitempty"`
+	SubTotal          models.Money                `json:"sub_total"`
+	TotalDiscount     models.Money                `json:"total_discount"`
+	TotalTax          models.Money                `json:"total_tax"`
+	TotalAmount       models.Money                `json:"total_amount"`
 	Supplier          *SupplierResponse           `json:"supplier,omitempty"`
 	Request           *PurchaseRequestResponse    `json:"request,omitempty"`
 	Items             []PurchaseOrderItemResponse `json:"items"`
@@ -166,15 +185,16 @@ type PurchaseOrderResponse struct {
 
 // PurchaseOrderItemResponse 采购订单项目响应
 type PurchaseOrderItemResponse struct {
-	ID          uint         `json:"id"`
-	Quantity    float64      `json:"quantity"`
-	UnitPrice   float64      `json:"unit_price"`
-	TaxRate     float64      `json:"tax_rate"`
-	TaxAmount   float64      `json:"tax_amount"`
-	Amount      float64      `json:"amount"`
-	ReceivedQty float64      `json:"received_qty"`
-	Notes       string       `json:"notes,omitempty"`
-	Item        ItemResponse `json:"item"`
+	ID            uint         `json:"id"`
+	Quantity      float64      `json:"quantity"`
+	PriceListRate models.Money `json:"price_list_rate"`
+	UnitPrice     models.Money `json:"unit_price"`
+	TaxRate       float64      `json:"tax_rate"`
+	TaxAmount     models.Money `json:"tax_amount"`
+	Amount        models.Money `json:"amount"`
+	ReceivedQty   float64      `json:"received_qty"`
+	Notes         string       `json:"notes,omitempty"`
+	Item          ItemResponse `json:"item"`
 }
 
 // PurchaseReceiptCreateRequest 采购收货创建请求
@@ -229,23 +249,23 @@ type SupplierFilter struct {
 // PurchaseOrderFilter 采购订单过滤器
 type PurchaseOrderFilter struct {
 	SearchRequest
-	SupplierID *uint      `json:"supplier_id,omitempty" form:"supplier_id"`
-	Status     string     `json:"status,omitempty" form:"status"`
-	StartDate  *time.Time `json:"start_date,omitempty" form:"start_date"`
-	EndDate    *time.Time `json:"end_date,omitempty" form:"end_date"`
-	MinAmount  *float64   `json:"min_amount,omitempty" form:"min_amount"`
-	MaxAmount  *float64   `json:"max_amount,omitempty" form:"max_amount"`
+	SupplierID *uint         `json:"supplier_id,omitempty" form:"supplier_id"`
+	Status     string        `json:"status,omitempty" form:"status"`
+	StartDate  *time.Time    `json:"start_date,omitempty" form:"start_date"`
+	EndDate    *time.Time    `json:"end_date,omitempty" form:"end_date"`
+	MinAmount  *models.Money `json:"min_amount,omitempty" form:"min_amount"`
+	MaxAmount  *models.Money `json:"max_amount,omitempty" form:"max_amount"`
 }
 
 // PurchaseSearchRequest 采购搜索请求
 type PurchaseSearchRequest struct {
 	SearchRequest
-	SupplierID *uint      `json:"supplier_id,omitempty" form:"supplier_id"`
-	StartDate  *time.Time `json:"start_date,omitempty" form:"start_date"`
-	EndDate    *time.Time `json:"end_date,omitempty" form:"end_date"`
-	MinAmount  *float64   `json:"min_amount,omitempty" form:"min_amount"`
-	MaxAmount  *float64   `json:"max_amount,omitempty" form:"max_amount"`
-	Priority   string     `json:"priority,omitempty" form:"priority"`
+	SupplierID *uint         `json:"supplier_id,omitempty" form:"supplier_id"`
+	StartDate  *time.Time    `json:"start_date,omitempty" form:"start_date"`
+	EndDate    *time.Time    `json:"end_date,omitempty" form:"end_date"`
+	MinAmount  *models.Money `json:"min_amount,omitempty" form:"min_amount"`
+	MaxAmount  *models.Money `json:"max_amount,omitempty" form:"max_amount"`
+	Priority   string        `json:"priority,omitempty" form:"priority"`
 }
 
 // PurchaseApprovalRequest 采购审批请求
@@ -257,7 +277,7 @@ type PurchaseApprovalRequest struct {
 // PurchaseStatisticsResponse 采购统计响应
 type PurchaseStatisticsResponse struct {
 	TotalOrders     int64                `json:"total_orders"`
-	TotalAmount     float64              `json:"total_amount"`
+	TotalAmount     models.Money         `json:"total_amount"`
 	PendingOrders   int64                `json:"pending_orders"`
 	ApprovedOrders  int64                `json:"approved_orders"`
 	CompletedOrders int64                `json:"completed_orders"`
@@ -267,17 +287,17 @@ type PurchaseStatisticsResponse struct {
 
 // SupplierStatistics 供应商统计
 type SupplierStatistics struct {
-	SupplierID   uint    `json:"supplier_id"`
-	SupplierName string  `json:"supplier_name"`
-	OrderCount   int64   `json:"order_count"`
-	TotalAmount  float64 `json:"total_amount"`
+	SupplierID   uint         `json:"supplier_id"`
+	SupplierName string       `json:"supplier_name"`
+	OrderCount   int64        `json:"order_count"`
+	TotalAmount  models.Money `json:"total_amount"`
 }
 
 // MonthlyPurchase 月度采购
 type MonthlyPurchase struct {
-	Month       string  `json:"month"`
-	OrderCount  int64   `json:"order_count"`
-	TotalAmount float64 `json:"total_amount"`
+	Month       string       `json:"month"`
+	OrderCount  int64        `json:"order_count"`
+	TotalAmount models.Money `json:"total_amount"`
 }
 
 // PurchaseReportRequest 采购报告请求
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// ReplenishmentRequest 补货计算请求，ID 为 0 表示不筛选
+type ReplenishmentRequest struct {
+	WarehouseID uint       `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	ItemID      uint       `json:"item_id,omitempty" form:"item_id"`
+	SupplierID  uint       `json:"supplier_id,omitempty" form:"supplier_id"`
+	Priority    string     `json:"priority,omitempty" form:"priority" validate:"omitempty,oneof=low medium high urgent"`
+	RequiredBy  *time.Time `json:"required_by,omitempty"` // 需求日期，默认 7 天后
+}
+
+// ReplenishmentSuggestion 物料在仓库的补货建议
+type ReplenishmentSuggestion struct {
+	ItemID          uint         `json:"item_id"`
+	ItemCode        string       `json:"item_code"`
+	ItemName        string       `json:"item_name"`
+	Unit            string       `json:"unit,omitempty"`
+	WarehouseID     uint         `json:"warehouse_id"`
+	WarehouseCode   string       `json:"warehouse_code"`
+	OnHandQty       float64      `json:"on_hand_qty"`
+	OnOrderQty      float64      `json:"on_order_qty"`  // 未完成采购订单的未收货数量
+	RequestedQty    float64      `json:"requested_qty"` // 尚未转为采购订单的采购申请数量
+	ReservedQty     float64      `json:"reserved_qty"`  // 销售订单库存预留的未发货数量
+	ProjectedQty    float64      `json:"projected_qty"`
+	ReorderLevel    float64      `json:"reorder_level"`
+	ReorderQty      float64      `json:"reorder_qty"`
+	MaxLevel        float64      `json:"max_level"`
+	SuggestedQty    float64      `json:"suggested_qty"`
+	SupplierID      *uint        `json:"supplier_id,omitempty"`
+	SupplierName    string       `json:"supplier_name,omitempty"`
+	EstimatedCost   models.Money `json:"estimated_cost"`
+	EstimatedAmount models.Money `json:"estimated_amount"`
+}
+
+// ReplenishmentGroup 按首选供应商分组的补货建议，每组生成一张采购申请
+type ReplenishmentGroup struct {
+	SupplierID      *uint                     `json:"supplier_id,omitempty"`
+	SupplierName    string                    `json:"supplier_name,omitempty"`
+	Lines           []ReplenishmentSuggestion `json:"lines"`
+	EstimatedAmount models.Money              `json:"estimated_amount"`
+}
+
+// ReplenishmentPreviewResponse 补货建议预览
+type ReplenishmentPreviewResponse struct {
+	Groups          []ReplenishmentGroup `json:"groups"`
+	TotalLines      int                  `json:"total_lines"`
+	EstimatedAmount models.Money         `json:"estimated_amount"`
+}
+
+// ReplenishmentRequestSummary 补货生成的采购申请
+type ReplenishmentRequestSummary struct {
+	ID              uint         `json:"id"`
+	RequestNumber   string       `json:"request_number"`
+	SupplierID      *uint        `json:"supplier_id,omitempty"`
+	SupplierName    string       `json:"supplier_name,omitempty"`
+	LineCount       int          `json:"line_count"`
+	EstimatedAmount models.Money `json:"estimated_amount"`
+}
+
+// ReplenishmentRunResponse 补货执行结果
+type ReplenishmentRunResponse struct {
+	PurchaseRequests []ReplenishmentRequestSummary `json:"purchase_requests"`
+	TotalLines       int                           `json:"total_lines"`
+}
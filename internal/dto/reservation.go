@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// StockReservationListRequest 库存预留列表请求
+type StockReservationListRequest struct {
+	PaginationRequest
+	ItemID       uint   `json:"item_id,omitempty" form:"item_id"`
+	WarehouseID  uint   `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	SalesOrderID uint   `json:"sales_order_id,omitempty" form:"sales_order_id"`
+	Status       string `json:"status,omitempty" form:"status" validate:"omitempty,oneof=active released fulfilled"`
+	ShortageOnly bool   `json:"shortage_only,omitempty" form:"shortage_only"` // 仅显示库存不足未完全分配的预留
+}
+
+// StockReservationResponse 库存预留响应
+type StockReservationResponse struct {
+	ID               uint       `json:"id"`
+	SalesOrderID     uint       `json:"sales_order_id"`
+	SalesOrderNumber string     `json:"sales_order_number,omitempty"`
+	SalesOrderItemID uint       `json:"sales_order_item_id"`
+	Priority         int        `json:"priority"`
+	DeliveryDate     *time.Time `json:"delivery_date,omitempty"`
+	ItemID           uint       `json:"item_id"`
+	ItemCode         string     `json:"item_code,omitempty"`
+	ItemName         string     `json:"item_name,omitempty"`
+	WarehouseID      uint       `json:"warehouse_id"`
+	WarehouseCode    string     `json:"warehouse_code,omitempty"`
+	Quantity         float64    `json:"quantity"`      // 未发货的需求数量
+	ReservedQty      float64    `json:"reserved_qty"`  // 已分配的库存数量
+	ShortageQty      float64    `json:"shortage_qty"`  // 库存不足未分配的数量
+	DeliveredQty     float64    `json:"delivered_qty"` // 已发货数量
+	Status           string     `json:"status"`
+	ReleasedAt       *time.Time `json:"released_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// StockReservationReallocateRequest 重新分配库存预留请求，ID 为 0 表示不筛选
+type StockReservationReallocateRequest struct {
+	ItemID      uint `json:"item_id,omitempty"`
+	WarehouseID uint `json:"warehouse_id,omitempty"`
+}
+
+// StockReservationReallocateResult 重新分配结果
+type StockReservationReallocateResult struct {
+	Pairs int `json:"pairs"` // 重新分配的物料与仓库组合数
+}
@@ -2,73 +2,80 @@ package dto
 
 import (
 	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
 )
 
 // CustomerCreateRequest 客户创建请求
 type CustomerCreateRequest struct {
-	Name         string  `json:"name" validate:"required,max=100"`
-	Code         string  `json:"code" validate:"required,max=50"`
-	Type         string  `json:"type" validate:"required,oneof=individual corporate"`
-	ContactName  string  `json:"contact_name,omitempty" validate:"max=50"`
-	Email        string  `json:"email,omitempty" validate:"omitempty,email"`
-	Phone        string  `json:"phone,omitempty" validate:"omitempty,chinese_mobile"`
-	Address      string  `json:"address,omitempty"`
-	TaxNumber    string  `json:"tax_number,omitempty" validate:"max=50"`
-	PaymentTerms string  `json:"payment_terms,omitempty"`
-	CreditLimit  float64 `json:"credit_limit,omitempty" validate:"min=0,currency"`
+	RepresentsCompanyID *uint        `json:"represents_company_id,omitempty"`
+	Name                string       `json:"name" validate:"required,max=100"`
+	Code                string       `json:"code" validate:"required,max=50"`
+	Type                string       `json:"type" validate:"required,oneof=individual corporate"`
+	ContactName         string       `json:"contact_name,omitempty" validate:"max=50"`
+	Email               string       `json:"email,omitempty" validate:"omitempty,email"`
+	Phone               string       `json:"phone,omitempty" validate:"omitempty,chinese_mobile"`
+	Address             string       `json:"address,omitempty"`
+	TaxNumber           string       `json:"tax_number,omitempty" validate:"max=50"`
+	PaymentTerms        string       `json:"payment_terms,omitempty"`
+	CreditLimit         models.Money `json:"credit_limit,omitempty" validate:"min=0,currency"`
 }
 
 // CustomerUpdateRequest 客户更新请求
 type CustomerUpdateRequest struct {
-	Name         string   `json:"name,omitempty" validate:"omitempty,max=100"`
-	Type         string   `json:"type,omitempty" validate:"omitempty,oneof=individual corporate"`
-	ContactName  string   `json:"contact_name,omitempty" validate:"omitempty,max=50"`
-	Email        string   `json:"email,omitempty" validate:"omitempty,email"`
-	Phone        string   `json:"phone,omitempty" validate:"omitempty,chinese_mobile"`
-	Address      string   `json:"address,omitempty"`
-	TaxNumber    string   `json:"tax_number,omitempty" validate:"omitempty,max=50"`
-	PaymentTerms string   `json:"payment_terms,omitempty"`
-	CreditLimit  *float64 `json:"credit_limit,omitempty" validate:"omitempty,min=0,currency"`
-	IsActive     *bool    `json:"is_active,omitempty"`
+	RepresentsCompanyID *uint         `json:"represents_company_id,omitempty"`
+	Name                string        `json:"name,omitempty" validate:"omitempty,max=100"`
+	Type                string        `json:"type,omitempty" validate:"omitempty,oneof=individual corporate"`
+	ContactName         string        `json:"contact_name,omitempty" validate:"omitempty,max=50"`
+	Email               string        `json:"email,omitempty" validate:"omitempty,email"`
+	Phone               string        `json:"phone,omitempty" validate:"omitempty,chinese_mobile"`
+	Address             string        `json:"address,omitempty"`
+	TaxNumber           string        `json:"tax_number,omitempty" validate:"omitempty,max=50"`
+	PaymentTerms        string        `json:"payment_terms,omitempty"`
+	CreditLimit         *models.Money `json:"credit_limit,omitempty" validate:"omitempty,min=0,currency"`
+	IsActive            *bool         `json:"is_active,omitempty"`
 }
 
 // CustomerResponse 客户响应
 type CustomerResponse struct {
-	ID           uint      `json:"id"`
-	Name         string    `json:"name"`
-	Code         string    `json:"code"`
-	Type         string    `json:"type"`
-	ContactName  string    `json:"contact_name,omitempty"`
-	Email        string    `json:"email,omitempty"`
-	Phone        string    `json:"phone,omitempty"`
-	Address      string    `json:"address,omitempty"`
-	TaxNumber    string    `json:"tax_number,omitempty"`
-	PaymentTerms string    `json:"payment_terms,omitempty"`
-	CreditLimit  float64   `json:"credit_limit"`
-	IsActive     bool      `json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                  uint         `json:"id"`
+	RepresentsCompanyID *uint        `json:"represents_company_id,omitempty"`
+	Name                string       `json:"name"`
+	Code                string       `json:"code"`
+	Type                string       `json:"type"`
+	ContactName         string       `json:"contact_name,omitempty"`
+	Email               string       `json:"email,omitempty"`
+	Phone               string       `json:"phone,omitempty"`
+	Address             string       `json:"address,omitempty"`
+	TaxNumber           string       `json:"tax_number,omitempty"`
+	PaymentTerms        string       `json:"payment_terms,omitempty"`
+	CreditLimit         models.Money `json:"credit_limit"`
+	IsActive            bool         `json:"is_active"`
+	CreatedAt           time.Time    `json:"created_at"`
+	UpdatedAt           time.Time    `json:"updated_at"`
 }
 
 // QuotationCreateRequest 报价单创建请求
 type QuotationCreateRequest struct {
+	CompanyID    uint                   `json:"company_id,omitempty"`
 	CustomerID   uint                   `json:"customer_id" validate:"required"`
 	Title        string                 `json:"title" validate:"required,max=200"`
 	Description  string                 `json:"description,omitempty"`
 	ValidUntil   time.Time              `json:"valid_until" validate:"required"`
 	PaymentTerms string                 `json:"payment_terms,omitempty"`
 	Notes        string                 `json:"notes,omitempty"`
+	PriceListID  *uint                  `json:"price_list_id,omitempty"` // 指定销售价格表，为空时按客户、客户组与默认价格表取价
 	Items        []QuotationItemRequest `json:"items" validate:"required,min=1"`
 }
 
 // QuotationItemRequest 报价单项目请求
 type QuotationItemRequest struct {
-	ItemID    uint    `json:"item_id" validate:"required"`
-	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
-	UnitPrice float64 `json:"unit_price" validate:"required,gt=0,currency"`
-	Discount  float64 `json:"discount,omitempty" validate:"min=0,max=100"`
-	TaxRate   float64 `json:"tax_rate,omitempty" validate:"min=0,max=100"`
-	Notes     string  `json:"notes,omitempty"`
+	ItemID    uint         `json:"item_id" validate:"required"`
+	Quantity  float64      `json:"quantity" validate:"required,gt=0"`
+	UnitPrice models.Money `json:"unit_price,omitempty" validate:"omitempty,gt=0,currency"` // 为空时按价格表取价
+	Discount  float64      `json:"discount,omitempty" validate:"min=0,max=100"`
+	TaxRate   float64      `json:"tax_rate,omitempty" validate:"min=0,max=100"`
+	Notes     string       `json:"notes,omitempty"`
 }
 
 // QuotationUpdateRequest 报价单更新请求
@@ -79,13 +86,14 @@ type QuotationUpdateRequest struct {
 	ValidUntil   *time.Time             `json:"valid_until,omitempty"`
 	PaymentTerms string                 `json:"payment_terms,omitempty"`
 	Notes        string                 `json:"notes,omitempty"`
-	TotalAmount  *float64               `json:"total_amount,omitempty"`
+	TotalAmount  *models.Money          `json:"total_amount,omitempty"`
 	Items        []QuotationItemRequest `json:"items,omitempty"`
 }
 
 // QuotationResponse 报价单响应
 type QuotationResponse struct {
 	ID              uint                    `json:"id"`
+	CompanyID       uint                    `json:"company_id,omitempty"`
 	Number          string                  `json:"number"`
 	QuotationNumber string                  `json:"quotationNumber"` // 前端期望的字段名
 	Title           string                  `json:"title"`
@@ -97,11 +105,12 @@ type QuotationResponse struct {
 	Date            time.Time               `json:"date"`      // 前端期望的字段名
 	PaymentTerms    string                  `json:"payment_terms,omitempty"`
 	Notes           string                  `json:"notes,omitempty"`
-	SubTotal        float64                 `json:"sub_total"`
-	DiscountAmount  float64                 `json:"discount_amount"`
-	TaxAmount       float64                 `json:"tax_amount"`
-	TotalAmount     float64                 `json:"total_amount"`
-	GrandTotal      float64                 `json:"grand_total"` // 前端期望的字段名
+	PriceListID     *uint                   `json:"price_list_id,omitempty"`
+	SubTotal        models.Money            `json:"sub_total"`
+	DiscountAmount  models.Money            `json:"discount_amount"`
+	TaxAmount       models.Money            `json:"tax_amount"`
+	TotalAmount     models.Money            `json:"total_amount"`
+	GrandTotal      models.Money            `json:"grand_total"` // 前端期望的字段名
 	Customer        CustomerResponse        `json:"customer"`
 	Items           []QuotationItemResponse `json:"items"`
 	CreatedBy       UserResponse            `json:"created_by"`
@@ -112,39 +121,64 @@ type QuotationResponse struct {
 // QuotationItemResponse 报价单项目响应
 type QuotationItemResponse struct {
 	ID             uint         `json:"id"`
+	ItemID         uint         `json:"item_id"`
 	Quantity       float64      `json:"quantity"`
-	UnitPrice      float64      `json:"unit_price"`
+	OrderedQty     float64      `json:"ordered_qty"`
+	PriceListRate  models.Money `json:"price_list_rate"`
+	UnitPrice      models.Money `json:"unit_price"`
 	Discount       float64      `json:"discount"`
-	DiscountAmount float64      `json:"discount_amount"`
+	DiscountAmount models.Money `json:"discount_amount"`
 	TaxRate        float64      `json:"tax_rate"`
-	TaxAmount      float64      `json:"tax_amount"`
-	Amount         float64      `json:"amount"`
+	TaxAmount      models.Money `json:"tax_amount"`
+	Amount         models.Money `json:"amount"`
 	Notes          string       `json:"notes,omitempty"`
 	Item           ItemResponse `json:"item"`
 }
 
+// QuotationConvertRequest 报价单转销售订单请求；不选择明细时转入全部未下单数量
+type QuotationConvertRequest struct {
+	OrderDate    *time.Time                    `json:"order_date,omitempty"`    // 默认当天
+	DeliveryDate *time.Time                    `json:"delivery_date,omitempty"` // 默认为订单日期
+	Priority     int                           `json:"priority,omitempty" validate:"min=0"`
+	Notes        string                        `json:"notes,omitempty"`
+	Items        []QuotationConvertItemRequest `json:"items,omitempty" validate:"omitempty,dive"`
+}
+
+// QuotationConvertItemRequest 转入销售订单的报价明细与数量
+type QuotationConvertItemRequest struct {
+	QuotationItemID uint    `json:"quotation_item_id" validate:"required"`
+	Quantity        float64 `json:"quantity,omitempty" validate:"omitempty,gt=0"` // 为空时转入该行全部未下单数量
+	WarehouseID     *uint   `json:"warehouse_id,omitempty"`
+}
+
 // SalesOrderCreateRequest 销售订单创建请求
 type SalesOrderCreateRequest struct {
+	CompanyID       uint                    `json:"company_id,omitempty"`
 	CustomerID      uint                    `json:"customer_id" validate:"required"`
 	QuotationID     *uint                   `json:"quotation_id,omitempty"`
 	OrderDate       time.Time               `json:"order_date" validate:"required"`
 	DeliveryDate    *time.Time              `json:"delivery_date,omitempty"`
 	ExpectedDate    time.Time               `json:"expected_date" validate:"required"`
 	Status          string                  `json:"status,omitempty"`
+	Priority        int                     `json:"priority,omitempty" validate:"min=0"` // 库存不足时优先级高的订单先分配预留
 	PaymentTerms    string                  `json:"payment_terms,omitempty"`
 	ShippingAddress string                  `json:"shipping_address,omitempty"`
 	Notes           string                  `json:"notes,omitempty"`
+	PriceListID     *uint                   `json:"price_list_id,omitempty"`                       // 指定销售价格表，为空时按客户、客户组与默认价格表取价
+	Currency        string                  `json:"currency,omitempty" validate:"omitempty,len=3"` // 默认本位币
+	ExchangeRate    float64                 `json:"exchange_rate,omitempty" validate:"omitempty,gt=0"`
 	Items           []SalesOrderItemRequest `json:"items" validate:"required,min=1"`
 }
 
 // SalesOrderItemRequest 销售订单项目请求
 type SalesOrderItemRequest struct {
-	ItemID    uint    `json:"item_id" validate:"required"`
-	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
-	UnitPrice float64 `json:"unit_price" validate:"required,gt=0,currency"`
-	Discount  float64 `json:"discount,omitempty" validate:"min=0,max=100"`
-	TaxRate   float64 `json:"tax_rate,omitempty" validate:"min=0,max=100"`
-	Notes     string  `json:"notes,omitempty"`
+	ItemID      uint         `json:"item_id" validate:"required"`
+	Quantity    float64      `json:"quantity" validate:"required,gt=0"`
+	UnitPrice   models.Money `json:"unit_price,omitempty" validate:"omitempty,gt=0,currency"` // 为空时按价格表取价
+	Discount    float64      `json:"discount,omitempty" validate:"min=0,max=100"`
+	TaxRate     float64      `json:"tax_rate,omitempty" validate:"min=0,max=100"`
+	WarehouseID *uint        `json:"warehouse_id,omitempty"` // 发货仓库，订单确认时在该仓库预留库存
+	Notes       string       `json:"notes,omitempty"`
 }
 
 // SalesOrderUpdateRequest 销售订单更新请求
@@ -154,32 +188,58 @@ type SalesOrderUpdateRequest struct {
 	DeliveryDate    *time.Time              `json:"delivery_date,omitempty"`
 	ExpectedDate    *time.Time              `json:"expected_date,omitempty"`
 	Status          *string                 `json:"status,omitempty"`
+	Priority        *int                    `json:"priority,omitempty" validate:"omitempty,min=0"`
 	PaymentTerms    string                  `json:"payment_terms,omitempty"`
 	ShippingAddress string                  `json:"shipping_address,omitempty"`
 	Notes           *string                 `json:"notes,omitempty"`
-	TotalAmount     *float64                `json:"total_amount,omitempty"`
+	TotalAmount     *models.Money           `json:"total_amount,omitempty"`
 	Items           []SalesOrderItemRequest `json:"items,omitempty"`
 }
 
+// SalesOrderStatusUpdateRequest 销售订单状态变更请求
+type SalesOrderStatusUpdateRequest struct {
+	Status string `json:"status" validate:"required"`
+	Reason string `json:"reason,omitempty"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+// SalesOrderStatusLogResponse 销售订单状态变更日志响应
+type SalesOrderStatusLogResponse struct {
+	ID           uint      `json:"id"`
+	SalesOrderID uint      `json:"sales_order_id"`
+	FromStatus   string    `json:"from_status,omitempty"`
+	ToStatus     string    `json:"to_status"`
+	ChangedBy    uint      `json:"changed_by"`
+	ChangedAt    time.Time `json:"changed_at"`
+	Reason       string    `json:"reason,omitempty"`
+	Notes        string    `json:"notes,omitempty"`
+}
+
 // SalesOrderResponse 销售订单响应
 type SalesOrderResponse struct {
 	ID              uint                     `json:"id"`
+	CompanyID       uint                     `json:"company_id,omitempty"`
 	Number          string                   `json:"number"`
 	OrderNumber     string                   `json:"orderNumber"` // 前端期望的字段名
 	Status          string                   `json:"status"`
+	Priority        int                      `json:"priority"`
 	OrderDate       time.Time                `json:"orderDate"`    // 前端期望的订单日期字段
 	DeliveryDate    time.Time                `json:"deliveryDate"` // 前端期望的交付日期字段
 	ExpectedDate    time.Time                `json:"expected_date"`
 	PaymentTerms    string                   `json:"payment_terms,omitempty"`
 	ShippingAddress string                   `json:"shipping_address,omitempty"`
 	Notes           string                   `json:"notes,omitempty"`
-	SubTotal        float64                  `json:"sub_total"`
-	DiscountAmount  float64                  `json:"discount_amount"`
-	TaxAmount       float64                  `json:"tax_amount"`
-	TotalAmount     float64                  `json:"total_amount"`
+	PriceListID     *uint                    `json:"price_list_id,omitempty"`
+	Currency        string                   `json:"currency"`
+	ExchangeRate    float64                  `json:"exchange_rate"`
+	SubTotal        models.Money             `json:"sub_total"`
+	DiscountAmount  models.Money             `json:"discount_amount"`
+	TaxAmount       models.Money             `json:"tax_amount"`
+	TotalAmount     models.Money             `json:"total_amount"`
 	Customer        CustomerResponse         `json:"customer"`
 	Quotation       *QuotationResponse       `json:"quotation,omitempty"`
 	Items           []SalesOrderItemResponse `json:"items"`
+	NextStatuses    []string                 `json:"next_statuses,omitempty"` // 当前状态允许变更到的状态
 	CreatedBy       UserResponse             `json:"created_by"`
 	ApprovedBy      *UserResponse            `json:"approved_by,omitempty"`
 	CreatedAt       time.Time                `json:"created_at"`
@@ -188,21 +248,25 @@ type SalesOrderResponse struct {
 
 // SalesOrderItemResponse 销售订单项目响应
 type SalesOrderItemResponse struct {
-	ID             uint         `json:"id"`
-	SalesOrderID   uint         `json:"sales_order_id"`
-	ItemID         uint         `json:"item_id"`
-	Quantity       float64      `json:"quantity"`
-	UnitPrice      float64      `json:"unit_price"`
-	Discount       float64      `json:"discount"`
-	DiscountAmount float64      `json:"discount_amount"`
-	TaxRate        float64      `json:"tax_rate"`
-	TaxAmount      float64      `json:"tax_amount"`
-	LineTotal      float64      `json:"line_total"`
-	DeliveredQty   float64      `json:"delivered_qty"`
-	Description    string       `json:"description,omitempty"`
-	Item           ItemResponse `json:"item"`
-	CreatedAt      time.Time    `json:"created_at"`
-	UpdatedAt      time.Time    `json:"updated_at"`
+	ID              uint         `json:"id"`
+	SalesOrderID    uint         `json:"sales_order_id"`
+	QuotationItemID *uint        `json:"quotation_item_id,omitempty"`
+	ItemID          uint         `json:"item_id"`
+	Quantity        float64      `json:"quantity"`
+	PriceListRate   models.Money `json:"price_list_rate"`
+	UnitPrice       models.Money `json:"unit_price"`
+	Discount        float64      `json:"discount"`
+	DiscountAmount  models.Money `json:"discount_amount"`
+	TaxRate         float64      `json:"tax_rate"`
+	TaxAmount       models.Money `json:"tax_amount"`
+	LineTotal       models.Money `json:"line_total"`
+	DeliveredQty    float64      `json:"delivered_qty"`
+	BilledQty       float64      `json:"billed_qty"`
+	WarehouseID     *uint        `json:"warehouse_id,omitempty"`
+	Description     string       `json:"description,omitempty"`
+	Item            ItemResponse `json:"item"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
 }
 
 // DeliveryCreateRequest 发货创建请求
@@ -261,10 +325,10 @@ type InvoiceCreateRequest struct {
 
 // InvoiceItemRequest 发票项目请求
 type InvoiceItemRequest struct {
-	OrderItemID uint    `json:"order_item_id" validate:"required"`
-	Quantity    float64 `json:"quantity" validate:"required,gt=0"`
-	UnitPrice   float64 `json:"unit_price" validate:"required,gt=0"`
-	Notes       string  `json:"notes,omitempty"`
+	OrderItemID uint         `json:"order_item_id" validate:"required"`
+	Quantity    float64      `json:"quantity" validate:"required,gt=0"`
+	UnitPrice   models.Money `json:"unit_price" validate:"required,gt=0"`
+	Notes       string       `json:"notes,omitempty"`
 }
 
 // InvoiceResponse 发票响应
@@ -275,11 +339,11 @@ type InvoiceResponse struct {
 	DueDate       time.Time             `json:"due_date"`
 	Notes         string                `json:"notes,omitempty"`
 	Status        string                `json:"status"`
-	SubTotal      float64               `json:"sub_total"`
-	TaxAmount     float64               `json:"tax_amount"`
-	TotalAmount   float64               `json:"total_amount"`
-	PaidAmount    float64               `json:"paid_amount"`
-	BalanceAmount float64               `json:"balance_amount"`
+	SubTotal      models.Money          `json:"sub_total"`
+	TaxAmount     models.Money          `json:"tax_amount"`
+	TotalAmount   models.Money          `json:"total_amount"`
+	PaidAmount    models.Money          `json:"paid_amount"`
+	BalanceAmount models.Money          `json:"balance_amount"`
 	Order         SalesOrderResponse    `json:"order"`
 	Items         []InvoiceItemResponse `json:"items"`
 	CreatedBy     UserResponse          `json:"created_by"`
@@ -291,8 +355,8 @@ type InvoiceResponse struct {
 type InvoiceItemResponse struct {
 	ID        uint                   `json:"id"`
 	Quantity  float64                `json:"quantity"`
-	UnitPrice float64                `json:"unit_price"`
-	Amount    float64                `json:"amount"`
+	UnitPrice models.Money           `json:"unit_price"`
+	Amount    models.Money           `json:"amount"`
 	Notes     string                 `json:"notes,omitempty"`
 	OrderItem SalesOrderItemResponse `json:"order_item"`
 }
@@ -300,22 +364,22 @@ type InvoiceItemResponse struct {
 // SalesOrderFilter 销售订单过滤器
 type SalesOrderFilter struct {
 	SearchRequest
-	CustomerID *uint      `json:"customer_id,omitempty" form:"customer_id"`
-	Status     string     `json:"status,omitempty" form:"status"`
-	StartDate  *time.Time `json:"start_date,omitempty" form:"start_date"`
-	EndDate    *time.Time `json:"end_date,omitempty" form:"end_date"`
-	MinAmount  *float64   `json:"min_amount,omitempty" form:"min_amount"`
-	MaxAmount  *float64   `json:"max_amount,omitempty" form:"max_amount"`
+	CustomerID *uint         `json:"customer_id,omitempty" form:"customer_id"`
+	Status     string        `json:"status,omitempty" form:"status"`
+	StartDate  *time.Time    `json:"start_date,omitempty" form:"start_date"`
+	EndDate    *time.Time    `json:"end_date,omitempty" form:"end_date"`
+	MinAmount  *models.Money `json:"min_amount,omitempty" form:"min_amount"`
+	MaxAmount  *models.Money `json:"max_amount,omitempty" form:"max_amount"`
 }
 
 // SalesSearchRequest 销售搜索请求
 type SalesSearchRequest struct {
 	SearchRequest
-	CustomerID *uint      `json:"customer_id,omitempty" form:"customer_id"`
-	StartDate  *time.Time `json:"start_date,omitempty" form:"start_date"`
-	EndDate    *time.Time `json:"end_date,omitempty" form:"end_date"`
-	MinAmount  *float64   `json:"min_amount,omitempty" form:"min_amount"`
-	MaxAmount  *float64   `json:"max_amount,omitempty" form:"max_amount"`
+	CustomerID *uint         `json:"customer_id,omitempty" form:"customer_id"`
+	StartDate  *time.Time    `json:"start_date,omitempty" form:"start_date"`
+	EndDate    *time.Time    `json:"end_date,omitempty" form:"end_date"`
+	MinAmount  *models.Money `json:"min_amount,omitempty" form:"min_amount"`
+	MaxAmount  *models.Money `json:"max_amount,omitempty" form:"max_amount"`
 }
 
 // SalesApprovalRequest 销售审批请求
@@ -327,7 +391,7 @@ type SalesApprovalRequest struct {
 // SalesStatisticsResponse 销售统计响应
 type SalesStatisticsResponse struct {
 	TotalOrders     int64                `json:"total_orders"`
-	TotalAmount     float64              `json:"total_amount"`
+	TotalAmount     models.Money         `json:"total_amount"`
 	PendingOrders   int64                `json:"pending_orders"`
 	ApprovedOrders  int64                `json:"approved_orders"`
 	CompletedOrders int64                `json:"completed_orders"`
@@ -338,25 +402,25 @@ type SalesStatisticsResponse struct {
 
 // CustomerStatistics 客户统计
 type CustomerStatistics struct {
-	CustomerID   uint    `json:"customer_id"`
-	CustomerName string  `json:"customer_name"`
-	OrderCount   int64   `json:"order_count"`
-	TotalAmount  float64 `json:"total_amount"`
+	CustomerID   uint         `json:"customer_id"`
+	CustomerName string       `json:"customer_name"`
+	OrderCount   int64        `json:"order_count"`
+	TotalAmount  models.Money `json:"total_amount"`
 }
 
 // ProductStatistics 产品统计
 type ProductStatistics struct {
-	ItemID      uint    `json:"item_id"`
-	ItemName    string  `json:"item_name"`
-	SoldQty     float64 `json:"sold_qty"`
-	TotalAmount float64 `json:"total_amount"`
+	ItemID      uint         `json:"item_id"`
+	ItemName    string       `json:"item_name"`
+	SoldQty     float64      `json:"sold_qty"`
+	TotalAmount models.Money `json:"total_amount"`
 }
 
 // MonthlySales 月度销售
 type MonthlySales struct {
-	Month       string  `json:"month"`
-	OrderCount  int64   `json:"order_count"`
-	TotalAmount float64 `json:"total_amount"`
+	Month       string       `json:"month"`
+	OrderCount  int64        `json:"order_count"`
+	TotalAmount models.Money `json:"total_amount"`
 }
 
 // SalesReportRequest 销售报告请求
@@ -403,13 +467,13 @@ type QuotationTemplateUpdateRequest struct {
 
 // QuotationTemplateItemCreateRequest 报价单模板项目创建请求
 type QuotationTemplateItemCreateRequest struct {
-	ItemID       uint    `json:"item_id" validate:"required"`
-	Description  string  `json:"description,omitempty"`
-	Quantity     float64 `json:"quantity" validate:"required,gt=0"`
-	Rate         float64 `json:"rate" validate:"required,gt=0"`
-	DiscountRate float64 `json:"discount_rate,omitempty" validate:"min=0,max=100"`
-	TaxRate      float64 `json:"tax_rate,omitempty" validate:"min=0,max=100"`
-	SortOrder    int     `json:"sort_order,omitempty"`
+	ItemID       uint         `json:"item_id" validate:"required"`
+	Description  string       `json:"description,omitempty"`
+	Quantity     float64      `json:"quantity" validate:"required,gt=0"`
+	Rate         models.Money `json:"rate" validate:"required,gt=0"`
+	DiscountRate float64      `json:"discount_rate,omitempty" validate:"min=0,max=100"`
+	TaxRate      float64      `json:"tax_rate,omitempty" validate:"min=0,max=100"`
+	SortOrder    int          `json:"sort_order,omitempty"`
 }
 
 // QuotationTemplateResponse 报价单模板响应
@@ -431,14 +495,14 @@ type QuotationTemplateResponse struct {
 
 // QuotationTemplateItemResponse 报价单模板项目响应
 type QuotationTemplateItemResponse struct {
-	ID           uint    `json:"id"`
-	ItemID       uint    `json:"item_id"`
-	Description  string  `json:"description,omitempty"`
-	Quantity     float64 `json:"quantity"`
-	Rate         float64 `json:"rate"`
-	DiscountRate float64 `json:"discount_rate"`
-	TaxRate      float64 `json:"tax_rate"`
-	SortOrder    int     `json:"sort_order"`
+	ID           uint         `json:"id"`
+	ItemID       uint         `json:"item_id"`
+	Description  string       `json:"description,omitempty"`
+	Quantity     float64      `json:"quantity"`
+	Rate         models.Money `json:"rate"`
+	DiscountRate float64      `json:"discount_rate"`
+	TaxRate      float64      `json:"tax_rate"`
+	SortOrder    int          `json:"sort_order"`
 }
 
 // CreateQuotationFromTemplateRequest 从模板创建报价单请求
@@ -2,10 +2,13 @@ package dto
 
 import (
 	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
 )
 
 // SalesInvoiceCreateRequest 销售发票创建请求
 type SalesInvoiceCreateRequest struct {
+	CompanyID         uint                        `json:"company_id,omitempty"`
 	CustomerID        uint                        `json:"customer_id" validate:"required"`
 	SalesOrderID      *uint                       `json:"sales_order_id,omitempty"`
 	DeliveryNoteID    *uint                       `json:"delivery_note_id,omitempty"`
@@ -62,27 +65,56 @@ type SalesInvoiceUpdateRequest struct {
 
 // SalesInvoiceItemRequest 销售发票明细请求
 type SalesInvoiceItemRequest struct {
-	SalesOrderItemID   *uint   `json:"sales_order_item_id,omitempty"`
-	DeliveryNoteItemID *uint   `json:"delivery_note_item_id,omitempty"`
-	ItemID             uint    `json:"item_id" validate:"required"`
-	Description        string  `json:"description,omitempty"`
-	Quantity           float64 `json:"quantity" validate:"required,gt=0"`
-	UOM                string  `json:"uom,omitempty"`
-	Rate               float64 `json:"rate" validate:"required,gte=0"`
-	Amount             float64 `json:"amount" validate:"required,gte=0"`
-	DiscountPercentage float64 `json:"discount_percentage" validate:"min=0,max=100"`
-	TaxCategory        string  `json:"tax_category,omitempty"`
-	TaxRate            float64 `json:"tax_rate" validate:"min=0,max=100"`
-	WarehouseID        *uint   `json:"warehouse_id,omitempty"`
-	BatchNo            string  `json:"batch_no,omitempty"`
-	SerialNo           string  `json:"serial_no,omitempty"`
-	CostCenter         string  `json:"cost_center,omitempty"`
-	Project            string  `json:"project,omitempty"`
+	SalesOrderItemID   *uint        `json:"sales_order_item_id,omitempty"`
+	DeliveryNoteItemID *uint        `json:"delivery_note_item_id,omitempty"`
+	ItemID             uint         `json:"item_id" validate:"required"`
+	Description        string       `json:"description,omitempty"`
+	Quantity           float64      `json:"quantity" validate:"required,gt=0"`
+	UOM                string       `json:"uom,omitempty"`
+	Rate               models.Money `json:"rate" validate:"required,gte=0"`
+	Amount             models.Money `json:"amount" validate:"required,gte=0"`
+	DiscountPercentage float64      `json:"discount_percentage" validate:"min=0,max=100"`
+	TaxCategory        string       `json:"tax_category,omitempty"`
+	TaxRate            float64      `json:"tax_rate" validate:"min=0,max=100"`
+	WarehouseID        *uint        `json:"warehouse_id,omitempty"`
+	BatchNo            string       `json:"batch_no,omitempty"`
+	SerialNo           string       `json:"serial_no,omitempty"`
+	CostCenter         string       `json:"cost_center,omitempty"`
+	Project            string       `json:"project,omitempty"`
+}
+
+// SalesInvoiceFromSalesOrderRequest 按销售订单开具销售发票请求；不选择明细时开具全部未开票数量
+type SalesInvoiceFromSalesOrderRequest struct {
+	SalesOrderID     uint                            `json:"sales_order_id" validate:"required"`
+	InvoiceDate      *time.Time                      `json:"invoice_date,omitempty"`                                  // 默认当天
+	PostingDate      *time.Time                      `json:"posting_date,omitempty"`                                  // 默认为开票日期
+	DueDate          *time.Time                      `json:"due_date,omitempty"`                                      // 默认按付款期限天数推算
+	PaymentTermsDays *int                            `json:"payment_terms_days,omitempty" validate:"omitempty,min=0"` // 默认 30 天
+	Notes            string                          `json:"notes,omitempty"`
+	Items            []SalesInvoiceSourceItemRequest `json:"items,omitempty" validate:"omitempty,dive"`
+}
+
+// SalesInvoiceFromDeliveryNoteRequest 按送货单开具销售发票请求；多张送货单须属于同一客户，合并开具一张发票
+type SalesInvoiceFromDeliveryNoteRequest struct {
+	DeliveryNoteIDs  []uint                          `json:"delivery_note_ids" validate:"required,min=1"`
+	InvoiceDate      *time.Time                      `json:"invoice_date,omitempty"`                                  // 默认当天
+	PostingDate      *time.Time                      `json:"posting_date,omitempty"`                                  // 默认为开票日期
+	DueDate          *time.Time                      `json:"due_date,omitempty"`                                      // 默认按付款期限天数推算
+	PaymentTermsDays *int                            `json:"payment_terms_days,omitempty" validate:"omitempty,min=0"` // 默认 30 天
+	Notes            string                          `json:"notes,omitempty"`
+	Items            []SalesInvoiceSourceItemRequest `json:"items,omitempty" validate:"omitempty,dive"`
+}
+
+// SalesInvoiceSourceItemRequest 开票的来源明细与数量，来源明细为销售订单明细或送货单明细
+type SalesInvoiceSourceItemRequest struct {
+	SourceItemID uint    `json:"source_item_id" validate:"required"`
+	Quantity     float64 `json:"quantity,omitempty" validate:"omitempty,gt=0"` // 为空时开具该行全部未开票数量
 }
 
 // SalesInvoiceResponse 销售发票响应
 type SalesInvoiceResponse struct {
 	ID                uint                       `json:"id"`
+	CompanyID         uint                       `json:"company_id,omitempty"`
 	InvoiceNumber     string                     `json:"invoice_number"`
 	CustomerID        uint                       `json:"customer_id"`
 	SalesOrderID      *uint                      `json:"sales_order_id,omitempty"`
@@ -94,13 +126,13 @@ type SalesInvoiceResponse struct {
 	PaymentStatus     string                     `json:"payment_status"`
 	Currency          string                     `json:"currency"`
 	ExchangeRate      float64                    `json:"exchange_rate"`
-	SubTotal          float64                    `json:"sub_total"`
-	DiscountAmount    float64                    `json:"discount_amount"`
-	TaxAmount         float64                    `json:"tax_amount"`
-	ShippingAmount    float64                    `json:"shipping_amount"`
-	GrandTotal        float64                    `json:"grand_total"`
-	OutstandingAmount float64                    `json:"outstanding_amount"`
-	PaidAmount        float64                    `json:"paid_amount"`
+	SubTotal          models.Money               `json:"sub_total"`
+	DiscountAmount    models.Money               `json:"discount_amount"`
+	TaxAmount         models.Money               `json:"tax_amount"`
+	ShippingAmount    models.Money               `json:"shipping_amount"`
+	GrandTotal        models.Money               `json:"grand_total"`
+	OutstandingAmount models.Money               `json:"outstanding_amount"`
+	PaidAmount        models.Money               `json:"paid_amount"`
 	BillingAddress    string                     `json:"billing_address,omitempty"`
 	ShippingAddress   string                     `json:"shipping_address,omitempty"`
 	PaymentTerms      string                     `json:"payment_terms,omitempty"`
@@ -140,16 +172,16 @@ type SalesInvoiceItemResponse struct {
 	UOM                string             `json:"uom"`
 	ConversionFactor   float64            `json:"conversion_factor"`
 	StockUOM           string             `json:"stock_uom,omitempty"`
-	Rate               float64            `json:"rate"`
-	PriceListRate      float64            `json:"price_list_rate"`
-	Amount             float64            `json:"amount"`
+	Rate               models.Money       `json:"rate"`
+	PriceListRate      models.Money       `json:"price_list_rate"`
+	Amount             models.Money       `json:"amount"`
 	DiscountPercentage float64            `json:"discount_percentage"`
-	DiscountAmount     float64            `json:"discount_amount"`
+	DiscountAmount     models.Money       `json:"discount_amount"`
 	TaxCategory        string             `json:"tax_category,omitempty"`
 	TaxRate            float64            `json:"tax_rate"`
-	TaxAmount          float64            `json:"tax_amount"`
-	NetRate            float64            `json:"net_rate"`
-	NetAmount          float64            `json:"net_amount"`
+	TaxAmount          models.Money       `json:"tax_amount"`
+	NetRate            models.Money       `json:"net_rate"`
+	NetAmount          models.Money       `json:"net_amount"`
 	WarehouseID        *uint              `json:"warehouse_id,omitempty"`
 	BatchNo            string             `json:"batch_no,omitempty"`
 	SerialNo           string             `json:"serial_no,omitempty"`
@@ -166,7 +198,7 @@ type InvoicePaymentResponse struct {
 	PaymentEntryID  *uint                `json:"payment_entry_id,omitempty"`
 	PaymentDate     time.Time            `json:"payment_date"`
 	PaymentMethod   string               `json:"payment_method"`
-	Amount          float64              `json:"amount"`
+	Amount          models.Money         `json:"amount"`
 	Currency        string               `json:"currency"`
 	ExchangeRate    float64              `json:"exchange_rate"`
 	ReferenceNumber string               `json:"reference_number,omitempty"`
@@ -231,21 +263,21 @@ type SalesInvoiceStatusUpdateRequest struct {
 
 // SalesInvoicePaymentCreateRequest 销售发票付款创建请求
 type SalesInvoicePaymentCreateRequest struct {
-	SalesInvoiceID uint      `json:"sales_invoice_id" validate:"required"`
-	PaymentDate    time.Time `json:"payment_date" validate:"required"`
-	Amount         float64   `json:"amount" validate:"required,gt=0"`
-	PaymentMethod  string    `json:"payment_method" validate:"required,oneof=Cash Bank Transfer Credit Card Check"`
-	Reference      string    `json:"reference,omitempty"`
-	Notes          string    `json:"notes,omitempty"`
+	SalesInvoiceID uint         `json:"sales_invoice_id" validate:"required"`
+	PaymentDate    time.Time    `json:"payment_date" validate:"required"`
+	Amount         models.Money `json:"amount" validate:"required,gt=0"`
+	PaymentMethod  string       `json:"payment_method" validate:"required,oneof=Cash Bank Transfer Credit Card Check"`
+	Reference      string       `json:"reference,omitempty"`
+	Notes          string       `json:"notes,omitempty"`
 }
 
 // SalesInvoicePaymentUpdateRequest 销售发票付款更新请求
 type SalesInvoicePaymentUpdateRequest struct {
-	PaymentDate   *time.Time `json:"payment_date,omitempty"`
-	Amount        *float64   `json:"amount,omitempty" validate:"omitempty,gt=0"`
-	PaymentMethod string     `json:"payment_method,omitempty" validate:"omitempty,oneof=Cash Bank Transfer Credit Card Check"`
-	Reference     string     `json:"reference,omitempty"`
-	Notes         string     `json:"notes,omitempty"`
+	PaymentDate   *time.Time    `json:"payment_date,omitempty"`
+	Amount        *models.Money `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	PaymentMethod string        `json:"payment_method,omitempty" validate:"omitempty,oneof=Cash Bank Transfer Credit Card Check"`
+	Reference     string        `json:"reference,omitempty"`
+	Notes         string        `json:"notes,omitempty"`
 }
 
 // SalesInvoiceBatchCreateRequest 批量创建销售发票请求
@@ -263,36 +295,36 @@ type SalesInvoiceBatchCreateRequest struct {
 
 // InvoicePaymentCreateRequest 发票付款创建请求
 type InvoicePaymentCreateRequest struct {
-	PaymentDate     time.Time `json:"payment_date" validate:"required"`
-	PaymentMethod   string    `json:"payment_method" validate:"required"`
-	Amount          float64   `json:"amount" validate:"required,gt=0"`
-	Currency        string    `json:"currency" validate:"required"`
-	ExchangeRate    float64   `json:"exchange_rate" validate:"min=0"`
-	ReferenceNumber string    `json:"reference_number,omitempty"`
-	BankAccountID   *uint     `json:"bank_account_id,omitempty"`
-	Notes           string    `json:"notes,omitempty"`
+	PaymentDate     time.Time    `json:"payment_date" validate:"required"`
+	PaymentMethod   string       `json:"payment_method" validate:"required"`
+	Amount          models.Money `json:"amount" validate:"required,gt=0"`
+	Currency        string       `json:"currency" validate:"required"`
+	ExchangeRate    float64      `json:"exchange_rate" validate:"min=0"`
+	ReferenceNumber string       `json:"reference_number,omitempty"`
+	BankAccountID   *uint        `json:"bank_account_id,omitempty"`
+	Notes           string       `json:"notes,omitempty"`
 }
 
 // PricingRuleCreateRequest 定价规则创建请求
 type PricingRuleCreateRequest struct {
-	RuleName           string    `json:"rule_name" validate:"required"`
-	RuleType           string    `json:"rule_type" validate:"required,oneof=Discount Price"`
-	ApplicableFor      string    `json:"applicable_for" validate:"required,oneof=Item ItemGroup Customer CustomerGroup"`
-	Priority           int       `json:"priority" validate:"min=1"`
-	ItemID             *uint     `json:"item_id,omitempty"`
-	ItemGroup          string    `json:"item_group,omitempty"`
-	CustomerID         *uint     `json:"customer_id,omitempty"`
-	CustomerGroup      string    `json:"customer_group,omitempty"`
-	Territory          string    `json:"territory,omitempty"`
-	MinQty             float64   `json:"min_qty" validate:"gte=0"`
-	MaxQty             float64   `json:"max_qty" validate:"gte=0"`
-	MinAmount          float64   `json:"min_amount" validate:"gte=0"`
-	MaxAmount          float64   `json:"max_amount" validate:"gte=0"`
-	Rate               float64   `json:"rate" validate:"gte=0"`
-	DiscountPercentage float64   `json:"discount_percentage" validate:"gte=0,lte=100"`
-	DiscountAmount     float64   `json:"discount_amount" validate:"gte=0"`
-	ValidFrom          time.Time `json:"valid_from" validate:"required"`
-	ValidUpto          time.Time `json:"valid_upto" validate:"required"`
+	RuleName           string       `json:"rule_name" validate:"required"`
+	RuleType           string       `json:"rule_type" validate:"required,oneof=Discount Price"`
+	ApplicableFor      string       `json:"applicable_for" validate:"required,oneof=Item ItemGroup Customer CustomerGroup"`
+	Priority           int          `json:"priority" validate:"min=1"`
+	ItemID             *uint        `json:"item_id,omitempty"`
+	ItemGroup          string       `json:"item_group,omitempty"`
+	CustomerID         *uint        `json:"customer_id,omitempty"`
+	CustomerGroup      string       `json:"customer_group,omitempty"`
+	Territory          string       `json:"territory,omitempty"`
+	MinQty             float64      `json:"min_qty" validate:"gte=0"`
+	MaxQty             float64      `json:"max_qty" validate:"gte=0"`
+	MinAmount          models.Money `json:"min_amount" validate:"gte=0"`
+	MaxAmount          models.Money `json:"max_amount" validate:"gte=0"`
+	Rate               models.Money `json:"rate" validate:"gte=0"`
+	DiscountPercentage float64      `json:"discount_percentage" validate:"gte=0,lte=100"`
+	DiscountAmount     models.Money `json:"discount_amount" validate:"gte=0"`
+	ValidFrom          time.Time    `json:"valid_from" validate:"required"`
+	ValidUpto          time.Time    `json:"valid_upto" validate:"required"`
 }
 
 // PricingRuleResponse 定价规则响应
@@ -309,11 +341,11 @@ type PricingRuleResponse struct {
 	Territory          string            `json:"territory,omitempty"`
 	MinQty             float64           `json:"min_qty"`
 	MaxQty             float64           `json:"max_qty"`
-	MinAmount          float64           `json:"min_amount"`
-	MaxAmount          float64           `json:"max_amount"`
-	Rate               float64           `json:"rate"`
+	MinAmount          models.Money      `json:"min_amount"`
+	MaxAmount          models.Money      `json:"max_amount"`
+	Rate               models.Money      `json:"rate"`
 	DiscountPercentage float64           `json:"discount_percentage"`
-	DiscountAmount     float64           `json:"discount_amount"`
+	DiscountAmount     models.Money      `json:"discount_amount"`
 	ValidFrom          time.Time         `json:"valid_from"`
 	ValidUpto          time.Time         `json:"valid_upto"`
 	IsActive           bool              `json:"is_active"`
@@ -325,15 +357,15 @@ type PricingRuleResponse struct {
 
 // SalesInvoiceStatistics 销售发票统计
 type SalesInvoiceStatistics struct {
-	TotalInvoices     int64   `json:"total_invoices"`
-	DraftInvoices     int64   `json:"draft_invoices"`
-	SubmittedInvoices int64   `json:"submitted_invoices"`
-	PaidInvoices      int64   `json:"paid_invoices"`
-	UnpaidInvoices    int64   `json:"unpaid_invoices"`
-	OverdueInvoices   int64   `json:"overdue_invoices"`
-	TotalAmount       float64 `json:"total_amount"`
-	PaidAmount        float64 `json:"paid_amount"`
-	OutstandingAmount float64 `json:"outstanding_amount"`
+	TotalInvoices     int64        `json:"total_invoices"`
+	DraftInvoices     int64        `json:"draft_invoices"`
+	SubmittedInvoices int64        `json:"submitted_invoices"`
+	PaidInvoices      int64        `json:"paid_invoices"`
+	UnpaidInvoices    int64        `json:"unpaid_invoices"`
+	OverdueInvoices   int64        `json:"overdue_invoices"`
+	TotalAmount       models.Money `json:"total_amount"`
+	PaidAmount        models.Money `json:"paid_amount"`
+	OutstandingAmount models.Money `json:"outstanding_amount"`
 }
 
 // SalesInvoiceReportRequest 销售发票报告请求
@@ -0,0 +1,172 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// StockCountCreateRequest 生成盘点单请求：整仓、按库区或按 ABC 分类生成盘点行并冻结账面数量
+type StockCountCreateRequest struct {
+	CompanyID                uint          `json:"company_id,omitempty"`
+	WarehouseID              uint          `json:"warehouse_id" validate:"required"`
+	CountType                string        `json:"count_type" validate:"required,oneof=full zone abc"`
+	Zone                     string        `json:"zone,omitempty" validate:"max=50"`                     // 按库区盘点时必填
+	ABCClass                 string        `json:"abc_class,omitempty" validate:"omitempty,oneof=A B C"` // 按 ABC 分类盘点时必填
+	ItemIDs                  []uint        `json:"item_ids,omitempty"`                                   // 进一步限定物料
+	IncludeZero              bool          `json:"include_zero,omitempty"`                               // 是否包含账面数量为 0 的库存
+	CountDate                *time.Time    `json:"count_date,omitempty"`                                 // 过账日期，默认今天
+	ApprovalValueThreshold   *models.Money `json:"approval_value_threshold,omitempty" validate:"omitempty,min=0"`
+	ApprovalPercentThreshold *float64      `json:"approval_percent_threshold,omitempty" validate:"omitempty,min=0"`
+	Notes                    string        `json:"notes,omitempty"`
+}
+
+// StockCountListRequest 盘点单列表请求
+type StockCountListRequest struct {
+	PaginationRequest
+	WarehouseID uint   `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	CountType   string `json:"count_type,omitempty" form:"count_type" validate:"omitempty,oneof=full zone abc cycle"`
+	Status      string `json:"status,omitempty" form:"status" validate:"omitempty,oneof=counting pending_approval approved posted cancelled"`
+}
+
+// StockCountEntryLine 实盘数量录入：指定盘点行ID，或按物料、库位与批次匹配盘点行，匹配不到时追加账外盘点行
+type StockCountEntryLine struct {
+	LineID     uint    `json:"line_id,omitempty"`
+	ItemID     uint    `json:"item_id,omitempty"`
+	LocationID *uint   `json:"location_id,omitempty"`
+	BatchNo    string  `json:"batch_no,omitempty"`
+	CountedQty float64 `json:"counted_qty" validate:"min=0"`
+	SerialNo   string  `json:"serial_no,omitempty"` // 序列号管理物料的盘盈或盘亏序列号
+	Notes      string  `json:"notes,omitempty"`
+}
+
+// StockCountEntryRequest 录入实盘数量请求
+type StockCountEntryRequest struct {
+	Lines []StockCountEntryLine `json:"lines" validate:"required,min=1,dive"`
+}
+
+// StockCountScanRequest 扫描数据上传参数；add 模式按扫描记录累加数量，replace 模式以上传数量覆盖实盘数量
+type StockCountScanRequest struct {
+	Format string `json:"format" form:"format" validate:"omitempty,oneof=csv json"`
+	Mode   string `json:"mode" form:"mode" validate:"omitempty,oneof=add replace"`
+}
+
+// StockCountScanRow 扫描记录，数量为空时按 1 计
+type StockCountScanRow struct {
+	ItemCode     string   `json:"item_code"`
+	LocationCode string   `json:"location_code,omitempty"`
+	BatchNo      string   `json:"batch_no,omitempty"`
+	Quantity     *float64 `json:"quantity,omitempty"`
+}
+
+// StockCountScanResult 扫描数据处理结果
+type StockCountScanResult struct {
+	Rows     int      `json:"rows"`
+	Applied  int      `json:"applied"`
+	NewLines int      `json:"new_lines"` // 追加的账外盘点行
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// StockCountApprovalRequest 审批或驳回盘点差异请求
+type StockCountApprovalRequest struct {
+	Notes string `json:"notes,omitempty"`
+}
+
+// StockCountPostRequest 盘点差异过账请求，科目编码默认为库存商品与待处理财产损溢
+type StockCountPostRequest struct {
+	InventoryAccountCode  string `json:"inventory_account_code,omitempty"`
+	DifferenceAccountCode string `json:"difference_account_code,omitempty"`
+}
+
+// StockCountLineResponse 盘点行响应
+type StockCountLineResponse struct {
+	ID            uint         `json:"id"`
+	ItemID        uint         `json:"item_id"`
+	ItemCode      string       `json:"item_code,omitempty"`
+	ItemName      string       `json:"item_name,omitempty"`
+	Unit          string       `json:"unit,omitempty"`
+	LocationID    *uint        `json:"location_id,omitempty"`
+	LocationCode  string       `json:"location_code,omitempty"`
+	BatchNo       string       `json:"batch_no,omitempty"`
+	ABCClass      string       `json:"abc_class,omitempty"`
+	ExpectedQty   float64      `json:"expected_qty"`
+	CountedQty    *float64     `json:"counted_qty,omitempty"`
+	VarianceQty   float64      `json:"variance_qty"`
+	ValuationRate models.Money `json:"valuation_rate"`
+	VarianceValue models.Money `json:"variance_value"`
+	SerialNo      string       `json:"serial_no,omitempty"`
+	CountedAt     *time.Time   `json:"counted_at,omitempty"`
+	MovementID    *uint        `json:"movement_id,omitempty"`
+	Notes         string       `json:"notes,omitempty"`
+}
+
+// StockCountResponse 盘点单响应
+type StockCountResponse struct {
+	ID                       uint                     `json:"id"`
+	CompanyID                uint                     `json:"company_id"`
+	CountNumber              string                   `json:"count_number"`
+	WarehouseID              uint                     `json:"warehouse_id"`
+	WarehouseCode            string                   `json:"warehouse_code,omitempty"`
+	CountType                string                   `json:"count_type"`
+	Zone                     string                   `json:"zone,omitempty"`
+	ABCClass                 string                   `json:"abc_class,omitempty"`
+	Status                   string                   `json:"status"`
+	SnapshotAt               time.Time                `json:"snapshot_at"`
+	CountDate                time.Time                `json:"count_date"`
+	ApprovalValueThreshold   models.Money             `json:"approval_value_threshold"`
+	ApprovalPercentThreshold float64                  `json:"approval_percent_threshold"`
+	ApprovalRequired         bool                     `json:"approval_required"`
+	TotalLines               int                      `json:"total_lines"`
+	CountedLines             int                      `json:"counted_lines"`
+	VarianceLines            int                      `json:"variance_lines"`
+	GainValue                models.Money             `json:"gain_value"` // 过账前为按冻结单价估算的金额
+	LossValue                models.Money             `json:"loss_value"`
+	SubmittedAt              *time.Time               `json:"submitted_at,omitempty"`
+	ApprovedBy               *uint                    `json:"approved_by,omitempty"`
+	ApprovedAt               *time.Time               `json:"approved_at,omitempty"`
+	PostedAt                 *time.Time               `json:"posted_at,omitempty"`
+	TransactionID            *uint                    `json:"transaction_id,omitempty"`
+	Notes                    string                   `json:"notes,omitempty"`
+	Lines                    []StockCountLineResponse `json:"lines,omitempty"`
+	CreatedAt                time.Time                `json:"created_at"`
+	UpdatedAt                time.Time                `json:"updated_at"`
+}
+
+// CycleCountRequest 循环盘点请求：按 ABC 分类的盘点周期（天）选出到期未盘点的物料，
+// 每个仓库生成一张循环盘点单；周期为 0 时使用默认值，仓库ID 为 0 表示全部启用的仓库
+type CycleCountRequest struct {
+	WarehouseID uint       `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	ADays       int        `json:"a_days,omitempty" form:"a_days" validate:"omitempty,min=1"`
+	BDays       int        `json:"b_days,omitempty" form:"b_days" validate:"omitempty,min=1"`
+	CDays       int        `json:"c_days,omitempty" form:"c_days" validate:"omitempty,min=1"`
+	MaxItems    int        `json:"max_items,omitempty" form:"max_items" validate:"omitempty,min=1"` // 每张盘点单最多物料数
+	CountDate   *time.Time `json:"count_date,omitempty"`
+}
+
+// CycleCountDueItem 到期应盘点的物料
+type CycleCountDueItem struct {
+	ItemID        uint       `json:"item_id"`
+	ItemCode      string     `json:"item_code"`
+	ItemName      string     `json:"item_name"`
+	ABCClass      string     `json:"abc_class"`
+	LastCountedAt *time.Time `json:"last_counted_at,omitempty"` // 为空表示从未盘点
+	OverdueDays   int        `json:"overdue_days"`
+}
+
+// CycleCountWarehousePlan 仓库的循环盘点计划
+type CycleCountWarehousePlan struct {
+	WarehouseID   uint                `json:"warehouse_id"`
+	WarehouseCode string              `json:"warehouse_code"`
+	Items         []CycleCountDueItem `json:"items"`
+	StockCountID  *uint               `json:"stock_count_id,omitempty"` // 生成的循环盘点单
+	CountNumber   string              `json:"count_number,omitempty"`
+}
+
+// CycleCountResponse 循环盘点计划或生成结果
+type CycleCountResponse struct {
+	ADays      int                       `json:"a_days"`
+	BDays      int                       `json:"b_days"`
+	CDays      int                       `json:"c_days"`
+	Warehouses []CycleCountWarehousePlan `json:"warehouses"`
+	Created    int                       `json:"created"`
+}
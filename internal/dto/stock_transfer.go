@@ -0,0 +1,98 @@
+package dto
+
+import "time"
+
+// StockTransferCreateRequest 创建库存调拨单请求
+type StockTransferCreateRequest struct {
+	FromWarehouseID uint                       `json:"from_warehouse_id" validate:"required"`
+	ToWarehouseID   uint                       `json:"to_warehouse_id" validate:"required,nefield=FromWarehouseID"`
+	FromLocationID  *uint                      `json:"from_location_id,omitempty"`
+	ToLocationID    *uint                      `json:"to_location_id,omitempty"`
+	TransferDate    *time.Time                 `json:"transfer_date,omitempty"`
+	Notes           string                     `json:"notes,omitempty"`
+	Items           []StockTransferItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// StockTransferItemRequest 调拨明细请求，库位为空时使用单据头库位
+type StockTransferItemRequest struct {
+	ItemID         uint    `json:"item_id" validate:"required"`
+	FromLocationID *uint   `json:"from_location_id,omitempty"`
+	ToLocationID   *uint   `json:"to_location_id,omitempty"`
+	Quantity       float64 `json:"quantity" validate:"required,gt=0"`
+	UOM            string  `json:"uom,omitempty" validate:"max=50"`       // 数量的单位，为空时为库存单位
+	BatchNo        string  `json:"batch_no,omitempty" validate:"max=100"` // 批次管理物料必填
+	SerialNo       string  `json:"serial_no,omitempty"`                   // 序列号管理物料必填，逗号分隔
+	Notes          string  `json:"notes,omitempty"`
+}
+
+// StockTransferReceiveRequest 调拨收货请求，支持分批收货；
+// Close 为 true 时结束收货，剩余在途数量按 Resolution 报损或退回调出仓
+type StockTransferReceiveRequest struct {
+	ReceivedAt *time.Time                        `json:"received_at,omitempty"`
+	Items      []StockTransferReceiveItemRequest `json:"items" validate:"dive"`
+	Close      bool                              `json:"close"`
+	Resolution string                            `json:"resolution,omitempty" validate:"omitempty,oneof=write_off return"`
+	Reason     string                            `json:"reason,omitempty" validate:"max=255"`
+}
+
+// StockTransferReceiveItemRequest 调拨收货明细，数量超过在途数量的部分按溢收计入调入仓
+type StockTransferReceiveItemRequest struct {
+	LineID   uint    `json:"line_id" validate:"required"`
+	Quantity float64 `json:"quantity" validate:"gte=0"`
+	SerialNo string  `json:"serial_no,omitempty"` // 序列号管理物料本次收到的序列号，为空时按发出顺序收货
+	Reason   string  `json:"reason,omitempty" validate:"max=255"`
+}
+
+// StockTransferListRequest 调拨单列表请求
+type StockTransferListRequest struct {
+	PaginationRequest
+	Status          string `json:"status,omitempty" form:"status"`
+	FromWarehouseID uint   `json:"from_warehouse_id,omitempty" form:"from_warehouse_id"`
+	ToWarehouseID   uint   `json:"to_warehouse_id,omitempty" form:"to_warehouse_id"`
+}
+
+// StockTransferItemResponse 调拨明细响应
+type StockTransferItemResponse struct {
+	ID                    uint    `json:"id"`
+	ItemID                uint    `json:"item_id"`
+	ItemCode              string  `json:"item_code,omitempty"`
+	ItemName              string  `json:"item_name,omitempty"`
+	FromLocationID        *uint   `json:"from_location_id,omitempty"`
+	ToLocationID          *uint   `json:"to_location_id,omitempty"`
+	Quantity              float64 `json:"quantity"`
+	UOM                   string  `json:"uom,omitempty"`
+	UOMQuantity           float64 `json:"uom_quantity,omitempty"`
+	ConversionFactor      float64 `json:"conversion_factor,omitempty"`
+	ShippedQty            float64 `json:"shipped_qty"`
+	ReceivedQty           float64 `json:"received_qty"`
+	InTransitQty          float64 `json:"in_transit_qty"`
+	DiscrepancyQty        float64 `json:"discrepancy_qty"`
+	DiscrepancyResolution string  `json:"discrepancy_resolution,omitempty"`
+	DiscrepancyReason     string  `json:"discrepancy_reason,omitempty"`
+	BatchNo               string  `json:"batch_no,omitempty"`
+	SerialNo              string  `json:"serial_no,omitempty"`
+	ReceivedSerialNo      string  `json:"received_serial_no,omitempty"`
+	Notes                 string  `json:"notes,omitempty"`
+}
+
+// StockTransferResponse 调拨单响应
+type StockTransferResponse struct {
+	ID                 uint                        `json:"id"`
+	TransferNumber     string                      `json:"transfer_number"`
+	FromWarehouseID    uint                        `json:"from_warehouse_id"`
+	FromWarehouseName  string                      `json:"from_warehouse_name,omitempty"`
+	ToWarehouseID      uint                        `json:"to_warehouse_id"`
+	ToWarehouseName    string                      `json:"to_warehouse_name,omitempty"`
+	FromLocationID     *uint                       `json:"from_location_id,omitempty"`
+	ToLocationID       *uint                       `json:"to_location_id,omitempty"`
+	TransitWarehouseID *uint                       `json:"transit_warehouse_id,omitempty"`
+	TransferDate       time.Time                   `json:"transfer_date"`
+	ShippedAt          *time.Time                  `json:"shipped_at,omitempty"`
+	ReceivedAt         *time.Time                  `json:"received_at,omitempty"`
+	Status             string                      `json:"status"`
+	HasDiscrepancy     bool                        `json:"has_discrepancy"`
+	Notes              string                      `json:"notes,omitempty"`
+	Items              []StockTransferItemResponse `json:"items"`
+	CreatedAt          time.Time                   `json:"created_at"`
+	UpdatedAt          time.Time                   `json:"updated_at"`
+}
@@ -0,0 +1,111 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// StockValuationRequest 库存估值查询请求，as_of 为空时按当前台账估值
+type StockValuationRequest struct {
+	AsOf        time.Time `json:"as_of" form:"as_of" time_format:"2006-01-02"`
+	ItemID      uint      `json:"item_id,omitempty" form:"item_id"`
+	WarehouseID uint      `json:"warehouse_id,omitempty" form:"warehouse_id"`
+	Category    string    `json:"category,omitempty" form:"category"`
+}
+
+// StockValuationLine 物料在仓库的库存估值
+type StockValuationLine struct {
+	ItemID          uint         `json:"item_id"`
+	ItemCode        string       `json:"item_code"`
+	ItemName        string       `json:"item_name"`
+	Category        string       `json:"category,omitempty"`
+	ValuationMethod string       `json:"valuation_method"`
+	WarehouseID     uint         `json:"warehouse_id"`
+	WarehouseCode   string       `json:"warehouse_code"`
+	WarehouseName   string       `json:"warehouse_name"`
+	Quantity        float64      `json:"quantity"`
+	ValuationRate   models.Money `json:"valuation_rate"`
+	StockValue      models.Money `json:"stock_value"`
+}
+
+// StockValuationResponse 库存估值响应
+type StockValuationResponse struct {
+	AsOf          *time.Time           `json:"as_of,omitempty"`
+	Lines         []StockValuationLine `json:"lines"`
+	TotalQuantity float64              `json:"total_quantity"`
+	TotalValue    models.Money         `json:"total_value"`
+}
+
+// StockValuationReportRequest 库存估值报表请求；account_codes 为逗号分隔的存货科目编码，
+// 为空时使用库存商品科目，报表金额与这些科目在截止日的总账余额核对
+type StockValuationReportRequest struct {
+	StockValuationRequest
+	CompanyID    uint   `json:"company_id,omitempty" form:"company_id"`
+	AccountCodes string `json:"account_codes,omitempty" form:"account_codes"`
+}
+
+// StockValuationGroup 按仓库和物料类别汇总的库存估值
+type StockValuationGroup struct {
+	WarehouseID   uint         `json:"warehouse_id"`
+	WarehouseCode string       `json:"warehouse_code"`
+	WarehouseName string       `json:"warehouse_name"`
+	Category      string       `json:"category"`
+	ItemCount     int          `json:"item_count"`
+	Quantity      float64      `json:"quantity"`
+	StockValue    models.Money `json:"stock_value"`
+}
+
+// StockValuationTotal 单一维度的库存估值合计
+type StockValuationTotal struct {
+	Key        string       `json:"key"`
+	Name       string       `json:"name,omitempty"`
+	Quantity   float64      `json:"quantity"`
+	StockValue models.Money `json:"stock_value"`
+}
+
+// StockValuationReportResponse 库存估值报表响应
+type StockValuationReportResponse struct {
+	AsOf            *time.Time            `json:"as_of,omitempty"`
+	CompanyID       uint                  `json:"company_id"`
+	Groups          []StockValuationGroup `json:"groups"`
+	WarehouseTotals []StockValuationTotal `json:"warehouse_totals"`
+	CategoryTotals  []StockValuationTotal `json:"category_totals"`
+	TotalValue      models.Money          `json:"total_value"`
+	AccountCodes    []string              `json:"account_codes"`
+	GLBalance       models.Money          `json:"gl_balance"`
+	Difference      models.Money          `json:"difference"` // 库存金额减总账余额
+	IsReconciled    bool                  `json:"is_reconciled"`
+}
+
+// CostOfGoodsSoldRequest 发货成本查询请求
+type CostOfGoodsSoldRequest struct {
+	DeliveryNoteID uint      `json:"delivery_note_id,omitempty" form:"delivery_note_id"`
+	StartDate      time.Time `json:"start_date" form:"start_date" time_format:"2006-01-02"`
+	EndDate        time.Time `json:"end_date" form:"end_date" time_format:"2006-01-02"`
+}
+
+// CostOfGoodsSoldLine 送货单物料的发货成本
+type CostOfGoodsSoldLine struct {
+	ItemID      uint         `json:"item_id"`
+	ItemCode    string       `json:"item_code"`
+	ItemName    string       `json:"item_name"`
+	WarehouseID uint         `json:"warehouse_id"`
+	Quantity    float64      `json:"quantity"`
+	UnitCost    models.Money `json:"unit_cost"`
+	TotalCost   models.Money `json:"total_cost"`
+}
+
+// DeliveryCostOfGoodsSold 单张送货单的发货成本
+type DeliveryCostOfGoodsSold struct {
+	DeliveryNoteID uint                  `json:"delivery_note_id"`
+	DeliveryNumber string                `json:"delivery_number,omitempty"`
+	Lines          []CostOfGoodsSoldLine `json:"lines"`
+	TotalCost      models.Money          `json:"total_cost"`
+}
+
+// CostOfGoodsSoldResponse 发货成本响应
+type CostOfGoodsSoldResponse struct {
+	Deliveries []DeliveryCostOfGoodsSold `json:"deliveries"`
+	TotalCost  models.Money              `json:"total_cost"`
+}
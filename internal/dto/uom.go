@@ -0,0 +1,103 @@
+package dto
+
+import "time"
+
+// UOMCategoryCreateRequest 单位类别创建请求
+type UOMCategoryCreateRequest struct {
+	Code        string `json:"code" validate:"required,max=50"`
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description,omitempty"`
+}
+
+// UOMCategoryUpdateRequest 单位类别更新请求
+type UOMCategoryUpdateRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,max=100"`
+	Description *string `json:"description,omitempty"`
+}
+
+// UOMCategoryResponse 单位类别响应
+type UOMCategoryResponse struct {
+	ID          uint      `json:"id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UOMCreateRequest 计量单位创建请求
+type UOMCreateRequest struct {
+	Code        string   `json:"code" validate:"required,max=50"`
+	Name        string   `json:"name" validate:"required,max=100"`
+	CategoryID  uint     `json:"category_id" validate:"required"`
+	BaseFactor  *float64 `json:"base_factor,omitempty" validate:"omitempty,gt=0"`               // 折合类别基准单位的数量，默认 1
+	Precision   int      `json:"precision,omitempty" validate:"min=0,max=6"`                    // 数量保留的小数位数
+	Rounding    string   `json:"rounding,omitempty" validate:"omitempty,oneof=half_up up down"` // 默认 half_up
+	Description string   `json:"description,omitempty"`
+}
+
+// UOMUpdateRequest 计量单位更新请求，单位编码与类别不能修改
+type UOMUpdateRequest struct {
+	Name        *string  `json:"name,omitempty" validate:"omitempty,max=100"`
+	BaseFactor  *float64 `json:"base_factor,omitempty" validate:"omitempty,gt=0"`
+	Precision   *int     `json:"precision,omitempty" validate:"omitempty,min=0,max=6"`
+	Rounding    *string  `json:"rounding,omitempty" validate:"omitempty,oneof=half_up up down"`
+	Description *string  `json:"description,omitempty"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+// UOMListRequest 计量单位列表请求
+type UOMListRequest struct {
+	CategoryID uint `json:"category_id,omitempty" form:"category_id"`
+	ActiveOnly bool `json:"active_only,omitempty" form:"active_only"`
+}
+
+// UOMResponse 计量单位响应
+type UOMResponse struct {
+	ID           uint      `json:"id"`
+	Code         string    `json:"code"`
+	Name         string    `json:"name"`
+	CategoryID   uint      `json:"category_id"`
+	CategoryCode string    `json:"category_code,omitempty"`
+	BaseFactor   float64   `json:"base_factor"`
+	Precision    int       `json:"precision"`
+	Rounding     string    `json:"rounding"`
+	Description  string    `json:"description,omitempty"`
+	IsActive     bool      `json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ItemUOMConversionRequest 物料单位换算请求，同一物料同一单位重复提交时更新换算系数
+type ItemUOMConversionRequest struct {
+	UOM    string  `json:"uom" validate:"required,max=50"`
+	Factor float64 `json:"factor" validate:"required,gt=0"` // 1 个该单位折合的库存单位数量
+}
+
+// ItemUOMConversionResponse 物料单位换算响应
+type ItemUOMConversionResponse struct {
+	ID       uint    `json:"id"`
+	ItemID   uint    `json:"item_id"`
+	UOMID    uint    `json:"uom_id"`
+	UOM      string  `json:"uom"`
+	UOMName  string  `json:"uom_name,omitempty"`
+	Factor   float64 `json:"factor"`
+	StockUOM string  `json:"stock_uom"`
+}
+
+// UOMConvertRequest 单位换算查询请求
+type UOMConvertRequest struct {
+	ItemID   uint    `json:"item_id" form:"item_id" validate:"required"`
+	UOM      string  `json:"uom" form:"uom" validate:"required"`
+	Quantity float64 `json:"quantity" form:"quantity" validate:"gt=0"`
+}
+
+// UOMConversionResult 换算为库存单位的结果
+type UOMConversionResult struct {
+	ItemID           uint    `json:"item_id"`
+	UOM              string  `json:"uom"`
+	Quantity         float64 `json:"quantity"` // 按单据单位舍入后的数量
+	ConversionFactor float64 `json:"conversion_factor"`
+	StockUOM         string  `json:"stock_uom"`
+	StockQuantity    float64 `json:"stock_quantity"` // 按库存单位舍入后的数量
+}
@@ -7,16 +7,18 @@ import (
 // Account 会计科目模型
 type Account struct {
 	BaseModel
-	Code        string  `json:"code" gorm:"uniqueIndex;not null"`
-	Name        string  `json:"name" gorm:"not null"`
-	Description string  `json:"description,omitempty"`
-	AccountType string  `json:"account_type" gorm:"not null"`
-	Balance     float64 `json:"balance" gorm:"default:0"`
-	IsActive    bool    `json:"is_active" gorm:"default:true"`
-	ParentID    *uint   `json:"parent_id,omitempty"`
-	Currency    string  `json:"currency" gorm:"default:'USD'"`
+	CompanyID   uint   `json:"company_id" gorm:"uniqueIndex:idx_accounts_company_code;not null;default:1"`
+	Code        string `json:"code" gorm:"uniqueIndex:idx_accounts_company_code;not null"`
+	Name        string `json:"name" gorm:"not null"`
+	Description string `json:"description,omitempty"`
+	AccountType string `json:"account_type" gorm:"not null"`
+	Balance     Money  `json:"balance" gorm:"default:0"`
+	IsActive    bool   `json:"is_active" gorm:"default:true"`
+	ParentID    *uint  `json:"parent_id,omitempty"`
+	Currency    string `json:"currency" gorm:"default:'USD'"`
 
 	// 关联
+	Company  *Company  `json:"company,omitempty" gorm:"foreignKey:CompanyID"`
 	Parent   *Account  `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
 	Children []Account `json:"children,omitempty" gorm:"foreignKey:ParentID"`
 }
@@ -24,11 +26,13 @@ type Account struct {
 // JournalEntry 会计分录模型
 type JournalEntry struct {
 	BaseModel
-	TransactionID uint    `json:"transaction_id" gorm:"not null"`
-	AccountID     uint    `json:"account_id" gorm:"not null"`
-	Debit         float64 `json:"debit,omitempty"`
-	Credit        float64 `json:"credit,omitempty"`
-	Description   string  `json:"description,omitempty"`
+	CompanyID             uint   `json:"company_id" gorm:"index;not null;default:1"`
+	TransactionID         uint   `json:"transaction_id" gorm:"not null"`
+	AccountID             uint   `json:"account_id" gorm:"not null"`
+	Debit                 Money  `json:"debit,omitempty"`
+	Credit                Money  `json:"credit,omitempty"`
+	Description           string `json:"description,omitempty"`
+	IntercompanyCompanyID *uint  `json:"intercompany_company_id,omitempty" gorm:"index"` // 内部往来对方公司，合并时抵销
 
 	// 关联
 	Account Account `json:"account,omitempty" gorm:"foreignKey:AccountID"`
@@ -37,10 +41,11 @@ type JournalEntry struct {
 // Payment 付款模型
 type Payment struct {
 	AuditableModel
+	CompanyID     uint      `json:"company_id" gorm:"index;not null;default:1"`
 	PaymentNumber string    `json:"payment_number" gorm:"uniqueIndex;size:100;not null"`
 	PaymentDate   time.Time `json:"payment_date" gorm:"index;not null"`
 	PaymentType   string    `json:"payment_type" gorm:"size:50;not null;index"` // cash, bank, check, card
-	Amount        float64   `json:"amount" gorm:"not null"`
+	Amount        Money     `json:"amount" gorm:"not null"`
 	Currency      string    `json:"currency" gorm:"size:10;default:'CNY'"`
 	ExchangeRate  float64   `json:"exchange_rate" gorm:"default:1"`
 	PayerType     string    `json:"payer_type" gorm:"size:50;not null;index"` // customer, supplier, employee
@@ -60,16 +65,17 @@ type Payment struct {
 // BankAccount 银行账户模型
 type BankAccount struct {
 	BaseModel
-	AccountName   string  `json:"account_name" gorm:"not null"`
-	BankName      string  `json:"bank_name" gorm:"not null"`
-	AccountNumber string  `json:"account_number" gorm:"uniqueIndex;not null"`
-	IBAN          string  `json:"iban,omitempty"`
-	SwiftCode     string  `json:"swift_code,omitempty"`
-	Currency      string  `json:"currency" gorm:"default:'USD'"`
-	Balance       float64 `json:"balance" gorm:"default:0"`
-	IsDefault     bool    `json:"is_default" gorm:"default:false"`
-	IsActive      bool    `json:"is_active" gorm:"default:true"`
-	AccountID     *uint   `json:"account_id,omitempty"`
+	CompanyID     uint   `json:"company_id" gorm:"index;not null;default:1"`
+	AccountName   string `json:"account_name" gorm:"not null"`
+	BankName      string `json:"bank_name" gorm:"not null"`
+	AccountNumber string `json:"account_number" gorm:"uniqueIndex;not null"`
+	IBAN          string `json:"iban,omitempty"`
+	SwiftCode     string `json:"swift_code,omitempty"`
+	Currency      string `json:"currency" gorm:"default:'USD'"`
+	Balance       Money  `json:"balance" gorm:"default:0"`
+	IsDefault     bool   `json:"is_default" gorm:"default:false"`
+	IsActive      bool   `json:"is_active" gorm:"default:true"`
+	AccountID     *uint  `json:"account_id,omitempty"`
 
 	// 关联
 	Account  *Account  `json:"account,omitempty" gorm:"foreignKey:AccountID"`
@@ -79,13 +85,14 @@ type BankAccount struct {
 // Budget 预算模型
 type Budget struct {
 	AuditableModel
+	CompanyID       uint      `json:"company_id" gorm:"index;not null;default:1"`
 	BudgetName      string    `json:"budget_name" gorm:"size:255;not null"`
 	BudgetYear      int       `json:"budget_year" gorm:"index;not null"`
 	StartDate       time.Time `json:"start_date" gorm:"index;not null"`
 	EndDate         time.Time `json:"end_date" gorm:"index;not null"`
-	TotalAmount     float64   `json:"total_amount" gorm:"not null"`
-	UsedAmount      float64   `json:"used_amount" gorm:"default:0"`
-	RemainingAmount float64   `json:"remaining_amount" gorm:"default:0"`
+	TotalAmount     Money     `json:"total_amount" gorm:"not null"`
+	UsedAmount      Money     `json:"used_amount" gorm:"default:0"`
+	RemainingAmount Money     `json:"remaining_amount" gorm:"default:0"`
 	Status          string    `json:"status" gorm:"size:50;default:'draft';index"` // draft, approved, active, closed
 
 	// 关联
@@ -95,12 +102,12 @@ type Budget struct {
 // BudgetItem 预算明细模型
 type BudgetItem struct {
 	BaseModel
-	BudgetID       uint    `json:"budget_id" gorm:"index;not null"`
-	AccountID      uint    `json:"account_id" gorm:"index;not null"`
-	BudgetAmount   float64 `json:"budget_amount" gorm:"not null"`
-	ActualAmount   float64 `json:"actual_amount" gorm:"default:0"`
-	VarianceAmount float64 `json:"variance_amount" gorm:"default:0"`
-	Notes          string  `json:"notes,omitempty" gorm:"type:text"`
+	BudgetID       uint   `json:"budget_id" gorm:"index;not null"`
+	AccountID      uint   `json:"account_id" gorm:"index;not null"`
+	BudgetAmount   Money  `json:"budget_amount" gorm:"not null"`
+	ActualAmount   Money  `json:"actual_amount" gorm:"default:0"`
+	VarianceAmount Money  `json:"variance_amount" gorm:"default:0"`
+	Notes          string `json:"notes,omitempty" gorm:"type:text"`
 
 	// 关联
 	Budget  Budget  `json:"budget,omitempty" gorm:"foreignKey:BudgetID"`
@@ -147,7 +154,7 @@ type FinancialReportItem struct {
 	BaseModel
 	ReportID   uint    `json:"report_id" gorm:"index;not null"`
 	AccountID  uint    `json:"account_id" gorm:"index;not null"`
-	Amount     float64 `json:"amount" gorm:"not null"`
+	Amount     Money   `json:"amount" gorm:"not null"`
 	Percentage float64 `json:"percentage,omitempty" gorm:"default:0"`
 
 	// 关联
@@ -158,10 +165,11 @@ type FinancialReportItem struct {
 // Transaction 交易记录模型
 type Transaction struct {
 	AuditableModel
+	CompanyID         uint      `json:"company_id" gorm:"index;not null;default:1"`
 	TransactionNumber string    `json:"transaction_number" gorm:"uniqueIndex;size:100;not null"`
 	TransactionDate   time.Time `json:"transaction_date" gorm:"index;not null"`
 	TransactionType   string    `json:"transaction_type" gorm:"size:50;not null;index"` // income, expense, transfer
-	Amount            float64   `json:"amount" gorm:"not null"`
+	Amount            Money     `json:"amount" gorm:"not null"`
 	Currency          string    `json:"currency" gorm:"size:10;default:'CNY'"`
 	ExchangeRate      float64   `json:"exchange_rate" gorm:"default:1"`
 	Description       string    `json:"description" gorm:"type:text;not null"`
@@ -174,13 +182,14 @@ type Transaction struct {
 // Receivable 应收账款模型
 type Receivable struct {
 	BaseModel
+	CompanyID     uint      `json:"company_id" gorm:"index;not null;default:1"`
 	CustomerID    uint      `json:"customer_id" gorm:"not null"`
 	InvoiceDate   time.Time `json:"invoice_date" gorm:"not null"`
 	DueDate       time.Time `json:"due_date" gorm:"not null"`
 	InvoiceNumber string    `json:"invoice_number" gorm:"not null"`
 	Description   string    `json:"description,omitempty"`
-	Amount        float64   `json:"amount" gorm:"not null"`
-	AmountPaid    float64   `json:"amount_paid" gorm:"default:0"`
+	Amount        Money     `json:"amount" gorm:"not null"`
+	AmountPaid    Money     `json:"amount_paid" gorm:"default:0"`
 	Currency      string    `json:"currency" gorm:"default:'USD'"`
 	ExchangeRate  float64   `json:"exchange_rate" gorm:"default:1"`
 	Status        string    `json:"status" gorm:"default:'open'"`
@@ -192,13 +201,14 @@ type Receivable struct {
 // Payable 应付账款模型
 type Payable struct {
 	BaseModel
+	CompanyID     uint      `json:"company_id" gorm:"index;not null;default:1"`
 	SupplierID    uint      `json:"supplier_id" gorm:"not null"`
 	InvoiceDate   time.Time `json:"invoice_date" gorm:"not null"`
 	DueDate       time.Time `json:"due_date" gorm:"not null"`
 	InvoiceNumber string    `json:"invoice_number" gorm:"not null"`
 	Description   string    `json:"description,omitempty"`
-	Amount        float64   `json:"amount" gorm:"not null"`
-	AmountPaid    float64   `json:"amount_paid" gorm:"default:0"`
+	Amount        Money     `json:"amount" gorm:"not null"`
+	AmountPaid    Money     `json:"amount_paid" gorm:"default:0"`
 	Currency      string    `json:"currency" gorm:"default:'USD'"`
 	ExchangeRate  float64   `json:"exchange_rate" gorm:"default:1"`
 	Status        string    `json:"status" gorm:"default:'open'"`
@@ -210,12 +220,13 @@ type Payable struct {
 // FixedAsset 固定资产模型
 type FixedAsset struct {
 	AuditableModel
+	CompanyID        uint      `json:"company_id" gorm:"index;not null;default:1"`
 	AssetNumber      string    `json:"asset_number" gorm:"uniqueIndex;size:100;not null"`
 	AssetName        string    `json:"asset_name" gorm:"size:255;not null"`
 	AssetCategory    string    `json:"asset_category" gorm:"size:100;not null;index"`
 	PurchaseDate     time.Time `json:"purchase_date" gorm:"index;not null"`
-	PurchasePrice    float64   `json:"purchase_price" gorm:"not null"`
-	CurrentValue     float64   `json:"current_value" gorm:"default:0"`
+	PurchasePrice    Money     `json:"purchase_price" gorm:"not null"`
+	CurrentValue     Money     `json:"current_value" gorm:"default:0"`
 	DepreciationRate float64   `json:"depreciation_rate" gorm:"default:0"` // 年折旧率
 	UsefulLife       int       `json:"useful_life" gorm:"default:0"`       // 使用年限
 	Location         string    `json:"location" gorm:"size:255"`
@@ -228,9 +239,9 @@ type DepreciationEntry struct {
 	AuditableModel
 	AssetID            uint      `json:"asset_id" gorm:"index;not null"`
 	DepreciationDate   time.Time `json:"depreciation_date" gorm:"index;not null"`
-	DepreciationAmount float64   `json:"depreciation_amount" gorm:"not null"`
-	AccumulatedAmount  float64   `json:"accumulated_amount" gorm:"default:0"`
-	BookValue          float64   `json:"book_value" gorm:"default:0"`
+	DepreciationAmount Money     `json:"depreciation_amount" gorm:"not null"`
+	AccumulatedAmount  Money     `json:"accumulated_amount" gorm:"default:0"`
+	BookValue          Money     `json:"book_value" gorm:"default:0"`
 	Method             string    `json:"method" gorm:"size:50;not null"` // straight_line, declining_balance
 	Notes              string    `json:"notes,omitempty" gorm:"type:text"`
 
@@ -241,12 +252,13 @@ type DepreciationEntry struct {
 // TaxEntry 税务记录模型
 type TaxEntry struct {
 	AuditableModel
+	CompanyID     uint      `json:"company_id" gorm:"index;not null;default:1"`
 	TaxNumber     string    `json:"tax_number" gorm:"uniqueIndex;size:100;not null"`
 	TaxDate       time.Time `json:"tax_date" gorm:"index;not null"`
 	TaxType       string    `json:"tax_type" gorm:"size:50;not null;index"` // vat, income, sales
-	TaxableAmount float64   `json:"taxable_amount" gorm:"not null"`
+	TaxableAmount Money     `json:"taxable_amount" gorm:"not null"`
 	TaxRate       float64   `json:"tax_rate" gorm:"not null"`
-	TaxAmount     float64   `json:"tax_amount" gorm:"not null"`
+	TaxAmount     Money     `json:"tax_amount" gorm:"not null"`
 	Status        string    `json:"status" gorm:"size:50;default:'pending';index"` // pending, filed, paid
 	Notes         string    `json:"notes,omitempty" gorm:"type:text"`
 }
@@ -262,31 +274,72 @@ type Currency struct {
 	Status         string  `json:"status" gorm:"size:50;default:'active';index"`
 }
 
+// 付款类型
+const (
+	PaymentTypeReceive = "Receive" // 向客户收款
+	PaymentTypePay     = "Pay"     // 向供应商付款
+)
+
+// 付款分配单据类型
+const (
+	AllocationRefSalesInvoice = "sales_invoice"
+	AllocationRefPayable      = "payable"
+)
+
 // PaymentEntry 付款记录模型
 type PaymentEntry struct {
 	BaseModel
-	PaymentType    string     `json:"payment_type" gorm:"not null"`
-	PartyType      string     `json:"party_type" gorm:"not null"`
-	PartyID        uint       `json:"party_id" gorm:"not null"`
-	PostingDate    time.Time  `json:"posting_date" gorm:"not null"`
-	PaidAmount     float64    `json:"paid_amount" gorm:"not null"`
-	ReceivedAmount float64    `json:"received_amount" gorm:"not null"`
-	Currency       string     `json:"currency" gorm:"default:'USD'"`
-	ExchangeRate   float64    `json:"exchange_rate" gorm:"default:1"`
-	BankAccountID  *uint      `json:"bank_account_id,omitempty"`
-	CashAccountID  *uint      `json:"cash_account_id,omitempty"`
-	Reference      string     `json:"reference,omitempty"`
-	Remarks        string     `json:"remarks,omitempty"`
-	Status         string     `json:"status" gorm:"default:'draft'"`
-	IsPosted       bool       `json:"is_posted" gorm:"default:false"`
-	PostedAt       *time.Time `json:"posted_at,omitempty"`
-	CostCenterID   *uint      `json:"cost_center_id,omitempty"`
-	ProjectID      *uint      `json:"project_id,omitempty"`
+	CompanyID         uint       `json:"company_id" gorm:"index;not null;default:1"`
+	PaymentNumber     string     `json:"payment_number" gorm:"size:100;index"`
+	PaymentType       string     `json:"payment_type" gorm:"not null"`
+	PartyType         string     `json:"party_type" gorm:"not null"`
+	PartyID           uint       `json:"party_id" gorm:"not null"`
+	PostingDate       time.Time  `json:"posting_date" gorm:"not null"`
+	PaidAmount        Money      `json:"paid_amount" gorm:"not null"`
+	ReceivedAmount    Money      `json:"received_amount" gorm:"not null"`
+	AllocatedAmount   Money      `json:"allocated_amount" gorm:"default:0"`
+	UnallocatedAmount Money      `json:"unallocated_amount" gorm:"default:0"` // 未核销金额，作为预收/预付结转
+	Currency          string     `json:"currency" gorm:"default:'USD'"`
+	ExchangeRate      float64    `json:"exchange_rate" gorm:"default:1"`
+	PaymentMethod     string     `json:"payment_method,omitempty" gorm:"size:50"`
+	BankAccountID     *uint      `json:"bank_account_id,omitempty"`
+	CashAccountID     *uint      `json:"cash_account_id,omitempty"`
+	PartyAccountID    *uint      `json:"party_account_id,omitempty"` // 应收/应付科目
+	TransactionID     *uint      `json:"transaction_id,omitempty"`   // 过账生成的凭证
+	Reference         string     `json:"reference,omitempty"`
+	Remarks           string     `json:"remarks,omitempty"`
+	Status            string     `json:"status" gorm:"default:'draft'"` // draft, submitted, cancelled
+	IsPosted          bool       `json:"is_posted" gorm:"default:false"`
+	PostedAt          *time.Time `json:"posted_at,omitempty"`
+	CostCenterID      *uint      `json:"cost_center_id,omitempty"`
+	ProjectID         *uint      `json:"project_id,omitempty"`
 
 	// 关联
-	BankAccount *BankAccount `json:"bank_account,omitempty" gorm:"foreignKey:BankAccountID"`
-	CashAccount *Account     `json:"cash_account,omitempty" gorm:"foreignKey:CashAccountID"`
-	CostCenter  *CostCenter  `json:"cost_center,omitempty" gorm:"foreignKey:CostCenterID"`
+	BankAccount  *BankAccount        `json:"bank_account,omitempty" gorm:"foreignKey:BankAccountID"`
+	CashAccount  *Account            `json:"cash_account,omitempty" gorm:"foreignKey:CashAccountID"`
+	PartyAccount *Account            `json:"party_account,omitempty" gorm:"foreignKey:PartyAccountID"`
+	CostCenter   *CostCenter         `json:"cost_center,omitempty" gorm:"foreignKey:CostCenterID"`
+	Allocations  []PaymentAllocation `json:"allocations,omitempty" gorm:"foreignKey:PaymentEntryID"`
+}
+
+// Amount 付款金额：收款取实收金额，付款取实付金额
+func (p *PaymentEntry) Amount() Money {
+	if p.PaymentType == PaymentTypeReceive {
+		return p.ReceivedAmount
+	}
+	return p.PaidAmount
+}
+
+// PaymentAllocation 付款分配模型，记录一笔付款核销到发票或应付单据的金额
+type PaymentAllocation struct {
+	BaseModel
+	PaymentEntryID  uint      `json:"payment_entry_id" gorm:"index;not null"`
+	ReferenceType   string    `json:"reference_type" gorm:"size:50;not null;index:idx_payment_allocations_reference"`
+	ReferenceID     uint      `json:"reference_id" gorm:"not null;index:idx_payment_allocations_reference"`
+	ReferenceNumber string    `json:"reference_number" gorm:"size:100"`
+	AllocatedAmount Money     `json:"allocated_amount" gorm:"not null"`
+	AllocationDate  time.Time `json:"allocation_date" gorm:"not null"`
+	Status          string    `json:"status" gorm:"size:20;default:'pending';index"` // pending, applied, cancelled
 }
 
 // ExchangeRateHistory 汇率历史模型
@@ -310,22 +363,27 @@ type TaxTemplate struct {
 // FiscalYear 财政年度模型
 type FiscalYear struct {
 	BaseModel
-	Year      int       `json:"year" gorm:"uniqueIndex;not null"`
+	CompanyID uint      `json:"company_id" gorm:"uniqueIndex:idx_fiscal_years_company_year;not null;default:1"`
+	Year      int       `json:"year" gorm:"uniqueIndex:idx_fiscal_years_company_year;not null"`
 	StartDate time.Time `json:"start_date" gorm:"index;not null"`
 	EndDate   time.Time `json:"end_date" gorm:"index;not null"`
 	IsCurrent bool      `json:"is_current" gorm:"default:false"`
 	Status    string    `json:"status" gorm:"size:50;default:'active';index"`
+
+	// 关联
+	Periods []AccountingPeriod `json:"periods,omitempty" gorm:"foreignKey:FiscalYearID"`
 }
 
 // AccountingPeriod 会计期间模型
 type AccountingPeriod struct {
 	BaseModel
-	Name       string     `json:"name" gorm:"not null"`
-	StartDate  time.Time  `json:"start_date" gorm:"not null"`
-	EndDate    time.Time  `json:"end_date" gorm:"not null"`
-	FiscalYear string     `json:"fiscal_year" gorm:"not null"`
-	IsClosed   bool       `json:"is_closed" gorm:"default:false"`
-	ClosedBy   *uint      `json:"closed_by,omitempty"`
-	ClosedAt   *time.Time `json:"closed_at,omitempty"`
-	Company    string     `json:"company,omitempty"`
+	CompanyID    uint       `json:"company_id" gorm:"index;not null;default:1"`
+	FiscalYearID *uint      `json:"fiscal_year_id,omitempty" gorm:"index"`
+	Name         string     `json:"name" gorm:"not null"`
+	StartDate    time.Time  `json:"start_date" gorm:"not null"`
+	EndDate      time.Time  `json:"end_date" gorm:"not null"`
+	FiscalYear   string     `json:"fiscal_year" gorm:"not null"`
+	IsClosed     bool       `json:"is_closed" gorm:"default:false"`
+	ClosedBy     *uint      `json:"closed_by,omitempty"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
 }
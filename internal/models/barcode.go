@@ -0,0 +1,27 @@
+package models
+
+// 物料条码类型
+const (
+	BarcodeTypeEAN13   = "ean13"
+	BarcodeTypeUPCA    = "upca"
+	BarcodeTypeEAN8    = "ean8"
+	BarcodeTypeGTIN14  = "gtin14"
+	BarcodeTypeCode128 = "code128"
+	BarcodeTypeOther   = "other"
+)
+
+// ItemBarcode 物料条码，一个物料可按不同单位登记多个条码，如单品 EAN-13 与整箱 GTIN-14；
+// 扫描到条码时数量按条码的单位换算为库存单位
+type ItemBarcode struct {
+	BaseModel
+	ItemID      uint   `json:"item_id" gorm:"index;not null"`
+	Barcode     string `json:"barcode" gorm:"uniqueIndex;size:100;not null"`
+	GTIN        string `json:"gtin,omitempty" gorm:"size:14;index"` // 补零为 14 位的 GTIN，用于匹配 GS1 条码中的 (01)；非 GTIN 条码为空
+	BarcodeType string `json:"barcode_type" gorm:"size:20;not null"`
+	UOM         string `json:"uom,omitempty" gorm:"size:50"`    // 条码对应的单位，为空时为库存单位
+	IsPrimary   bool   `json:"is_primary" gorm:"default:false"` // 主条码，打印物料标签时使用
+	Description string `json:"description,omitempty" gorm:"size:255"`
+
+	// 关联
+	Item *Item `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// 物料批次、序列号跟踪方式
+const (
+	TrackingModeNone   = "none"
+	TrackingModeBatch  = "batch"
+	TrackingModeSerial = "serial"
+)
+
+// 序列号状态
+const (
+	SerialStatusInStock  = "in_stock"
+	SerialStatusIssued   = "issued" // 已出库（发货、生产领用、报损）
+	SerialStatusInactive = "inactive"
+)
+
+// Batch 批次主数据，同一物料的批次号唯一
+type Batch struct {
+	BaseModel
+	ItemID          uint       `json:"item_id" gorm:"uniqueIndex:idx_batches_item_batch;not null"`
+	BatchNo         string     `json:"batch_no" gorm:"uniqueIndex:idx_batches_item_batch;size:100;not null"`
+	ManufactureDate *time.Time `json:"manufacture_date,omitempty"`
+	ExpiryDate      *time.Time `json:"expiry_date,omitempty" gorm:"index"`
+	SupplierID      *uint      `json:"supplier_id,omitempty" gorm:"index"`
+	SupplierLotNo   string     `json:"supplier_lot_no,omitempty" gorm:"size:100"`
+	Notes           string     `json:"notes,omitempty" gorm:"type:text"`
+	IsActive        bool       `json:"is_active" gorm:"default:true"`
+
+	// 关联
+	Item     *Item     `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+	Supplier *Supplier `json:"supplier,omitempty" gorm:"foreignKey:SupplierID"`
+}
+
+// IsExpired 批次在指定时间是否已过期，有效期当天仍可使用，未设置有效期的批次不会过期
+func (b *Batch) IsExpired(at time.Time) bool {
+	return b.ExpiryDate != nil && b.ExpiryDate.Before(StartOfDay(at))
+}
+
+// StartOfDay 返回指定时间当天的零点，用于按日期比较有效期
+func StartOfDay(at time.Time) time.Time {
+	year, month, day := at.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, at.Location())
+}
+
+// BatchStock 批次在仓库的库存余额，与库存余额在同一事务中随库存移动更新
+type BatchStock struct {
+	BaseModel
+	ItemID      uint    `json:"item_id" gorm:"uniqueIndex:idx_batch_stocks_item_warehouse_batch;not null"`
+	WarehouseID uint    `json:"warehouse_id" gorm:"uniqueIndex:idx_batch_stocks_item_warehouse_batch;not null"`
+	BatchID     uint    `json:"batch_id" gorm:"uniqueIndex:idx_batch_stocks_item_warehouse_batch;index;not null"`
+	Quantity    float64 `json:"quantity" gorm:"default:0"`
+
+	// 关联
+	Batch     *Batch     `json:"batch,omitempty" gorm:"foreignKey:BatchID"`
+	Warehouse *Warehouse `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
+}
+
+// SerialNumber 序列号主数据，记录当前所在仓库与状态
+type SerialNumber struct {
+	BaseModel
+	ItemID          uint       `json:"item_id" gorm:"uniqueIndex:idx_serial_numbers_item_serial;not null"`
+	SerialNo        string     `json:"serial_no" gorm:"uniqueIndex:idx_serial_numbers_item_serial;size:100;not null"`
+	BatchID         *uint      `json:"batch_id,omitempty" gorm:"index"`
+	WarehouseID     *uint      `json:"warehouse_id,omitempty" gorm:"index"` // 出库后为空
+	Status          string     `json:"status" gorm:"size:20;default:'in_stock';index"`
+	ManufactureDate *time.Time `json:"manufacture_date,omitempty"`
+	ExpiryDate      *time.Time `json:"expiry_date,omitempty"`
+	SupplierID      *uint      `json:"supplier_id,omitempty" gorm:"index"`
+	LastMovementID  *uint      `json:"last_movement_id,omitempty"`
+
+	// 关联
+	Item      *Item      `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+	Batch     *Batch     `json:"batch,omitempty" gorm:"foreignKey:BatchID"`
+	Warehouse *Warehouse `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
+}
+
+// MovementSerialNo 库存移动涉及的序列号，用于按序列号追溯
+type MovementSerialNo struct {
+	ID             uint `json:"id" gorm:"primarykey"`
+	MovementID     uint `json:"movement_id" gorm:"index;not null"`
+	SerialNumberID uint `json:"serial_number_id" gorm:"index;not null"`
+}
+
+// ParseSerialNos 解析以逗号、分号或空白分隔的序列号列表，去除空项与重复项
+func ParseSerialNos(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || r == '，' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+	seen := make(map[string]bool, len(fields))
+	serials := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !seen[field] {
+			seen[field] = true
+			serials = append(serials, field)
+		}
+	}
+	return serials
+}
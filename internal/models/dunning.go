@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// DunningLevel 催款级别配置模型
+type DunningLevel struct {
+	AuditableModel
+	Level           int     `json:"level" gorm:"uniqueIndex;not null"` // 1=提醒, 2=二次通知, 3=最终催告
+	Name            string  `json:"name" gorm:"size:100;not null"`
+	DaysOverdue     int     `json:"days_overdue" gorm:"not null"`   // 逾期天数达到该值时触发
+	FeeAmount       Money   `json:"fee_amount" gorm:"default:0"`    // 催款手续费
+	InterestRate    float64 `json:"interest_rate" gorm:"default:0"` // 逾期利息年利率（百分比）
+	SubjectTemplate string  `json:"subject_template" gorm:"size:500"`
+	BodyTemplate    string  `json:"body_template" gorm:"type:text"`
+	IsActive        bool    `json:"is_active" gorm:"default:true;index"`
+}
+
+// DunningLetter 催款函记录模型
+type DunningLetter struct {
+	AuditableModel
+	LetterNumber      string     `json:"letter_number" gorm:"uniqueIndex;size:50;not null"`
+	CustomerID        uint       `json:"customer_id" gorm:"index;not null"`
+	SalesInvoiceID    uint       `json:"sales_invoice_id" gorm:"index;not null"`
+	DunningLevelID    uint       `json:"dunning_level_id" gorm:"index;not null"`
+	Level             int        `json:"level" gorm:"index;not null"`
+	LetterDate        time.Time  `json:"letter_date" gorm:"index;not null"`
+	OverdueDays       int        `json:"overdue_days" gorm:"not null"`
+	Currency          string     `json:"currency" gorm:"size:10;default:'CNY'"`
+	OutstandingAmount Money      `json:"outstanding_amount" gorm:"not null"`
+	FeeAmount         Money      `json:"fee_amount" gorm:"default:0"`
+	InterestAmount    Money      `json:"interest_amount" gorm:"default:0"`
+	TotalDue          Money      `json:"total_due" gorm:"not null"`
+	Subject           string     `json:"subject" gorm:"size:500"`
+	Content           string     `json:"content" gorm:"type:text"`
+	TriggerType       string     `json:"trigger_type" gorm:"size:20;default:'manual';index"` // manual, scheduled
+	Status            string     `json:"status" gorm:"size:20;default:'sent';index"`         // sent, cancelled
+	SentAt            *time.Time `json:"sent_at,omitempty"`
+
+	// 关联
+	Customer     Customer     `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
+	SalesInvoice SalesInvoice `json:"sales_invoice,omitempty" gorm:"foreignKey:SalesInvoiceID"`
+	DunningLevel DunningLevel `json:"dunning_level,omitempty" gorm:"foreignKey:DunningLevelID"`
+}
@@ -121,15 +121,15 @@ type Payroll struct {
 	EmployeeID      uint       `json:"employee_id" gorm:"index;not null"`
 	PayPeriodStart  time.Time  `json:"pay_period_start" gorm:"index;not null"`
 	PayPeriodEnd    time.Time  `json:"pay_period_end" gorm:"index;not null"`
-	BasicSalary     float64    `json:"basic_salary" gorm:"not null"`
-	OvertimePay     float64    `json:"overtime_pay" gorm:"default:0"`
-	Allowance       float64    `json:"allowance" gorm:"default:0"`
-	Bonus           float64    `json:"bonus" gorm:"default:0"`
-	Deductions      float64    `json:"deductions" gorm:"default:0"`
-	SocialInsurance float64    `json:"social_insurance" gorm:"default:0"`
-	HousingFund     float64    `json:"housing_fund" gorm:"default:0"`
-	Tax             float64    `json:"tax" gorm:"default:0"`
-	NetPay          float64    `json:"net_pay" gorm:"not null"`
+	BasicSalary     Money      `json:"basic_salary" gorm:"not null"`
+	OvertimePay     Money      `json:"overtime_pay" gorm:"default:0"`
+	Allowance       Money      `json:"allowance" gorm:"default:0"`
+	Bonus           Money      `json:"bonus" gorm:"default:0"`
+	Deductions      Money      `json:"deductions" gorm:"default:0"`
+	SocialInsurance Money      `json:"social_insurance" gorm:"default:0"`
+	HousingFund     Money      `json:"housing_fund" gorm:"default:0"`
+	Tax             Money      `json:"tax" gorm:"default:0"`
+	NetPay          Money      `json:"net_pay" gorm:"not null"`
 	Status          string     `json:"status" gorm:"size:50;default:'draft';index"` // draft, confirmed, paid
 	PaidAt          *time.Time `json:"paid_at,omitempty"`
 
@@ -147,7 +147,7 @@ type Training struct {
 	EndDate         time.Time `json:"end_date" gorm:"index;not null"`
 	Trainer         string    `json:"trainer,omitempty" gorm:"size:100"`
 	Location        string    `json:"location,omitempty" gorm:"size:255"`
-	Cost            float64   `json:"cost,omitempty" gorm:"default:0"`
+	Cost            Money     `json:"cost,omitempty" gorm:"default:0"`
 	Currency        string    `json:"currency" gorm:"size:10;default:'CNY'"`
 	MaxParticipants int       `json:"max_participants,omitempty" gorm:"default:0"`
 	Status          string    `json:"status" gorm:"size:50;default:'planned';index"` // planned, ongoing, completed, cancelled
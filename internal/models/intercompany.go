@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// 内部交易单据类型
+const (
+	IntercompanyDocSalesOrder    = "sales_order"
+	IntercompanyDocPurchaseOrder = "purchase_order"
+)
+
+// IntercompanyTransaction 内部交易关联模型，记录源单据与兄弟公司中镜像单据的对应关系
+type IntercompanyTransaction struct {
+	AuditableModel
+	SourceCompanyID uint      `json:"source_company_id" gorm:"index;not null"`
+	TargetCompanyID uint      `json:"target_company_id" gorm:"index;not null"`
+	SourceDocType   string    `json:"source_doc_type" gorm:"size:50;not null;uniqueIndex:idx_intercompany_source"`
+	SourceDocID     uint      `json:"source_doc_id" gorm:"not null;uniqueIndex:idx_intercompany_source"`
+	SourceDocNumber string    `json:"source_doc_number" gorm:"size:100"`
+	TargetDocType   string    `json:"target_doc_type" gorm:"size:50;not null;index"`
+	TargetDocID     uint      `json:"target_doc_id" gorm:"not null;index"`
+	TargetDocNumber string    `json:"target_doc_number" gorm:"size:100"`
+	Amount          Money     `json:"amount" gorm:"default:0"`
+	Currency        string    `json:"currency" gorm:"size:10;default:'CNY'"`
+	TransactionDate time.Time `json:"transaction_date" gorm:"index;not null"`
+	Status          string    `json:"status" gorm:"size:50;default:'linked';index"` // linked, cancelled
+
+	// 关联
+	SourceCompany *Company `json:"source_company,omitempty" gorm:"foreignKey:SourceCompanyID"`
+	TargetCompany *Company `json:"target_company,omitempty" gorm:"foreignKey:TargetCompanyID"`
+}
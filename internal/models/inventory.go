@@ -7,19 +7,30 @@ import (
 // Item 物料模型 - 根据数据库结构调整
 type Item struct {
 	BaseModel
-	Code         string  `json:"code" gorm:"uniqueIndex;size:100;not null"`
-	Name         string  `json:"name" gorm:"size:255;not null"`
-	Description  string  `json:"description,omitempty" gorm:"type:text"`
-	Category     string  `json:"category,omitempty" gorm:"size:100"`
-	Unit         string  `json:"unit,omitempty" gorm:"size:50"`
-	Cost         float64 `json:"cost" gorm:"default:0"`
-	Price        float64 `json:"price" gorm:"default:0"`
-	ReorderLevel int     `json:"reorder_level" gorm:"default:0"`
-	IsActive     bool    `json:"is_active" gorm:"default:true"`
+	Code                  string  `json:"code" gorm:"uniqueIndex;size:100;not null"`
+	Name                  string  `json:"name" gorm:"size:255;not null"`
+	Description           string  `json:"description,omitempty" gorm:"type:text"`
+	Category              string  `json:"category,omitempty" gorm:"size:100"`
+	Unit                  string  `json:"unit,omitempty" gorm:"size:50"`
+	Cost                  Money   `json:"cost" gorm:"default:0"` // 标准成本法下的标准成本，其他计价方法下作为无成本入库的默认成本
+	Price                 Money   `json:"price" gorm:"default:0"`
+	ReorderLevel          int     `json:"reorder_level" gorm:"default:0"`               // 再订货点，预计库存不高于该值时补货
+	ReorderQty            float64 `json:"reorder_qty" gorm:"default:0"`                 // 每次补货数量
+	MaxLevel              float64 `json:"max_level" gorm:"default:0"`                   // 最高库存，大于 0 时补货至该库存
+	PreferredSupplierID   *uint   `json:"preferred_supplier_id,omitempty" gorm:"index"` // 首选供应商，补货申请按其分组
+	IsActive              bool    `json:"is_active" gorm:"default:true"`
+	ValuationMethod       string  `json:"valuation_method" gorm:"size:20;default:'moving_average'"` // fifo, moving_average, standard
+	TrackingMode          string  `json:"tracking_mode" gorm:"size:20;default:'none'"`              // none, batch, serial
+	ShelfLifeDays         int     `json:"shelf_life_days" gorm:"default:0"`                         // 保质期天数，入库新建批次未填有效期时按入库日期推算
+	Weight                float64 `json:"weight" gorm:"default:0"`                                  // 单位重量（千克），到岸成本按重量分摊时使用
+	OverDeliveryAllowance float64 `json:"over_delivery_allowance" gorm:"default:0"`                 // 允许超出销售订单数量发货的百分比
+	HasVariants           bool    `json:"has_variants" gorm:"default:false"`                        // 模板物料，不直接持有库存，按属性生成变体
+	VariantOf             *uint   `json:"variant_of,omitempty" gorm:"index"`                        // 变体所属的模板物料
 
 	// 关联
-	Stocks    []Stock    `json:"stocks,omitempty" gorm:"foreignKey:ItemID"`
-	Movements []Movement `json:"movements,omitempty" gorm:"foreignKey:ItemID"`
+	Stocks            []Stock                `json:"stocks,omitempty" gorm:"foreignKey:ItemID"`
+	Movements         []Movement             `json:"movements,omitempty" gorm:"foreignKey:ItemID"`
+	VariantAttributes []ItemVariantAttribute `json:"variant_attributes,omitempty" gorm:"foreignKey:ItemID"`
 }
 
 // 注意：Category和Unit模型已移除，因为数据库中使用字符串字段而非关联表
@@ -33,6 +44,7 @@ type Warehouse struct {
 	Address     string `json:"address,omitempty" gorm:"type:text"`
 	ManagerID   *uint  `json:"manager_id,omitempty" gorm:"index"`
 	IsActive    bool   `json:"is_active" gorm:"default:true"`
+	IsTransit   bool   `json:"is_transit" gorm:"default:false;index"` // 在途虚拟仓，用于记录已发出未收货的调拨库存
 
 	// 关联
 	Manager   *User      `json:"manager,omitempty" gorm:"foreignKey:ManagerID"`
@@ -44,45 +56,78 @@ type Warehouse struct {
 // Location 库位模型 - 根据数据库结构调整
 type Location struct {
 	BaseModel
-	WarehouseID  uint   `json:"warehouse_id" gorm:"index;not null"`
-	Code         string `json:"code" gorm:"size:100;not null"`
-	Name         string `json:"name" gorm:"size:255;not null"`
-	LocationType string `json:"location_type" gorm:"size:50"`
-	Status       string `json:"status" gorm:"size:50;default:'ACTIVE'"`
+	WarehouseID  uint    `json:"warehouse_id" gorm:"index;not null"`
+	Code         string  `json:"code" gorm:"size:100;not null"`
+	Name         string  `json:"name" gorm:"size:255;not null"`
+	LocationType string  `json:"location_type" gorm:"size:50"`
+	Status       string  `json:"status" gorm:"size:50;default:'ACTIVE'"`
+	Description  string  `json:"description,omitempty" gorm:"type:text"`
+	Capacity     float64 `json:"capacity" gorm:"default:0"`           // 库位可存放的最大数量，0 表示不限
+	Sequence     int     `json:"sequence" gorm:"default:0"`           // 上架与拣货时的库位顺序
+	Zone         string  `json:"zone,omitempty" gorm:"size:50;index"` // 所属库区，用于按库区盘点
 
 	// 关联
 	Warehouse Warehouse `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
 }
 
 // Stock 库存模型 - 根据数据库结构调整
+// Quantity 为库存台账（Movement.QuantityChange）的汇总余额，只能通过过账库存移动修改
 type Stock struct {
 	BaseModel
-	ItemID      uint    `json:"item_id" gorm:"index;not null"`
-	WarehouseID uint    `json:"warehouse_id" gorm:"index;not null"`
-	Quantity    float64 `json:"quantity" gorm:"default:0"`
+	ItemID        uint    `json:"item_id" gorm:"index;uniqueIndex:idx_stocks_item_warehouse;not null"`
+	WarehouseID   uint    `json:"warehouse_id" gorm:"index;uniqueIndex:idx_stocks_item_warehouse;not null"`
+	Quantity      float64 `json:"quantity" gorm:"default:0"`
+	StockValue    Money   `json:"stock_value" gorm:"default:0"`    // 库存金额，台账 ValueChange 的汇总
+	ValuationRate Money   `json:"valuation_rate" gorm:"default:0"` // 单位成本，库存金额除以数量
 
 	// 关联
 	Item      Item      `json:"item,omitempty" gorm:"foreignKey:ItemID"`
 	Warehouse Warehouse `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
 }
 
+// StockCostLayer 先进先出成本层，每笔入库形成一层，出库时按入库顺序消耗
+type StockCostLayer struct {
+	BaseModel
+	ItemID       uint    `json:"item_id" gorm:"index:idx_stock_cost_layers_item_warehouse;not null"`
+	WarehouseID  uint    `json:"warehouse_id" gorm:"index:idx_stock_cost_layers_item_warehouse;not null"`
+	MovementID   uint    `json:"movement_id" gorm:"index;not null"`
+	Quantity     float64 `json:"quantity" gorm:"not null"`
+	RemainingQty float64 `json:"remaining_qty" gorm:"not null"`
+	UnitCost     Money   `json:"unit_cost" gorm:"not null"`
+}
+
 // Movement 库存移动模型 - 根据数据库结构调整
+// 库存移动构成只追加的库存台账，每条记录与库存余额更新在同一事务中提交
 type Movement struct {
 	BaseModel
-	ItemID        *uint      `json:"item_id,omitempty"`
-	WarehouseID   *uint      `json:"warehouse_id,omitempty"`
-	Quantity      *float64   `json:"quantity,omitempty"`
-	MovementType  string     `json:"movement_type,omitempty"`
-	Reference     string     `json:"reference,omitempty"`
-	Notes         string     `json:"notes,omitempty"`
-	UnitCost      float64    `json:"unit_cost" gorm:"default:0"`
-	TotalCost     float64    `json:"total_cost" gorm:"default:0"`
-	ReferenceType string     `json:"reference_type,omitempty"`
-	ReferenceID   *uint      `json:"reference_id,omitempty"`
-	BatchNo       string     `json:"batch_no,omitempty"`
-	SerialNo      string     `json:"serial_no,omitempty"`
-	ExpiryDate    *time.Time `json:"expiry_date,omitempty"`
-	CreatedBy     *uint      `json:"created_by,omitempty"`
+	ItemID           *uint      `json:"item_id,omitempty"`
+	WarehouseID      *uint      `json:"warehouse_id,omitempty"`
+	Quantity         *float64   `json:"quantity,omitempty"`                 // 库存单位数量
+	UOM              string     `json:"uom,omitempty" gorm:"size:50"`       // 单据行单位，为空表示库存单位
+	UOMQuantity      float64    `json:"uom_quantity" gorm:"default:0"`      // 按单据行单位的数量
+	ConversionFactor float64    `json:"conversion_factor" gorm:"default:1"` // 1 个单据行单位折合的库存单位数量
+	QuantityChange   float64    `json:"quantity_change" gorm:"default:0"`   // 对库存余额的带符号影响
+	BalanceAfter     float64    `json:"balance_after" gorm:"default:0"`     // 过账后的库存余额
+	MovementType     string     `json:"movement_type,omitempty"`
+	Reference        string     `json:"reference,omitempty"`
+	Notes            string     `json:"notes,omitempty"`
+	UnitCost         Money      `json:"unit_cost" gorm:"default:0"`
+	TotalCost        Money      `json:"total_cost" gorm:"default:0"`
+	ValueChange      Money      `json:"value_change" gorm:"default:0"` // 对库存金额的带符号影响
+	ValueAfter       Money      `json:"value_after" gorm:"default:0"`  // 过账后的库存金额
+	ReferenceType    string     `json:"reference_type,omitempty"`
+	ReferenceID      *uint      `json:"reference_id,omitempty"`
+	ReferenceLineID  *uint      `json:"reference_line_id,omitempty"`
+	IdempotencyKey   *string    `json:"idempotency_key,omitempty" gorm:"size:191;uniqueIndex"` // 同一来源单据行只过账一次
+	LocationID       *uint      `json:"location_id,omitempty" gorm:"index"`                    // 为空时出库先扣减未分配库位的库存
+	BatchID          *uint      `json:"batch_id,omitempty" gorm:"index"`
+	BatchNo          string     `json:"batch_no,omitempty"`
+	SerialNo         string     `json:"serial_no,omitempty"` // 序列号管理物料的序列号列表，逗号分隔
+	ExpiryDate       *time.Time `json:"expiry_date,omitempty"`
+	CreatedBy        *uint      `json:"created_by,omitempty"`
+
+	// SourceMovement 入库成本取自已过账的来源移动（如调拨发货），不持久化
+	SourceMovement *Movement `json:"-" gorm:"-"`
 
 	// 关联
 	Item      *Item      `json:"item,omitempty" gorm:"foreignKey:ItemID"`
@@ -113,8 +158,8 @@ type StockAdjustmentItem struct {
 	SystemQty     float64 `json:"system_qty" gorm:"not null"`
 	ActualQty     float64 `json:"actual_qty" gorm:"not null"`
 	DifferenceQty float64 `json:"difference_qty" gorm:"not null"`
-	UnitCost      float64 `json:"unit_cost" gorm:"default:0"`
-	TotalCost     float64 `json:"total_cost" gorm:"default:0"`
+	UnitCost      Money   `json:"unit_cost" gorm:"default:0"`
+	TotalCost     Money   `json:"total_cost" gorm:"default:0"`
 	Notes         string  `json:"notes,omitempty" gorm:"type:text"`
 
 	// 关联
@@ -125,17 +170,120 @@ type StockAdjustmentItem struct {
 // StockMovement 库存移动类型别名，用于兼容repository
 type StockMovement = Movement
 
-// StockTransfer 库存调拨模型 - 根据数据库结构调整
+// StockTransfer 库存调拨单：发货时从调出仓转入在途仓，收货时从在途仓转入调入仓
 type StockTransfer struct {
+	AuditableModel
+	TransferNumber     string     `json:"transfer_number" gorm:"uniqueIndex;size:100;not null"`
+	FromWarehouseID    uint       `json:"from_warehouse_id" gorm:"index;not null"`
+	ToWarehouseID      uint       `json:"to_warehouse_id" gorm:"index;not null"`
+	FromLocationID     *uint      `json:"from_location_id,omitempty" gorm:"index"`
+	ToLocationID       *uint      `json:"to_location_id,omitempty" gorm:"index"`
+	TransitWarehouseID *uint      `json:"transit_warehouse_id,omitempty" gorm:"index"`
+	TransferDate       time.Time  `json:"transfer_date" gorm:"index;not null"`
+	ShippedAt          *time.Time `json:"shipped_at,omitempty"`
+	ReceivedAt         *time.Time `json:"received_at,omitempty"`
+	Status             string     `json:"status" gorm:"size:50;default:'draft';index"` // draft, in_transit, partially_received, received, cancelled
+	HasDiscrepancy     bool       `json:"has_discrepancy" gorm:"default:false"`
+	Notes              string     `json:"notes,omitempty" gorm:"type:text"`
+
+	// 关联
+	FromWarehouse    *Warehouse          `json:"from_warehouse,omitempty" gorm:"foreignKey:FromWarehouseID"`
+	ToWarehouse      *Warehouse          `json:"to_warehouse,omitempty" gorm:"foreignKey:ToWarehouseID"`
+	TransitWarehouse *Warehouse          `json:"transit_warehouse,omitempty" gorm:"foreignKey:TransitWarehouseID"`
+	Items            []StockTransferItem `json:"items,omitempty" gorm:"foreignKey:TransferID"`
+}
+
+// StockTransferItem 库存调拨明细
+type StockTransferItem struct {
 	BaseModel
-	ItemID          *uint    `json:"item_id,omitempty"`
-	FromWarehouseID *uint    `json:"from_warehouse_id,omitempty"`
-	ToWarehouseID   *uint    `json:"to_warehouse_id,omitempty"`
-	Quantity        *float64 `json:"quantity,omitempty"`
-	Notes           string   `json:"notes,omitempty"`
+	TransferID            uint    `json:"transfer_id" gorm:"index;not null"`
+	ItemID                uint    `json:"item_id" gorm:"index;not null"`
+	FromLocationID        *uint   `json:"from_location_id,omitempty" gorm:"index"`
+	ToLocationID          *uint   `json:"to_location_id,omitempty" gorm:"index"`
+	Quantity              float64 `json:"quantity" gorm:"not null"` // 库存单位数量，发货、收货数量同为库存单位
+	UOM                   string  `json:"uom,omitempty" gorm:"size:50"`
+	UOMQuantity           float64 `json:"uom_quantity" gorm:"default:0"`
+	ConversionFactor      float64 `json:"conversion_factor" gorm:"default:1"`
+	ShippedQty            float64 `json:"shipped_qty" gorm:"default:0"`
+	ReceivedQty           float64 `json:"received_qty" gorm:"default:0"`
+	DiscrepancyQty        float64 `json:"discrepancy_qty" gorm:"default:0"`                // 实收减实发，负数为短收
+	DiscrepancyResolution string  `json:"discrepancy_resolution,omitempty" gorm:"size:50"` // write_off, return, surplus
+	DiscrepancyReason     string  `json:"discrepancy_reason,omitempty" gorm:"size:255"`
+	BatchNo               string  `json:"batch_no,omitempty" gorm:"size:100"`
+	SerialNo              string  `json:"serial_no,omitempty" gorm:"type:text"`          // 序列号管理物料发出的序列号，逗号分隔
+	ReceivedSerialNo      string  `json:"received_serial_no,omitempty" gorm:"type:text"` // 已收货的序列号，逗号分隔
+	Notes                 string  `json:"notes,omitempty" gorm:"type:text"`
 
 	// 关联
-	Item          *Item      `json:"item,omitempty" gorm:"foreignKey:ItemID"`
-	FromWarehouse *Warehouse `json:"from_warehouse,omitempty" gorm:"foreignKey:FromWarehouseID"`
-	ToWarehouse   *Warehouse `json:"to_warehouse,omitempty" gorm:"foreignKey:ToWarehouseID"`
+	Item *Item `json:"item,omitempty" gorm:"foreignKey:ItemID"`
 }
+
+// PendingQty 已发出尚未收货或处理的在途数量
+func (i *StockTransferItem) PendingQty() float64 {
+	if i.DiscrepancyResolution == StockTransferResolutionWriteOff || i.DiscrepancyResolution == StockTransferResolutionReturn {
+		return 0
+	}
+	if pending := i.ShippedQty - i.ReceivedQty; pending > 0 {
+		return pending
+	}
+	return 0
+}
+
+// PendingSerialNos 已发出尚未收货或处理的序列号
+func (i *StockTransferItem) PendingSerialNos() []string {
+	if i.DiscrepancyResolution == StockTransferResolutionWriteOff || i.DiscrepancyResolution == StockTransferResolutionReturn {
+		return nil
+	}
+	received := make(map[string]bool)
+	for _, serialNo := range ParseSerialNos(i.ReceivedSerialNo) {
+		received[serialNo] = true
+	}
+	var pending []string
+	for _, serialNo := range ParseSerialNos(i.SerialNo) {
+		if !received[serialNo] {
+			pending = append(pending, serialNo)
+		}
+	}
+	return pending
+}
+
+// 库存移动类型
+const (
+	MovementTypeIn          = "in"
+	MovementTypeOut         = "out"
+	MovementTypeAdjustment  = "adjustment"
+	MovementTypeTransferOut = "transfer_out"
+	MovementTypeTransferIn  = "transfer_in"
+	MovementTypeOpening     = "opening"
+	MovementTypeRevaluation = "revaluation" // 只调整库存金额，数量不变，金额由来源单据给定
+)
+
+// 库存移动来源单据类型
+const (
+	MovementReferenceDeliveryNote    = "delivery_note"
+	MovementReferencePurchaseReceipt = "purchase_receipt"
+	MovementReferenceProductionOrder = "production_order"
+)
+
+// 存货计价方法
+const (
+	ValuationMethodFIFO          = "fifo"
+	ValuationMethodMovingAverage = "moving_average"
+	ValuationMethodStandard      = "standard"
+)
+
+// 库存调拨单状态
+const (
+	StockTransferStatusDraft             = "draft"
+	StockTransferStatusInTransit         = "in_transit"
+	StockTransferStatusPartiallyReceived = "partially_received"
+	StockTransferStatusReceived          = "received"
+	StockTransferStatusCancelled         = "cancelled"
+)
+
+// 调拨差异处理方式
+const (
+	StockTransferResolutionWriteOff = "write_off" // 短收数量从在途仓报损
+	StockTransferResolutionReturn   = "return"    // 短收数量退回调出仓
+	StockTransferResolutionSurplus  = "surplus"   // 溢收数量直接计入调入仓
+)
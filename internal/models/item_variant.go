@@ -0,0 +1,44 @@
+package models
+
+// ItemAttribute 物料属性（尺码、颜色、材质等），模板物料按所选属性的取值组合生成变体
+type ItemAttribute struct {
+	BaseModel
+	Code        string `json:"code" gorm:"uniqueIndex;size:50;not null"`
+	Name        string `json:"name" gorm:"size:100;not null"`
+	Description string `json:"description,omitempty" gorm:"type:text"`
+
+	// 关联
+	Values []ItemAttributeValue `json:"values,omitempty" gorm:"foreignKey:AttributeID"`
+}
+
+// ItemAttributeValue 物料属性的可选取值，缩写用于拼接变体编码
+type ItemAttributeValue struct {
+	BaseModel
+	AttributeID  uint   `json:"attribute_id" gorm:"uniqueIndex:idx_item_attribute_values_attribute_value;not null"`
+	Value        string `json:"value" gorm:"uniqueIndex:idx_item_attribute_values_attribute_value;size:100;not null"`
+	Abbreviation string `json:"abbreviation" gorm:"size:20;not null"`
+	SortOrder    int    `json:"sort_order" gorm:"default:0"`
+}
+
+// ItemTemplateAttribute 模板物料使用的属性，SortOrder 决定变体编码与名称中的属性顺序
+type ItemTemplateAttribute struct {
+	BaseModel
+	ItemID      uint `json:"item_id" gorm:"uniqueIndex:idx_item_template_attributes_item_attribute;not null"`
+	AttributeID uint `json:"attribute_id" gorm:"uniqueIndex:idx_item_template_attributes_item_attribute;not null"`
+	SortOrder   int  `json:"sort_order" gorm:"default:0"`
+
+	// 关联
+	Attribute *ItemAttribute `json:"attribute,omitempty" gorm:"foreignKey:AttributeID"`
+}
+
+// ItemVariantAttribute 变体物料在各属性上的取值
+type ItemVariantAttribute struct {
+	BaseModel
+	ItemID           uint `json:"item_id" gorm:"uniqueIndex:idx_item_variant_attributes_item_attribute;not null"`
+	AttributeID      uint `json:"attribute_id" gorm:"uniqueIndex:idx_item_variant_attributes_item_attribute;not null"`
+	AttributeValueID uint `json:"attribute_value_id" gorm:"index;not null"`
+
+	// 关联
+	Attribute      *ItemAttribute      `json:"attribute,omitempty" gorm:"foreignKey:AttributeID"`
+	AttributeValue *ItemAttributeValue `json:"attribute_value,omitempty" gorm:"foreignKey:AttributeValueID"`
+}
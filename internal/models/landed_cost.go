@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// 到岸成本分摊方式
+const (
+	LandedCostAllocateByQuantity = "quantity" // 按入库数量
+	LandedCostAllocateByValue    = "value"    // 按入库金额
+	LandedCostAllocateByWeight   = "weight"   // 按物料单位重量 × 入库数量
+	LandedCostAllocateManual     = "manual"   // 按收货明细手工指定金额
+)
+
+// 到岸成本费用类型
+const (
+	LandedCostChargeFreight   = "freight"
+	LandedCostChargeCustoms   = "customs"
+	LandedCostChargeInsurance = "insurance"
+	LandedCostChargeOther     = "other"
+)
+
+// 到岸成本单状态
+const (
+	LandedCostStatusDraft     = "draft"     // 已计算分摊，尚未调整库存金额
+	LandedCostStatusSubmitted = "submitted" // 已调整库存金额并生成总账凭证
+	LandedCostStatusCancelled = "cancelled"
+)
+
+// MovementReferenceLandedCost 到岸成本重估的库存移动来源单据类型
+const MovementReferenceLandedCost = "landed_cost_voucher"
+
+// LandedCostVoucher 到岸成本单：将运费、关税、保险等费用分摊到一张或多张采购收货单的收货明细上，
+// 提交时仍在库的部分计入库存金额，已出库的部分计入销售成本
+type LandedCostVoucher struct {
+	BaseModel
+	CompanyID         uint       `json:"company_id" gorm:"index;not null;default:1"`
+	VoucherNumber     string     `json:"voucher_number" gorm:"uniqueIndex;size:50;not null"`
+	PostingDate       time.Time  `json:"posting_date" gorm:"index;not null"`
+	AllocationMethod  string     `json:"allocation_method" gorm:"size:20;not null"`
+	Status            string     `json:"status" gorm:"size:20;default:'draft';index"`
+	TotalCharges      Money      `json:"total_charges" gorm:"default:0"`
+	CapitalizedAmount Money      `json:"capitalized_amount" gorm:"default:0"` // 计入库存金额的部分
+	ExpensedAmount    Money      `json:"expensed_amount" gorm:"default:0"`    // 已出库或标准成本物料计入销售成本的部分
+	SubmittedAt       *time.Time `json:"submitted_at,omitempty"`
+	TransactionID     *uint      `json:"transaction_id,omitempty" gorm:"index"` // 到岸成本总账凭证
+	Notes             string     `json:"notes,omitempty" gorm:"type:text"`
+	CreatedBy         *uint      `json:"created_by,omitempty"`
+
+	// 关联
+	Receipts []LandedCostReceipt `json:"receipts,omitempty" gorm:"foreignKey:LandedCostVoucherID"`
+	Charges  []LandedCostCharge  `json:"charges,omitempty" gorm:"foreignKey:LandedCostVoucherID"`
+	Items    []LandedCostItem    `json:"items,omitempty" gorm:"foreignKey:LandedCostVoucherID"`
+}
+
+// LandedCostReceipt 到岸成本单引用的采购收货单
+type LandedCostReceipt struct {
+	BaseModel
+	LandedCostVoucherID uint `json:"landed_cost_voucher_id" gorm:"index;not null"`
+	PurchaseReceiptID   uint `json:"purchase_receipt_id" gorm:"index;not null"`
+
+	// 关联
+	PurchaseReceipt *PurchaseReceipt `json:"purchase_receipt,omitempty" gorm:"foreignKey:PurchaseReceiptID"`
+}
+
+// LandedCostCharge 到岸成本费用，提交时贷记费用科目
+type LandedCostCharge struct {
+	BaseModel
+	LandedCostVoucherID uint   `json:"landed_cost_voucher_id" gorm:"index;not null"`
+	ChargeType          string `json:"charge_type" gorm:"size:20;not null"`
+	Description         string `json:"description,omitempty"`
+	Amount              Money  `json:"amount" gorm:"not null"`
+	AccountCode         string `json:"account_code" gorm:"size:50"` // 贷方科目编码，默认应付账款
+	SupplierID          *uint  `json:"supplier_id,omitempty"`       // 承运商、报关行等费用供应商
+}
+
+// LandedCostItem 到岸成本分摊行，按收货明细与入库仓库区分；数量与金额取自收货入库的库存移动
+type LandedCostItem struct {
+	BaseModel
+	LandedCostVoucherID   uint    `json:"landed_cost_voucher_id" gorm:"index;not null"`
+	PurchaseReceiptID     uint    `json:"purchase_receipt_id" gorm:"not null"`
+	PurchaseReceiptItemID uint    `json:"purchase_receipt_item_id" gorm:"index;not null"`
+	ItemID                uint    `json:"item_id" gorm:"not null"`
+	WarehouseID           uint    `json:"warehouse_id" gorm:"not null"`
+	Quantity              float64 `json:"quantity" gorm:"default:0"` // 入库数量
+	Amount                Money   `json:"amount" gorm:"default:0"`   // 入库金额
+	Weight                float64 `json:"weight" gorm:"default:0"`   // 入库总重量
+	AllocatedAmount       Money   `json:"allocated_amount" gorm:"default:0"`
+	CapitalizedAmount     Money   `json:"capitalized_amount" gorm:"default:0"`
+	ExpensedAmount        Money   `json:"expensed_amount" gorm:"default:0"`
+	MovementID            *uint   `json:"movement_id,omitempty"` // 调整库存金额的重估移动
+
+	// 关联
+	Item *Item `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// 库位状态
+const (
+	LocationStatusActive   = "ACTIVE"
+	LocationStatusInactive = "INACTIVE"
+)
+
+// LocationTypeQuarantine 隔离库位类型，存放已过期待处理的批次库存，不参与拣货
+const LocationTypeQuarantine = "quarantine"
+
+// 拣货策略
+const (
+	PickingStrategyFIFO = "fifo" // 先入库先拣
+	PickingStrategyFEFO = "fefo" // 先到期先拣
+)
+
+// LocationStock 库位库存余额，按物料、库位与批次区分（无批次时 BatchID 为 0）。
+// 仓库库存与库位库存之差为尚未上架的未分配库存
+type LocationStock struct {
+	BaseModel
+	ItemID          uint       `json:"item_id" gorm:"uniqueIndex:idx_location_stocks_item_location_batch;index:idx_location_stocks_item_warehouse;not null"`
+	WarehouseID     uint       `json:"warehouse_id" gorm:"index:idx_location_stocks_item_warehouse;not null"`
+	LocationID      uint       `json:"location_id" gorm:"uniqueIndex:idx_location_stocks_item_location_batch;index;not null"`
+	BatchID         uint       `json:"batch_id" gorm:"uniqueIndex:idx_location_stocks_item_location_batch;default:0"`
+	Quantity        float64    `json:"quantity" gorm:"default:0"`
+	FirstReceivedAt *time.Time `json:"first_received_at,omitempty"` // 当前库存最早的入库时间，用于先入库先拣
+
+	// 关联
+	Item     *Item     `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+	Location *Location `json:"location,omitempty" gorm:"foreignKey:LocationID"`
+}
+
+// PutawayRule 上架规则：指定物料时为固定库位，指定类别时按物料类别匹配，均为空时适用于仓库全部物料；
+// 同类规则按优先级从小到大选择，库位容量不足时顺延到下一条规则
+type PutawayRule struct {
+	BaseModel
+	WarehouseID uint   `json:"warehouse_id" gorm:"index;not null"`
+	LocationID  uint   `json:"location_id" gorm:"index;not null"`
+	ItemID      *uint  `json:"item_id,omitempty" gorm:"index"`
+	Category    string `json:"category,omitempty" gorm:"size:100;index"`
+	Priority    int    `json:"priority" gorm:"default:0"`
+	IsActive    bool   `json:"is_active" gorm:"default:true"`
+	Notes       string `json:"notes,omitempty" gorm:"type:text"`
+
+	// 关联
+	Location *Location `json:"location,omitempty" gorm:"foreignKey:LocationID"`
+	Item     *Item     `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+}
+
+// LocationMove 仓库内库位间移库记录，只调整库位库存，不影响仓库库存与金额；
+// 调出库位为空表示从未分配库存上架，调入库位为空表示下架为未分配库存
+type LocationMove struct {
+	BaseModel
+	ItemID         uint    `json:"item_id" gorm:"index;not null"`
+	WarehouseID    uint    `json:"warehouse_id" gorm:"index;not null"`
+	FromLocationID *uint   `json:"from_location_id,omitempty" gorm:"index"`
+	ToLocationID   *uint   `json:"to_location_id,omitempty" gorm:"index"`
+	BatchID        uint    `json:"batch_id" gorm:"default:0"`
+	Quantity       float64 `json:"quantity" gorm:"not null"`
+	Reference      string  `json:"reference,omitempty" gorm:"size:100"`
+	Notes          string  `json:"notes,omitempty" gorm:"type:text"`
+	CreatedBy      *uint   `json:"created_by,omitempty"`
+
+	// 关联
+	Item         *Item     `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+	FromLocation *Location `json:"from_location,omitempty" gorm:"foreignKey:FromLocationID"`
+	ToLocation   *Location `json:"to_location,omitempty" gorm:"foreignKey:ToLocationID"`
+}
@@ -0,0 +1,327 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// MoneyScale 金额内部精度：以 1/10000 为最小存储单位
+const (
+	MoneyScale     = 10000
+	MoneyPrecision = 4
+)
+
+// DefaultCurrency 未指定币种的单据所使用的本位币
+const DefaultCurrency = "CNY"
+
+// Money 定点小数金额类型
+// 内部以 int64 保存 金额*10000，避免 float64 累加产生的分位误差；
+// JSON 以字符串编码（如 "1234.50"），反序列化同时兼容字符串与数字；
+// PostgreSQL 列类型为 numeric(20,4)；SQLite 使用 decimal(20,4) 获得 NUMERIC 亲和性，
+// 以保证比较、排序与 SUM 聚合按数值进行，15 位有效数字内可无损往返。
+type Money int64
+
+// 舍入策略：统一采用四舍五入（远离零方向），所有服务均通过 Round/RoundCurrency 应用
+var currencyPrecision = map[string]int{
+	"CNY": 2, "USD": 2, "EUR": 2, "GBP": 2, "HKD": 2, "SGD": 2, "AUD": 2, "CAD": 2,
+	"JPY": 0, "KRW": 0, "VND": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "JOD": 3,
+}
+
+// CurrencyPrecision 返回币种的小数位数，未知币种默认 2 位
+func CurrencyPrecision(currency string) int {
+	if p, ok := currencyPrecision[strings.ToUpper(currency)]; ok {
+		return p
+	}
+	return 2
+}
+
+// NewMoney 由整数金额构造
+func NewMoney(units int64) Money {
+	return Money(units * MoneyScale)
+}
+
+// NewMoneyFromFloat 由 float64 构造金额，按十进制最短表示解析以避免二进制误差
+func NewMoneyFromFloat(f float64) Money {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	m, err := ParseMoney(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Money(math.Round(f * MoneyScale))
+	}
+	return m
+}
+
+// decimalPattern 金额只接受普通十进制写法，拒绝分数、十六进制与科学计数法
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)$`)
+
+// ParseMoney 解析十进制字符串为金额，超出 4 位的小数按四舍五入处理
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if !decimalPattern.MatchString(s) {
+		return 0, fmt.Errorf("无效的金额: %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("无效的金额: %q", s)
+	}
+	return moneyFromRat(r)
+}
+
+// moneyFromRat 将有理数按四舍五入转换为金额
+func moneyFromRat(r *big.Rat) (Money, error) {
+	scaled := new(big.Rat).Mul(r, big.NewRat(MoneyScale, 1))
+	num := new(big.Int).Set(scaled.Num())
+	den := scaled.Denom()
+
+	neg := num.Sign() < 0
+	num.Abs(num)
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	// 余数*2 >= 分母 则进位（远离零）
+	if rem.Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if neg {
+		q.Neg(q)
+	}
+	if !q.IsInt64() {
+		return 0, fmt.Errorf("金额超出范围: %s", r.FloatString(MoneyPrecision))
+	}
+	return Money(q.Int64()), nil
+}
+
+// rat 返回金额的有理数表示
+func (m Money) rat() *big.Rat {
+	return big.NewRat(int64(m), MoneyScale)
+}
+
+// floatRat 将 float64 按十进制最短表示转换为有理数
+func floatRat(f float64) *big.Rat {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// Add 加法
+func (m Money) Add(o Money) Money { return m + o }
+
+// Sub 减法
+func (m Money) Sub(o Money) Money { return m - o }
+
+// Neg 取反
+func (m Money) Neg() Money { return -m }
+
+// Abs 绝对值
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// Mul 乘以数量或系数，结果四舍五入到内部精度，超出金额范围时返回错误
+func (m Money) Mul(factor float64) (Money, error) {
+	return moneyFromRat(new(big.Rat).Mul(m.rat(), floatRat(factor)))
+}
+
+// Div 除以数量或系数，除数为 0 时返回 0，超出金额范围时返回错误
+func (m Money) Div(divisor float64) (Money, error) {
+	d := floatRat(divisor)
+	if d.Sign() == 0 {
+		return 0, nil
+	}
+	return moneyFromRat(new(big.Rat).Quo(m.rat(), d))
+}
+
+// Percent 计算百分比金额，如 Percent(13) 为 13%，超出金额范围时返回错误
+func (m Money) Percent(percent float64) (Money, error) {
+	r := new(big.Rat).Mul(m.rat(), floatRat(percent))
+	r.Quo(r, big.NewRat(100, 1))
+	return moneyFromRat(r)
+}
+
+// ProratedPercent 按期间比例计算百分比金额 m × percent% × elapsed / period，如按年利率计算逾期天数的利息；
+// 全程有理数运算，仅在最后舍入一次，period 不大于 0 时返回 0，超出金额范围时返回错误
+func (m Money) ProratedPercent(percent float64, elapsed, period int) (Money, error) {
+	if period <= 0 {
+		return 0, nil
+	}
+	r := new(big.Rat).Mul(m.rat(), floatRat(percent))
+	r.Mul(r, big.NewRat(int64(elapsed), int64(period)*100))
+	return moneyFromRat(r)
+}
+
+// Ratio 返回 m/o 的比例，o 为 0 时返回 0
+func (m Money) Ratio(o Money) float64 {
+	if o == 0 {
+		return 0
+	}
+	f, _ := new(big.Rat).Quo(m.rat(), o.rat()).Float64()
+	return f
+}
+
+// Round 四舍五入到指定小数位
+func (m Money) Round(places int) Money {
+	if places >= MoneyPrecision {
+		return m
+	}
+	if places < 0 {
+		places = 0
+	}
+	unit := int64(math.Pow10(MoneyPrecision - places))
+	v := int64(m)
+	half := unit / 2
+	if v >= 0 {
+		return Money((v + half) / unit * unit)
+	}
+	return Money(-((-v + half) / unit * unit))
+}
+
+// RoundCurrency 按币种精度四舍五入
+func (m Money) RoundCurrency(currency string) Money {
+	return m.Round(CurrencyPrecision(currency))
+}
+
+// IsZero 是否为零
+func (m Money) IsZero() bool { return m == 0 }
+
+// IsPositive 是否大于零
+func (m Money) IsPositive() bool { return m > 0 }
+
+// IsNegative 是否小于零
+func (m Money) IsNegative() bool { return m < 0 }
+
+// Min 返回较小值
+func (m Money) Min(o Money) Money {
+	if o < m {
+		return o
+	}
+	return m
+}
+
+// Max 返回较大值
+func (m Money) Max(o Money) Money {
+	if o > m {
+		return o
+	}
+	return m
+}
+
+// Float64 转换为 float64，仅用于展示或统计，不应参与记账运算
+func (m Money) Float64() float64 {
+	return float64(m) / MoneyScale
+}
+
+// String 十进制字符串表示，至少保留 2 位小数，去除多余的尾随零
+func (m Money) String() string {
+	s := m.StringFixed(MoneyPrecision)
+	dot := strings.IndexByte(s, '.')
+	end := len(s)
+	for end > dot+3 && s[end-1] == '0' {
+		end--
+	}
+	return s[:end]
+}
+
+// StringFixed 以固定小数位输出
+func (m Money) StringFixed(places int) string {
+	if places > MoneyPrecision {
+		places = MoneyPrecision
+	}
+	return m.rat().FloatString(places)
+}
+
+// SumMoney 求和
+func SumMoney(values ...Money) Money {
+	var total Money
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// MarshalJSON 以字符串编码金额
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON 兼容字符串与数字两种编码
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	v, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Value 实现 driver.Valuer，以十进制字符串写入数据库
+func (m Money) Value() (driver.Value, error) {
+	return m.StringFixed(MoneyPrecision), nil
+}
+
+// Scan 实现 sql.Scanner，兼容历史 REAL/INTEGER 列及 numeric/text 列
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = NewMoney(v)
+	case float64:
+		*m = NewMoneyFromFloat(v)
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	default:
+		return fmt.Errorf("无法将 %T 转换为金额", value)
+	}
+	return nil
+}
+
+// GormDataType 通用数据类型
+func (Money) GormDataType() string {
+	return "decimal"
+}
+
+// GormDBDataType 按数据库方言返回列类型
+func (Money) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "sqlite":
+		return "decimal(20,4)"
+	default:
+		return "numeric(20,4)"
+	}
+}
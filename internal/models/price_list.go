@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// 价格表类型
+const (
+	PriceListTypeSelling = "selling"
+	PriceListTypeBuying  = "buying"
+)
+
+// 价格表分配对象
+const (
+	PriceListPartyCustomer      = "customer"
+	PriceListPartyCustomerGroup = "customer_group"
+	PriceListPartySupplier      = "supplier"
+)
+
+// PriceList 价格表，销售价格表用于报价单与销售订单，采购价格表用于采购订单；
+// 有效期为空表示不限，每种类型可设一个默认价格表，未分配价格表的客户与供应商使用默认价格表
+type PriceList struct {
+	BaseModel
+	Code          string     `json:"code" gorm:"uniqueIndex;size:50;not null"`
+	Name          string     `json:"name" gorm:"size:100;not null"`
+	PriceListType string     `json:"price_list_type" gorm:"size:20;not null;index"` // selling, buying
+	Currency      string     `json:"currency" gorm:"size:10;default:'CNY'"`
+	ValidFrom     *time.Time `json:"valid_from,omitempty"`
+	ValidTo       *time.Time `json:"valid_to,omitempty"`
+	IsDefault     bool       `json:"is_default" gorm:"default:false"`
+	IsActive      bool       `json:"is_active" gorm:"default:true"`
+	Description   string     `json:"description,omitempty" gorm:"type:text"`
+
+	// 关联
+	Prices []ItemPrice `json:"prices,omitempty" gorm:"foreignKey:PriceListID"`
+}
+
+// IsValidAt 价格表在指定日期是否启用且在有效期内，有效期按日期比较
+func (p *PriceList) IsValidAt(at time.Time) bool {
+	return p.IsActive && withinValidity(p.ValidFrom, p.ValidTo, at)
+}
+
+// ItemPrice 物料在价格表中的价格。同一物料可按不同单位、起订数量与有效期设置多条价格，
+// 取价时选用满足数量且起订数量最大的价格，实现数量阶梯价
+type ItemPrice struct {
+	BaseModel
+	PriceListID uint       `json:"price_list_id" gorm:"index;not null"`
+	ItemID      uint       `json:"item_id" gorm:"index;not null"`
+	UOM         string     `json:"uom,omitempty" gorm:"size:50"` // 价格对应的单位，为空时为库存单位
+	MinQty      float64    `json:"min_qty" gorm:"default:0"`     // 起订数量（价格单位），达到该数量时适用
+	Rate        Money      `json:"rate" gorm:"not null"`
+	ValidFrom   *time.Time `json:"valid_from,omitempty"`
+	ValidTo     *time.Time `json:"valid_to,omitempty"`
+
+	// 关联
+	PriceList *PriceList `json:"price_list,omitempty" gorm:"foreignKey:PriceListID"`
+	Item      *Item      `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+}
+
+// IsValidAt 价格在指定日期是否在有效期内
+func (p *ItemPrice) IsValidAt(at time.Time) bool {
+	return withinValidity(p.ValidFrom, p.ValidTo, at)
+}
+
+// PriceListAssignment 价格表分配：客户、客户组（Customer.CustomerGroup）或供应商使用的价格表。
+// 取价时客户的分配优先于客户组的分配；同一对象同一类型只能分配一个价格表
+type PriceListAssignment struct {
+	BaseModel
+	PriceListID   uint   `json:"price_list_id" gorm:"index;not null"`
+	PartyType     string `json:"party_type" gorm:"size:20;not null;index"` // customer, customer_group, supplier
+	PartyID       *uint  `json:"party_id,omitempty" gorm:"index"`          // 客户或供应商ID
+	CustomerGroup string `json:"customer_group,omitempty" gorm:"size:100;index"`
+
+	// 关联
+	PriceList *PriceList `json:"price_list,omitempty" gorm:"foreignKey:PriceListID"`
+}
+
+// withinValidity 日期是否落在有效期内，起止日期为空表示不限
+func withinValidity(from, to *time.Time, at time.Time) bool {
+	day := StartOfDay(at)
+	if from != nil && day.Before(StartOfDay(*from)) {
+		return false
+	}
+	if to != nil && day.After(StartOfDay(*to)) {
+		return false
+	}
+	return true
+}
@@ -7,14 +7,14 @@ import (
 // Product 产品模型
 type Product struct {
 	BaseModel
-	Code        string  `json:"code" gorm:"uniqueIndex;size:100;not null"`
-	Name        string  `json:"name" gorm:"size:255;not null"`
-	Description string  `json:"description" gorm:"type:text"`
-	Category    string  `json:"category" gorm:"size:100;not null;index"`
-	Unit        string  `json:"unit" gorm:"size:50;not null"`
-	Price       float64 `json:"price" gorm:"default:0"`
-	Cost        float64 `json:"cost" gorm:"default:0"`
-	Status      string  `json:"status" gorm:"size:50;default:'active';index"`
+	Code        string `json:"code" gorm:"uniqueIndex;size:100;not null"`
+	Name        string `json:"name" gorm:"size:255;not null"`
+	Description string `json:"description" gorm:"type:text"`
+	Category    string `json:"category" gorm:"size:100;not null;index"`
+	Unit        string `json:"unit" gorm:"size:50;not null"`
+	Price       Money  `json:"price" gorm:"default:0"`
+	Cost        Money  `json:"cost" gorm:"default:0"`
+	Status      string `json:"status" gorm:"size:50;default:'active';index"`
 }
 
 // BOM 物料清单模型
@@ -39,8 +39,8 @@ type BOMItem struct {
 	BOMID     uint    `json:"bom_id" gorm:"index;not null"`
 	ItemID    uint    `json:"item_id" gorm:"index;not null"`
 	Quantity  float64 `json:"quantity" gorm:"not null"`
-	UnitCost  float64 `json:"unit_cost,omitempty" gorm:"default:0"`
-	TotalCost float64 `json:"total_cost,omitempty" gorm:"default:0"`
+	UnitCost  Money   `json:"unit_cost,omitempty" gorm:"default:0"`
+	TotalCost Money   `json:"total_cost,omitempty" gorm:"default:0"`
 	ScrapRate float64 `json:"scrap_rate,omitempty" gorm:"default:0"` // 损耗率
 	Notes     string  `json:"notes,omitempty" gorm:"type:text"`
 
@@ -99,7 +99,7 @@ type Operation struct {
 	WorkCenterID uint    `json:"work_center_id" gorm:"index;not null"`
 	SetupTime    float64 `json:"setup_time" gorm:"default:0"`    // 准备时间（小时）
 	RunTime      float64 `json:"run_time" gorm:"default:0"`      // 运行时间（小时/单位）
-	StandardCost float64 `json:"standard_cost" gorm:"default:0"` // 标准成本（元/小时）
+	StandardCost Money   `json:"standard_cost" gorm:"default:0"` // 标准成本（元/小时）
 
 	// 关联
 	WorkCenter          WorkCenter           `json:"work_center,omitempty" gorm:"foreignKey:WorkCenterID"`
@@ -112,7 +112,7 @@ type WorkCenter struct {
 	WorkCenterType string  `json:"work_center_type" gorm:"size:50;not null;index"` // machine, manual, assembly
 	Capacity       float64 `json:"capacity" gorm:"default:8"`                      // 产能（小时/天）
 	Efficiency     float64 `json:"efficiency" gorm:"default:100"`                  // 效率（%）
-	CostPerHour    float64 `json:"cost_per_hour" gorm:"default:0"`                 // 每小时成本
+	CostPerHour    Money   `json:"cost_per_hour" gorm:"default:0"`                 // 每小时成本
 
 	// 关联
 	Operations []Operation `json:"operations,omitempty" gorm:"foreignKey:WorkCenterID"`
@@ -127,8 +127,8 @@ type WorkOrderMaterial struct {
 	IssuedQty   float64 `json:"issued_qty" gorm:"default:0"`
 	ConsumedQty float64 `json:"consumed_qty" gorm:"default:0"`
 	ReturnedQty float64 `json:"returned_qty" gorm:"default:0"`
-	UnitCost    float64 `json:"unit_cost" gorm:"default:0"`
-	TotalCost   float64 `json:"total_cost" gorm:"default:0"`
+	UnitCost    Money   `json:"unit_cost" gorm:"default:0"`
+	TotalCost   Money   `json:"total_cost" gorm:"default:0"`
 	Status      string  `json:"status" gorm:"size:50;default:'pending';index"` // pending, issued, consumed, returned
 
 	// 关联
@@ -375,7 +375,7 @@ type EquipmentMaintenance struct {
 	ScheduledDate     time.Time  `json:"scheduled_date" gorm:"index;not null"`
 	ActualDate        *time.Time `json:"actual_date,omitempty" gorm:"index"`
 	Duration          float64    `json:"duration" gorm:"default:0"` // 维护时长（小时）
-	Cost              float64    `json:"cost" gorm:"default:0"`
+	Cost              Money      `json:"cost" gorm:"default:0"`
 	TechnicianID      uint       `json:"technician_id" gorm:"index;not null"`
 	Description       string     `json:"description" gorm:"type:text;not null"`
 	PartsUsed         string     `json:"parts_used" gorm:"type:text"`
@@ -396,7 +396,7 @@ type EquipmentFailure struct {
 	Description   string     `json:"description" gorm:"type:text;not null"`
 	CauseAnalysis string     `json:"cause_analysis" gorm:"type:text"`
 	RepairAction  string     `json:"repair_action" gorm:"type:text"`
-	RepairCost    float64    `json:"repair_cost" gorm:"default:0"`
+	RepairCost    Money      `json:"repair_cost" gorm:"default:0"`
 	DowntimeHours float64    `json:"downtime_hours" gorm:"default:0"`
 	TechnicianID  *uint      `json:"technician_id,omitempty" gorm:"index"`
 	ReportedBy    uint       `json:"reported_by" gorm:"index;not null"`
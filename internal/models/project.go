@@ -16,8 +16,8 @@ type Project struct {
 	EndDate         time.Time  `json:"end_date" gorm:"index;not null"`
 	ActualStartDate *time.Time `json:"actual_start_date,omitempty"`
 	ActualEndDate   *time.Time `json:"actual_end_date,omitempty"`
-	Budget          float64    `json:"budget" gorm:"default:0"`
-	ActualCost      float64    `json:"actual_cost" gorm:"default:0"`
+	Budget          Money      `json:"budget" gorm:"default:0"`
+	ActualCost      Money      `json:"actual_cost" gorm:"default:0"`
 	Progress        float64    `json:"progress" gorm:"default:0"`                      // 进度百分比
 	Priority        string     `json:"priority" gorm:"size:20;default:'normal';index"` // low, normal, high, urgent
 	Status          string     `json:"status" gorm:"size:50;default:'planning';index"` // planning, active, on_hold, completed, cancelled
@@ -85,8 +85,8 @@ type TimeEntry struct {
 	Hours       float64   `json:"hours" gorm:"not null"`
 	Description string    `json:"description,omitempty" gorm:"type:text"`
 	IsBillable  bool      `json:"is_billable" gorm:"default:true;index"`
-	HourlyRate  float64   `json:"hourly_rate,omitempty" gorm:"default:0"`
-	Amount      float64   `json:"amount,omitempty" gorm:"default:0"`
+	HourlyRate  Money     `json:"hourly_rate,omitempty" gorm:"default:0"`
+	Amount      Money     `json:"amount,omitempty" gorm:"default:0"`
 	Status      string    `json:"status" gorm:"size:50;default:'draft';index"` // draft, submitted, approved, billed
 
 	// 关联
@@ -113,7 +113,7 @@ type ProjectExpense struct {
 	ProjectID      uint       `json:"project_id" gorm:"index;not null"`
 	ExpenseDate    time.Time  `json:"expense_date" gorm:"index;not null"`
 	ExpenseType    string     `json:"expense_type" gorm:"size:100;not null;index"` // travel, material, equipment, other
-	Amount         float64    `json:"amount" gorm:"not null"`
+	Amount         Money      `json:"amount" gorm:"not null"`
 	Currency       string     `json:"currency" gorm:"size:10;default:'CNY'"`
 	Description    string     `json:"description" gorm:"type:text;not null"`
 	Receipt        string     `json:"receipt,omitempty" gorm:"size:500"`
@@ -175,8 +175,8 @@ type ProjectResource struct {
 	ResourceID   uint       `json:"resource_id" gorm:"index;not null"`
 	Quantity     float64    `json:"quantity" gorm:"default:1"`
 	Unit         string     `json:"unit" gorm:"size:50"`
-	CostPerUnit  float64    `json:"cost_per_unit" gorm:"default:0"`
-	TotalCost    float64    `json:"total_cost" gorm:"default:0"`
+	CostPerUnit  Money      `json:"cost_per_unit" gorm:"default:0"`
+	TotalCost    Money      `json:"total_cost" gorm:"default:0"`
 	AllocatedAt  time.Time  `json:"allocated_at" gorm:"index;not null"`
 	ReleasedAt   *time.Time `json:"released_at,omitempty" gorm:"index"`
 	Status       string     `json:"status" gorm:"size:50;default:'allocated';index"` // allocated, in_use, released
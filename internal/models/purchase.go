@@ -17,20 +17,29 @@ type Supplier struct {
 	PostalCode     string  `json:"postal_code,omitempty"`
 	Country        string  `json:"country,omitempty"`
 	ContactPerson  string  `json:"contact_person,omitempty"`
-	CreditLimit    float64 `json:"credit_limit" gorm:"default:0"`
+	CreditLimit    Money   `json:"credit_limit" gorm:"default:0"`
 	SupplierGroup  string  `json:"supplier_group,omitempty"`
 	Territory      string  `json:"territory,omitempty"`
 	QualityRating  float64 `json:"quality_rating" gorm:"default:0"`
 	DeliveryRating float64 `json:"delivery_rating" gorm:"default:0"`
 	IsActive       bool    `json:"is_active" gorm:"default:true"`
+	// 内部供应商：代表集团内的兄弟公司，用于内部交易单据镜像
+	RepresentsCompanyID *uint `json:"represents_company_id,omitempty" gorm:"index"`
 
 	// 关联关系
 	PurchaseOrders []PurchaseOrder `json:"purchase_orders,omitempty" gorm:"foreignKey:SupplierID"`
 }
 
+// 采购申请来源
+const (
+	PurchaseRequestSourceManual        = "manual"
+	PurchaseRequestSourceReplenishment = "replenishment"
+)
+
 // PurchaseRequest 采购申请模型
 type PurchaseRequest struct {
 	BaseModel
+	CompanyID     uint      `json:"company_id" gorm:"index;not null;default:1"`
 	RequestNumber string    `json:"request_number" gorm:"uniqueIndex;not null"`
 	Title         string    `json:"title" gorm:"not null"`
 	Description   string    `json:"description,omitempty"`
@@ -39,12 +48,15 @@ type PurchaseRequest struct {
 	RequiredBy    time.Time `json:"required_by" gorm:"not null"`
 	Department    string    `json:"department,omitempty"`
 	Status        string    `json:"status" gorm:"default:'draft'"`
+	Source        string    `json:"source" gorm:"size:20;default:'manual';index"` // manual 手工创建，replenishment 补货生成
+	SupplierID    *uint     `json:"supplier_id,omitempty" gorm:"index"`           // 建议供应商
 	Notes         string    `json:"notes,omitempty"`
 	CreatedBy     uint      `json:"created_by,omitempty"`
 	ApprovedBy    *uint     `json:"approved_by,omitempty"`
 
 	// 关联
-	Items []PurchaseRequestItem `json:"items,omitempty" gorm:"foreignKey:PurchaseRequestID"`
+	Supplier *Supplier             `json:"supplier,omitempty" gorm:"foreignKey:SupplierID"`
+	Items    []PurchaseRequestItem `json:"items,omitempty" gorm:"foreignKey:PurchaseRequestID"`
 }
 
 // PurchaseRequestItem 采购申请明细模型
@@ -55,7 +67,9 @@ type PurchaseRequestItem struct {
 	Description       string  `json:"description,omitempty"`
 	Quantity          float64 `json:"quantity" gorm:"default:1"`
 	UOM               string  `json:"uom,omitempty"`
-	EstimatedCost     float64 `json:"estimated_cost" gorm:"default:0"`
+	ConversionFactor  float64 `json:"conversion_factor" gorm:"default:1"`  // 1 个申请单位折合的库存单位数量
+	EstimatedCost     Money   `json:"estimated_cost" gorm:"default:0"`     // 按申请单位的预估单价
+	WarehouseID       *uint   `json:"warehouse_id,omitempty" gorm:"index"` // 需求仓库
 	Notes             string  `json:"notes,omitempty"`
 
 	// 关联
@@ -66,16 +80,18 @@ type PurchaseRequestItem struct {
 // PurchaseOrder 采购订单模型
 type PurchaseOrder struct {
 	BaseModel
+	CompanyID         uint      `json:"company_id" gorm:"index;not null;default:1"`
 	OrderNumber       string    `json:"order_number" gorm:"uniqueIndex;not null"`
 	SupplierID        uint      `json:"supplier_id" gorm:"not null"`
 	OrderDate         time.Time `json:"order_date" gorm:"not null"`
 	DeliveryDate      time.Time `json:"delivery_date" gorm:"not null"`
 	Status            string    `json:"status" gorm:"default:'Draft'"`
 	PurchaseRequestID *uint     `json:"purchase_request_id,omitempty"`
-	TotalAmount       float64   `json:"total_amount" gorm:"default:0"`
-	DiscountAmount    float64   `json:"discount_amount" gorm:"default:0"`
-	TaxAmount         float64   `json:"tax_amount" gorm:"default:0"`
-	GrandTotal        float64   `json:"grand_total" gorm:"default:0"`
+	PriceListID       *uint     `json:"price_list_id,omitempty" gorm:"index"` // 明细取价使用的采购价格表
+	TotalAmount       Money     `json:"total_amount" gorm:"default:0"`
+	DiscountAmount    Money     `json:"discount_amount" gorm:"default:0"`
+	TaxAmount         Money     `json:"tax_amount" gorm:"default:0"`
+	GrandTotal        Money     `json:"grand_total" gorm:"default:0"`
 	Terms             string    `json:"terms,omitempty"`
 	Notes             string    `json:"notes,omitempty"`
 	CreatedBy         uint      `json:"created_by,omitempty"`
@@ -95,13 +111,14 @@ type PurchaseOrderItem struct {
 	Description     string  `json:"description,omitempty"`
 	Quantity        float64 `json:"quantity" gorm:"default:1"`
 	ReceivedQty     float64 `json:"received_qty" gorm:"default:0"`
-	Rate            float64 `json:"rate" gorm:"default:0"`
-	Amount          float64 `json:"amount" gorm:"default:0"`
+	PriceListRate   Money   `json:"price_list_rate" gorm:"default:0"` // 价格表价格，单价为空时按此计价
+	Rate            Money   `json:"rate" gorm:"default:0"`
+	Amount          Money   `json:"amount" gorm:"default:0"`
 	DiscountRate    float64 `json:"discount_rate" gorm:"default:0"`
-	DiscountAmount  float64 `json:"discount_amount" gorm:"default:0"`
+	DiscountAmount  Money   `json:"discount_amount" gorm:"default:0"`
 	TaxRate         float64 `json:"tax_rate" gorm:"default:0"`
-	TaxAmount       float64 `json:"tax_amount" gorm:"default:0"`
-	TotalAmount     float64 `json:"total_amount" gorm:"default:0"`
+	TaxAmount       Money   `json:"tax_amount" gorm:"default:0"`
+	TotalAmount     Money   `json:"total_amount" gorm:"default:0"`
 	WarehouseID     *uint   `json:"warehouse_id,omitempty"`
 
 	// 关联
@@ -113,6 +130,7 @@ type PurchaseOrderItem struct {
 // PurchaseReceipt 采购收货模型
 type PurchaseReceipt struct {
 	BaseModel
+	CompanyID       uint      `json:"company_id" gorm:"index;not null;default:1"`
 	ReceiptNumber   string    `json:"receipt_number" gorm:"uniqueIndex;not null"`
 	SupplierID      uint      `json:"supplier_id" gorm:"not null"`
 	PurchaseOrderID *uint     `json:"purchase_order_id,omitempty"`
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// 库存预留状态
+const (
+	ReservationStatusActive    = "active"    // 有效，按优先级分配库存
+	ReservationStatusReleased  = "released"  // 订单取消或关闭后释放
+	ReservationStatusFulfilled = "fulfilled" // 已全部发货
+)
+
+// StockReservation 销售订单行的库存预留。Quantity 为尚未发货的需求数量，ReservedQty 为按优先级
+// 实际分配到的库存数量，库存不足时 ReservedQty 小于 Quantity
+type StockReservation struct {
+	BaseModel
+	SalesOrderID     uint       `json:"sales_order_id" gorm:"index;not null"`
+	SalesOrderItemID uint       `json:"sales_order_item_id" gorm:"uniqueIndex;not null"`
+	ItemID           uint       `json:"item_id" gorm:"index:idx_stock_reservations_item_warehouse;not null"`
+	WarehouseID      uint       `json:"warehouse_id" gorm:"index:idx_stock_reservations_item_warehouse;not null"`
+	Quantity         float64    `json:"quantity" gorm:"default:0"`
+	ReservedQty      float64    `json:"reserved_qty" gorm:"default:0"`
+	DeliveredQty     float64    `json:"delivered_qty" gorm:"default:0"`
+	Status           string     `json:"status" gorm:"size:20;default:'active';index"`
+	ReleasedAt       *time.Time `json:"released_at,omitempty"`
+
+	// 关联
+	SalesOrder *SalesOrder `json:"sales_order,omitempty" gorm:"foreignKey:SalesOrderID"`
+	Item       *Item       `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+	Warehouse  *Warehouse  `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
+}
+
+// ShortageQty 库存不足未能分配的数量
+func (r *StockReservation) ShortageQty() float64 {
+	if r.Status != ReservationStatusActive || r.ReservedQty >= r.Quantity {
+		return 0
+	}
+	return r.Quantity - r.ReservedQty
+}
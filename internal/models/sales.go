@@ -7,43 +7,56 @@ import (
 // Customer 客户模型
 type Customer struct {
 	BaseModel
-	Name          string  `json:"name" gorm:"not null"`
-	Code          string  `json:"code" gorm:"uniqueIndex;not null"`
-	Email         string  `json:"email,omitempty"`
-	Phone         string  `json:"phone,omitempty"`
-	Address       string  `json:"address,omitempty"`
-	City          string  `json:"city,omitempty"`
-	State         string  `json:"state,omitempty"`
-	PostalCode    string  `json:"postal_code,omitempty"`
-	Country       string  `json:"country,omitempty"`
-	ContactPerson string  `json:"contact_person,omitempty"`
-	CreditLimit   float64 `json:"credit_limit" gorm:"default:0"`
-	CustomerGroup string  `json:"customer_group,omitempty"`
-	Territory     string  `json:"territory,omitempty"`
-	IsActive      bool    `json:"is_active" gorm:"default:true"`
+	Name          string `json:"name" gorm:"not null"`
+	Code          string `json:"code" gorm:"uniqueIndex;not null"`
+	Email         string `json:"email,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+	Address       string `json:"address,omitempty"`
+	City          string `json:"city,omitempty"`
+	State         string `json:"state,omitempty"`
+	PostalCode    string `json:"postal_code,omitempty"`
+	Country       string `json:"country,omitempty"`
+	ContactPerson string `json:"contact_person,omitempty"`
+	CreditLimit   Money  `json:"credit_limit" gorm:"default:0"`
+	CustomerGroup string `json:"customer_group,omitempty"`
+	Territory     string `json:"territory,omitempty"`
+	IsActive      bool   `json:"is_active" gorm:"default:true"`
+	// 内部客户：代表集团内的兄弟公司，用于内部交易单据镜像
+	RepresentsCompanyID *uint `json:"represents_company_id,omitempty" gorm:"index"`
 
 	// 关联关系
 	Quotations  []Quotation  `json:"quotations,omitempty" gorm:"foreignKey:CustomerID"`
 	SalesOrders []SalesOrder `json:"salesOrders,omitempty" gorm:"foreignKey:CustomerID"`
 }
 
+// 报价单状态
+const (
+	QuotationStatusDraft            = "draft"
+	QuotationStatusPartiallyOrdered = "partially_ordered"
+	QuotationStatusOrdered          = "ordered"
+	QuotationStatusCancelled        = "cancelled"
+)
+
 // Quotation 报价单模型
 type Quotation struct {
 	BaseModel
-	QuotationNumber string    `json:"quotation_number" gorm:"uniqueIndex;not null"`
-	CustomerID      uint      `json:"customer_id" gorm:"not null"`
-	TemplateID      *uint     `json:"template_id,omitempty"`
-	Date            time.Time `json:"date" gorm:"not null"`
-	ValidTill       time.Time `json:"valid_till" gorm:"not null"`
-	Status          string    `json:"status" gorm:"default:'Draft'"`
-	Subject         string    `json:"subject,omitempty"`
-	TotalAmount     float64   `json:"total_amount" gorm:"default:0"`
-	DiscountAmount  float64   `json:"discount_amount" gorm:"default:0"`
-	TaxAmount       float64   `json:"tax_amount" gorm:"default:0"`
-	GrandTotal      float64   `json:"grand_total" gorm:"default:0"`
-	Terms           string    `json:"terms,omitempty"`
-	Notes           string    `json:"notes,omitempty"`
-	CreatedBy       uint      `json:"created_by,omitempty"`
+	CompanyID         uint      `json:"company_id" gorm:"index;not null;default:1"`
+	QuotationNumber   string    `json:"quotation_number" gorm:"uniqueIndex;not null"`
+	CustomerID        uint      `json:"customer_id" gorm:"not null"`
+	TemplateID        *uint     `json:"template_id,omitempty"`
+	PriceListID       *uint     `json:"price_list_id,omitempty" gorm:"index"` // 明细取价使用的销售价格表
+	Date              time.Time `json:"date" gorm:"not null"`
+	ValidTill         time.Time `json:"valid_till" gorm:"not null"`
+	Status            string    `json:"status" gorm:"default:'Draft'"`
+	StatusBeforeOrder string    `json:"status_before_order,omitempty"` // 首次转为销售订单前的状态，订单全部取消后据此恢复
+	Subject           string    `json:"subject,omitempty"`
+	TotalAmount       Money     `json:"total_amount" gorm:"default:0"`
+	DiscountAmount    Money     `json:"discount_amount" gorm:"default:0"`
+	TaxAmount         Money     `json:"tax_amount" gorm:"default:0"`
+	GrandTotal        Money     `json:"grand_total" gorm:"default:0"`
+	Terms             string    `json:"terms,omitempty"`
+	Notes             string    `json:"notes,omitempty"`
+	CreatedBy         uint      `json:"created_by,omitempty"`
 
 	// 版本管理字段
 	CurrentVersion int  `json:"current_version" gorm:"default:1"`
@@ -64,70 +77,118 @@ type QuotationItem struct {
 	ItemID         uint    `json:"item_id" gorm:"not null"`
 	Description    string  `json:"description,omitempty"`
 	Quantity       float64 `json:"quantity" gorm:"default:1"`
-	Rate           float64 `json:"rate" gorm:"default:0"`
-	Amount         float64 `json:"amount" gorm:"default:0"`
+	OrderedQty     float64 `json:"ordered_qty" gorm:"default:0"`     // 已转为销售订单的数量，不含已取消的订单
+	PriceListRate  Money   `json:"price_list_rate" gorm:"default:0"` // 价格表价格，单价为空时按此计价
+	Rate           Money   `json:"rate" gorm:"default:0"`
+	Amount         Money   `json:"amount" gorm:"default:0"`
 	DiscountRate   float64 `json:"discount_rate" gorm:"default:0"`
-	DiscountAmount float64 `json:"discount_amount" gorm:"default:0"`
+	DiscountAmount Money   `json:"discount_amount" gorm:"default:0"`
 	TaxRate        float64 `json:"tax_rate" gorm:"default:0"`
-	TaxAmount      float64 `json:"tax_amount" gorm:"default:0"`
-	TotalAmount    float64 `json:"total_amount" gorm:"default:0"`
+	TaxAmount      Money   `json:"tax_amount" gorm:"default:0"`
+	TotalAmount    Money   `json:"total_amount" gorm:"default:0"`
 
 	// 关联
 	Quotation Quotation `json:"quotation,omitempty" gorm:"foreignKey:QuotationID"`
 	Item      Item      `json:"item,omitempty" gorm:"foreignKey:ItemID"`
 }
 
+// 销售订单状态，历史数据中的大写或带空格写法比较前先规范化
+const (
+	SalesOrderStatusDraft              = "draft"
+	SalesOrderStatusConfirmed          = "confirmed"
+	SalesOrderStatusOnHold             = "on_hold"
+	SalesOrderStatusPartiallyDelivered = "partially_delivered"
+	SalesOrderStatusDelivered          = "delivered"
+	SalesOrderStatusPartiallyBilled    = "partially_billed"
+	SalesOrderStatusCompleted          = "completed"
+	SalesOrderStatusClosed             = "closed"
+	SalesOrderStatusCancelled          = "cancelled"
+)
+
 // SalesOrder 销售订单模型
 type SalesOrder struct {
 	BaseModel
+	CompanyID      uint      `json:"company_id" gorm:"index;not null;default:1"`
 	OrderNumber    string    `json:"order_number" gorm:"uniqueIndex;not null"`
 	CustomerID     uint      `json:"customer_id" gorm:"not null"`
 	Date           time.Time `json:"date" gorm:"not null"`
 	DeliveryDate   time.Time `json:"delivery_date" gorm:"not null"`
 	Status         string    `json:"status" gorm:"default:'Draft'"`
+	Priority       int       `json:"priority" gorm:"default:0"` // 库存不足时优先级高的订单先分配预留
 	QuotationID    *uint     `json:"quotation_id,omitempty"`
-	TotalAmount    float64   `json:"total_amount" gorm:"default:0"`
-	DiscountAmount float64   `json:"discount_amount" gorm:"default:0"`
-	TaxAmount      float64   `json:"tax_amount" gorm:"default:0"`
-	GrandTotal     float64   `json:"grand_total" gorm:"default:0"`
+	PriceListID    *uint     `json:"price_list_id,omitempty" gorm:"index"` // 明细取价使用的销售价格表
+	Currency       string    `json:"currency" gorm:"size:10;default:'CNY'"`
+	ExchangeRate   float64   `json:"exchange_rate" gorm:"default:1"` // 订单币种折合本位币的汇率
+	TotalAmount    Money     `json:"total_amount" gorm:"default:0"`
+	DiscountAmount Money     `json:"discount_amount" gorm:"default:0"` // 明细折扣合计，超出部分为整单折扣
+	TaxAmount      Money     `json:"tax_amount" gorm:"default:0"`      // 明细税额合计，超出部分为整单税额
+	GrandTotal     Money     `json:"grand_total" gorm:"default:0"`
 	Terms          string    `json:"terms,omitempty"`
 	Notes          string    `json:"notes,omitempty"`
 	CreatedBy      uint      `json:"created_by,omitempty"`
 
 	// 关联
-	Customer      Customer         `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
-	CreatedByUser *User            `json:"created_by_user,omitempty" gorm:"foreignKey:CreatedBy"`
-	Quotation     *Quotation       `json:"quotation,omitempty" gorm:"foreignKey:QuotationID"`
-	Items         []SalesOrderItem `json:"items,omitempty" gorm:"foreignKey:SalesOrderID"`
+	Customer      Customer              `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
+	CreatedByUser *User                 `json:"created_by_user,omitempty" gorm:"foreignKey:CreatedBy"`
+	Quotation     *Quotation            `json:"quotation,omitempty" gorm:"foreignKey:QuotationID"`
+	Items         []SalesOrderItem      `json:"items,omitempty" gorm:"foreignKey:SalesOrderID"`
+	StatusLogs    []SalesOrderStatusLog `json:"status_logs,omitempty" gorm:"foreignKey:SalesOrderID"`
 }
 
 // SalesOrderItem 销售订单明细模型
 type SalesOrderItem struct {
 	BaseModel
-	SalesOrderID   uint    `json:"sales_order_id" gorm:"not null"`
-	ItemID         uint    `json:"item_id" gorm:"not null"`
-	Description    string  `json:"description,omitempty"`
-	Quantity       float64 `json:"quantity" gorm:"default:1"`
-	DeliveredQty   float64 `json:"delivered_qty" gorm:"default:0"`
-	Rate           float64 `json:"rate" gorm:"default:0"`
-	Amount         float64 `json:"amount" gorm:"default:0"`
-	DiscountRate   float64 `json:"discount_rate" gorm:"default:0"`
-	DiscountAmount float64 `json:"discount_amount" gorm:"default:0"`
-	TaxRate        float64 `json:"tax_rate" gorm:"default:0"`
-	TaxAmount      float64 `json:"tax_amount" gorm:"default:0"`
-	TotalAmount    float64 `json:"total_amount" gorm:"default:0"`
-	WarehouseID    *uint   `json:"warehouse_id,omitempty"`
+	SalesOrderID    uint    `json:"sales_order_id" gorm:"not null"`
+	QuotationItemID *uint   `json:"quotation_item_id,omitempty" gorm:"index"` // 由报价单转入时对应的报价明细
+	ItemID          uint    `json:"item_id" gorm:"not null"`
+	Description     string  `json:"description,omitempty"`
+	Quantity        float64 `json:"quantity" gorm:"default:1"`
+	DeliveredQty    float64 `json:"delivered_qty" gorm:"default:0"`
+	BilledQty       float64 `json:"billed_qty" gorm:"default:0"`      // 已提交销售发票的开票数量
+	PriceListRate   Money   `json:"price_list_rate" gorm:"default:0"` // 价格表价格，单价为空时按此计价
+	Rate            Money   `json:"rate" gorm:"default:0"`
+	Amount          Money   `json:"amount" gorm:"default:0"`
+	DiscountRate    float64 `json:"discount_rate" gorm:"default:0"`
+	DiscountAmount  Money   `json:"discount_amount" gorm:"default:0"`
+	TaxRate         float64 `json:"tax_rate" gorm:"default:0"`
+	TaxAmount       Money   `json:"tax_amount" gorm:"default:0"`
+	TotalAmount     Money   `json:"total_amount" gorm:"default:0"`
+	WarehouseID     *uint   `json:"warehouse_id,omitempty"`
 
 	// 关联
 	SalesOrder SalesOrder `json:"sales_order,omitempty" gorm:"foreignKey:SalesOrderID"`
 	Item       Item       `json:"item,omitempty" gorm:"foreignKey:ItemID"`
 }
 
+// SalesOrderStatusLog 销售订单状态变更日志模型
+type SalesOrderStatusLog struct {
+	BaseModel
+	SalesOrderID uint      `json:"sales_order_id" gorm:"not null;index"`
+	FromStatus   string    `json:"from_status,omitempty"`
+	ToStatus     string    `json:"to_status" gorm:"not null"`
+	ChangedBy    uint      `json:"changed_by"` // 系统自动变更时为 0
+	ChangedAt    time.Time `json:"changed_at" gorm:"not null"`
+	Reason       string    `json:"reason,omitempty"`
+	Notes        string    `json:"notes,omitempty"`
+
+	// 关联
+	SalesOrder SalesOrder `json:"sales_order,omitempty" gorm:"foreignKey:SalesOrderID"`
+}
+
 // 注意：Delivery、DeliveryItem、Invoice、InvoiceItem模型已移除，因为数据库中没有对应的表
 
+// 送货单状态，提交时出库，已提交或已送达的送货单取消时冲销出库
+const (
+	DeliveryNoteStatusDraft     = "Draft"
+	DeliveryNoteStatusSubmitted = "Submitted"
+	DeliveryNoteStatusDelivered = "Delivered"
+	DeliveryNoteStatusCancelled = "Cancelled"
+)
+
 // DeliveryNote 送货单模型
 type DeliveryNote struct {
 	BaseModel
+	CompanyID      uint      `json:"company_id" gorm:"index;not null;default:1"`
 	DeliveryNumber string    `json:"delivery_number" gorm:"uniqueIndex;not null"`
 	CustomerID     uint      `json:"customer_id" gorm:"not null"`
 	SalesOrderID   *uint     `json:"sales_order_id,omitempty"`
@@ -154,7 +215,11 @@ type DeliveryNoteItem struct {
 	SalesOrderItemID *uint   `json:"sales_order_item_id,omitempty"`
 	ItemID           uint    `json:"item_id" gorm:"not null"`
 	Description      string  `json:"description,omitempty"`
-	Quantity         float64 `json:"quantity" gorm:"not null"`
+	Quantity         float64 `json:"quantity" gorm:"not null"` // 按发货单位的数量
+	UOM              string  `json:"uom,omitempty" gorm:"size:50"`
+	ConversionFactor float64 `json:"conversion_factor" gorm:"default:1"`
+	StockQty         float64 `json:"stock_qty"`                   // 换算后的库存单位数量
+	BilledQty        float64 `json:"billed_qty" gorm:"default:0"` // 已提交销售发票的开票数量，按库存单位计
 	BatchNo          string  `json:"batch_no,omitempty"`
 	SerialNo         string  `json:"serial_no,omitempty"`
 	WarehouseID      *uint   `json:"warehouse_id,omitempty"`
@@ -166,6 +231,14 @@ type DeliveryNoteItem struct {
 	Warehouse      *Warehouse      `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
 }
 
+// StockQuantity 返回库存单位的发货数量，引入单位换算前创建的明细按发货数量计
+func (i *DeliveryNoteItem) StockQuantity() float64 {
+	if i.StockQty > 0 {
+		return i.StockQty
+	}
+	return i.Quantity
+}
+
 // QuotationTemplate 报价单模板模型
 type QuotationTemplate struct {
 	BaseModel
@@ -191,7 +264,7 @@ type QuotationTemplateItem struct {
 	ItemID       uint    `json:"item_id" gorm:"not null"`
 	Description  string  `json:"description,omitempty"`
 	Quantity     float64 `json:"quantity" gorm:"default:1"`
-	Rate         float64 `json:"rate" gorm:"default:0"`
+	Rate         Money   `json:"rate" gorm:"default:0"`
 	DiscountRate float64 `json:"discount_rate" gorm:"default:0"`
 	TaxRate      float64 `json:"tax_rate" gorm:"default:0"`
 	SortOrder    int     `json:"sort_order" gorm:"default:0"`
@@ -216,6 +289,28 @@ type QuotationVersion struct {
 	Quotation Quotation `json:"quotation,omitempty" gorm:"foreignKey:QuotationID"`
 }
 
+// QuotationVersionData 报价单版本数据，创建版本时将报价单的条款与明细序列化为 JSON 存入 VersionData，
+// 转为销售订单时按活跃版本的数据生成订单
+type QuotationVersionData struct {
+	Subject     string                 `json:"subject,omitempty"`
+	Terms       string                 `json:"terms,omitempty"`
+	Notes       string                 `json:"notes,omitempty"`
+	PriceListID *uint                  `json:"price_list_id,omitempty"`
+	Items       []QuotationVersionLine `json:"items"`
+}
+
+// QuotationVersionLine 报价单版本中的一行明细
+type QuotationVersionLine struct {
+	QuotationItemID uint    `json:"quotation_item_id"`
+	ItemID          uint    `json:"item_id"`
+	Description     string  `json:"description,omitempty"`
+	Quantity        float64 `json:"quantity"`
+	PriceListRate   Money   `json:"price_list_rate"`
+	Rate            Money   `json:"rate"`
+	DiscountRate    float64 `json:"discount_rate"`
+	TaxRate         float64 `json:"tax_rate"`
+}
+
 // QuotationVersionComparison 报价单版本比较结果
 type QuotationVersionComparison struct {
 	FieldName   string      `json:"field_name"`
@@ -4,9 +4,17 @@ import (
 	"time"
 )
 
+// 销售发票单据状态
+const (
+	SalesInvoiceDocStatusDraft     = "Draft"
+	SalesInvoiceDocStatusSubmitted = "Submitted"
+	SalesInvoiceDocStatusCancelled = "Cancelled"
+)
+
 // SalesInvoice 销售发票模型
 type SalesInvoice struct {
 	AuditableModel
+	CompanyID      uint      `json:"company_id" gorm:"index;not null;default:1"`
 	InvoiceNumber  string    `json:"invoice_number" gorm:"uniqueIndex;not null"`
 	CustomerID     uint      `json:"customer_id" gorm:"not null"`
 	SalesOrderID   *uint     `json:"sales_order_id,omitempty"`
@@ -24,13 +32,13 @@ type SalesInvoice struct {
 	ExchangeRate float64 `json:"exchange_rate" gorm:"default:1"`
 
 	// 金额字段
-	SubTotal          float64 `json:"sub_total" gorm:"default:0"`
-	DiscountAmount    float64 `json:"discount_amount" gorm:"default:0"`
-	TaxAmount         float64 `json:"tax_amount" gorm:"default:0"`
-	ShippingAmount    float64 `json:"shipping_amount" gorm:"default:0"`
-	GrandTotal        float64 `json:"grand_total" gorm:"default:0"`
-	OutstandingAmount float64 `json:"outstanding_amount" gorm:"default:0"`
-	PaidAmount        float64 `json:"paid_amount" gorm:"default:0"`
+	SubTotal          Money `json:"sub_total" gorm:"default:0"`
+	DiscountAmount    Money `json:"discount_amount" gorm:"default:0"`
+	TaxAmount         Money `json:"tax_amount" gorm:"default:0"`
+	ShippingAmount    Money `json:"shipping_amount" gorm:"default:0"`
+	GrandTotal        Money `json:"grand_total" gorm:"default:0"`
+	OutstandingAmount Money `json:"outstanding_amount" gorm:"default:0"`
+	PaidAmount        Money `json:"paid_amount" gorm:"default:0"`
 
 	// 地址信息
 	BillingAddress  string `json:"billing_address,omitempty"`
@@ -85,22 +93,22 @@ type SalesInvoiceItem struct {
 	StockUOM         string  `json:"stock_uom,omitempty"`
 
 	// 价格和金额
-	Rate          float64 `json:"rate" gorm:"not null"`
-	PriceListRate float64 `json:"price_list_rate" gorm:"default:0"`
-	Amount        float64 `json:"amount" gorm:"not null"`
+	Rate          Money `json:"rate" gorm:"not null"`
+	PriceListRate Money `json:"price_list_rate" gorm:"default:0"`
+	Amount        Money `json:"amount" gorm:"not null"`
 
 	// 折扣
 	DiscountPercentage float64 `json:"discount_percentage" gorm:"default:0"`
-	DiscountAmount     float64 `json:"discount_amount" gorm:"default:0"`
+	DiscountAmount     Money   `json:"discount_amount" gorm:"default:0"`
 
 	// 税费
 	TaxCategory string  `json:"tax_category,omitempty"`
 	TaxRate     float64 `json:"tax_rate" gorm:"default:0"`
-	TaxAmount   float64 `json:"tax_amount" gorm:"default:0"`
+	TaxAmount   Money   `json:"tax_amount" gorm:"default:0"`
 
 	// 总计
-	NetRate   float64 `json:"net_rate" gorm:"not null"`
-	NetAmount float64 `json:"net_amount" gorm:"not null"`
+	NetRate   Money `json:"net_rate" gorm:"not null"`
+	NetAmount Money `json:"net_amount" gorm:"not null"`
 
 	// 仓库和批次信息
 	WarehouseID *uint  `json:"warehouse_id,omitempty"`
@@ -119,6 +127,14 @@ type SalesInvoiceItem struct {
 	Warehouse        *Warehouse        `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
 }
 
+// StockQuantity 返回库存单位的开票数量，换算系数未设置时按 1 计
+func (i *SalesInvoiceItem) StockQuantity() float64 {
+	if i.ConversionFactor > 0 {
+		return i.Quantity * i.ConversionFactor
+	}
+	return i.Quantity
+}
+
 // InvoicePayment 发票付款记录模型
 type InvoicePayment struct {
 	AuditableModel
@@ -126,7 +142,7 @@ type InvoicePayment struct {
 	PaymentEntryID  *uint     `json:"payment_entry_id,omitempty"`
 	PaymentDate     time.Time `json:"payment_date" gorm:"not null"`
 	PaymentMethod   string    `json:"payment_method" gorm:"not null"`
-	Amount          float64   `json:"amount" gorm:"not null"`
+	Amount          Money     `json:"amount" gorm:"not null"`
 	Currency        string    `json:"currency" gorm:"default:'CNY'"`
 	ExchangeRate    float64   `json:"exchange_rate" gorm:"default:1"`
 	ReferenceNumber string    `json:"reference_number,omitempty"`
@@ -177,13 +193,13 @@ type PricingRule struct {
 	MaxQty float64 `json:"max_qty" gorm:"default:0"`
 
 	// 金额条件
-	MinAmount float64 `json:"min_amount" gorm:"default:0"`
-	MaxAmount float64 `json:"max_amount" gorm:"default:0"`
+	MinAmount Money `json:"min_amount" gorm:"default:0"`
+	MaxAmount Money `json:"max_amount" gorm:"default:0"`
 
 	// 价格/折扣设置
-	Rate               float64 `json:"rate" gorm:"default:0"`
+	Rate               Money   `json:"rate" gorm:"default:0"`
 	DiscountPercentage float64 `json:"discount_percentage" gorm:"default:0"`
-	DiscountAmount     float64 `json:"discount_amount" gorm:"default:0"`
+	DiscountAmount     Money   `json:"discount_amount" gorm:"default:0"`
 
 	// 有效期
 	ValidFrom time.Time `json:"valid_from" gorm:"not null"`
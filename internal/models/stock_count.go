@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// 盘点范围
+const (
+	StockCountTypeFull  = "full"  // 整仓盘点
+	StockCountTypeZone  = "zone"  // 按库区盘点，按库位与批次生成盘点行
+	StockCountTypeABC   = "abc"   // 按 ABC 分类盘点
+	StockCountTypeCycle = "cycle" // 按 ABC 分类频率生成的循环盘点
+)
+
+// 盘点单状态
+const (
+	StockCountStatusCounting        = "counting"         // 已冻结账面数量，录入实盘数量
+	StockCountStatusPendingApproval = "pending_approval" // 差异超过阈值，等待审批
+	StockCountStatusApproved        = "approved"         // 差异已确认，等待过账
+	StockCountStatusPosted          = "posted"           // 差异已过账为库存调整与总账凭证
+	StockCountStatusCancelled       = "cancelled"
+)
+
+// MovementReferenceStockCount 盘点差异过账的库存移动来源单据类型
+const MovementReferenceStockCount = "stock_count"
+
+// StockCount 盘点单：生成时冻结各盘点行的账面数量与单位成本，差异 = 实盘数量 − 冻结数量，
+// 过账时在当前库存上按差异调整，盘点期间发生的出入库不影响差异
+type StockCount struct {
+	BaseModel
+	CompanyID   uint      `json:"company_id" gorm:"index;not null;default:1"`
+	CountNumber string    `json:"count_number" gorm:"uniqueIndex;size:50;not null"`
+	WarehouseID uint      `json:"warehouse_id" gorm:"index;not null"`
+	CountType   string    `json:"count_type" gorm:"size:20;not null;index"`
+	Zone        string    `json:"zone,omitempty" gorm:"size:50"`
+	ABCClass    string    `json:"abc_class,omitempty" gorm:"size:1"`
+	Status      string    `json:"status" gorm:"size:20;default:'counting';index"`
+	SnapshotAt  time.Time `json:"snapshot_at" gorm:"not null"`
+	CountDate   time.Time `json:"count_date" gorm:"index;not null"` // 过账日期
+
+	// 审批阈值：任一行差异金额绝对值超过金额阈值，或差异数量超过冻结数量的百分比阈值时需要审批，0 表示不检查
+	ApprovalValueThreshold   Money   `json:"approval_value_threshold" gorm:"default:0"`
+	ApprovalPercentThreshold float64 `json:"approval_percent_threshold" gorm:"default:0"`
+	ApprovalRequired         bool    `json:"approval_required" gorm:"default:false"`
+
+	GainValue     Money      `json:"gain_value" gorm:"default:0"` // 盘盈金额
+	LossValue     Money      `json:"loss_value" gorm:"default:0"` // 盘亏金额（正数）
+	SubmittedAt   *time.Time `json:"submitted_at,omitempty"`
+	ApprovedBy    *uint      `json:"approved_by,omitempty"`
+	ApprovedAt    *time.Time `json:"approved_at,omitempty"`
+	PostedAt      *time.Time `json:"posted_at,omitempty"`
+	TransactionID *uint      `json:"transaction_id,omitempty" gorm:"index"` // 盘点差异总账凭证
+	Notes         string     `json:"notes,omitempty" gorm:"type:text"`
+	CreatedBy     *uint      `json:"created_by,omitempty"`
+
+	// 关联
+	Warehouse *Warehouse       `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
+	Items     []StockCountItem `json:"items,omitempty" gorm:"foreignKey:StockCountID"`
+}
+
+// StockCountItem 盘点行，按物料、库位（按库区盘点时）与批次区分；盘点中发现的账外物料以冻结数量 0 追加
+type StockCountItem struct {
+	BaseModel
+	StockCountID  uint       `json:"stock_count_id" gorm:"index;not null"`
+	ItemID        uint       `json:"item_id" gorm:"index;not null"`
+	LocationID    *uint      `json:"location_id,omitempty" gorm:"index"`
+	BatchID       *uint      `json:"batch_id,omitempty"`
+	BatchNo       string     `json:"batch_no,omitempty" gorm:"size:100"`
+	ABCClass      string     `json:"abc_class,omitempty" gorm:"size:1"`
+	ExpectedQty   float64    `json:"expected_qty" gorm:"default:0"`   // 生成盘点单时冻结的账面数量
+	ValuationRate Money      `json:"valuation_rate" gorm:"default:0"` // 冻结时的单位成本，用于估算差异金额
+	CountedQty    *float64   `json:"counted_qty,omitempty"`           // 为空表示尚未盘点，过账时不调整
+	VarianceQty   float64    `json:"variance_qty" gorm:"default:0"`
+	VarianceValue Money      `json:"variance_value" gorm:"default:0"`      // 过账前为估算金额，过账后为实际调整金额
+	SerialNo      string     `json:"serial_no,omitempty" gorm:"type:text"` // 序列号管理物料的盘盈或盘亏序列号
+	CountedAt     *time.Time `json:"counted_at,omitempty"`
+	CountedBy     *uint      `json:"counted_by,omitempty"`
+	MovementID    *uint      `json:"movement_id,omitempty"` // 差异过账的库存调整移动
+	Notes         string     `json:"notes,omitempty" gorm:"type:text"`
+
+	// 关联
+	Item     *Item     `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+	Location *Location `json:"location,omitempty" gorm:"foreignKey:LocationID"`
+}
+
+// IsCounted 是否已录入实盘数量
+func (i *StockCountItem) IsCounted() bool {
+	return i.CountedQty != nil
+}
@@ -4,14 +4,20 @@ import (
 	"time"
 )
 
+// DefaultCompanyID 默认公司ID，未指定公司的科目、凭证与单据归属于该公司
+const DefaultCompanyID uint = 1
+
 // Company 公司模型
 type Company struct {
 	DescriptionModel
-	Address string `json:"address,omitempty" gorm:"type:text"`
-	Phone   string `json:"phone,omitempty" gorm:"size:20"`
-	Email   string `json:"email,omitempty" gorm:"size:255"`
+	Address         string `json:"address,omitempty" gorm:"type:text"`
+	Phone           string `json:"phone,omitempty" gorm:"size:20"`
+	Email           string `json:"email,omitempty" gorm:"size:255"`
+	ParentID        *uint  `json:"parent_id,omitempty" gorm:"index"` // 上级公司（集团合并范围）
+	DefaultCurrency string `json:"default_currency" gorm:"size:10;default:'CNY'"`
 
 	// 关联
+	Parent      *Company     `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
 	Departments []Department `json:"departments,omitempty" gorm:"foreignKey:CompanyID"`
 	Users       []User       `json:"users,omitempty" gorm:"foreignKey:CompanyID"`
 }
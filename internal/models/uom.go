@@ -0,0 +1,68 @@
+package models
+
+import "math"
+
+// 计量单位舍入方式
+const (
+	UOMRoundingHalfUp = "half_up" // 四舍五入
+	UOMRoundingUp     = "up"      // 向上取整，如整箱发货
+	UOMRoundingDown   = "down"    // 向下取整
+)
+
+// uomRoundingTolerance 舍入前吸收浮点误差的容差，避免 2.9999999 向上取整为 3.01
+const uomRoundingTolerance = 1e-9
+
+// UOMCategory 计量单位类别（数量、重量、长度等），同一类别的单位之间按基准系数换算
+type UOMCategory struct {
+	BaseModel
+	Code        string `json:"code" gorm:"uniqueIndex;size:50;not null"`
+	Name        string `json:"name" gorm:"size:100;not null"`
+	Description string `json:"description,omitempty" gorm:"type:text"`
+
+	// 关联
+	UOMs []UOM `json:"uoms,omitempty" gorm:"foreignKey:CategoryID"`
+}
+
+// UOM 计量单位主数据，物料的库存单位与单据行单位都必须在此定义
+type UOM struct {
+	BaseModel
+	Code        string  `json:"code" gorm:"uniqueIndex;size:50;not null"`
+	Name        string  `json:"name" gorm:"size:100;not null"`
+	CategoryID  uint    `json:"category_id" gorm:"index;not null"`
+	BaseFactor  float64 `json:"base_factor" gorm:"default:1"`              // 1 个该单位折合类别基准单位的数量，如 kg 为 1 时 g 为 0.001
+	Precision   int     `json:"precision" gorm:"default:0"`                // 数量保留的小数位数
+	Rounding    string  `json:"rounding" gorm:"size:20;default:'half_up'"` // half_up, up, down
+	Description string  `json:"description,omitempty" gorm:"type:text"`
+	IsActive    bool    `json:"is_active" gorm:"default:true"`
+
+	// 关联
+	Category *UOMCategory `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+}
+
+// Round 按单位的小数位数与舍入方式舍入数量
+func (u *UOM) Round(quantity float64) float64 {
+	scale := math.Pow10(u.Precision)
+	scaled := quantity * scale
+	switch u.Rounding {
+	case UOMRoundingUp:
+		scaled = math.Ceil(scaled - uomRoundingTolerance)
+	case UOMRoundingDown:
+		scaled = math.Floor(scaled + uomRoundingTolerance)
+	default:
+		scaled = math.Round(scaled)
+	}
+	return scaled / scale
+}
+
+// ItemUOMConversion 物料的单位换算，如 1 箱 = 12 个、1 托盘 = 480 个；
+// 优先于同类别单位之间的基准系数换算，跨类别的单位（箱与个）必须在此定义
+type ItemUOMConversion struct {
+	BaseModel
+	ItemID uint    `json:"item_id" gorm:"uniqueIndex:idx_item_uom_conversions_item_uom;not null"`
+	UOMID  uint    `json:"uom_id" gorm:"uniqueIndex:idx_item_uom_conversions_item_uom;not null"`
+	Factor float64 `json:"factor" gorm:"not null"` // 1 个该单位折合的库存单位数量
+
+	// 关联
+	Item *Item `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+	UOM  *UOM  `json:"uom,omitempty" gorm:"foreignKey:UOMID"`
+}
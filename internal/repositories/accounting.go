@@ -2,6 +2,11 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
 	"github.com/galaxyerp/galaxyErp/internal/models"
 	"gorm.io/gorm"
 )
@@ -9,9 +14,12 @@ import (
 // AccountRepository 会计科目仓储接口
 type AccountRepository interface {
 	BaseRepository[models.Account]
-	GetByCode(ctx context.Context, code string) (*models.Account, error)
-	GetByType(ctx context.Context, accountType string, offset, limit int) ([]*models.Account, int64, error)
+	GetByCode(ctx context.Context, companyID uint, code string) (*models.Account, error)
+	GetByType(ctx context.Context, companyID uint, accountType string, offset, limit int) ([]*models.Account, int64, error)
+	GetByCompany(ctx context.Context, companyID uint, offset, limit int) ([]*models.Account, int64, error)
 	GetChildren(ctx context.Context, parentID uint) ([]*models.Account, error)
+	GetChartOfAccounts(ctx context.Context, companyID uint) ([]*models.Account, error)
+	Transaction(ctx context.Context, fn func(repo AccountRepository) error) error
 }
 
 // AccountRepositoryImpl 会计科目仓储实现
@@ -28,30 +36,37 @@ func NewAccountRepository(db *gorm.DB) AccountRepository {
 	}
 }
 
-// GetByCode 根据科目编码获取会计科目
-func (r *AccountRepositoryImpl) GetByCode(ctx context.Context, code string) (*models.Account, error) {
+// GetByCode 根据公司与科目编码获取会计科目，不存在时返回 nil
+func (r *AccountRepositoryImpl) GetByCode(ctx context.Context, companyID uint, code string) (*models.Account, error) {
 	var account models.Account
-	err := r.db.WithContext(ctx).Preload("Parent").Preload("Children").Where("code = ?", code).First(&account).Error
+	err := r.db.WithContext(ctx).Preload("Parent").Preload("Children").
+		Where("company_id = ? AND code = ?", companyID, code).First(&account).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &account, nil
 }
 
-// GetByType 根据科目类型获取会计科目
-func (r *AccountRepositoryImpl) GetByType(ctx context.Context, accountType string, offset, limit int) ([]*models.Account, int64, error) {
+// GetByType 根据科目类型获取会计科目，companyID 为 0 时不限公司
+func (r *AccountRepositoryImpl) GetByType(ctx context.Context, companyID uint, accountType string, offset, limit int) ([]*models.Account, int64, error) {
 	var accounts []*models.Account
 	var total int64
 
+	query := r.db.WithContext(ctx).Model(&models.Account{}).Where("account_type = ?", accountType)
+	if companyID != 0 {
+		query = query.Where("company_id = ?", companyID)
+	}
+
 	// 获取总数
-	if err := r.db.WithContext(ctx).Model(&models.Account{}).
-		Where("account_type = ?", accountType).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 获取分页数据
-	err := r.db.WithContext(ctx).Preload("Parent").
-		Where("account_type = ?", accountType).
+	err := query.Preload("Parent").Order("code ASC").
 		Offset(offset).Limit(limit).Find(&accounts).Error
 	if err != nil {
 		return nil, 0, err
@@ -60,6 +75,27 @@ func (r *AccountRepositoryImpl) GetByType(ctx context.Context, accountType strin
 	return accounts, total, nil
 }
 
+// GetByCompany 获取公司科目表
+func (r *AccountRepositoryImpl) GetByCompany(ctx context.Context, companyID uint, offset, limit int) ([]*models.Account, int64, error) {
+	var accounts []*models.Account
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Account{}).Where("company_id = ?", companyID)
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页数据
+	err := query.Order("code ASC").Offset(offset).Limit(limit).Find(&accounts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return accounts, total, nil
+}
+
 // GetChildren 获取子科目
 func (r *AccountRepositoryImpl) GetChildren(ctx context.Context, parentID uint) ([]*models.Account, error) {
 	var accounts []*models.Account
@@ -67,10 +103,28 @@ func (r *AccountRepositoryImpl) GetChildren(ctx context.Context, parentID uint)
 	return accounts, err
 }
 
+// GetChartOfAccounts 获取公司完整科目表（含父科目），按编码排序
+func (r *AccountRepositoryImpl) GetChartOfAccounts(ctx context.Context, companyID uint) ([]*models.Account, error) {
+	var accounts []*models.Account
+	err := r.db.WithContext(ctx).Preload("Parent").
+		Where("company_id = ?", companyID).
+		Order("code ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// Transaction 在同一数据库事务中执行科目操作，fn 返回错误时整体回滚
+func (r *AccountRepositoryImpl) Transaction(ctx context.Context, fn func(repo AccountRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewAccountRepository(tx))
+	})
+}
+
 // JournalEntryRepository 日记账分录仓储接口
 type JournalEntryRepository interface {
 	BaseRepository[models.JournalEntry]
 	GetByDateRange(ctx context.Context, startDate, endDate string, offset, limit int) ([]*models.JournalEntry, int64, error)
+	CreateVoucher(ctx context.Context, transaction *models.Transaction, entries []*models.JournalEntry) error
+	GetTrialBalanceRows(ctx context.Context, companyIDs, eliminationCompanyIDs []uint, startDate, endDate time.Time) ([]dto.TrialBalanceRow, error)
 }
 
 // JournalEntryRepositoryImpl 日记账分录仓储实现
@@ -110,10 +164,67 @@ func (r *JournalEntryRepositoryImpl) GetByDateRange(ctx context.Context, startDa
 	return entries, total, nil
 }
 
+// CreateVoucher 在同一事务中创建凭证头及其分录
+func (r *JournalEntryRepositoryImpl) CreateVoucher(ctx context.Context, transaction *models.Transaction, entries []*models.JournalEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			entry.TransactionID = transaction.ID
+			entry.CompanyID = transaction.CompanyID
+			if err := tx.Create(entry).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetTrialBalanceRows 按公司、科目编码汇总截至结束日期的分录发生额
+// 对方公司属于 eliminationCompanyIDs 的内部往来分录单独成行，供合并抵销使用
+func (r *JournalEntryRepositoryImpl) GetTrialBalanceRows(ctx context.Context, companyIDs, eliminationCompanyIDs []uint, startDate, endDate time.Time) ([]dto.TrialBalanceRow, error) {
+	if len(eliminationCompanyIDs) == 0 {
+		eliminationCompanyIDs = []uint{0}
+	}
+
+	var rows []dto.TrialBalanceRow
+	err := r.db.WithContext(ctx).
+		Table("journal_entries AS je").
+		Select(`je.company_id AS company_id,
+			a.code AS account_code,
+			MAX(a.name) AS account_name,
+			MAX(a.account_type) AS account_type,
+			CASE WHEN je.intercompany_company_id IN ? THEN 1 ELSE 0 END AS intercompany,
+			COALESCE(SUM(CASE WHEN t.transaction_date < ? THEN je.debit - je.credit ELSE 0 END), 0) AS opening_balance,
+			COALESCE(SUM(CASE WHEN t.transaction_date >= ? THEN je.debit ELSE 0 END), 0) AS debit,
+			COALESCE(SUM(CASE WHEN t.transaction_date >= ? THEN je.credit ELSE 0 END), 0) AS credit`,
+			eliminationCompanyIDs, startDate, startDate, startDate).
+		Joins("JOIN accounts AS a ON a.id = je.account_id").
+		Joins("JOIN transactions AS t ON t.id = je.transaction_id").
+		Where("je.deleted_at IS NULL AND t.deleted_at IS NULL").
+		Where("t.status <> ?", "cancelled").
+		Where("je.company_id IN ?", companyIDs).
+		Where("t.transaction_date < ?", endDate).
+		Group("je.company_id, a.code, intercompany").
+		Order("a.code ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
 // PaymentEntryRepository 付款分录仓储接口
 type PaymentEntryRepository interface {
 	BaseRepository[models.PaymentEntry]
 	GetByParty(ctx context.Context, partyType string, partyID uint, offset, limit int) ([]*models.PaymentEntry, int64, error)
+	GetWithAllocations(ctx context.Context, id uint) (*models.PaymentEntry, error)
+	GetUnallocated(ctx context.Context, companyID uint, partyType string, partyID uint) ([]*models.PaymentEntry, error)
+	GetSalesInvoice(ctx context.Context, id uint) (*models.SalesInvoice, error)
+	GetPayable(ctx context.Context, id uint) (*models.Payable, error)
+	GetPartyRepresentsCompany(ctx context.Context, partyType string, partyID uint) (*uint, error)
+	GetBankAccount(ctx context.Context, id uint) (*models.BankAccount, error)
+	AddAllocations(ctx context.Context, payment *models.PaymentEntry, allocations []*models.PaymentAllocation) error
+	Submit(ctx context.Context, payment *models.PaymentEntry, transaction *models.Transaction, entries []*models.JournalEntry) error
+	Cancel(ctx context.Context, payment *models.PaymentEntry) error
 }
 
 // PaymentEntryRepositoryImpl 付款分录仓储实现
@@ -143,8 +254,9 @@ func (r *PaymentEntryRepositoryImpl) GetByParty(ctx context.Context, partyType s
 	}
 
 	// 获取分页数据
-	err := r.db.WithContext(ctx).Preload("PaymentEntryReferences").
+	err := r.db.WithContext(ctx).Preload("Allocations").
 		Where("party_type = ? AND party_id = ?", partyType, partyID).
+		Order("posting_date DESC, id DESC").
 		Offset(offset).Limit(limit).Find(&payments).Error
 	if err != nil {
 		return nil, 0, err
@@ -152,3 +264,361 @@ func (r *PaymentEntryRepositoryImpl) GetByParty(ctx context.Context, partyType s
 
 	return payments, total, nil
 }
+
+// GetWithAllocations 获取付款分录及其分配明细
+func (r *PaymentEntryRepositoryImpl) GetWithAllocations(ctx context.Context, id uint) (*models.PaymentEntry, error) {
+	var payment models.PaymentEntry
+	err := r.db.WithContext(ctx).
+		Preload("Allocations", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		First(&payment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetUnallocated 获取当事方已提交但尚未核销完的付款（预收/预付结转）
+func (r *PaymentEntryRepositoryImpl) GetUnallocated(ctx context.Context, companyID uint, partyType string, partyID uint) ([]*models.PaymentEntry, error) {
+	var payments []*models.PaymentEntry
+	query := r.db.WithContext(ctx).
+		Where("party_type = ? AND party_id = ?", partyType, partyID).
+		Where("status = ? AND unallocated_amount > 0", "submitted")
+	if companyID != 0 {
+		query = query.Where("company_id = ?", companyID)
+	}
+	err := query.Order("posting_date ASC, id ASC").Find(&payments).Error
+	return payments, err
+}
+
+// GetSalesInvoice 获取销售发票
+func (r *PaymentEntryRepositoryImpl) GetSalesInvoice(ctx context.Context, id uint) (*models.SalesInvoice, error) {
+	var invoice models.SalesInvoice
+	if err := r.db.WithContext(ctx).First(&invoice, id).Error; err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// GetPayable 获取应付单据
+func (r *PaymentEntryRepositoryImpl) GetPayable(ctx context.Context, id uint) (*models.Payable, error) {
+	var payable models.Payable
+	if err := r.db.WithContext(ctx).First(&payable, id).Error; err != nil {
+		return nil, err
+	}
+	return &payable, nil
+}
+
+// GetPartyRepresentsCompany 获取内部客户/供应商所代表的公司，外部单位返回 nil
+func (r *PaymentEntryRepositoryImpl) GetPartyRepresentsCompany(ctx context.Context, partyType string, partyID uint) (*uint, error) {
+	var representsCompanyID *uint
+	var model interface{}
+	switch partyType {
+	case "Customer":
+		model = &models.Customer{}
+	case "Supplier":
+		model = &models.Supplier{}
+	default:
+		return nil, nil
+	}
+	err := r.db.WithContext(ctx).Model(model).
+		Where("id = ?", partyID).
+		Select("represents_company_id").
+		Scan(&representsCompanyID).Error
+	return representsCompanyID, err
+}
+
+// GetBankAccount 获取银行账户
+func (r *PaymentEntryRepositoryImpl) GetBankAccount(ctx context.Context, id uint) (*models.BankAccount, error) {
+	var bankAccount models.BankAccount
+	if err := r.db.WithContext(ctx).First(&bankAccount, id).Error; err != nil {
+		return nil, err
+	}
+	return &bankAccount, nil
+}
+
+// AddAllocations 追加付款分配；已提交的付款立即核销到单据，草稿付款在提交时核销。
+// 分配总额超过付款可分配余额时返回 ErrPaymentOverAllocated
+func (r *PaymentEntryRepositoryImpl) AddAllocations(ctx context.Context, payment *models.PaymentEntry, allocations []*models.PaymentAllocation) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var allocated models.Money
+		for _, allocation := range allocations {
+			allocated += allocation.AllocatedAmount
+		}
+		// 先累加已分配金额锁定付款行，并以锁定后的状态决定是否立即核销
+		if err := updatePaymentAllocatedAmount(tx, payment, allocated); err != nil {
+			return err
+		}
+
+		for _, allocation := range allocations {
+			allocation.PaymentEntryID = payment.ID
+			allocation.Status = "pending"
+			if payment.Status == "submitted" {
+				if err := applyAllocation(tx, payment, allocation); err != nil {
+					return err
+				}
+				allocation.Status = "applied"
+			}
+			if err := tx.Create(allocation).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ErrPaymentEntryStatusChanged 提交或取消付款时付款状态已被并发修改
+var ErrPaymentEntryStatusChanged = errors.New("付款状态已变更，请刷新后重试")
+
+// changePaymentStatus 仅当付款仍为加载时的状态时更新状态字段，防止并发提交或取消重复过账与核销
+func changePaymentStatus(tx *gorm.DB, payment *models.PaymentEntry, fromStatus string, updates map[string]interface{}) error {
+	result := tx.Model(&models.PaymentEntry{}).
+		Where("id = ? AND status = ?", payment.ID, fromStatus).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPaymentEntryStatusChanged
+	}
+	return nil
+}
+
+// Submit 提交付款：生成过账凭证并核销全部待核销分配；付款已不是草稿时返回 ErrPaymentEntryStatusChanged
+func (r *PaymentEntryRepositoryImpl) Submit(ctx context.Context, payment *models.PaymentEntry, transaction *models.Transaction, entries []*models.JournalEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := changePaymentStatus(tx, payment, "draft", map[string]interface{}{
+			"status":    "submitted",
+			"is_posted": true,
+			"posted_at": &now,
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			entry.TransactionID = transaction.ID
+			entry.CompanyID = transaction.CompanyID
+			if err := tx.Create(entry).Error; err != nil {
+				return err
+			}
+		}
+
+		for i := range payment.Allocations {
+			allocation := &payment.Allocations[i]
+			if allocation.Status != "pending" {
+				continue
+			}
+			if err := applyAllocation(tx, payment, allocation); err != nil {
+				return err
+			}
+			allocation.Status = "applied"
+			if err := tx.Model(allocation).Update("status", allocation.Status).Error; err != nil {
+				return err
+			}
+		}
+
+		payment.Status = "submitted"
+		payment.IsPosted = true
+		payment.PostedAt = &now
+		payment.TransactionID = &transaction.ID
+		return tx.Model(payment).Update("transaction_id", payment.TransactionID).Error
+	})
+}
+
+// Cancel 取消已提交的付款：冲回凭证与全部已核销分配；付款状态已被并发修改时返回 ErrPaymentEntryStatusChanged
+func (r *PaymentEntryRepositoryImpl) Cancel(ctx context.Context, payment *models.PaymentEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := changePaymentStatus(tx, payment, payment.Status, map[string]interface{}{
+			"status":             "cancelled",
+			"allocated_amount":   models.Money(0),
+			"unallocated_amount": models.Money(0),
+		}); err != nil {
+			return err
+		}
+
+		if payment.TransactionID != nil {
+			if err := tx.Model(&models.Transaction{}).
+				Where("id = ?", *payment.TransactionID).
+				Update("status", "cancelled").Error; err != nil {
+				return err
+			}
+		}
+
+		for i := range payment.Allocations {
+			allocation := &payment.Allocations[i]
+			if allocation.Status == "cancelled" {
+				continue
+			}
+			if allocation.Status == "applied" {
+				if err := reverseAllocation(tx, payment, allocation); err != nil {
+					return err
+				}
+			}
+			allocation.Status = "cancelled"
+			if err := tx.Model(allocation).Update("status", allocation.Status).Error; err != nil {
+				return err
+			}
+		}
+
+		payment.Status = "cancelled"
+		payment.AllocatedAmount = 0
+		payment.UnallocatedAmount = 0
+		return nil
+	})
+}
+
+// ErrPaymentOverAllocated 分配金额超过付款可分配余额
+var ErrPaymentOverAllocated = errors.New("分配金额超过付款可分配余额")
+
+// updatePaymentAllocatedAmount 以条件更新累加付款已分配金额并扣减未核销金额，防止并发分配超过付款金额；
+// 成功后按数据库中的最新值刷新付款的分配金额与状态
+func updatePaymentAllocatedAmount(tx *gorm.DB, payment *models.PaymentEntry, allocated models.Money) error {
+	result := tx.Model(&models.PaymentEntry{}).
+		Where("id = ? AND status <> ?", payment.ID, "cancelled").
+		Where("allocated_amount + ? <= CASE WHEN payment_type = ? THEN received_amount ELSE paid_amount END",
+			allocated, models.PaymentTypeReceive).
+		Updates(map[string]interface{}{
+			"allocated_amount":   gorm.Expr("allocated_amount + ?", allocated),
+			"unallocated_amount": gorm.Expr("unallocated_amount - ?", allocated),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w，付款 %s 本次分配 %s", ErrPaymentOverAllocated, payment.PaymentNumber, allocated)
+	}
+
+	var current models.PaymentEntry
+	if err := tx.Select("status", "allocated_amount", "unallocated_amount").First(&current, payment.ID).Error; err != nil {
+		return err
+	}
+	payment.Status = current.Status
+	payment.AllocatedAmount = current.AllocatedAmount
+	payment.UnallocatedAmount = current.UnallocatedAmount
+	return nil
+}
+
+// applyAllocation 将分配金额核销到单据；以条件更新防止并发下超额核销
+func applyAllocation(tx *gorm.DB, payment *models.PaymentEntry, allocation *models.PaymentAllocation) error {
+	amount := allocation.AllocatedAmount
+	switch allocation.ReferenceType {
+	case models.AllocationRefSalesInvoice:
+		result := tx.Model(&models.SalesInvoice{}).
+			Where("id = ? AND outstanding_amount >= ?", allocation.ReferenceID, amount).
+			Updates(map[string]interface{}{
+				"paid_amount":        gorm.Expr("paid_amount + ?", amount),
+				"outstanding_amount": gorm.Expr("outstanding_amount - ?", amount),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("发票 %s 的未付金额不足以核销 %s", allocation.ReferenceNumber, amount)
+		}
+		if err := refreshInvoicePaymentStatus(tx, allocation.ReferenceID); err != nil {
+			return err
+		}
+
+		// 同步生成发票收款记录，保持对账单与发票付款明细一致
+		paymentMethod := payment.PaymentMethod
+		if paymentMethod == "" {
+			paymentMethod = "payment_entry"
+		}
+		invoicePayment := &models.InvoicePayment{
+			SalesInvoiceID:  allocation.ReferenceID,
+			PaymentEntryID:  &payment.ID,
+			PaymentDate:     payment.PostingDate,
+			PaymentMethod:   paymentMethod,
+			Amount:          amount,
+			Currency:        payment.Currency,
+			ExchangeRate:    payment.ExchangeRate,
+			ReferenceNumber: payment.PaymentNumber,
+			BankAccountID:   payment.BankAccountID,
+			Status:          "Cleared",
+		}
+		return tx.Create(invoicePayment).Error
+
+	case models.AllocationRefPayable:
+		result := tx.Model(&models.Payable{}).
+			Where("id = ? AND amount_paid + ? <= amount", allocation.ReferenceID, amount).
+			Update("amount_paid", gorm.Expr("amount_paid + ?", amount))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("应付单据 %s 的未付金额不足以核销 %s", allocation.ReferenceNumber, amount)
+		}
+		return refreshPayableStatus(tx, allocation.ReferenceID)
+	}
+	return fmt.Errorf("不支持的分配单据类型: %s", allocation.ReferenceType)
+}
+
+// reverseAllocation 冲回已核销到单据的分配金额
+func reverseAllocation(tx *gorm.DB, payment *models.PaymentEntry, allocation *models.PaymentAllocation) error {
+	amount := allocation.AllocatedAmount
+	switch allocation.ReferenceType {
+	case models.AllocationRefSalesInvoice:
+		if err := tx.Model(&models.SalesInvoice{}).
+			Where("id = ?", allocation.ReferenceID).
+			Updates(map[string]interface{}{
+				"paid_amount":        gorm.Expr("paid_amount - ?", amount),
+				"outstanding_amount": gorm.Expr("outstanding_amount + ?", amount),
+			}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.InvoicePayment{}).
+			Where("sales_invoice_id = ? AND payment_entry_id = ?", allocation.ReferenceID, payment.ID).
+			Update("status", "Cancelled").Error; err != nil {
+			return err
+		}
+		return refreshInvoicePaymentStatus(tx, allocation.ReferenceID)
+
+	case models.AllocationRefPayable:
+		if err := tx.Model(&models.Payable{}).
+			Where("id = ?", allocation.ReferenceID).
+			Update("amount_paid", gorm.Expr("amount_paid - ?", amount)).Error; err != nil {
+			return err
+		}
+		return refreshPayableStatus(tx, allocation.ReferenceID)
+	}
+	return fmt.Errorf("不支持的分配单据类型: %s", allocation.ReferenceType)
+}
+
+// refreshInvoicePaymentStatus 根据已付金额重算发票付款状态
+func refreshInvoicePaymentStatus(tx *gorm.DB, invoiceID uint) error {
+	var invoice models.SalesInvoice
+	if err := tx.First(&invoice, invoiceID).Error; err != nil {
+		return err
+	}
+
+	status := "Unpaid"
+	if invoice.OutstandingAmount <= 0 {
+		status = "Paid"
+	} else if invoice.PaidAmount > 0 {
+		status = "Partially Paid"
+	} else if invoice.DueDate.Before(time.Now()) {
+		status = "Overdue"
+	}
+	return tx.Model(&invoice).Update("payment_status", status).Error
+}
+
+// refreshPayableStatus 根据已付金额重算应付单据状态
+func refreshPayableStatus(tx *gorm.DB, payableID uint) error {
+	var payable models.Payable
+	if err := tx.First(&payable, payableID).Error; err != nil {
+		return err
+	}
+
+	status := "open"
+	if payable.AmountPaid >= payable.Amount {
+		status = "paid"
+	} else if payable.AmountPaid > 0 {
+		status = "partially_paid"
+	}
+	return tx.Model(&payable).Update("status", status).Error
+}
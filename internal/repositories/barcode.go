@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// BarcodeRepository 物料条码仓储接口
+type BarcodeRepository interface {
+	BaseRepository[models.ItemBarcode]
+	GetBarcode(ctx context.Context, id uint) (*models.ItemBarcode, error)
+	GetByBarcode(ctx context.Context, barcode string) (*models.ItemBarcode, error)
+	GetByGTIN(ctx context.Context, gtin string) (*models.ItemBarcode, error)
+	ListItemBarcodes(ctx context.Context, itemID uint) ([]*models.ItemBarcode, error)
+	GetPrimaryBarcodes(ctx context.Context, itemIDs []uint) (map[uint]*models.ItemBarcode, error)
+	CreateBarcode(ctx context.Context, barcode *models.ItemBarcode) error
+	DeleteBarcode(ctx context.Context, id uint) error
+	GetItemByCode(ctx context.Context, code string) (*models.Item, error)
+	GetItemsByIDs(ctx context.Context, ids []uint) ([]*models.Item, error)
+	FindLocationsByCode(ctx context.Context, warehouseID uint, code string) ([]*models.Location, error)
+	GetLocationsByIDs(ctx context.Context, ids []uint) ([]*models.Location, error)
+	GetOpenPurchaseOrderItem(ctx context.Context, purchaseOrderID, itemID uint) (*models.PurchaseOrderItem, error)
+}
+
+// BarcodeRepositoryImpl 物料条码仓储实现
+type BarcodeRepositoryImpl struct {
+	BaseRepository[models.ItemBarcode]
+	db *gorm.DB
+}
+
+// NewBarcodeRepository 创建物料条码仓储实例
+func NewBarcodeRepository(db *gorm.DB) BarcodeRepository {
+	return &BarcodeRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.ItemBarcode](db),
+		db:             db,
+	}
+}
+
+// GetBarcode 根据ID获取物料条码
+func (r *BarcodeRepositoryImpl) GetBarcode(ctx context.Context, id uint) (*models.ItemBarcode, error) {
+	var barcode models.ItemBarcode
+	if err := r.db.WithContext(ctx).First(&barcode, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &barcode, nil
+}
+
+// GetByBarcode 根据条码内容获取物料条码
+func (r *BarcodeRepositoryImpl) GetByBarcode(ctx context.Context, code string) (*models.ItemBarcode, error) {
+	var barcode models.ItemBarcode
+	if err := r.db.WithContext(ctx).Where("barcode = ?", code).First(&barcode).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &barcode, nil
+}
+
+// GetByGTIN 根据补零为 14 位的 GTIN 获取物料条码，同一 GTIN 只会登记一次
+func (r *BarcodeRepositoryImpl) GetByGTIN(ctx context.Context, gtin string) (*models.ItemBarcode, error) {
+	var barcode models.ItemBarcode
+	if err := r.db.WithContext(ctx).Where("gtin = ?", gtin).First(&barcode).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &barcode, nil
+}
+
+// ListItemBarcodes 获取物料的全部条码，主条码在前
+func (r *BarcodeRepositoryImpl) ListItemBarcodes(ctx context.Context, itemID uint) ([]*models.ItemBarcode, error) {
+	var barcodes []*models.ItemBarcode
+	err := r.db.WithContext(ctx).Where("item_id = ?", itemID).Order("is_primary DESC, id").Find(&barcodes).Error
+	return barcodes, err
+}
+
+// GetPrimaryBarcodes 获取物料用于打印标签的条码：优先主条码，其次最早登记的条码；没有条码的物料不在结果中
+func (r *BarcodeRepositoryImpl) GetPrimaryBarcodes(ctx context.Context, itemIDs []uint) (map[uint]*models.ItemBarcode, error) {
+	result := make(map[uint]*models.ItemBarcode, len(itemIDs))
+	if len(itemIDs) == 0 {
+		return result, nil
+	}
+	var barcodes []*models.ItemBarcode
+	err := r.db.WithContext(ctx).Where("item_id IN ?", itemIDs).Order("is_primary DESC, id").Find(&barcodes).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, barcode := range barcodes {
+		if _, ok := result[barcode.ItemID]; !ok {
+			result[barcode.ItemID] = barcode
+		}
+	}
+	return result, nil
+}
+
+// CreateBarcode 登记物料条码；设为主条码时取消该物料原有的主条码
+func (r *BarcodeRepositoryImpl) CreateBarcode(ctx context.Context, barcode *models.ItemBarcode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if barcode.IsPrimary {
+			if err := tx.Model(&models.ItemBarcode{}).Where("item_id = ? AND is_primary = ?", barcode.ItemID, true).
+				Update("is_primary", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(barcode).Error
+	})
+}
+
+// DeleteBarcode 删除物料条码。条码列有唯一索引，物理删除以便条码可重新登记
+func (r *BarcodeRepositoryImpl) DeleteBarcode(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.ItemBarcode{}, id).Error
+}
+
+// GetItemByCode 根据物料编码获取物料，用于扫描物料编码条码
+func (r *BarcodeRepositoryImpl) GetItemByCode(ctx context.Context, code string) (*models.Item, error) {
+	var item models.Item
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetItemsByIDs 按ID获取物料，用于打印物料标签
+func (r *BarcodeRepositoryImpl) GetItemsByIDs(ctx context.Context, ids []uint) ([]*models.Item, error) {
+	var items []*models.Item
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("code").Find(&items).Error
+	return items, err
+}
+
+// FindLocationsByCode 根据编码查找库位；warehouseID 为 0 时在全部仓库中查找，可能匹配多个仓库的同名库位
+func (r *BarcodeRepositoryImpl) FindLocationsByCode(ctx context.Context, warehouseID uint, code string) ([]*models.Location, error) {
+	var locations []*models.Location
+	query := r.db.WithContext(ctx).Where("code = ?", code)
+	if warehouseID != 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	err := query.Order("warehouse_id").Find(&locations).Error
+	return locations, err
+}
+
+// GetLocationsByIDs 按ID获取库位及其仓库，用于打印库位标签
+func (r *BarcodeRepositoryImpl) GetLocationsByIDs(ctx context.Context, ids []uint) ([]*models.Location, error) {
+	var locations []*models.Location
+	err := r.db.WithContext(ctx).Preload("Warehouse").Where("id IN ?", ids).Order("warehouse_id, sequence, code").Find(&locations).Error
+	return locations, err
+}
+
+// GetOpenPurchaseOrderItem 获取采购订单中该物料尚未收完的第一行，用于预填收货明细
+func (r *BarcodeRepositoryImpl) GetOpenPurchaseOrderItem(ctx context.Context, purchaseOrderID, itemID uint) (*models.PurchaseOrderItem, error) {
+	var line models.PurchaseOrderItem
+	err := r.db.WithContext(ctx).
+		Where("purchase_order_id = ? AND item_id = ? AND received_qty < quantity", purchaseOrderID, itemID).
+		Order("id").First(&line).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &line, nil
+}
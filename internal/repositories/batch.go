@@ -0,0 +1,324 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// BatchFilter 批次列表筛选条件
+type BatchFilter struct {
+	ItemID  uint
+	BatchNo string
+}
+
+// BatchStockFilter 批次库存筛选条件，ID 为 0 表示不筛选；
+// ExpiryBefore 不为空时只返回有效期早于该时间的批次
+type BatchStockFilter struct {
+	ItemID       uint
+	WarehouseID  uint
+	BatchID      uint
+	IncludeZero  bool
+	ExpiryBefore *time.Time
+}
+
+// SerialNumberFilter 序列号列表筛选条件
+type SerialNumberFilter struct {
+	ItemID      uint
+	WarehouseID uint
+	BatchID     uint
+	Status      string
+	SerialNo    string
+}
+
+// BatchStockRow 批次在仓库的库存
+type BatchStockRow struct {
+	ItemID        uint
+	ItemCode      string
+	ItemName      string
+	WarehouseID   uint
+	WarehouseCode string
+	WarehouseName string
+	BatchID       uint
+	BatchNo       string
+	ExpiryDate    *time.Time
+	Quantity      float64
+}
+
+// TraceMovementRow 追溯用的库存移动及其来源单据
+type TraceMovementRow struct {
+	MovementID      uint
+	ItemID          uint
+	ItemCode        string
+	WarehouseID     uint
+	BatchID         *uint
+	BatchNo         string
+	SerialNo        string
+	MovementType    string
+	QuantityChange  float64
+	ReferenceType   string
+	ReferenceID     *uint
+	ReferenceNumber string
+	PartyID         *uint
+	PartyName       string
+	CreatedAt       time.Time
+}
+
+// BatchRepository 批次与序列号仓储接口
+type BatchRepository interface {
+	BaseRepository[models.Batch]
+	GetByBatchNo(ctx context.Context, itemID uint, batchNo string) (*models.Batch, error)
+	ListBatches(ctx context.Context, filter BatchFilter, offset, limit int) ([]*models.Batch, int64, error)
+	GetBatchStocks(ctx context.Context, filter BatchStockFilter) ([]BatchStockRow, error)
+	GetFEFOBatches(ctx context.Context, itemID, warehouseID, locationID uint, asOf time.Time) ([]BatchStockRow, error)
+	GetSerialNumber(ctx context.Context, id uint) (*models.SerialNumber, error)
+	GetSerialNumbers(ctx context.Context, itemID uint, serialNos []string) ([]*models.SerialNumber, error)
+	ListSerialNumbers(ctx context.Context, filter SerialNumberFilter, offset, limit int) ([]*models.SerialNumber, int64, error)
+	GetBatchMovements(ctx context.Context, batchIDs []uint) ([]TraceMovementRow, error)
+	GetReferenceMovements(ctx context.Context, referenceType string, referenceIDs []uint) ([]TraceMovementRow, error)
+	GetSerialMovements(ctx context.Context, serialNumberID uint) ([]TraceMovementRow, error)
+}
+
+// BatchRepositoryImpl 批次与序列号仓储实现
+type BatchRepositoryImpl struct {
+	BaseRepository[models.Batch]
+	db *gorm.DB
+}
+
+// NewBatchRepository 创建批次仓储实例
+func NewBatchRepository(db *gorm.DB) BatchRepository {
+	return &BatchRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.Batch](db),
+		db:             db,
+	}
+}
+
+// GetByBatchNo 根据物料和批次号获取批次
+func (r *BatchRepositoryImpl) GetByBatchNo(ctx context.Context, itemID uint, batchNo string) (*models.Batch, error) {
+	var batch models.Batch
+	err := r.db.WithContext(ctx).Where("item_id = ? AND batch_no = ?", itemID, batchNo).First(&batch).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// ListBatches 分页获取批次
+func (r *BatchRepositoryImpl) ListBatches(ctx context.Context, filter BatchFilter, offset, limit int) ([]*models.Batch, int64, error) {
+	var batches []*models.Batch
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Batch{})
+	if filter.ItemID != 0 {
+		query = query.Where("item_id = ?", filter.ItemID)
+	}
+	if filter.BatchNo != "" {
+		query = query.Where("batch_no LIKE ?", "%"+filter.BatchNo+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Item").
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&batches).Error
+	return batches, total, err
+}
+
+// GetBatchStocks 获取批次在各仓库的库存
+func (r *BatchRepositoryImpl) GetBatchStocks(ctx context.Context, filter BatchStockFilter) ([]BatchStockRow, error) {
+	query := r.db.WithContext(ctx).
+		Table("batch_stocks AS bs").
+		Select(`bs.item_id AS item_id,
+			i.code AS item_code,
+			i.name AS item_name,
+			bs.warehouse_id AS warehouse_id,
+			w.code AS warehouse_code,
+			w.name AS warehouse_name,
+			bs.batch_id AS batch_id,
+			b.batch_no AS batch_no,
+			b.expiry_date AS expiry_date,
+			bs.quantity AS quantity`).
+		Joins("JOIN batches AS b ON b.id = bs.batch_id").
+		Joins("JOIN items AS i ON i.id = bs.item_id").
+		Joins("JOIN warehouses AS w ON w.id = bs.warehouse_id").
+		Where("bs.deleted_at IS NULL")
+	if filter.ItemID != 0 {
+		query = query.Where("bs.item_id = ?", filter.ItemID)
+	}
+	if filter.WarehouseID != 0 {
+		query = query.Where("bs.warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.BatchID != 0 {
+		query = query.Where("bs.batch_id = ?", filter.BatchID)
+	}
+	if !filter.IncludeZero {
+		query = query.Where("bs.quantity <> 0")
+	}
+	if filter.ExpiryBefore != nil {
+		query = query.Where("b.expiry_date IS NOT NULL AND b.expiry_date < ?", *filter.ExpiryBefore)
+	}
+
+	var rows []BatchStockRow
+	err := query.Order("i.code, b.batch_no, w.code").Scan(&rows).Error
+	return rows, err
+}
+
+// GetFEFOBatches 获取物料在仓库可发出的批次库存，指定库位时只取该库位的库存；
+// 按先到期先出排序：有效期早的在前，未设置有效期的排最后，同一有效期按批次创建先后。
+// 在 asOf 当天之前过期的批次与存放在隔离库位的数量不参与分配
+func (r *BatchRepositoryImpl) GetFEFOBatches(ctx context.Context, itemID, warehouseID, locationID uint, asOf time.Time) ([]BatchStockRow, error) {
+	db := r.db.WithContext(ctx)
+	var query *gorm.DB
+	if locationID != 0 {
+		query = db.Table("location_stocks AS s").
+			Select("s.item_id AS item_id, s.warehouse_id AS warehouse_id, s.batch_id AS batch_id, b.batch_no AS batch_no, b.expiry_date AS expiry_date, s.quantity AS quantity").
+			Joins("JOIN locations AS l ON l.id = s.location_id").
+			Where("s.location_id = ? AND (l.location_type IS NULL OR l.location_type <> ?)", locationID, models.LocationTypeQuarantine)
+	} else {
+		quarantined := db.Table("location_stocks AS ls").
+			Select("ls.batch_id, SUM(ls.quantity) AS quantity").
+			Joins("JOIN locations AS l ON l.id = ls.location_id").
+			Where("ls.item_id = ? AND ls.warehouse_id = ? AND ls.deleted_at IS NULL AND l.location_type = ?", itemID, warehouseID, models.LocationTypeQuarantine).
+			Group("ls.batch_id")
+		query = db.Table("batch_stocks AS s").
+			Select("s.item_id AS item_id, s.warehouse_id AS warehouse_id, s.batch_id AS batch_id, b.batch_no AS batch_no, b.expiry_date AS expiry_date, s.quantity - COALESCE(q.quantity, 0) AS quantity").
+			Joins("LEFT JOIN (?) AS q ON q.batch_id = s.batch_id", quarantined)
+	}
+
+	var rows []BatchStockRow
+	err := query.
+		Joins("JOIN batches AS b ON b.id = s.batch_id AND b.deleted_at IS NULL").
+		Where("s.item_id = ? AND s.warehouse_id = ? AND s.deleted_at IS NULL", itemID, warehouseID).
+		Where("b.expiry_date IS NULL OR b.expiry_date >= ?", models.StartOfDay(asOf)).
+		Order("CASE WHEN b.expiry_date IS NULL THEN 1 ELSE 0 END, b.expiry_date, b.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	available := rows[:0]
+	for _, row := range rows {
+		if row.Quantity > quantityEpsilon {
+			available = append(available, row)
+		}
+	}
+	return available, nil
+}
+
+// GetSerialNumber 根据ID获取序列号
+func (r *BatchRepositoryImpl) GetSerialNumber(ctx context.Context, id uint) (*models.SerialNumber, error) {
+	var serial models.SerialNumber
+	err := r.db.WithContext(ctx).Preload("Batch").Preload("Item").First(&serial, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &serial, nil
+}
+
+// GetSerialNumbers 获取物料的指定序列号
+func (r *BatchRepositoryImpl) GetSerialNumbers(ctx context.Context, itemID uint, serialNos []string) ([]*models.SerialNumber, error) {
+	var serials []*models.SerialNumber
+	err := r.db.WithContext(ctx).Where("item_id = ? AND serial_no IN ?", itemID, serialNos).Find(&serials).Error
+	return serials, err
+}
+
+// ListSerialNumbers 分页获取序列号
+func (r *BatchRepositoryImpl) ListSerialNumbers(ctx context.Context, filter SerialNumberFilter, offset, limit int) ([]*models.SerialNumber, int64, error) {
+	var serials []*models.SerialNumber
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.SerialNumber{})
+	if filter.ItemID != 0 {
+		query = query.Where("item_id = ?", filter.ItemID)
+	}
+	if filter.WarehouseID != 0 {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.BatchID != 0 {
+		query = query.Where("batch_id = ?", filter.BatchID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.SerialNo != "" {
+		query = query.Where("serial_no LIKE ?", "%"+filter.SerialNo+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Item").
+		Preload("Batch").
+		Order("item_id, serial_no").
+		Offset(offset).Limit(limit).
+		Find(&serials).Error
+	return serials, total, err
+}
+
+// GetBatchMovements 获取批次的全部库存移动及来源单据
+func (r *BatchRepositoryImpl) GetBatchMovements(ctx context.Context, batchIDs []uint) ([]TraceMovementRow, error) {
+	var rows []TraceMovementRow
+	err := r.traceMovements(ctx).Where("m.batch_id IN ?", batchIDs).Scan(&rows).Error
+	return rows, err
+}
+
+// GetReferenceMovements 获取引用指定单据的全部库存移动
+func (r *BatchRepositoryImpl) GetReferenceMovements(ctx context.Context, referenceType string, referenceIDs []uint) ([]TraceMovementRow, error) {
+	var rows []TraceMovementRow
+	err := r.traceMovements(ctx).
+		Where("m.reference_type = ? AND m.reference_id IN ?", referenceType, referenceIDs).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetSerialMovements 获取序列号经历的全部库存移动
+func (r *BatchRepositoryImpl) GetSerialMovements(ctx context.Context, serialNumberID uint) ([]TraceMovementRow, error) {
+	var rows []TraceMovementRow
+	err := r.traceMovements(ctx).
+		Joins("JOIN movement_serial_nos AS ms ON ms.movement_id = m.id").
+		Where("ms.serial_number_id = ?", serialNumberID).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// traceMovements 构建追溯查询：库存移动关联采购入库单、生产订单、送货单及其供应商或客户
+func (r *BatchRepositoryImpl) traceMovements(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("movements AS m").
+		Select(`m.id AS movement_id,
+			m.item_id AS item_id,
+			i.code AS item_code,
+			m.warehouse_id AS warehouse_id,
+			m.batch_id AS batch_id,
+			m.batch_no AS batch_no,
+			m.serial_no AS serial_no,
+			m.movement_type AS movement_type,
+			m.quantity_change AS quantity_change,
+			m.reference_type AS reference_type,
+			m.reference_id AS reference_id,
+			COALESCE(pr.receipt_number, po.order_number, dn.delivery_number, m.reference) AS reference_number,
+			COALESCE(dn.customer_id, pr.supplier_id) AS party_id,
+			COALESCE(c.name, s.name, '') AS party_name,
+			m.created_at AS created_at`).
+		Joins("JOIN items AS i ON i.id = m.item_id").
+		Joins("LEFT JOIN purchase_receipts AS pr ON m.reference_type = ? AND pr.id = m.reference_id", models.MovementReferencePurchaseReceipt).
+		Joins("LEFT JOIN suppliers AS s ON s.id = pr.supplier_id").
+		Joins("LEFT JOIN production_orders AS po ON m.reference_type = ? AND po.id = m.reference_id", models.MovementReferenceProductionOrder).
+		Joins("LEFT JOIN delivery_notes AS dn ON m.reference_type = ? AND dn.id = m.reference_id", models.MovementReferenceDeliveryNote).
+		Joins("LEFT JOIN customers AS c ON c.id = dn.customer_id").
+		Where("m.deleted_at IS NULL").
+		Order("m.id")
+}
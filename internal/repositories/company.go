@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// CompanyRepository 公司仓储接口
+type CompanyRepository interface {
+	BaseRepository[models.Company]
+	GetByCode(ctx context.Context, code string) (*models.Company, error)
+	GetAll(ctx context.Context) ([]*models.Company, error)
+	CountAll(ctx context.Context) (int64, error)
+	CountDependents(ctx context.Context, companyID uint) (int64, error)
+	CreateFiscalYear(ctx context.Context, fiscalYear *models.FiscalYear) error
+	GetFiscalYears(ctx context.Context, companyID uint) ([]*models.FiscalYear, error)
+	GetOverlappingFiscalYear(ctx context.Context, companyID uint, startDate, endDate time.Time) (*models.FiscalYear, error)
+	GetPeriodByDate(ctx context.Context, companyID uint, date time.Time) (*models.AccountingPeriod, error)
+	GetPeriodByID(ctx context.Context, id uint) (*models.AccountingPeriod, error)
+	UpdatePeriod(ctx context.Context, period *models.AccountingPeriod) error
+}
+
+// CompanyRepositoryImpl 公司仓储实现
+type CompanyRepositoryImpl struct {
+	BaseRepository[models.Company]
+	db *gorm.DB
+}
+
+// NewCompanyRepository 创建公司仓储实例
+func NewCompanyRepository(db *gorm.DB) CompanyRepository {
+	return &CompanyRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.Company](db),
+		db:             db,
+	}
+}
+
+// GetByCode 根据编码获取公司，不存在时返回 nil
+func (r *CompanyRepositoryImpl) GetByCode(ctx context.Context, code string) (*models.Company, error) {
+	var company models.Company
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&company).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &company, nil
+}
+
+// GetAll 获取全部公司
+func (r *CompanyRepositoryImpl) GetAll(ctx context.Context) ([]*models.Company, error) {
+	var companies []*models.Company
+	err := r.db.WithContext(ctx).Order("id ASC").Find(&companies).Error
+	return companies, err
+}
+
+// CountAll 统计公司数量（含已删除）
+func (r *CompanyRepositoryImpl) CountAll(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Company{}).Count(&count).Error
+	return count, err
+}
+
+// CountDependents 统计归属于公司的科目与凭证数量
+func (r *CompanyRepositoryImpl) CountDependents(ctx context.Context, companyID uint) (int64, error) {
+	var accounts, transactions int64
+	if err := r.db.WithContext(ctx).Model(&models.Account{}).
+		Where("company_id = ?", companyID).Count(&accounts).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Where("company_id = ?", companyID).Count(&transactions).Error; err != nil {
+		return 0, err
+	}
+	return accounts + transactions, nil
+}
+
+// CreateFiscalYear 创建财政年度及其会计期间
+func (r *CompanyRepositoryImpl) CreateFiscalYear(ctx context.Context, fiscalYear *models.FiscalYear) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if fiscalYear.IsCurrent {
+			if err := tx.Model(&models.FiscalYear{}).
+				Where("company_id = ? AND is_current = ?", fiscalYear.CompanyID, true).
+				Update("is_current", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(fiscalYear).Error
+	})
+}
+
+// GetFiscalYears 获取公司的财政年度（含会计期间）
+func (r *CompanyRepositoryImpl) GetFiscalYears(ctx context.Context, companyID uint) ([]*models.FiscalYear, error) {
+	var fiscalYears []*models.FiscalYear
+	err := r.db.WithContext(ctx).
+		Preload("Periods", func(db *gorm.DB) *gorm.DB {
+			return db.Order("start_date ASC")
+		}).
+		Where("company_id = ?", companyID).
+		Order("year DESC").
+		Find(&fiscalYears).Error
+	return fiscalYears, err
+}
+
+// GetOverlappingFiscalYear 获取与指定日期范围重叠的财政年度，不存在时返回 nil
+func (r *CompanyRepositoryImpl) GetOverlappingFiscalYear(ctx context.Context, companyID uint, startDate, endDate time.Time) (*models.FiscalYear, error) {
+	var fiscalYear models.FiscalYear
+	err := r.db.WithContext(ctx).
+		Where("company_id = ? AND start_date <= ? AND end_date >= ?", companyID, endDate, startDate).
+		First(&fiscalYear).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &fiscalYear, nil
+}
+
+// GetPeriodByDate 获取公司在指定日期所属的会计期间，不存在时返回 nil
+func (r *CompanyRepositoryImpl) GetPeriodByDate(ctx context.Context, companyID uint, date time.Time) (*models.AccountingPeriod, error) {
+	var period models.AccountingPeriod
+	err := r.db.WithContext(ctx).
+		Where("company_id = ? AND start_date <= ? AND end_date >= ?", companyID, date, date).
+		Order("start_date DESC").
+		First(&period).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &period, nil
+}
+
+// GetPeriodByID 根据ID获取会计期间
+func (r *CompanyRepositoryImpl) GetPeriodByID(ctx context.Context, id uint) (*models.AccountingPeriod, error) {
+	var period models.AccountingPeriod
+	if err := r.db.WithContext(ctx).First(&period, id).Error; err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
+
+// UpdatePeriod 更新会计期间
+func (r *CompanyRepositoryImpl) UpdatePeriod(ctx context.Context, period *models.AccountingPeriod) error {
+	return r.db.WithContext(ctx).Save(period).Error
+}
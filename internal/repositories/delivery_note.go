@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -24,8 +25,19 @@ type DeliveryNoteRepository interface {
 	CheckDeliveryNumberExists(deliveryNumber string, excludeID ...uint) (bool, error)
 	GenerateDeliveryNumber() (string, error)
 	ListWithFilters(req *dto.DeliveryNoteListRequest) ([]*models.DeliveryNote, int64, error)
+	GetWithItems(ctx context.Context, id uint) (*models.DeliveryNote, error)
+	PostDelivery(ctx context.Context, deliveryNote *models.DeliveryNote, userID uint) error
+	ReverseDelivery(ctx context.Context, deliveryNote *models.DeliveryNote, userID uint) error
 }
 
+// 发货过账与冲销错误，控制器据此区分响应状态码
+var (
+	// ErrOverDelivery 累计发货数量超过订单数量与物料允许的超发比例
+	ErrOverDelivery = errors.New("发货数量超过订单允许的数量")
+	// ErrDeliveryNoteStatusConflict 发货单或关联单据的当前状态不允许此次状态变更
+	ErrDeliveryNoteStatusConflict = errors.New("发货单状态不允许此操作")
+)
+
 // DeliveryNoteRepositoryImpl 交付单仓储实现
 type DeliveryNoteRepositoryImpl struct {
 	BaseRepository[models.DeliveryNote]
@@ -222,3 +234,182 @@ func (r *DeliveryNoteRepositoryImpl) GenerateDeliveryNumber() (string, error) {
 	
 	return fmt.Sprintf("%s%04d", prefix, count+1), nil
 }
+
+// GetWithItems 获取送货单及按 ID 排序的明细，不存在时返回 nil
+func (r *DeliveryNoteRepositoryImpl) GetWithItems(ctx context.Context, id uint) (*models.DeliveryNote, error) {
+	var deliveryNote models.DeliveryNote
+	err := r.db.WithContext(ctx).
+		Preload("Customer").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&deliveryNote, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deliveryNote, nil
+}
+
+// PostDelivery 在同一事务中提交送货单：按明细的仓库与批次过账出库移动，
+// 累加对应销售订单行的已发货数量并核销预留；任一明细库存不足或超发时全部回滚
+func (r *DeliveryNoteRepositoryImpl) PostDelivery(ctx context.Context, deliveryNote *models.DeliveryNote, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.DeliveryNote{}).
+			Where("id = ? AND status = ?", deliveryNote.ID, models.DeliveryNoteStatusDraft).
+			Update("status", models.DeliveryNoteStatusSubmitted)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w，送货单 %s 不是草稿状态，不能提交", ErrDeliveryNoteStatusConflict, deliveryNote.DeliveryNumber)
+		}
+
+		movements := make([]*models.Movement, 0, len(deliveryNote.Items))
+		var deliveries []ReservationDelivery
+		for i := range deliveryNote.Items {
+			line := &deliveryNote.Items[i]
+			itemID, quantity := line.ItemID, line.StockQuantity()
+			key := fmt.Sprintf("%s:%d:%d:%s", models.MovementReferenceDeliveryNote, deliveryNote.ID, line.ID, models.MovementTypeOut)
+			movements = append(movements, &models.Movement{
+				ItemID:           &itemID,
+				WarehouseID:      line.WarehouseID,
+				Quantity:         &quantity,
+				UOM:              line.UOM,
+				UOMQuantity:      line.Quantity,
+				ConversionFactor: line.ConversionFactor,
+				MovementType:     models.MovementTypeOut,
+				Reference:        deliveryNote.DeliveryNumber,
+				ReferenceType:    models.MovementReferenceDeliveryNote,
+				ReferenceID:      &deliveryNote.ID,
+				ReferenceLineID:  &line.ID,
+				IdempotencyKey:   &key,
+				BatchNo:          line.BatchNo,
+				SerialNo:         line.SerialNo,
+				CreatedBy:        &userID,
+			})
+
+			if line.SalesOrderItemID != nil {
+				if err := deliverSalesOrderItem(tx, *line.SalesOrderItemID, quantity); err != nil {
+					return err
+				}
+				deliveries = append(deliveries, ReservationDelivery{SalesOrderItemID: *line.SalesOrderItemID, Quantity: quantity})
+			}
+		}
+
+		if err := postMovements(tx, movements); err != nil {
+			return err
+		}
+		return consumeReservations(tx, deliveries)
+	})
+}
+
+// ReverseDelivery 在同一事务中取消已提交或已送达的送货单：按原出库移动的仓库、库位、批次、序列号与成本
+// 过账入库冲销，并扣回对应销售订单行的已发货数量；订单行的预留由调用方按订单状态重新建立
+func (r *DeliveryNoteRepositoryImpl) ReverseDelivery(ctx context.Context, deliveryNote *models.DeliveryNote, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.DeliveryNote{}).
+			Where("id = ? AND status IN ?", deliveryNote.ID, []string{models.DeliveryNoteStatusSubmitted, models.DeliveryNoteStatusDelivered}).
+			Update("status", models.DeliveryNoteStatusCancelled)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w，送货单 %s 未提交，无需冲销", ErrDeliveryNoteStatusConflict, deliveryNote.DeliveryNumber)
+		}
+
+		var billed int64
+		if err := tx.Model(&models.DeliveryNoteItem{}).
+			Where("delivery_note_id = ? AND billed_qty > ?", deliveryNote.ID, quantityEpsilon).
+			Count(&billed).Error; err != nil {
+			return err
+		}
+		if billed > 0 {
+			return fmt.Errorf("%w，送货单 %s 已开具销售发票，不能冲销", ErrDeliveryNoteStatusConflict, deliveryNote.DeliveryNumber)
+		}
+
+		var issued []models.Movement
+		if err := tx.Where("reference_type = ? AND reference_id = ? AND movement_type = ?",
+			models.MovementReferenceDeliveryNote, deliveryNote.ID, models.MovementTypeOut).
+			Order("id").Find(&issued).Error; err != nil {
+			return err
+		}
+
+		movements := make([]*models.Movement, 0, len(issued))
+		for i := range issued {
+			source := &issued[i]
+			quantity := -source.QuantityChange
+			key := fmt.Sprintf("%s:%d:%d:reversal", models.MovementReferenceDeliveryNote, deliveryNote.ID, source.ID)
+			movements = append(movements, &models.Movement{
+				ItemID:           source.ItemID,
+				WarehouseID:      source.WarehouseID,
+				LocationID:       source.LocationID,
+				Quantity:         &quantity,
+				UOM:              source.UOM,
+				UOMQuantity:      source.UOMQuantity,
+				ConversionFactor: source.ConversionFactor,
+				MovementType:     models.MovementTypeIn,
+				Reference:        deliveryNote.DeliveryNumber,
+				Notes:            fmt.Sprintf("取消送货单 %s 冲销出库", deliveryNote.DeliveryNumber),
+				ReferenceType:    models.MovementReferenceDeliveryNote,
+				ReferenceID:      &deliveryNote.ID,
+				ReferenceLineID:  source.ReferenceLineID,
+				IdempotencyKey:   &key,
+				BatchNo:          source.BatchNo,
+				SerialNo:         source.SerialNo,
+				CreatedBy:        &userID,
+				SourceMovement:   source,
+			})
+		}
+		if err := postMovements(tx, movements); err != nil {
+			return err
+		}
+
+		for _, line := range deliveryNote.Items {
+			if line.SalesOrderItemID == nil {
+				continue
+			}
+			quantity := line.StockQuantity()
+			if err := tx.Model(&models.SalesOrderItem{}).Where("id = ?", *line.SalesOrderItemID).
+				Update("delivered_qty", gorm.Expr("CASE WHEN delivered_qty > ? THEN delivered_qty - ? ELSE 0 END", quantity, quantity)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deliverSalesOrderItem 累加销售订单行的已发货数量（库存单位），累计数量不得超过订单数量加物料允许的超发比例；
+// 条件更新保证并发提交的送货单不会合计超发
+func deliverSalesOrderItem(tx *gorm.DB, salesOrderItemID uint, quantity float64) error {
+	var line struct {
+		Quantity              float64
+		DeliveredQty          float64
+		OverDeliveryAllowance float64
+		ItemCode              string
+	}
+	result := tx.Table("sales_order_items AS soi").
+		Select("soi.quantity, soi.delivered_qty, i.over_delivery_allowance, i.code AS item_code").
+		Joins("JOIN items AS i ON i.id = soi.item_id").
+		Where("soi.id = ? AND soi.deleted_at IS NULL", salesOrderItemID).
+		Scan(&line)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("销售订单明细 %d 不存在", salesOrderItemID)
+	}
+
+	limit := line.Quantity * (1 + line.OverDeliveryAllowance/100)
+	result = tx.Model(&models.SalesOrderItem{}).
+		Where("id = ? AND delivered_qty + ? <= ?", salesOrderItemID, quantity, limit+quantityEpsilon).
+		Update("delivered_qty", gorm.Expr("delivered_qty + ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w：物料 %s 订单数量 %.2f，已发货 %.2f，本次发货 %.2f，允许超发 %.2f%%",
+			ErrOverDelivery, line.ItemCode, line.Quantity, line.DeliveredQty, quantity, line.OverDeliveryAllowance)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// DunningRepository 催款仓储接口
+type DunningRepository interface {
+	BaseRepository[models.DunningLetter]
+	GetLevels(ctx context.Context, activeOnly bool) ([]*models.DunningLevel, error)
+	GetLevelByID(ctx context.Context, id uint) (*models.DunningLevel, error)
+	CreateLevel(ctx context.Context, level *models.DunningLevel) error
+	UpdateLevel(ctx context.Context, level *models.DunningLevel) error
+	DeleteLevel(ctx context.Context, id uint) error
+	GetLastLetterLevel(ctx context.Context, invoiceID uint) (int, error)
+	ListWithFilters(ctx context.Context, req *dto.DunningLetterListRequest) ([]*models.DunningLetter, int64, error)
+	GenerateLetterNumber(ctx context.Context) (string, error)
+	MarkOverdueInvoices(ctx context.Context, asOf time.Time) (int64, error)
+	GetOverdueInvoices(ctx context.Context, customerID *uint) ([]*models.SalesInvoice, error)
+	GetStatementInvoices(ctx context.Context, customerID uint, endDate time.Time) ([]*models.SalesInvoice, error)
+	GetStatementPayments(ctx context.Context, customerID uint, endDate time.Time) ([]*models.InvoicePayment, error)
+}
+
+// DunningRepositoryImpl 催款仓储实现
+type DunningRepositoryImpl struct {
+	BaseRepository[models.DunningLetter]
+	db *gorm.DB
+}
+
+// NewDunningRepository 创建催款仓储
+func NewDunningRepository(db *gorm.DB) DunningRepository {
+	return &DunningRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.DunningLetter](db),
+		db:             db,
+	}
+}
+
+// GetLevels 获取催款级别，按级别升序
+func (r *DunningRepositoryImpl) GetLevels(ctx context.Context, activeOnly bool) ([]*models.DunningLevel, error) {
+	var levels []*models.DunningLevel
+	query := r.db.WithContext(ctx).Model(&models.DunningLevel{})
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("level ASC").Find(&levels).Error
+	return levels, err
+}
+
+// GetLevelByID 根据ID获取催款级别
+func (r *DunningRepositoryImpl) GetLevelByID(ctx context.Context, id uint) (*models.DunningLevel, error) {
+	var level models.DunningLevel
+	if err := r.db.WithContext(ctx).First(&level, id).Error; err != nil {
+		return nil, err
+	}
+	return &level, nil
+}
+
+// CreateLevel 创建催款级别
+func (r *DunningRepositoryImpl) CreateLevel(ctx context.Context, level *models.DunningLevel) error {
+	return r.db.WithContext(ctx).Create(level).Error
+}
+
+// UpdateLevel 更新催款级别
+func (r *DunningRepositoryImpl) UpdateLevel(ctx context.Context, level *models.DunningLevel) error {
+	return r.db.WithContext(ctx).Save(level).Error
+}
+
+// DeleteLevel 删除催款级别
+func (r *DunningRepositoryImpl) DeleteLevel(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.DunningLevel{}, id).Error
+}
+
+// GetLastLetterLevel 获取发票已发送的最高催款级别，未发送过返回 0
+func (r *DunningRepositoryImpl) GetLastLetterLevel(ctx context.Context, invoiceID uint) (int, error) {
+	var level int
+	err := r.db.WithContext(ctx).Model(&models.DunningLetter{}).
+		Where("sales_invoice_id = ? AND status = ?", invoiceID, "sent").
+		Select("COALESCE(MAX(level), 0)").
+		Scan(&level).Error
+	return level, err
+}
+
+// ListWithFilters 获取催款函列表（带过滤条件）
+func (r *DunningRepositoryImpl) ListWithFilters(ctx context.Context, req *dto.DunningLetterListRequest) ([]*models.DunningLetter, int64, error) {
+	var letters []*models.DunningLetter
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.DunningLetter{})
+
+	if req.CustomerID != nil {
+		query = query.Where("customer_id = ?", *req.CustomerID)
+	}
+	if req.SalesInvoiceID != nil {
+		query = query.Where("sales_invoice_id = ?", *req.SalesInvoiceID)
+	}
+	if req.Level != nil {
+		query = query.Where("level = ?", *req.Level)
+	}
+	if req.TriggerType != "" {
+		query = query.Where("trigger_type = ?", req.TriggerType)
+	}
+	if req.StartDate != nil {
+		query = query.Where("letter_date >= ?", *req.StartDate)
+	}
+	if req.EndDate != nil {
+		query = query.Where("letter_date <= ?", *req.EndDate)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Customer").
+		Preload("SalesInvoice").
+		Preload("DunningLevel").
+		Offset(req.GetOffset()).
+		Limit(req.GetLimit()).
+		Order("letter_date DESC, id DESC").
+		Find(&letters).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return letters, total, nil
+}
+
+// GenerateLetterNumber 生成催款函编号
+func (r *DunningRepositoryImpl) GenerateLetterNumber(ctx context.Context) (string, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Unscoped().Model(&models.DunningLetter{}).Count(&count).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("DUN-%s-%06d", time.Now().Format("20060102"), count+1), nil
+}
+
+// MarkOverdueInvoices 将已过到期日且仍有未收金额的已提交发票标记为逾期
+func (r *DunningRepositoryImpl) MarkOverdueInvoices(ctx context.Context, asOf time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.SalesInvoice{}).
+		Where("doc_status = ?", "Submitted").
+		Where("payment_status IN ?", []string{"Unpaid", "Partially Paid"}).
+		Where("outstanding_amount > ?", 0).
+		Where("due_date < ?", asOf).
+		Update("payment_status", "Overdue")
+	return result.RowsAffected, result.Error
+}
+
+// GetOverdueInvoices 获取逾期且仍有未收金额的发票
+func (r *DunningRepositoryImpl) GetOverdueInvoices(ctx context.Context, customerID *uint) ([]*models.SalesInvoice, error) {
+	var invoices []*models.SalesInvoice
+	query := r.db.WithContext(ctx).Preload("Customer").
+		Where("doc_status = ?", "Submitted").
+		Where("payment_status = ?", "Overdue").
+		Where("outstanding_amount > ?", 0)
+	if customerID != nil {
+		query = query.Where("customer_id = ?", *customerID)
+	}
+	err := query.Order("customer_id ASC, due_date ASC").Find(&invoices).Error
+	return invoices, err
+}
+
+// GetStatementInvoices 获取客户截至结束日期的已提交发票
+func (r *DunningRepositoryImpl) GetStatementInvoices(ctx context.Context, customerID uint, endDate time.Time) ([]*models.SalesInvoice, error) {
+	var invoices []*models.SalesInvoice
+	err := r.db.WithContext(ctx).
+		Where("customer_id = ?", customerID).
+		Where("doc_status = ?", "Submitted").
+		Where("posting_date <= ?", endDate).
+		Order("posting_date ASC, id ASC").
+		Find(&invoices).Error
+	return invoices, err
+}
+
+// GetStatementPayments 获取客户截至结束日期的有效收款记录
+func (r *DunningRepositoryImpl) GetStatementPayments(ctx context.Context, customerID uint, endDate time.Time) ([]*models.InvoicePayment, error) {
+	var payments []*models.InvoicePayment
+	err := r.db.WithContext(ctx).
+		Preload("SalesInvoice").
+		Joins("JOIN sales_invoices ON sales_invoices.id = invoice_payments.sales_invoice_id").
+		Where("sales_invoices.customer_id = ?", customerID).
+		Where("sales_invoices.doc_status = ?", "Submitted").
+		Where("invoice_payments.status <> ?", "Cancelled").
+		Where("invoice_payments.payment_date <= ?", endDate).
+		Order("invoice_payments.payment_date ASC, invoice_payments.id ASC").
+		Find(&payments).Error
+	return payments, err
+}
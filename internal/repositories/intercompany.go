@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// IntercompanyRepository 内部交易仓储接口
+type IntercompanyRepository interface {
+	BaseRepository[models.IntercompanyTransaction]
+	GetBySource(ctx context.Context, docType string, docID uint) (*models.IntercompanyTransaction, error)
+	ListWithFilters(ctx context.Context, req *dto.IntercompanyTransactionListRequest) ([]*models.IntercompanyTransaction, int64, error)
+	GetSalesOrderWithItems(ctx context.Context, id uint) (*models.SalesOrder, error)
+	GetPurchaseOrderWithItems(ctx context.Context, id uint) (*models.PurchaseOrder, error)
+	GetCustomer(ctx context.Context, id uint) (*models.Customer, error)
+	GetSupplier(ctx context.Context, id uint) (*models.Supplier, error)
+	FindInternalCustomer(ctx context.Context, representsCompanyID uint) (*models.Customer, error)
+	FindInternalSupplier(ctx context.Context, representsCompanyID uint) (*models.Supplier, error)
+	CreatePurchaseOrderMirror(ctx context.Context, purchaseOrder *models.PurchaseOrder, link *models.IntercompanyTransaction) error
+	CreateSalesOrderMirror(ctx context.Context, salesOrder *models.SalesOrder, link *models.IntercompanyTransaction) error
+}
+
+// IntercompanyRepositoryImpl 内部交易仓储实现
+type IntercompanyRepositoryImpl struct {
+	BaseRepository[models.IntercompanyTransaction]
+	db *gorm.DB
+}
+
+// NewIntercompanyRepository 创建内部交易仓储实例
+func NewIntercompanyRepository(db *gorm.DB) IntercompanyRepository {
+	return &IntercompanyRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.IntercompanyTransaction](db),
+		db:             db,
+	}
+}
+
+// GetBySource 根据源单据获取内部交易记录，不存在时返回 nil
+func (r *IntercompanyRepositoryImpl) GetBySource(ctx context.Context, docType string, docID uint) (*models.IntercompanyTransaction, error) {
+	var link models.IntercompanyTransaction
+	err := r.db.WithContext(ctx).
+		Where("source_doc_type = ? AND source_doc_id = ?", docType, docID).
+		First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+// ListWithFilters 分页查询内部交易记录
+func (r *IntercompanyRepositoryImpl) ListWithFilters(ctx context.Context, req *dto.IntercompanyTransactionListRequest) ([]*models.IntercompanyTransaction, int64, error) {
+	var links []*models.IntercompanyTransaction
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.IntercompanyTransaction{})
+	if req.CompanyID != nil {
+		query = query.Where("source_company_id = ? OR target_company_id = ?", *req.CompanyID, *req.CompanyID)
+	}
+	if req.SourceDocType != "" {
+		query = query.Where("source_doc_type = ?", req.SourceDocType)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("SourceCompany").Preload("TargetCompany").
+		Order("created_at DESC").
+		Offset(req.GetOffset()).Limit(req.GetLimit()).
+		Find(&links).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return links, total, nil
+}
+
+// GetSalesOrderWithItems 获取销售订单及明细
+func (r *IntercompanyRepositoryImpl) GetSalesOrderWithItems(ctx context.Context, id uint) (*models.SalesOrder, error) {
+	var salesOrder models.SalesOrder
+	if err := r.db.WithContext(ctx).Preload("Items").First(&salesOrder, id).Error; err != nil {
+		return nil, err
+	}
+	return &salesOrder, nil
+}
+
+// GetPurchaseOrderWithItems 获取采购订单及明细
+func (r *IntercompanyRepositoryImpl) GetPurchaseOrderWithItems(ctx context.Context, id uint) (*models.PurchaseOrder, error) {
+	var purchaseOrder models.PurchaseOrder
+	if err := r.db.WithContext(ctx).Preload("Items").First(&purchaseOrder, id).Error; err != nil {
+		return nil, err
+	}
+	return &purchaseOrder, nil
+}
+
+// GetCustomer 获取客户
+func (r *IntercompanyRepositoryImpl) GetCustomer(ctx context.Context, id uint) (*models.Customer, error) {
+	var customer models.Customer
+	if err := r.db.WithContext(ctx).First(&customer, id).Error; err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetSupplier 获取供应商
+func (r *IntercompanyRepositoryImpl) GetSupplier(ctx context.Context, id uint) (*models.Supplier, error) {
+	var supplier models.Supplier
+	if err := r.db.WithContext(ctx).First(&supplier, id).Error; err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+// FindInternalCustomer 查找代表指定公司的内部客户，不存在时返回 nil
+func (r *IntercompanyRepositoryImpl) FindInternalCustomer(ctx context.Context, representsCompanyID uint) (*models.Customer, error) {
+	var customer models.Customer
+	err := r.db.WithContext(ctx).
+		Where("represents_company_id = ? AND is_active = ?", representsCompanyID, true).
+		Order("id ASC").
+		First(&customer).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// FindInternalSupplier 查找代表指定公司的内部供应商，不存在时返回 nil
+func (r *IntercompanyRepositoryImpl) FindInternalSupplier(ctx context.Context, representsCompanyID uint) (*models.Supplier, error) {
+	var supplier models.Supplier
+	err := r.db.WithContext(ctx).
+		Where("represents_company_id = ? AND is_active = ?", representsCompanyID, true).
+		Order("id ASC").
+		First(&supplier).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+// CreatePurchaseOrderMirror 在同一事务中创建镜像采购订单及内部交易记录
+func (r *IntercompanyRepositoryImpl) CreatePurchaseOrderMirror(ctx context.Context, purchaseOrder *models.PurchaseOrder, link *models.IntercompanyTransaction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(purchaseOrder).Error; err != nil {
+			return err
+		}
+		link.TargetDocID = purchaseOrder.ID
+		link.TargetDocNumber = purchaseOrder.OrderNumber
+		return tx.Create(link).Error
+	})
+}
+
+// CreateSalesOrderMirror 在同一事务中创建镜像销售订单及内部交易记录
+func (r *IntercompanyRepositoryImpl) CreateSalesOrderMirror(ctx context.Context, salesOrder *models.SalesOrder, link *models.IntercompanyTransaction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(salesOrder).Error; err != nil {
+			return err
+		}
+		link.TargetDocID = salesOrder.ID
+		link.TargetDocNumber = salesOrder.OrderNumber
+		return tx.Create(link).Error
+	})
+}
@@ -7,12 +7,23 @@ import (
 	"gorm.io/gorm"
 )
 
+// ItemSearchFilter 物料搜索条件，零值表示不筛选
+type ItemSearchFilter struct {
+	Keyword     string
+	TemplateID  uint  // 只返回该模板的变体
+	HasVariants *bool // true 只返回模板物料，false 排除模板物料
+	IsActive    *bool
+	Attributes  map[string]string // 属性编码 -> 取值，变体须同时满足全部属性
+}
+
 // ItemRepository 物料仓储接口
 type ItemRepository interface {
 	BaseRepository[models.Item]
 	GetBySKU(ctx context.Context, sku string) (*models.Item, error)
 	ListItems(ctx context.Context, offset, limit int) ([]*models.Item, int64, error)
 	Search(ctx context.Context, query string, offset, limit int) ([]*models.Item, int64, error)
+	SearchItems(ctx context.Context, filter ItemSearchFilter, offset, limit int) ([]*models.Item, int64, error)
+	GetStockQuantity(ctx context.Context, itemID uint) (float64, error)
 }
 
 // ItemRepositoryImpl 物料仓储实现
@@ -63,18 +74,44 @@ func (r *ItemRepositoryImpl) ListItems(ctx context.Context, offset, limit int) (
 
 // Search 搜索物料
 func (r *ItemRepositoryImpl) Search(ctx context.Context, query string, offset, limit int) ([]*models.Item, int64, error) {
-	var items []*models.Item
-	var total int64
+	return r.SearchItems(ctx, ItemSearchFilter{Keyword: query}, offset, limit)
+}
 
-	searchQuery := "%" + query + "%"
+// SearchItems 按关键字、模板与属性取值搜索物料，结果附带变体的属性取值
+func (r *ItemRepositoryImpl) SearchItems(ctx context.Context, filter ItemSearchFilter, offset, limit int) ([]*models.Item, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Item{})
+	if filter.Keyword != "" {
+		keyword := "%" + filter.Keyword + "%"
+		query = query.Where("name LIKE ? OR code LIKE ?", keyword, keyword)
+	}
+	if filter.TemplateID != 0 {
+		query = query.Where("variant_of = ?", filter.TemplateID)
+	}
+	if filter.HasVariants != nil {
+		query = query.Where("has_variants = ?", *filter.HasVariants)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	for code, value := range filter.Attributes {
+		query = query.Where(`EXISTS (SELECT 1 FROM item_variant_attributes AS iva
+			JOIN item_attributes AS a ON a.id = iva.attribute_id
+			JOIN item_attribute_values AS v ON v.id = iva.attribute_value_id
+			WHERE iva.item_id = items.id AND iva.deleted_at IS NULL AND a.code = ? AND LOWER(v.value) = LOWER(?))`, code, value)
+	}
 
-	// 获取总数
-	if err := r.db.WithContext(ctx).Model(&models.Item{}).Where("name LIKE ? OR code LIKE ? OR sku LIKE ?", searchQuery, searchQuery, searchQuery).Count(&total).Error; err != nil {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// 获取分页数据
-	err := r.db.WithContext(ctx).Preload("Category").Preload("Unit").Where("name LIKE ? OR code LIKE ? OR sku LIKE ?", searchQuery, searchQuery, searchQuery).Offset(offset).Limit(limit).Find(&items).Error
+	var items []*models.Item
+	err := query.
+		Preload("VariantAttributes.Attribute").
+		Preload("VariantAttributes.AttributeValue").
+		Order("code").
+		Offset(offset).Limit(limit).
+		Find(&items).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -82,6 +119,16 @@ func (r *ItemRepositoryImpl) Search(ctx context.Context, query string, offset, l
 	return items, total, nil
 }
 
+// GetStockQuantity 获取物料在所有仓库的库存总量
+func (r *ItemRepositoryImpl) GetStockQuantity(ctx context.Context, itemID uint) (float64, error) {
+	var quantity float64
+	err := r.db.WithContext(ctx).Model(&models.Stock{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("item_id = ?", itemID).
+		Scan(&quantity).Error
+	return quantity, err
+}
+
 // StockRepository 库存仓储接口
 type StockRepository interface {
 	BaseRepository[models.Stock]
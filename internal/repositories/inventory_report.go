@@ -0,0 +1,288 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// InventoryStockFilter 库存明细筛选条件，ID 为 0、字符串为空表示不筛选；
+// ItemID 为模板物料时匹配其全部变体，GroupByTemplate 时变体库存汇总到模板物料
+type InventoryStockFilter struct {
+	ItemID          uint
+	WarehouseID     uint
+	Category        string
+	Keyword         string
+	LowStockOnly    bool
+	IncludeZero     bool
+	GroupByTemplate bool
+}
+
+// InventoryStockRow 物料在仓库的库存明细
+type InventoryStockRow struct {
+	ItemID        uint
+	ItemCode      string
+	ItemName      string
+	Category      string
+	Unit          string
+	ReorderLevel  int
+	WarehouseID   uint
+	WarehouseCode string
+	WarehouseName string
+	Quantity      float64
+	ValuationRate models.Money
+	StockValue    models.Money
+}
+
+// ItemConsumptionRow 物料在期间内的出库消耗
+type ItemConsumptionRow struct {
+	ItemID           uint
+	ItemCode         string
+	ItemName         string
+	Category         string
+	ConsumedQty      float64
+	ConsumptionValue models.Money
+}
+
+// InboundMovementRow 增加库存的移动，用于按入库时间推算在库库存的库龄
+type InboundMovementRow struct {
+	ItemID      uint
+	WarehouseID uint
+	Quantity    float64
+	ReceivedAt  time.Time
+}
+
+// LastOutboundRow 物料在仓库最近一次出库的时间
+type LastOutboundRow struct {
+	ItemID         uint
+	WarehouseID    uint
+	LastOutboundAt time.Time
+}
+
+// InventoryReportRepository 库存报表仓储接口
+type InventoryReportRepository interface {
+	GetStockRows(ctx context.Context, filter InventoryStockFilter, offset, limit int) ([]InventoryStockRow, int64, error)
+	CountLowStockItems(ctx context.Context) (int64, error)
+	GetConsumption(ctx context.Context, start, end time.Time, warehouseID uint, category string, groupByTemplate bool) ([]ItemConsumptionRow, error)
+	GetInboundMovements(ctx context.Context, filter InventoryStockFilter, asOf time.Time) ([]InboundMovementRow, error)
+	GetLastOutbound(ctx context.Context, filter InventoryStockFilter, asOf time.Time) ([]LastOutboundRow, error)
+}
+
+// InventoryReportRepositoryImpl 库存报表仓储实现
+type InventoryReportRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewInventoryReportRepository 创建库存报表仓储实例
+func NewInventoryReportRepository(db *gorm.DB) InventoryReportRepository {
+	return &InventoryReportRepositoryImpl{db: db}
+}
+
+// GetStockRows 获取库存明细，limit 小于等于 0 时返回全部
+func (r *InventoryReportRepositoryImpl) GetStockRows(ctx context.Context, filter InventoryStockFilter, offset, limit int) ([]InventoryStockRow, int64, error) {
+	if filter.GroupByTemplate {
+		return r.getTemplateStockRows(ctx, filter, offset, limit)
+	}
+
+	query := r.stockRowsQuery(ctx, filter)
+	if filter.LowStockOnly {
+		query = query.Where("i.reorder_level > 0 AND s.quantity <= i.reorder_level")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Select(`s.item_id AS item_id,
+			i.code AS item_code,
+			i.name AS item_name,
+			i.category AS category,
+			i.unit AS unit,
+			i.reorder_level AS reorder_level,
+			s.warehouse_id AS warehouse_id,
+			w.code AS warehouse_code,
+			w.name AS warehouse_name,
+			s.quantity AS quantity,
+			s.valuation_rate AS valuation_rate,
+			s.stock_value AS stock_value`).
+		Order("w.code, i.code")
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+
+	var rows []InventoryStockRow
+	err := query.Scan(&rows).Error
+	return rows, total, err
+}
+
+// getTemplateStockRows 按模板物料与仓库汇总库存，非变体物料按自身汇总；单位成本为汇总金额除以汇总数量
+func (r *InventoryReportRepositoryImpl) getTemplateStockRows(ctx context.Context, filter InventoryStockFilter, offset, limit int) ([]InventoryStockRow, int64, error) {
+	grouped := func() *gorm.DB {
+		query := r.stockRowsQuery(ctx, filter).
+			Joins("JOIN items AS t ON t.id = COALESCE(i.variant_of, i.id)").
+			Group("t.id, t.code, t.name, t.category, t.unit, t.reorder_level, s.warehouse_id, w.code, w.name")
+		if filter.LowStockOnly {
+			query = query.Having("t.reorder_level > 0 AND SUM(s.quantity) <= t.reorder_level")
+		}
+		return query
+	}
+
+	var total int64
+	if err := r.db.WithContext(ctx).Table("(?) AS g", grouped().Select("t.id, s.warehouse_id")).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := grouped().Select(`t.id AS item_id,
+			t.code AS item_code,
+			t.name AS item_name,
+			t.category AS category,
+			t.unit AS unit,
+			t.reorder_level AS reorder_level,
+			s.warehouse_id AS warehouse_id,
+			w.code AS warehouse_code,
+			w.name AS warehouse_name,
+			SUM(s.quantity) AS quantity,
+			SUM(s.stock_value) AS stock_value`).
+		Order("w.code, t.code")
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+
+	var rows []InventoryStockRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range rows {
+		if rows[i].Quantity != 0 {
+			rate, err := rows[i].StockValue.Div(rows[i].Quantity)
+			if err != nil {
+				return nil, 0, err
+			}
+			rows[i].ValuationRate = rate
+		}
+	}
+	return rows, total, nil
+}
+
+// stockRowsQuery 构造库存明细的基础查询与公共筛选条件
+func (r *InventoryReportRepositoryImpl) stockRowsQuery(ctx context.Context, filter InventoryStockFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Table("stocks AS s").
+		Joins("JOIN items AS i ON i.id = s.item_id AND i.deleted_at IS NULL").
+		Joins("JOIN warehouses AS w ON w.id = s.warehouse_id AND w.deleted_at IS NULL").
+		Where("s.deleted_at IS NULL")
+	if filter.ItemID != 0 {
+		query = query.Where("(s.item_id = ? OR i.variant_of = ?)", filter.ItemID, filter.ItemID)
+	}
+	if filter.WarehouseID != 0 {
+		query = query.Where("s.warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.Category != "" {
+		query = query.Where("i.category = ?", filter.Category)
+	}
+	if filter.Keyword != "" {
+		keyword := "%" + filter.Keyword + "%"
+		query = query.Where("i.code LIKE ? OR i.name LIKE ?", keyword, keyword)
+	}
+	if !filter.IncludeZero {
+		query = query.Where("s.quantity <> 0")
+	}
+	return query
+}
+
+// CountLowStockItems 统计全部仓库合计库存不高于再订货点的启用物料数
+func (r *InventoryReportRepositoryImpl) CountLowStockItems(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Item{}).
+		Where("is_active = ? AND reorder_level > 0", true).
+		Where("COALESCE((SELECT SUM(s.quantity) FROM stocks AS s WHERE s.item_id = items.id AND s.deleted_at IS NULL), 0) <= reorder_level").
+		Count(&count).Error
+	return count, err
+}
+
+// GetConsumption 汇总启用物料在 [start, end) 期间的出库数量与出库成本，无出库的物料数量与金额为 0；
+// 模板物料本身不持有库存不参与统计，groupByTemplate 时变体的消耗汇总到模板物料
+func (r *InventoryReportRepositoryImpl) GetConsumption(ctx context.Context, start, end time.Time, warehouseID uint, category string, groupByTemplate bool) ([]ItemConsumptionRow, error) {
+	join := "LEFT JOIN movements AS m ON m.item_id = i.id AND m.deleted_at IS NULL AND m.movement_type = ? AND m.created_at >= ? AND m.created_at < ?"
+	args := []interface{}{models.MovementTypeOut, start, end}
+	if warehouseID != 0 {
+		join += " AND m.warehouse_id = ?"
+		args = append(args, warehouseID)
+	}
+
+	// 汇总对象：按模板汇总时为模板物料，否则为物料本身
+	target := "i"
+	query := r.db.WithContext(ctx).Table("items AS i")
+	if groupByTemplate {
+		target = "t"
+		query = query.Joins("JOIN items AS t ON t.id = COALESCE(i.variant_of, i.id)")
+	}
+	query = query.
+		Select(target+`.id AS item_id,
+			MAX(`+target+`.code) AS item_code,
+			MAX(`+target+`.name) AS item_name,
+			MAX(`+target+`.category) AS category,
+			COALESCE(SUM(-m.quantity_change), 0) AS consumed_qty,
+			COALESCE(SUM(-m.value_change), 0) AS consumption_value`).
+		Joins(join, args...).
+		Where("i.deleted_at IS NULL AND i.is_active = ? AND i.has_variants = ?", true, false)
+	if category != "" {
+		query = query.Where("i.category = ?", category)
+	}
+
+	var rows []ItemConsumptionRow
+	err := query.Group(target + ".id").Scan(&rows).Error
+	return rows, err
+}
+
+// GetInboundMovements 获取截至 asOf 增加库存的移动（入库、调入、期初与盘盈调整），按时间由近及远排列
+func (r *InventoryReportRepositoryImpl) GetInboundMovements(ctx context.Context, filter InventoryStockFilter, asOf time.Time) ([]InboundMovementRow, error) {
+	var rows []InboundMovementRow
+	err := r.movementQuery(ctx, filter).
+		Select("m.item_id AS item_id, m.warehouse_id AS warehouse_id, m.quantity_change AS quantity, m.created_at AS received_at").
+		Where("m.quantity_change > 0 AND m.created_at < ?", asOf).
+		Order("m.created_at DESC, m.id DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetLastOutbound 获取物料在各仓库截至 asOf 最近一次出库或调出的时间
+func (r *InventoryReportRepositoryImpl) GetLastOutbound(ctx context.Context, filter InventoryStockFilter, asOf time.Time) ([]LastOutboundRow, error) {
+	// 先取各物料仓库最新出库移动的ID再读取其时间，聚合函数在 SQLite 下返回文本无法扫描为时间
+	latest := r.movementQuery(ctx, filter).
+		Select("MAX(m.id)").
+		Where("m.movement_type IN ? AND m.created_at < ?", []string{models.MovementTypeOut, models.MovementTypeTransferOut}, asOf).
+		Group("m.item_id, m.warehouse_id")
+
+	var rows []LastOutboundRow
+	err := r.db.WithContext(ctx).Table("movements").
+		Select("item_id, warehouse_id, created_at AS last_outbound_at").
+		Where("id IN (?)", latest).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// movementQuery 构造库存移动查询，筛选条件与库存明细一致
+func (r *InventoryReportRepositoryImpl) movementQuery(ctx context.Context, filter InventoryStockFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Table("movements AS m").
+		Joins("JOIN items AS i ON i.id = m.item_id AND i.deleted_at IS NULL").
+		Where("m.deleted_at IS NULL")
+	if filter.ItemID != 0 {
+		query = query.Where("(m.item_id = ? OR i.variant_of = ?)", filter.ItemID, filter.ItemID)
+	}
+	if filter.WarehouseID != 0 {
+		query = query.Where("m.warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.Category != "" {
+		query = query.Where("i.category = ?", filter.Category)
+	}
+	if filter.Keyword != "" {
+		keyword := "%" + filter.Keyword + "%"
+		query = query.Where("i.code LIKE ? OR i.name LIKE ?", keyword, keyword)
+	}
+	return query
+}
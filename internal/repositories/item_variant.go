@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// ItemVariantRepository 物料属性、模板属性与变体仓储接口
+type ItemVariantRepository interface {
+	BaseRepository[models.ItemAttribute]
+	GetAttribute(ctx context.Context, id uint) (*models.ItemAttribute, error)
+	GetAttributeByCode(ctx context.Context, code string) (*models.ItemAttribute, error)
+	ListAttributes(ctx context.Context) ([]*models.ItemAttribute, error)
+	SaveAttribute(ctx context.Context, attribute *models.ItemAttribute) error
+	DeleteAttribute(ctx context.Context, id uint) error
+	CountAttributeUsage(ctx context.Context, attributeID uint) (int64, error)
+	GetAttributeValue(ctx context.Context, id uint) (*models.ItemAttributeValue, error)
+	CreateAttributeValue(ctx context.Context, value *models.ItemAttributeValue) error
+	DeleteAttributeValue(ctx context.Context, id uint) error
+	CountAttributeValueUsage(ctx context.Context, valueID uint) (int64, error)
+	GetTemplateAttributes(ctx context.Context, itemID uint) ([]*models.ItemTemplateAttribute, error)
+	ReplaceTemplateAttributes(ctx context.Context, itemID uint, attributeIDs []uint) error
+	ListVariants(ctx context.Context, templateID uint) ([]*models.Item, error)
+	CountVariants(ctx context.Context, templateID uint) (int64, error)
+	GetExistingItemCodes(ctx context.Context, codes []string) ([]string, error)
+	CreateVariants(ctx context.Context, templateID uint, variants []*models.Item) error
+	GetStockQuantities(ctx context.Context, itemIDs []uint) (map[uint]float64, error)
+}
+
+// ItemVariantRepositoryImpl 物料变体仓储实现
+type ItemVariantRepositoryImpl struct {
+	BaseRepository[models.ItemAttribute]
+	db *gorm.DB
+}
+
+// NewItemVariantRepository 创建物料变体仓储实例
+func NewItemVariantRepository(db *gorm.DB) ItemVariantRepository {
+	return &ItemVariantRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.ItemAttribute](db),
+		db:             db,
+	}
+}
+
+// preloadAttributeValues 按排序号与取值加载属性的可选取值
+func preloadAttributeValues(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order, value")
+}
+
+// GetAttribute 根据ID获取物料属性及其取值
+func (r *ItemVariantRepositoryImpl) GetAttribute(ctx context.Context, id uint) (*models.ItemAttribute, error) {
+	var attribute models.ItemAttribute
+	if err := r.db.WithContext(ctx).Preload("Values", preloadAttributeValues).First(&attribute, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attribute, nil
+}
+
+// GetAttributeByCode 根据编码获取物料属性
+func (r *ItemVariantRepositoryImpl) GetAttributeByCode(ctx context.Context, code string) (*models.ItemAttribute, error) {
+	var attribute models.ItemAttribute
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&attribute).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attribute, nil
+}
+
+// ListAttributes 获取全部物料属性及其取值
+func (r *ItemVariantRepositoryImpl) ListAttributes(ctx context.Context) ([]*models.ItemAttribute, error) {
+	var attributes []*models.ItemAttribute
+	err := r.db.WithContext(ctx).Preload("Values", preloadAttributeValues).Order("code").Find(&attributes).Error
+	return attributes, err
+}
+
+// SaveAttribute 保存物料属性，不处理取值
+func (r *ItemVariantRepositoryImpl) SaveAttribute(ctx context.Context, attribute *models.ItemAttribute) error {
+	return r.db.WithContext(ctx).Omit("Values").Save(attribute).Error
+}
+
+// DeleteAttribute 删除物料属性及其取值
+func (r *ItemVariantRepositoryImpl) DeleteAttribute(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("attribute_id = ?", id).Delete(&models.ItemAttributeValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ItemAttribute{}, id).Error
+	})
+}
+
+// CountAttributeUsage 统计使用该属性的模板物料数量
+func (r *ItemVariantRepositoryImpl) CountAttributeUsage(ctx context.Context, attributeID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ItemTemplateAttribute{}).Where("attribute_id = ?", attributeID).Count(&count).Error
+	return count, err
+}
+
+// GetAttributeValue 根据ID获取属性取值
+func (r *ItemVariantRepositoryImpl) GetAttributeValue(ctx context.Context, id uint) (*models.ItemAttributeValue, error) {
+	var value models.ItemAttributeValue
+	if err := r.db.WithContext(ctx).First(&value, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &value, nil
+}
+
+// CreateAttributeValue 创建属性取值
+func (r *ItemVariantRepositoryImpl) CreateAttributeValue(ctx context.Context, value *models.ItemAttributeValue) error {
+	return r.db.WithContext(ctx).Create(value).Error
+}
+
+// DeleteAttributeValue 删除属性取值
+func (r *ItemVariantRepositoryImpl) DeleteAttributeValue(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ItemAttributeValue{}, id).Error
+}
+
+// CountAttributeValueUsage 统计使用该取值的变体物料数量
+func (r *ItemVariantRepositoryImpl) CountAttributeValueUsage(ctx context.Context, valueID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ItemVariantAttribute{}).Where("attribute_value_id = ?", valueID).Count(&count).Error
+	return count, err
+}
+
+// GetTemplateAttributes 按顺序获取模板物料使用的属性及其取值
+func (r *ItemVariantRepositoryImpl) GetTemplateAttributes(ctx context.Context, itemID uint) ([]*models.ItemTemplateAttribute, error) {
+	var attributes []*models.ItemTemplateAttribute
+	err := r.db.WithContext(ctx).
+		Preload("Attribute").
+		Preload("Attribute.Values", preloadAttributeValues).
+		Where("item_id = ?", itemID).
+		Order("sort_order, id").
+		Find(&attributes).Error
+	return attributes, err
+}
+
+// ReplaceTemplateAttributes 替换模板物料使用的属性并将物料标记为模板
+func (r *ItemVariantRepositoryImpl) ReplaceTemplateAttributes(ctx context.Context, itemID uint, attributeIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 物理删除旧记录，避免软删除的行占用唯一索引
+		if err := tx.Unscoped().Where("item_id = ?", itemID).Delete(&models.ItemTemplateAttribute{}).Error; err != nil {
+			return err
+		}
+		for i, attributeID := range attributeIDs {
+			attribute := &models.ItemTemplateAttribute{ItemID: itemID, AttributeID: attributeID, SortOrder: i + 1}
+			if err := tx.Create(attribute).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Item{}).Where("id = ?", itemID).Update("has_variants", len(attributeIDs) > 0).Error
+	})
+}
+
+// ListVariants 获取模板物料的全部变体及其属性取值
+func (r *ItemVariantRepositoryImpl) ListVariants(ctx context.Context, templateID uint) ([]*models.Item, error) {
+	var variants []*models.Item
+	err := r.db.WithContext(ctx).
+		Preload("VariantAttributes.Attribute").
+		Preload("VariantAttributes.AttributeValue").
+		Where("variant_of = ?", templateID).
+		Order("code").
+		Find(&variants).Error
+	return variants, err
+}
+
+// CountVariants 统计模板物料的变体数量
+func (r *ItemVariantRepositoryImpl) CountVariants(ctx context.Context, templateID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Item{}).Where("variant_of = ?", templateID).Count(&count).Error
+	return count, err
+}
+
+// GetExistingItemCodes 返回已被物料占用的编码，含已删除的物料
+func (r *ItemVariantRepositoryImpl) GetExistingItemCodes(ctx context.Context, codes []string) ([]string, error) {
+	var existing []string
+	if len(codes) == 0 {
+		return existing, nil
+	}
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Item{}).Where("code IN ?", codes).Pluck("code", &existing).Error
+	return existing, err
+}
+
+// CreateVariants 在同一事务中创建变体物料及其属性取值，并复制模板物料的单位换算
+func (r *ItemVariantRepositoryImpl) CreateVariants(ctx context.Context, templateID uint, variants []*models.Item) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var conversions []models.ItemUOMConversion
+		if err := tx.Where("item_id = ?", templateID).Find(&conversions).Error; err != nil {
+			return err
+		}
+		for _, variant := range variants {
+			if err := tx.Create(variant).Error; err != nil {
+				return fmt.Errorf("创建变体 %s 失败: %w", variant.Code, err)
+			}
+			for _, conversion := range conversions {
+				copied := models.ItemUOMConversion{ItemID: variant.ID, UOMID: conversion.UOMID, Factor: conversion.Factor}
+				if err := tx.Create(&copied).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// GetStockQuantities 获取物料在全部仓库的库存合计
+func (r *ItemVariantRepositoryImpl) GetStockQuantities(ctx context.Context, itemIDs []uint) (map[uint]float64, error) {
+	quantities := make(map[uint]float64, len(itemIDs))
+	if len(itemIDs) == 0 {
+		return quantities, nil
+	}
+	var rows []struct {
+		ItemID   uint
+		Quantity float64
+	}
+	err := r.db.WithContext(ctx).Model(&models.Stock{}).
+		Select("item_id, SUM(quantity) AS quantity").
+		Where("item_id IN ?", itemIDs).
+		Group("item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		quantities[row.ItemID] = row.Quantity
+	}
+	return quantities, nil
+}
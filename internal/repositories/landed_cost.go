@@ -0,0 +1,327 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// LandedCostFilter 到岸成本单筛选条件，ID 为 0、字符串为空表示不筛选
+type LandedCostFilter struct {
+	Status            string
+	PurchaseReceiptID uint
+}
+
+// ReceiptStockRow 采购收货明细在各仓库的入库数量与金额，来源为引用收货单的入库移动
+type ReceiptStockRow struct {
+	PurchaseReceiptID     uint
+	PurchaseReceiptItemID uint
+	ItemID                uint
+	WarehouseID           uint
+	Quantity              float64
+	Amount                models.Money
+}
+
+// LandedCostCredit 到岸成本凭证的贷方分录
+type LandedCostCredit struct {
+	AccountID   uint
+	Amount      models.Money
+	Description string
+}
+
+// LandedCostJournal 到岸成本总账凭证：在库部分借存货科目，已出库部分借销售成本科目，按费用贷记各费用科目
+type LandedCostJournal struct {
+	CompanyID          uint
+	InventoryAccountID uint
+	ExpenseAccountID   uint
+	Credits            []LandedCostCredit
+	Date               time.Time
+	Description        string
+}
+
+// LandedCostRepository 到岸成本仓储接口
+type LandedCostRepository interface {
+	BaseRepository[models.LandedCostVoucher]
+	GetVoucher(ctx context.Context, id uint) (*models.LandedCostVoucher, error)
+	ListVouchers(ctx context.Context, filter LandedCostFilter, offset, limit int) ([]*models.LandedCostVoucher, int64, error)
+	GetPurchaseReceipt(ctx context.Context, id uint) (*models.PurchaseReceipt, error)
+	GetReceiptStockRows(ctx context.Context, receiptIDs []uint) ([]ReceiptStockRow, error)
+	GetItemsByIDs(ctx context.Context, ids []uint) (map[uint]*models.Item, error)
+	UpdateVoucher(ctx context.Context, voucher *models.LandedCostVoucher, updates map[string]interface{}) error
+	SubmitVoucher(ctx context.Context, voucher *models.LandedCostVoucher, journal *LandedCostJournal) error
+}
+
+// LandedCostRepositoryImpl 到岸成本仓储实现
+type LandedCostRepositoryImpl struct {
+	BaseRepository[models.LandedCostVoucher]
+	db *gorm.DB
+}
+
+// NewLandedCostRepository 创建到岸成本仓储实例
+func NewLandedCostRepository(db *gorm.DB) LandedCostRepository {
+	return &LandedCostRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.LandedCostVoucher](db),
+		db:             db,
+	}
+}
+
+// GetVoucher 获取到岸成本单及其收货单、费用与分摊行
+func (r *LandedCostRepositoryImpl) GetVoucher(ctx context.Context, id uint) (*models.LandedCostVoucher, error) {
+	var voucher models.LandedCostVoucher
+	err := r.db.WithContext(ctx).
+		Preload("Receipts", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Receipts.PurchaseReceipt").
+		Preload("Charges", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Item").
+		First(&voucher, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+// ListVouchers 分页获取到岸成本单
+func (r *LandedCostRepositoryImpl) ListVouchers(ctx context.Context, filter LandedCostFilter, offset, limit int) ([]*models.LandedCostVoucher, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.LandedCostVoucher{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.PurchaseReceiptID != 0 {
+		query = query.Where("id IN (?)", r.db.Model(&models.LandedCostReceipt{}).
+			Select("landed_cost_voucher_id").
+			Where("purchase_receipt_id = ?", filter.PurchaseReceiptID))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var vouchers []*models.LandedCostVoucher
+	err := query.Preload("Receipts.PurchaseReceipt").
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&vouchers).Error
+	return vouchers, total, err
+}
+
+// GetPurchaseReceipt 根据ID获取采购收货单
+func (r *LandedCostRepositoryImpl) GetPurchaseReceipt(ctx context.Context, id uint) (*models.PurchaseReceipt, error) {
+	var receipt models.PurchaseReceipt
+	if err := r.db.WithContext(ctx).First(&receipt, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// GetReceiptStockRows 按收货明细与仓库汇总收货单的入库数量与入库金额
+func (r *LandedCostRepositoryImpl) GetReceiptStockRows(ctx context.Context, receiptIDs []uint) ([]ReceiptStockRow, error) {
+	var rows []ReceiptStockRow
+	if len(receiptIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.WithContext(ctx).
+		Table("movements AS m").
+		Select(`m.reference_id AS purchase_receipt_id,
+			m.reference_line_id AS purchase_receipt_item_id,
+			m.item_id AS item_id,
+			m.warehouse_id AS warehouse_id,
+			COALESCE(SUM(m.quantity_change), 0) AS quantity,
+			COALESCE(SUM(m.value_change), 0) AS amount`).
+		Where("m.deleted_at IS NULL").
+		Where("m.reference_type = ? AND m.reference_id IN ? AND m.reference_line_id IS NOT NULL", models.MovementReferencePurchaseReceipt, receiptIDs).
+		Where("m.quantity_change > 0").
+		Group("m.reference_id, m.reference_line_id, m.item_id, m.warehouse_id").
+		Order("purchase_receipt_id, purchase_receipt_item_id, warehouse_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetItemsByIDs 批量获取物料，按ID索引
+func (r *LandedCostRepositoryImpl) GetItemsByIDs(ctx context.Context, ids []uint) (map[uint]*models.Item, error) {
+	items := make(map[uint]*models.Item, len(ids))
+	if len(ids) == 0 {
+		return items, nil
+	}
+	var list []*models.Item
+	if err := r.db.WithContext(ctx).Unscoped().Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range list {
+		items[item.ID] = item
+	}
+	return items, nil
+}
+
+// UpdateVoucher 更新到岸成本单字段
+func (r *LandedCostRepositoryImpl) UpdateVoucher(ctx context.Context, voucher *models.LandedCostVoucher, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(voucher).Updates(updates).Error
+}
+
+// SubmitVoucher 在同一事务中提交到岸成本单：按各分摊行入库数量中仍在库的比例拆分分摊金额，
+// 在库部分以重估移动计入库存金额，先进先出物料同时提高收货入库形成的成本层单价；
+// 已出库部分与标准成本物料的分摊金额计入销售成本，最后生成总账凭证并将单据标记为已提交
+func (r *LandedCostRepositoryImpl) SubmitVoucher(ctx context.Context, voucher *models.LandedCostVoucher, journal *LandedCostJournal) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var capitalized, expensed models.Money
+		for i := range voucher.Items {
+			line := &voucher.Items[i]
+			if line.AllocatedAmount.IsZero() || line.Quantity <= quantityEpsilon {
+				continue
+			}
+
+			lineCapitalized, err := capitalizeLandedCost(tx, voucher, line)
+			if err != nil {
+				return fmt.Errorf("收货明细 %d 分摊失败: %w", line.PurchaseReceiptItemID, err)
+			}
+			line.CapitalizedAmount = lineCapitalized
+			line.ExpensedAmount = line.AllocatedAmount.Sub(lineCapitalized)
+			capitalized = capitalized.Add(line.CapitalizedAmount)
+			expensed = expensed.Add(line.ExpensedAmount)
+			if err := tx.Model(&models.LandedCostItem{}).Where("id = ?", line.ID).Updates(map[string]interface{}{
+				"capitalized_amount": line.CapitalizedAmount,
+				"expensed_amount":    line.ExpensedAmount,
+				"movement_id":        line.MovementID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":             models.LandedCostStatusSubmitted,
+			"capitalized_amount": capitalized,
+			"expensed_amount":    expensed,
+			"submitted_at":       now,
+		}
+		if journal != nil && voucher.TotalCharges.IsPositive() {
+			transaction := &models.Transaction{
+				CompanyID:         journal.CompanyID,
+				TransactionNumber: fmt.Sprintf("TXN-%d", now.UnixNano()),
+				TransactionDate:   journal.Date,
+				TransactionType:   "journal",
+				Amount:            voucher.TotalCharges,
+				Description:       journal.Description,
+				ReferenceType:     models.MovementReferenceLandedCost,
+				ReferenceID:       &voucher.ID,
+				Status:            "completed",
+			}
+			if err := tx.Create(transaction).Error; err != nil {
+				return err
+			}
+
+			var entries []*models.JournalEntry
+			if capitalized.IsPositive() {
+				entries = append(entries, &models.JournalEntry{AccountID: journal.InventoryAccountID, Debit: capitalized, Description: "到岸成本计入存货"})
+			}
+			if expensed.IsPositive() {
+				entries = append(entries, &models.JournalEntry{AccountID: journal.ExpenseAccountID, Debit: expensed, Description: "到岸成本计入销售成本"})
+			}
+			for _, credit := range journal.Credits {
+				entries = append(entries, &models.JournalEntry{AccountID: credit.AccountID, Credit: credit.Amount, Description: credit.Description})
+			}
+			for _, entry := range entries {
+				entry.TransactionID = transaction.ID
+				entry.CompanyID = transaction.CompanyID
+				if err := tx.Create(entry).Error; err != nil {
+					return err
+				}
+			}
+			updates["transaction_id"] = transaction.ID
+		}
+		return tx.Model(voucher).Updates(updates).Error
+	})
+}
+
+// capitalizeLandedCost 将分摊行中仍在库的部分计入库存金额，返回计入的金额。
+// 先进先出物料按收货入库成本层的剩余数量计算在库比例，并按分摊单价提高这些成本层的单价；
+// 移动加权平均物料按仓库当前库存与入库数量的较小者计算在库比例；标准成本物料不调整库存金额
+func capitalizeLandedCost(tx *gorm.DB, voucher *models.LandedCostVoucher, line *models.LandedCostItem) (models.Money, error) {
+	stock, err := lockStock(tx, line.ItemID, line.WarehouseID)
+	if err != nil {
+		return 0, err
+	}
+	var item models.Item
+	if err := tx.Unscoped().Select("id, code, valuation_method").First(&item, line.ItemID).Error; err != nil {
+		return 0, err
+	}
+
+	var capitalized models.Money
+	switch item.ValuationMethod {
+	case models.ValuationMethodStandard:
+		return 0, nil
+	case models.ValuationMethodFIFO:
+		var movementIDs []uint
+		if err := tx.Model(&models.Movement{}).
+			Where("reference_type = ? AND reference_id = ? AND reference_line_id = ?", models.MovementReferencePurchaseReceipt, line.PurchaseReceiptID, line.PurchaseReceiptItemID).
+			Where("item_id = ? AND warehouse_id = ? AND quantity_change > 0", line.ItemID, line.WarehouseID).
+			Pluck("id", &movementIDs).Error; err != nil {
+			return 0, err
+		}
+		var layers []models.StockCostLayer
+		if len(movementIDs) > 0 {
+			if err := tx.Where("movement_id IN ?", movementIDs).Find(&layers).Error; err != nil {
+				return 0, err
+			}
+		}
+		unitCharge, err := line.AllocatedAmount.Div(line.Quantity)
+		if err != nil {
+			return 0, err
+		}
+		var remaining float64
+		for _, layer := range layers {
+			remaining += layer.RemainingQty
+			if err := tx.Model(&models.StockCostLayer{}).Where("id = ?", layer.ID).
+				Update("unit_cost", layer.UnitCost.Add(unitCharge)).Error; err != nil {
+				return 0, err
+			}
+		}
+		if capitalized, err = line.AllocatedAmount.Mul(math.Min(remaining/line.Quantity, 1)); err != nil {
+			return 0, err
+		}
+	default:
+		if stock.Quantity > quantityEpsilon {
+			if capitalized, err = line.AllocatedAmount.Mul(math.Min(stock.Quantity/line.Quantity, 1)); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if capitalized.IsZero() {
+		return 0, nil
+	}
+
+	zero := 0.0
+	itemID, warehouseID := line.ItemID, line.WarehouseID
+	key := fmt.Sprintf("%s:%d:%d", models.MovementReferenceLandedCost, voucher.ID, line.ID)
+	movement := &models.Movement{
+		ItemID:          &itemID,
+		WarehouseID:     &warehouseID,
+		Quantity:        &zero,
+		MovementType:    models.MovementTypeRevaluation,
+		Reference:       voucher.VoucherNumber,
+		ReferenceType:   models.MovementReferenceLandedCost,
+		ReferenceID:     &voucher.ID,
+		ReferenceLineID: &line.ID,
+		IdempotencyKey:  &key,
+		ValueChange:     capitalized,
+		Notes:           fmt.Sprintf("到岸成本 %s", voucher.VoucherNumber),
+		CreatedBy:       voucher.CreatedBy,
+	}
+	if err := postMovement(tx, movement); err != nil {
+		return 0, err
+	}
+	line.MovementID = &movement.ID
+	return capitalized, nil
+}
@@ -0,0 +1,406 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// LocationStockFilter 库位库存筛选条件，ID 为 0 表示不筛选
+type LocationStockFilter struct {
+	WarehouseID uint
+	LocationID  uint
+	ItemID      uint
+}
+
+// LocationMoveFilter 移库记录筛选条件
+type LocationMoveFilter struct {
+	WarehouseID uint
+	ItemID      uint
+	LocationID  uint // 调出或调入库位
+}
+
+// LocationStockRow 库位库存明细
+type LocationStockRow struct {
+	ItemID          uint
+	ItemCode        string
+	ItemName        string
+	WarehouseID     uint
+	LocationID      uint
+	LocationCode    string
+	LocationName    string
+	BatchID         uint
+	BatchNo         string
+	ExpiryDate      *time.Time
+	Quantity        float64
+	FirstReceivedAt *time.Time
+}
+
+// UnassignedStockRow 尚未分配库位的仓库库存
+type UnassignedStockRow struct {
+	ItemID      uint
+	ItemCode    string
+	ItemName    string
+	WarehouseID uint
+	Quantity    float64
+}
+
+// LocationRepository 库位、上架规则与移库仓储接口
+type LocationRepository interface {
+	BaseRepository[models.Location]
+	GetLocation(ctx context.Context, id uint) (*models.Location, error)
+	GetByCode(ctx context.Context, warehouseID uint, code string) (*models.Location, error)
+	ListByWarehouse(ctx context.Context, warehouseID uint) ([]*models.Location, error)
+	GetLocationUsage(ctx context.Context, locationIDs []uint) (map[uint]float64, error)
+	GetLocationStocks(ctx context.Context, filter LocationStockFilter) ([]LocationStockRow, error)
+	GetUnassignedStocks(ctx context.Context, warehouseID, itemID uint) ([]UnassignedStockRow, error)
+	GetUnassignedQuantity(ctx context.Context, itemID, warehouseID uint, batchID *uint) (float64, error)
+	GetPickCandidates(ctx context.Context, itemID, warehouseID uint, batchID *uint, strategy string) ([]LocationStockRow, error)
+	GetQuarantineLocation(ctx context.Context, warehouseID uint) (*models.Location, error)
+	GetExpiredLocationStocks(ctx context.Context, warehouseID uint, asOf time.Time) ([]LocationStockRow, error)
+	CreatePutawayRule(ctx context.Context, rule *models.PutawayRule) error
+	GetPutawayRule(ctx context.Context, id uint) (*models.PutawayRule, error)
+	SavePutawayRule(ctx context.Context, rule *models.PutawayRule) error
+	DeletePutawayRule(ctx context.Context, id uint) error
+	ListPutawayRules(ctx context.Context, warehouseID uint) ([]*models.PutawayRule, error)
+	GetMatchingPutawayRules(ctx context.Context, warehouseID, itemID uint, category string) ([]*models.PutawayRule, error)
+	MoveStock(ctx context.Context, move *models.LocationMove) error
+	ListLocationMoves(ctx context.Context, filter LocationMoveFilter, offset, limit int) ([]*models.LocationMove, int64, error)
+	GetDeliveryNoteItems(ctx context.Context, deliveryNoteID uint) ([]models.DeliveryNoteItem, error)
+}
+
+// LocationRepositoryImpl 库位仓储实现
+type LocationRepositoryImpl struct {
+	BaseRepository[models.Location]
+	db *gorm.DB
+}
+
+// NewLocationRepository 创建库位仓储实例
+func NewLocationRepository(db *gorm.DB) LocationRepository {
+	return &LocationRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.Location](db),
+		db:             db,
+	}
+}
+
+// GetLocation 根据ID获取库位
+func (r *LocationRepositoryImpl) GetLocation(ctx context.Context, id uint) (*models.Location, error) {
+	var location models.Location
+	if err := r.db.WithContext(ctx).First(&location, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &location, nil
+}
+
+// GetByCode 根据仓库和编码获取库位
+func (r *LocationRepositoryImpl) GetByCode(ctx context.Context, warehouseID uint, code string) (*models.Location, error) {
+	var location models.Location
+	err := r.db.WithContext(ctx).Where("warehouse_id = ? AND code = ?", warehouseID, code).First(&location).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &location, nil
+}
+
+// ListByWarehouse 获取仓库的全部库位
+func (r *LocationRepositoryImpl) ListByWarehouse(ctx context.Context, warehouseID uint) ([]*models.Location, error) {
+	var locations []*models.Location
+	err := r.db.WithContext(ctx).Where("warehouse_id = ?", warehouseID).Order("sequence, code").Find(&locations).Error
+	return locations, err
+}
+
+// GetLocationUsage 获取库位已占用的数量（全部物料合计）
+func (r *LocationRepositoryImpl) GetLocationUsage(ctx context.Context, locationIDs []uint) (map[uint]float64, error) {
+	usage := make(map[uint]float64, len(locationIDs))
+	if len(locationIDs) == 0 {
+		return usage, nil
+	}
+	var rows []struct {
+		LocationID uint
+		Quantity   float64
+	}
+	err := r.db.WithContext(ctx).Model(&models.LocationStock{}).
+		Select("location_id, COALESCE(SUM(quantity), 0) AS quantity").
+		Where("location_id IN ?", locationIDs).
+		Group("location_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		usage[row.LocationID] = row.Quantity
+	}
+	return usage, nil
+}
+
+// GetLocationStocks 获取库位库存明细，只返回非零余额
+func (r *LocationRepositoryImpl) GetLocationStocks(ctx context.Context, filter LocationStockFilter) ([]LocationStockRow, error) {
+	query := r.locationStockQuery(ctx)
+	if filter.WarehouseID != 0 {
+		query = query.Where("ls.warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.LocationID != 0 {
+		query = query.Where("ls.location_id = ?", filter.LocationID)
+	}
+	if filter.ItemID != 0 {
+		query = query.Where("ls.item_id = ?", filter.ItemID)
+	}
+
+	var rows []LocationStockRow
+	err := query.Order("l.sequence, l.code, i.code, b.batch_no").Scan(&rows).Error
+	return rows, err
+}
+
+// GetUnassignedStocks 获取仓库库存中尚未分配库位的数量
+func (r *LocationRepositoryImpl) GetUnassignedStocks(ctx context.Context, warehouseID, itemID uint) ([]UnassignedStockRow, error) {
+	located := r.db.Model(&models.LocationStock{}).
+		Select("item_id, warehouse_id, SUM(quantity) AS quantity").
+		Where("deleted_at IS NULL").
+		Group("item_id, warehouse_id")
+
+	query := r.db.WithContext(ctx).
+		Table("stocks AS s").
+		Select(`s.item_id AS item_id,
+			i.code AS item_code,
+			i.name AS item_name,
+			s.warehouse_id AS warehouse_id,
+			s.quantity - COALESCE(l.quantity, 0) AS quantity`).
+		Joins("JOIN items AS i ON i.id = s.item_id").
+		Joins("LEFT JOIN (?) AS l ON l.item_id = s.item_id AND l.warehouse_id = s.warehouse_id", located).
+		Where("s.deleted_at IS NULL").
+		Where("ABS(s.quantity - COALESCE(l.quantity, 0)) > ?", quantityEpsilon)
+	if warehouseID != 0 {
+		query = query.Where("s.warehouse_id = ?", warehouseID)
+	}
+	if itemID != 0 {
+		query = query.Where("s.item_id = ?", itemID)
+	}
+
+	var rows []UnassignedStockRow
+	err := query.Order("i.code").Scan(&rows).Error
+	return rows, err
+}
+
+// GetUnassignedQuantity 获取物料在仓库（或指定批次）尚未分配库位的数量
+func (r *LocationRepositoryImpl) GetUnassignedQuantity(ctx context.Context, itemID, warehouseID uint, batchID *uint) (float64, error) {
+	return unassignedQuantity(r.db.WithContext(ctx), itemID, warehouseID, batchID)
+}
+
+// GetPickCandidates 获取可拣货的库位库存：先到期先拣按批次有效期排序（无有效期排最后），
+// 先入库先拣按库位库存的入库时间排序，同一时间按库位顺序；隔离库位与已过期批次的库存不参与拣货
+func (r *LocationRepositoryImpl) GetPickCandidates(ctx context.Context, itemID, warehouseID uint, batchID *uint, strategy string) ([]LocationStockRow, error) {
+	query := r.locationStockQuery(ctx).
+		Where("ls.item_id = ? AND ls.warehouse_id = ?", itemID, warehouseID).
+		Where("ls.quantity > ?", quantityEpsilon).
+		Where("l.status = ?", models.LocationStatusActive).
+		Where("l.location_type IS NULL OR l.location_type <> ?", models.LocationTypeQuarantine).
+		Where("b.expiry_date IS NULL OR b.expiry_date >= ?", models.StartOfDay(time.Now()))
+	if batchID != nil {
+		query = query.Where("ls.batch_id = ?", *batchID)
+	}
+	if strategy == models.PickingStrategyFEFO {
+		query = query.Order("CASE WHEN b.expiry_date IS NULL THEN 1 ELSE 0 END, b.expiry_date")
+	}
+
+	var rows []LocationStockRow
+	err := query.Order("ls.first_received_at, l.sequence, l.code").Scan(&rows).Error
+	return rows, err
+}
+
+// GetQuarantineLocation 获取仓库用于存放过期批次的隔离库位，有多个时按库位顺序取第一个启用的库位
+func (r *LocationRepositoryImpl) GetQuarantineLocation(ctx context.Context, warehouseID uint) (*models.Location, error) {
+	var location models.Location
+	err := r.db.WithContext(ctx).
+		Where("warehouse_id = ? AND location_type = ? AND status = ?", warehouseID, models.LocationTypeQuarantine, models.LocationStatusActive).
+		Order("sequence, code").
+		First(&location).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &location, nil
+}
+
+// GetExpiredLocationStocks 获取仍存放在隔离库位以外的过期批次库存，warehouseID 为 0 时查询全部仓库
+func (r *LocationRepositoryImpl) GetExpiredLocationStocks(ctx context.Context, warehouseID uint, asOf time.Time) ([]LocationStockRow, error) {
+	query := r.locationStockQuery(ctx).
+		Where("ls.quantity > ?", quantityEpsilon).
+		Where("l.location_type IS NULL OR l.location_type <> ?", models.LocationTypeQuarantine).
+		Where("b.expiry_date IS NOT NULL AND b.expiry_date < ?", models.StartOfDay(asOf))
+	if warehouseID != 0 {
+		query = query.Where("ls.warehouse_id = ?", warehouseID)
+	}
+
+	var rows []LocationStockRow
+	err := query.Order("ls.warehouse_id, i.code, b.batch_no, l.sequence, l.code").Scan(&rows).Error
+	return rows, err
+}
+
+// CreatePutawayRule 创建上架规则
+func (r *LocationRepositoryImpl) CreatePutawayRule(ctx context.Context, rule *models.PutawayRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+// GetPutawayRule 根据ID获取上架规则
+func (r *LocationRepositoryImpl) GetPutawayRule(ctx context.Context, id uint) (*models.PutawayRule, error) {
+	var rule models.PutawayRule
+	if err := r.db.WithContext(ctx).Preload("Location").Preload("Item").First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// SavePutawayRule 保存上架规则
+func (r *LocationRepositoryImpl) SavePutawayRule(ctx context.Context, rule *models.PutawayRule) error {
+	return r.db.WithContext(ctx).Omit("Location", "Item").Save(rule).Error
+}
+
+// DeletePutawayRule 删除上架规则
+func (r *LocationRepositoryImpl) DeletePutawayRule(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.PutawayRule{}, id).Error
+}
+
+// ListPutawayRules 获取仓库的上架规则，warehouseID 为 0 时返回全部
+func (r *LocationRepositoryImpl) ListPutawayRules(ctx context.Context, warehouseID uint) ([]*models.PutawayRule, error) {
+	var rules []*models.PutawayRule
+	query := r.db.WithContext(ctx).Preload("Location").Preload("Item")
+	if warehouseID != 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	err := query.Order("warehouse_id, priority, id").Find(&rules).Error
+	return rules, err
+}
+
+// GetMatchingPutawayRules 获取适用于物料的启用规则：固定库位优先，其次物料类别，最后通用规则，同类按优先级排序
+func (r *LocationRepositoryImpl) GetMatchingPutawayRules(ctx context.Context, warehouseID, itemID uint, category string) ([]*models.PutawayRule, error) {
+	var rules []*models.PutawayRule
+	err := r.db.WithContext(ctx).
+		Preload("Location").
+		Where("warehouse_id = ? AND is_active = ?", warehouseID, true).
+		Where("item_id = ? OR (item_id IS NULL AND (category = ? OR category = '' OR category IS NULL))", itemID, category).
+		Order("CASE WHEN item_id IS NOT NULL THEN 0 WHEN category <> '' THEN 1 ELSE 2 END, priority, id").
+		Find(&rules).Error
+	return rules, err
+}
+
+// MoveStock 在一个事务中完成库位间移库：扣减调出库位（为空时校验未分配库存），
+// 增加调入库位（校验容量），并记录移库单
+func (r *LocationRepositoryImpl) MoveStock(ctx context.Context, move *models.LocationMove) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockStock(tx, move.ItemID, move.WarehouseID); err != nil {
+			return err
+		}
+		var batchID *uint
+		if move.BatchID != 0 {
+			batchID = &move.BatchID
+		}
+		now := time.Now()
+
+		if move.FromLocationID != nil {
+			from, err := loadWarehouseLocation(tx, *move.FromLocationID, move.WarehouseID)
+			if err != nil {
+				return err
+			}
+			if err := updateLocationStock(tx, move.ItemID, move.WarehouseID, from, move.BatchID, -move.Quantity, now); err != nil {
+				return err
+			}
+		} else {
+			available, err := unassignedQuantity(tx, move.ItemID, move.WarehouseID, batchID)
+			if err != nil {
+				return err
+			}
+			if available+quantityEpsilon < move.Quantity {
+				return fmt.Errorf("%w，未分配库位的库存: %.2f，需要: %.2f", ErrInsufficientStock, available, move.Quantity)
+			}
+		}
+
+		if move.ToLocationID != nil {
+			to, err := loadWarehouseLocation(tx, *move.ToLocationID, move.WarehouseID)
+			if err != nil {
+				return err
+			}
+			if err := checkLocationCapacity(tx, to, move.Quantity); err != nil {
+				return err
+			}
+			if err := updateLocationStock(tx, move.ItemID, move.WarehouseID, to, move.BatchID, move.Quantity, now); err != nil {
+				return err
+			}
+		}
+
+		return tx.Omit("Item", "FromLocation", "ToLocation").Create(move).Error
+	})
+}
+
+// ListLocationMoves 分页获取移库记录
+func (r *LocationRepositoryImpl) ListLocationMoves(ctx context.Context, filter LocationMoveFilter, offset, limit int) ([]*models.LocationMove, int64, error) {
+	var moves []*models.LocationMove
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.LocationMove{})
+	if filter.WarehouseID != 0 {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.ItemID != 0 {
+		query = query.Where("item_id = ?", filter.ItemID)
+	}
+	if filter.LocationID != 0 {
+		query = query.Where("from_location_id = ? OR to_location_id = ?", filter.LocationID, filter.LocationID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Item").
+		Preload("FromLocation").
+		Preload("ToLocation").
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&moves).Error
+	return moves, total, err
+}
+
+// GetDeliveryNoteItems 获取送货单明细
+func (r *LocationRepositoryImpl) GetDeliveryNoteItems(ctx context.Context, deliveryNoteID uint) ([]models.DeliveryNoteItem, error) {
+	var items []models.DeliveryNoteItem
+	err := r.db.WithContext(ctx).Where("delivery_note_id = ?", deliveryNoteID).Order("id").Find(&items).Error
+	return items, err
+}
+
+// locationStockQuery 构建库位库存明细查询
+func (r *LocationRepositoryImpl) locationStockQuery(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("location_stocks AS ls").
+		Select(`ls.item_id AS item_id,
+			i.code AS item_code,
+			i.name AS item_name,
+			ls.warehouse_id AS warehouse_id,
+			ls.location_id AS location_id,
+			l.code AS location_code,
+			l.name AS location_name,
+			ls.batch_id AS batch_id,
+			COALESCE(b.batch_no, '') AS batch_no,
+			b.expiry_date AS expiry_date,
+			ls.quantity AS quantity,
+			ls.first_received_at AS first_received_at`).
+		Joins("JOIN items AS i ON i.id = ls.item_id").
+		Joins("JOIN locations AS l ON l.id = ls.location_id").
+		Joins("LEFT JOIN batches AS b ON b.id = ls.batch_id").
+		Where("ls.deleted_at IS NULL").
+		Where("ABS(ls.quantity) > ?", quantityEpsilon)
+}
@@ -0,0 +1,285 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// PriceListFilter 价格表列表筛选条件
+type PriceListFilter struct {
+	PriceListType string
+	IsActive      *bool
+}
+
+// ItemPriceFilter 物料价格筛选条件，ID 为 0 表示不筛选
+type ItemPriceFilter struct {
+	PriceListID uint
+	ItemID      uint
+}
+
+// PriceListRepository 价格表仓储接口
+type PriceListRepository interface {
+	BaseRepository[models.PriceList]
+	GetPriceList(ctx context.Context, id uint) (*models.PriceList, error)
+	GetByCode(ctx context.Context, code string) (*models.PriceList, error)
+	ListPriceLists(ctx context.Context, filter PriceListFilter, offset, limit int) ([]*models.PriceList, int64, error)
+	SavePriceList(ctx context.Context, priceList *models.PriceList) error
+	GetDefaultPriceList(ctx context.Context, priceListType string) (*models.PriceList, error)
+	CountPriceListUsage(ctx context.Context, priceListID uint) (int64, error)
+	DeletePriceList(ctx context.Context, id uint) error
+	GetItemPrice(ctx context.Context, id uint) (*models.ItemPrice, error)
+	ListItemPrices(ctx context.Context, filter ItemPriceFilter, offset, limit int) ([]*models.ItemPrice, int64, error)
+	GetItemPrices(ctx context.Context, priceListID, itemID uint) ([]*models.ItemPrice, error)
+	CreateItemPrice(ctx context.Context, price *models.ItemPrice) error
+	SaveItemPrice(ctx context.Context, price *models.ItemPrice) error
+	DeleteItemPrice(ctx context.Context, id uint) error
+	GetAssignment(ctx context.Context, id uint) (*models.PriceListAssignment, error)
+	FindAssignment(ctx context.Context, partyType string, partyID uint, customerGroup, priceListType string) (*models.PriceListAssignment, error)
+	ListAssignments(ctx context.Context, priceListID uint) ([]*models.PriceListAssignment, error)
+	CreateAssignment(ctx context.Context, assignment *models.PriceListAssignment) error
+	DeleteAssignment(ctx context.Context, id uint) error
+	GetCustomer(ctx context.Context, id uint) (*models.Customer, error)
+	GetSupplier(ctx context.Context, id uint) (*models.Supplier, error)
+}
+
+// PriceListRepositoryImpl 价格表仓储实现
+type PriceListRepositoryImpl struct {
+	BaseRepository[models.PriceList]
+	db *gorm.DB
+}
+
+// NewPriceListRepository 创建价格表仓储实例
+func NewPriceListRepository(db *gorm.DB) PriceListRepository {
+	return &PriceListRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.PriceList](db),
+		db:             db,
+	}
+}
+
+// GetPriceList 根据ID获取价格表
+func (r *PriceListRepositoryImpl) GetPriceList(ctx context.Context, id uint) (*models.PriceList, error) {
+	var priceList models.PriceList
+	if err := r.db.WithContext(ctx).First(&priceList, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &priceList, nil
+}
+
+// GetByCode 根据编码获取价格表
+func (r *PriceListRepositoryImpl) GetByCode(ctx context.Context, code string) (*models.PriceList, error) {
+	var priceList models.PriceList
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&priceList).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &priceList, nil
+}
+
+// ListPriceLists 分页获取价格表
+func (r *PriceListRepositoryImpl) ListPriceLists(ctx context.Context, filter PriceListFilter, offset, limit int) ([]*models.PriceList, int64, error) {
+	var priceLists []*models.PriceList
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.PriceList{})
+	if filter.PriceListType != "" {
+		query = query.Where("price_list_type = ?", filter.PriceListType)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("price_list_type, code").Offset(offset).Limit(limit).Find(&priceLists).Error
+	return priceLists, total, err
+}
+
+// SavePriceList 创建或保存价格表；设为默认时取消同类型其他价格表的默认标记
+func (r *PriceListRepositoryImpl) SavePriceList(ctx context.Context, priceList *models.PriceList) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(priceList).Error; err != nil {
+			return err
+		}
+		if !priceList.IsDefault {
+			return nil
+		}
+		return tx.Model(&models.PriceList{}).
+			Where("price_list_type = ? AND id <> ? AND is_default = ?", priceList.PriceListType, priceList.ID, true).
+			Update("is_default", false).Error
+	})
+}
+
+// GetDefaultPriceList 获取指定类型的默认价格表
+func (r *PriceListRepositoryImpl) GetDefaultPriceList(ctx context.Context, priceListType string) (*models.PriceList, error) {
+	var priceList models.PriceList
+	err := r.db.WithContext(ctx).Where("price_list_type = ? AND is_default = ?", priceListType, true).First(&priceList).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &priceList, nil
+}
+
+// CountPriceListUsage 统计引用价格表的报价单、销售订单与采购订单数量
+func (r *PriceListRepositoryImpl) CountPriceListUsage(ctx context.Context, priceListID uint) (int64, error) {
+	var total int64
+	for _, model := range []interface{}{&models.Quotation{}, &models.SalesOrder{}, &models.PurchaseOrder{}} {
+		var count int64
+		if err := r.db.WithContext(ctx).Model(model).Where("price_list_id = ?", priceListID).Count(&count).Error; err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// DeletePriceList 删除价格表及其物料价格与分配
+func (r *PriceListRepositoryImpl) DeletePriceList(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("price_list_id = ?", id).Delete(&models.ItemPrice{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("price_list_id = ?", id).Delete(&models.PriceListAssignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.PriceList{}, id).Error
+	})
+}
+
+// GetItemPrice 根据ID获取物料价格
+func (r *PriceListRepositoryImpl) GetItemPrice(ctx context.Context, id uint) (*models.ItemPrice, error) {
+	var price models.ItemPrice
+	if err := r.db.WithContext(ctx).First(&price, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &price, nil
+}
+
+// ListItemPrices 分页获取物料价格
+func (r *PriceListRepositoryImpl) ListItemPrices(ctx context.Context, filter ItemPriceFilter, offset, limit int) ([]*models.ItemPrice, int64, error) {
+	var prices []*models.ItemPrice
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.ItemPrice{})
+	if filter.PriceListID != 0 {
+		query = query.Where("price_list_id = ?", filter.PriceListID)
+	}
+	if filter.ItemID != 0 {
+		query = query.Where("item_id = ?", filter.ItemID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Preload("Item").Order("price_list_id, item_id, uom, min_qty").Offset(offset).Limit(limit).Find(&prices).Error
+	return prices, total, err
+}
+
+// GetItemPrices 获取物料在价格表中的全部价格，用于取价
+func (r *PriceListRepositoryImpl) GetItemPrices(ctx context.Context, priceListID, itemID uint) ([]*models.ItemPrice, error) {
+	var prices []*models.ItemPrice
+	err := r.db.WithContext(ctx).Where("price_list_id = ? AND item_id = ?", priceListID, itemID).
+		Order("min_qty DESC, id DESC").Find(&prices).Error
+	return prices, err
+}
+
+// CreateItemPrice 创建物料价格
+func (r *PriceListRepositoryImpl) CreateItemPrice(ctx context.Context, price *models.ItemPrice) error {
+	return r.db.WithContext(ctx).Create(price).Error
+}
+
+// SaveItemPrice 保存物料价格
+func (r *PriceListRepositoryImpl) SaveItemPrice(ctx context.Context, price *models.ItemPrice) error {
+	return r.db.WithContext(ctx).Save(price).Error
+}
+
+// DeleteItemPrice 删除物料价格
+func (r *PriceListRepositoryImpl) DeleteItemPrice(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ItemPrice{}, id).Error
+}
+
+// GetAssignment 根据ID获取价格表分配
+func (r *PriceListRepositoryImpl) GetAssignment(ctx context.Context, id uint) (*models.PriceListAssignment, error) {
+	var assignment models.PriceListAssignment
+	if err := r.db.WithContext(ctx).First(&assignment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// FindAssignment 查找客户、客户组或供应商分配的指定类型价格表；客户组按 customerGroup 匹配，其余按 partyID 匹配
+func (r *PriceListRepositoryImpl) FindAssignment(ctx context.Context, partyType string, partyID uint, customerGroup, priceListType string) (*models.PriceListAssignment, error) {
+	query := r.db.WithContext(ctx).Preload("PriceList").
+		Joins("JOIN price_lists ON price_lists.id = price_list_assignments.price_list_id AND price_lists.deleted_at IS NULL").
+		Where("price_list_assignments.party_type = ? AND price_lists.price_list_type = ?", partyType, priceListType)
+	if partyType == models.PriceListPartyCustomerGroup {
+		query = query.Where("price_list_assignments.customer_group = ?", customerGroup)
+	} else {
+		query = query.Where("price_list_assignments.party_id = ?", partyID)
+	}
+
+	var assignment models.PriceListAssignment
+	if err := query.First(&assignment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// ListAssignments 获取价格表的全部分配
+func (r *PriceListRepositoryImpl) ListAssignments(ctx context.Context, priceListID uint) ([]*models.PriceListAssignment, error) {
+	var assignments []*models.PriceListAssignment
+	err := r.db.WithContext(ctx).Where("price_list_id = ?", priceListID).Order("party_type, id").Find(&assignments).Error
+	return assignments, err
+}
+
+// CreateAssignment 创建价格表分配
+func (r *PriceListRepositoryImpl) CreateAssignment(ctx context.Context, assignment *models.PriceListAssignment) error {
+	return r.db.WithContext(ctx).Create(assignment).Error
+}
+
+// DeleteAssignment 删除价格表分配
+func (r *PriceListRepositoryImpl) DeleteAssignment(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.PriceListAssignment{}, id).Error
+}
+
+// GetCustomer 根据ID获取客户，用于按客户与客户组取价
+func (r *PriceListRepositoryImpl) GetCustomer(ctx context.Context, id uint) (*models.Customer, error) {
+	var customer models.Customer
+	if err := r.db.WithContext(ctx).First(&customer, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetSupplier 根据ID获取供应商，用于按供应商取价
+func (r *PriceListRepositoryImpl) GetSupplier(ctx context.Context, id uint) (*models.Supplier, error) {
+	var supplier models.Supplier
+	if err := r.db.WithContext(ctx).First(&supplier, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &supplier, nil
+}
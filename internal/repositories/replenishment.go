@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// 参与补货计算的单据状态
+var (
+	openPurchaseOrderStatuses   = []string{"draft", "sent", "confirmed", "partial"}
+	openPurchaseRequestStatuses = []string{"draft", "submitted", "approved"}
+)
+
+// ReplenishmentFilter 补货计算筛选条件，ID 为 0 表示不筛选
+type ReplenishmentFilter struct {
+	ItemID     uint
+	SupplierID uint
+}
+
+// ReplenishmentItemRow 设置了再订货点的启用物料
+type ReplenishmentItemRow struct {
+	ItemID              uint
+	ItemCode            string
+	ItemName            string
+	Unit                string
+	Cost                models.Money
+	ReorderLevel        int
+	ReorderQty          float64
+	MaxLevel            float64
+	PreferredSupplierID *uint
+	SupplierName        string
+}
+
+// ReplenishmentWarehouseRow 参与补货的仓库
+type ReplenishmentWarehouseRow struct {
+	ID   uint
+	Code string
+	Name string
+}
+
+// ReplenishmentQuantityRow 物料在仓库的数量
+type ReplenishmentQuantityRow struct {
+	ItemID      uint
+	WarehouseID uint
+	Quantity    float64
+}
+
+// ReplenishmentRepository 补货仓储接口
+type ReplenishmentRepository interface {
+	GetItems(ctx context.Context, filter ReplenishmentFilter) ([]ReplenishmentItemRow, error)
+	GetWarehouses(ctx context.Context, warehouseID uint) ([]ReplenishmentWarehouseRow, error)
+	GetOnHand(ctx context.Context, itemIDs []uint, warehouseID uint) ([]ReplenishmentQuantityRow, error)
+	GetOnOrder(ctx context.Context, itemIDs []uint, warehouseID uint) ([]ReplenishmentQuantityRow, error)
+	GetRequested(ctx context.Context, itemIDs []uint, warehouseID uint) ([]ReplenishmentQuantityRow, error)
+	GetReserved(ctx context.Context, itemIDs []uint, warehouseID uint) ([]ReplenishmentQuantityRow, error)
+	CreatePurchaseRequests(ctx context.Context, requests []*models.PurchaseRequest) error
+}
+
+// ReplenishmentRepositoryImpl 补货仓储实现
+type ReplenishmentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewReplenishmentRepository 创建补货仓储实例
+func NewReplenishmentRepository(db *gorm.DB) ReplenishmentRepository {
+	return &ReplenishmentRepositoryImpl{db: db}
+}
+
+// GetItems 获取设置了再订货点的启用物料及其首选供应商
+func (r *ReplenishmentRepositoryImpl) GetItems(ctx context.Context, filter ReplenishmentFilter) ([]ReplenishmentItemRow, error) {
+	query := r.db.WithContext(ctx).
+		Table("items AS i").
+		Joins("LEFT JOIN suppliers AS sp ON sp.id = i.preferred_supplier_id AND sp.deleted_at IS NULL").
+		Where("i.deleted_at IS NULL AND i.is_active = ? AND i.reorder_level > 0", true)
+	if filter.ItemID != 0 {
+		query = query.Where("i.id = ?", filter.ItemID)
+	}
+	if filter.SupplierID != 0 {
+		query = query.Where("i.preferred_supplier_id = ?", filter.SupplierID)
+	}
+
+	var rows []ReplenishmentItemRow
+	err := query.Select(`i.id AS item_id,
+			i.code AS item_code,
+			i.name AS item_name,
+			i.unit AS unit,
+			i.cost AS cost,
+			i.reorder_level AS reorder_level,
+			i.reorder_qty AS reorder_qty,
+			i.max_level AS max_level,
+			i.preferred_supplier_id AS preferred_supplier_id,
+			COALESCE(sp.name, '') AS supplier_name`).
+		Order("i.code").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetWarehouses 获取启用的非在途仓库
+func (r *ReplenishmentRepositoryImpl) GetWarehouses(ctx context.Context, warehouseID uint) ([]ReplenishmentWarehouseRow, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.Warehouse{}).
+		Where("is_active = ? AND is_transit = ?", true, false)
+	if warehouseID != 0 {
+		query = query.Where("id = ?", warehouseID)
+	}
+
+	var rows []ReplenishmentWarehouseRow
+	err := query.Select("id, code, name").Order("code").Scan(&rows).Error
+	return rows, err
+}
+
+// GetOnHand 获取物料在各仓库的现有库存
+func (r *ReplenishmentRepositoryImpl) GetOnHand(ctx context.Context, itemIDs []uint, warehouseID uint) ([]ReplenishmentQuantityRow, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.Stock{}).
+		Where("item_id IN ?", itemIDs)
+	if warehouseID != 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+
+	var rows []ReplenishmentQuantityRow
+	err := query.Select("item_id, warehouse_id, SUM(quantity) AS quantity").
+		Group("item_id, warehouse_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetOnOrder 获取未完成采购订单中尚未收货的数量
+func (r *ReplenishmentRepositoryImpl) GetOnOrder(ctx context.Context, itemIDs []uint, warehouseID uint) ([]ReplenishmentQuantityRow, error) {
+	query := r.db.WithContext(ctx).
+		Table("purchase_order_items AS poi").
+		Joins("JOIN purchase_orders AS po ON po.id = poi.purchase_order_id AND po.deleted_at IS NULL").
+		Where("poi.deleted_at IS NULL AND poi.warehouse_id IS NOT NULL").
+		Where("po.status IN ?", openPurchaseOrderStatuses).
+		Where("poi.item_id IN ?", itemIDs).
+		Where("poi.quantity > poi.received_qty")
+	if warehouseID != 0 {
+		query = query.Where("poi.warehouse_id = ?", warehouseID)
+	}
+
+	var rows []ReplenishmentQuantityRow
+	err := query.Select("poi.item_id AS item_id, poi.warehouse_id AS warehouse_id, SUM(poi.quantity - poi.received_qty) AS quantity").
+		Group("poi.item_id, poi.warehouse_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetRequested 获取尚未转为采购订单的有效采购申请数量，避免重复补货
+func (r *ReplenishmentRepositoryImpl) GetRequested(ctx context.Context, itemIDs []uint, warehouseID uint) ([]ReplenishmentQuantityRow, error) {
+	query := r.db.WithContext(ctx).
+		Table("purchase_request_items AS pri").
+		Joins("JOIN purchase_requests AS pr ON pr.id = pri.purchase_request_id AND pr.deleted_at IS NULL").
+		Where("pri.deleted_at IS NULL AND pri.warehouse_id IS NOT NULL").
+		Where("pr.status IN ?", openPurchaseRequestStatuses).
+		Where("pri.item_id IN ?", itemIDs).
+		Where("NOT EXISTS (SELECT 1 FROM purchase_orders AS po WHERE po.purchase_request_id = pr.id AND po.deleted_at IS NULL)")
+	if warehouseID != 0 {
+		query = query.Where("pri.warehouse_id = ?", warehouseID)
+	}
+
+	var rows []ReplenishmentQuantityRow
+	err := query.Select("pri.item_id AS item_id, pri.warehouse_id AS warehouse_id, SUM(pri.quantity * COALESCE(pri.conversion_factor, 1)) AS quantity").
+		Group("pri.item_id, pri.warehouse_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetReserved 获取有效库存预留中尚未发货的需求数量（含库存不足未分配的部分）
+func (r *ReplenishmentRepositoryImpl) GetReserved(ctx context.Context, itemIDs []uint, warehouseID uint) ([]ReplenishmentQuantityRow, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.StockReservation{}).
+		Where("status = ?", models.ReservationStatusActive).
+		Where("item_id IN ?", itemIDs)
+	if warehouseID != 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+
+	var rows []ReplenishmentQuantityRow
+	err := query.Select("item_id, warehouse_id, SUM(quantity) AS quantity").
+		Group("item_id, warehouse_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CreatePurchaseRequests 在同一事务中创建补货生成的采购申请及明细
+func (r *ReplenishmentRepositoryImpl) CreatePurchaseRequests(ctx context.Context, requests []*models.PurchaseRequest) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, request := range requests {
+			if err := tx.Create(request).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
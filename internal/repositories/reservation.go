@@ -0,0 +1,332 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// ReservationFilter 库存预留筛选条件，ID 为 0、字符串为空表示不筛选
+type ReservationFilter struct {
+	ItemID       uint
+	WarehouseID  uint
+	SalesOrderID uint
+	Status       string
+	ShortageOnly bool
+}
+
+// ReservationDelivery 送货单行对销售订单行的发货数量
+type ReservationDelivery struct {
+	SalesOrderItemID uint
+	Quantity         float64
+}
+
+// ReservationRepository 库存预留仓储接口
+type ReservationRepository interface {
+	ReserveSalesOrder(ctx context.Context, salesOrderID uint, status string) ([]*models.StockReservation, error)
+	ConsumeForDelivery(ctx context.Context, deliveries []ReservationDelivery) error
+	Reallocate(ctx context.Context, itemID, warehouseID uint) (int, error)
+	GetReservedQuantities(ctx context.Context, itemID uint) (map[uint]float64, error)
+	GetSalesOrderReservations(ctx context.Context, salesOrderID uint) ([]*models.StockReservation, error)
+	ListReservations(ctx context.Context, filter ReservationFilter, offset, limit int) ([]*models.StockReservation, int64, error)
+}
+
+// ReservationRepositoryImpl 库存预留仓储实现
+type ReservationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewReservationRepository 创建库存预留仓储实例
+func NewReservationRepository(db *gorm.DB) ReservationRepository {
+	return &ReservationRepositoryImpl{db: db}
+}
+
+// reservationPair 物料与仓库
+type reservationPair struct {
+	itemID      uint
+	warehouseID uint
+}
+
+// ReserveSalesOrder 在同一事务中更新订单状态并为指定了仓库的未发货订单行建立预留，随后按优先级重新分配库存。
+// 未指定仓库的订单行无法预留，不返回对应记录
+func (r *ReservationRepositoryImpl) ReserveSalesOrder(ctx context.Context, salesOrderID uint, status string) ([]*models.StockReservation, error) {
+	var reservations []*models.StockReservation
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SalesOrder{}).Where("id = ?", salesOrderID).Update("status", status).Error; err != nil {
+			return err
+		}
+
+		var err error
+		reservations, err = reserveSalesOrder(tx, salesOrderID)
+		return err
+	})
+	return reservations, err
+}
+
+// reserveSalesOrder 为指定了仓库的未发货订单行建立或重建预留，随后按优先级重新分配库存
+func reserveSalesOrder(tx *gorm.DB, salesOrderID uint) ([]*models.StockReservation, error) {
+	var lines []models.SalesOrderItem
+	if err := tx.Where("sales_order_id = ?", salesOrderID).Order("id").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[reservationPair]bool)
+	for _, line := range lines {
+		openQty := line.Quantity - line.DeliveredQty
+		if line.WarehouseID == nil || openQty <= quantityEpsilon {
+			continue
+		}
+
+		var reservation models.StockReservation
+		err := tx.Unscoped().Where("sales_order_item_id = ?", line.ID).First(&reservation).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if reservation.ID != 0 {
+			pairs[reservationPair{reservation.ItemID, reservation.WarehouseID}] = true
+		}
+
+		reservation.DeletedAt = gorm.DeletedAt{}
+		reservation.SalesOrderID = salesOrderID
+		reservation.SalesOrderItemID = line.ID
+		reservation.ItemID = line.ItemID
+		reservation.WarehouseID = *line.WarehouseID
+		reservation.Quantity = openQty
+		reservation.DeliveredQty = line.DeliveredQty
+		reservation.ReservedQty = 0
+		reservation.Status = models.ReservationStatusActive
+		reservation.ReleasedAt = nil
+		if err := tx.Unscoped().Save(&reservation).Error; err != nil {
+			return nil, err
+		}
+		pairs[reservationPair{line.ItemID, *line.WarehouseID}] = true
+	}
+
+	if err := reallocatePairs(tx, pairs); err != nil {
+		return nil, err
+	}
+	var reservations []*models.StockReservation
+	if err := tx.Where("sales_order_id = ?", salesOrderID).Order("id").Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// releaseSalesOrder 释放订单的有效预留，释放的库存按优先级分配给其他订单
+func releaseSalesOrder(tx *gorm.DB, salesOrderID uint) error {
+	var reservations []models.StockReservation
+	if err := tx.Where("sales_order_id = ? AND status = ?", salesOrderID, models.ReservationStatusActive).
+		Find(&reservations).Error; err != nil {
+		return err
+	}
+	if len(reservations) == 0 {
+		return nil
+	}
+
+	pairs := make(map[reservationPair]bool)
+	ids := make([]uint, 0, len(reservations))
+	for _, reservation := range reservations {
+		ids = append(ids, reservation.ID)
+		pairs[reservationPair{reservation.ItemID, reservation.WarehouseID}] = true
+	}
+	if err := tx.Model(&models.StockReservation{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"status":       models.ReservationStatusReleased,
+		"reserved_qty": 0,
+		"released_at":  time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+	return reallocatePairs(tx, pairs)
+}
+
+// ConsumeForDelivery 按送货单行的发货数量核销对应订单行的预留，全部发货后预留完成
+func (r *ReservationRepositoryImpl) ConsumeForDelivery(ctx context.Context, deliveries []ReservationDelivery) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return consumeReservations(tx, deliveries)
+	})
+}
+
+// Reallocate 按优先级重新分配库存，itemID、warehouseID 为 0 时处理全部有有效预留的物料与仓库，返回处理的组合数
+func (r *ReservationRepositoryImpl) Reallocate(ctx context.Context, itemID, warehouseID uint) (int, error) {
+	var rows []struct {
+		ItemID      uint
+		WarehouseID uint
+	}
+	query := r.db.WithContext(ctx).Model(&models.StockReservation{}).
+		Where("status = ?", models.ReservationStatusActive)
+	if itemID != 0 {
+		query = query.Where("item_id = ?", itemID)
+	}
+	if warehouseID != 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if err := query.Distinct("item_id", "warehouse_id").Scan(&rows).Error; err != nil {
+		return 0, err
+	}
+
+	// 每个组合单独提交，避免长事务锁定大量库存行
+	for _, row := range rows {
+		pairs := map[reservationPair]bool{{row.ItemID, row.WarehouseID}: true}
+		if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return reallocatePairs(tx, pairs)
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(rows), nil
+}
+
+// GetReservedQuantities 获取物料在各仓库已分配的预留数量
+func (r *ReservationRepositoryImpl) GetReservedQuantities(ctx context.Context, itemID uint) (map[uint]float64, error) {
+	var rows []struct {
+		WarehouseID uint
+		Quantity    float64
+	}
+	if err := r.db.WithContext(ctx).Model(&models.StockReservation{}).
+		Select("warehouse_id, SUM(reserved_qty) AS quantity").
+		Where("item_id = ? AND status = ?", itemID, models.ReservationStatusActive).
+		Group("warehouse_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	reserved := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		reserved[row.WarehouseID] = row.Quantity
+	}
+	return reserved, nil
+}
+
+// GetSalesOrderReservations 获取销售订单的全部预留
+func (r *ReservationRepositoryImpl) GetSalesOrderReservations(ctx context.Context, salesOrderID uint) ([]*models.StockReservation, error) {
+	var reservations []*models.StockReservation
+	err := r.db.WithContext(ctx).
+		Preload("SalesOrder").Preload("Item").Preload("Warehouse").
+		Where("sales_order_id = ?", salesOrderID).
+		Order("id").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+// ListReservations 分页获取库存预留
+func (r *ReservationRepositoryImpl) ListReservations(ctx context.Context, filter ReservationFilter, offset, limit int) ([]*models.StockReservation, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.StockReservation{})
+	if filter.ItemID != 0 {
+		query = query.Where("item_id = ?", filter.ItemID)
+	}
+	if filter.WarehouseID != 0 {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.SalesOrderID != 0 {
+		query = query.Where("sales_order_id = ?", filter.SalesOrderID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ShortageOnly {
+		query = query.Where("status = ? AND reserved_qty < quantity", models.ReservationStatusActive)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reservations []*models.StockReservation
+	err := query.Preload("SalesOrder").Preload("Item").Preload("Warehouse").
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&reservations).Error
+	return reservations, total, err
+}
+
+// consumeReservations 核销订单行的预留数量，并重新分配受影响的物料与仓库
+func consumeReservations(tx *gorm.DB, deliveries []ReservationDelivery) error {
+	pairs := make(map[reservationPair]bool)
+	for _, delivery := range deliveries {
+		var reservation models.StockReservation
+		err := tx.Where("sales_order_item_id = ? AND status = ?", delivery.SalesOrderItemID, models.ReservationStatusActive).
+			First(&reservation).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		quantity := math.Max(reservation.Quantity-delivery.Quantity, 0)
+		updates := map[string]interface{}{
+			"quantity":      quantity,
+			"delivered_qty": reservation.DeliveredQty + delivery.Quantity,
+			"reserved_qty":  math.Min(reservation.ReservedQty, quantity),
+		}
+		if quantity <= quantityEpsilon {
+			updates["quantity"] = 0
+			updates["reserved_qty"] = 0
+			updates["status"] = models.ReservationStatusFulfilled
+		}
+		if err := tx.Model(&reservation).Updates(updates).Error; err != nil {
+			return err
+		}
+		pairs[reservationPair{reservation.ItemID, reservation.WarehouseID}] = true
+	}
+	return reallocatePairs(tx, pairs)
+}
+
+// reallocatePairs 锁定库存行后按优先级重新分配各物料、仓库的预留；按物料、仓库顺序加锁，
+// 避免并发事务以不同顺序锁定同一组库存行而死锁
+func reallocatePairs(tx *gorm.DB, pairs map[reservationPair]bool) error {
+	ordered := make([]reservationPair, 0, len(pairs))
+	for pair := range pairs {
+		ordered = append(ordered, pair)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].itemID != ordered[j].itemID {
+			return ordered[i].itemID < ordered[j].itemID
+		}
+		return ordered[i].warehouseID < ordered[j].warehouseID
+	})
+	for _, pair := range ordered {
+		stock, err := lockStock(tx, pair.itemID, pair.warehouseID)
+		if err != nil {
+			return err
+		}
+		if err := allocateReservations(tx, pair.itemID, pair.warehouseID, stock.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allocateReservations 将现有库存按优先级分配给有效预留：订单优先级高者优先，其次交货日期早者，
+// 再次先确认者；库存不足时排在后面的预留只分配部分或不分配。调用方需已锁定库存行
+func allocateReservations(tx *gorm.DB, itemID, warehouseID uint, onHand float64) error {
+	var reservations []models.StockReservation
+	if err := tx.Model(&models.StockReservation{}).
+		Joins("JOIN sales_orders ON sales_orders.id = stock_reservations.sales_order_id").
+		Where("stock_reservations.item_id = ? AND stock_reservations.warehouse_id = ? AND stock_reservations.status = ?",
+			itemID, warehouseID, models.ReservationStatusActive).
+		Order("sales_orders.priority DESC, sales_orders.delivery_date, stock_reservations.id").
+		Find(&reservations).Error; err != nil {
+		return err
+	}
+
+	remaining := math.Max(onHand, 0)
+	for _, reservation := range reservations {
+		allocated := math.Min(reservation.Quantity, remaining)
+		remaining -= allocated
+		if math.Abs(allocated-reservation.ReservedQty) <= quantityEpsilon {
+			continue
+		}
+		if err := tx.Model(&models.StockReservation{}).Where("id = ?", reservation.ID).
+			Update("reserved_qty", allocated).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
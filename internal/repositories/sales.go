@@ -2,11 +2,15 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/galaxyerp/galaxyErp/internal/common"
 	"github.com/galaxyerp/galaxyErp/internal/dto"
 	"github.com/galaxyerp/galaxyErp/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"time"
 )
 
 // CustomerRepository 客户仓储接口
@@ -147,10 +151,10 @@ func (r *CustomerRepositoryImpl) applyFilter(query *gorm.DB, filter common.Filte
 	}
 }
 
-// GetNextInvoiceNumber 获取下一个发票编号
+// GetNextInvoiceNumber 获取下一个发票编号，已删除的草稿发票仍占用编号
 func (r *SalesInvoiceRepositoryImpl) GetNextInvoiceNumber(ctx context.Context) (string, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.SalesInvoice{}).Count(&count).Error
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.SalesInvoice{}).Count(&count).Error
 	if err != nil {
 		return "", err
 	}
@@ -203,8 +207,26 @@ type SalesOrderRepository interface {
 	GetStatistics(ctx context.Context, startDate, endDate string) (*dto.SalesStatisticsResponse, error)
 	GetSalesTrend(ctx context.Context, period string) ([]*dto.MonthlySales, error)
 	Search(ctx context.Context, keyword string, options *common.QueryOptions) ([]*models.SalesOrder, error)
+	GetWithItems(ctx context.Context, id uint) (*models.SalesOrder, error)
+	ChangeStatus(ctx context.Context, change *SalesOrderStatusChange) ([]*models.StockReservation, error)
+	GetStatusLogs(ctx context.Context, salesOrderID uint) ([]*models.SalesOrderStatusLog, error)
+	CountSubmittedDocuments(ctx context.Context, salesOrderID uint) (deliveries, invoices int64, err error)
+	GetCreditExposure(ctx context.Context, customerID, excludeOrderID uint, openStatuses []string) (models.Money, error)
 }
 
+// SalesOrderStatusChange 销售订单状态变更及其在同一事务中执行的副作用
+type SalesOrderStatusChange struct {
+	Log                   *models.SalesOrderStatusLog
+	CurrentStatus         string // 读取订单时的状态，仅在订单仍为该状态时变更
+	ReserveStock          bool   // 为指定了仓库的未发货订单行建立预留
+	ReleaseReservations   bool   // 释放订单的有效预留
+	CancelDraftDeliveries bool
+	CancelDraftInvoices   bool
+}
+
+// ErrSalesOrderStatusChanged 变更状态时订单状态已被并发修改
+var ErrSalesOrderStatusChanged = errors.New("销售订单状态已变更，请刷新后重试")
+
 // SalesOrderRepositoryImpl 销售订单仓储实现
 type SalesOrderRepositoryImpl struct {
 	BaseRepository[models.SalesOrder]
@@ -229,6 +251,156 @@ func (r *SalesOrderRepositoryImpl) GetByOrderNumber(ctx context.Context, orderNu
 	return &order, nil
 }
 
+// GetWithItems 获取销售订单及其客户、创建人与明细，不存在时返回 nil
+func (r *SalesOrderRepositoryImpl) GetWithItems(ctx context.Context, id uint) (*models.SalesOrder, error) {
+	var order models.SalesOrder
+	err := r.db.WithContext(ctx).
+		Preload("Customer").
+		Preload("CreatedByUser").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Item").
+		First(&order, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ChangeStatus 在同一事务中按读取时的状态条件更新订单状态、建立或释放库存预留、取消下游草稿单据并写入状态变更日志，
+// 取消订单时同时重新汇总来源报价单的已下单数量与状态。订单状态已被并发修改时返回 ErrSalesOrderStatusChanged，
+// 返回建立预留后订单的预留记录
+func (r *SalesOrderRepositoryImpl) ChangeStatus(ctx context.Context, change *SalesOrderStatusChange) ([]*models.StockReservation, error) {
+	log := change.Log
+	var reservations []*models.StockReservation
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.SalesOrder{}).
+			Where("id = ? AND status = ?", log.SalesOrderID, change.CurrentStatus).
+			Update("status", log.ToStatus)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSalesOrderStatusChanged
+		}
+
+		switch {
+		case change.ReserveStock:
+			var err error
+			if reservations, err = reserveSalesOrder(tx, log.SalesOrderID); err != nil {
+				return err
+			}
+		case change.ReleaseReservations:
+			if err := releaseSalesOrder(tx, log.SalesOrderID); err != nil {
+				return err
+			}
+		}
+
+		if log.ToStatus == models.SalesOrderStatusCancelled {
+			if err := releaseQuotationOrders(tx, log.SalesOrderID); err != nil {
+				return err
+			}
+		}
+
+		if change.CancelDraftDeliveries {
+			if err := tx.Model(&models.DeliveryNote{}).
+				Where("sales_order_id = ? AND LOWER(status) = ?", log.SalesOrderID, strings.ToLower(models.DeliveryNoteStatusDraft)).
+				Update("status", models.DeliveryNoteStatusCancelled).Error; err != nil {
+				return err
+			}
+		}
+
+		if change.CancelDraftInvoices {
+			var invoices []models.SalesInvoice
+			if err := tx.Where("sales_order_id = ? AND doc_status = ?", log.SalesOrderID, models.SalesInvoiceDocStatusDraft).Find(&invoices).Error; err != nil {
+				return err
+			}
+			for _, invoice := range invoices {
+				if err := tx.Model(&models.SalesInvoice{}).Where("id = ?", invoice.ID).Update("doc_status", models.SalesInvoiceDocStatusCancelled).Error; err != nil {
+					return err
+				}
+				invoiceLog := &models.InvoiceStatusLog{
+					SalesInvoiceID: invoice.ID,
+					FromStatus:     invoice.DocStatus,
+					ToStatus:       models.SalesInvoiceDocStatusCancelled,
+					StatusType:     "doc_status",
+					ChangedBy:      log.ChangedBy,
+					ChangedAt:      log.ChangedAt,
+					Reason:         fmt.Sprintf("销售订单 %d 已取消", log.SalesOrderID),
+				}
+				if err := tx.Create(invoiceLog).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Create(log).Error
+	})
+	return reservations, err
+}
+
+// GetStatusLogs 按时间顺序获取销售订单的状态变更日志
+func (r *SalesOrderRepositoryImpl) GetStatusLogs(ctx context.Context, salesOrderID uint) ([]*models.SalesOrderStatusLog, error) {
+	var logs []*models.SalesOrderStatusLog
+	err := r.db.WithContext(ctx).
+		Where("sales_order_id = ?", salesOrderID).
+		Order("changed_at, id").
+		Find(&logs).Error
+	return logs, err
+}
+
+// CountSubmittedDocuments 统计订单已提交或已送达的送货单数量与已提交的销售发票数量
+func (r *SalesOrderRepositoryImpl) CountSubmittedDocuments(ctx context.Context, salesOrderID uint) (deliveries, invoices int64, err error) {
+	err = r.db.WithContext(ctx).Model(&models.DeliveryNote{}).
+		Where("sales_order_id = ? AND LOWER(status) IN ?", salesOrderID, []string{"submitted", "delivered"}).
+		Count(&deliveries).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	err = r.db.WithContext(ctx).Model(&models.SalesInvoice{}).
+		Where("sales_order_id = ? AND doc_status = ?", salesOrderID, "Submitted").
+		Count(&invoices).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	return deliveries, invoices, nil
+}
+
+// GetCreditExposure 计算客户的信用占用：已提交发票的未收金额加上其他未结订单尚未开票的金额
+func (r *SalesOrderRepositoryImpl) GetCreditExposure(ctx context.Context, customerID, excludeOrderID uint, openStatuses []string) (models.Money, error) {
+	var outstanding, ordered, billed models.Money
+	err := r.db.WithContext(ctx).Model(&models.SalesInvoice{}).
+		Select("COALESCE(SUM(outstanding_amount), 0)").
+		Where("customer_id = ? AND doc_status = ?", customerID, "Submitted").
+		Scan(&outstanding).Error
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.db.WithContext(ctx).Model(&models.SalesOrder{}).
+		Select("COALESCE(SUM(grand_total), 0)").
+		Where("customer_id = ? AND id <> ? AND LOWER(status) IN ?", customerID, excludeOrderID, openStatuses).
+		Scan(&ordered).Error
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.db.WithContext(ctx).
+		Table("sales_invoices").
+		Select("COALESCE(SUM(sales_invoices.grand_total), 0)").
+		Joins("JOIN sales_orders ON sales_orders.id = sales_invoices.sales_order_id AND sales_orders.deleted_at IS NULL").
+		Where("sales_orders.customer_id = ? AND sales_orders.id <> ? AND LOWER(sales_orders.status) IN ?", customerID, excludeOrderID, openStatuses).
+		Where("sales_invoices.doc_status = ? AND sales_invoices.deleted_at IS NULL", "Submitted").
+		Scan(&billed).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return outstanding.Add(ordered.Sub(billed).Max(0)), nil
+}
+
 // GetByCustomerID 根据客户ID获取销售订单
 func (r *SalesOrderRepositoryImpl) GetByCustomerID(ctx context.Context, customerID uint) ([]*models.SalesOrder, error) {
 	var orders []*models.SalesOrder
@@ -381,6 +553,10 @@ type QuotationRepository interface {
 	GetByStatus(ctx context.Context, status string) ([]*models.Quotation, error)
 	UpdateStatus(ctx context.Context, id uint, status string) error
 	Search(ctx context.Context, keyword string, options *common.QueryOptions) ([]*models.Quotation, error)
+	GetWithItems(ctx context.Context, id uint) (*models.Quotation, error)
+	GetActiveVersion(ctx context.Context, quotationID uint) (*models.QuotationVersion, error)
+	GetOrderedQuantities(ctx context.Context, quotationID uint) (map[uint]float64, error)
+	CreateSalesOrderFromQuotation(ctx context.Context, order *models.SalesOrder, quoted map[uint]float64) (string, error)
 }
 
 // QuotationRepositoryImpl 报价单仓储实现
@@ -426,6 +602,181 @@ func (r *QuotationRepositoryImpl) UpdateStatus(ctx context.Context, id uint, sta
 	return r.db.WithContext(ctx).Model(&models.Quotation{}).Where("id = ?", id).Update("status", status).Error
 }
 
+// GetWithItems 获取报价单及其客户与明细，不存在时返回 nil
+func (r *QuotationRepositoryImpl) GetWithItems(ctx context.Context, id uint) (*models.Quotation, error) {
+	var quotation models.Quotation
+	err := r.db.WithContext(ctx).
+		Preload("Customer").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Item").
+		First(&quotation, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &quotation, nil
+}
+
+// GetActiveVersion 获取报价单的活跃版本，没有活跃版本时返回 nil
+func (r *QuotationRepositoryImpl) GetActiveVersion(ctx context.Context, quotationID uint) (*models.QuotationVersion, error) {
+	var version models.QuotationVersion
+	err := r.db.WithContext(ctx).
+		Where("quotation_id = ? AND is_active = ?", quotationID, true).
+		Order("version_number DESC").
+		First(&version).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &version, nil
+}
+
+// GetOrderedQuantities 按报价明细汇总已转为销售订单的数量，已取消的订单不计
+func (r *QuotationRepositoryImpl) GetOrderedQuantities(ctx context.Context, quotationID uint) (map[uint]float64, error) {
+	return quotationOrderedQuantities(r.db.WithContext(ctx), quotationID)
+}
+
+// CreateSalesOrderFromQuotation 在同一事务中创建由报价单转入的销售订单，回写报价明细的已下单数量并更新报价单状态。
+// 报价单行加锁后重新汇总已下单数量，并发转换合计超过报价数量时返回 ErrQuotationOverOrdered。
+// quoted 为报价明细的报价数量，返回更新后的报价单状态
+func (r *QuotationRepositoryImpl) CreateSalesOrderFromQuotation(ctx context.Context, order *models.SalesOrder, quoted map[uint]float64) (string, error) {
+	var status string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var quotation models.Quotation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&quotation, *order.QuotationID).Error; err != nil {
+			return err
+		}
+		if strings.ToLower(quotation.Status) == models.QuotationStatusCancelled {
+			return fmt.Errorf("报价单 %s 已取消，不能转为销售订单", quotation.QuotationNumber)
+		}
+
+		ordered, err := quotationOrderedQuantities(tx, quotation.ID)
+		if err != nil {
+			return err
+		}
+		for _, item := range order.Items {
+			if item.QuotationItemID == nil {
+				continue
+			}
+			quotationItemID := *item.QuotationItemID
+			if ordered[quotationItemID]+item.Quantity > quoted[quotationItemID]+quantityEpsilon {
+				return fmt.Errorf("%w：报价明细 %d 报价数量 %.2f，已下单 %.2f，本次下单 %.2f",
+					ErrQuotationOverOrdered, quotationItemID, quoted[quotationItemID], ordered[quotationItemID], item.Quantity)
+			}
+		}
+
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		status, err = syncQuotationOrders(tx, &quotation, quoted)
+		return err
+	})
+	return status, err
+}
+
+// ErrQuotationOverOrdered 累计转入销售订单的数量超过报价数量
+var ErrQuotationOverOrdered = errors.New("下单数量超过报价单未下单数量")
+
+// quotationOrderedQuantities 按报价明细汇总未取消销售订单的数量
+func quotationOrderedQuantities(db *gorm.DB, quotationID uint) (map[uint]float64, error) {
+	var rows []struct {
+		QuotationItemID uint
+		Quantity        float64
+	}
+	err := db.Table("sales_order_items").
+		Select("sales_order_items.quotation_item_id, SUM(sales_order_items.quantity) AS quantity").
+		Joins("JOIN sales_orders ON sales_orders.id = sales_order_items.sales_order_id AND sales_orders.deleted_at IS NULL").
+		Where("sales_orders.quotation_id = ? AND LOWER(sales_orders.status) <> ?", quotationID, models.SalesOrderStatusCancelled).
+		Where("sales_order_items.quotation_item_id IS NOT NULL AND sales_order_items.deleted_at IS NULL").
+		Group("sales_order_items.quotation_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		ordered[row.QuotationItemID] = row.Quantity
+	}
+	return ordered, nil
+}
+
+// syncQuotationOrders 按未取消的销售订单重新回写报价明细的已下单数量并推导报价单状态：
+// 全部明细下单为已下单，部分下单为部分下单；首次下单时记录转换前的状态，订单全部取消后恢复为该状态以便重新转换
+func syncQuotationOrders(tx *gorm.DB, quotation *models.Quotation, quoted map[uint]float64) (string, error) {
+	ordered, err := quotationOrderedQuantities(tx, quotation.ID)
+	if err != nil {
+		return "", err
+	}
+
+	status := models.QuotationStatusOrdered
+	anyOrdered := false
+	for quotationItemID, quantity := range quoted {
+		if err := tx.Model(&models.QuotationItem{}).Where("id = ?", quotationItemID).
+			Update("ordered_qty", ordered[quotationItemID]).Error; err != nil {
+			return "", err
+		}
+		if ordered[quotationItemID] > quantityEpsilon {
+			anyOrdered = true
+		}
+		if ordered[quotationItemID] < quantity-quantityEpsilon {
+			status = models.QuotationStatusPartiallyOrdered
+		}
+	}
+
+	current := strings.ToLower(quotation.Status)
+	converted := current == models.QuotationStatusOrdered || current == models.QuotationStatusPartiallyOrdered
+	updates := map[string]interface{}{"status": status}
+	switch {
+	case anyOrdered && !converted:
+		updates["status_before_order"] = quotation.Status
+	case !anyOrdered:
+		status = quotation.StatusBeforeOrder
+		if status == "" {
+			status = models.QuotationStatusDraft
+		}
+		updates["status"] = status
+		updates["status_before_order"] = ""
+	}
+	if err := tx.Model(&models.Quotation{}).Where("id = ?", quotation.ID).Updates(updates).Error; err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// releaseQuotationOrders 销售订单取消后重新汇总来源报价单的已下单数量与状态，已取消的报价单不处理
+func releaseQuotationOrders(tx *gorm.DB, salesOrderID uint) error {
+	var order models.SalesOrder
+	if err := tx.Select("id", "quotation_id").First(&order, salesOrderID).Error; err != nil {
+		return err
+	}
+	if order.QuotationID == nil {
+		return nil
+	}
+
+	var quotation models.Quotation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&quotation, *order.QuotationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if strings.ToLower(quotation.Status) == models.QuotationStatusCancelled {
+		return nil
+	}
+
+	quoted := make(map[uint]float64, len(quotation.Items))
+	for _, item := range quotation.Items {
+		quoted[item.ID] = item.Quantity
+	}
+	_, err := syncQuotationOrders(tx, &quotation, quoted)
+	return err
+}
+
 // Search 搜索报价单
 func (r *QuotationRepositoryImpl) Search(ctx context.Context, keyword string, options *common.QueryOptions) ([]*models.Quotation, error) {
 	var quotations []*models.Quotation
@@ -519,8 +870,17 @@ type SalesInvoiceRepository interface {
 	GetNextInvoiceNumber(ctx context.Context) (string, error)
 	AddPaymentWithTransaction(ctx context.Context, invoiceID uint, payment *models.InvoicePayment) error
 	GetPayments(ctx context.Context, invoiceID uint) ([]*models.InvoicePayment, error)
+	GetWithItems(ctx context.Context, id uint) (*models.SalesInvoice, error)
+	GetDraftQuantitiesBySalesOrderItem(ctx context.Context, salesOrderItemIDs []uint) (map[uint]float64, error)
+	GetDraftQuantitiesByDeliveryNoteItem(ctx context.Context, deliveryNoteItemIDs []uint) (map[uint]float64, error)
+	GetBilledAmountsByInvoice(ctx context.Context, salesOrderItemIDs []uint) (map[uint]models.Money, error)
+	ChangeDocStatus(ctx context.Context, invoice *models.SalesInvoice, toStatus string, userID uint, reason string) error
+	GetSalesOrderIDs(ctx context.Context, invoiceID uint) ([]uint, error)
 }
 
+// ErrOverBilling 累计开票数量超过销售订单或送货单明细的可开票数量
+var ErrOverBilling = errors.New("开票数量超过可开票数量")
+
 // SalesInvoiceRepositoryImpl 销售发票仓储实现
 type SalesInvoiceRepositoryImpl struct {
 	BaseRepository[models.SalesInvoice]
@@ -564,6 +924,217 @@ func (r *SalesInvoiceRepositoryImpl) UpdateStatus(ctx context.Context, id uint,
 	return r.db.WithContext(ctx).Model(&models.SalesInvoice{}).Where("id = ?", id).Update("doc_status", status).Error
 }
 
+// GetWithItems 获取销售发票及其客户与明细，不存在时返回 nil
+func (r *SalesInvoiceRepositoryImpl) GetWithItems(ctx context.Context, id uint) (*models.SalesInvoice, error) {
+	var invoice models.SalesInvoice
+	err := r.db.WithContext(ctx).
+		Preload("Customer").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Item").
+		First(&invoice, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// GetDraftQuantitiesBySalesOrderItem 按订单明细汇总草稿销售发票中尚未提交的开票数量
+func (r *SalesInvoiceRepositoryImpl) GetDraftQuantitiesBySalesOrderItem(ctx context.Context, salesOrderItemIDs []uint) (map[uint]float64, error) {
+	return r.getDraftQuantities(ctx, "sales_order_item_id", salesOrderItemIDs)
+}
+
+// GetDraftQuantitiesByDeliveryNoteItem 按送货单明细汇总草稿销售发票中尚未提交的开票数量
+func (r *SalesInvoiceRepositoryImpl) GetDraftQuantitiesByDeliveryNoteItem(ctx context.Context, deliveryNoteItemIDs []uint) (map[uint]float64, error) {
+	return r.getDraftQuantities(ctx, "delivery_note_item_id", deliveryNoteItemIDs)
+}
+
+// GetBilledAmountsByInvoice 按发票汇总未取消的销售发票中来源于指定订单明细的开票金额（折扣前），草稿发票一并计入
+func (r *SalesInvoiceRepositoryImpl) GetBilledAmountsByInvoice(ctx context.Context, salesOrderItemIDs []uint) (map[uint]models.Money, error) {
+	amounts := make(map[uint]models.Money)
+	if len(salesOrderItemIDs) == 0 {
+		return amounts, nil
+	}
+
+	var rows []struct {
+		SalesInvoiceID uint
+		Amount         models.Money
+	}
+	err := r.db.WithContext(ctx).
+		Table("sales_invoice_items").
+		Select("sales_invoice_items.sales_invoice_id, sales_invoice_items.amount").
+		Joins("JOIN sales_invoices ON sales_invoices.id = sales_invoice_items.sales_invoice_id AND sales_invoices.deleted_at IS NULL").
+		Where("sales_invoices.doc_status <> ?", "Cancelled").
+		Where("sales_invoice_items.sales_order_item_id IN ? AND sales_invoice_items.deleted_at IS NULL", salesOrderItemIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		amounts[row.SalesInvoiceID] += row.Amount
+	}
+	return amounts, nil
+}
+
+// getDraftQuantities 按来源明细列汇总草稿发票的开票数量，换算为库存单位
+func (r *SalesInvoiceRepositoryImpl) getDraftQuantities(ctx context.Context, column string, ids []uint) (map[uint]float64, error) {
+	quantities := make(map[uint]float64, len(ids))
+	if len(ids) == 0 {
+		return quantities, nil
+	}
+
+	var rows []struct {
+		SourceID uint
+		Quantity float64
+	}
+	err := r.db.WithContext(ctx).
+		Table("sales_invoice_items").
+		Select("sales_invoice_items."+column+" AS source_id, SUM(sales_invoice_items.quantity * CASE WHEN sales_invoice_items.conversion_factor > 0 THEN sales_invoice_items.conversion_factor ELSE 1 END) AS quantity").
+		Joins("JOIN sales_invoices ON sales_invoices.id = sales_invoice_items.sales_invoice_id AND sales_invoices.deleted_at IS NULL").
+		Where("sales_invoices.doc_status = ?", "Draft").
+		Where("sales_invoice_items."+column+" IN ? AND sales_invoice_items.deleted_at IS NULL", ids).
+		Group("sales_invoice_items." + column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		quantities[row.SourceID] = row.Quantity
+	}
+	return quantities, nil
+}
+
+// ChangeDocStatus 在同一事务中变更发票单据状态并写入状态日志：提交时累加来源订单明细与送货单明细的开票数量，
+// 超出可开票数量时整体回滚；已提交的发票取消时扣回开票数量
+func (r *SalesInvoiceRepositoryImpl) ChangeDocStatus(ctx context.Context, invoice *models.SalesInvoice, toStatus string, userID uint, reason string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		updates := map[string]interface{}{"doc_status": toStatus, "updated_by": userID}
+		if toStatus == "Submitted" {
+			updates["submitted_by"] = userID
+			updates["submitted_at"] = now
+		}
+		result := tx.Model(&models.SalesInvoice{}).
+			Where("id = ? AND doc_status = ?", invoice.ID, invoice.DocStatus).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("发票 %s 状态已变更，请刷新后重试", invoice.InvoiceNumber)
+		}
+
+		switch {
+		case toStatus == "Submitted":
+			for _, item := range invoice.Items {
+				if err := billInvoiceItem(tx, &item); err != nil {
+					return err
+				}
+			}
+		case invoice.DocStatus == "Submitted" && toStatus == "Cancelled":
+			for _, item := range invoice.Items {
+				if err := unbillInvoiceItem(tx, &item); err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Create(&models.InvoiceStatusLog{
+			SalesInvoiceID: invoice.ID,
+			FromStatus:     invoice.DocStatus,
+			ToStatus:       toStatus,
+			StatusType:     "doc_status",
+			ChangedBy:      userID,
+			ChangedAt:      now,
+			Reason:         reason,
+		}).Error
+	})
+}
+
+// GetSalesOrderIDs 获取发票明细所开票的销售订单，合并开票的发票可能涉及多张订单
+func (r *SalesInvoiceRepositoryImpl) GetSalesOrderIDs(ctx context.Context, invoiceID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Table("sales_invoice_items").
+		Distinct("sales_order_items.sales_order_id").
+		Joins("JOIN sales_order_items ON sales_order_items.id = sales_invoice_items.sales_order_item_id").
+		Where("sales_invoice_items.sales_invoice_id = ? AND sales_invoice_items.deleted_at IS NULL", invoiceID).
+		Pluck("sales_order_items.sales_order_id", &ids).Error
+	return ids, err
+}
+
+// billInvoiceItem 按发票明细的库存单位数量累加来源明细的开票数量。订单明细的数量与已发货数量均按库存单位记录，
+// 最多开票至两者中的较大者；送货单明细最多开票至其库存单位发货数量
+func billInvoiceItem(tx *gorm.DB, item *models.SalesInvoiceItem) error {
+	quantity := item.StockQuantity()
+	if item.SalesOrderItemID != nil {
+		var line models.SalesOrderItem
+		if err := tx.First(&line, *item.SalesOrderItemID).Error; err != nil {
+			return fmt.Errorf("销售订单明细 %d 不存在", *item.SalesOrderItemID)
+		}
+		result := tx.Model(&models.SalesOrderItem{}).
+			Where("id = ? AND billed_qty + ? <= CASE WHEN delivered_qty > quantity THEN delivered_qty ELSE quantity END + ?",
+				line.ID, quantity, quantityEpsilon).
+			Update("billed_qty", gorm.Expr("billed_qty + ?", quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w：物料 %s 订单数量 %.2f，已发货 %.2f，已开票 %.2f，本次开票 %.2f",
+				ErrOverBilling, item.ItemCode, line.Quantity, line.DeliveredQty, line.BilledQty, quantity)
+		}
+	}
+
+	if item.DeliveryNoteItemID != nil {
+		var line models.DeliveryNoteItem
+		if err := tx.First(&line, *item.DeliveryNoteItemID).Error; err != nil {
+			return fmt.Errorf("送货单明细 %d 不存在", *item.DeliveryNoteItemID)
+		}
+		var deliveryNote models.DeliveryNote
+		if err := tx.First(&deliveryNote, line.DeliveryNoteID).Error; err != nil {
+			return fmt.Errorf("送货单 %d 不存在", line.DeliveryNoteID)
+		}
+		if deliveryNote.Status != models.DeliveryNoteStatusSubmitted && deliveryNote.Status != models.DeliveryNoteStatusDelivered {
+			return fmt.Errorf("送货单 %s 状态为 %s，不能开票", deliveryNote.DeliveryNumber, deliveryNote.Status)
+		}
+		result := tx.Model(&models.DeliveryNoteItem{}).
+			Where("id = ? AND billed_qty + ? <= CASE WHEN stock_qty > 0 THEN stock_qty ELSE quantity END + ?",
+				line.ID, quantity, quantityEpsilon).
+			Update("billed_qty", gorm.Expr("billed_qty + ?", quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w：物料 %s 送货数量 %.2f，已开票 %.2f，本次开票 %.2f",
+				ErrOverBilling, item.ItemCode, line.StockQuantity(), line.BilledQty, quantity)
+		}
+	}
+	return nil
+}
+
+// unbillInvoiceItem 按发票明细的库存单位数量扣回来源明细累加的开票数量，最低扣至 0
+func unbillInvoiceItem(tx *gorm.DB, item *models.SalesInvoiceItem) error {
+	quantity := item.StockQuantity()
+	expr := gorm.Expr("CASE WHEN billed_qty > ? THEN billed_qty - ? ELSE 0 END", quantity, quantity)
+	if item.SalesOrderItemID != nil {
+		if err := tx.Model(&models.SalesOrderItem{}).Where("id = ?", *item.SalesOrderItemID).
+			Update("billed_qty", expr).Error; err != nil {
+			return err
+		}
+	}
+	if item.DeliveryNoteItemID != nil {
+		if err := tx.Model(&models.DeliveryNoteItem{}).Where("id = ?", *item.DeliveryNoteItemID).
+			Update("billed_qty", expr).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Search 搜索销售发票
 func (r *SalesInvoiceRepositoryImpl) Search(ctx context.Context, keyword string, options *common.QueryOptions) ([]*models.SalesInvoice, error) {
 	var invoices []*models.SalesInvoice
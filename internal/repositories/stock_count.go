@@ -0,0 +1,435 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// StockCountFilter 盘点单筛选条件，ID 为 0、字符串为空表示不筛选
+type StockCountFilter struct {
+	WarehouseID uint
+	CountType   string
+	Status      string
+}
+
+// StockCountSnapshotFilter 生成盘点行的范围：指定库区时按库位库存生成，否则按仓库库存生成；
+// ItemIDs 为空表示仓库全部物料
+type StockCountSnapshotFilter struct {
+	WarehouseID uint
+	Zone        string
+	ItemIDs     []uint
+	IncludeZero bool
+}
+
+// StockCountVoucher 盘点差异总账凭证：盘盈借存货科目、贷差异科目，盘亏反之
+type StockCountVoucher struct {
+	CompanyID           uint
+	InventoryAccountID  uint
+	DifferenceAccountID uint
+	Date                time.Time
+	Description         string
+}
+
+// StockCountRepository 盘点仓储接口
+type StockCountRepository interface {
+	BaseRepository[models.StockCount]
+	GetStockCount(ctx context.Context, id uint) (*models.StockCount, error)
+	ListStockCounts(ctx context.Context, filter StockCountFilter, offset, limit int) ([]*models.StockCount, int64, error)
+	GetSnapshotLines(ctx context.Context, filter StockCountSnapshotFilter) ([]models.StockCountItem, error)
+	GetItemByCode(ctx context.Context, code string) (*models.Item, error)
+	GetItemByID(ctx context.Context, id uint) (*models.Item, error)
+	GetCountWarehouses(ctx context.Context, warehouseID uint) ([]*models.Warehouse, error)
+	GetStockedItems(ctx context.Context, warehouseID uint) ([]*models.Item, error)
+	GetLastCountedAt(ctx context.Context, warehouseID uint) (map[uint]time.Time, error)
+	GetOpenCountItemIDs(ctx context.Context, warehouseID uint) (map[uint]bool, error)
+	SaveCountLines(ctx context.Context, lines []*models.StockCountItem) error
+	UpdateStockCount(ctx context.Context, count *models.StockCount, updates map[string]interface{}) error
+	PostStockCount(ctx context.Context, count *models.StockCount, voucher *StockCountVoucher) error
+}
+
+// StockCountRepositoryImpl 盘点仓储实现
+type StockCountRepositoryImpl struct {
+	BaseRepository[models.StockCount]
+	db *gorm.DB
+}
+
+// NewStockCountRepository 创建盘点仓储实例
+func NewStockCountRepository(db *gorm.DB) StockCountRepository {
+	return &StockCountRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.StockCount](db),
+		db:             db,
+	}
+}
+
+// openStockCountStatuses 尚未过账或取消的盘点单状态
+var openStockCountStatuses = []string{
+	models.StockCountStatusCounting,
+	models.StockCountStatusPendingApproval,
+	models.StockCountStatusApproved,
+}
+
+// GetStockCount 获取盘点单及盘点行
+func (r *StockCountRepositoryImpl) GetStockCount(ctx context.Context, id uint) (*models.StockCount, error) {
+	var count models.StockCount
+	err := r.db.WithContext(ctx).
+		Preload("Warehouse").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Item").
+		Preload("Items.Location").
+		First(&count, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &count, nil
+}
+
+// ListStockCounts 分页获取盘点单
+func (r *StockCountRepositoryImpl) ListStockCounts(ctx context.Context, filter StockCountFilter, offset, limit int) ([]*models.StockCount, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.StockCount{})
+	if filter.WarehouseID != 0 {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.CountType != "" {
+		query = query.Where("count_type = ?", filter.CountType)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var counts []*models.StockCount
+	err := query.Preload("Warehouse").
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&counts).Error
+	return counts, total, err
+}
+
+// GetSnapshotLines 读取当前账面数量生成盘点行：按库区时取库区内各库位、批次的库位库存；
+// 否则批次或序列号管理物料按批次库存生成，其余物料按仓库库存生成。单位成本取库存单价，无库存单价时取物料成本
+func (r *StockCountRepositoryImpl) GetSnapshotLines(ctx context.Context, filter StockCountSnapshotFilter) ([]models.StockCountItem, error) {
+	type snapshotRow struct {
+		ItemID        uint
+		LocationID    *uint
+		BatchID       *uint
+		BatchNo       string
+		Quantity      float64
+		ValuationRate models.Money
+		Cost          models.Money
+	}
+	var rows []snapshotRow
+	db := r.db.WithContext(ctx)
+
+	rateJoin := "LEFT JOIN stocks ON stocks.item_id = items.id AND stocks.warehouse_id = ? AND stocks.deleted_at IS NULL"
+	if filter.Zone != "" {
+		query := db.Table("location_stocks").
+			Select("location_stocks.item_id, location_stocks.location_id, "+
+				"NULLIF(location_stocks.batch_id, 0) AS batch_id, COALESCE(batches.batch_no, '') AS batch_no, "+
+				"location_stocks.quantity, COALESCE(stocks.valuation_rate, 0) AS valuation_rate, items.cost").
+			Joins("JOIN locations ON locations.id = location_stocks.location_id AND locations.deleted_at IS NULL").
+			Joins("JOIN items ON items.id = location_stocks.item_id AND items.deleted_at IS NULL").
+			Joins(rateJoin, filter.WarehouseID).
+			Joins("LEFT JOIN batches ON batches.id = location_stocks.batch_id").
+			Where("location_stocks.deleted_at IS NULL AND location_stocks.warehouse_id = ? AND locations.zone = ?",
+				filter.WarehouseID, filter.Zone)
+		if len(filter.ItemIDs) > 0 {
+			query = query.Where("location_stocks.item_id IN ?", filter.ItemIDs)
+		}
+		if !filter.IncludeZero {
+			query = query.Where("ABS(location_stocks.quantity) > ?", quantityEpsilon)
+		}
+		if err := query.Order("locations.sequence, locations.code, items.code, batch_no").Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+	} else {
+		tracked := []string{models.TrackingModeBatch, models.TrackingModeSerial}
+
+		untracked := db.Table("stocks").
+			Select("stocks.item_id, stocks.quantity, stocks.valuation_rate, items.cost").
+			Joins("JOIN items ON items.id = stocks.item_id AND items.deleted_at IS NULL").
+			Where("stocks.deleted_at IS NULL AND stocks.warehouse_id = ? AND COALESCE(items.tracking_mode, '') NOT IN ?",
+				filter.WarehouseID, tracked)
+		batched := db.Table("batch_stocks").
+			Select("batch_stocks.item_id, batch_stocks.batch_id, batches.batch_no, batch_stocks.quantity, "+
+				"COALESCE(stocks.valuation_rate, 0) AS valuation_rate, items.cost").
+			Joins("JOIN items ON items.id = batch_stocks.item_id AND items.deleted_at IS NULL").
+			Joins("JOIN batches ON batches.id = batch_stocks.batch_id").
+			Joins(rateJoin, filter.WarehouseID).
+			Where("batch_stocks.deleted_at IS NULL AND batch_stocks.warehouse_id = ? AND items.tracking_mode IN ?",
+				filter.WarehouseID, tracked)
+		if len(filter.ItemIDs) > 0 {
+			untracked = untracked.Where("stocks.item_id IN ?", filter.ItemIDs)
+			batched = batched.Where("batch_stocks.item_id IN ?", filter.ItemIDs)
+		}
+		if !filter.IncludeZero {
+			untracked = untracked.Where("ABS(stocks.quantity) > ?", quantityEpsilon)
+			batched = batched.Where("ABS(batch_stocks.quantity) > ?", quantityEpsilon)
+		}
+
+		if err := untracked.Order("items.code").Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		var batchRows []snapshotRow
+		if err := batched.Order("items.code, batches.batch_no").Scan(&batchRows).Error; err != nil {
+			return nil, err
+		}
+		rows = append(rows, batchRows...)
+	}
+
+	lines := make([]models.StockCountItem, 0, len(rows))
+	for _, row := range rows {
+		rate := row.ValuationRate
+		if rate.IsZero() {
+			rate = row.Cost
+		}
+		lines = append(lines, models.StockCountItem{
+			ItemID:        row.ItemID,
+			LocationID:    row.LocationID,
+			BatchID:       row.BatchID,
+			BatchNo:       row.BatchNo,
+			ExpectedQty:   row.Quantity,
+			ValuationRate: rate,
+		})
+	}
+	return lines, nil
+}
+
+// GetItemByCode 根据物料编码或登记的物料条码获取物料，用于解析扫描数据；
+// 盘点数量按库存单位计，只匹配库存单位的条码，整箱等其他单位的条码不参与
+func (r *StockCountRepositoryImpl) GetItemByCode(ctx context.Context, code string) (*models.Item, error) {
+	var item models.Item
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = r.db.WithContext(ctx).
+			Where("id IN (?)", r.db.Model(&models.ItemBarcode{}).Select("item_id").
+				Where("barcode = ? AND (item_barcodes.uom = '' OR item_barcodes.uom = items.unit)", code)).
+			First(&item).Error
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetItemByID 根据ID获取物料，用于追加账外盘点行
+func (r *StockCountRepositoryImpl) GetItemByID(ctx context.Context, id uint) (*models.Item, error) {
+	var item models.Item
+	if err := r.db.WithContext(ctx).First(&item, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetCountWarehouses 获取可盘点的仓库（启用且非在途仓），warehouseID 为 0 时返回全部
+func (r *StockCountRepositoryImpl) GetCountWarehouses(ctx context.Context, warehouseID uint) ([]*models.Warehouse, error) {
+	query := r.db.WithContext(ctx).Where("is_active = ? AND is_transit = ?", true, false)
+	if warehouseID != 0 {
+		query = query.Where("id = ?", warehouseID)
+	}
+	var warehouses []*models.Warehouse
+	err := query.Order("code").Find(&warehouses).Error
+	return warehouses, err
+}
+
+// GetStockedItems 获取在仓库有账面库存的物料
+func (r *StockCountRepositoryImpl) GetStockedItems(ctx context.Context, warehouseID uint) ([]*models.Item, error) {
+	var items []*models.Item
+	err := r.db.WithContext(ctx).
+		Where("id IN (?)", r.db.Model(&models.Stock{}).Select("item_id").
+			Where("warehouse_id = ? AND ABS(quantity) > ?", warehouseID, quantityEpsilon)).
+		Order("code").
+		Find(&items).Error
+	return items, err
+}
+
+// GetLastCountedAt 获取仓库内各物料最近一次已过账盘点的时间
+func (r *StockCountRepositoryImpl) GetLastCountedAt(ctx context.Context, warehouseID uint) (map[uint]time.Time, error) {
+	var rows []struct {
+		ItemID   uint
+		PostedAt time.Time
+	}
+	// 逐行读取过账时间后取最大值，聚合函数在 SQLite 下返回文本无法扫描为时间
+	if err := r.db.WithContext(ctx).Table("stock_count_items").
+		Select("stock_count_items.item_id, stock_counts.posted_at").
+		Joins("JOIN stock_counts ON stock_counts.id = stock_count_items.stock_count_id AND stock_counts.deleted_at IS NULL").
+		Where("stock_count_items.deleted_at IS NULL AND stock_counts.warehouse_id = ? AND stock_counts.status = ?",
+			warehouseID, models.StockCountStatusPosted).
+		Where("stock_count_items.counted_qty IS NOT NULL AND stock_counts.posted_at IS NOT NULL").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counted := make(map[uint]time.Time, len(rows))
+	for _, row := range rows {
+		if last, ok := counted[row.ItemID]; !ok || row.PostedAt.After(last) {
+			counted[row.ItemID] = row.PostedAt
+		}
+	}
+	return counted, nil
+}
+
+// GetOpenCountItemIDs 获取仓库内已在未完成盘点单中的物料
+func (r *StockCountRepositoryImpl) GetOpenCountItemIDs(ctx context.Context, warehouseID uint) (map[uint]bool, error) {
+	var itemIDs []uint
+	if err := r.db.WithContext(ctx).Table("stock_count_items").
+		Joins("JOIN stock_counts ON stock_counts.id = stock_count_items.stock_count_id AND stock_counts.deleted_at IS NULL").
+		Where("stock_count_items.deleted_at IS NULL AND stock_counts.warehouse_id = ? AND stock_counts.status IN ?",
+			warehouseID, openStockCountStatuses).
+		Distinct().Pluck("stock_count_items.item_id", &itemIDs).Error; err != nil {
+		return nil, err
+	}
+
+	open := make(map[uint]bool, len(itemIDs))
+	for _, itemID := range itemIDs {
+		open[itemID] = true
+	}
+	return open, nil
+}
+
+// SaveCountLines 在同一事务中保存实盘数量，新追加的盘点行一并创建
+func (r *StockCountRepositoryImpl) SaveCountLines(ctx context.Context, lines []*models.StockCountItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, line := range lines {
+			if line.ID == 0 {
+				if err := tx.Omit("Item", "Location").Create(line).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(&models.StockCountItem{}).Where("id = ?", line.ID).Updates(map[string]interface{}{
+				"counted_qty":    line.CountedQty,
+				"variance_qty":   line.VarianceQty,
+				"variance_value": line.VarianceValue,
+				"serial_no":      line.SerialNo,
+				"counted_at":     line.CountedAt,
+				"counted_by":     line.CountedBy,
+				"notes":          line.Notes,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateStockCount 更新盘点单字段
+func (r *StockCountRepositoryImpl) UpdateStockCount(ctx context.Context, count *models.StockCount, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(count).Updates(updates).Error
+}
+
+// PostStockCount 在同一事务中将盘点差异过账：每个有差异的盘点行在当前库存上按差异数量生成一笔调整移动，
+// 按实际调整金额汇总盘盈、盘亏并生成总账凭证，最后将盘点单标记为已过账
+func (r *StockCountRepositoryImpl) PostStockCount(ctx context.Context, count *models.StockCount, voucher *StockCountVoucher) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var gain, loss models.Money
+		for i := range count.Items {
+			line := &count.Items[i]
+			if !line.IsCounted() || line.VarianceQty == 0 {
+				continue
+			}
+
+			stock, err := lockStock(tx, line.ItemID, count.WarehouseID)
+			if err != nil {
+				return err
+			}
+			target := stock.Quantity + line.VarianceQty
+			itemID, warehouseID := line.ItemID, count.WarehouseID
+			key := fmt.Sprintf("%s:%d:%d", models.MovementReferenceStockCount, count.ID, line.ID)
+			movement := &models.Movement{
+				ItemID:          &itemID,
+				WarehouseID:     &warehouseID,
+				Quantity:        &target,
+				MovementType:    models.MovementTypeAdjustment,
+				Reference:       count.CountNumber,
+				ReferenceType:   models.MovementReferenceStockCount,
+				ReferenceID:     &count.ID,
+				ReferenceLineID: &line.ID,
+				IdempotencyKey:  &key,
+				LocationID:      line.LocationID,
+				BatchNo:         line.BatchNo,
+				SerialNo:        line.SerialNo,
+				Notes:           fmt.Sprintf("盘点差异 %.4f", line.VarianceQty),
+				CreatedBy:       count.ApprovedBy,
+			}
+			if err := postMovement(tx, movement); err != nil {
+				return fmt.Errorf("盘点行 %d 过账失败: %w", line.ID, err)
+			}
+
+			line.MovementID = &movement.ID
+			line.VarianceValue = movement.ValueChange
+			if movement.ValueChange.IsPositive() {
+				gain = gain.Add(movement.ValueChange)
+			} else {
+				loss = loss.Add(movement.ValueChange.Neg())
+			}
+			if err := tx.Model(&models.StockCountItem{}).Where("id = ?", line.ID).Updates(map[string]interface{}{
+				"movement_id":    line.MovementID,
+				"variance_value": line.VarianceValue,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		updates := map[string]interface{}{
+			"status":     models.StockCountStatusPosted,
+			"gain_value": gain,
+			"loss_value": loss,
+			"posted_at":  time.Now(),
+		}
+		if voucher != nil && (gain.IsPositive() || loss.IsPositive()) {
+			transaction := &models.Transaction{
+				CompanyID:         voucher.CompanyID,
+				TransactionNumber: fmt.Sprintf("TXN-%d", time.Now().UnixNano()),
+				TransactionDate:   voucher.Date,
+				TransactionType:   "journal",
+				Amount:            gain.Add(loss),
+				Description:       voucher.Description,
+				ReferenceType:     models.MovementReferenceStockCount,
+				ReferenceID:       &count.ID,
+				Status:            "completed",
+			}
+			if err := tx.Create(transaction).Error; err != nil {
+				return err
+			}
+
+			var entries []*models.JournalEntry
+			if gain.IsPositive() {
+				entries = append(entries,
+					&models.JournalEntry{AccountID: voucher.InventoryAccountID, Debit: gain, Description: "盘盈"},
+					&models.JournalEntry{AccountID: voucher.DifferenceAccountID, Credit: gain, Description: "盘盈"})
+			}
+			if loss.IsPositive() {
+				entries = append(entries,
+					&models.JournalEntry{AccountID: voucher.DifferenceAccountID, Debit: loss, Description: "盘亏"},
+					&models.JournalEntry{AccountID: voucher.InventoryAccountID, Credit: loss, Description: "盘亏"})
+			}
+			for _, entry := range entries {
+				entry.TransactionID = transaction.ID
+				entry.CompanyID = transaction.CompanyID
+				if err := tx.Create(entry).Error; err != nil {
+					return err
+				}
+			}
+			updates["transaction_id"] = transaction.ID
+		}
+		return tx.Model(count).Updates(updates).Error
+	})
+}
@@ -0,0 +1,501 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientStock 出库数量超过当前库存
+var ErrInsufficientStock = errors.New("库存不足")
+
+// quantityEpsilon 数量比较容差，避免浮点误差留下极小的残余数量
+const quantityEpsilon = 1e-9
+
+// StockLedgerBalance 物料在仓库的库存余额
+type StockLedgerBalance struct {
+	ItemID      uint
+	WarehouseID uint
+	Quantity    float64
+	StockValue  models.Money
+}
+
+// StockLedgerRepository 库存台账仓储接口
+// 库存移动只追加不修改，库存余额只能通过 PostMovement 在同一事务中变更
+type StockLedgerRepository interface {
+	PostMovement(ctx context.Context, movement *models.Movement) (bool, error)
+	PostMovements(ctx context.Context, movements []*models.Movement) error
+	GetByIdempotencyKey(ctx context.Context, key string) (*models.Movement, error)
+	GetLedgerBalances(ctx context.Context, itemID, warehouseID uint) ([]StockLedgerBalance, error)
+	GetStockBalances(ctx context.Context, itemID, warehouseID uint) ([]StockLedgerBalance, error)
+	RebuildStock(ctx context.Context, itemID, warehouseID uint) (float64, error)
+	EnsureOpeningBalances(ctx context.Context) (int, error)
+}
+
+// StockLedgerRepositoryImpl 库存台账仓储实现
+type StockLedgerRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewStockLedgerRepository 创建库存台账仓储实例
+func NewStockLedgerRepository(db *gorm.DB) StockLedgerRepository {
+	return &StockLedgerRepositoryImpl{db: db}
+}
+
+// PostMovement 过账库存移动：锁定库存行、条件更新余额并写入台账，全部在一个事务中完成。
+// 携带幂等键的移动重复过账时返回已存在的记录，第一个返回值为 true
+func (r *StockLedgerRepositoryImpl) PostMovement(ctx context.Context, movement *models.Movement) (bool, error) {
+	if movement.IdempotencyKey != nil {
+		existing, err := r.GetByIdempotencyKey(ctx, *movement.IdempotencyKey)
+		if err != nil {
+			return false, err
+		}
+		if existing != nil {
+			*movement = *existing
+			return true, nil
+		}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return postMovement(tx, movement)
+	})
+	if err != nil && movement.IdempotencyKey != nil && !errors.Is(err, ErrInsufficientStock) {
+		// 并发重复过账时唯一索引冲突，事务已回滚，返回先提交的记录
+		existing, lookupErr := r.GetByIdempotencyKey(ctx, *movement.IdempotencyKey)
+		if lookupErr == nil && existing != nil {
+			*movement = *existing
+			return true, nil
+		}
+	}
+	return false, err
+}
+
+// PostMovements 在一个事务中过账多条库存移动，任一条失败时全部回滚；不处理幂等键重复，由调用方先行检查
+func (r *StockLedgerRepositoryImpl) PostMovements(ctx context.Context, movements []*models.Movement) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return postMovements(tx, movements)
+	})
+}
+
+// GetByIdempotencyKey 根据幂等键获取库存移动
+func (r *StockLedgerRepositoryImpl) GetByIdempotencyKey(ctx context.Context, key string) (*models.Movement, error) {
+	var movement models.Movement
+	err := r.db.WithContext(ctx).Where("idempotency_key = ?", key).First(&movement).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &movement, nil
+}
+
+// GetLedgerBalances 按物料和仓库汇总台账余额，ID 为 0 表示不筛选
+func (r *StockLedgerRepositoryImpl) GetLedgerBalances(ctx context.Context, itemID, warehouseID uint) ([]StockLedgerBalance, error) {
+	var balances []StockLedgerBalance
+	query := r.db.WithContext(ctx).Model(&models.Movement{}).
+		Select("item_id, warehouse_id, SUM(quantity_change) AS quantity, SUM(value_change) AS stock_value").
+		Where("item_id IS NOT NULL AND warehouse_id IS NOT NULL")
+	query = filterStockPair(query, itemID, warehouseID)
+	err := query.Group("item_id, warehouse_id").Scan(&balances).Error
+	return balances, err
+}
+
+// GetStockBalances 获取库存表中的余额，ID 为 0 表示不筛选
+func (r *StockLedgerRepositoryImpl) GetStockBalances(ctx context.Context, itemID, warehouseID uint) ([]StockLedgerBalance, error) {
+	var balances []StockLedgerBalance
+	query := r.db.WithContext(ctx).Model(&models.Stock{}).Select("item_id, warehouse_id, quantity, stock_value")
+	query = filterStockPair(query, itemID, warehouseID)
+	err := query.Order("item_id, warehouse_id").Scan(&balances).Error
+	return balances, err
+}
+
+// RebuildStock 按台账重算库存数量与金额，返回重算后的数量
+func (r *StockLedgerRepositoryImpl) RebuildStock(ctx context.Context, itemID, warehouseID uint) (float64, error) {
+	var quantity float64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stock, err := lockStock(tx, itemID, warehouseID)
+		if err != nil {
+			return err
+		}
+
+		var sum struct {
+			Quantity   float64
+			StockValue models.Money
+		}
+		if err := tx.Model(&models.Movement{}).
+			Select("COALESCE(SUM(quantity_change), 0) AS quantity, COALESCE(SUM(value_change), 0) AS stock_value").
+			Where("item_id = ? AND warehouse_id = ?", itemID, warehouseID).
+			Scan(&sum).Error; err != nil {
+			return err
+		}
+
+		quantity = sum.Quantity
+		updates := map[string]interface{}{"quantity": quantity, "stock_value": sum.StockValue}
+		if quantity > quantityEpsilon {
+			rate, err := sum.StockValue.Div(quantity)
+			if err != nil {
+				return err
+			}
+			updates["valuation_rate"] = rate
+		}
+		if err := tx.Model(&models.Stock{}).Where("id = ?", stock.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return allocateReservations(tx, itemID, warehouseID, quantity)
+	})
+	return quantity, err
+}
+
+// EnsureOpeningBalances 为尚无台账记录的库存补录期初移动，使台账余额与现有库存一致。
+// 用于启用库存台账前已存在的库存数据，返回补录的记录数
+func (r *StockLedgerRepositoryImpl) EnsureOpeningBalances(ctx context.Context) (int, error) {
+	var stocks []models.Stock
+	err := r.db.WithContext(ctx).
+		Where("quantity <> 0").
+		Where("NOT EXISTS (?)", r.db.Model(&models.Movement{}).
+			Select("1").
+			Where("movements.item_id = stocks.item_id AND movements.warehouse_id = stocks.warehouse_id AND movements.quantity_change <> 0")).
+		Find(&stocks).Error
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, stock := range stocks {
+		itemID, warehouseID, quantity := stock.ItemID, stock.WarehouseID, stock.Quantity
+		key := fmt.Sprintf("opening:%d:%d", itemID, warehouseID)
+		movement := &models.Movement{
+			ItemID:         &itemID,
+			WarehouseID:    &warehouseID,
+			Quantity:       &quantity,
+			MovementType:   models.MovementTypeOpening,
+			Reference:      "期初库存",
+			ReferenceType:  "opening",
+			IdempotencyKey: &key,
+		}
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			existing, err := lockStock(tx, itemID, warehouseID)
+			if err != nil {
+				return err
+			}
+			// 期初移动从零余额开始过账，由 postMovement 计算期初金额并写回当前数量
+			if err := tx.Model(&models.Stock{}).Where("id = ?", existing.ID).
+				Updates(map[string]interface{}{"quantity": 0, "stock_value": 0}).Error; err != nil {
+				return err
+			}
+			return postMovement(tx, movement)
+		})
+		if err != nil {
+			if existing, lookupErr := r.GetByIdempotencyKey(ctx, key); lookupErr == nil && existing != nil {
+				continue
+			}
+			return created, err
+		}
+		created++
+	}
+	return created, nil
+}
+
+// postMovements 在给定事务中过账多条库存移动。先按物料、仓库顺序锁定全部库存行，
+// 固定的加锁顺序避免并发单据之间互相等待造成死锁；再按给定顺序过账，
+// 使调入移动可以沿用同一批次中先过账的调出成本
+func postMovements(tx *gorm.DB, movements []*models.Movement) error {
+	ordered := make([]*models.Movement, 0, len(movements))
+	for _, movement := range movements {
+		if movement.ItemID == nil || movement.WarehouseID == nil {
+			return errors.New("库存移动缺少物料或仓库")
+		}
+		ordered = append(ordered, movement)
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if *ordered[i].ItemID != *ordered[j].ItemID {
+			return *ordered[i].ItemID < *ordered[j].ItemID
+		}
+		return *ordered[i].WarehouseID < *ordered[j].WarehouseID
+	})
+	for _, movement := range ordered {
+		if _, err := lockStock(tx, *movement.ItemID, *movement.WarehouseID); err != nil {
+			return err
+		}
+	}
+
+	for _, movement := range movements {
+		if err := postMovement(tx, movement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postMovement 在给定事务中过账库存移动：锁定库存行、条件更新余额、按物料计价方法计算成本并写入台账；
+// 重估移动不改变数量，按调用方给定的金额变动调整库存金额与单位成本
+func postMovement(tx *gorm.DB, movement *models.Movement) error {
+	if movement.ItemID == nil || movement.WarehouseID == nil || movement.Quantity == nil {
+		return errors.New("库存移动缺少物料、仓库或数量")
+	}
+
+	stock, err := lockStock(tx, *movement.ItemID, *movement.WarehouseID)
+	if err != nil {
+		return err
+	}
+
+	var item models.Item
+	if err := tx.Unscoped().Select("id, code, cost, valuation_method, tracking_mode, shelf_life_days, has_variants").First(&item, *movement.ItemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("物料不存在")
+		}
+		return err
+	}
+	// 库存记录在变体上，模板物料只用于生成变体与汇总报表
+	if item.HasVariants {
+		return fmt.Errorf("物料 %s 是模板物料，不能直接出入库，请使用其变体", item.Code)
+	}
+
+	quantity := *movement.Quantity
+	var delta float64
+	switch movement.MovementType {
+	case models.MovementTypeIn, models.MovementTypeTransferIn, models.MovementTypeOpening:
+		delta = quantity
+	case models.MovementTypeOut, models.MovementTypeTransferOut:
+		delta = -quantity
+	case models.MovementTypeAdjustment:
+		delta = quantity - stock.Quantity
+	case models.MovementTypeRevaluation:
+		delta = 0
+	default:
+		return fmt.Errorf("不支持的库存移动类型: %s", movement.MovementType)
+	}
+
+	if delta != 0 {
+		// 条件更新保证并发出库不会超卖：余额不足时不更新任何行
+		query := tx.Model(&models.Stock{}).Where("id = ?", stock.ID)
+		if delta < 0 && movement.MovementType != models.MovementTypeAdjustment {
+			query = query.Where("quantity >= ?", -delta)
+		}
+		result := query.Update("quantity", gorm.Expr("quantity + ?", delta))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var current models.Stock
+			if err := tx.Select("quantity").First(&current, stock.ID).Error; err != nil {
+				return err
+			}
+			return fmt.Errorf("%w，当前库存: %.2f，需要: %.2f", ErrInsufficientStock, current.Quantity, quantity)
+		}
+	}
+
+	var balance models.Stock
+	if err := tx.Select("quantity").First(&balance, stock.ID).Error; err != nil {
+		return err
+	}
+
+	serials, err := applyTracking(tx, &item, movement, delta)
+	if err != nil {
+		return err
+	}
+	if err := applyLocation(tx, movement, delta, balance.Quantity); err != nil {
+		return err
+	}
+	if delta != 0 {
+		if err := allocateReservations(tx, *movement.ItemID, *movement.WarehouseID, balance.Quantity); err != nil {
+			return err
+		}
+	}
+
+	valueChange := movement.ValueChange
+	if movement.MovementType != models.MovementTypeRevaluation {
+		if valueChange, err = movementValue(tx, stock, &item, movement, delta); err != nil {
+			return err
+		}
+	}
+	stockValue := stock.StockValue.Add(valueChange)
+	rate := stock.ValuationRate
+	switch {
+	case item.ValuationMethod == models.ValuationMethodStandard:
+		rate = item.Cost
+	case balance.Quantity > quantityEpsilon:
+		rate, err = stockValue.Div(balance.Quantity)
+	case delta > 0 && rate.IsZero():
+		rate, err = valueChange.Div(delta)
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Stock{}).Where("id = ?", stock.ID).
+		Updates(map[string]interface{}{"stock_value": stockValue, "valuation_rate": rate}).Error; err != nil {
+		return err
+	}
+
+	movement.QuantityChange = delta
+	movement.BalanceAfter = balance.Quantity
+	movement.ValueChange = valueChange
+	movement.ValueAfter = stockValue
+	movement.TotalCost = valueChange.Abs()
+	if delta != 0 {
+		if movement.UnitCost, err = movement.TotalCost.Div(math.Abs(delta)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Create(movement).Error; err != nil {
+		return err
+	}
+	if err := linkMovementSerials(tx, movement, serials); err != nil {
+		return err
+	}
+
+	// 先进先出物料的每笔入库形成一个成本层
+	if delta > 0 && item.ValuationMethod == models.ValuationMethodFIFO {
+		return tx.Create(&models.StockCostLayer{
+			ItemID:       *movement.ItemID,
+			WarehouseID:  *movement.WarehouseID,
+			MovementID:   movement.ID,
+			Quantity:     delta,
+			RemainingQty: delta,
+			UnitCost:     movement.UnitCost,
+		}).Error
+	}
+	return nil
+}
+
+// movementValue 按物料计价方法计算库存移动对库存金额的带符号影响。
+// 入库按移动单价、来源移动单价、当前库存单价、物料成本的顺序取成本，标准成本法始终使用物料成本；
+// 出库时标准成本法按标准成本、移动加权平均按当前平均成本、先进先出按成本层依次结转
+func movementValue(tx *gorm.DB, stock *models.Stock, item *models.Item, movement *models.Movement, delta float64) (models.Money, error) {
+	if delta == 0 {
+		return 0, nil
+	}
+
+	if delta > 0 {
+		rate := item.Cost
+		if item.ValuationMethod != models.ValuationMethodStandard {
+			switch {
+			case !movement.UnitCost.IsZero():
+				rate = movement.UnitCost
+			case movement.SourceMovement != nil && !movement.SourceMovement.UnitCost.IsZero():
+				rate = movement.SourceMovement.UnitCost
+			case !stock.ValuationRate.IsZero():
+				rate = stock.ValuationRate
+			}
+		}
+		return rate.Mul(delta)
+	}
+
+	quantity := -delta
+	if item.ValuationMethod == models.ValuationMethodStandard {
+		value, err := item.Cost.Mul(quantity)
+		return value.Neg(), err
+	}
+
+	fallback := stock.ValuationRate
+	if fallback.IsZero() {
+		fallback = item.Cost
+	}
+	var value models.Money
+	var err error
+	switch {
+	case item.ValuationMethod == models.ValuationMethodFIFO:
+		value, err = consumeCostLayers(tx, stock.ItemID, stock.WarehouseID, quantity, fallback)
+	case stock.Quantity > quantityEpsilon:
+		if value, err = stock.StockValue.Mul(quantity); err == nil {
+			value, err = value.Div(stock.Quantity)
+		}
+	default:
+		value, err = fallback.Mul(quantity)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// 全部出库时结转全部库存金额，避免按单价计算留下尾差
+	if stock.Quantity > quantityEpsilon && quantity >= stock.Quantity-quantityEpsilon {
+		value = stock.StockValue
+	}
+	return value.Neg(), nil
+}
+
+// consumeCostLayers 按入库顺序消耗先进先出成本层，返回结转的成本；
+// 成本层不足的部分按 fallback 单价计算
+func consumeCostLayers(tx *gorm.DB, itemID, warehouseID uint, quantity float64, fallback models.Money) (models.Money, error) {
+	var layers []models.StockCostLayer
+	if err := tx.Where("item_id = ? AND warehouse_id = ? AND remaining_qty > ?", itemID, warehouseID, quantityEpsilon).
+		Order("id").Find(&layers).Error; err != nil {
+		return 0, err
+	}
+
+	var value models.Money
+	remaining := quantity
+	for i := range layers {
+		if remaining <= quantityEpsilon {
+			break
+		}
+		layer := &layers[i]
+		take := math.Min(layer.RemainingQty, remaining)
+		left := layer.RemainingQty - take
+		if left <= quantityEpsilon {
+			left = 0
+		}
+		cost, err := layer.UnitCost.Mul(take)
+		if err != nil {
+			return 0, err
+		}
+		value = value.Add(cost)
+		if err := tx.Model(&models.StockCostLayer{}).Where("id = ?", layer.ID).Update("remaining_qty", left).Error; err != nil {
+			return 0, err
+		}
+		remaining -= take
+	}
+	if remaining > quantityEpsilon {
+		cost, err := fallback.Mul(remaining)
+		if err != nil {
+			return 0, err
+		}
+		value = value.Add(cost)
+	}
+	return value, nil
+}
+
+// lockStock 获取并锁定物料在仓库的库存行，不存在时先创建。
+// PostgreSQL 使用 SELECT ... FOR UPDATE；SQLite 的写事务本身串行执行
+func lockStock(tx *gorm.DB, itemID, warehouseID uint) (*models.Stock, error) {
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "item_id"}, {Name: "warehouse_id"}},
+		DoNothing: true,
+	}).Create(&models.Stock{ItemID: itemID, WarehouseID: warehouseID}).Error; err != nil {
+		return nil, err
+	}
+
+	var stock models.Stock
+	if err := tx.Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("item_id = ? AND warehouse_id = ?", itemID, warehouseID).
+		First(&stock).Error; err != nil {
+		return nil, err
+	}
+
+	// 已删除的库存行在重新发生移动时恢复
+	if stock.DeletedAt.Valid {
+		if err := tx.Unscoped().Model(&stock).Update("deleted_at", nil).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &stock, nil
+}
+
+// filterStockPair 按物料和仓库筛选，ID 为 0 表示不筛选
+func filterStockPair(query *gorm.DB, itemID, warehouseID uint) *gorm.DB {
+	if itemID != 0 {
+		query = query.Where("item_id = ?", itemID)
+	}
+	if warehouseID != 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	return query
+}
@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// applyLocation 在库存移动过账时维护库位库存：指定库位的移动直接增减该库位库存；
+// 未指定库位的入库计入未分配库存，出库先扣减未分配库存，不足部分按入库先后从库位扣减。
+// balanceAfter 为过账后的仓库库存，须在批次库存更新后调用
+func applyLocation(tx *gorm.DB, movement *models.Movement, delta, balanceAfter float64) error {
+	if delta == 0 {
+		return nil
+	}
+	itemID := *movement.ItemID
+	warehouseID := *movement.WarehouseID
+	var batchID uint
+	if movement.BatchID != nil {
+		batchID = *movement.BatchID
+	}
+
+	if movement.LocationID != nil {
+		location, err := loadWarehouseLocation(tx, *movement.LocationID, warehouseID)
+		if err != nil {
+			return err
+		}
+		if delta > 0 {
+			if err := checkLocationCapacity(tx, location, delta); err != nil {
+				return err
+			}
+		}
+		return updateLocationStock(tx, itemID, warehouseID, location, batchID, delta, time.Now())
+	}
+	if delta > 0 {
+		return nil
+	}
+
+	// 出库后仓库（或批次）库存小于库位库存合计时，差额从库位中扣减
+	pool := balanceAfter
+	located := tx.Model(&models.LocationStock{}).Where("item_id = ? AND warehouse_id = ?", itemID, warehouseID)
+	if movement.BatchID != nil {
+		var batchStock models.BatchStock
+		if err := tx.Where("item_id = ? AND warehouse_id = ? AND batch_id = ?", itemID, warehouseID, batchID).
+			First(&batchStock).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		pool = batchStock.Quantity
+		located = located.Where("batch_id = ?", batchID)
+	}
+	var locatedQty float64
+	if err := located.Select("COALESCE(SUM(quantity), 0)").Scan(&locatedQty).Error; err != nil {
+		return err
+	}
+	excess := locatedQty - pool
+	if excess <= quantityEpsilon {
+		return nil
+	}
+
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("item_id = ? AND warehouse_id = ? AND quantity > ?", itemID, warehouseID, quantityEpsilon)
+	if movement.BatchID != nil {
+		query = query.Where("batch_id = ?", batchID)
+	}
+	// 隔离库位的库存最后扣减，避免未指定库位的出库取走待处理的过期库存
+	var stocks []models.LocationStock
+	quarantined := tx.Model(&models.Location{}).Select("id").Where("location_type = ?", models.LocationTypeQuarantine)
+	if err := query.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:  "CASE WHEN location_id IN (?) THEN 1 ELSE 0 END, first_received_at, id",
+		Vars: []interface{}{quarantined},
+	}}).Find(&stocks).Error; err != nil {
+		return err
+	}
+	for _, stock := range stocks {
+		if excess <= quantityEpsilon {
+			break
+		}
+		take := stock.Quantity
+		if take > excess {
+			take = excess
+		}
+		if err := tx.Model(&models.LocationStock{}).Where("id = ?", stock.ID).
+			Update("quantity", gorm.Expr("quantity - ?", take)).Error; err != nil {
+			return err
+		}
+		excess -= take
+	}
+	return nil
+}
+
+// loadWarehouseLocation 获取库位并校验其属于指定仓库
+func loadWarehouseLocation(tx *gorm.DB, locationID, warehouseID uint) (*models.Location, error) {
+	var location models.Location
+	if err := tx.First(&location, locationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("库位 %d 不存在", locationID)
+		}
+		return nil, err
+	}
+	if location.WarehouseID != warehouseID {
+		return nil, fmt.Errorf("库位 %s 不属于仓库 %d", location.Code, warehouseID)
+	}
+	return &location, nil
+}
+
+// checkLocationCapacity 校验库位可以再放入指定数量：库位须启用，设置容量时合计不能超过容量
+func checkLocationCapacity(tx *gorm.DB, location *models.Location, quantity float64) error {
+	if location.Status != "" && location.Status != models.LocationStatusActive {
+		return fmt.Errorf("库位 %s 已停用", location.Code)
+	}
+	if location.Capacity <= 0 {
+		return nil
+	}
+	var used float64
+	if err := tx.Model(&models.LocationStock{}).Where("location_id = ?", location.ID).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&used).Error; err != nil {
+		return err
+	}
+	if used+quantity > location.Capacity+quantityEpsilon {
+		return fmt.Errorf("库位 %s 容量不足: 容量 %.2f，已用 %.2f，需要 %.2f", location.Code, location.Capacity, used, quantity)
+	}
+	return nil
+}
+
+// updateLocationStock 增减库位库存；库位库存从零开始入库时记录入库时间，扣减超过库位库存时返回库存不足
+func updateLocationStock(tx *gorm.DB, itemID, warehouseID uint, location *models.Location, batchID uint, delta float64, at time.Time) error {
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "item_id"}, {Name: "location_id"}, {Name: "batch_id"}},
+		DoNothing: true,
+	}).Create(&models.LocationStock{ItemID: itemID, WarehouseID: warehouseID, LocationID: location.ID, BatchID: batchID}).Error; err != nil {
+		return err
+	}
+
+	query := tx.Model(&models.LocationStock{}).
+		Where("item_id = ? AND location_id = ? AND batch_id = ?", itemID, location.ID, batchID)
+	if delta > 0 {
+		return query.Updates(map[string]interface{}{
+			"first_received_at": gorm.Expr("CASE WHEN quantity <= ? OR first_received_at IS NULL THEN ? ELSE first_received_at END", quantityEpsilon, at),
+			"quantity":          gorm.Expr("quantity + ?", delta),
+		}).Error
+	}
+
+	result := query.Where("quantity >= ?", -delta-quantityEpsilon).Update("quantity", gorm.Expr("quantity + ?", delta))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var current models.LocationStock
+		if err := tx.Where("item_id = ? AND location_id = ? AND batch_id = ?", itemID, location.ID, batchID).
+			First(&current).Error; err != nil {
+			return err
+		}
+		return fmt.Errorf("%w，库位 %s 当前库存: %.2f，需要: %.2f", ErrInsufficientStock, location.Code, current.Quantity, -delta)
+	}
+	return nil
+}
+
+// unassignedQuantity 计算仓库（或批次）库存中尚未分配库位的数量
+func unassignedQuantity(tx *gorm.DB, itemID, warehouseID uint, batchID *uint) (float64, error) {
+	var pool float64
+	if batchID != nil {
+		if err := tx.Model(&models.BatchStock{}).
+			Where("item_id = ? AND warehouse_id = ? AND batch_id = ?", itemID, warehouseID, *batchID).
+			Select("COALESCE(SUM(quantity), 0)").Scan(&pool).Error; err != nil {
+			return 0, err
+		}
+	} else if err := tx.Model(&models.Stock{}).
+		Where("item_id = ? AND warehouse_id = ?", itemID, warehouseID).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&pool).Error; err != nil {
+		return 0, err
+	}
+
+	located := tx.Model(&models.LocationStock{}).Where("item_id = ? AND warehouse_id = ?", itemID, warehouseID)
+	if batchID != nil {
+		located = located.Where("batch_id = ?", *batchID)
+	}
+	var locatedQty float64
+	if err := located.Select("COALESCE(SUM(quantity), 0)").Scan(&locatedQty).Error; err != nil {
+		return 0, err
+	}
+	return pool - locatedQty, nil
+}
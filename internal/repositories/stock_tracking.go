@@ -0,0 +1,224 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrBatchExpired 出库批次已过期，过期库存只能通过库存调整报废或移入隔离库位
+var ErrBatchExpired = errors.New("批次已过期")
+
+// applyTracking 在库存移动写入前处理批次与序列号：解析批次并更新批次库存，
+// 校验并更新序列号所在仓库，返回需要与移动关联的序列号
+func applyTracking(tx *gorm.DB, item *models.Item, movement *models.Movement, delta float64) ([]models.SerialNumber, error) {
+	if item.TrackingMode != models.TrackingModeBatch && item.TrackingMode != models.TrackingModeSerial {
+		return nil, nil
+	}
+	if delta == 0 {
+		return nil, nil
+	}
+
+	movement.BatchNo = strings.TrimSpace(movement.BatchNo)
+	if item.TrackingMode == models.TrackingModeBatch && movement.BatchNo == "" {
+		return nil, fmt.Errorf("物料 %s 按批次管理，库存移动必须指定批次号", item.Code)
+	}
+	if item.TrackingMode == models.TrackingModeSerial && movement.BatchNo == "" {
+		if err := inferSerialBatch(tx, item, movement); err != nil {
+			return nil, err
+		}
+	}
+	if movement.BatchNo != "" {
+		batch, err := resolveBatch(tx, item, movement, delta > 0)
+		if err != nil {
+			return nil, err
+		}
+		movement.BatchID = &batch.ID
+		if isIssue(movement.MovementType) && batch.IsExpired(time.Now()) {
+			return nil, fmt.Errorf("%w: 物料 %s 的批次 %s 已于 %s 过期，不能出库", ErrBatchExpired, item.Code, batch.BatchNo, batch.ExpiryDate.Format("2006-01-02"))
+		}
+		if err := updateBatchStock(tx, batch, *movement.WarehouseID, delta); err != nil {
+			return nil, err
+		}
+	}
+
+	if item.TrackingMode != models.TrackingModeSerial {
+		return nil, nil
+	}
+	return updateSerialNumbers(tx, item, movement, delta)
+}
+
+// inferSerialBatch 序列号管理物料未指定批次时，按已登记序列号所属的批次确定移动批次，
+// 使批次库存与序列号保持一致；序列号分属不同批次时需按批次分别移动
+func inferSerialBatch(tx *gorm.DB, item *models.Item, movement *models.Movement) error {
+	serialNos := models.ParseSerialNos(movement.SerialNo)
+	if len(serialNos) == 0 {
+		return nil
+	}
+	var batchIDs []uint
+	if err := tx.Model(&models.SerialNumber{}).
+		Where("item_id = ? AND serial_no IN ? AND batch_id IS NOT NULL", item.ID, serialNos).
+		Distinct().Pluck("batch_id", &batchIDs).Error; err != nil {
+		return err
+	}
+	switch len(batchIDs) {
+	case 0:
+		return nil
+	case 1:
+		var batch models.Batch
+		if err := tx.First(&batch, batchIDs[0]).Error; err != nil {
+			return err
+		}
+		movement.BatchNo = batch.BatchNo
+		return nil
+	default:
+		return fmt.Errorf("物料 %s 的序列号分属不同批次，请按批次分别移动", item.Code)
+	}
+}
+
+// linkMovementSerials 记录库存移动涉及的序列号，并更新序列号的最后一次移动
+func linkMovementSerials(tx *gorm.DB, movement *models.Movement, serials []models.SerialNumber) error {
+	if len(serials) == 0 {
+		return nil
+	}
+	links := make([]models.MovementSerialNo, 0, len(serials))
+	ids := make([]uint, 0, len(serials))
+	for _, serial := range serials {
+		links = append(links, models.MovementSerialNo{MovementID: movement.ID, SerialNumberID: serial.ID})
+		ids = append(ids, serial.ID)
+	}
+	if err := tx.Create(&links).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.SerialNumber{}).Where("id IN ?", ids).Update("last_movement_id", movement.ID).Error
+}
+
+// isIssue 是否为发出库存的移动（出库、调拨调出），库存调整不在此列
+func isIssue(movementType string) bool {
+	return movementType == models.MovementTypeOut || movementType == models.MovementTypeTransferOut
+}
+
+// resolveBatch 获取移动对应的批次，入库时批次不存在则按移动的有效期创建，
+// 未填有效期且物料设置了保质期时按入库日期推算
+func resolveBatch(tx *gorm.DB, item *models.Item, movement *models.Movement, create bool) (*models.Batch, error) {
+	var batch models.Batch
+	err := tx.Where("item_id = ? AND batch_no = ?", item.ID, movement.BatchNo).First(&batch).Error
+	if err == nil {
+		return &batch, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if !create {
+		return nil, fmt.Errorf("物料 %s 的批次 %s 不存在", item.Code, movement.BatchNo)
+	}
+
+	expiryDate := movement.ExpiryDate
+	if expiryDate == nil && item.ShelfLifeDays > 0 {
+		expiry := models.StartOfDay(time.Now()).AddDate(0, 0, item.ShelfLifeDays)
+		expiryDate = &expiry
+	}
+	batch = models.Batch{
+		ItemID:     item.ID,
+		BatchNo:    movement.BatchNo,
+		ExpiryDate: expiryDate,
+		IsActive:   true,
+	}
+	if err := tx.Create(&batch).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// updateBatchStock 更新批次在仓库的库存，出库数量超过批次库存时返回库存不足
+func updateBatchStock(tx *gorm.DB, batch *models.Batch, warehouseID uint, delta float64) error {
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "item_id"}, {Name: "warehouse_id"}, {Name: "batch_id"}},
+		DoNothing: true,
+	}).Create(&models.BatchStock{ItemID: batch.ItemID, WarehouseID: warehouseID, BatchID: batch.ID}).Error; err != nil {
+		return err
+	}
+
+	query := tx.Model(&models.BatchStock{}).
+		Where("item_id = ? AND warehouse_id = ? AND batch_id = ?", batch.ItemID, warehouseID, batch.ID)
+	if delta < 0 {
+		query = query.Where("quantity >= ?", -delta-quantityEpsilon)
+	}
+	result := query.Update("quantity", gorm.Expr("quantity + ?", delta))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var current models.BatchStock
+		if err := tx.Where("item_id = ? AND warehouse_id = ? AND batch_id = ?", batch.ItemID, warehouseID, batch.ID).
+			First(&current).Error; err != nil {
+			return err
+		}
+		return fmt.Errorf("%w，批次 %s 当前库存: %.2f，需要: %.2f", ErrInsufficientStock, batch.BatchNo, current.Quantity, -delta)
+	}
+	return nil
+}
+
+// updateSerialNumbers 校验序列号数量与移动数量一致：入库的序列号不能已在库，
+// 出库的序列号必须在本仓库在库；随后更新序列号的所在仓库与状态
+func updateSerialNumbers(tx *gorm.DB, item *models.Item, movement *models.Movement, delta float64) ([]models.SerialNumber, error) {
+	serialNos := models.ParseSerialNos(movement.SerialNo)
+	if float64(len(serialNos)) != math.Abs(delta) {
+		return nil, fmt.Errorf("物料 %s 按序列号管理，序列号数量 %d 与移动数量 %.2f 不一致", item.Code, len(serialNos), math.Abs(delta))
+	}
+	movement.SerialNo = strings.Join(serialNos, ",")
+	warehouseID := *movement.WarehouseID
+
+	var existing []models.SerialNumber
+	if err := tx.Where("item_id = ? AND serial_no IN ?", item.ID, serialNos).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	bySerial := make(map[string]models.SerialNumber, len(existing))
+	for _, serial := range existing {
+		bySerial[serial.SerialNo] = serial
+	}
+
+	serials := make([]models.SerialNumber, 0, len(serialNos))
+	for _, serialNo := range serialNos {
+		serial, found := bySerial[serialNo]
+		if delta > 0 {
+			if found && serial.Status == models.SerialStatusInStock {
+				return nil, fmt.Errorf("序列号 %s 已在库，不能重复入库", serialNo)
+			}
+			serial.ItemID = item.ID
+			serial.SerialNo = serialNo
+			serial.WarehouseID = &warehouseID
+			serial.Status = models.SerialStatusInStock
+			if movement.BatchID != nil {
+				serial.BatchID = movement.BatchID
+			}
+			if serial.ExpiryDate == nil {
+				serial.ExpiryDate = movement.ExpiryDate
+			}
+		} else {
+			if !found {
+				return nil, fmt.Errorf("序列号 %s 不存在", serialNo)
+			}
+			if serial.Status != models.SerialStatusInStock || serial.WarehouseID == nil || *serial.WarehouseID != warehouseID {
+				return nil, fmt.Errorf("序列号 %s 不在仓库 %d 中", serialNo, warehouseID)
+			}
+			if movement.BatchID != nil && (serial.BatchID == nil || *serial.BatchID != *movement.BatchID) {
+				return nil, fmt.Errorf("序列号 %s 不属于批次 %s", serialNo, movement.BatchNo)
+			}
+			serial.WarehouseID = nil
+			serial.Status = models.SerialStatusIssued
+		}
+
+		if err := tx.Omit(clause.Associations).Save(&serial).Error; err != nil {
+			return nil, err
+		}
+		serials = append(serials, serial)
+	}
+	return serials, nil
+}
@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TransitWarehouseCode 在途虚拟仓编码
+const TransitWarehouseCode = "IN-TRANSIT"
+
+// StockTransferFilter 调拨单列表筛选条件
+type StockTransferFilter struct {
+	Status          string
+	FromWarehouseID uint
+	ToWarehouseID   uint
+}
+
+// StockTransferRepository 库存调拨仓储接口
+type StockTransferRepository interface {
+	BaseRepository[models.StockTransfer]
+	GetWithItems(ctx context.Context, id uint) (*models.StockTransfer, error)
+	ListTransfers(ctx context.Context, filter StockTransferFilter, offset, limit int) ([]*models.StockTransfer, int64, error)
+	GenerateTransferNumber(ctx context.Context) (string, error)
+	GetLocation(ctx context.Context, id uint) (*models.Location, error)
+	GetTransitWarehouse(ctx context.Context) (*models.Warehouse, error)
+	Transition(ctx context.Context, id uint, fn func(transfer *models.StockTransfer) ([]*models.Movement, error)) (*models.StockTransfer, error)
+}
+
+// StockTransferRepositoryImpl 库存调拨仓储实现
+type StockTransferRepositoryImpl struct {
+	BaseRepository[models.StockTransfer]
+	db *gorm.DB
+}
+
+// NewStockTransferRepository 创建库存调拨仓储实例
+func NewStockTransferRepository(db *gorm.DB) StockTransferRepository {
+	return &StockTransferRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.StockTransfer](db),
+		db:             db,
+	}
+}
+
+// GetWithItems 获取调拨单及明细
+func (r *StockTransferRepositoryImpl) GetWithItems(ctx context.Context, id uint) (*models.StockTransfer, error) {
+	var transfer models.StockTransfer
+	err := r.db.WithContext(ctx).
+		Preload("FromWarehouse").
+		Preload("ToWarehouse").
+		Preload("TransitWarehouse").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Item").
+		First(&transfer, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// ListTransfers 分页获取调拨单
+func (r *StockTransferRepositoryImpl) ListTransfers(ctx context.Context, filter StockTransferFilter, offset, limit int) ([]*models.StockTransfer, int64, error) {
+	var transfers []*models.StockTransfer
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.StockTransfer{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.FromWarehouseID != 0 {
+		query = query.Where("from_warehouse_id = ?", filter.FromWarehouseID)
+	}
+	if filter.ToWarehouseID != 0 {
+		query = query.Where("to_warehouse_id = ?", filter.ToWarehouseID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("FromWarehouse").
+		Preload("ToWarehouse").
+		Preload("Items").
+		Order("transfer_date DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&transfers).Error
+	return transfers, total, err
+}
+
+// GenerateTransferNumber 生成调拨单号
+func (r *StockTransferRepositoryImpl) GenerateTransferNumber(ctx context.Context) (string, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Unscoped().Model(&models.StockTransfer{}).Count(&count).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ST-%s-%06d", time.Now().Format("20060102"), count+1), nil
+}
+
+// GetLocation 获取库位
+func (r *StockTransferRepositoryImpl) GetLocation(ctx context.Context, id uint) (*models.Location, error) {
+	var location models.Location
+	if err := r.db.WithContext(ctx).First(&location, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &location, nil
+}
+
+// GetTransitWarehouse 获取在途虚拟仓，不存在时创建
+func (r *StockTransferRepositoryImpl) GetTransitWarehouse(ctx context.Context) (*models.Warehouse, error) {
+	warehouse := models.Warehouse{
+		Code:        TransitWarehouseCode,
+		Name:        "在途库存",
+		Description: "库存调拨已发出未收货的虚拟仓库",
+		IsActive:    true,
+		IsTransit:   true,
+	}
+	err := r.db.WithContext(ctx).
+		Where(models.Warehouse{Code: TransitWarehouseCode}).
+		Attrs(warehouse).
+		FirstOrCreate(&warehouse).Error
+	if err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+// Transition 在一个事务中锁定调拨单并重新加载明细，由 fn 修改单据状态并返回需过账的库存移动；
+// 移动过账与单据保存同时提交，任一库存不足时整体回滚
+func (r *StockTransferRepositoryImpl) Transition(ctx context.Context, id uint, fn func(transfer *models.StockTransfer) ([]*models.Movement, error)) (*models.StockTransfer, error) {
+	var transfer models.StockTransfer
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transfer, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("调拨单不存在")
+			}
+			return err
+		}
+		if err := tx.Where("transfer_id = ?", transfer.ID).Order("id").Find(&transfer.Items).Error; err != nil {
+			return err
+		}
+
+		movements, err := fn(&transfer)
+		if err != nil {
+			return err
+		}
+		if err := postMovements(tx, movements); err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Save(&transfer).Error; err != nil {
+			return err
+		}
+		for i := range transfer.Items {
+			if err := tx.Omit(clause.Associations).Save(&transfer.Items[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// StockValuationFilter 库存估值筛选条件，ID 为 0、字符串为空表示不筛选；
+// Before 为零值时汇总全部台账，否则只汇总该时间之前的移动
+type StockValuationFilter struct {
+	ItemID      uint
+	WarehouseID uint
+	Category    string
+	Before      time.Time
+}
+
+// StockValuationRow 按物料、仓库汇总的台账数量与金额
+type StockValuationRow struct {
+	ItemID          uint
+	ItemCode        string
+	ItemName        string
+	Category        string
+	ValuationMethod string
+	WarehouseID     uint
+	WarehouseCode   string
+	WarehouseName   string
+	Quantity        float64
+	StockValue      models.Money
+}
+
+// DeliveryCostFilter 发货成本筛选条件
+type DeliveryCostFilter struct {
+	DeliveryNoteID uint
+	StartDate      time.Time
+	EndDate        time.Time
+}
+
+// DeliveryCostRow 按送货单、物料、仓库汇总的发货成本，退货冲减数量与成本
+type DeliveryCostRow struct {
+	DeliveryNoteID uint
+	DeliveryNumber string
+	ItemID         uint
+	ItemCode       string
+	ItemName       string
+	WarehouseID    uint
+	Quantity       float64
+	TotalCost      models.Money
+}
+
+// StockValuationRepository 库存估值仓储接口，数据全部来自库存台账
+type StockValuationRepository interface {
+	GetValuationRows(ctx context.Context, filter StockValuationFilter) ([]StockValuationRow, error)
+	GetDeliveryCosts(ctx context.Context, filter DeliveryCostFilter) ([]DeliveryCostRow, error)
+}
+
+// StockValuationRepositoryImpl 库存估值仓储实现
+type StockValuationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewStockValuationRepository 创建库存估值仓储实例
+func NewStockValuationRepository(db *gorm.DB) StockValuationRepository {
+	return &StockValuationRepositoryImpl{db: db}
+}
+
+// GetValuationRows 按物料、仓库汇总台账的数量变动与金额变动，得到指定时点的库存数量与金额
+func (r *StockValuationRepositoryImpl) GetValuationRows(ctx context.Context, filter StockValuationFilter) ([]StockValuationRow, error) {
+	query := r.db.WithContext(ctx).
+		Table("movements AS m").
+		Select(`m.item_id AS item_id,
+			MAX(i.code) AS item_code,
+			MAX(i.name) AS item_name,
+			MAX(i.category) AS category,
+			MAX(i.valuation_method) AS valuation_method,
+			m.warehouse_id AS warehouse_id,
+			MAX(w.code) AS warehouse_code,
+			MAX(w.name) AS warehouse_name,
+			COALESCE(SUM(m.quantity_change), 0) AS quantity,
+			COALESCE(SUM(m.value_change), 0) AS stock_value`).
+		Joins("JOIN items AS i ON i.id = m.item_id").
+		Joins("JOIN warehouses AS w ON w.id = m.warehouse_id").
+		Where("m.deleted_at IS NULL")
+	if filter.ItemID != 0 {
+		query = query.Where("m.item_id = ?", filter.ItemID)
+	}
+	if filter.WarehouseID != 0 {
+		query = query.Where("m.warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.Category != "" {
+		query = query.Where("i.category = ?", filter.Category)
+	}
+	if !filter.Before.IsZero() {
+		query = query.Where("m.created_at < ?", filter.Before)
+	}
+
+	var rows []StockValuationRow
+	err := query.Group("m.item_id, m.warehouse_id").
+		Having("SUM(m.quantity_change) <> 0 OR SUM(m.value_change) <> 0").
+		Order("warehouse_code, item_code").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetDeliveryCosts 汇总送货单结转的发货成本，来源为引用送货单的库存移动
+func (r *StockValuationRepositoryImpl) GetDeliveryCosts(ctx context.Context, filter DeliveryCostFilter) ([]DeliveryCostRow, error) {
+	query := r.db.WithContext(ctx).
+		Table("movements AS m").
+		Select(`m.reference_id AS delivery_note_id,
+			MAX(dn.delivery_number) AS delivery_number,
+			m.item_id AS item_id,
+			MAX(i.code) AS item_code,
+			MAX(i.name) AS item_name,
+			m.warehouse_id AS warehouse_id,
+			COALESCE(SUM(-m.quantity_change), 0) AS quantity,
+			COALESCE(SUM(-m.value_change), 0) AS total_cost`).
+		Joins("JOIN items AS i ON i.id = m.item_id").
+		Joins("LEFT JOIN delivery_notes AS dn ON dn.id = m.reference_id").
+		Where("m.deleted_at IS NULL").
+		Where("m.reference_type = ? AND m.reference_id IS NOT NULL", models.MovementReferenceDeliveryNote)
+	if filter.DeliveryNoteID != 0 {
+		query = query.Where("m.reference_id = ?", filter.DeliveryNoteID)
+	}
+	if !filter.StartDate.IsZero() {
+		query = query.Where("m.created_at >= ?", filter.StartDate)
+	}
+	if !filter.EndDate.IsZero() {
+		query = query.Where("m.created_at < ?", filter.EndDate)
+	}
+
+	var rows []DeliveryCostRow
+	err := query.Group("m.reference_id, m.item_id, m.warehouse_id").
+		Order("delivery_note_id, item_code").
+		Scan(&rows).Error
+	return rows, err
+}
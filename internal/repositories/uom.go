@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"gorm.io/gorm"
+)
+
+// UOMRepository 计量单位、单位类别与物料单位换算仓储接口
+type UOMRepository interface {
+	BaseRepository[models.UOM]
+	GetUOM(ctx context.Context, id uint) (*models.UOM, error)
+	GetByCode(ctx context.Context, code string) (*models.UOM, error)
+	ListUOMs(ctx context.Context, categoryID uint, activeOnly bool) ([]*models.UOM, error)
+	SaveUOM(ctx context.Context, uom *models.UOM) error
+	DeleteUOM(ctx context.Context, id uint) error
+	CountUOMUsage(ctx context.Context, uom *models.UOM) (int64, error)
+	CreateCategory(ctx context.Context, category *models.UOMCategory) error
+	GetCategory(ctx context.Context, id uint) (*models.UOMCategory, error)
+	GetCategoryByCode(ctx context.Context, code string) (*models.UOMCategory, error)
+	ListCategories(ctx context.Context) ([]*models.UOMCategory, error)
+	SaveCategory(ctx context.Context, category *models.UOMCategory) error
+	DeleteCategory(ctx context.Context, id uint) error
+	CountCategoryUOMs(ctx context.Context, categoryID uint) (int64, error)
+	GetItemConversion(ctx context.Context, itemID, uomID uint) (*models.ItemUOMConversion, error)
+	GetConversion(ctx context.Context, id uint) (*models.ItemUOMConversion, error)
+	ListItemConversions(ctx context.Context, itemID uint) ([]*models.ItemUOMConversion, error)
+	SaveConversion(ctx context.Context, conversion *models.ItemUOMConversion) error
+	DeleteConversion(ctx context.Context, id uint) error
+}
+
+// UOMRepositoryImpl 计量单位仓储实现
+type UOMRepositoryImpl struct {
+	BaseRepository[models.UOM]
+	db *gorm.DB
+}
+
+// NewUOMRepository 创建计量单位仓储实例
+func NewUOMRepository(db *gorm.DB) UOMRepository {
+	return &UOMRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.UOM](db),
+		db:             db,
+	}
+}
+
+// GetUOM 根据ID获取计量单位及其类别
+func (r *UOMRepositoryImpl) GetUOM(ctx context.Context, id uint) (*models.UOM, error) {
+	var uom models.UOM
+	if err := r.db.WithContext(ctx).Preload("Category").First(&uom, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &uom, nil
+}
+
+// GetByCode 根据编码获取计量单位
+func (r *UOMRepositoryImpl) GetByCode(ctx context.Context, code string) (*models.UOM, error) {
+	var uom models.UOM
+	if err := r.db.WithContext(ctx).Preload("Category").Where("code = ?", code).First(&uom).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &uom, nil
+}
+
+// ListUOMs 获取计量单位，categoryID 为 0 时返回全部类别
+func (r *UOMRepositoryImpl) ListUOMs(ctx context.Context, categoryID uint, activeOnly bool) ([]*models.UOM, error) {
+	query := r.db.WithContext(ctx).Preload("Category")
+	if categoryID != 0 {
+		query = query.Where("category_id = ?", categoryID)
+	}
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	var uoms []*models.UOM
+	err := query.Order("category_id, base_factor, code").Find(&uoms).Error
+	return uoms, err
+}
+
+// SaveUOM 保存计量单位
+func (r *UOMRepositoryImpl) SaveUOM(ctx context.Context, uom *models.UOM) error {
+	return r.db.WithContext(ctx).Omit("Category").Save(uom).Error
+}
+
+// DeleteUOM 删除计量单位
+func (r *UOMRepositoryImpl) DeleteUOM(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.UOM{}, id).Error
+}
+
+// CountUOMUsage 统计以该单位为库存单位的物料与引用该单位的物料换算数量
+func (r *UOMRepositoryImpl) CountUOMUsage(ctx context.Context, uom *models.UOM) (int64, error) {
+	var items, conversions int64
+	if err := r.db.WithContext(ctx).Model(&models.Item{}).Where("unit = ?", uom.Code).Count(&items).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.WithContext(ctx).Model(&models.ItemUOMConversion{}).Where("uom_id = ?", uom.ID).Count(&conversions).Error; err != nil {
+		return 0, err
+	}
+	return items + conversions, nil
+}
+
+// CreateCategory 创建单位类别
+func (r *UOMRepositoryImpl) CreateCategory(ctx context.Context, category *models.UOMCategory) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+// GetCategory 根据ID获取单位类别
+func (r *UOMRepositoryImpl) GetCategory(ctx context.Context, id uint) (*models.UOMCategory, error) {
+	var category models.UOMCategory
+	if err := r.db.WithContext(ctx).First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetCategoryByCode 根据编码获取单位类别
+func (r *UOMRepositoryImpl) GetCategoryByCode(ctx context.Context, code string) (*models.UOMCategory, error) {
+	var category models.UOMCategory
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// ListCategories 获取全部单位类别
+func (r *UOMRepositoryImpl) ListCategories(ctx context.Context) ([]*models.UOMCategory, error) {
+	var categories []*models.UOMCategory
+	err := r.db.WithContext(ctx).Order("code").Find(&categories).Error
+	return categories, err
+}
+
+// SaveCategory 保存单位类别
+func (r *UOMRepositoryImpl) SaveCategory(ctx context.Context, category *models.UOMCategory) error {
+	return r.db.WithContext(ctx).Omit("UOMs").Save(category).Error
+}
+
+// DeleteCategory 删除单位类别
+func (r *UOMRepositoryImpl) DeleteCategory(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.UOMCategory{}, id).Error
+}
+
+// CountCategoryUOMs 统计类别下的计量单位数量
+func (r *UOMRepositoryImpl) CountCategoryUOMs(ctx context.Context, categoryID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.UOM{}).Where("category_id = ?", categoryID).Count(&count).Error
+	return count, err
+}
+
+// GetItemConversion 获取物料指定单位的换算
+func (r *UOMRepositoryImpl) GetItemConversion(ctx context.Context, itemID, uomID uint) (*models.ItemUOMConversion, error) {
+	var conversion models.ItemUOMConversion
+	err := r.db.WithContext(ctx).Where("item_id = ? AND uom_id = ?", itemID, uomID).First(&conversion).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &conversion, nil
+}
+
+// GetConversion 根据ID获取物料单位换算
+func (r *UOMRepositoryImpl) GetConversion(ctx context.Context, id uint) (*models.ItemUOMConversion, error) {
+	var conversion models.ItemUOMConversion
+	if err := r.db.WithContext(ctx).Preload("UOM").First(&conversion, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &conversion, nil
+}
+
+// ListItemConversions 获取物料的全部单位换算
+func (r *UOMRepositoryImpl) ListItemConversions(ctx context.Context, itemID uint) ([]*models.ItemUOMConversion, error) {
+	var conversions []*models.ItemUOMConversion
+	err := r.db.WithContext(ctx).Preload("UOM").Where("item_id = ?", itemID).Order("factor").Find(&conversions).Error
+	return conversions, err
+}
+
+// SaveConversion 保存物料单位换算
+func (r *UOMRepositoryImpl) SaveConversion(ctx context.Context, conversion *models.ItemUOMConversion) error {
+	return r.db.WithContext(ctx).Omit("Item", "UOM").Save(conversion).Error
+}
+
+// DeleteConversion 删除物料单位换算
+func (r *UOMRepositoryImpl) DeleteConversion(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ItemUOMConversion{}, id).Error
+}
@@ -8,12 +8,18 @@ import (
 // RegisterAccountingRoutes 注册会计相关路由
 func RegisterAccountingRoutes(router *gin.RouterGroup, container *container.Container) {
 	accountingController := container.AccountingController
+	intercompanyController := container.IntercompanyController
+	paymentEntryController := container.PaymentEntryController
 
 	// 会计科目管理
 	accounts := router.Group("/accounts")
 	{
 		accounts.POST("/", accountingController.CreateAccount)
 		accounts.GET("/", accountingController.GetAccountList)
+		accounts.GET("/templates", accountingController.GetChartTemplates)
+		accounts.POST("/templates/apply", accountingController.ApplyChartTemplate)
+		accounts.POST("/import", accountingController.ImportAccounts)
+		accounts.GET("/export", accountingController.ExportAccounts)
 		accounts.GET("/:id", accountingController.GetAccount)
 		accounts.PUT("/:id", accountingController.UpdateAccount)
 		accounts.DELETE("/:id", accountingController.DeleteAccount)
@@ -31,12 +37,34 @@ func RegisterAccountingRoutes(router *gin.RouterGroup, container *container.Cont
 		journalEntries.DELETE("/:id", accountingController.DeleteJournalEntry)
 	}
 
+	// 收付款管理
+	paymentEntries := router.Group("/payment-entries")
+	{
+		paymentEntries.POST("/", paymentEntryController.CreatePaymentEntry)
+		paymentEntries.GET("/", paymentEntryController.ListPaymentEntries)
+		paymentEntries.GET("/unallocated", paymentEntryController.ListUnallocatedPayments)
+		paymentEntries.GET("/:id", paymentEntryController.GetPaymentEntry)
+		paymentEntries.POST("/:id/allocations", paymentEntryController.AllocatePayment)
+		paymentEntries.POST("/:id/submit", paymentEntryController.SubmitPaymentEntry)
+		paymentEntries.POST("/:id/cancel", paymentEntryController.CancelPaymentEntry)
+	}
+
+	// 内部交易
+	intercompany := router.Group("/intercompany")
+	{
+		intercompany.POST("/sales-orders/:id/mirror", intercompanyController.MirrorSalesOrder)
+		intercompany.POST("/purchase-orders/:id/mirror", intercompanyController.MirrorPurchaseOrder)
+		intercompany.GET("/transactions", intercompanyController.ListTransactions)
+	}
+
 	// 科目类型
 	router.GET("/account-types", accountingController.GetAccountTypes)
 
 	// 财务报表
 	reports := router.Group("/reports")
 	{
+		reports.GET("/trial-balance", intercompanyController.GetTrialBalance)
+		reports.GET("/consolidated-trial-balance", intercompanyController.GetConsolidatedTrialBalance)
 		reports.GET("/balance-sheet", func(c *gin.Context) {
 			c.JSON(501, gin.H{"error": "功能暂未实现"})
 		})
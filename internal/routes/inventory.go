@@ -15,7 +15,54 @@ func RegisterInventoryRoutes(router *gin.RouterGroup, container *container.Conta
 		items.PUT("/:id", container.InventoryController.UpdateItem)
 		items.DELETE("/:id", container.InventoryController.DeleteItem)
 		items.GET("/", container.InventoryController.ListItems)
+		items.GET("/search", container.InventoryController.SearchItems)
 		items.POST("/search", container.InventoryController.SearchItems)
+		items.GET("/:id/uom-conversions", container.UOMController.ListItemConversions)
+		items.POST("/:id/uom-conversions", container.UOMController.SetItemConversion)
+		items.DELETE("/:id/uom-conversions/:conversion_id", container.UOMController.DeleteItemConversion)
+		items.PUT("/:id/template-attributes", container.ItemVariantController.SetTemplateAttributes)
+		items.GET("/:id/variants", container.ItemVariantController.GetVariants)
+		items.POST("/:id/variants", container.ItemVariantController.GenerateVariants)
+		items.GET("/:id/barcodes", container.BarcodeController.ListItemBarcodes)
+		items.POST("/:id/barcodes", container.BarcodeController.RegisterBarcode)
+		items.DELETE("/:id/barcodes/:barcode_id", container.BarcodeController.DeleteBarcode)
+	}
+
+	// 条码扫描与标签打印
+	barcodes := router.Group("/barcodes")
+	{
+		barcodes.POST("/scan", container.BarcodeController.Scan)
+		barcodes.POST("/labels", container.BarcodeController.GenerateLabels)
+	}
+
+	// 物料属性
+	itemAttributes := router.Group("/item-attributes")
+	{
+		itemAttributes.POST("/", container.ItemVariantController.CreateAttribute)
+		itemAttributes.GET("/", container.ItemVariantController.ListAttributes)
+		itemAttributes.GET("/:id", container.ItemVariantController.GetAttribute)
+		itemAttributes.PUT("/:id", container.ItemVariantController.UpdateAttribute)
+		itemAttributes.DELETE("/:id", container.ItemVariantController.DeleteAttribute)
+		itemAttributes.POST("/:id/values", container.ItemVariantController.AddAttributeValue)
+		itemAttributes.DELETE("/:id/values/:value_id", container.ItemVariantController.DeleteAttributeValue)
+	}
+
+	// 计量单位
+	uomCategories := router.Group("/uom-categories")
+	{
+		uomCategories.POST("/", container.UOMController.CreateCategory)
+		uomCategories.GET("/", container.UOMController.ListCategories)
+		uomCategories.PUT("/:id", container.UOMController.UpdateCategory)
+		uomCategories.DELETE("/:id", container.UOMController.DeleteCategory)
+	}
+	uoms := router.Group("/uoms")
+	{
+		uoms.POST("/", container.UOMController.CreateUOM)
+		uoms.GET("/", container.UOMController.ListUOMs)
+		uoms.GET("/convert", container.UOMController.Convert)
+		uoms.GET("/:id", container.UOMController.GetUOM)
+		uoms.PUT("/:id", container.UOMController.UpdateUOM)
+		uoms.DELETE("/:id", container.UOMController.DeleteUOM)
 	}
 
 	// 库存管理
@@ -36,7 +83,45 @@ func RegisterInventoryRoutes(router *gin.RouterGroup, container *container.Conta
 		stockMovements.POST("/in", container.InventoryController.StockIn)
 		stockMovements.POST("/out", container.InventoryController.StockOut)
 		stockMovements.POST("/adjustment", container.InventoryController.StockAdjustment)
-		stockMovements.POST("/transfer", container.InventoryController.StockTransfer)
+		stockMovements.POST("/transfer", container.StockTransferController.CreateTransfer)
+	}
+
+	// 库存调拨
+	stockTransfers := router.Group("/stock-transfers")
+	{
+		stockTransfers.POST("/", container.StockTransferController.CreateTransfer)
+		stockTransfers.GET("/", container.StockTransferController.ListTransfers)
+		stockTransfers.GET("/:id", container.StockTransferController.GetTransfer)
+		stockTransfers.POST("/:id/ship", container.StockTransferController.ShipTransfer)
+		stockTransfers.POST("/:id/receive", container.StockTransferController.ReceiveTransfer)
+		stockTransfers.POST("/:id/cancel", container.StockTransferController.CancelTransfer)
+	}
+
+	// 库存估值
+	stockValuation := router.Group("/stock-valuation")
+	{
+		stockValuation.GET("/", container.StockValuationController.GetValuation)
+		stockValuation.GET("/report", container.StockValuationController.GetValuationReport)
+		stockValuation.GET("/cogs", container.StockValuationController.GetCostOfGoodsSold)
+	}
+
+	// 批次与序列号
+	batches := router.Group("/batches")
+	{
+		batches.POST("/", container.BatchController.CreateBatch)
+		batches.GET("/", container.BatchController.ListBatches)
+		batches.GET("/stock", container.BatchController.GetBatchStocks)
+		batches.GET("/expiring", container.BatchController.GetExpiryReport)
+		batches.GET("/:id", container.BatchController.GetBatch)
+		batches.PUT("/:id", container.BatchController.UpdateBatch)
+		batches.GET("/:id/stock", container.BatchController.GetBatchStock)
+		batches.GET("/:id/trace", container.BatchController.TraceBatch)
+	}
+
+	serialNumbers := router.Group("/serial-numbers")
+	{
+		serialNumbers.GET("/", container.BatchController.ListSerialNumbers)
+		serialNumbers.GET("/:id/trace", container.BatchController.TraceSerialNumber)
 	}
 
 	// 仓库管理
@@ -47,12 +132,62 @@ func RegisterInventoryRoutes(router *gin.RouterGroup, container *container.Conta
 		warehouses.GET("/:id", container.InventoryController.GetWarehouse)
 		warehouses.PUT("/:id", container.InventoryController.UpdateWarehouse)
 		warehouses.DELETE("/:id", container.InventoryController.DeleteWarehouse)
+		warehouses.POST("/:id/locations", container.LocationController.CreateLocation)
+		warehouses.GET("/:id/locations", container.LocationController.ListLocations)
+		warehouses.GET("/:id/locations/:location_id", container.LocationController.GetLocation)
+		warehouses.PUT("/:id/locations/:location_id", container.LocationController.UpdateLocation)
+		warehouses.DELETE("/:id/locations/:location_id", container.LocationController.DeleteLocation)
+		warehouses.GET("/:id/location-stock", container.LocationController.GetLocationStock)
+		warehouses.POST("/:id/putaway-suggestions", container.LocationController.SuggestPutaway)
+	}
+
+	// 上架规则
+	putawayRules := router.Group("/putaway-rules")
+	{
+		putawayRules.POST("/", container.LocationController.CreatePutawayRule)
+		putawayRules.GET("/", container.LocationController.ListPutawayRules)
+		putawayRules.PUT("/:id", container.LocationController.UpdatePutawayRule)
+		putawayRules.DELETE("/:id", container.LocationController.DeletePutawayRule)
+	}
+
+	// 库位间移库
+	locationMoves := router.Group("/location-moves")
+	{
+		locationMoves.POST("/", container.LocationController.MoveStock)
+		locationMoves.GET("/", container.LocationController.ListLocationMoves)
+		locationMoves.POST("/quarantine-expired", container.LocationController.QuarantineExpired)
+	}
+
+	// 库存预留
+	reservations := router.Group("/stock-reservations")
+	{
+		reservations.GET("/", container.ReservationController.ListReservations)
+		reservations.POST("/reallocate", container.ReservationController.Reallocate)
+	}
+
+	// 库存盘点
+	stockCounts := router.Group("/stock-counts")
+	{
+		stockCounts.POST("/", container.StockCountController.CreateStockCount)
+		stockCounts.GET("/", container.StockCountController.ListStockCounts)
+		stockCounts.GET("/cycle/preview", container.StockCountController.PreviewCycleCount)
+		stockCounts.POST("/cycle", container.StockCountController.GenerateCycleCounts)
+		stockCounts.GET("/:id", container.StockCountController.GetStockCount)
+		stockCounts.PUT("/:id/counts", container.StockCountController.EnterCounts)
+		stockCounts.POST("/:id/scans", container.StockCountController.ImportScans)
+		stockCounts.POST("/:id/submit", container.StockCountController.SubmitStockCount)
+		stockCounts.POST("/:id/approve", container.StockCountController.ApproveStockCount)
+		stockCounts.POST("/:id/reject", container.StockCountController.RejectStockCount)
+		stockCounts.POST("/:id/post", container.StockCountController.PostStockCount)
+		stockCounts.POST("/:id/cancel", container.StockCountController.CancelStockCount)
 	}
 
 	// 库存查询
 	stock := router.Group("/stock")
 	{
 		stock.GET("/item/:item_id", container.InventoryController.GetStockByItemID)
+		stock.GET("/reconcile", container.InventoryController.CheckStockReconciliation)
+		stock.POST("/reconcile", container.InventoryController.ReconcileStock)
 	}
 
 	// 库存报告和统计
@@ -61,6 +196,9 @@ func RegisterInventoryRoutes(router *gin.RouterGroup, container *container.Conta
 		reports.GET("/stats", container.InventoryController.GetInventoryStats)
 		reports.GET("/report", container.InventoryController.GetInventoryReport)
 		reports.GET("/abc-analysis", container.InventoryController.GetABCAnalysis)
+		reports.GET("/aging", container.InventoryController.GetInventoryAging)
+		reports.GET("/slow-moving", container.InventoryController.GetSlowMovingStock)
+		reports.GET("/write-down-proposal", container.InventoryController.GetWriteDownProposal)
 		reports.GET("/export", container.InventoryController.ExportInventoryReport)
 	}
 }
@@ -44,6 +44,23 @@ func RegisterPurchaseRoutes(router *gin.RouterGroup, container *container.Contai
 		requests.POST("/:id/reject", purchaseController.RejectPurchaseRequest)
 	}
 
+	// 到岸成本
+	landedCosts := router.Group("/landed-cost-vouchers")
+	{
+		landedCosts.POST("/", container.LandedCostController.CreateLandedCost)
+		landedCosts.GET("/", container.LandedCostController.ListLandedCosts)
+		landedCosts.GET("/:id", container.LandedCostController.GetLandedCost)
+		landedCosts.POST("/:id/submit", container.LandedCostController.SubmitLandedCost)
+		landedCosts.POST("/:id/cancel", container.LandedCostController.CancelLandedCost)
+	}
+
+	// 再订货点补货
+	replenishment := router.Group("/replenishment")
+	{
+		replenishment.GET("/preview", container.ReplenishmentController.PreviewReplenishment)
+		replenishment.POST("/run", container.ReplenishmentController.RunReplenishment)
+	}
+
 	// 采购统计
 	router.GET("/purchase/stats", purchaseController.GetPurchaseStats)
 }
@@ -16,6 +16,7 @@ func RegisterSalesRoutes(router *gin.RouterGroup, container *container.Container
 		customers.DELETE("/:id", container.SalesController.DeleteCustomer)
 		customers.GET("/", container.SalesController.ListCustomers)
 		customers.POST("/search", container.SalesController.SearchCustomers)
+		customers.GET("/:id/statement", container.DunningController.GetCustomerStatement)
 	}
 
 	// 销售订单管理
@@ -27,6 +28,8 @@ func RegisterSalesRoutes(router *gin.RouterGroup, container *container.Container
 		orders.DELETE("/:id", container.SalesController.DeleteSalesOrder)
 		orders.GET("/", container.SalesController.ListSalesOrders)
 		orders.PUT("/:id/status", container.SalesController.UpdateOrderStatus)
+		orders.GET("/:id/status-logs", container.SalesController.GetOrderStatusLogs)
+		orders.GET("/:id/reservations", container.ReservationController.GetSalesOrderReservations)
 	}
 
 	// 报价单管理
@@ -38,6 +41,7 @@ func RegisterSalesRoutes(router *gin.RouterGroup, container *container.Container
 		quotations.DELETE("/:id", container.SalesController.DeleteQuotation)
 		quotations.GET("/", container.SalesController.ListQuotations)
 		quotations.GET("/search", container.SalesController.SearchQuotations)
+		quotations.POST("/:id/convert-to-order", container.SalesController.ConvertQuotationToOrder)
 
 		// 报价单版本管理
 		quotations.POST("/:id/versions", container.SalesController.CreateQuotationVersion)
@@ -67,6 +71,8 @@ func RegisterSalesRoutes(router *gin.RouterGroup, container *container.Container
 	invoices := router.Group("/sales-invoices")
 	{
 		invoices.POST("/", container.SalesController.CreateSalesInvoice)
+		invoices.POST("/from-sales-order", container.SalesController.CreateSalesInvoiceFromSalesOrder)
+		invoices.POST("/from-delivery-note", container.SalesController.CreateSalesInvoiceFromDeliveryNote)
 		invoices.GET("/:id", container.SalesController.GetSalesInvoice)
 		invoices.PUT("/:id", container.SalesController.UpdateSalesInvoice)
 		invoices.DELETE("/:id", container.SalesController.DeleteSalesInvoice)
@@ -90,5 +96,36 @@ func RegisterSalesRoutes(router *gin.RouterGroup, container *container.Container
 		deliveryNotes.POST("/from-sales-order", container.DeliveryNoteController.CreateFromSalesOrder)
 		deliveryNotes.GET("/statistics", container.DeliveryNoteController.GetStatistics)
 		deliveryNotes.GET("/trend", container.DeliveryNoteController.GetDeliveryTrend)
+		deliveryNotes.GET("/:id/picking-list", container.LocationController.GetPickingList)
+	}
+
+	// 催款管理
+	dunning := router.Group("/dunning")
+	{
+		dunning.GET("/levels", container.DunningController.ListDunningLevels)
+		dunning.POST("/levels", container.DunningController.CreateDunningLevel)
+		dunning.PUT("/levels/:id", container.DunningController.UpdateDunningLevel)
+		dunning.DELETE("/levels/:id", container.DunningController.DeleteDunningLevel)
+		dunning.POST("/run", container.DunningController.RunDunning)
+		dunning.GET("/letters", container.DunningController.ListDunningLetters)
+		dunning.GET("/letters/:id", container.DunningController.GetDunningLetter)
+	}
+
+	// 价格表管理（销售与采购共用）
+	priceLists := router.Group("/price-lists")
+	{
+		priceLists.POST("/", container.PriceListController.CreatePriceList)
+		priceLists.GET("/", container.PriceListController.ListPriceLists)
+		priceLists.GET("/resolve", container.PriceListController.ResolvePrice)
+		priceLists.GET("/:id", container.PriceListController.GetPriceList)
+		priceLists.PUT("/:id", container.PriceListController.UpdatePriceList)
+		priceLists.DELETE("/:id", container.PriceListController.DeletePriceList)
+		priceLists.GET("/:id/prices", container.PriceListController.ListItemPrices)
+		priceLists.POST("/:id/prices", container.PriceListController.AddItemPrice)
+		priceLists.PUT("/:id/prices/:price_id", container.PriceListController.UpdateItemPrice)
+		priceLists.DELETE("/:id/prices/:price_id", container.PriceListController.DeleteItemPrice)
+		priceLists.GET("/:id/assignments", container.PriceListController.ListAssignments)
+		priceLists.POST("/:id/assignments", container.PriceListController.AssignPriceList)
+		priceLists.DELETE("/:id/assignments/:assignment_id", container.PriceListController.DeleteAssignment)
 	}
 }
@@ -81,11 +81,20 @@ func registerOrganizationRoutes(sys *gin.RouterGroup, container *container.Conta
 	// 公司管理
 	companies := sys.Group("/companies")
 	{
-		companies.POST("/", container.SystemController.CreateCompany)
-		companies.GET("/", container.SystemController.GetCompanies)
-		companies.GET("/:id", container.SystemController.GetCompany)
-		companies.PUT("/:id", container.SystemController.UpdateCompany)
-		companies.DELETE("/:id", container.SystemController.DeleteCompany)
+		companies.POST("/", container.CompanyController.CreateCompany)
+		companies.GET("/", container.CompanyController.GetCompanies)
+		companies.GET("/:id", container.CompanyController.GetCompany)
+		companies.PUT("/:id", container.CompanyController.UpdateCompany)
+		companies.DELETE("/:id", container.CompanyController.DeleteCompany)
+		companies.GET("/:id/fiscal-years", container.CompanyController.GetFiscalYears)
+		companies.POST("/:id/fiscal-years", container.CompanyController.CreateFiscalYear)
+	}
+
+	// 会计期间管理
+	periods := sys.Group("/accounting-periods")
+	{
+		periods.PUT("/:id/close", container.CompanyController.CloseAccountingPeriod)
+		periods.PUT("/:id/reopen", container.CompanyController.ReopenAccountingPeriod)
 	}
 
 	// 部门管理
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// accountCSVHeader 科目表 CSV 导入导出的列顺序
+var accountCSVHeader = []string{"code", "name", "type", "parent_code", "currency", "description"}
+
+// errAccountImportRollback 导入试运行或存在冲突时用于回滚事务
+var errAccountImportRollback = errors.New("科目导入已回滚")
+
+// ListChartTemplates 获取内置科目表模板
+func (s *AccountServiceImpl) ListChartTemplates() []dto.ChartTemplateResponse {
+	templates := make([]dto.ChartTemplateResponse, 0, len(chartTemplates))
+	for _, template := range chartTemplates {
+		templates = append(templates, dto.ChartTemplateResponse{
+			Code:         template.Code,
+			Name:         template.Name,
+			Description:  template.Description,
+			AccountCount: len(template.Accounts),
+		})
+	}
+	return templates
+}
+
+// ApplyChartTemplate 将内置科目表模板导入到公司
+func (s *AccountServiceImpl) ApplyChartTemplate(ctx context.Context, req *dto.ChartTemplateApplyRequest) (*dto.AccountImportResult, error) {
+	template, ok := findChartTemplate(req.Template)
+	if !ok {
+		return nil, fmt.Errorf("科目表模板 %s 不存在", req.Template)
+	}
+
+	rows := make([]dto.AccountImportRow, len(template.Accounts))
+	for i, row := range template.Accounts {
+		if row.Currency == "" {
+			row.Currency = template.Currency
+		}
+		rows[i] = row
+	}
+
+	return s.importAccountRows(ctx, req.CompanyID, rows, req.DryRun, req.SkipExisting)
+}
+
+// ImportAccounts 从 CSV 或 JSON 数据导入科目，父科目编码解析为 ParentID
+func (s *AccountServiceImpl) ImportAccounts(ctx context.Context, req *dto.AccountImportRequest, data []byte) (*dto.AccountImportResult, error) {
+	rows, err := parseAccountRows(req.Format, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("导入文件中没有科目")
+	}
+
+	return s.importAccountRows(ctx, req.CompanyID, rows, req.DryRun, req.SkipExisting)
+}
+
+// ExportAccounts 按导入格式导出公司科目表
+func (s *AccountServiceImpl) ExportAccounts(ctx context.Context, req *dto.AccountExportRequest) ([]byte, error) {
+	companyID, err := resolveCompanyID(ctx, s.companyRepo, req.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.accountRepo.GetChartOfAccounts(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("获取科目表失败: %w", err)
+	}
+
+	rows := make([]dto.AccountImportRow, 0, len(accounts))
+	for _, account := range accounts {
+		row := dto.AccountImportRow{
+			Code:        account.Code,
+			Name:        account.Name,
+			Type:        account.AccountType,
+			Currency:    account.Currency,
+			Description: account.Description,
+		}
+		if account.Parent != nil {
+			row.ParentCode = account.Parent.Code
+		}
+		rows = append(rows, row)
+	}
+
+	if req.Format == "json" {
+		return json.MarshalIndent(rows, "", "  ")
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(accountCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if err := writer.Write([]string{row.Code, row.Name, row.Type, row.ParentCode, row.Currency, row.Description}); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// importAccountRows 在一个事务中按父子顺序创建科目，每个科目经 ValidateAccountHierarchy 校验；
+// 试运行或存在冲突时回滚，仅返回检查结果
+func (s *AccountServiceImpl) importAccountRows(ctx context.Context, companyID uint, rows []dto.AccountImportRow, dryRun, skipExisting bool) (*dto.AccountImportResult, error) {
+	companyID, err := resolveCompanyID(ctx, s.companyRepo, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.AccountImportResult{
+		CompanyID: companyID,
+		DryRun:    dryRun,
+		Total:     len(rows),
+		Conflicts: []dto.AccountImportConflict{},
+	}
+	conflict := func(index int, code, message string) {
+		result.Conflicts = append(result.Conflicts, dto.AccountImportConflict{Row: index + 1, Code: code, Message: message})
+	}
+
+	// 基础字段校验与文件内编码重复检查
+	rowIndex := make(map[string]int, len(rows))
+	pending := make([]int, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		row.Code = strings.TrimSpace(row.Code)
+		row.Name = strings.TrimSpace(row.Name)
+		row.Type = strings.ToLower(strings.TrimSpace(row.Type))
+		row.ParentCode = strings.TrimSpace(row.ParentCode)
+
+		if errs := utils.ValidateStruct(row); len(errs) > 0 {
+			conflict(i, row.Code, joinValidationErrors(errs))
+			continue
+		}
+		if _, exists := rowIndex[row.Code]; exists {
+			conflict(i, row.Code, "科目编码在导入文件中重复")
+			continue
+		}
+		if row.ParentCode == row.Code {
+			conflict(i, row.Code, "科目不能以自己作为父科目")
+			continue
+		}
+		rowIndex[row.Code] = i
+		pending = append(pending, i)
+	}
+
+	err = s.accountRepo.Transaction(ctx, func(repo repositories.AccountRepository) error {
+		txService := &AccountServiceImpl{accountRepo: repo, companyRepo: s.companyRepo}
+		accountIDs := make(map[string]uint)
+		failed := make(map[string]bool)
+
+		// 父科目先于子科目创建；一轮无进展时剩余行存在循环引用
+		for len(pending) > 0 {
+			var deferred []int
+			for _, i := range pending {
+				row := rows[i]
+
+				var parentID *uint
+				if row.ParentCode != "" {
+					if id, ok := accountIDs[row.ParentCode]; ok {
+						parentID = &id
+					} else if failed[row.ParentCode] {
+						failed[row.Code] = true
+						conflict(i, row.Code, fmt.Sprintf("父科目 %s 导入失败", row.ParentCode))
+						continue
+					} else if _, inFile := rowIndex[row.ParentCode]; inFile {
+						deferred = append(deferred, i)
+						continue
+					} else {
+						parent, err := repo.GetByCode(ctx, companyID, row.ParentCode)
+						if err != nil {
+							return err
+						}
+						if parent == nil {
+							failed[row.Code] = true
+							conflict(i, row.Code, fmt.Sprintf("父科目 %s 不存在", row.ParentCode))
+							continue
+						}
+						accountIDs[parent.Code] = parent.ID
+						parentID = &parent.ID
+					}
+				}
+
+				existing, err := repo.GetByCode(ctx, companyID, row.Code)
+				if err != nil {
+					return err
+				}
+				if existing != nil {
+					if skipExisting {
+						accountIDs[row.Code] = existing.ID
+						result.Skipped++
+					} else {
+						failed[row.Code] = true
+						conflict(i, row.Code, "科目编码已存在")
+					}
+					continue
+				}
+
+				account := &models.Account{
+					CompanyID:   companyID,
+					Code:        row.Code,
+					Name:        row.Name,
+					Description: row.Description,
+					AccountType: row.Type,
+					ParentID:    parentID,
+					Currency:    row.Currency,
+					IsActive:    true,
+				}
+				if account.Currency == "" {
+					account.Currency = models.DefaultCurrency
+				}
+				if err := txService.ValidateAccountHierarchy(ctx, account); err != nil {
+					failed[row.Code] = true
+					conflict(i, row.Code, err.Error())
+					continue
+				}
+				if err := repo.Create(ctx, account); err != nil {
+					return fmt.Errorf("创建科目 %s 失败: %w", row.Code, err)
+				}
+				accountIDs[row.Code] = account.ID
+				result.Created++
+			}
+
+			if len(deferred) == len(pending) {
+				for _, i := range deferred {
+					conflict(i, rows[i].Code, "父子科目存在循环引用")
+				}
+				break
+			}
+			pending = deferred
+		}
+
+		if dryRun || len(result.Conflicts) > 0 {
+			return errAccountImportRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAccountImportRollback) {
+		return nil, fmt.Errorf("导入科目失败: %w", err)
+	}
+
+	sort.SliceStable(result.Conflicts, func(i, j int) bool {
+		return result.Conflicts[i].Row < result.Conflicts[j].Row
+	})
+	result.Imported = !dryRun && len(result.Conflicts) == 0
+	if !result.Imported && !dryRun {
+		result.Created = 0
+	}
+	return result, nil
+}
+
+// parseAccountRows 解析 CSV 或 JSON 格式的科目数据
+func parseAccountRows(format string, data []byte) ([]dto.AccountImportRow, error) {
+	if format == "json" {
+		var rows []dto.AccountImportRow
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("解析 JSON 失败: %w", err)
+		}
+		return rows, nil
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取 CSV 表头失败: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"code", "name", "type"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV 缺少 %s 列", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var rows []dto.AccountImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 CSV 失败: %w", err)
+		}
+		rows = append(rows, dto.AccountImportRow{
+			Code:        field(record, "code"),
+			Name:        field(record, "name"),
+			Type:        field(record, "type"),
+			ParentCode:  field(record, "parent_code"),
+			Currency:    field(record, "currency"),
+			Description: field(record, "description"),
+		})
+	}
+	return rows, nil
+}
+
+// joinValidationErrors 将字段校验错误拼接为一条消息
+func joinValidationErrors(errs map[string]string) string {
+	messages := make([]string, 0, len(errs))
+	for _, message := range errs {
+		messages = append(messages, message)
+	}
+	sort.Strings(messages)
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,168 @@
+package services
+
+import "github.com/galaxyerp/galaxyErp/internal/dto"
+
+// chartTemplate 内置科目表模板
+type chartTemplate struct {
+	Code        string
+	Name        string
+	Description string
+	Currency    string
+	Accounts    []dto.AccountImportRow
+}
+
+// chartTemplates 内置科目表模板，按模板编码索引
+var chartTemplates = []chartTemplate{
+	{
+		Code:        "cn-small-business",
+		Name:        "小企业会计准则",
+		Description: "适用于中国境内小企业的一级科目及常用明细科目（成本类科目按资产类处理）",
+		Currency:    "CNY",
+		Accounts: []dto.AccountImportRow{
+			{Code: "1001", Name: "库存现金", Type: "asset"},
+			{Code: "1002", Name: "银行存款", Type: "asset"},
+			{Code: "1012", Name: "其他货币资金", Type: "asset"},
+			{Code: "1101", Name: "短期投资", Type: "asset"},
+			{Code: "1121", Name: "应收票据", Type: "asset"},
+			{Code: "1122", Name: "应收账款", Type: "asset"},
+			{Code: "1123", Name: "预付账款", Type: "asset"},
+			{Code: "1131", Name: "应收股利", Type: "asset"},
+			{Code: "1132", Name: "应收利息", Type: "asset"},
+			{Code: "1221", Name: "其他应收款", Type: "asset"},
+			{Code: "1401", Name: "材料采购", Type: "asset"},
+			{Code: "1402", Name: "在途物资", Type: "asset"},
+			{Code: "1403", Name: "原材料", Type: "asset"},
+			{Code: "1404", Name: "材料成本差异", Type: "asset"},
+			{Code: "1405", Name: "库存商品", Type: "asset"},
+			{Code: "1407", Name: "商品进销差价", Type: "asset"},
+			{Code: "1408", Name: "委托加工物资", Type: "asset"},
+			{Code: "1411", Name: "周转材料", Type: "asset"},
+			{Code: "1501", Name: "长期债券投资", Type: "asset"},
+			{Code: "1511", Name: "长期股权投资", Type: "asset"},
+			{Code: "1601", Name: "固定资产", Type: "asset"},
+			{Code: "1602", Name: "累计折旧", Type: "asset"},
+			{Code: "1604", Name: "在建工程", Type: "asset"},
+			{Code: "1605", Name: "工程物资", Type: "asset"},
+			{Code: "1606", Name: "固定资产清理", Type: "asset"},
+			{Code: "1701", Name: "无形资产", Type: "asset"},
+			{Code: "1702", Name: "累计摊销", Type: "asset"},
+			{Code: "1801", Name: "长期待摊费用", Type: "asset"},
+			{Code: "1901", Name: "待处理财产损溢", Type: "asset"},
+			{Code: "2001", Name: "短期借款", Type: "liability"},
+			{Code: "2201", Name: "应付票据", Type: "liability"},
+			{Code: "2202", Name: "应付账款", Type: "liability"},
+			{Code: "2203", Name: "预收账款", Type: "liability"},
+			{Code: "2211", Name: "应付职工薪酬", Type: "liability"},
+			{Code: "2211.01", Name: "工资", Type: "liability", ParentCode: "2211"},
+			{Code: "2211.02", Name: "社会保险费", Type: "liability", ParentCode: "2211"},
+			{Code: "2211.03", Name: "住房公积金", Type: "liability", ParentCode: "2211"},
+			{Code: "2221", Name: "应交税费", Type: "liability"},
+			{Code: "2221.01", Name: "应交增值税", Type: "liability", ParentCode: "2221"},
+			{Code: "2221.02", Name: "未交增值税", Type: "liability", ParentCode: "2221"},
+			{Code: "2221.03", Name: "应交企业所得税", Type: "liability", ParentCode: "2221"},
+			{Code: "2221.04", Name: "应交个人所得税", Type: "liability", ParentCode: "2221"},
+			{Code: "2221.05", Name: "应交城市维护建设税", Type: "liability", ParentCode: "2221"},
+			{Code: "2221.06", Name: "应交教育费附加", Type: "liability", ParentCode: "2221"},
+			{Code: "2231", Name: "应付利息", Type: "liability"},
+			{Code: "2232", Name: "应付利润", Type: "liability"},
+			{Code: "2241", Name: "其他应付款", Type: "liability"},
+			{Code: "2401", Name: "递延收益", Type: "liability"},
+			{Code: "2501", Name: "长期借款", Type: "liability"},
+			{Code: "2701", Name: "长期应付款", Type: "liability"},
+			{Code: "3001", Name: "实收资本", Type: "equity"},
+			{Code: "3002", Name: "资本公积", Type: "equity"},
+			{Code: "3101", Name: "盈余公积", Type: "equity"},
+			{Code: "3103", Name: "本年利润", Type: "equity"},
+			{Code: "3104", Name: "利润分配", Type: "equity"},
+			{Code: "4001", Name: "生产成本", Type: "asset"},
+			{Code: "4101", Name: "制造费用", Type: "asset"},
+			{Code: "4301", Name: "研发支出", Type: "asset"},
+			{Code: "5001", Name: "主营业务收入", Type: "revenue"},
+			{Code: "5051", Name: "其他业务收入", Type: "revenue"},
+			{Code: "5111", Name: "投资收益", Type: "revenue"},
+			{Code: "5301", Name: "营业外收入", Type: "revenue"},
+			{Code: "5401", Name: "主营业务成本", Type: "expense"},
+			{Code: "5402", Name: "其他业务成本", Type: "expense"},
+			{Code: "5403", Name: "税金及附加", Type: "expense"},
+			{Code: "5601", Name: "销售费用", Type: "expense"},
+			{Code: "5602", Name: "管理费用", Type: "expense"},
+			{Code: "5602.01", Name: "办公费", Type: "expense", ParentCode: "5602"},
+			{Code: "5602.02", Name: "差旅费", Type: "expense", ParentCode: "5602"},
+			{Code: "5602.03", Name: "业务招待费", Type: "expense", ParentCode: "5602"},
+			{Code: "5602.04", Name: "折旧费", Type: "expense", ParentCode: "5602"},
+			{Code: "5603", Name: "财务费用", Type: "expense"},
+			{Code: "5603.01", Name: "利息费用", Type: "expense", ParentCode: "5603"},
+			{Code: "5603.02", Name: "手续费", Type: "expense", ParentCode: "5603"},
+			{Code: "5711", Name: "营业外支出", Type: "expense"},
+			{Code: "5801", Name: "所得税费用", Type: "expense"},
+		},
+	},
+	{
+		Code:        "us-gaap",
+		Name:        "US GAAP Generic",
+		Description: "按美国通用会计准则编排的通用科目表，适用于一般商贸与服务企业",
+		Currency:    "USD",
+		Accounts: []dto.AccountImportRow{
+			{Code: "1000", Name: "Current Assets", Type: "asset"},
+			{Code: "1010", Name: "Cash", Type: "asset", ParentCode: "1000"},
+			{Code: "1020", Name: "Petty Cash", Type: "asset", ParentCode: "1000"},
+			{Code: "1100", Name: "Accounts Receivable", Type: "asset", ParentCode: "1000"},
+			{Code: "1150", Name: "Allowance for Doubtful Accounts", Type: "asset", ParentCode: "1000"},
+			{Code: "1200", Name: "Inventory", Type: "asset", ParentCode: "1000"},
+			{Code: "1300", Name: "Prepaid Expenses", Type: "asset", ParentCode: "1000"},
+			{Code: "1500", Name: "Property, Plant and Equipment", Type: "asset"},
+			{Code: "1510", Name: "Land", Type: "asset", ParentCode: "1500"},
+			{Code: "1520", Name: "Buildings", Type: "asset", ParentCode: "1500"},
+			{Code: "1530", Name: "Equipment", Type: "asset", ParentCode: "1500"},
+			{Code: "1540", Name: "Vehicles", Type: "asset", ParentCode: "1500"},
+			{Code: "1590", Name: "Accumulated Depreciation", Type: "asset", ParentCode: "1500"},
+			{Code: "1700", Name: "Intangible Assets", Type: "asset"},
+			{Code: "1710", Name: "Goodwill", Type: "asset", ParentCode: "1700"},
+			{Code: "1790", Name: "Accumulated Amortization", Type: "asset", ParentCode: "1700"},
+			{Code: "2000", Name: "Current Liabilities", Type: "liability"},
+			{Code: "2010", Name: "Accounts Payable", Type: "liability", ParentCode: "2000"},
+			{Code: "2100", Name: "Accrued Liabilities", Type: "liability", ParentCode: "2000"},
+			{Code: "2200", Name: "Sales Tax Payable", Type: "liability", ParentCode: "2000"},
+			{Code: "2300", Name: "Payroll Liabilities", Type: "liability", ParentCode: "2000"},
+			{Code: "2400", Name: "Unearned Revenue", Type: "liability", ParentCode: "2000"},
+			{Code: "2500", Name: "Long-term Liabilities", Type: "liability"},
+			{Code: "2510", Name: "Notes Payable", Type: "liability", ParentCode: "2500"},
+			{Code: "2520", Name: "Bonds Payable", Type: "liability", ParentCode: "2500"},
+			{Code: "3000", Name: "Equity", Type: "equity"},
+			{Code: "3100", Name: "Common Stock", Type: "equity", ParentCode: "3000"},
+			{Code: "3200", Name: "Additional Paid-in Capital", Type: "equity", ParentCode: "3000"},
+			{Code: "3300", Name: "Retained Earnings", Type: "equity", ParentCode: "3000"},
+			{Code: "3400", Name: "Dividends", Type: "equity", ParentCode: "3000"},
+			{Code: "4000", Name: "Revenue", Type: "revenue"},
+			{Code: "4010", Name: "Sales Revenue", Type: "revenue", ParentCode: "4000"},
+			{Code: "4020", Name: "Service Revenue", Type: "revenue", ParentCode: "4000"},
+			{Code: "4100", Name: "Sales Returns and Allowances", Type: "revenue", ParentCode: "4000"},
+			{Code: "4200", Name: "Sales Discounts", Type: "revenue", ParentCode: "4000"},
+			{Code: "4900", Name: "Other Income", Type: "revenue"},
+			{Code: "5000", Name: "Cost of Goods Sold", Type: "expense"},
+			{Code: "6000", Name: "Operating Expenses", Type: "expense"},
+			{Code: "6010", Name: "Salaries and Wages", Type: "expense", ParentCode: "6000"},
+			{Code: "6020", Name: "Rent Expense", Type: "expense", ParentCode: "6000"},
+			{Code: "6030", Name: "Utilities Expense", Type: "expense", ParentCode: "6000"},
+			{Code: "6040", Name: "Office Supplies", Type: "expense", ParentCode: "6000"},
+			{Code: "6050", Name: "Depreciation Expense", Type: "expense", ParentCode: "6000"},
+			{Code: "6060", Name: "Insurance Expense", Type: "expense", ParentCode: "6000"},
+			{Code: "6070", Name: "Advertising Expense", Type: "expense", ParentCode: "6000"},
+			{Code: "6080", Name: "Travel Expense", Type: "expense", ParentCode: "6000"},
+			{Code: "6090", Name: "Professional Fees", Type: "expense", ParentCode: "6000"},
+			{Code: "7000", Name: "Other Expenses", Type: "expense"},
+			{Code: "7010", Name: "Interest Expense", Type: "expense", ParentCode: "7000"},
+			{Code: "7020", Name: "Income Tax Expense", Type: "expense", ParentCode: "7000"},
+		},
+	},
+}
+
+// findChartTemplate 根据编码查找内置科目表模板
+func findChartTemplate(code string) (*chartTemplate, bool) {
+	for i := range chartTemplates {
+		if chartTemplates[i].Code == code {
+			return &chartTemplates[i], true
+		}
+	}
+	return nil, false
+}
@@ -17,24 +17,29 @@ type AccountService interface {
 	CRUDService[models.Account, dto.AccountCreateRequest, dto.AccountUpdateRequest, dto.AccountResponse]
 	CreateAccount(ctx context.Context, account *models.Account) error
 	GetAccount(ctx context.Context, id uint) (*models.Account, error)
-	GetAccountByCode(ctx context.Context, code string) (*models.Account, error)
+	GetAccountByCode(ctx context.Context, companyID uint, code string) (*models.Account, error)
 	UpdateAccount(ctx context.Context, account *models.Account) error
 	DeleteAccount(ctx context.Context, id uint) error
-	ListAccounts(ctx context.Context, page, pageSize int) ([]*models.Account, int64, error)
-	GetAccountsByType(ctx context.Context, accountType string, page, pageSize int) ([]*models.Account, int64, error)
+	ListAccounts(ctx context.Context, companyID uint, page, pageSize int) ([]*models.Account, int64, error)
+	GetAccountsByType(ctx context.Context, companyID uint, accountType string, page, pageSize int) ([]*models.Account, int64, error)
 	GetAccountChildren(ctx context.Context, parentID uint) ([]*models.Account, error)
 	SearchAccounts(ctx context.Context, keyword string, page, pageSize int) ([]*models.Account, int64, error)
 	ValidateAccountHierarchy(ctx context.Context, account *models.Account) error
+	ListChartTemplates() []dto.ChartTemplateResponse
+	ApplyChartTemplate(ctx context.Context, req *dto.ChartTemplateApplyRequest) (*dto.AccountImportResult, error)
+	ImportAccounts(ctx context.Context, req *dto.AccountImportRequest, data []byte) (*dto.AccountImportResult, error)
+	ExportAccounts(ctx context.Context, req *dto.AccountExportRequest) ([]byte, error)
 }
 
 // AccountServiceImpl 会计科目服务实现
 type AccountServiceImpl struct {
 	*BaseService
 	accountRepo repositories.AccountRepository
+	companyRepo repositories.CompanyRepository
 }
 
 // NewAccountService 创建会计科目服务实例
-func NewAccountService(accountRepo repositories.AccountRepository) AccountService {
+func NewAccountService(accountRepo repositories.AccountRepository, companyRepo repositories.CompanyRepository) AccountService {
 	baseConfig := &BaseServiceConfig{
 		EnableAudit:      true,
 		EnableValidation: true,
@@ -46,6 +51,7 @@ func NewAccountService(accountRepo repositories.AccountRepository) AccountServic
 	return &AccountServiceImpl{
 		BaseService: NewBaseService(baseConfig),
 		accountRepo: accountRepo,
+		companyRepo: companyRepo,
 	}
 }
 
@@ -58,6 +64,7 @@ func (s *AccountServiceImpl) Create(ctx context.Context, req *dto.AccountCreateR
 
 	// 转换为模型
 	account := &models.Account{
+		CompanyID:   req.CompanyID,
 		Code:        req.Code,
 		Name:        req.Name,
 		AccountType: req.Type,
@@ -230,6 +237,7 @@ func (s *AccountServiceImpl) convertToAccountResponse(account *models.Account) *
 
 	return &dto.AccountResponse{
 		ID:        account.ID,
+		CompanyID: account.CompanyID,
 		Code:      account.Code,
 		Name:      account.Name,
 		Type:      account.AccountType,
@@ -242,8 +250,15 @@ func (s *AccountServiceImpl) convertToAccountResponse(account *models.Account) *
 
 // CreateAccount 创建会计科目
 func (s *AccountServiceImpl) CreateAccount(ctx context.Context, account *models.Account) error {
-	// 验证科目编码是否已存在
-	existingAccount, err := s.accountRepo.GetByCode(ctx, account.Code)
+	// 解析所属公司，未指定时归属默认公司
+	companyID, err := resolveCompanyID(ctx, s.companyRepo, account.CompanyID)
+	if err != nil {
+		return err
+	}
+	account.CompanyID = companyID
+
+	// 验证科目编码在公司内是否已存在
+	existingAccount, err := s.accountRepo.GetByCode(ctx, account.CompanyID, account.Code)
 	if err != nil {
 		return fmt.Errorf("检查科目编码失败: %w", err)
 	}
@@ -277,9 +292,12 @@ func (s *AccountServiceImpl) GetAccount(ctx context.Context, id uint) (*models.A
 	return account, nil
 }
 
-// GetAccountByCode 根据编码获取会计科目
-func (s *AccountServiceImpl) GetAccountByCode(ctx context.Context, code string) (*models.Account, error) {
-	account, err := s.accountRepo.GetByCode(ctx, code)
+// GetAccountByCode 根据编码获取公司的会计科目，companyID 为 0 时使用默认公司
+func (s *AccountServiceImpl) GetAccountByCode(ctx context.Context, companyID uint, code string) (*models.Account, error) {
+	if companyID == 0 {
+		companyID = models.DefaultCompanyID
+	}
+	account, err := s.accountRepo.GetByCode(ctx, companyID, code)
 	if err != nil {
 		return nil, fmt.Errorf("获取科目失败: %w", err)
 	}
@@ -300,9 +318,12 @@ func (s *AccountServiceImpl) UpdateAccount(ctx context.Context, account *models.
 		return errors.New("科目不存在")
 	}
 
+	// 科目所属公司不可变更
+	account.CompanyID = existingAccount.CompanyID
+
 	// 如果编码发生变化，检查新编码是否已存在
 	if existingAccount.Code != account.Code {
-		codeAccount, err := s.accountRepo.GetByCode(ctx, account.Code)
+		codeAccount, err := s.accountRepo.GetByCode(ctx, account.CompanyID, account.Code)
 		if err != nil {
 			return fmt.Errorf("检查科目编码失败: %w", err)
 		}
@@ -346,8 +367,18 @@ func (s *AccountServiceImpl) DeleteAccount(ctx context.Context, id uint) error {
 	return s.accountRepo.Delete(ctx, id)
 }
 
-// ListAccounts 获取会计科目列表
-func (s *AccountServiceImpl) ListAccounts(ctx context.Context, page, pageSize int) ([]*models.Account, int64, error) {
+// ListAccounts 获取会计科目列表，companyID 不为 0 时仅返回该公司的科目
+func (s *AccountServiceImpl) ListAccounts(ctx context.Context, companyID uint, page, pageSize int) ([]*models.Account, int64, error) {
+	if companyID != 0 {
+		if page < 1 {
+			page = 1
+		}
+		if pageSize < 1 || pageSize > 100 {
+			pageSize = 20
+		}
+		return s.accountRepo.GetByCompany(ctx, companyID, (page-1)*pageSize, pageSize)
+	}
+
 	// 转换为通用分页请求
 	req := &dto.PaginationRequest{
 		Page:     page,
@@ -374,6 +405,7 @@ func (s *AccountServiceImpl) ListAccounts(ctx context.Context, page, pageSize in
 				CreatedAt: response.CreatedAt,
 				UpdatedAt: response.UpdatedAt,
 			},
+			CompanyID:   response.CompanyID,
 			Code:        response.Code,
 			Name:        response.Name,
 			AccountType: response.Type,
@@ -386,7 +418,7 @@ func (s *AccountServiceImpl) ListAccounts(ctx context.Context, page, pageSize in
 }
 
 // GetAccountsByType 根据科目类型获取会计科目
-func (s *AccountServiceImpl) GetAccountsByType(ctx context.Context, accountType string, page, pageSize int) ([]*models.Account, int64, error) {
+func (s *AccountServiceImpl) GetAccountsByType(ctx context.Context, companyID uint, accountType string, page, pageSize int) ([]*models.Account, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -407,7 +439,7 @@ func (s *AccountServiceImpl) GetAccountsByType(ctx context.Context, accountType
 	}
 
 	offset := (page - 1) * pageSize
-	return s.accountRepo.GetByType(ctx, accountType, offset, pageSize)
+	return s.accountRepo.GetByType(ctx, companyID, accountType, offset, pageSize)
 }
 
 // GetAccountChildren 获取子科目
@@ -446,6 +478,7 @@ func (s *AccountServiceImpl) SearchAccounts(ctx context.Context, keyword string,
 				CreatedAt: response.CreatedAt,
 				UpdatedAt: response.UpdatedAt,
 			},
+			CompanyID:   response.CompanyID,
 			Code:        response.Code,
 			Name:        response.Name,
 			AccountType: response.Type,
@@ -472,6 +505,11 @@ func (s *AccountServiceImpl) ValidateAccountHierarchy(ctx context.Context, accou
 		return errors.New("父科目不存在")
 	}
 
+	// 父科目必须属于同一公司
+	if account.CompanyID != 0 && parent.CompanyID != account.CompanyID {
+		return errors.New("父科目必须属于同一公司")
+	}
+
 	// 检查父科目类型是否匹配
 	if parent.AccountType != account.AccountType {
 		return errors.New("子科目类型必须与父科目类型一致")
@@ -526,13 +564,15 @@ type JournalEntryService interface {
 type JournalEntryServiceImpl struct {
 	journalRepo repositories.JournalEntryRepository
 	accountRepo repositories.AccountRepository
+	companyRepo repositories.CompanyRepository
 }
 
 // NewJournalEntryService 创建会计分录服务实例
-func NewJournalEntryService(journalRepo repositories.JournalEntryRepository, accountRepo repositories.AccountRepository) JournalEntryService {
+func NewJournalEntryService(journalRepo repositories.JournalEntryRepository, accountRepo repositories.AccountRepository, companyRepo repositories.CompanyRepository) JournalEntryService {
 	return &JournalEntryServiceImpl{
 		journalRepo: journalRepo,
 		accountRepo: accountRepo,
+		companyRepo: companyRepo,
 	}
 }
 
@@ -547,6 +587,12 @@ func (s *JournalEntryServiceImpl) CreateJournalEntry(ctx context.Context, entry
 		return errors.New("科目不存在")
 	}
 
+	// 分录归属于科目所在公司
+	entry.CompanyID = account.CompanyID
+	if err := s.validateIntercompany(ctx, entry.CompanyID, entry.IntercompanyCompanyID); err != nil {
+		return err
+	}
+
 	// 验证借贷金额
 	if entry.Debit < 0 || entry.Credit < 0 {
 		return errors.New("借贷金额不能为负数")
@@ -561,67 +607,69 @@ func (s *JournalEntryServiceImpl) CreateJournalEntry(ctx context.Context, entry
 	return s.journalRepo.Create(ctx, entry)
 }
 
-// CreateJournalEntryFromDTO 从DTO创建会计分录
+// CreateJournalEntryFromDTO 从DTO创建会计凭证：凭证头与分录在同一事务中写入，且全部分录属于同一公司
 func (s *JournalEntryServiceImpl) CreateJournalEntryFromDTO(ctx context.Context, req *dto.JournalEntryCreateRequest) (*dto.JournalEntryResponse, error) {
-	// 首先创建一个Transaction记录
+	companyID, err := resolveCompanyID(ctx, s.companyRepo, req.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 已关闭的会计期间不允许过账
+	if err := ensurePeriodOpen(ctx, s.companyRepo, companyID, req.Date); err != nil {
+		return nil, err
+	}
+
 	transaction := &models.Transaction{
-		TransactionNumber: fmt.Sprintf("TXN-%d", time.Now().Unix()),
+		CompanyID:         companyID,
+		TransactionNumber: fmt.Sprintf("TXN-%d", time.Now().UnixNano()),
 		TransactionDate:   req.Date,
 		TransactionType:   "journal",
-		Amount:            0, // 将在下面计算
 		Description:       req.Description,
 		Status:            "completed",
 	}
 
-	// 计算总金额
-	var totalAmount float64
-	for _, item := range req.Items {
-		if item.DebitAmount > 0 {
-			totalAmount += item.DebitAmount
-		}
-	}
-	transaction.Amount = totalAmount
-
-	// 这里我们需要一个Transaction repository，暂时跳过Transaction创建
-	// 直接创建JournalEntry记录，使用固定的TransactionID
-	transactionID := uint(1) // 临时使用固定ID
-
+	accounts := make(map[uint]*models.Account)
 	var journalEntries []*models.JournalEntry
 	for _, item := range req.Items {
-		entry := &models.JournalEntry{
-			TransactionID: transactionID,
-			AccountID:     item.AccountID,
-			Debit:         item.DebitAmount,
-			Credit:        item.CreditAmount,
-			Description:   item.Description,
-		}
-
-		// 验证科目存在
+		// 验证科目存在且属于凭证所在公司
 		account, err := s.accountRepo.GetByID(ctx, item.AccountID)
-		if err != nil {
-			return nil, fmt.Errorf("检查科目失败: %w", err)
-		}
-		if account == nil {
+		if err != nil || account == nil {
 			return nil, fmt.Errorf("科目ID %d 不存在", item.AccountID)
 		}
-
-		if err := s.journalRepo.Create(ctx, entry); err != nil {
-			return nil, fmt.Errorf("创建分录失败: %w", err)
+		if account.CompanyID != companyID {
+			return nil, fmt.Errorf("科目 %s 不属于凭证所在公司", account.Code)
 		}
-		journalEntries = append(journalEntries, entry)
+		if err := s.validateIntercompany(ctx, companyID, item.IntercompanyCompanyID); err != nil {
+			return nil, err
+		}
+		accounts[account.ID] = account
+
+		transaction.Amount += item.DebitAmount
+		journalEntries = append(journalEntries, &models.JournalEntry{
+			AccountID:             item.AccountID,
+			Debit:                 item.DebitAmount,
+			Credit:                item.CreditAmount,
+			Description:           item.Description,
+			IntercompanyCompanyID: item.IntercompanyCompanyID,
+		})
+	}
+
+	if err := s.journalRepo.CreateVoucher(ctx, transaction, journalEntries); err != nil {
+		return nil, fmt.Errorf("创建凭证失败: %w", err)
 	}
 
 	// 构造响应
 	response := &dto.JournalEntryResponse{
-		ID:          journalEntries[0].ID, // 使用第一个分录的ID
-		Number:      fmt.Sprintf("JE-%d", journalEntries[0].ID),
+		ID:          transaction.ID,
+		CompanyID:   companyID,
+		Number:      transaction.TransactionNumber,
 		Date:        req.Date,
 		Reference:   req.Reference,
 		Description: req.Description,
-		TotalDebit:  0,
-		TotalCredit: 0,
 		Status:      "posted",
-		Items:       make([]dto.JournalEntryItemResponse, 0),
+		Items:       make([]dto.JournalEntryItemResponse, 0, len(journalEntries)),
+		CreatedAt:   transaction.CreatedAt,
+		UpdatedAt:   transaction.UpdatedAt,
 	}
 
 	// 计算总借贷金额并构造分录项响应
@@ -629,27 +677,40 @@ func (s *JournalEntryServiceImpl) CreateJournalEntryFromDTO(ctx context.Context,
 		response.TotalDebit += entry.Debit
 		response.TotalCredit += entry.Credit
 
-		// 获取科目信息
-		account, _ := s.accountRepo.GetByID(ctx, entry.AccountID)
-
-		itemResponse := dto.JournalEntryItemResponse{
-			ID:           entry.ID,
-			DebitAmount:  entry.Debit,
-			CreditAmount: entry.Credit,
-			Description:  entry.Description,
+		account := accounts[entry.AccountID]
+		response.Items = append(response.Items, dto.JournalEntryItemResponse{
+			ID:                    entry.ID,
+			DebitAmount:           entry.Debit,
+			CreditAmount:          entry.Credit,
+			Description:           entry.Description,
+			IntercompanyCompanyID: entry.IntercompanyCompanyID,
 			Account: dto.AccountResponse{
-				ID:   account.ID,
-				Code: account.Code,
-				Name: account.Name,
-				Type: account.AccountType,
+				ID:        account.ID,
+				CompanyID: account.CompanyID,
+				Code:      account.Code,
+				Name:      account.Name,
+				Type:      account.AccountType,
 			},
-		}
-		response.Items = append(response.Items, itemResponse)
+		})
 	}
 
 	return response, nil
 }
 
+// validateIntercompany 校验内部往来对方公司存在且不是本公司
+func (s *JournalEntryServiceImpl) validateIntercompany(ctx context.Context, companyID uint, counterpartyID *uint) error {
+	if counterpartyID == nil {
+		return nil
+	}
+	if *counterpartyID == companyID {
+		return errors.New("内部往来对方公司不能是本公司")
+	}
+	if _, err := s.companyRepo.GetByID(ctx, *counterpartyID); err != nil {
+		return fmt.Errorf("内部往来对方公司 %d 不存在", *counterpartyID)
+	}
+	return nil
+}
+
 // GetJournalEntry 获取会计分录
 func (s *JournalEntryServiceImpl) GetJournalEntry(ctx context.Context, id uint) (*models.JournalEntry, error) {
 	entry, err := s.journalRepo.GetByID(ctx, id)
@@ -681,6 +742,13 @@ func (s *JournalEntryServiceImpl) UpdateJournalEntry(ctx context.Context, entry
 	if account == nil {
 		return errors.New("科目不存在")
 	}
+	if account.CompanyID != existingEntry.CompanyID {
+		return errors.New("科目与分录不属于同一公司")
+	}
+	entry.CompanyID = existingEntry.CompanyID
+	if err := s.validateIntercompany(ctx, entry.CompanyID, entry.IntercompanyCompanyID); err != nil {
+		return err
+	}
 
 	// 验证借贷金额
 	if entry.Debit < 0 || entry.Credit < 0 {
@@ -741,138 +809,3 @@ func (s *JournalEntryServiceImpl) GetJournalEntriesByDateRange(ctx context.Conte
 	offset := (page - 1) * pageSize
 	return s.journalRepo.GetByDateRange(ctx, startDate, endDate, offset, pageSize)
 }
-
-// PaymentEntryService 付款记录服务接口
-type PaymentEntryService interface {
-	CreatePaymentEntry(ctx context.Context, payment *models.PaymentEntry) error
-	GetPaymentEntry(ctx context.Context, id uint) (*models.PaymentEntry, error)
-	UpdatePaymentEntry(ctx context.Context, payment *models.PaymentEntry) error
-	DeletePaymentEntry(ctx context.Context, id uint) error
-	ListPaymentEntries(ctx context.Context, page, pageSize int) ([]*models.PaymentEntry, int64, error)
-	GetPaymentEntriesByParty(ctx context.Context, partyType string, partyID uint, page, pageSize int) ([]*models.PaymentEntry, int64, error)
-}
-
-// PaymentEntryServiceImpl 付款记录服务实现
-type PaymentEntryServiceImpl struct {
-	paymentRepo repositories.PaymentEntryRepository
-}
-
-// NewPaymentEntryService 创建付款记录服务实例
-func NewPaymentEntryService(paymentRepo repositories.PaymentEntryRepository) PaymentEntryService {
-	return &PaymentEntryServiceImpl{
-		paymentRepo: paymentRepo,
-	}
-}
-
-// CreatePaymentEntry 创建付款记录
-func (s *PaymentEntryServiceImpl) CreatePaymentEntry(ctx context.Context, payment *models.PaymentEntry) error {
-	// 验证必填字段
-	if payment.PaymentType == "" {
-		return errors.New("付款类型不能为空")
-	}
-	if payment.PartyType == "" {
-		return errors.New("关联方类型不能为空")
-	}
-	if payment.PartyID == 0 {
-		return errors.New("关联方ID不能为空")
-	}
-	if payment.PaidAmount < 0 || payment.ReceivedAmount < 0 {
-		return errors.New("付款金额不能为负数")
-	}
-
-	payment.CreatedAt = time.Now()
-	payment.UpdatedAt = time.Now()
-	if payment.Status == "" {
-		payment.Status = "draft"
-	}
-
-	return s.paymentRepo.Create(ctx, payment)
-}
-
-// GetPaymentEntry 获取付款记录
-func (s *PaymentEntryServiceImpl) GetPaymentEntry(ctx context.Context, id uint) (*models.PaymentEntry, error) {
-	if id == 0 {
-		return nil, errors.New("付款记录ID不能为空")
-	}
-	return s.paymentRepo.GetByID(ctx, id)
-}
-
-// UpdatePaymentEntry 更新付款记录
-func (s *PaymentEntryServiceImpl) UpdatePaymentEntry(ctx context.Context, payment *models.PaymentEntry) error {
-	// 检查记录是否存在
-	existing, err := s.paymentRepo.GetByID(ctx, payment.ID)
-	if err != nil {
-		return fmt.Errorf("检查付款记录失败: %w", err)
-	}
-	if existing == nil {
-		return errors.New("付款记录不存在")
-	}
-
-	// 验证必填字段
-	if payment.PaymentType == "" {
-		return errors.New("付款类型不能为空")
-	}
-	if payment.PartyType == "" {
-		return errors.New("关联方类型不能为空")
-	}
-	if payment.PartyID == 0 {
-		return errors.New("关联方ID不能为空")
-	}
-	if payment.PaidAmount < 0 || payment.ReceivedAmount < 0 {
-		return errors.New("付款金额不能为负数")
-	}
-
-	payment.UpdatedAt = time.Now()
-	return s.paymentRepo.Update(ctx, payment)
-}
-
-// DeletePaymentEntry 删除付款记录
-func (s *PaymentEntryServiceImpl) DeletePaymentEntry(ctx context.Context, id uint) error {
-	// 检查记录是否存在
-	payment, err := s.paymentRepo.GetByID(ctx, id)
-	if err != nil {
-		return fmt.Errorf("检查付款记录失败: %w", err)
-	}
-	if payment == nil {
-		return errors.New("付款记录不存在")
-	}
-
-	return s.paymentRepo.Delete(ctx, id)
-}
-
-// ListPaymentEntries 获取付款记录列表
-func (s *PaymentEntryServiceImpl) ListPaymentEntries(ctx context.Context, page, pageSize int) ([]*models.PaymentEntry, int64, error) {
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
-	}
-
-	options := &common.QueryOptions{
-		Pagination: &dto.PaginationRequest{
-			Page:     page,
-			PageSize: pageSize,
-		},
-	}
-	return s.paymentRepo.List(ctx, options)
-}
-
-// GetPaymentEntriesByParty 根据关联方获取付款记录
-func (s *PaymentEntryServiceImpl) GetPaymentEntriesByParty(ctx context.Context, partyType string, partyID uint, page, pageSize int) ([]*models.PaymentEntry, int64, error) {
-	if partyType == "" {
-		return nil, 0, errors.New("关联方类型不能为空")
-	}
-	if partyID == 0 {
-		return nil, 0, errors.New("关联方ID不能为空")
-	}
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
-	}
-
-	offset := (page - 1) * pageSize
-	return s.paymentRepo.GetByParty(ctx, partyType, partyID, offset, pageSize)
-}
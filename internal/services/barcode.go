@@ -0,0 +1,635 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// 扫描条码的识别方式
+const (
+	scanFormatGS1      = "gs1"
+	scanFormatGTIN     = "gtin"
+	scanFormatBarcode  = "barcode"
+	scanFormatItemCode = "item_code"
+	scanFormatLocation = "location"
+	scanFormatUnknown  = "unknown"
+)
+
+// BarcodeService 物料条码、扫描解析与标签打印服务接口
+type BarcodeService interface {
+	RegisterBarcode(ctx context.Context, itemID uint, req *dto.ItemBarcodeCreateRequest) (*dto.ItemBarcodeResponse, error)
+	ListItemBarcodes(ctx context.Context, itemID uint) ([]dto.ItemBarcodeResponse, error)
+	DeleteBarcode(ctx context.Context, itemID, barcodeID uint) error
+	Scan(ctx context.Context, req *dto.BarcodeScanRequest) (*dto.BarcodeScanResponse, error)
+	GenerateLabels(ctx context.Context, req *dto.BarcodeLabelRequest) ([]byte, error)
+}
+
+// BarcodeServiceImpl 物料条码服务实现
+type BarcodeServiceImpl struct {
+	barcodeRepo repositories.BarcodeRepository
+	batchRepo   repositories.BatchRepository
+	itemRepo    repositories.ItemRepository
+	uomService  UOMService
+}
+
+// NewBarcodeService 创建物料条码服务实例
+func NewBarcodeService(
+	barcodeRepo repositories.BarcodeRepository,
+	batchRepo repositories.BatchRepository,
+	itemRepo repositories.ItemRepository,
+	uomService UOMService,
+) BarcodeService {
+	return &BarcodeServiceImpl{
+		barcodeRepo: barcodeRepo,
+		batchRepo:   batchRepo,
+		itemRepo:    itemRepo,
+		uomService:  uomService,
+	}
+}
+
+// RegisterBarcode 为物料登记条码。EAN-13、UPC-A、EAN-8 与 GTIN-14 校验校验位并记录 14 位 GTIN，
+// 同一 GTIN 不能登记到多个物料；条码单位必须能换算为物料的库存单位
+func (s *BarcodeServiceImpl) RegisterBarcode(ctx context.Context, itemID uint, req *dto.ItemBarcodeCreateRequest) (*dto.ItemBarcodeResponse, error) {
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("物料 %d 不存在", itemID)
+	}
+
+	code := strings.TrimSpace(req.Barcode)
+	barcodeType := req.BarcodeType
+	if barcodeType == "" {
+		if barcodeType, err = detectBarcodeType(code); err != nil {
+			return nil, err
+		}
+	}
+	barcode := &models.ItemBarcode{
+		ItemID:      item.ID,
+		Barcode:     code,
+		BarcodeType: barcodeType,
+		UOM:         strings.TrimSpace(req.UOM),
+		IsPrimary:   req.IsPrimary,
+		Description: req.Description,
+	}
+
+	switch barcodeType {
+	case models.BarcodeTypeEAN13, models.BarcodeTypeUPCA, models.BarcodeTypeEAN8, models.BarcodeTypeGTIN14:
+		if len(code) != gtinLength(barcodeType) || !utils.ValidGTIN(code) {
+			return nil, fmt.Errorf("条码 %s 不是有效的 %s 条码", code, strings.ToUpper(barcodeType))
+		}
+		barcode.GTIN = utils.NormalizeGTIN(code)
+	case models.BarcodeTypeCode128:
+		if _, err := utils.EncodeCode128(code, false); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := s.barcodeRepo.GetByBarcode(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料条码失败: %w", err)
+	}
+	if existing == nil && barcode.GTIN != "" {
+		if existing, err = s.barcodeRepo.GetByGTIN(ctx, barcode.GTIN); err != nil {
+			return nil, fmt.Errorf("获取物料条码失败: %w", err)
+		}
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("条码 %s 已登记到物料 %d", code, existing.ItemID)
+	}
+
+	conversion, err := s.uomService.ConvertToStock(ctx, item.ID, barcode.UOM, 1)
+	if err != nil {
+		return nil, err
+	}
+	barcode.UOM = conversion.UOM
+
+	if err := s.barcodeRepo.CreateBarcode(ctx, barcode); err != nil {
+		return nil, fmt.Errorf("登记物料条码失败: %w", err)
+	}
+	utils.Info("登记物料条码", utils.Uint("item_id", item.ID), utils.String("barcode", code))
+
+	response := toItemBarcodeResponse(barcode)
+	response.ConversionFactor = conversion.ConversionFactor
+	return &response, nil
+}
+
+// ListItemBarcodes 获取物料的全部条码
+func (s *BarcodeServiceImpl) ListItemBarcodes(ctx context.Context, itemID uint) ([]dto.ItemBarcodeResponse, error) {
+	if _, err := s.itemRepo.GetByID(ctx, itemID); err != nil {
+		return nil, fmt.Errorf("物料 %d 不存在", itemID)
+	}
+	barcodes, err := s.barcodeRepo.ListItemBarcodes(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料条码失败: %w", err)
+	}
+
+	responses := make([]dto.ItemBarcodeResponse, 0, len(barcodes))
+	for _, barcode := range barcodes {
+		response := toItemBarcodeResponse(barcode)
+		if conversion, err := s.uomService.ConvertToStock(ctx, itemID, barcode.UOM, 1); err == nil {
+			response.ConversionFactor = conversion.ConversionFactor
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// DeleteBarcode 删除物料条码
+func (s *BarcodeServiceImpl) DeleteBarcode(ctx context.Context, itemID, barcodeID uint) error {
+	barcode, err := s.barcodeRepo.GetBarcode(ctx, barcodeID)
+	if err != nil {
+		return fmt.Errorf("获取物料条码失败: %w", err)
+	}
+	if barcode == nil || barcode.ItemID != itemID {
+		return errors.New("物料条码不存在")
+	}
+	if err := s.barcodeRepo.DeleteBarcode(ctx, barcodeID); err != nil {
+		return fmt.Errorf("删除物料条码失败: %w", err)
+	}
+	return nil
+}
+
+// scanState 一次扫描作业的解析中间结果
+type scanState struct {
+	item     *models.Item
+	uom      string
+	quantity float64
+	gs1Qty   bool
+	batchNo  string
+	serialNo string
+	expiry   *time.Time
+	location *models.Location
+}
+
+// Scan 解析一次作业中扫描的条码并预填单据行。每个条码依次按 GS1 应用标识符、登记的条码、GTIN、
+// 物料编码与库位编码识别；GS1 条码提供批次、有效期、序列号与数量，普通条码每扫描一次计数量 1（条码单位）。
+// 识别出物料与仓库时预填库存移动，指定采购订单时预填该物料未收完的订单行的收货明细；无法识别或需要注意的情况以警告返回
+func (s *BarcodeServiceImpl) Scan(ctx context.Context, req *dto.BarcodeScanRequest) (*dto.BarcodeScanResponse, error) {
+	response := &dto.BarcodeScanResponse{}
+	state := &scanState{}
+	for _, raw := range req.Codes {
+		code := strings.TrimSpace(raw)
+		scanned, err := s.resolveCode(ctx, req.WarehouseID, code, state, response)
+		if err != nil {
+			return nil, err
+		}
+		response.Codes = append(response.Codes, scanned)
+	}
+
+	if state.location != nil {
+		response.Location = &dto.BarcodeScanLocation{
+			ID:          state.location.ID,
+			Code:        state.location.Code,
+			Name:        state.location.Name,
+			WarehouseID: state.location.WarehouseID,
+		}
+	}
+	if state.item == nil {
+		response.Warnings = append(response.Warnings, "未识别到物料")
+		return response, nil
+	}
+
+	item := state.item
+	response.Item = &dto.BarcodeScanItem{
+		ID:           item.ID,
+		Code:         item.Code,
+		Name:         item.Name,
+		Unit:         item.Unit,
+		TrackingMode: item.TrackingMode,
+	}
+	response.Quantity = state.quantity
+	response.UOM = state.uom
+	response.SerialNo = state.serialNo
+	conversion, err := s.uomService.ConvertToStock(ctx, item.ID, state.uom, state.quantity)
+	if err != nil {
+		response.Warnings = append(response.Warnings, err.Error())
+	} else {
+		response.UOM = conversion.UOM
+		response.StockQuantity = conversion.StockQuantity
+	}
+
+	movementType := req.MovementType
+	if movementType == "" {
+		movementType = models.MovementTypeIn
+	}
+	if err := s.resolveScanTracking(ctx, item, state, movementType, response); err != nil {
+		return nil, err
+	}
+
+	warehouseID := req.WarehouseID
+	if warehouseID == 0 && state.location != nil {
+		warehouseID = state.location.WarehouseID
+	}
+	if warehouseID == 0 {
+		response.Warnings = append(response.Warnings, "未指定仓库，无法预填库存移动")
+	} else {
+		movement := &dto.MovementCreateRequest{
+			ItemID:      item.ID,
+			WarehouseID: warehouseID,
+			Type:        movementType,
+			Quantity:    response.Quantity,
+			UOM:         response.UOM,
+			BatchNo:     state.batchNo,
+			SerialNo:    state.serialNo,
+		}
+		if state.location != nil {
+			movement.LocationID = state.location.ID
+		}
+		if response.Batch != nil && !response.Batch.Exists {
+			movement.ExpiryDate = state.expiry
+		}
+		response.Movement = movement
+	}
+
+	if req.PurchaseOrderID != 0 {
+		orderLine, err := s.barcodeRepo.GetOpenPurchaseOrderItem(ctx, req.PurchaseOrderID, item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("获取采购订单明细失败: %w", err)
+		}
+		if orderLine == nil {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("采购订单 %d 中没有未收完的物料 %s", req.PurchaseOrderID, item.Code))
+		} else {
+			line := &dto.PurchaseReceiptItemRequest{
+				OrderItemID:   orderLine.ID,
+				ReceivedQty:   response.StockQuantity,
+				QualityStatus: "pending",
+			}
+			if state.location != nil {
+				line.LocationID = state.location.ID
+			} else {
+				response.Warnings = append(response.Warnings, "未扫描库位，收货明细需要补充库位")
+			}
+			if remaining := orderLine.Quantity - orderLine.ReceivedQty; response.StockQuantity > remaining+stockQuantityTolerance {
+				response.Warnings = append(response.Warnings, fmt.Sprintf("扫描数量 %g 超过订单未收数量 %g", response.StockQuantity, remaining))
+			}
+			response.ReceiptLine = line
+		}
+	}
+	return response, nil
+}
+
+// resolveCode 识别单个条码并合并到扫描结果
+func (s *BarcodeServiceImpl) resolveCode(ctx context.Context, warehouseID uint, code string, state *scanState, response *dto.BarcodeScanResponse) (dto.BarcodeScanCode, error) {
+	scanned := dto.BarcodeScanCode{Code: code, Format: scanFormatUnknown}
+	if utils.IsGS1(code) {
+		data, err := utils.ParseGS1(code)
+		if err != nil {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("条码 %s 解析失败: %s", code, err.Error()))
+			return scanned, nil
+		}
+		scanned.Format = scanFormatGS1
+		scanned.GS1 = data
+		if data.GTIN != "" {
+			barcode, err := s.barcodeRepo.GetByGTIN(ctx, data.GTIN)
+			if err != nil {
+				return scanned, fmt.Errorf("获取物料条码失败: %w", err)
+			}
+			if barcode == nil {
+				response.Warnings = append(response.Warnings, fmt.Sprintf("GTIN %s 未登记到物料", data.GTIN))
+			} else if err := s.applyScannedItem(ctx, barcode.ItemID, barcode.UOM, data.Quantity, state, response); err != nil {
+				return scanned, err
+			} else {
+				scanned.Match = state.item.Code
+			}
+		}
+		if data.BatchNo != "" {
+			state.batchNo = data.BatchNo
+		}
+		if data.SerialNo != "" {
+			state.serialNo = data.SerialNo
+		}
+		if data.ExpiryDate != nil {
+			state.expiry = data.ExpiryDate
+		}
+		return scanned, nil
+	}
+
+	barcode, err := s.barcodeRepo.GetByBarcode(ctx, code)
+	if err != nil {
+		return scanned, fmt.Errorf("获取物料条码失败: %w", err)
+	}
+	if barcode != nil {
+		scanned.Format = scanFormatBarcode
+	} else if gtin := utils.NormalizeGTIN(code); gtin != "" {
+		if barcode, err = s.barcodeRepo.GetByGTIN(ctx, gtin); err != nil {
+			return scanned, fmt.Errorf("获取物料条码失败: %w", err)
+		}
+		scanned.Format = scanFormatGTIN
+	}
+	if barcode != nil {
+		if err := s.applyScannedItem(ctx, barcode.ItemID, barcode.UOM, 0, state, response); err != nil {
+			return scanned, err
+		}
+		scanned.Match = state.item.Code
+		return scanned, nil
+	}
+
+	item, err := s.barcodeRepo.GetItemByCode(ctx, code)
+	if err != nil {
+		return scanned, fmt.Errorf("获取物料失败: %w", err)
+	}
+	if item != nil {
+		scanned.Format = scanFormatItemCode
+		if err := s.applyScannedItem(ctx, item.ID, "", 0, state, response); err != nil {
+			return scanned, err
+		}
+		scanned.Match = item.Code
+		return scanned, nil
+	}
+
+	locations, err := s.barcodeRepo.FindLocationsByCode(ctx, warehouseID, code)
+	if err != nil {
+		return scanned, fmt.Errorf("获取库位失败: %w", err)
+	}
+	switch len(locations) {
+	case 0:
+		response.Warnings = append(response.Warnings, fmt.Sprintf("条码 %s 无法识别", code))
+	case 1:
+		scanned.Format = scanFormatLocation
+		scanned.Match = locations[0].Code
+		state.location = locations[0]
+	default:
+		scanned.Format = scanFormatLocation
+		response.Warnings = append(response.Warnings, fmt.Sprintf("库位 %s 存在于多个仓库，请指定仓库", code))
+	}
+	return scanned, nil
+}
+
+// applyScannedItem 记录扫描到的物料；同一物料的普通条码重复扫描时累加数量，扫描到其他物料时保留第一个并警告
+func (s *BarcodeServiceImpl) applyScannedItem(ctx context.Context, itemID uint, uom string, gs1Qty float64, state *scanState, response *dto.BarcodeScanResponse) error {
+	if state.item != nil && state.item.ID != itemID {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("扫描内容包含多个物料，已忽略物料 %d", itemID))
+		return nil
+	}
+	if state.item == nil {
+		item, err := s.itemRepo.GetByID(ctx, itemID)
+		if err != nil {
+			return fmt.Errorf("物料 %d 不存在", itemID)
+		}
+		state.item = item
+		state.uom = displayUOM(uom, item)
+	} else if state.uom != displayUOM(uom, state.item) {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("物料 %s 的条码单位不一致，按 %s 计数", state.item.Code, state.uom))
+	}
+
+	switch {
+	case gs1Qty > 0:
+		state.quantity = gs1Qty
+		state.gs1Qty = true
+	case !state.gs1Qty:
+		state.quantity++
+	}
+	return nil
+}
+
+// resolveScanTracking 校验扫描到的批次与序列号
+func (s *BarcodeServiceImpl) resolveScanTracking(ctx context.Context, item *models.Item, state *scanState, movementType string, response *dto.BarcodeScanResponse) error {
+	if state.batchNo != "" {
+		scanBatch := &dto.BarcodeScanBatch{BatchNo: state.batchNo, ExpiryDate: state.expiry}
+		batch, err := s.batchRepo.GetByBatchNo(ctx, item.ID, state.batchNo)
+		if err != nil {
+			return fmt.Errorf("获取批次失败: %w", err)
+		}
+		if batch != nil {
+			scanBatch.ID = batch.ID
+			scanBatch.Exists = true
+			scanBatch.ExpiryDate = batch.ExpiryDate
+			scanBatch.IsExpired = batch.IsExpired(time.Now())
+			if batch.ExpiryDate == nil {
+				scanBatch.ExpiryDate = state.expiry
+				scanBatch.IsExpired = state.expiry != nil && state.expiry.Before(models.StartOfDay(time.Now()))
+			}
+			if state.expiry != nil && batch.ExpiryDate != nil && !models.StartOfDay(*state.expiry).Equal(models.StartOfDay(*batch.ExpiryDate)) {
+				response.Warnings = append(response.Warnings, fmt.Sprintf("条码有效期 %s 与批次 %s 的有效期 %s 不一致",
+					state.expiry.Format("2006-01-02"), batch.BatchNo, batch.ExpiryDate.Format("2006-01-02")))
+			}
+		} else {
+			scanBatch.IsExpired = state.expiry != nil && state.expiry.Before(models.StartOfDay(time.Now()))
+			if movementType == models.MovementTypeIn {
+				response.Warnings = append(response.Warnings, fmt.Sprintf("批次 %s 尚未登记，入库时自动创建", state.batchNo))
+			} else {
+				response.Warnings = append(response.Warnings, fmt.Sprintf("批次 %s 不存在", state.batchNo))
+			}
+		}
+		if scanBatch.IsExpired {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("批次 %s 已过期", state.batchNo))
+		}
+		if item.TrackingMode == models.TrackingModeNone {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("物料 %s 未启用批次管理，批次号将被忽略", item.Code))
+		}
+		response.Batch = scanBatch
+	} else if item.TrackingMode == models.TrackingModeBatch && movementType == models.MovementTypeIn {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("物料 %s 启用批次管理，入库需要批次号", item.Code))
+	}
+
+	if state.serialNo == "" {
+		if item.TrackingMode == models.TrackingModeSerial {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("物料 %s 启用序列号管理，需要扫描序列号", item.Code))
+		}
+		return nil
+	}
+	if response.StockQuantity > 1+stockQuantityTolerance {
+		response.Warnings = append(response.Warnings, "扫描到序列号时数量应为 1")
+	}
+	serials, err := s.batchRepo.GetSerialNumbers(ctx, item.ID, []string{state.serialNo})
+	if err != nil {
+		return fmt.Errorf("获取序列号失败: %w", err)
+	}
+	inStock := len(serials) > 0 && serials[0].Status == models.SerialStatusInStock
+	switch {
+	case movementType == models.MovementTypeIn && inStock:
+		response.Warnings = append(response.Warnings, fmt.Sprintf("序列号 %s 已在库", state.serialNo))
+	case movementType == models.MovementTypeOut && !inStock:
+		response.Warnings = append(response.Warnings, fmt.Sprintf("序列号 %s 不在库", state.serialNo))
+	}
+	return nil
+}
+
+// GenerateLabels 生成物料或库位标签，格式为 ZPL 或 PDF。
+// 物料标签使用主条码（EAN-13/UPC-A 按原码制，其余按 Code 128），没有登记条码时以物料编码生成 Code 128；
+// 指定批次且条码为 GTIN 时打印包含 GTIN、有效期与批次的 GS1-128 条码。库位标签以库位编码生成 Code 128
+func (s *BarcodeServiceImpl) GenerateLabels(ctx context.Context, req *dto.BarcodeLabelRequest) ([]byte, error) {
+	var labels []utils.Label
+	var err error
+	switch req.Type {
+	case "location":
+		labels, err = s.locationLabels(ctx, req.IDs)
+	default:
+		labels, err = s.itemLabels(ctx, req.IDs, strings.TrimSpace(req.BatchNo))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Format == "pdf" {
+		return utils.RenderLabelPDF(labels, req.Copies)
+	}
+	return utils.RenderZPL(labels, req.Copies), nil
+}
+
+// itemLabels 生成物料标签
+func (s *BarcodeServiceImpl) itemLabels(ctx context.Context, ids []uint, batchNo string) ([]utils.Label, error) {
+	items, err := s.barcodeRepo.GetItemsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料失败: %w", err)
+	}
+	if len(items) != len(uniqueIDs(ids)) {
+		return nil, errors.New("部分物料不存在")
+	}
+	barcodes, err := s.barcodeRepo.GetPrimaryBarcodes(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料条码失败: %w", err)
+	}
+
+	labels := make([]utils.Label, 0, len(items))
+	for _, item := range items {
+		label := utils.Label{
+			Title:     item.Name,
+			Lines:     []string{item.Code},
+			Symbology: utils.LabelSymbologyCode128,
+			Barcode:   item.Code,
+		}
+		barcode := barcodes[item.ID]
+		if barcode != nil {
+			label.Barcode = barcode.Barcode
+			if barcode.UOM != "" && barcode.UOM != item.Unit {
+				label.Lines = append(label.Lines, "单位: "+barcode.UOM)
+			}
+			if barcode.BarcodeType == models.BarcodeTypeEAN13 || barcode.BarcodeType == models.BarcodeTypeUPCA {
+				label.Symbology = utils.LabelSymbologyEAN13
+			}
+		}
+
+		if batchNo != "" {
+			batch, err := s.batchRepo.GetByBatchNo(ctx, item.ID, batchNo)
+			if err != nil {
+				return nil, fmt.Errorf("获取批次失败: %w", err)
+			}
+			if batch == nil {
+				return nil, fmt.Errorf("物料 %s 的批次 %s 不存在", item.Code, batchNo)
+			}
+			label.Lines = append(label.Lines, "批次: "+batch.BatchNo)
+			if batch.ExpiryDate != nil {
+				label.Lines = append(label.Lines, "有效期: "+batch.ExpiryDate.Format("2006-01-02"))
+			}
+			if barcode != nil && barcode.GTIN != "" {
+				elements := []utils.GS1Element{{AI: "01", Value: barcode.GTIN}}
+				if batch.ExpiryDate != nil {
+					elements = append(elements, utils.GS1Element{AI: "17", Value: batch.ExpiryDate.Format("060102")})
+				}
+				elements = append(elements, utils.GS1Element{AI: "10", Value: batch.BatchNo})
+				label.Symbology = utils.LabelSymbologyGS1128
+				label.Barcode = utils.EncodeGS1(elements)
+				label.Text = utils.FormatGS1(elements)
+			}
+		}
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+// locationLabels 生成库位标签
+func (s *BarcodeServiceImpl) locationLabels(ctx context.Context, ids []uint) ([]utils.Label, error) {
+	locations, err := s.barcodeRepo.GetLocationsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("获取库位失败: %w", err)
+	}
+	if len(locations) != len(uniqueIDs(ids)) {
+		return nil, errors.New("部分库位不存在")
+	}
+
+	labels := make([]utils.Label, 0, len(locations))
+	for _, location := range locations {
+		lines := []string{location.Warehouse.Name}
+		if location.Zone != "" {
+			lines = append(lines, "库区: "+location.Zone)
+		}
+		labels = append(labels, utils.Label{
+			Title:     location.Code,
+			Lines:     append(lines, location.Name),
+			Symbology: utils.LabelSymbologyCode128,
+			Barcode:   location.Code,
+		})
+	}
+	return labels, nil
+}
+
+// detectBarcodeType 按条码内容识别条码类型：8、12、13、14 位数字视为 GTIN 并校验校验位，其余为 Code 128
+func detectBarcodeType(code string) (string, error) {
+	barcodeType := models.BarcodeTypeGTIN14
+	switch len(code) {
+	case 8:
+		barcodeType = models.BarcodeTypeEAN8
+	case 12:
+		barcodeType = models.BarcodeTypeUPCA
+	case 13:
+		barcodeType = models.BarcodeTypeEAN13
+	case 14:
+	default:
+		return models.BarcodeTypeCode128, nil
+	}
+	if strings.Trim(code, "0123456789") != "" {
+		return models.BarcodeTypeCode128, nil
+	}
+	if !utils.ValidGTIN(code) {
+		return "", fmt.Errorf("条码 %s 校验位错误，应为 %c", code, utils.GTINCheckDigit(code[:len(code)-1]))
+	}
+	return barcodeType, nil
+}
+
+// gtinLength 返回 GTIN 类条码的位数
+func gtinLength(barcodeType string) int {
+	switch barcodeType {
+	case models.BarcodeTypeEAN8:
+		return 8
+	case models.BarcodeTypeUPCA:
+		return 12
+	case models.BarcodeTypeEAN13:
+		return 13
+	default:
+		return 14
+	}
+}
+
+// displayUOM 返回条码的单位，为空时为物料的库存单位
+func displayUOM(uom string, item *models.Item) string {
+	if uom == "" {
+		return item.Unit
+	}
+	return uom
+}
+
+// uniqueIDs 去除重复的ID
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// toItemBarcodeResponse 转换物料条码响应
+func toItemBarcodeResponse(barcode *models.ItemBarcode) dto.ItemBarcodeResponse {
+	return dto.ItemBarcodeResponse{
+		ID:          barcode.ID,
+		ItemID:      barcode.ItemID,
+		Barcode:     barcode.Barcode,
+		GTIN:        barcode.GTIN,
+		BarcodeType: barcode.BarcodeType,
+		UOM:         barcode.UOM,
+		IsPrimary:   barcode.IsPrimary,
+		Description: barcode.Description,
+		CreatedAt:   barcode.CreatedAt,
+	}
+}
@@ -0,0 +1,600 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+)
+
+// 追溯方向
+const (
+	TraceDirectionForward  = "forward"
+	TraceDirectionBackward = "backward"
+	TraceDirectionBoth     = "both"
+)
+
+// DefaultNearExpiryDays 近效期报表未指定天数时的默认预警天数
+const DefaultNearExpiryDays = 30
+
+// 近效期报表行状态
+const (
+	ExpiryStatusExpired    = "expired"
+	ExpiryStatusNearExpiry = "near_expiry"
+)
+
+// maxTraceDepth 追溯经过生产订单的最大层数，防止异常数据形成环路
+const maxTraceDepth = 20
+
+// BatchTrackingService 批次与序列号跟踪服务接口
+type BatchTrackingService interface {
+	CreateBatch(ctx context.Context, req *dto.BatchCreateRequest) (*dto.BatchResponse, error)
+	GetBatch(ctx context.Context, id uint) (*dto.BatchResponse, error)
+	UpdateBatch(ctx context.Context, id uint, req *dto.BatchUpdateRequest) (*dto.BatchResponse, error)
+	ListBatches(ctx context.Context, req *dto.BatchListRequest) ([]dto.BatchResponse, int64, error)
+	GetBatchStock(ctx context.Context, batchID uint, req *dto.BatchStockRequest) (*dto.BatchStockResponse, error)
+	GetExpiryReport(ctx context.Context, req *dto.ExpiryReportRequest) (*dto.ExpiryReportResponse, error)
+	ListSerialNumbers(ctx context.Context, req *dto.SerialNumberListRequest) ([]dto.SerialNumberResponse, int64, error)
+	TraceBatch(ctx context.Context, id uint, req *dto.TraceRequest) (*dto.TraceResponse, error)
+	TraceSerialNumber(ctx context.Context, id uint, req *dto.TraceRequest) (*dto.TraceResponse, error)
+}
+
+// BatchTrackingServiceImpl 批次与序列号跟踪服务实现
+type BatchTrackingServiceImpl struct {
+	batchRepo repositories.BatchRepository
+	itemRepo  repositories.ItemRepository
+}
+
+// NewBatchTrackingService 创建批次跟踪服务实例
+func NewBatchTrackingService(batchRepo repositories.BatchRepository, itemRepo repositories.ItemRepository) BatchTrackingService {
+	return &BatchTrackingServiceImpl{
+		batchRepo: batchRepo,
+		itemRepo:  itemRepo,
+	}
+}
+
+// CreateBatch 创建批次，物料须启用批次或序列号跟踪
+func (s *BatchTrackingServiceImpl) CreateBatch(ctx context.Context, req *dto.BatchCreateRequest) (*dto.BatchResponse, error) {
+	item, err := s.itemRepo.GetByID(ctx, req.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("物料 %d 不存在", req.ItemID)
+	}
+	if item.TrackingMode != models.TrackingModeBatch && item.TrackingMode != models.TrackingModeSerial {
+		return nil, fmt.Errorf("物料 %s 未启用批次跟踪", item.Code)
+	}
+	if req.ManufactureDate != nil && req.ExpiryDate != nil && req.ExpiryDate.Before(*req.ManufactureDate) {
+		return nil, errors.New("有效期不能早于生产日期")
+	}
+
+	existing, err := s.batchRepo.GetByBatchNo(ctx, req.ItemID, req.BatchNo)
+	if err != nil {
+		return nil, fmt.Errorf("检查批次号失败: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("物料 %s 的批次号 %s 已存在", item.Code, req.BatchNo)
+	}
+
+	batch := &models.Batch{
+		ItemID:          req.ItemID,
+		BatchNo:         req.BatchNo,
+		ManufactureDate: req.ManufactureDate,
+		ExpiryDate:      req.ExpiryDate,
+		SupplierID:      req.SupplierID,
+		SupplierLotNo:   req.SupplierLotNo,
+		Notes:           req.Notes,
+		IsActive:        true,
+	}
+	if err := s.batchRepo.Create(ctx, batch); err != nil {
+		return nil, fmt.Errorf("创建批次失败: %w", err)
+	}
+	batch.Item = item
+
+	return toBatchResponse(batch), nil
+}
+
+// GetBatch 获取批次详情
+func (s *BatchTrackingServiceImpl) GetBatch(ctx context.Context, id uint) (*dto.BatchResponse, error) {
+	batch, err := s.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toBatchResponse(batch), nil
+}
+
+// UpdateBatch 更新批次的日期、供应商批号等主数据
+func (s *BatchTrackingServiceImpl) UpdateBatch(ctx context.Context, id uint, req *dto.BatchUpdateRequest) (*dto.BatchResponse, error) {
+	batch, err := s.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ManufactureDate != nil {
+		batch.ManufactureDate = req.ManufactureDate
+	}
+	if req.ExpiryDate != nil {
+		batch.ExpiryDate = req.ExpiryDate
+	}
+	if batch.ManufactureDate != nil && batch.ExpiryDate != nil && batch.ExpiryDate.Before(*batch.ManufactureDate) {
+		return nil, errors.New("有效期不能早于生产日期")
+	}
+	if req.SupplierID != nil {
+		batch.SupplierID = req.SupplierID
+	}
+	if req.SupplierLotNo != nil {
+		batch.SupplierLotNo = *req.SupplierLotNo
+	}
+	if req.Notes != nil {
+		batch.Notes = *req.Notes
+	}
+	if req.IsActive != nil {
+		batch.IsActive = *req.IsActive
+	}
+
+	item := batch.Item
+	batch.Item = nil
+	if err := s.batchRepo.Update(ctx, batch); err != nil {
+		return nil, fmt.Errorf("更新批次失败: %w", err)
+	}
+	batch.Item = item
+
+	return toBatchResponse(batch), nil
+}
+
+// ListBatches 分页获取批次
+func (s *BatchTrackingServiceImpl) ListBatches(ctx context.Context, req *dto.BatchListRequest) ([]dto.BatchResponse, int64, error) {
+	filter := repositories.BatchFilter{
+		ItemID:  req.ItemID,
+		BatchNo: req.BatchNo,
+	}
+	batches, total, err := s.batchRepo.ListBatches(ctx, filter, req.GetOffset(), req.GetLimit())
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取批次列表失败: %w", err)
+	}
+
+	responses := make([]dto.BatchResponse, 0, len(batches))
+	for _, batch := range batches {
+		responses = append(responses, *toBatchResponse(batch))
+	}
+	return responses, total, nil
+}
+
+// GetBatchStock 获取批次在各仓库的库存，batchID 为 0 时按请求条件查询全部批次
+func (s *BatchTrackingServiceImpl) GetBatchStock(ctx context.Context, batchID uint, req *dto.BatchStockRequest) (*dto.BatchStockResponse, error) {
+	if batchID != 0 {
+		if _, err := s.getBatch(ctx, batchID); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.batchRepo.GetBatchStocks(ctx, repositories.BatchStockFilter{
+		ItemID:      req.ItemID,
+		WarehouseID: req.WarehouseID,
+		BatchID:     batchID,
+		IncludeZero: req.IncludeZero,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取批次库存失败: %w", err)
+	}
+
+	response := &dto.BatchStockResponse{Lines: make([]dto.BatchStockLine, 0, len(rows))}
+	for _, row := range rows {
+		response.Lines = append(response.Lines, dto.BatchStockLine{
+			ItemID:        row.ItemID,
+			ItemCode:      row.ItemCode,
+			ItemName:      row.ItemName,
+			WarehouseID:   row.WarehouseID,
+			WarehouseCode: row.WarehouseCode,
+			WarehouseName: row.WarehouseName,
+			BatchID:       row.BatchID,
+			BatchNo:       row.BatchNo,
+			ExpiryDate:    row.ExpiryDate,
+			Quantity:      row.Quantity,
+		})
+		response.TotalQuantity += row.Quantity
+	}
+	return response, nil
+}
+
+// GetExpiryReport 获取已过期及在预警天数内到期的批次库存，按有效期先后排列
+func (s *BatchTrackingServiceImpl) GetExpiryReport(ctx context.Context, req *dto.ExpiryReportRequest) (*dto.ExpiryReportResponse, error) {
+	days := req.Days
+	if days <= 0 {
+		days = DefaultNearExpiryDays
+	}
+	today := models.StartOfDay(time.Now())
+	// 第 days 天当天到期的批次也列入报表
+	horizon := today.AddDate(0, 0, days+1)
+
+	rows, err := s.batchRepo.GetBatchStocks(ctx, repositories.BatchStockFilter{
+		ItemID:       req.ItemID,
+		WarehouseID:  req.WarehouseID,
+		ExpiryBefore: &horizon,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取批次库存失败: %w", err)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].ExpiryDate.Before(*rows[j].ExpiryDate)
+	})
+
+	response := &dto.ExpiryReportResponse{AsOf: today, Days: days, Lines: make([]dto.ExpiryReportLine, 0, len(rows))}
+	for _, row := range rows {
+		line := dto.ExpiryReportLine{
+			BatchStockLine: dto.BatchStockLine{
+				ItemID:        row.ItemID,
+				ItemCode:      row.ItemCode,
+				ItemName:      row.ItemName,
+				WarehouseID:   row.WarehouseID,
+				WarehouseCode: row.WarehouseCode,
+				WarehouseName: row.WarehouseName,
+				BatchID:       row.BatchID,
+				BatchNo:       row.BatchNo,
+				ExpiryDate:    row.ExpiryDate,
+				Quantity:      row.Quantity,
+			},
+			DaysToExpiry: int(models.StartOfDay(*row.ExpiryDate).Sub(today).Hours() / 24),
+			Status:       ExpiryStatusNearExpiry,
+		}
+		if line.DaysToExpiry < 0 {
+			if req.ExcludeExpired {
+				continue
+			}
+			line.Status = ExpiryStatusExpired
+			response.ExpiredQuantity += row.Quantity
+		} else {
+			response.NearExpiryQuantity += row.Quantity
+		}
+		response.Lines = append(response.Lines, line)
+	}
+	return response, nil
+}
+
+// ListSerialNumbers 分页获取序列号
+func (s *BatchTrackingServiceImpl) ListSerialNumbers(ctx context.Context, req *dto.SerialNumberListRequest) ([]dto.SerialNumberResponse, int64, error) {
+	filter := repositories.SerialNumberFilter{
+		ItemID:      req.ItemID,
+		WarehouseID: req.WarehouseID,
+		BatchID:     req.BatchID,
+		Status:      req.Status,
+		SerialNo:    req.SerialNo,
+	}
+	serials, total, err := s.batchRepo.ListSerialNumbers(ctx, filter, req.GetOffset(), req.GetLimit())
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取序列号列表失败: %w", err)
+	}
+
+	responses := make([]dto.SerialNumberResponse, 0, len(serials))
+	for _, serial := range serials {
+		responses = append(responses, *toSerialNumberResponse(serial))
+	}
+	return responses, total, nil
+}
+
+// TraceBatch 追溯批次：正向经生产订单追到产出批次及客户送货，反向经生产订单追到投料批次及采购入库
+func (s *BatchTrackingServiceImpl) TraceBatch(ctx context.Context, id uint, req *dto.TraceRequest) (*dto.TraceResponse, error) {
+	batch, err := s.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer := newBatchTracer(s.batchRepo)
+	tracer.visit(batch.ID, batch.BatchNo, batch.ItemID, itemCode(batch.Item), 0)
+	rows, err := s.batchRepo.GetBatchMovements(ctx, []uint{batch.ID})
+	if err != nil {
+		return nil, fmt.Errorf("获取批次库存移动失败: %w", err)
+	}
+	tracer.addMovements(rows)
+
+	direction := traceDirection(req)
+	if err := tracer.expand(ctx, rows, direction); err != nil {
+		return nil, err
+	}
+
+	response := tracer.response(direction)
+	response.Batch = toBatchResponse(batch)
+	return response, nil
+}
+
+// TraceSerialNumber 追溯序列号经历的入库、生产与发货单据
+func (s *BatchTrackingServiceImpl) TraceSerialNumber(ctx context.Context, id uint, req *dto.TraceRequest) (*dto.TraceResponse, error) {
+	serial, err := s.batchRepo.GetSerialNumber(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取序列号失败: %w", err)
+	}
+	if serial == nil {
+		return nil, errors.New("序列号不存在")
+	}
+
+	tracer := newBatchTracer(s.batchRepo)
+	if serial.Batch != nil {
+		tracer.visit(serial.Batch.ID, serial.Batch.BatchNo, serial.ItemID, itemCode(serial.Item), 0)
+	}
+	rows, err := s.batchRepo.GetSerialMovements(ctx, serial.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取序列号库存移动失败: %w", err)
+	}
+	tracer.addMovements(rows)
+
+	direction := traceDirection(req)
+	if err := tracer.expand(ctx, rows, direction); err != nil {
+		return nil, err
+	}
+
+	response := tracer.response(direction)
+	response.SerialNumber = toSerialNumberResponse(serial)
+	return response, nil
+}
+
+// getBatch 获取批次及物料
+func (s *BatchTrackingServiceImpl) getBatch(ctx context.Context, id uint) (*models.Batch, error) {
+	batch, err := s.batchRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("批次不存在")
+	}
+	if item, err := s.itemRepo.GetByID(ctx, batch.ItemID); err == nil {
+		batch.Item = item
+	}
+	return batch, nil
+}
+
+// batchTracer 按批次经生产订单逐层展开追溯链路
+type batchTracer struct {
+	batchRepo repositories.BatchRepository
+	batches   map[uint]*dto.TraceBatch
+	movements map[uint]repositories.TraceMovementRow
+}
+
+func newBatchTracer(batchRepo repositories.BatchRepository) *batchTracer {
+	return &batchTracer{
+		batchRepo: batchRepo,
+		batches:   make(map[uint]*dto.TraceBatch),
+		movements: make(map[uint]repositories.TraceMovementRow),
+	}
+}
+
+// visit 登记批次，已登记时返回 false
+func (t *batchTracer) visit(batchID uint, batchNo string, itemID uint, code string, level int) bool {
+	if _, ok := t.batches[batchID]; ok {
+		return false
+	}
+	t.batches[batchID] = &dto.TraceBatch{
+		BatchID:  batchID,
+		BatchNo:  batchNo,
+		ItemID:   itemID,
+		ItemCode: code,
+		Level:    level,
+	}
+	return true
+}
+
+func (t *batchTracer) addMovements(rows []repositories.TraceMovementRow) {
+	for _, row := range rows {
+		t.movements[row.MovementID] = row
+	}
+}
+
+// expand 从起点移动出发按方向展开：正向取领用起点的生产订单及其产出批次，反向取产出起点的生产订单及其投料批次
+func (t *batchTracer) expand(ctx context.Context, seed []repositories.TraceMovementRow, direction string) error {
+	if direction != TraceDirectionBackward {
+		if err := t.expandDirection(ctx, seed, true); err != nil {
+			return err
+		}
+	}
+	if direction != TraceDirectionForward {
+		if err := t.expandDirection(ctx, seed, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *batchTracer) expandDirection(ctx context.Context, rows []repositories.TraceMovementRow, forward bool) error {
+	level := 0
+	for depth := 0; depth < maxTraceDepth; depth++ {
+		orderIDs := make([]uint, 0)
+		seen := make(map[uint]bool)
+		for _, row := range rows {
+			if row.ReferenceType != models.MovementReferenceProductionOrder || row.ReferenceID == nil || seen[*row.ReferenceID] {
+				continue
+			}
+			if (forward && row.QuantityChange < 0) || (!forward && row.QuantityChange > 0) {
+				seen[*row.ReferenceID] = true
+				orderIDs = append(orderIDs, *row.ReferenceID)
+			}
+		}
+		if len(orderIDs) == 0 {
+			return nil
+		}
+
+		orderRows, err := t.batchRepo.GetReferenceMovements(ctx, models.MovementReferenceProductionOrder, orderIDs)
+		if err != nil {
+			return fmt.Errorf("获取生产订单库存移动失败: %w", err)
+		}
+		if forward {
+			level++
+		} else {
+			level--
+		}
+		next := make([]uint, 0)
+		for _, row := range orderRows {
+			if row.BatchID == nil {
+				continue
+			}
+			if (forward && row.QuantityChange > 0) || (!forward && row.QuantityChange < 0) {
+				if t.visit(*row.BatchID, row.BatchNo, row.ItemID, row.ItemCode, level) {
+					next = append(next, *row.BatchID)
+				}
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+
+		rows, err = t.batchRepo.GetBatchMovements(ctx, next)
+		if err != nil {
+			return fmt.Errorf("获取批次库存移动失败: %w", err)
+		}
+		t.addMovements(rows)
+	}
+	return nil
+}
+
+// response 汇总追溯涉及的批次、单据与库存移动
+func (t *batchTracer) response(direction string) *dto.TraceResponse {
+	response := &dto.TraceResponse{
+		Direction:        direction,
+		Batches:          make([]dto.TraceBatch, 0, len(t.batches)),
+		Receipts:         make([]dto.TraceDocument, 0),
+		ProductionOrders: make([]dto.TraceDocument, 0),
+		Deliveries:       make([]dto.TraceDocument, 0),
+		Movements:        make([]dto.TraceMovement, 0, len(t.movements)),
+	}
+	for _, batch := range t.batches {
+		response.Batches = append(response.Batches, *batch)
+	}
+	sort.Slice(response.Batches, func(i, j int) bool {
+		if response.Batches[i].Level != response.Batches[j].Level {
+			return response.Batches[i].Level < response.Batches[j].Level
+		}
+		return response.Batches[i].BatchNo < response.Batches[j].BatchNo
+	})
+
+	for _, row := range t.movements {
+		response.Movements = append(response.Movements, dto.TraceMovement{
+			MovementID:      row.MovementID,
+			ItemID:          row.ItemID,
+			ItemCode:        row.ItemCode,
+			WarehouseID:     row.WarehouseID,
+			BatchID:         row.BatchID,
+			BatchNo:         row.BatchNo,
+			SerialNo:        row.SerialNo,
+			MovementType:    row.MovementType,
+			QuantityChange:  row.QuantityChange,
+			ReferenceType:   row.ReferenceType,
+			ReferenceID:     row.ReferenceID,
+			ReferenceNumber: row.ReferenceNumber,
+			PartyID:         row.PartyID,
+			PartyName:       row.PartyName,
+			CreatedAt:       row.CreatedAt,
+		})
+	}
+	sort.Slice(response.Movements, func(i, j int) bool {
+		return response.Movements[i].MovementID < response.Movements[j].MovementID
+	})
+
+	documents := make(map[string]*dto.TraceDocument)
+	order := make([]string, 0)
+	for _, movement := range response.Movements {
+		if movement.ReferenceID == nil {
+			continue
+		}
+		switch movement.ReferenceType {
+		case models.MovementReferencePurchaseReceipt, models.MovementReferenceProductionOrder, models.MovementReferenceDeliveryNote:
+		default:
+			continue
+		}
+		key := fmt.Sprintf("%s:%d", movement.ReferenceType, *movement.ReferenceID)
+		document, ok := documents[key]
+		if !ok {
+			document = &dto.TraceDocument{
+				ReferenceType:   movement.ReferenceType,
+				ReferenceID:     *movement.ReferenceID,
+				ReferenceNumber: movement.ReferenceNumber,
+				PartyID:         movement.PartyID,
+				PartyName:       movement.PartyName,
+				BatchNos:        make([]string, 0),
+			}
+			documents[key] = document
+			order = append(order, key)
+		}
+		if movement.BatchNo != "" && !containsString(document.BatchNos, movement.BatchNo) {
+			document.BatchNos = append(document.BatchNos, movement.BatchNo)
+		}
+	}
+	for _, key := range order {
+		document := documents[key]
+		switch document.ReferenceType {
+		case models.MovementReferencePurchaseReceipt:
+			response.Receipts = append(response.Receipts, *document)
+		case models.MovementReferenceProductionOrder:
+			response.ProductionOrders = append(response.ProductionOrders, *document)
+		case models.MovementReferenceDeliveryNote:
+			response.Deliveries = append(response.Deliveries, *document)
+		}
+	}
+	return response
+}
+
+func traceDirection(req *dto.TraceRequest) string {
+	if req == nil || req.Direction == "" {
+		return TraceDirectionBoth
+	}
+	return req.Direction
+}
+
+func itemCode(item *models.Item) string {
+	if item == nil {
+		return ""
+	}
+	return item.Code
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func toBatchResponse(batch *models.Batch) *dto.BatchResponse {
+	response := &dto.BatchResponse{
+		ID:              batch.ID,
+		ItemID:          batch.ItemID,
+		BatchNo:         batch.BatchNo,
+		ManufactureDate: batch.ManufactureDate,
+		ExpiryDate:      batch.ExpiryDate,
+		SupplierID:      batch.SupplierID,
+		SupplierLotNo:   batch.SupplierLotNo,
+		Notes:           batch.Notes,
+		IsActive:        batch.IsActive,
+		CreatedAt:       batch.CreatedAt,
+		UpdatedAt:       batch.UpdatedAt,
+	}
+	if batch.Item != nil {
+		response.ItemCode = batch.Item.Code
+		response.ItemName = batch.Item.Name
+	}
+	return response
+}
+
+func toSerialNumberResponse(serial *models.SerialNumber) *dto.SerialNumberResponse {
+	response := &dto.SerialNumberResponse{
+		ID:              serial.ID,
+		ItemID:          serial.ItemID,
+		SerialNo:        serial.SerialNo,
+		BatchID:         serial.BatchID,
+		WarehouseID:     serial.WarehouseID,
+		Status:          serial.Status,
+		ManufactureDate: serial.ManufactureDate,
+		ExpiryDate:      serial.ExpiryDate,
+		SupplierID:      serial.SupplierID,
+		LastMovementID:  serial.LastMovementID,
+		CreatedAt:       serial.CreatedAt,
+		UpdatedAt:       serial.UpdatedAt,
+	}
+	if serial.Item != nil {
+		response.ItemCode = serial.Item.Code
+		response.ItemName = serial.Item.Name
+	}
+	if serial.Batch != nil {
+		response.BatchNo = serial.Batch.BatchNo
+	}
+	return response
+}
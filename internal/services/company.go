@@ -0,0 +1,440 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/common"
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// CompanyService 公司服务接口
+type CompanyService interface {
+	CreateCompany(ctx context.Context, req *dto.CompanyCreateRequest, userID uint) (*dto.CompanyResponse, error)
+	GetCompany(ctx context.Context, id uint) (*dto.CompanyResponse, error)
+	UpdateCompany(ctx context.Context, id uint, req *dto.CompanyUpdateRequest, userID uint) (*dto.CompanyResponse, error)
+	DeleteCompany(ctx context.Context, id uint) error
+	ListCompanies(ctx context.Context, req *dto.PaginationRequest) (*dto.PaginatedResponse[dto.CompanyResponse], error)
+	EnsureDefaultCompany(ctx context.Context) error
+	CreateFiscalYear(ctx context.Context, companyID uint, req *dto.FiscalYearCreateRequest) (*dto.FiscalYearResponse, error)
+	ListFiscalYears(ctx context.Context, companyID uint) ([]dto.FiscalYearResponse, error)
+	SetPeriodClosed(ctx context.Context, periodID uint, closed bool, userID uint) (*dto.AccountingPeriodResponse, error)
+}
+
+// CompanyServiceImpl 公司服务实现
+type CompanyServiceImpl struct {
+	companyRepo repositories.CompanyRepository
+}
+
+// NewCompanyService 创建公司服务实例
+func NewCompanyService(companyRepo repositories.CompanyRepository) CompanyService {
+	return &CompanyServiceImpl{
+		companyRepo: companyRepo,
+	}
+}
+
+// CreateCompany 创建公司
+func (s *CompanyServiceImpl) CreateCompany(ctx context.Context, req *dto.CompanyCreateRequest, userID uint) (*dto.CompanyResponse, error) {
+	existing, err := s.companyRepo.GetByCode(ctx, req.Code)
+	if err != nil {
+		return nil, fmt.Errorf("检查公司编码失败: %w", err)
+	}
+	if existing != nil {
+		return nil, errors.New("公司编码已存在")
+	}
+
+	if req.ParentID != nil {
+		if _, err := s.companyRepo.GetByID(ctx, *req.ParentID); err != nil {
+			return nil, errors.New("上级公司不存在")
+		}
+	}
+
+	company := &models.Company{
+		Address:         req.Address,
+		Phone:           req.Phone,
+		Email:           req.Email,
+		ParentID:        req.ParentID,
+		DefaultCurrency: req.DefaultCurrency,
+	}
+	company.Code = req.Code
+	company.Name = req.Name
+	company.Description = req.Description
+	company.IsActive = true
+	company.CreatedBy = userID
+	company.UpdatedBy = userID
+	if company.DefaultCurrency == "" {
+		company.DefaultCurrency = models.DefaultCurrency
+	}
+
+	if err := s.companyRepo.Create(ctx, company); err != nil {
+		return nil, fmt.Errorf("创建公司失败: %w", err)
+	}
+
+	utils.Info("公司创建成功",
+		utils.Uint("company_id", company.ID),
+		utils.String("code", company.Code),
+		utils.Uint("created_by", userID),
+	)
+
+	return s.convertToCompanyResponse(company), nil
+}
+
+// GetCompany 获取公司
+func (s *CompanyServiceImpl) GetCompany(ctx context.Context, id uint) (*dto.CompanyResponse, error) {
+	company, err := s.companyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("公司不存在")
+	}
+	return s.convertToCompanyResponse(company), nil
+}
+
+// UpdateCompany 更新公司
+func (s *CompanyServiceImpl) UpdateCompany(ctx context.Context, id uint, req *dto.CompanyUpdateRequest, userID uint) (*dto.CompanyResponse, error) {
+	company, err := s.companyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("公司不存在")
+	}
+
+	if req.ParentID != nil {
+		if err := s.validateParent(ctx, id, *req.ParentID); err != nil {
+			return nil, err
+		}
+		company.ParentID = req.ParentID
+	}
+	if req.Name != nil {
+		company.Name = *req.Name
+	}
+	if req.Description != nil {
+		company.Description = *req.Description
+	}
+	if req.Address != nil {
+		company.Address = *req.Address
+	}
+	if req.Phone != nil {
+		company.Phone = *req.Phone
+	}
+	if req.Email != nil {
+		company.Email = *req.Email
+	}
+	if req.DefaultCurrency != nil {
+		company.DefaultCurrency = *req.DefaultCurrency
+	}
+	if req.IsActive != nil {
+		if !*req.IsActive && company.ID == models.DefaultCompanyID {
+			return nil, errors.New("默认公司不能停用")
+		}
+		company.IsActive = *req.IsActive
+	}
+	company.UpdatedBy = userID
+
+	if err := s.companyRepo.Update(ctx, company); err != nil {
+		return nil, fmt.Errorf("更新公司失败: %w", err)
+	}
+
+	return s.convertToCompanyResponse(company), nil
+}
+
+// DeleteCompany 删除公司，存在科目、凭证或下级公司时不允许删除
+func (s *CompanyServiceImpl) DeleteCompany(ctx context.Context, id uint) error {
+	if id == models.DefaultCompanyID {
+		return errors.New("默认公司不能删除")
+	}
+	if _, err := s.companyRepo.GetByID(ctx, id); err != nil {
+		return errors.New("公司不存在")
+	}
+
+	dependents, err := s.companyRepo.CountDependents(ctx, id)
+	if err != nil {
+		return fmt.Errorf("检查公司数据失败: %w", err)
+	}
+	if dependents > 0 {
+		return errors.New("公司存在科目或凭证，无法删除")
+	}
+
+	companies, err := s.companyRepo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("检查下级公司失败: %w", err)
+	}
+	for _, company := range companies {
+		if company.ParentID != nil && *company.ParentID == id {
+			return errors.New("存在下级公司，无法删除")
+		}
+	}
+
+	return s.companyRepo.Delete(ctx, id)
+}
+
+// ListCompanies 获取公司列表
+func (s *CompanyServiceImpl) ListCompanies(ctx context.Context, req *dto.PaginationRequest) (*dto.PaginatedResponse[dto.CompanyResponse], error) {
+	options := &common.QueryOptions{
+		Pagination: req,
+		Sorts:      []common.SortCondition{{Field: "id", Order: common.SortOrderAsc}},
+	}
+
+	companies, total, err := s.companyRepo.List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("获取公司列表失败: %w", err)
+	}
+
+	responses := make([]dto.CompanyResponse, len(companies))
+	for i, company := range companies {
+		responses[i] = *s.convertToCompanyResponse(company)
+	}
+
+	limit := req.GetLimit()
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	return &dto.PaginatedResponse[dto.CompanyResponse]{
+		Data:       responses,
+		Total:      total,
+		Page:       req.Page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// EnsureDefaultCompany 系统中没有任何公司时创建默认公司，使历史数据有所归属
+func (s *CompanyServiceImpl) EnsureDefaultCompany(ctx context.Context) error {
+	count, err := s.companyRepo.CountAll(ctx)
+	if err != nil {
+		return fmt.Errorf("检查公司数据失败: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	company := &models.Company{DefaultCurrency: models.DefaultCurrency}
+	company.Code = "DEFAULT"
+	company.Name = "默认公司"
+	company.IsActive = true
+	if err := s.companyRepo.Create(ctx, company); err != nil {
+		return fmt.Errorf("创建默认公司失败: %w", err)
+	}
+
+	utils.Info("已创建默认公司", utils.Uint("company_id", company.ID))
+	return nil
+}
+
+// CreateFiscalYear 创建公司财政年度，并按自然月生成会计期间
+func (s *CompanyServiceImpl) CreateFiscalYear(ctx context.Context, companyID uint, req *dto.FiscalYearCreateRequest) (*dto.FiscalYearResponse, error) {
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return nil, errors.New("公司不存在")
+	}
+
+	startDate := truncateToDay(req.StartDate)
+	endDate := truncateToDay(req.EndDate)
+	if !endDate.After(startDate) {
+		return nil, errors.New("结束日期必须晚于开始日期")
+	}
+	if endDate.After(startDate.AddDate(1, 0, 0)) {
+		return nil, errors.New("财政年度不能超过一年")
+	}
+
+	overlapping, err := s.companyRepo.GetOverlappingFiscalYear(ctx, companyID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("检查财政年度失败: %w", err)
+	}
+	if overlapping != nil {
+		return nil, fmt.Errorf("与财政年度 %d 的日期范围重叠", overlapping.Year)
+	}
+
+	fiscalYear := &models.FiscalYear{
+		CompanyID: companyID,
+		Year:      req.Year,
+		StartDate: startDate,
+		EndDate:   endDate,
+		IsCurrent: req.IsCurrent,
+		Status:    "active",
+		Periods:   buildMonthlyPeriods(companyID, req.Year, startDate, endDate),
+	}
+
+	if err := s.companyRepo.CreateFiscalYear(ctx, fiscalYear); err != nil {
+		return nil, fmt.Errorf("创建财政年度失败: %w", err)
+	}
+
+	return s.convertToFiscalYearResponse(fiscalYear), nil
+}
+
+// ListFiscalYears 获取公司财政年度及会计期间
+func (s *CompanyServiceImpl) ListFiscalYears(ctx context.Context, companyID uint) ([]dto.FiscalYearResponse, error) {
+	fiscalYears, err := s.companyRepo.GetFiscalYears(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("获取财政年度失败: %w", err)
+	}
+
+	responses := make([]dto.FiscalYearResponse, len(fiscalYears))
+	for i, fiscalYear := range fiscalYears {
+		responses[i] = *s.convertToFiscalYearResponse(fiscalYear)
+	}
+	return responses, nil
+}
+
+// SetPeriodClosed 关闭或重新打开会计期间，已关闭期间不允许过账
+func (s *CompanyServiceImpl) SetPeriodClosed(ctx context.Context, periodID uint, closed bool, userID uint) (*dto.AccountingPeriodResponse, error) {
+	period, err := s.companyRepo.GetPeriodByID(ctx, periodID)
+	if err != nil {
+		return nil, errors.New("会计期间不存在")
+	}
+
+	if period.IsClosed == closed {
+		if closed {
+			return nil, errors.New("会计期间已关闭")
+		}
+		return nil, errors.New("会计期间未关闭")
+	}
+
+	period.IsClosed = closed
+	if closed {
+		now := time.Now()
+		period.ClosedBy = &userID
+		period.ClosedAt = &now
+	} else {
+		period.ClosedBy = nil
+		period.ClosedAt = nil
+	}
+
+	if err := s.companyRepo.UpdatePeriod(ctx, period); err != nil {
+		return nil, fmt.Errorf("更新会计期间失败: %w", err)
+	}
+
+	return convertToAccountingPeriodResponse(period), nil
+}
+
+// validateParent 校验上级公司存在且不会形成循环
+func (s *CompanyServiceImpl) validateParent(ctx context.Context, companyID, parentID uint) error {
+	if parentID == companyID {
+		return errors.New("公司不能以自己作为上级公司")
+	}
+
+	current, err := s.companyRepo.GetByID(ctx, parentID)
+	if err != nil {
+		return errors.New("上级公司不存在")
+	}
+	for current.ParentID != nil {
+		if *current.ParentID == companyID {
+			return errors.New("不能形成循环的公司层级")
+		}
+		current, err = s.companyRepo.GetByID(ctx, *current.ParentID)
+		if err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// convertToCompanyResponse 转换公司响应
+func (s *CompanyServiceImpl) convertToCompanyResponse(company *models.Company) *dto.CompanyResponse {
+	return &dto.CompanyResponse{
+		ID:              company.ID,
+		Code:            company.Code,
+		Name:            company.Name,
+		Description:     company.Description,
+		Address:         company.Address,
+		Phone:           company.Phone,
+		Email:           company.Email,
+		ParentID:        company.ParentID,
+		DefaultCurrency: company.DefaultCurrency,
+		IsActive:        company.IsActive,
+		CreatedAt:       company.CreatedAt,
+		UpdatedAt:       company.UpdatedAt,
+	}
+}
+
+// convertToFiscalYearResponse 转换财政年度响应
+func (s *CompanyServiceImpl) convertToFiscalYearResponse(fiscalYear *models.FiscalYear) *dto.FiscalYearResponse {
+	response := &dto.FiscalYearResponse{
+		ID:        fiscalYear.ID,
+		CompanyID: fiscalYear.CompanyID,
+		Year:      fiscalYear.Year,
+		StartDate: fiscalYear.StartDate,
+		EndDate:   fiscalYear.EndDate,
+		IsCurrent: fiscalYear.IsCurrent,
+		Status:    fiscalYear.Status,
+		CreatedAt: fiscalYear.CreatedAt,
+	}
+	for i := range fiscalYear.Periods {
+		response.Periods = append(response.Periods, *convertToAccountingPeriodResponse(&fiscalYear.Periods[i]))
+	}
+	return response
+}
+
+// convertToAccountingPeriodResponse 转换会计期间响应
+func convertToAccountingPeriodResponse(period *models.AccountingPeriod) *dto.AccountingPeriodResponse {
+	return &dto.AccountingPeriodResponse{
+		ID:           period.ID,
+		CompanyID:    period.CompanyID,
+		FiscalYearID: period.FiscalYearID,
+		Name:         period.Name,
+		StartDate:    period.StartDate,
+		EndDate:      period.EndDate,
+		FiscalYear:   period.FiscalYear,
+		IsClosed:     period.IsClosed,
+		ClosedBy:     period.ClosedBy,
+		ClosedAt:     period.ClosedAt,
+	}
+}
+
+// buildMonthlyPeriods 按自然月切分财政年度，生成会计期间
+func buildMonthlyPeriods(companyID uint, year int, startDate, endDate time.Time) []models.AccountingPeriod {
+	var periods []models.AccountingPeriod
+	for periodStart := startDate; !periodStart.After(endDate); {
+		nextMonth := time.Date(periodStart.Year(), periodStart.Month(), 1, 0, 0, 0, 0, periodStart.Location()).AddDate(0, 1, 0)
+		periodEnd := nextMonth.AddDate(0, 0, -1)
+		if periodEnd.After(endDate) {
+			periodEnd = endDate
+		}
+		periods = append(periods, models.AccountingPeriod{
+			CompanyID:  companyID,
+			Name:       periodStart.Format("2006-01"),
+			StartDate:  periodStart,
+			EndDate:    periodEnd,
+			FiscalYear: fmt.Sprintf("%d", year),
+		})
+		periodStart = nextMonth
+	}
+	return periods
+}
+
+// resolveCompanyID 解析所属公司：未指定时归属默认公司，并校验公司存在且启用
+func resolveCompanyID(ctx context.Context, companyRepo repositories.CompanyRepository, companyID uint) (uint, error) {
+	if companyID == 0 {
+		companyID = models.DefaultCompanyID
+	}
+	company, err := companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		return 0, fmt.Errorf("公司 %d 不存在", companyID)
+	}
+	if !company.IsActive {
+		return 0, fmt.Errorf("公司 %s 已停用", company.Name)
+	}
+	return companyID, nil
+}
+
+// companyBaseCurrency 返回公司的本位币，未设置时为系统默认币种
+func companyBaseCurrency(ctx context.Context, companyRepo repositories.CompanyRepository, companyID uint) (string, error) {
+	company, err := companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		return "", fmt.Errorf("公司 %d 不存在", companyID)
+	}
+	if company.DefaultCurrency == "" {
+		return models.DefaultCurrency, nil
+	}
+	return company.DefaultCurrency, nil
+}
+
+// ensurePeriodOpen 校验公司在过账日期所属的会计期间未关闭；未配置会计期间时不做限制
+func ensurePeriodOpen(ctx context.Context, companyRepo repositories.CompanyRepository, companyID uint, date time.Time) error {
+	period, err := companyRepo.GetPeriodByDate(ctx, companyID, truncateToDay(date))
+	if err != nil {
+		return fmt.Errorf("检查会计期间失败: %w", err)
+	}
+	if period != nil && period.IsClosed {
+		return fmt.Errorf("会计期间 %s 已关闭，不能过账", period.Name)
+	}
+	return nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/galaxyerp/galaxyErp/internal/dto"
 	"github.com/galaxyerp/galaxyErp/internal/models"
 	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
 )
 
 // DeliveryNoteServiceInterface 发货单服务接口
@@ -20,27 +24,52 @@ type DeliveryNoteServiceInterface interface {
 	Update(id uint, req *dto.DeliveryNoteUpdateRequest) (*models.DeliveryNote, error)
 	Delete(id uint) error
 	List(req *dto.DeliveryNoteListRequest) ([]*models.DeliveryNote, int64, error)
-	UpdateStatus(id uint, req *dto.DeliveryNoteStatusUpdateRequest) (*models.DeliveryNote, error)
+	UpdateStatus(id uint, req *dto.DeliveryNoteStatusUpdateRequest, userID uint) (*models.DeliveryNote, error)
 	CreateFromSalesOrder(ctx *gin.Context, req *dto.DeliveryNoteBatchCreateRequest, userID uint) (*models.DeliveryNote, error)
 	GetStatistics(ctx *gin.Context) (*dto.DeliveryNoteStatisticsResponse, error)
 	GetDeliveryTrend(days int) ([]dto.DeliveryTrendData, error)
 }
 
+// 发货单状态变更错误，控制器据此区分响应状态码
+var (
+	// ErrDeliveryNoteNotFound 发货单不存在
+	ErrDeliveryNoteNotFound = errors.New("发货单不存在")
+	// ErrDeliveryNoteStatusConflict 发货单或关联单据的当前状态不允许此次状态变更，与仓储层过账冲销共用
+	ErrDeliveryNoteStatusConflict = repositories.ErrDeliveryNoteStatusConflict
+	// ErrDeliveryNoteInvalid 发货单数据不满足提交条件
+	ErrDeliveryNoteInvalid = errors.New("发货单数据不完整")
+)
+
 type DeliveryNoteService struct {
-	deliveryNoteRepo repositories.DeliveryNoteRepository
-	salesOrderRepo   repositories.SalesOrderRepository
-	customerRepo     repositories.CustomerRepository
+	deliveryNoteRepo  repositories.DeliveryNoteRepository
+	salesOrderRepo    repositories.SalesOrderRepository
+	customerRepo      repositories.CustomerRepository
+	itemRepo          repositories.ItemRepository
+	batchRepo         repositories.BatchRepository
+	reservationRepo   repositories.ReservationRepository
+	uomService        UOMService
+	salesOrderService SalesOrderService
 }
 
 func NewDeliveryNoteService(
 	deliveryNoteRepo repositories.DeliveryNoteRepository,
 	salesOrderRepo repositories.SalesOrderRepository,
 	customerRepo repositories.CustomerRepository,
+	itemRepo repositories.ItemRepository,
+	batchRepo repositories.BatchRepository,
+	reservationRepo repositories.ReservationRepository,
+	uomService UOMService,
+	salesOrderService SalesOrderService,
 ) *DeliveryNoteService {
 	return &DeliveryNoteService{
-		deliveryNoteRepo: deliveryNoteRepo,
-		salesOrderRepo:   salesOrderRepo,
-		customerRepo:     customerRepo,
+		deliveryNoteRepo:  deliveryNoteRepo,
+		salesOrderRepo:    salesOrderRepo,
+		customerRepo:      customerRepo,
+		itemRepo:          itemRepo,
+		batchRepo:         batchRepo,
+		reservationRepo:   reservationRepo,
+		uomService:        uomService,
+		salesOrderService: salesOrderService,
 	}
 }
 
@@ -70,12 +99,6 @@ func (s *DeliveryNoteService) Create(ctx *gin.Context, req *dto.DeliveryNoteCrea
 		return nil, fmt.Errorf("生成发货单号失败: %w", err)
 	}
 
-	// 计算总数量
-	var totalQuantity float64
-	for _, item := range req.Items {
-		totalQuantity += item.Quantity
-	}
-
 	// 创建发货单
 	deliveryNote := &models.DeliveryNote{
 		DeliveryNumber: deliveryNumber,
@@ -83,7 +106,6 @@ func (s *DeliveryNoteService) Create(ctx *gin.Context, req *dto.DeliveryNoteCrea
 		SalesOrderID:   req.SalesOrderID,
 		Date:           req.Date,
 		Status:         "Draft", // 默认状态为草稿
-		TotalQuantity:  totalQuantity,
 		Transporter:    req.Transporter,
 		DriverName:     req.DriverName,
 		VehicleNumber:  req.VehicleNumber,
@@ -92,7 +114,7 @@ func (s *DeliveryNoteService) Create(ctx *gin.Context, req *dto.DeliveryNoteCrea
 		CreatedBy:      userID,
 	}
 
-	// 创建发货单明细
+	// 创建发货单明细，总数量按库存单位汇总
 	for _, itemReq := range req.Items {
 		item := models.DeliveryNoteItem{
 			SalesOrderItemID: itemReq.SalesOrderItemID,
@@ -103,7 +125,19 @@ func (s *DeliveryNoteService) Create(ctx *gin.Context, req *dto.DeliveryNoteCrea
 			SerialNo:         itemReq.SerialNo,
 			WarehouseID:      itemReq.WarehouseID,
 		}
+		if err := s.convertLine(ctx, &item, itemReq.UOM); err != nil {
+			return nil, err
+		}
 		deliveryNote.Items = append(deliveryNote.Items, item)
+		deliveryNote.TotalQuantity += item.StockQty
+	}
+
+	// 未指定批次的批次管理明细按先到期先出分配批次，再校验批次、序列号在发货仓库的库存
+	if deliveryNote.Items, err = s.allocateBatches(ctx, deliveryNote.Items); err != nil {
+		return nil, err
+	}
+	if err := s.validateTracking(ctx, deliveryNote.Items); err != nil {
+		return nil, err
 	}
 
 	// 保存到数据库
@@ -117,7 +151,14 @@ func (s *DeliveryNoteService) Create(ctx *gin.Context, req *dto.DeliveryNoteCrea
 
 // GetByID 根据ID获取发货单
 func (s *DeliveryNoteService) GetByID(id uint) (*models.DeliveryNote, error) {
-	return s.deliveryNoteRepo.GetByID(context.Background(), id)
+	deliveryNote, err := s.deliveryNoteRepo.GetWithItems(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	if deliveryNote == nil {
+		return nil, errors.New("发货单不存在")
+	}
+	return deliveryNote, nil
 }
 
 // Update 更新发货单
@@ -128,9 +169,9 @@ func (s *DeliveryNoteService) Update(id uint, req *dto.DeliveryNoteUpdateRequest
 		return nil, fmt.Errorf("发货单不存在: %w", err)
 	}
 
-	// 检查状态是否允许修改
-	if deliveryNote.Status == "Delivered" || deliveryNote.Status == "Cancelled" {
-		return nil, errors.New("已发货或已取消的发货单不能修改")
+	// 提交时已出库，只有草稿可以修改
+	if deliveryNote.Status != models.DeliveryNoteStatusDraft {
+		return nil, errors.New("只有草稿状态的发货单可以修改")
 	}
 
 	// 更新字段
@@ -170,11 +211,22 @@ func (s *DeliveryNoteService) Update(id uint, req *dto.DeliveryNoteUpdateRequest
 				SerialNo:         itemReq.SerialNo,
 				WarehouseID:      itemReq.WarehouseID,
 			}
+			if err := s.convertLine(context.Background(), &item, itemReq.UOM); err != nil {
+				return nil, err
+			}
 			deliveryNote.Items = append(deliveryNote.Items, item)
-			totalQuantity += itemReq.Quantity
+			totalQuantity += item.StockQty
 		}
 
 		deliveryNote.TotalQuantity = totalQuantity
+
+		// 未指定批次的批次管理明细按先到期先出分配批次，再校验批次、序列号在发货仓库的库存
+		if deliveryNote.Items, err = s.allocateBatches(context.Background(), deliveryNote.Items); err != nil {
+			return nil, err
+		}
+		if err := s.validateTracking(context.Background(), deliveryNote.Items); err != nil {
+			return nil, err
+		}
 	}
 
 	// 保存更新
@@ -194,9 +246,9 @@ func (s *DeliveryNoteService) Delete(id uint) error {
 		return fmt.Errorf("发货单不存在: %w", err)
 	}
 
-	// 检查状态是否允许删除
-	if deliveryNote.Status == "Delivered" {
-		return errors.New("已发货的发货单不能删除")
+	// 检查状态是否允许删除，已出库的发货单需先取消冲销
+	if deliveryNote.Status == models.DeliveryNoteStatusSubmitted || deliveryNote.Status == models.DeliveryNoteStatusDelivered {
+		return errors.New("已提交的发货单不能删除，请先取消")
 	}
 
 	return s.deliveryNoteRepo.Delete(context.Background(), id)
@@ -213,12 +265,16 @@ func (s *DeliveryNoteService) List(req *dto.DeliveryNoteListRequest) ([]*models.
 	return s.deliveryNoteRepo.List(context.Background(), options)
 }
 
-// UpdateStatus 更新发货单状态
-func (s *DeliveryNoteService) UpdateStatus(id uint, req *dto.DeliveryNoteStatusUpdateRequest) (*models.DeliveryNote, error) {
-	// 获取发货单
-	deliveryNote, err := s.deliveryNoteRepo.GetByID(context.Background(), id)
+// UpdateStatus 更新发货单状态：提交时按明细出库并回写销售订单发货数量，
+// 已提交或已送达的发货单取消时冲销出库，随后按履约进度更新销售订单状态
+func (s *DeliveryNoteService) UpdateStatus(id uint, req *dto.DeliveryNoteStatusUpdateRequest, userID uint) (*models.DeliveryNote, error) {
+	ctx := context.Background()
+	deliveryNote, err := s.deliveryNoteRepo.GetWithItems(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("发货单不存在: %w", err)
+		return nil, fmt.Errorf("获取发货单失败: %w", err)
+	}
+	if deliveryNote == nil {
+		return nil, ErrDeliveryNoteNotFound
 	}
 
 	// 验证状态转换
@@ -226,26 +282,123 @@ func (s *DeliveryNoteService) UpdateStatus(id uint, req *dto.DeliveryNoteStatusU
 		return nil, err
 	}
 
-	// 更新状态
-	if err := s.deliveryNoteRepo.UpdateStatus(context.Background(), id, req.Status); err != nil {
-		return nil, fmt.Errorf("更新状态失败: %w", err)
+	stockPosted := deliveryNote.Status == models.DeliveryNoteStatusSubmitted || deliveryNote.Status == models.DeliveryNoteStatusDelivered
+	switch {
+	case req.Status == models.DeliveryNoteStatusSubmitted:
+		if err := s.validateSubmission(ctx, deliveryNote); err != nil {
+			return nil, err
+		}
+		if err := s.deliveryNoteRepo.PostDelivery(ctx, deliveryNote, userID); err != nil {
+			return nil, fmt.Errorf("提交发货单失败: %w", err)
+		}
+	case req.Status == models.DeliveryNoteStatusCancelled && stockPosted:
+		if err := s.validateReversal(ctx, deliveryNote); err != nil {
+			return nil, err
+		}
+		if err := s.deliveryNoteRepo.ReverseDelivery(ctx, deliveryNote, userID); err != nil {
+			return nil, fmt.Errorf("冲销发货单失败: %w", err)
+		}
+	default:
+		if err := s.deliveryNoteRepo.UpdateStatus(ctx, id, req.Status); err != nil {
+			return nil, fmt.Errorf("更新状态失败: %w", err)
+		}
+	}
+
+	if deliveryNote.SalesOrderID != nil && (req.Status == models.DeliveryNoteStatusSubmitted || (req.Status == models.DeliveryNoteStatusCancelled && stockPosted)) {
+		s.syncSalesOrder(ctx, *deliveryNote.SalesOrderID, userID)
 	}
 
 	// 重新加载数据
-	return s.deliveryNoteRepo.GetByID(context.Background(), id)
+	return s.deliveryNoteRepo.GetWithItems(ctx, id)
+}
+
+// validateSubmission 校验发货单提交条件：每行须指定发货仓库，关联的销售订单须处于可发货状态，
+// 订单明细须属于该订单
+func (s *DeliveryNoteService) validateSubmission(ctx context.Context, deliveryNote *models.DeliveryNote) error {
+	if len(deliveryNote.Items) == 0 {
+		return fmt.Errorf("%w，发货单没有明细，不能提交", ErrDeliveryNoteInvalid)
+	}
+
+	var orderItems map[uint]bool
+	if deliveryNote.SalesOrderID != nil {
+		salesOrder, err := s.salesOrderRepo.GetWithItems(ctx, *deliveryNote.SalesOrderID)
+		if err != nil {
+			return fmt.Errorf("获取销售订单失败: %w", err)
+		}
+		if salesOrder == nil {
+			return fmt.Errorf("%w，关联的销售订单不存在", ErrDeliveryNoteInvalid)
+		}
+		if !IsSalesOrderDeliverable(salesOrder.Status) {
+			return fmt.Errorf("%w，销售订单 %s 状态为 %s，不能发货", ErrDeliveryNoteStatusConflict, salesOrder.OrderNumber, salesOrder.Status)
+		}
+		orderItems = make(map[uint]bool, len(salesOrder.Items))
+		for _, item := range salesOrder.Items {
+			orderItems[item.ID] = true
+		}
+	}
+
+	for _, line := range deliveryNote.Items {
+		if line.WarehouseID == nil {
+			return fmt.Errorf("%w，发货单明细 %d 未指定发货仓库，不能出库", ErrDeliveryNoteInvalid, line.ID)
+		}
+		if line.SalesOrderItemID != nil && !orderItems[*line.SalesOrderItemID] {
+			return fmt.Errorf("%w，发货单明细 %d 对应的订单明细 %d 不属于发货单的销售订单", ErrDeliveryNoteInvalid, line.ID, *line.SalesOrderItemID)
+		}
+	}
+	return nil
+}
+
+// validateReversal 已开票的送货单与已完成的销售订单不再冲销发货
+func (s *DeliveryNoteService) validateReversal(ctx context.Context, deliveryNote *models.DeliveryNote) error {
+	for _, item := range deliveryNote.Items {
+		if item.BilledQty > stockQuantityTolerance {
+			return fmt.Errorf("%w，发货单 %s 已开具销售发票，请先取消发票", ErrDeliveryNoteStatusConflict, deliveryNote.DeliveryNumber)
+		}
+	}
+	if deliveryNote.SalesOrderID == nil {
+		return nil
+	}
+	salesOrder, err := s.salesOrderRepo.GetByID(ctx, *deliveryNote.SalesOrderID)
+	if err != nil {
+		return fmt.Errorf("获取销售订单失败: %w", err)
+	}
+	if NormalizeSalesOrderStatus(salesOrder.Status) == models.SalesOrderStatusCompleted {
+		return fmt.Errorf("%w，销售订单 %s 已完成，不能取消发货单", ErrDeliveryNoteStatusConflict, salesOrder.OrderNumber)
+	}
+	return nil
+}
+
+// syncSalesOrder 按发货进度推进销售订单状态，并为仍可发货的订单重新建立未发货数量的预留。
+// 出库已过账，此处失败只记录日志，可再次变更订单状态修正
+func (s *DeliveryNoteService) syncSalesOrder(ctx context.Context, salesOrderID, userID uint) {
+	if err := s.salesOrderService.SyncFulfilmentStatus(ctx, salesOrderID, userID); err != nil {
+		utils.LogError("更新销售订单履约状态失败", utils.Uint("order_id", salesOrderID), utils.ErrorField(err))
+		return
+	}
+
+	salesOrder, err := s.salesOrderRepo.GetByID(ctx, salesOrderID)
+	if err != nil || !IsSalesOrderDeliverable(salesOrder.Status) {
+		return
+	}
+	if _, err := s.reservationRepo.ReserveSalesOrder(ctx, salesOrderID, salesOrder.Status); err != nil {
+		utils.LogError("重新预留销售订单库存失败", utils.Uint("order_id", salesOrderID), utils.ErrorField(err))
+	}
 }
 
 // CreateFromSalesOrder 从销售订单创建发货单
 func (s *DeliveryNoteService) CreateFromSalesOrder(ctx *gin.Context, req *dto.DeliveryNoteBatchCreateRequest, userID uint) (*models.DeliveryNote, error) {
 	// 获取销售订单
-	salesOrder, err := s.salesOrderRepo.GetByID(ctx, req.SalesOrderID)
+	salesOrder, err := s.salesOrderRepo.GetWithItems(ctx, req.SalesOrderID)
 	if err != nil {
-		return nil, fmt.Errorf("销售订单不存在: %w", err)
+		return nil, fmt.Errorf("获取销售订单失败: %w", err)
+	}
+	if salesOrder == nil {
+		return nil, errors.New("销售订单不存在")
 	}
 
 	// 检查销售订单状态
-	if salesOrder.Status != "Confirmed" {
-		return nil, errors.New("只能从已确认的销售订单创建发货单")
+	if !IsSalesOrderDeliverable(salesOrder.Status) {
+		return nil, fmt.Errorf("销售订单状态为 %s，只能从已确认且未全部发货的销售订单创建发货单", salesOrder.Status)
 	}
 
 	// 生成发货单号
@@ -254,20 +407,14 @@ func (s *DeliveryNoteService) CreateFromSalesOrder(ctx *gin.Context, req *dto.De
 		return nil, fmt.Errorf("生成发货单号失败: %w", err)
 	}
 
-	// 计算总数量
-	var totalQuantity float64
-	for _, item := range req.Items {
-		totalQuantity += item.Quantity
-	}
-
 	// 创建发货单
 	deliveryNote := &models.DeliveryNote{
+		CompanyID:      salesOrder.CompanyID,
 		DeliveryNumber: deliveryNumber,
 		CustomerID:     salesOrder.CustomerID,
 		SalesOrderID:   &req.SalesOrderID,
 		Date:           req.Date,
 		Status:         "Draft",
-		TotalQuantity:  totalQuantity,
 		Transporter:    req.Transporter,
 		DriverName:     req.DriverName,
 		VehicleNumber:  req.VehicleNumber,
@@ -288,12 +435,7 @@ func (s *DeliveryNoteService) CreateFromSalesOrder(ctx *gin.Context, req *dto.De
 		}
 		
 		if salesOrderItem == nil {
-			return nil, fmt.Errorf("销售订单明细不存在: %w", err)
-		}
-
-		// 检查发货数量是否超过订单数量
-		if itemReq.Quantity > salesOrderItem.Quantity {
-			return nil, fmt.Errorf("发货数量不能超过订单数量")
+			return nil, fmt.Errorf("销售订单明细 %d 不存在", itemReq.SalesOrderItemID)
 		}
 
 		item := models.DeliveryNoteItem{
@@ -305,7 +447,24 @@ func (s *DeliveryNoteService) CreateFromSalesOrder(ctx *gin.Context, req *dto.De
 			SerialNo:         itemReq.SerialNo,
 			WarehouseID:      itemReq.WarehouseID,
 		}
+		if err := s.convertLine(ctx, &item, itemReq.UOM); err != nil {
+			return nil, err
+		}
+
+		// 检查发货数量是否超过订单未发货数量，订单数量按库存单位计，提交时再按超发比例校验累计发货
+		if item.StockQty > salesOrderItem.Quantity-salesOrderItem.DeliveredQty+stockQuantityTolerance {
+			return nil, fmt.Errorf("发货数量 %.2f 超过订单未发货数量 %.2f", item.StockQty, salesOrderItem.Quantity-salesOrderItem.DeliveredQty)
+		}
 		deliveryNote.Items = append(deliveryNote.Items, item)
+		deliveryNote.TotalQuantity += item.StockQty
+	}
+
+	// 未指定批次的批次管理明细按先到期先出分配批次，再校验批次、序列号在发货仓库的库存
+	if deliveryNote.Items, err = s.allocateBatches(ctx, deliveryNote.Items); err != nil {
+		return nil, err
+	}
+	if err := s.validateTracking(ctx, deliveryNote.Items); err != nil {
+		return nil, err
 	}
 
 	// 保存到数据库
@@ -327,18 +486,177 @@ func (s *DeliveryNoteService) GetDeliveryTrend(days int) ([]dto.DeliveryTrendDat
 	return s.deliveryNoteRepo.GetDeliveryTrend(days)
 }
 
+// convertLine 将发货单行数量换算为库存单位，发货单位必须已定义且可换算
+func (s *DeliveryNoteService) convertLine(ctx context.Context, line *models.DeliveryNoteItem, uom string) error {
+	conversion, err := s.uomService.ConvertToStock(ctx, line.ItemID, uom, line.Quantity)
+	if err != nil {
+		return err
+	}
+	line.Quantity = conversion.Quantity
+	line.UOM = conversion.UOM
+	line.ConversionFactor = conversion.ConversionFactor
+	line.StockQty = conversion.StockQuantity
+	return nil
+}
+
+// allocateBatches 将未指定批次的批次管理物料明细按先到期先出拆分为多个批次的明细，
+// 已过期批次和隔离库位的库存不参与分配；同一物料的多行明细依次占用批次余额
+func (s *DeliveryNoteService) allocateBatches(ctx context.Context, lines []models.DeliveryNoteItem) ([]models.DeliveryNoteItem, error) {
+	allocated := make([]models.DeliveryNoteItem, 0, len(lines))
+	used := make(map[string]float64)
+	now := time.Now()
+	for _, line := range lines {
+		if line.BatchNo != "" || line.WarehouseID == nil {
+			allocated = append(allocated, line)
+			continue
+		}
+		item, err := s.itemRepo.GetByID(ctx, line.ItemID)
+		if err != nil {
+			return nil, fmt.Errorf("物料 %d 不存在", line.ItemID)
+		}
+		if item.TrackingMode != models.TrackingModeBatch {
+			allocated = append(allocated, line)
+			continue
+		}
+
+		rows, err := s.batchRepo.GetFEFOBatches(ctx, item.ID, *line.WarehouseID, 0, now)
+		if err != nil {
+			return nil, fmt.Errorf("获取批次库存失败: %w", err)
+		}
+		factor := line.ConversionFactor
+		if factor <= 0 {
+			factor = 1
+		}
+		remaining := line.StockQty
+		for _, row := range rows {
+			if remaining <= stockQuantityTolerance {
+				break
+			}
+			key := fmt.Sprintf("%d:%d", row.BatchID, *line.WarehouseID)
+			available := row.Quantity - used[key]
+			if available <= stockQuantityTolerance {
+				continue
+			}
+			quantity := math.Min(available, remaining)
+			used[key] += quantity
+			remaining -= quantity
+
+			split := line
+			split.BatchNo = row.BatchNo
+			split.StockQty = quantity
+			split.Quantity = quantity / factor
+			allocated = append(allocated, split)
+		}
+		if remaining > stockQuantityTolerance {
+			return nil, fmt.Errorf("物料 %s 在发货仓库未过期批次的可用库存不足，还差 %.2f", item.Code, remaining)
+		}
+	}
+	return allocated, nil
+}
+
+// validateTracking 校验批次、序列号跟踪物料的发货明细：批次须未过期且在发货仓库有足够库存，
+// 序列号须全部存在且在发货仓库库存中，数量与发货数量一致；序列号列表规范为逗号分隔
+func (s *DeliveryNoteService) validateTracking(ctx context.Context, lines []models.DeliveryNoteItem) error {
+	batchQty := make(map[string]float64)
+	shippedSerials := make(map[string]bool)
+	for i := range lines {
+		line := &lines[i]
+		item, err := s.itemRepo.GetByID(ctx, line.ItemID)
+		if err != nil {
+			return fmt.Errorf("物料 %d 不存在", line.ItemID)
+		}
+		if item.TrackingMode == "" || item.TrackingMode == models.TrackingModeNone {
+			continue
+		}
+		if line.WarehouseID == nil {
+			return fmt.Errorf("物料 %s 启用了批次或序列号跟踪，必须指定发货仓库", item.Code)
+		}
+
+		var batch *models.Batch
+		if line.BatchNo != "" {
+			batch, err = s.batchRepo.GetByBatchNo(ctx, item.ID, line.BatchNo)
+			if err != nil {
+				return fmt.Errorf("获取批次失败: %w", err)
+			}
+			if batch == nil {
+				return fmt.Errorf("物料 %s 的批次 %s 不存在", item.Code, line.BatchNo)
+			}
+			if batch.IsExpired(time.Now()) {
+				return fmt.Errorf("物料 %s 的批次 %s 已于 %s 过期，不能发货", item.Code, batch.BatchNo, batch.ExpiryDate.Format("2006-01-02"))
+			}
+		} else if item.TrackingMode == models.TrackingModeBatch {
+			return fmt.Errorf("物料 %s 启用了批次管理，必须指定批次号", item.Code)
+		}
+
+		if batch != nil {
+			key := fmt.Sprintf("%d:%d", batch.ID, *line.WarehouseID)
+			batchQty[key] += line.StockQty
+			rows, err := s.batchRepo.GetBatchStocks(ctx, repositories.BatchStockFilter{
+				ItemID:      item.ID,
+				WarehouseID: *line.WarehouseID,
+				BatchID:     batch.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("获取批次库存失败: %w", err)
+			}
+			var available float64
+			for _, row := range rows {
+				available += row.Quantity
+			}
+			if batchQty[key] > available {
+				return fmt.Errorf("物料 %s 的批次 %s 在发货仓库库存不足: 可用 %.2f，需要 %.2f", item.Code, batch.BatchNo, available, batchQty[key])
+			}
+		}
+
+		if item.TrackingMode != models.TrackingModeSerial {
+			continue
+		}
+		serialNos := models.ParseSerialNos(line.SerialNo)
+		if float64(len(serialNos)) != line.StockQty {
+			return fmt.Errorf("物料 %s 的序列号数量 %d 与发货数量 %.2f 不一致", item.Code, len(serialNos), line.StockQty)
+		}
+		serials, err := s.batchRepo.GetSerialNumbers(ctx, item.ID, serialNos)
+		if err != nil {
+			return fmt.Errorf("获取序列号失败: %w", err)
+		}
+		found := make(map[string]*models.SerialNumber, len(serials))
+		for _, serial := range serials {
+			found[serial.SerialNo] = serial
+		}
+		for _, serialNo := range serialNos {
+			serial, ok := found[serialNo]
+			if !ok {
+				return fmt.Errorf("物料 %s 的序列号 %s 不存在", item.Code, serialNo)
+			}
+			if serial.Status != models.SerialStatusInStock || serial.WarehouseID == nil || *serial.WarehouseID != *line.WarehouseID {
+				return fmt.Errorf("物料 %s 的序列号 %s 不在发货仓库的库存中", item.Code, serialNo)
+			}
+			if batch != nil && (serial.BatchID == nil || *serial.BatchID != batch.ID) {
+				return fmt.Errorf("物料 %s 的序列号 %s 不属于批次 %s", item.Code, serialNo, batch.BatchNo)
+			}
+			key := fmt.Sprintf("%d:%s", item.ID, serialNo)
+			if shippedSerials[key] {
+				return fmt.Errorf("物料 %s 的序列号 %s 重复发货", item.Code, serialNo)
+			}
+			shippedSerials[key] = true
+		}
+		line.SerialNo = strings.Join(serialNos, ",")
+	}
+	return nil
+}
+
 // validateStatusTransition 验证状态转换
 func (s *DeliveryNoteService) validateStatusTransition(currentStatus, newStatus string) error {
 	validTransitions := map[string][]string{
-		"Draft":     {"Submitted", "Cancelled"},
-		"Submitted": {"Delivered", "Cancelled"},
-		"Delivered": {}, // 已发货状态不能转换
-		"Cancelled": {}, // 已取消状态不能转换
+		models.DeliveryNoteStatusDraft:     {models.DeliveryNoteStatusSubmitted, models.DeliveryNoteStatusCancelled},
+		models.DeliveryNoteStatusSubmitted: {models.DeliveryNoteStatusDelivered, models.DeliveryNoteStatusCancelled},
+		models.DeliveryNoteStatusDelivered: {models.DeliveryNoteStatusCancelled}, // 取消时冲销出库
+		models.DeliveryNoteStatusCancelled: {},                                   // 已取消状态不能转换
 	}
 
 	allowedStatuses, exists := validTransitions[currentStatus]
 	if !exists {
-		return fmt.Errorf("无效的当前状态: %s", currentStatus)
+		return fmt.Errorf("%w，无效的当前状态: %s", ErrDeliveryNoteStatusConflict, currentStatus)
 	}
 
 	for _, status := range allowedStatuses {
@@ -347,5 +665,5 @@ func (s *DeliveryNoteService) validateStatusTransition(currentStatus, newStatus
 		}
 	}
 
-	return fmt.Errorf("不能从状态 %s 转换到 %s", currentStatus, newStatus)
+	return fmt.Errorf("%w，不能从状态 %s 转换到 %s", ErrDeliveryNoteStatusConflict, currentStatus, newStatus)
 }
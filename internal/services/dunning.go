@@ -0,0 +1,631 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// 催款触发方式
+const (
+	DunningTriggerManual    = "manual"
+	DunningTriggerScheduled = "scheduled"
+)
+
+// 默认催款函模板
+const (
+	defaultDunningSubjectTemplate = `【{{.LevelName}}】发票 {{.InvoiceNumber}} 已逾期 {{.OverdueDays}} 天`
+	defaultDunningBodyTemplate    = `尊敬的 {{.CustomerName}}：
+
+截至 {{.LetterDate}}，贵司发票 {{.InvoiceNumber}}（开票日期 {{.InvoiceDate}}，到期日 {{.DueDate}}）仍有未付金额 {{.Currency}} {{.OutstandingAmount}}，已逾期 {{.OverdueDays}} 天。
+{{- if .FeeAmount.IsPositive}}
+催款手续费：{{.Currency}} {{.FeeAmount}}
+{{- end}}
+{{- if .InterestAmount.IsPositive}}
+逾期利息（年利率 {{.InterestRate}}%）：{{.Currency}} {{.InterestAmount}}
+{{- end}}
+应付合计：{{.Currency}} {{.TotalDue}}
+
+请尽快安排付款，如已付款请忽略本函。
+
+催款函编号：{{.LetterNumber}}`
+)
+
+// defaultDunningLevels 未配置催款级别时使用的默认级别
+var defaultDunningLevels = []models.DunningLevel{
+	{Level: 1, Name: "付款提醒", DaysOverdue: 1, IsActive: true},
+	{Level: 2, Name: "二次催款通知", DaysOverdue: 15, FeeAmount: models.NewMoney(50), IsActive: true},
+	{Level: 3, Name: "最终催告函", DaysOverdue: 30, FeeAmount: models.NewMoney(100), InterestRate: 6, IsActive: true},
+}
+
+// DunningService 对账单与催款服务接口
+type DunningService interface {
+	GetCustomerStatement(ctx context.Context, customerID uint, req *dto.CustomerStatementRequest) (*dto.CustomerStatementResponse, error)
+	ListLevels(ctx context.Context) ([]dto.DunningLevelResponse, error)
+	CreateLevel(ctx context.Context, req *dto.DunningLevelCreateRequest, userID uint) (*dto.DunningLevelResponse, error)
+	UpdateLevel(ctx context.Context, id uint, req *dto.DunningLevelUpdateRequest, userID uint) (*dto.DunningLevelResponse, error)
+	DeleteLevel(ctx context.Context, id uint) error
+	RunDunning(ctx context.Context, req *dto.DunningRunRequest, triggerType string, userID uint) (*dto.DunningRunResponse, error)
+	ListLetters(ctx context.Context, req *dto.DunningLetterListRequest) (*dto.PaginatedResponse[dto.DunningLetterResponse], error)
+	GetLetter(ctx context.Context, id uint) (*dto.DunningLetterResponse, error)
+}
+
+// DunningServiceImpl 对账单与催款服务实现
+type DunningServiceImpl struct {
+	dunningRepo  repositories.DunningRepository
+	customerRepo repositories.CustomerRepository
+	runMutex     sync.Mutex // 防止定时任务与手动执行并发生成重复催款函
+}
+
+// NewDunningService 创建对账单与催款服务实例
+func NewDunningService(dunningRepo repositories.DunningRepository, customerRepo repositories.CustomerRepository) DunningService {
+	return &DunningServiceImpl{
+		dunningRepo:  dunningRepo,
+		customerRepo: customerRepo,
+	}
+}
+
+// GetCustomerStatement 生成客户在指定期间的对账单
+func (s *DunningServiceImpl) GetCustomerStatement(ctx context.Context, customerID uint, req *dto.CustomerStatementRequest) (*dto.CustomerStatementResponse, error) {
+	if req.EndDate.Before(req.StartDate) {
+		return nil, errors.New("结束日期不能早于开始日期")
+	}
+
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, errors.New("客户不存在")
+	}
+
+	startDate := truncateToDay(req.StartDate)
+	// 结束日期包含当天全天
+	endDate := truncateToDay(req.EndDate).Add(24*time.Hour - time.Nanosecond)
+
+	invoices, err := s.dunningRepo.GetStatementInvoices(ctx, customerID, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("获取客户发票失败: %v", err)
+	}
+	payments, err := s.dunningRepo.GetStatementPayments(ctx, customerID, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("获取客户收款记录失败: %v", err)
+	}
+
+	statement := &dto.CustomerStatementResponse{
+		CustomerID:   customer.ID,
+		CustomerCode: customer.Code,
+		CustomerName: customer.Name,
+		StartDate:    startDate,
+		EndDate:      truncateToDay(req.EndDate),
+		Lines:        []dto.CustomerStatementLine{},
+		GeneratedAt:  time.Now(),
+	}
+
+	var lines []dto.CustomerStatementLine
+	for _, invoice := range invoices {
+		if invoice.PostingDate.Before(startDate) {
+			statement.OpeningBalance += invoice.GrandTotal
+			continue
+		}
+		dueDate := invoice.DueDate
+		lines = append(lines, dto.CustomerStatementLine{
+			Date:          invoice.PostingDate,
+			Type:          "invoice",
+			Reference:     invoice.InvoiceNumber,
+			Description:   invoice.Notes,
+			DueDate:       &dueDate,
+			Debit:         invoice.GrandTotal,
+			PaymentStatus: invoice.PaymentStatus,
+		})
+		statement.TotalInvoiced += invoice.GrandTotal
+	}
+
+	for _, payment := range payments {
+		if payment.PaymentDate.Before(startDate) {
+			statement.OpeningBalance -= payment.Amount
+			continue
+		}
+		description := payment.PaymentMethod
+		if payment.SalesInvoice.InvoiceNumber != "" {
+			description = fmt.Sprintf("%s - %s", payment.PaymentMethod, payment.SalesInvoice.InvoiceNumber)
+		}
+		reference := payment.ReferenceNumber
+		if reference == "" {
+			reference = fmt.Sprintf("PAY-%d", payment.ID)
+		}
+		lines = append(lines, dto.CustomerStatementLine{
+			Date:        payment.PaymentDate,
+			Type:        "payment",
+			Reference:   reference,
+			Description: description,
+			Credit:      payment.Amount,
+		})
+		statement.TotalReceived += payment.Amount
+	}
+
+	// 按日期排序，同一天发票在前、收款在后
+	sort.SliceStable(lines, func(i, j int) bool {
+		if !lines[i].Date.Equal(lines[j].Date) {
+			return lines[i].Date.Before(lines[j].Date)
+		}
+		return lines[i].Type == "invoice" && lines[j].Type == "payment"
+	})
+
+	balance := statement.OpeningBalance
+	for i := range lines {
+		balance = balance + lines[i].Debit - lines[i].Credit
+		lines[i].Balance = balance
+	}
+	if len(lines) > 0 {
+		statement.Lines = lines
+	}
+	statement.ClosingBalance = balance
+
+	// 逾期金额：截至结束日期已到期且仍未结清的发票余额
+	for _, invoice := range invoices {
+		if invoice.OutstandingAmount.IsPositive() && invoice.DueDate.Before(endDate) {
+			statement.OverdueAmount += invoice.OutstandingAmount
+		}
+	}
+
+	return statement, nil
+}
+
+// ListLevels 获取催款级别列表
+func (s *DunningServiceImpl) ListLevels(ctx context.Context) ([]dto.DunningLevelResponse, error) {
+	levels, err := s.dunningRepo.GetLevels(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取催款级别失败: %v", err)
+	}
+
+	responses := make([]dto.DunningLevelResponse, 0, len(levels))
+	for _, level := range levels {
+		responses = append(responses, *s.convertToLevelResponse(level))
+	}
+	return responses, nil
+}
+
+// CreateLevel 创建催款级别
+func (s *DunningServiceImpl) CreateLevel(ctx context.Context, req *dto.DunningLevelCreateRequest, userID uint) (*dto.DunningLevelResponse, error) {
+	if err := validateDunningTemplates(req.SubjectTemplate, req.BodyTemplate); err != nil {
+		return nil, err
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	level := &models.DunningLevel{
+		Level:           req.Level,
+		Name:            req.Name,
+		DaysOverdue:     req.DaysOverdue,
+		FeeAmount:       req.FeeAmount,
+		InterestRate:    req.InterestRate,
+		SubjectTemplate: req.SubjectTemplate,
+		BodyTemplate:    req.BodyTemplate,
+		IsActive:        isActive,
+	}
+	level.CreatedBy = userID
+	level.UpdatedBy = userID
+
+	if err := s.dunningRepo.CreateLevel(ctx, level); err != nil {
+		return nil, fmt.Errorf("创建催款级别失败: %v", err)
+	}
+
+	return s.convertToLevelResponse(level), nil
+}
+
+// UpdateLevel 更新催款级别
+func (s *DunningServiceImpl) UpdateLevel(ctx context.Context, id uint, req *dto.DunningLevelUpdateRequest, userID uint) (*dto.DunningLevelResponse, error) {
+	level, err := s.dunningRepo.GetLevelByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("催款级别不存在")
+		}
+		return nil, fmt.Errorf("获取催款级别失败: %v", err)
+	}
+
+	if req.Name != nil {
+		level.Name = *req.Name
+	}
+	if req.DaysOverdue != nil {
+		level.DaysOverdue = *req.DaysOverdue
+	}
+	if req.FeeAmount != nil {
+		level.FeeAmount = *req.FeeAmount
+	}
+	if req.InterestRate != nil {
+		level.InterestRate = *req.InterestRate
+	}
+	if req.SubjectTemplate != nil {
+		level.SubjectTemplate = *req.SubjectTemplate
+	}
+	if req.BodyTemplate != nil {
+		level.BodyTemplate = *req.BodyTemplate
+	}
+	if req.IsActive != nil {
+		level.IsActive = *req.IsActive
+	}
+	if err := validateDunningTemplates(level.SubjectTemplate, level.BodyTemplate); err != nil {
+		return nil, err
+	}
+	level.UpdatedBy = userID
+
+	if err := s.dunningRepo.UpdateLevel(ctx, level); err != nil {
+		return nil, fmt.Errorf("更新催款级别失败: %v", err)
+	}
+
+	return s.convertToLevelResponse(level), nil
+}
+
+// DeleteLevel 删除催款级别
+func (s *DunningServiceImpl) DeleteLevel(ctx context.Context, id uint) error {
+	if _, err := s.dunningRepo.GetLevelByID(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("催款级别不存在")
+		}
+		return fmt.Errorf("获取催款级别失败: %v", err)
+	}
+	return s.dunningRepo.DeleteLevel(ctx, id)
+}
+
+// RunDunning 执行催款：标记逾期发票，并按逾期天数为每张发票生成下一级催款函
+func (s *DunningServiceImpl) RunDunning(ctx context.Context, req *dto.DunningRunRequest, triggerType string, userID uint) (*dto.DunningRunResponse, error) {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	asOf := time.Now()
+	if req.AsOfDate != nil {
+		asOf = *req.AsOfDate
+	}
+	asOfDay := truncateToDay(asOf)
+
+	result := &dto.DunningRunResponse{
+		AsOfDate:    asOfDay,
+		TriggerType: triggerType,
+		DryRun:      req.DryRun,
+		Letters:     []dto.DunningLetterResponse{},
+	}
+
+	if !req.DryRun {
+		marked, err := s.dunningRepo.MarkOverdueInvoices(ctx, asOfDay)
+		if err != nil {
+			return nil, fmt.Errorf("标记逾期发票失败: %v", err)
+		}
+		result.MarkedOverdue = marked
+	}
+
+	levels, err := s.getActiveLevels(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(levels) == 0 {
+		return result, nil
+	}
+
+	invoices, err := s.dunningRepo.GetOverdueInvoices(ctx, req.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("获取逾期发票失败: %v", err)
+	}
+	result.InvoicesChecked = len(invoices)
+
+	for _, invoice := range invoices {
+		overdueDays := int(asOfDay.Sub(truncateToDay(invoice.DueDate)).Hours() / 24)
+		if overdueDays <= 0 {
+			continue
+		}
+
+		lastLevel, err := s.dunningRepo.GetLastLetterLevel(ctx, invoice.ID)
+		if err != nil {
+			return nil, fmt.Errorf("获取发票 %s 的催款记录失败: %v", invoice.InvoiceNumber, err)
+		}
+
+		level := selectDunningLevel(levels, overdueDays, lastLevel)
+		if level == nil {
+			continue
+		}
+
+		letter, err := s.buildLetter(ctx, invoice, level, asOfDay, overdueDays, triggerType, req.DryRun, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !req.DryRun {
+			if err := s.dunningRepo.Create(ctx, letter); err != nil {
+				return nil, fmt.Errorf("保存催款函失败: %v", err)
+			}
+		}
+
+		letter.Customer = invoice.Customer
+		letter.SalesInvoice = *invoice
+		letter.DunningLevel = *level
+		result.Letters = append(result.Letters, *s.convertToLetterResponse(letter))
+	}
+	result.LettersCreated = len(result.Letters)
+
+	utils.Info("催款任务执行完成",
+		utils.String("trigger_type", triggerType),
+		utils.String("as_of_date", asOfDay.Format("2006-01-02")),
+		utils.Bool("dry_run", req.DryRun),
+		utils.Int64("marked_overdue", result.MarkedOverdue),
+		utils.Int("invoices_checked", result.InvoicesChecked),
+		utils.Int("letters_created", result.LettersCreated),
+	)
+
+	return result, nil
+}
+
+// ListLetters 获取催款函发送记录
+func (s *DunningServiceImpl) ListLetters(ctx context.Context, req *dto.DunningLetterListRequest) (*dto.PaginatedResponse[dto.DunningLetterResponse], error) {
+	letters, total, err := s.dunningRepo.ListWithFilters(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("查询催款函列表失败: %v", err)
+	}
+
+	responses := make([]dto.DunningLetterResponse, 0, len(letters))
+	for _, letter := range letters {
+		responses = append(responses, *s.convertToLetterResponse(letter))
+	}
+
+	limit := req.GetLimit()
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	return &dto.PaginatedResponse[dto.DunningLetterResponse]{
+		Data:       responses,
+		Total:      total,
+		Page:       req.Page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetLetter 获取催款函详情
+func (s *DunningServiceImpl) GetLetter(ctx context.Context, id uint) (*dto.DunningLetterResponse, error) {
+	letter, err := s.dunningRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("催款函不存在")
+		}
+		return nil, fmt.Errorf("获取催款函失败: %v", err)
+	}
+	return s.convertToLetterResponse(letter), nil
+}
+
+// getActiveLevels 获取启用的催款级别，首次运行且未配置时写入默认级别
+func (s *DunningServiceImpl) getActiveLevels(ctx context.Context, userID uint) ([]*models.DunningLevel, error) {
+	all, err := s.dunningRepo.GetLevels(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取催款级别失败: %v", err)
+	}
+
+	if len(all) == 0 {
+		for _, def := range defaultDunningLevels {
+			level := def
+			level.CreatedBy = userID
+			level.UpdatedBy = userID
+			if err := s.dunningRepo.CreateLevel(ctx, &level); err != nil {
+				return nil, fmt.Errorf("初始化默认催款级别失败: %v", err)
+			}
+		}
+	}
+
+	levels, err := s.dunningRepo.GetLevels(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("获取催款级别失败: %v", err)
+	}
+	return levels, nil
+}
+
+// selectDunningLevel 选择逾期天数已达到且高于已发送级别的最高催款级别
+func selectDunningLevel(levels []*models.DunningLevel, overdueDays, lastLevel int) *models.DunningLevel {
+	var selected *models.DunningLevel
+	for _, level := range levels {
+		if level.Level <= lastLevel || level.DaysOverdue > overdueDays {
+			continue
+		}
+		if selected == nil || level.Level > selected.Level {
+			selected = level
+		}
+	}
+	return selected
+}
+
+// dunningPreviewLetterNumber 试运行生成的催款函不占用编号，以此占位
+const dunningPreviewLetterNumber = "PREVIEW"
+
+// dunningLetterData 催款函模板数据
+type dunningLetterData struct {
+	LetterNumber      string
+	LetterDate        string
+	LevelName         string
+	Level             int
+	CustomerCode      string
+	CustomerName      string
+	InvoiceNumber     string
+	InvoiceDate       string
+	DueDate           string
+	OverdueDays       int
+	Currency          string
+	GrandTotal        models.Money
+	OutstandingAmount models.Money
+	FeeAmount         models.Money
+	InterestRate      float64
+	InterestAmount    models.Money
+	TotalDue          models.Money
+}
+
+// buildLetter 计算费用与利息并渲染催款函，试运行不生成催款函编号以免占用编号序列
+func (s *DunningServiceImpl) buildLetter(ctx context.Context, invoice *models.SalesInvoice, level *models.DunningLevel, asOf time.Time, overdueDays int, triggerType string, dryRun bool, userID uint) (*models.DunningLetter, error) {
+	letterNumber := dunningPreviewLetterNumber
+	if !dryRun {
+		var err error
+		letterNumber, err = s.dunningRepo.GenerateLetterNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("生成催款函编号失败: %v", err)
+		}
+	}
+
+	currency := invoice.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+
+	// 逾期利息 = 未收金额 × 年利率 × 逾期天数 / 365
+	interest, err := invoice.OutstandingAmount.ProratedPercent(level.InterestRate, overdueDays, 365)
+	if err != nil {
+		return nil, fmt.Errorf("计算发票 %s 的逾期利息失败: %w", invoice.InvoiceNumber, err)
+	}
+	interest = interest.RoundCurrency(currency)
+	fee := level.FeeAmount.RoundCurrency(currency)
+	totalDue := invoice.OutstandingAmount + fee + interest
+
+	data := dunningLetterData{
+		LetterNumber:      letterNumber,
+		LetterDate:        asOf.Format("2006-01-02"),
+		LevelName:         level.Name,
+		Level:             level.Level,
+		CustomerCode:      invoice.Customer.Code,
+		CustomerName:      invoice.Customer.Name,
+		InvoiceNumber:     invoice.InvoiceNumber,
+		InvoiceDate:       invoice.InvoiceDate.Format("2006-01-02"),
+		DueDate:           invoice.DueDate.Format("2006-01-02"),
+		OverdueDays:       overdueDays,
+		Currency:          currency,
+		GrandTotal:        invoice.GrandTotal,
+		OutstandingAmount: invoice.OutstandingAmount,
+		FeeAmount:         fee,
+		InterestRate:      level.InterestRate,
+		InterestAmount:    interest,
+		TotalDue:          totalDue,
+	}
+
+	subject, err := renderDunningTemplate("subject", level.SubjectTemplate, defaultDunningSubjectTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	content, err := renderDunningTemplate("body", level.BodyTemplate, defaultDunningBodyTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+
+	sentAt := time.Now()
+	letter := &models.DunningLetter{
+		LetterNumber:      letterNumber,
+		CustomerID:        invoice.CustomerID,
+		SalesInvoiceID:    invoice.ID,
+		DunningLevelID:    level.ID,
+		Level:             level.Level,
+		LetterDate:        asOf,
+		OverdueDays:       overdueDays,
+		Currency:          currency,
+		OutstandingAmount: invoice.OutstandingAmount,
+		FeeAmount:         fee,
+		InterestAmount:    interest,
+		TotalDue:          totalDue,
+		Subject:           subject,
+		Content:           content,
+		TriggerType:       triggerType,
+		Status:            "sent",
+		SentAt:            &sentAt,
+	}
+	letter.CreatedBy = userID
+	letter.UpdatedBy = userID
+
+	return letter, nil
+}
+
+// renderDunningTemplate 渲染催款函模板，未配置时使用默认模板
+func renderDunningTemplate(name, text, fallback string, data dunningLetterData) (string, error) {
+	if text == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("催款函模板解析失败: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("催款函模板渲染失败: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// validateDunningTemplates 校验模板语法与字段引用
+func validateDunningTemplates(subject, body string) error {
+	if _, err := renderDunningTemplate("subject", subject, defaultDunningSubjectTemplate, dunningLetterData{}); err != nil {
+		return err
+	}
+	if _, err := renderDunningTemplate("body", body, defaultDunningBodyTemplate, dunningLetterData{}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// truncateToDay 截断到当天零点
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// convertToLevelResponse 转换为催款级别响应
+func (s *DunningServiceImpl) convertToLevelResponse(level *models.DunningLevel) *dto.DunningLevelResponse {
+	return &dto.DunningLevelResponse{
+		ID:              level.ID,
+		Level:           level.Level,
+		Name:            level.Name,
+		DaysOverdue:     level.DaysOverdue,
+		FeeAmount:       level.FeeAmount,
+		InterestRate:    level.InterestRate,
+		SubjectTemplate: level.SubjectTemplate,
+		BodyTemplate:    level.BodyTemplate,
+		IsActive:        level.IsActive,
+		CreatedAt:       level.CreatedAt,
+		UpdatedAt:       level.UpdatedAt,
+	}
+}
+
+// convertToLetterResponse 转换为催款函响应
+func (s *DunningServiceImpl) convertToLetterResponse(letter *models.DunningLetter) *dto.DunningLetterResponse {
+	return &dto.DunningLetterResponse{
+		ID:                letter.ID,
+		LetterNumber:      letter.LetterNumber,
+		CustomerID:        letter.CustomerID,
+		CustomerName:      letter.Customer.Name,
+		SalesInvoiceID:    letter.SalesInvoiceID,
+		InvoiceNumber:     letter.SalesInvoice.InvoiceNumber,
+		DunningLevelID:    letter.DunningLevelID,
+		Level:             letter.Level,
+		LevelName:         letter.DunningLevel.Name,
+		LetterDate:        letter.LetterDate,
+		OverdueDays:       letter.OverdueDays,
+		Currency:          letter.Currency,
+		OutstandingAmount: letter.OutstandingAmount,
+		FeeAmount:         letter.FeeAmount,
+		InterestAmount:    letter.InterestAmount,
+		TotalDue:          letter.TotalDue,
+		Subject:           letter.Subject,
+		Content:           letter.Content,
+		TriggerType:       letter.TriggerType,
+		Status:            letter.Status,
+		SentAt:            letter.SentAt,
+		CreatedAt:         letter.CreatedAt,
+	}
+}
+
+// NewDunningScheduler 创建催款定时任务，按固定间隔执行 RunDunning
+func NewDunningScheduler(service DunningService, interval time.Duration) *PeriodicJob {
+	return NewPeriodicJob("催款", interval, func(ctx context.Context) error {
+		_, err := service.RunDunning(ctx, &dto.DunningRunRequest{}, DunningTriggerScheduled, 0)
+		return err
+	})
+}
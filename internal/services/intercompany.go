@@ -0,0 +1,486 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// IntercompanyService 内部交易与合并报表服务接口
+type IntercompanyService interface {
+	MirrorSalesOrder(ctx context.Context, salesOrderID uint, userID uint) (*dto.IntercompanyTransactionResponse, error)
+	MirrorPurchaseOrder(ctx context.Context, purchaseOrderID uint, userID uint) (*dto.IntercompanyTransactionResponse, error)
+	ListTransactions(ctx context.Context, req *dto.IntercompanyTransactionListRequest) ([]dto.IntercompanyTransactionResponse, int64, error)
+	GetTrialBalance(ctx context.Context, req *dto.TrialBalanceRequest) (*dto.TrialBalanceResponse, error)
+	GetConsolidatedTrialBalance(ctx context.Context, req *dto.ConsolidatedTrialBalanceRequest) (*dto.ConsolidatedTrialBalanceResponse, error)
+}
+
+// IntercompanyServiceImpl 内部交易与合并报表服务实现
+type IntercompanyServiceImpl struct {
+	intercompanyRepo repositories.IntercompanyRepository
+	companyRepo      repositories.CompanyRepository
+	journalRepo      repositories.JournalEntryRepository
+}
+
+// NewIntercompanyService 创建内部交易服务实例
+func NewIntercompanyService(
+	intercompanyRepo repositories.IntercompanyRepository,
+	companyRepo repositories.CompanyRepository,
+	journalRepo repositories.JournalEntryRepository,
+) IntercompanyService {
+	return &IntercompanyServiceImpl{
+		intercompanyRepo: intercompanyRepo,
+		companyRepo:      companyRepo,
+		journalRepo:      journalRepo,
+	}
+}
+
+// MirrorSalesOrder 将发给内部客户的销售订单镜像为对方公司的采购订单
+func (s *IntercompanyServiceImpl) MirrorSalesOrder(ctx context.Context, salesOrderID uint, userID uint) (*dto.IntercompanyTransactionResponse, error) {
+	salesOrder, err := s.intercompanyRepo.GetSalesOrderWithItems(ctx, salesOrderID)
+	if err != nil {
+		return nil, errors.New("销售订单不存在")
+	}
+
+	existing, err := s.intercompanyRepo.GetBySource(ctx, models.IntercompanyDocSalesOrder, salesOrder.ID)
+	if err != nil {
+		return nil, fmt.Errorf("检查内部交易失败: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("销售订单已生成内部采购订单 %s", existing.TargetDocNumber)
+	}
+
+	customer, err := s.companyCustomer(ctx, salesOrder.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	targetCompanyID := *customer.RepresentsCompanyID
+	if targetCompanyID == salesOrder.CompanyID {
+		return nil, errors.New("内部客户不能代表订单所属公司")
+	}
+	if _, err := resolveCompanyID(ctx, s.companyRepo, targetCompanyID); err != nil {
+		return nil, err
+	}
+
+	// 对方公司需要有代表本公司的内部供应商
+	supplier, err := s.intercompanyRepo.FindInternalSupplier(ctx, salesOrder.CompanyID)
+	if err != nil {
+		return nil, fmt.Errorf("查找内部供应商失败: %w", err)
+	}
+	if supplier == nil {
+		return nil, fmt.Errorf("未找到代表公司 %d 的内部供应商", salesOrder.CompanyID)
+	}
+
+	purchaseOrder := &models.PurchaseOrder{
+		CompanyID:      targetCompanyID,
+		OrderNumber:    fmt.Sprintf("PO%s%06d", time.Now().Format("20060102"), time.Now().UnixNano()%1000000),
+		SupplierID:     supplier.ID,
+		OrderDate:      salesOrder.Date,
+		DeliveryDate:   salesOrder.DeliveryDate,
+		Status:         "Draft",
+		TotalAmount:    salesOrder.TotalAmount,
+		DiscountAmount: salesOrder.DiscountAmount,
+		TaxAmount:      salesOrder.TaxAmount,
+		GrandTotal:     salesOrder.GrandTotal,
+		Terms:          salesOrder.Terms,
+		Notes:          fmt.Sprintf("内部交易: 源销售订单 %s", salesOrder.OrderNumber),
+		CreatedBy:      userID,
+	}
+	for _, item := range salesOrder.Items {
+		purchaseOrder.Items = append(purchaseOrder.Items, models.PurchaseOrderItem{
+			ItemID:         item.ItemID,
+			Description:    item.Description,
+			Quantity:       item.Quantity,
+			Rate:           item.Rate,
+			Amount:         item.Amount,
+			DiscountRate:   item.DiscountRate,
+			DiscountAmount: item.DiscountAmount,
+			TaxRate:        item.TaxRate,
+			TaxAmount:      item.TaxAmount,
+			TotalAmount:    item.TotalAmount,
+		})
+	}
+
+	link := s.newLink(salesOrder.CompanyID, targetCompanyID, salesOrder.GrandTotal, salesOrder.Date, userID)
+	link.SourceDocType = models.IntercompanyDocSalesOrder
+	link.SourceDocID = salesOrder.ID
+	link.SourceDocNumber = salesOrder.OrderNumber
+	link.TargetDocType = models.IntercompanyDocPurchaseOrder
+
+	if err := s.intercompanyRepo.CreatePurchaseOrderMirror(ctx, purchaseOrder, link); err != nil {
+		return nil, fmt.Errorf("创建内部采购订单失败: %w", err)
+	}
+
+	utils.Info("内部销售订单已镜像",
+		utils.Uint("sales_order_id", salesOrder.ID),
+		utils.Uint("purchase_order_id", purchaseOrder.ID),
+		utils.Uint("target_company_id", targetCompanyID),
+	)
+
+	return s.convertToTransactionResponse(link), nil
+}
+
+// MirrorPurchaseOrder 将向内部供应商下达的采购订单镜像为对方公司的销售订单
+func (s *IntercompanyServiceImpl) MirrorPurchaseOrder(ctx context.Context, purchaseOrderID uint, userID uint) (*dto.IntercompanyTransactionResponse, error) {
+	purchaseOrder, err := s.intercompanyRepo.GetPurchaseOrderWithItems(ctx, purchaseOrderID)
+	if err != nil {
+		return nil, errors.New("采购订单不存在")
+	}
+
+	existing, err := s.intercompanyRepo.GetBySource(ctx, models.IntercompanyDocPurchaseOrder, purchaseOrder.ID)
+	if err != nil {
+		return nil, fmt.Errorf("检查内部交易失败: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("采购订单已生成内部销售订单 %s", existing.TargetDocNumber)
+	}
+
+	supplier, err := s.companySupplier(ctx, purchaseOrder.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	targetCompanyID := *supplier.RepresentsCompanyID
+	if targetCompanyID == purchaseOrder.CompanyID {
+		return nil, errors.New("内部供应商不能代表订单所属公司")
+	}
+	if _, err := resolveCompanyID(ctx, s.companyRepo, targetCompanyID); err != nil {
+		return nil, err
+	}
+
+	// 对方公司需要有代表本公司的内部客户
+	customer, err := s.intercompanyRepo.FindInternalCustomer(ctx, purchaseOrder.CompanyID)
+	if err != nil {
+		return nil, fmt.Errorf("查找内部客户失败: %w", err)
+	}
+	if customer == nil {
+		return nil, fmt.Errorf("未找到代表公司 %d 的内部客户", purchaseOrder.CompanyID)
+	}
+
+	salesOrder := &models.SalesOrder{
+		CompanyID:      targetCompanyID,
+		OrderNumber:    fmt.Sprintf("SO%s%06d", time.Now().Format("20060102"), time.Now().UnixNano()%1000000),
+		CustomerID:     customer.ID,
+		Date:           purchaseOrder.OrderDate,
+		DeliveryDate:   purchaseOrder.DeliveryDate,
+		Status:         "Draft",
+		TotalAmount:    purchaseOrder.TotalAmount,
+		DiscountAmount: purchaseOrder.DiscountAmount,
+		TaxAmount:      purchaseOrder.TaxAmount,
+		GrandTotal:     purchaseOrder.GrandTotal,
+		Terms:          purchaseOrder.Terms,
+		Notes:          fmt.Sprintf("内部交易: 源采购订单 %s", purchaseOrder.OrderNumber),
+		CreatedBy:      userID,
+	}
+	for _, item := range purchaseOrder.Items {
+		salesOrder.Items = append(salesOrder.Items, models.SalesOrderItem{
+			ItemID:         item.ItemID,
+			Description:    item.Description,
+			Quantity:       item.Quantity,
+			Rate:           item.Rate,
+			Amount:         item.Amount,
+			DiscountRate:   item.DiscountRate,
+			DiscountAmount: item.DiscountAmount,
+			TaxRate:        item.TaxRate,
+			TaxAmount:      item.TaxAmount,
+			TotalAmount:    item.TotalAmount,
+		})
+	}
+
+	link := s.newLink(purchaseOrder.CompanyID, targetCompanyID, purchaseOrder.GrandTotal, purchaseOrder.OrderDate, userID)
+	link.SourceDocType = models.IntercompanyDocPurchaseOrder
+	link.SourceDocID = purchaseOrder.ID
+	link.SourceDocNumber = purchaseOrder.OrderNumber
+	link.TargetDocType = models.IntercompanyDocSalesOrder
+
+	if err := s.intercompanyRepo.CreateSalesOrderMirror(ctx, salesOrder, link); err != nil {
+		return nil, fmt.Errorf("创建内部销售订单失败: %w", err)
+	}
+
+	utils.Info("内部采购订单已镜像",
+		utils.Uint("purchase_order_id", purchaseOrder.ID),
+		utils.Uint("sales_order_id", salesOrder.ID),
+		utils.Uint("target_company_id", targetCompanyID),
+	)
+
+	return s.convertToTransactionResponse(link), nil
+}
+
+// ListTransactions 获取内部交易列表
+func (s *IntercompanyServiceImpl) ListTransactions(ctx context.Context, req *dto.IntercompanyTransactionListRequest) ([]dto.IntercompanyTransactionResponse, int64, error) {
+	links, total, err := s.intercompanyRepo.ListWithFilters(ctx, req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取内部交易列表失败: %w", err)
+	}
+
+	responses := make([]dto.IntercompanyTransactionResponse, len(links))
+	for i, link := range links {
+		responses[i] = *s.convertToTransactionResponse(link)
+	}
+	return responses, total, nil
+}
+
+// GetTrialBalance 获取单个公司的试算平衡表
+func (s *IntercompanyServiceImpl) GetTrialBalance(ctx context.Context, req *dto.TrialBalanceRequest) (*dto.TrialBalanceResponse, error) {
+	companyID := req.CompanyID
+	if companyID == 0 {
+		companyID = models.DefaultCompanyID
+	}
+	company, err := s.companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		return nil, errors.New("公司不存在")
+	}
+
+	startDate, endDate, err := trialBalanceRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.journalRepo.GetTrialBalanceRows(ctx, []uint{companyID}, nil, startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("获取试算平衡数据失败: %w", err)
+	}
+
+	lineIndex := make(map[string]*dto.TrialBalanceLine)
+	var codes []string
+	for _, row := range rows {
+		line, ok := lineIndex[row.AccountCode]
+		if !ok {
+			line = &dto.TrialBalanceLine{
+				AccountCode: row.AccountCode,
+				AccountName: row.AccountName,
+				AccountType: row.AccountType,
+			}
+			lineIndex[row.AccountCode] = line
+			codes = append(codes, row.AccountCode)
+		}
+		line.OpeningBalance += row.OpeningBalance
+		line.Debit += row.Debit
+		line.Credit += row.Credit
+	}
+	sort.Strings(codes)
+
+	response := &dto.TrialBalanceResponse{
+		CompanyID:   company.ID,
+		CompanyName: company.Name,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Lines:       make([]dto.TrialBalanceLine, 0, len(codes)),
+	}
+	for _, code := range codes {
+		line := lineIndex[code]
+		line.ClosingBalance = line.OpeningBalance + line.Debit - line.Credit
+		response.TotalDebit += line.Debit
+		response.TotalCredit += line.Credit
+		response.Lines = append(response.Lines, *line)
+	}
+	response.IsBalanced = response.TotalDebit == response.TotalCredit
+
+	return response, nil
+}
+
+// GetConsolidatedTrialBalance 获取合并试算平衡表，合并范围内公司之间的内部往来分录予以抵销
+func (s *IntercompanyServiceImpl) GetConsolidatedTrialBalance(ctx context.Context, req *dto.ConsolidatedTrialBalanceRequest) (*dto.ConsolidatedTrialBalanceResponse, error) {
+	companyIDs, err := s.consolidationScope(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, endDate, err := trialBalanceRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.journalRepo.GetTrialBalanceRows(ctx, companyIDs, companyIDs, startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("获取试算平衡数据失败: %w", err)
+	}
+
+	lineIndex := make(map[string]*dto.ConsolidatedTrialBalanceLine)
+	var codes []string
+	for _, row := range rows {
+		line, ok := lineIndex[row.AccountCode]
+		if !ok {
+			line = &dto.ConsolidatedTrialBalanceLine{
+				AccountCode:            row.AccountCode,
+				AccountName:            row.AccountName,
+				AccountType:            row.AccountType,
+				CompanyClosingBalances: make(map[uint]models.Money),
+			}
+			lineIndex[row.AccountCode] = line
+			codes = append(codes, row.AccountCode)
+		}
+		line.OpeningBalance += row.OpeningBalance
+		line.Debit += row.Debit
+		line.Credit += row.Credit
+		line.CompanyClosingBalances[row.CompanyID] += row.OpeningBalance + row.Debit - row.Credit
+
+		// 合并范围内的内部往来分录全部抵销
+		if row.Intercompany {
+			line.EliminationOpening += row.OpeningBalance
+			line.EliminationDebit += row.Debit
+			line.EliminationCredit += row.Credit
+		}
+	}
+	sort.Strings(codes)
+
+	response := &dto.ConsolidatedTrialBalanceResponse{
+		CompanyIDs: companyIDs,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Lines:      make([]dto.ConsolidatedTrialBalanceLine, 0, len(codes)),
+	}
+	for _, code := range codes {
+		line := lineIndex[code]
+		line.ConsolidatedOpening = line.OpeningBalance - line.EliminationOpening
+		line.ConsolidatedDebit = line.Debit - line.EliminationDebit
+		line.ConsolidatedCredit = line.Credit - line.EliminationCredit
+		line.ConsolidatedClosing = line.ConsolidatedOpening + line.ConsolidatedDebit - line.ConsolidatedCredit
+
+		response.TotalDebit += line.Debit
+		response.TotalCredit += line.Credit
+		response.TotalEliminationDebit += line.EliminationDebit
+		response.TotalEliminationCredit += line.EliminationCredit
+		response.ConsolidatedTotalDebit += line.ConsolidatedDebit
+		response.ConsolidatedTotalCredit += line.ConsolidatedCredit
+		response.Lines = append(response.Lines, *line)
+	}
+	response.IsBalanced = response.ConsolidatedTotalDebit == response.ConsolidatedTotalCredit
+
+	return response, nil
+}
+
+// consolidationScope 确定合并范围：上级公司及其全部下级公司，或显式指定的公司列表
+func (s *IntercompanyServiceImpl) consolidationScope(ctx context.Context, req *dto.ConsolidatedTrialBalanceRequest) ([]uint, error) {
+	if req.ParentCompanyID == nil {
+		if len(req.CompanyIDs) == 0 {
+			return nil, errors.New("请指定上级公司或合并公司列表")
+		}
+		seen := make(map[uint]bool)
+		var companyIDs []uint
+		for _, companyID := range req.CompanyIDs {
+			if seen[companyID] {
+				continue
+			}
+			if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+				return nil, fmt.Errorf("公司 %d 不存在", companyID)
+			}
+			seen[companyID] = true
+			companyIDs = append(companyIDs, companyID)
+		}
+		return companyIDs, nil
+	}
+
+	companies, err := s.companyRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取公司列表失败: %w", err)
+	}
+
+	children := make(map[uint][]uint)
+	found := false
+	for _, company := range companies {
+		if company.ID == *req.ParentCompanyID {
+			found = true
+		}
+		if company.ParentID != nil {
+			children[*company.ParentID] = append(children[*company.ParentID], company.ID)
+		}
+	}
+	if !found {
+		return nil, errors.New("上级公司不存在")
+	}
+
+	companyIDs := []uint{*req.ParentCompanyID}
+	visited := map[uint]bool{*req.ParentCompanyID: true}
+	for i := 0; i < len(companyIDs); i++ {
+		for _, childID := range children[companyIDs[i]] {
+			if !visited[childID] {
+				visited[childID] = true
+				companyIDs = append(companyIDs, childID)
+			}
+		}
+	}
+	return companyIDs, nil
+}
+
+// companyCustomer 获取客户并校验其为内部客户
+func (s *IntercompanyServiceImpl) companyCustomer(ctx context.Context, customerID uint) (*models.Customer, error) {
+	customer, err := s.intercompanyRepo.GetCustomer(ctx, customerID)
+	if err != nil {
+		return nil, errors.New("客户不存在")
+	}
+	if customer.RepresentsCompanyID == nil {
+		return nil, errors.New("客户不是内部客户，无法生成内部交易")
+	}
+	return customer, nil
+}
+
+// companySupplier 获取供应商并校验其为内部供应商
+func (s *IntercompanyServiceImpl) companySupplier(ctx context.Context, supplierID uint) (*models.Supplier, error) {
+	supplier, err := s.intercompanyRepo.GetSupplier(ctx, supplierID)
+	if err != nil {
+		return nil, errors.New("供应商不存在")
+	}
+	if supplier.RepresentsCompanyID == nil {
+		return nil, errors.New("供应商不是内部供应商，无法生成内部交易")
+	}
+	return supplier, nil
+}
+
+// newLink 构造内部交易关联记录
+func (s *IntercompanyServiceImpl) newLink(sourceCompanyID, targetCompanyID uint, amount models.Money, date time.Time, userID uint) *models.IntercompanyTransaction {
+	link := &models.IntercompanyTransaction{
+		SourceCompanyID: sourceCompanyID,
+		TargetCompanyID: targetCompanyID,
+		Amount:          amount,
+		Currency:        models.DefaultCurrency,
+		TransactionDate: date,
+		Status:          "linked",
+	}
+	link.CreatedBy = userID
+	link.UpdatedBy = userID
+	return link
+}
+
+// convertToTransactionResponse 转换内部交易响应
+func (s *IntercompanyServiceImpl) convertToTransactionResponse(link *models.IntercompanyTransaction) *dto.IntercompanyTransactionResponse {
+	response := &dto.IntercompanyTransactionResponse{
+		ID:              link.ID,
+		SourceCompanyID: link.SourceCompanyID,
+		TargetCompanyID: link.TargetCompanyID,
+		SourceDocType:   link.SourceDocType,
+		SourceDocID:     link.SourceDocID,
+		SourceDocNumber: link.SourceDocNumber,
+		TargetDocType:   link.TargetDocType,
+		TargetDocID:     link.TargetDocID,
+		TargetDocNumber: link.TargetDocNumber,
+		Amount:          link.Amount,
+		Currency:        link.Currency,
+		TransactionDate: link.TransactionDate,
+		Status:          link.Status,
+		CreatedAt:       link.CreatedAt,
+	}
+	if link.SourceCompany != nil {
+		response.SourceCompanyName = link.SourceCompany.Name
+	}
+	if link.TargetCompany != nil {
+		response.TargetCompanyName = link.TargetCompany.Name
+	}
+	return response
+}
+
+// trialBalanceRange 校验并规整试算平衡表的日期范围
+func trialBalanceRange(startDate, endDate time.Time) (time.Time, time.Time, error) {
+	startDate = truncateToDay(startDate)
+	endDate = truncateToDay(endDate)
+	if endDate.Before(startDate) {
+		return time.Time{}, time.Time{}, errors.New("结束日期不能早于开始日期")
+	}
+	return startDate, endDate, nil
+}
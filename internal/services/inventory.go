@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/galaxyerp/galaxyErp/internal/common"
@@ -21,16 +22,21 @@ type ItemService interface {
 	DeleteItem(ctx context.Context, id uint) error
 	GetItems(ctx context.Context, req *dto.PaginationRequest) (*dto.PaginatedResponse[dto.ItemResponse], error)
 	SearchItems(ctx context.Context, req *dto.ItemSearchRequest) (*dto.PaginatedResponse[dto.ItemResponse], error)
+	SetTemplateAttributes(ctx context.Context, id uint, req *dto.ItemTemplateAttributesRequest) (*dto.ItemTemplateResponse, error)
+	GetItemTemplate(ctx context.Context, id uint) (*dto.ItemTemplateResponse, error)
+	GenerateVariants(ctx context.Context, id uint, req *dto.ItemVariantGenerateRequest) (*dto.ItemVariantGenerateResponse, error)
 }
 
 // ItemServiceImpl 物料服务实现
 type ItemServiceImpl struct {
 	*BaseService
-	itemRepo repositories.ItemRepository
+	itemRepo    repositories.ItemRepository
+	uomRepo     repositories.UOMRepository
+	variantRepo repositories.ItemVariantRepository
 }
 
 // NewItemService 创建物料服务实例
-func NewItemService(itemRepo repositories.ItemRepository) ItemService {
+func NewItemService(itemRepo repositories.ItemRepository, uomRepo repositories.UOMRepository, variantRepo repositories.ItemVariantRepository) ItemService {
 	config := &BaseServiceConfig{
 		EnableAudit:      true,
 		EnableValidation: true,
@@ -42,6 +48,8 @@ func NewItemService(itemRepo repositories.ItemRepository) ItemService {
 	return &ItemServiceImpl{
 		BaseService: NewBaseService(config),
 		itemRepo:    itemRepo,
+		uomRepo:     uomRepo,
+		variantRepo: variantRepo,
 	}
 }
 
@@ -52,18 +60,40 @@ func (s *ItemServiceImpl) Create(ctx context.Context, req *dto.ItemCreateRequest
 		return nil, err
 	}
 
+	// 库存单位必须在计量单位中定义
+	unit, err := s.resolveStockUnit(ctx, req.UnitID)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建物料
 	item := &models.Item{
 		Code:        req.Code,
 		Name:        req.Name,
 		Description: req.Description,
-		// TODO: 需要根据CategoryID和UnitID查询对应的名称
+		// TODO: 需要根据CategoryID查询对应的名称
 		// Category:     req.Category,
-		// Unit:         req.Unit,
-		Cost:         req.UnitCost,
-		Price:        req.SalePrice,
-		ReorderLevel: int(req.MinStock),
-		IsActive:     true,
+		Unit:                  unit.Code,
+		Cost:                  req.UnitCost,
+		Price:                 req.SalePrice,
+		ReorderLevel:          int(req.MinStock),
+		ReorderQty:            req.ReorderQty,
+		MaxLevel:              req.MaxStock,
+		IsActive:              true,
+		ValuationMethod:       req.ValuationMethod,
+		TrackingMode:          req.TrackingMode,
+		ShelfLifeDays:         req.ShelfLifeDays,
+		Weight:                req.Weight,
+		OverDeliveryAllowance: req.OverDeliveryAllowance,
+	}
+	if item.ValuationMethod == "" {
+		item.ValuationMethod = models.ValuationMethodMovingAverage
+	}
+	if item.TrackingMode == "" {
+		item.TrackingMode = models.TrackingModeNone
+	}
+	if req.PreferredSupplierID != nil && *req.PreferredSupplierID != 0 {
+		item.PreferredSupplierID = req.PreferredSupplierID
 	}
 
 	if err := s.itemRepo.Create(ctx, item); err != nil {
@@ -136,22 +166,83 @@ func (s *ItemServiceImpl) Update(ctx context.Context, id uint, req *dto.ItemUpda
 	if req.Description != "" {
 		item.Description = req.Description
 	}
-	// TODO: 需要根据CategoryID和UnitID查询对应的名称
+	// TODO: 需要根据CategoryID查询对应的名称
 	// if req.CategoryID != nil {
 	//     item.Category = getCategoryName(*req.CategoryID)
 	// }
-	// if req.UnitID != nil {
-	//     item.Unit = getUnitName(*req.UnitID)
-	// }
+	var unit *models.UOM
+	if req.UnitID != nil {
+		if unit, err = s.resolveStockUnit(ctx, *req.UnitID); err != nil {
+			return nil, err
+		}
+	}
+	// 计价方法变更、标准成本物料调整标准成本都会使现有库存金额失真，仅允许在无库存时进行；
+	// 跟踪方式变更后现有库存没有对应的批次、序列号余额，同样仅允许在无库存时进行；
+	// 库存单位变更后现有库存数量的含义随之改变，同样仅允许在无库存时进行
+	valuationChanged := req.ValuationMethod != "" && req.ValuationMethod != item.ValuationMethod
+	standardCostChanged := req.UnitCost != nil && *req.UnitCost != item.Cost && item.ValuationMethod == models.ValuationMethodStandard
+	trackingChanged := req.TrackingMode != "" && req.TrackingMode != item.TrackingMode
+	unitChanged := unit != nil && unit.Code != item.Unit
+	if valuationChanged || standardCostChanged || trackingChanged || unitChanged {
+		quantity, err := s.itemRepo.GetStockQuantity(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("获取物料库存失败: %w", err)
+		}
+		if quantity != 0 {
+			if valuationChanged {
+				return nil, fmt.Errorf("物料仍有库存 %.2f，不能修改计价方法", quantity)
+			}
+			if trackingChanged {
+				return nil, fmt.Errorf("物料仍有库存 %.2f，不能修改批次、序列号跟踪方式", quantity)
+			}
+			if unitChanged {
+				return nil, fmt.Errorf("物料仍有库存 %.2f，不能修改库存单位", quantity)
+			}
+			return nil, fmt.Errorf("标准成本物料仍有库存 %.2f，不能修改标准成本", quantity)
+		}
+	}
+	if valuationChanged {
+		item.ValuationMethod = req.ValuationMethod
+	}
+	if trackingChanged {
+		item.TrackingMode = req.TrackingMode
+	}
+	if unitChanged {
+		item.Unit = unit.Code
+	}
 	if req.UnitCost != nil {
 		item.Cost = *req.UnitCost
 	}
 	if req.SalePrice != nil {
 		item.Price = *req.SalePrice
 	}
+	if req.MinStock != nil {
+		item.ReorderLevel = int(*req.MinStock)
+	}
+	if req.MaxStock != nil {
+		item.MaxLevel = *req.MaxStock
+	}
+	if req.ReorderQty != nil {
+		item.ReorderQty = *req.ReorderQty
+	}
+	if req.PreferredSupplierID != nil {
+		item.PreferredSupplierID = req.PreferredSupplierID
+		if *req.PreferredSupplierID == 0 {
+			item.PreferredSupplierID = nil
+		}
+	}
 	if req.IsActive != nil {
 		item.IsActive = *req.IsActive
 	}
+	if req.ShelfLifeDays != nil {
+		item.ShelfLifeDays = *req.ShelfLifeDays
+	}
+	if req.OverDeliveryAllowance != nil {
+		item.OverDeliveryAllowance = *req.OverDeliveryAllowance
+	}
+	if req.Weight != nil {
+		item.Weight = *req.Weight
+	}
 	item.UpdatedAt = time.Now()
 
 	if err := s.itemRepo.Update(ctx, item); err != nil {
@@ -184,6 +275,15 @@ func (s *ItemServiceImpl) Delete(ctx context.Context, id uint) (*dto.DeleteRespo
 	if item == nil {
 		return nil, errors.New("物料不存在")
 	}
+	if item.HasVariants {
+		count, err := s.variantRepo.CountVariants(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("获取物料变体失败: %w", err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("模板物料仍有 %d 个变体，不能删除", count)
+		}
+	}
 
 	if err := s.itemRepo.Delete(ctx, id); err != nil {
 		return nil, fmt.Errorf("删除物料失败: %w", err)
@@ -270,14 +370,42 @@ func (s *ItemServiceImpl) GetItems(ctx context.Context, req *dto.PaginationReque
 	return s.List(ctx, req)
 }
 
-// SearchItems 搜索物料
+// SearchItems 按关键字、模板与变体属性取值搜索物料
 func (s *ItemServiceImpl) SearchItems(ctx context.Context, req *dto.ItemSearchRequest) (*dto.PaginatedResponse[dto.ItemResponse], error) {
-	// 转换为通用搜索请求
-	searchReq := &dto.SearchRequest{
-		PaginationRequest: req.PaginationRequest,
-		Keyword:           req.Keyword,
+	filter := repositories.ItemSearchFilter{
+		Keyword:     req.Keyword,
+		HasVariants: req.HasVariants,
+		IsActive:    req.IsActive,
+		Attributes:  req.Attributes,
+	}
+	if req.TemplateID != nil {
+		filter.TemplateID = *req.TemplateID
 	}
-	return s.Search(ctx, searchReq)
+
+	offset := req.GetOffset()
+	limit := req.GetLimit()
+	items, total, err := s.itemRepo.SearchItems(ctx, filter, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("搜索物料失败: %w", err)
+	}
+
+	itemResponses := make([]dto.ItemResponse, len(items))
+	for i, item := range items {
+		itemResponses[i] = *s.toItemResponse(item)
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit > 0 {
+		totalPages++
+	}
+
+	return &dto.PaginatedResponse[dto.ItemResponse]{
+		Data:       itemResponses,
+		Total:      total,
+		Page:       req.Page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
 }
 
 // toItemResponse 转换为物料响应格式
@@ -288,15 +416,41 @@ func (s *ItemServiceImpl) toItemResponse(item *models.Item) *dto.ItemResponse {
 		Code:        item.Code,
 		Description: item.Description,
 		// TODO: 需要根据字符串字段映射到对应的ID和对象
-		// MinStock:    float64(item.ReorderLevel),
-		// MaxStock:    0, // 当前模型中没有MaxStock字段
-		UnitCost:  item.Cost,
-		SalePrice: item.Price,
+		MinStock:            float64(item.ReorderLevel),
+		MaxStock:            item.MaxLevel,
+		ReorderQty:          item.ReorderQty,
+		PreferredSupplierID: item.PreferredSupplierID,
+		UnitCost:            item.Cost,
+		SalePrice:           item.Price,
+		Unit:                dto.UnitResponse{Name: item.Unit, Symbol: item.Unit, IsActive: true},
 		// Barcode:     "", // 当前模型中没有Barcode字段
-		IsActive:  item.IsActive,
-		CreatedAt: item.CreatedAt,
-		UpdatedAt: item.UpdatedAt,
+		IsActive:              item.IsActive,
+		ValuationMethod:       item.ValuationMethod,
+		TrackingMode:          item.TrackingMode,
+		ShelfLifeDays:         item.ShelfLifeDays,
+		Weight:                item.Weight,
+		OverDeliveryAllowance: item.OverDeliveryAllowance,
+		HasVariants:           item.HasVariants,
+		VariantOf:             item.VariantOf,
+		Attributes:            toVariantAttributeResponses(item.VariantAttributes),
+		CreatedAt:             item.CreatedAt,
+		UpdatedAt:             item.UpdatedAt,
+	}
+}
+
+// resolveStockUnit 获取用作库存单位的计量单位，必须已定义且启用
+func (s *ItemServiceImpl) resolveStockUnit(ctx context.Context, unitID uint) (*models.UOM, error) {
+	unit, err := s.uomRepo.GetUOM(ctx, unitID)
+	if err != nil {
+		return nil, fmt.Errorf("获取计量单位失败: %w", err)
 	}
+	if unit == nil {
+		return nil, fmt.Errorf("计量单位 %d 未定义", unitID)
+	}
+	if !unit.IsActive {
+		return nil, fmt.Errorf("计量单位 %s 已停用", unit.Code)
+	}
+	return unit, nil
 }
 
 // StockService 库存服务接口
@@ -308,16 +462,20 @@ type StockService interface {
 	UpdateStock(ctx context.Context, id uint, quantity float64) (*dto.StockResponse, error)
 	GetByItemID(ctx context.Context, itemID uint) ([]*dto.StockResponse, error)
 	AdjustStock(ctx context.Context, req *dto.StockAdjustmentCreateRequest) (*dto.StockAdjustmentResponse, error)
+	ReconcileStock(ctx context.Context, req *dto.StockReconcileRequest) (*dto.StockReconcileResult, error)
+	EnsureOpeningBalances(ctx context.Context) (int, error)
 }
 
 // StockServiceImpl 库存服务实现
 type StockServiceImpl struct {
 	*BaseService
-	stockRepo repositories.StockRepository
+	stockRepo       repositories.StockRepository
+	ledgerRepo      repositories.StockLedgerRepository
+	reservationRepo repositories.ReservationRepository
 }
 
 // NewStockService 创建库存服务
-func NewStockService(stockRepo repositories.StockRepository) StockService {
+func NewStockService(stockRepo repositories.StockRepository, ledgerRepo repositories.StockLedgerRepository, reservationRepo repositories.ReservationRepository) StockService {
 	config := &BaseServiceConfig{
 		EnableAudit:      true,
 		EnableValidation: true,
@@ -327,8 +485,10 @@ func NewStockService(stockRepo repositories.StockRepository) StockService {
 	}
 	
 	return &StockServiceImpl{
-		BaseService: NewBaseService(config),
-		stockRepo:   stockRepo,
+		BaseService:     NewBaseService(config),
+		stockRepo:       stockRepo,
+		ledgerRepo:      ledgerRepo,
+		reservationRepo: reservationRepo,
 	}
 }
 
@@ -339,14 +499,12 @@ func (s *StockServiceImpl) Create(ctx context.Context, req *dto.StockCreateReque
 		return nil, err
 	}
 
-	// 创建库存记录
-	stock := &models.Stock{
-		ItemID:      req.ItemID,
-		WarehouseID: req.WarehouseID,
-		Quantity:    req.Quantity,
+	// 库存记录由台账过账创建，初始数量记为一笔调整
+	if _, err := postStockAdjustment(ctx, s.ledgerRepo, req.ItemID, req.WarehouseID, req.Quantity, "创建库存"); err != nil {
+		return nil, err
 	}
-
-	if err := s.stockRepo.Create(ctx, stock); err != nil {
+	stock, err := s.findStock(ctx, req.ItemID, req.WarehouseID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -392,13 +550,13 @@ func (s *StockServiceImpl) Update(ctx context.Context, id uint, req *dto.StockUp
 		return nil, err
 	}
 
-	// 更新字段
+	// 数量变更通过台账调整过账
 	if req.Quantity != nil {
-		stock.Quantity = *req.Quantity
-	}
-
-	if err := s.stockRepo.Update(ctx, stock); err != nil {
-		return nil, err
+		movement, err := postStockAdjustment(ctx, s.ledgerRepo, stock.ItemID, stock.WarehouseID, *req.Quantity, "库存更新")
+		if err != nil {
+			return nil, err
+		}
+		stock.Quantity = movement.BalanceAfter
 	}
 
 	// 清除缓存
@@ -416,6 +574,12 @@ func (s *StockServiceImpl) Delete(ctx context.Context, id uint) (*dto.DeleteResp
 	if err != nil {
 		return nil, err
 	}
+	if stock == nil {
+		return nil, errors.New("库存不存在")
+	}
+	if err := checkStockDeletable(stock); err != nil {
+		return nil, err
+	}
 
 	if err := s.stockRepo.Delete(ctx, id); err != nil {
 		return nil, err
@@ -508,13 +672,13 @@ func (s *StockServiceImpl) UpdateStock(ctx context.Context, id uint, quantity fl
 		return nil, errors.New("库存不存在")
 	}
 
-	// 更新库存数量
-	stock.Quantity = quantity
+	// 通过台账调整库存数量
 	// TODO: 需要实现预留数量逻辑
-
-	if err := s.stockRepo.Update(ctx, stock); err != nil {
+	movement, err := postStockAdjustment(ctx, s.ledgerRepo, stock.ItemID, stock.WarehouseID, quantity, "库存更新")
+	if err != nil {
 		return nil, fmt.Errorf("更新库存失败: %w", err)
 	}
+	stock.Quantity = movement.BalanceAfter
 
 	return s.toStockResponse(stock), nil
 }
@@ -528,6 +692,9 @@ func (s *StockServiceImpl) DeleteStock(ctx context.Context, id uint) error {
 	if stock == nil {
 		return errors.New("库存不存在")
 	}
+	if err := checkStockDeletable(stock); err != nil {
+		return err
+	}
 
 	if err := s.stockRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("删除库存失败: %w", err)
@@ -536,6 +703,14 @@ func (s *StockServiceImpl) DeleteStock(ctx context.Context, id uint) error {
 	return nil
 }
 
+// checkStockDeletable 库存余额是台账的汇总，仍有数量或金额时删除会使余额与台账不一致，须先通过库存调整清零
+func checkStockDeletable(stock *models.Stock) error {
+	if math.Abs(stock.Quantity) > stockQuantityTolerance || !stock.StockValue.IsZero() {
+		return fmt.Errorf("库存数量为 %.2f、金额为 %s，请先通过库存调整清零后再删除", stock.Quantity, stock.StockValue.StringFixed(2))
+	}
+	return nil
+}
+
 // GetStocks 获取库存列表
 func (s *StockServiceImpl) GetStocks(ctx context.Context, req *dto.PaginationRequest) (*dto.PaginatedResponse[dto.StockResponse], error) {
 	stocks, total, err := s.stockRepo.List(ctx, &common.QueryOptions{
@@ -560,16 +735,24 @@ func (s *StockServiceImpl) GetStocks(ctx context.Context, req *dto.PaginationReq
 	}, nil
 }
 
-// GetByItemID 根据物料ID获取库存
+// GetByItemID 根据物料ID获取库存，可承诺量为现有库存减去销售订单已分配的预留
 func (s *StockServiceImpl) GetByItemID(ctx context.Context, itemID uint) ([]*dto.StockResponse, error) {
 	stocks, err := s.stockRepo.GetByItemID(ctx, itemID)
 	if err != nil {
 		return nil, fmt.Errorf("获取物料库存失败: %w", err)
 	}
 
+	reserved, err := s.reservationRepo.GetReservedQuantities(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("获取库存预留失败: %w", err)
+	}
+
 	var stockResponses []*dto.StockResponse
 	for _, stock := range stocks {
-		stockResponses = append(stockResponses, s.toStockResponse(stock))
+		response := s.toStockResponse(stock)
+		response.ReservedQty = reserved[stock.WarehouseID]
+		response.AvailableQty = stock.Quantity - response.ReservedQty
+		stockResponses = append(stockResponses, response)
 	}
 
 	return stockResponses, nil
@@ -582,14 +765,30 @@ func (s *StockServiceImpl) AdjustStock(ctx context.Context, req *dto.StockAdjust
 	return nil, errors.New("方法未实现")
 }
 
+// findStock 获取物料在仓库的库存记录
+func (s *StockServiceImpl) findStock(ctx context.Context, itemID, warehouseID uint) (*models.Stock, error) {
+	stocks, err := s.stockRepo.GetByItemID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	for _, stock := range stocks {
+		if stock.WarehouseID == warehouseID {
+			return stock, nil
+		}
+	}
+	return nil, errors.New("库存不存在")
+}
+
 // toStockResponse 转换为库存响应格式
 func (s *StockServiceImpl) toStockResponse(stock *models.Stock) *dto.StockResponse {
 	response := &dto.StockResponse{
-		ID:           stock.ID,
-		Quantity:     stock.Quantity,
-		ReservedQty:  0,              // TODO: 需要实现预留数量逻辑
-		AvailableQty: stock.Quantity, // 暂时设为总数量
-		UpdatedAt:    stock.UpdatedAt,
+		ID:            stock.ID,
+		Quantity:      stock.Quantity,
+		ReservedQty:   0,              // 预留数量由 GetByItemID 按仓库填充
+		AvailableQty:  stock.Quantity, // 未填充预留时等于现有库存
+		StockValue:    stock.StockValue,
+		ValuationRate: stock.ValuationRate,
+		UpdatedAt:     stock.UpdatedAt,
 	}
 
 	// 填充Item信息
@@ -894,16 +1093,22 @@ type MovementServiceImpl struct {
 	*BaseService
 	movementRepo  repositories.MovementRepository
 	stockRepo     repositories.StockRepository
+	ledgerRepo    repositories.StockLedgerRepository
 	itemRepo      repositories.ItemRepository
 	warehouseRepo repositories.WarehouseRepository
+	batchRepo     repositories.BatchRepository
+	uomService    UOMService
 }
 
 // NewMovementService 创建库存移动服务
 func NewMovementService(
 	movementRepo repositories.MovementRepository,
 	stockRepo repositories.StockRepository,
+	ledgerRepo repositories.StockLedgerRepository,
 	itemRepo repositories.ItemRepository,
 	warehouseRepo repositories.WarehouseRepository,
+	batchRepo repositories.BatchRepository,
+	uomService UOMService,
 ) MovementService {
 	config := &BaseServiceConfig{
 		EnableAudit:      true,
@@ -917,8 +1122,11 @@ func NewMovementService(
 		BaseService:   NewBaseService(config),
 		movementRepo:  movementRepo,
 		stockRepo:     stockRepo,
+		ledgerRepo:    ledgerRepo,
 		itemRepo:      itemRepo,
 		warehouseRepo: warehouseRepo,
+		batchRepo:     batchRepo,
+		uomService:    uomService,
 	}
 }
 
@@ -981,27 +1189,111 @@ func (s *MovementServiceImpl) CreateMovement(ctx context.Context, req dto.Moveme
 		return nil, fmt.Errorf("仓库不存在: %w", err)
 	}
 
-	// 创建库存移动记录
+	// 数量与单位成本按单据单位填写，换算为库存单位后过账
+	conversion, err := s.uomService.ConvertToStock(ctx, req.ItemID, req.UOM, req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	quantity := conversion.StockQuantity
+	unitCost := req.UnitCost
+	if conversion.ConversionFactor != 1 {
+		if unitCost, err = req.UnitCost.Div(conversion.ConversionFactor); err != nil {
+			return nil, fmt.Errorf("换算单位成本失败: %w", err)
+		}
+	}
+
+	// 库存移动与库存余额在同一事务中过账，同一来源单据行重复提交时返回已过账的记录
 	movement := &models.Movement{
-		ItemID:       &req.ItemID,
-		WarehouseID:  &req.WarehouseID,
-		Quantity:     &req.Quantity,
-		MovementType: req.Type,
-		Reference:    req.Reference,
-		Notes:        req.Notes,
+		ItemID:           &req.ItemID,
+		WarehouseID:      &req.WarehouseID,
+		Quantity:         &quantity,
+		UOM:              conversion.UOM,
+		UOMQuantity:      conversion.Quantity,
+		ConversionFactor: conversion.ConversionFactor,
+		MovementType:     req.Type,
+		Reference:        req.Reference,
+		Notes:            req.Notes,
+		UnitCost:         unitCost,
+		ReferenceType:    req.ReferenceType,
+		ReferenceID:      req.ReferenceID,
+		ReferenceLineID:  req.ReferenceLineID,
+		IdempotencyKey:   movementIdempotencyKey(&req),
+		BatchNo:          req.BatchNo,
+		SerialNo:         req.SerialNo,
+		ExpiryDate:       req.ExpiryDate,
+	}
+	if req.LocationID != 0 {
+		movement.LocationID = &req.LocationID
+	}
+
+	// 批次管理物料出库未指定批次时，按先到期先出分配到未过期的批次
+	if movement.MovementType == models.MovementTypeOut && movement.BatchNo == "" && item.TrackingMode == models.TrackingModeBatch {
+		return s.postFEFOMovements(ctx, movement, item, warehouse)
+	}
+
+	if _, err := s.ledgerRepo.PostMovement(ctx, movement); err != nil {
+		return nil, fmt.Errorf("创建库存移动失败: %w", err)
 	}
 
-	err = s.movementRepo.Create(ctx, movement)
+	return s.convertToMovementResponse(movement, item, warehouse), nil
+}
+
+// postFEFOMovements 将出库数量按先到期先出分配到未过期的批次，每个批次一条库存移动，在一个事务中过账。
+// 返回第一条移动，BatchAllocations 列出全部批次的分配；后续移动的幂等键在原键后追加序号
+func (s *MovementServiceImpl) postFEFOMovements(ctx context.Context, base *models.Movement, item *models.Item, warehouse *models.Warehouse) (*dto.MovementResponse, error) {
+	if base.IdempotencyKey != nil {
+		existing, err := s.ledgerRepo.GetByIdempotencyKey(ctx, *base.IdempotencyKey)
+		if err != nil {
+			return nil, fmt.Errorf("检查库存移动失败: %w", err)
+		}
+		if existing != nil {
+			return s.convertToMovementResponse(existing, item, warehouse), nil
+		}
+	}
+
+	var locationID uint
+	if base.LocationID != nil {
+		locationID = *base.LocationID
+	}
+	batches, err := s.batchRepo.GetFEFOBatches(ctx, item.ID, warehouse.ID, locationID, time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("创建库存移动失败: %w", err)
+		return nil, fmt.Errorf("获取批次库存失败: %w", err)
 	}
 
-	// 更新库存数量
-	if err := s.updateStockQuantity(ctx, req.ItemID, req.WarehouseID, req.Quantity, req.Type); err != nil {
-		return nil, fmt.Errorf("更新库存失败: %w", err)
+	remaining := *base.Quantity
+	movements := make([]*models.Movement, 0, len(batches))
+	allocations := make([]dto.MovementBatchAllocation, 0, len(batches))
+	for _, batch := range batches {
+		if remaining <= stockQuantityTolerance {
+			break
+		}
+		quantity := math.Min(remaining, batch.Quantity)
+		movement := *base
+		movement.Quantity = &quantity
+		movement.UOMQuantity = base.UOMQuantity * quantity / *base.Quantity
+		movement.BatchNo = batch.BatchNo
+		if len(movements) > 0 && base.IdempotencyKey != nil {
+			key := fmt.Sprintf("%s:%d", *base.IdempotencyKey, len(movements)+1)
+			movement.IdempotencyKey = &key
+		}
+		movements = append(movements, &movement)
+		allocations = append(allocations, dto.MovementBatchAllocation{BatchNo: batch.BatchNo, ExpiryDate: batch.ExpiryDate, Quantity: quantity})
+		remaining -= quantity
+	}
+	if remaining > stockQuantityTolerance {
+		return nil, fmt.Errorf("创建库存移动失败: %w，物料 %s 未过期批次的可用库存还差 %.2f", repositories.ErrInsufficientStock, item.Code, remaining)
 	}
 
-	return s.convertToMovementResponse(movement, item, warehouse), nil
+	if err := s.ledgerRepo.PostMovements(ctx, movements); err != nil {
+		return nil, fmt.Errorf("创建库存移动失败: %w", err)
+	}
+
+	response := s.convertToMovementResponse(movements[0], item, warehouse)
+	for i, movement := range movements {
+		allocations[i].MovementID = movement.ID
+	}
+	response.BatchAllocations = allocations
+	return response, nil
 }
 
 // GetMovementByID 根据ID获取库存移动
@@ -1171,59 +1463,25 @@ func (s *MovementServiceImpl) GetMovementsByType(ctx context.Context, movementTy
 	}, nil
 }
 
-// updateStockQuantity 更新库存数量
-func (s *MovementServiceImpl) updateStockQuantity(ctx context.Context, itemID, warehouseID uint, quantity float64, movementType string) error {
-	// 查找现有库存记录
-	stocks, err := s.stockRepo.GetByItemID(ctx, itemID)
-	if err != nil {
-		return err
-	}
-
-	var targetStock *models.Stock
-	for _, stock := range stocks {
-		if stock.WarehouseID == warehouseID {
-			targetStock = stock
-			break
-		}
-	}
-
-	// 如果没有找到库存记录，创建新的
-	if targetStock == nil {
-		targetStock = &models.Stock{
-			ItemID:      itemID,
-			WarehouseID: warehouseID,
-			Quantity:    0,
-		}
-		err = s.stockRepo.Create(ctx, targetStock)
-		if err != nil {
-			return fmt.Errorf("创建库存记录失败: %w", err)
-		}
-	}
-
-	// 根据移动类型调整库存数量
-	switch movementType {
-	case "in":
-		targetStock.Quantity += quantity
-	case "out":
-		if targetStock.Quantity < quantity {
-			return fmt.Errorf("库存不足，当前库存: %.2f，需要: %.2f", targetStock.Quantity, quantity)
-		}
-		targetStock.Quantity -= quantity
-	case "adjustment":
-		targetStock.Quantity = quantity
-	}
-
-	return s.stockRepo.Update(ctx, targetStock)
-}
-
 // convertToMovementResponse 转换为移动响应
 func (s *MovementServiceImpl) convertToMovementResponse(movement *models.Movement, item *models.Item, warehouse *models.Warehouse) *dto.MovementResponse {
 	response := &dto.MovementResponse{
-		ID:        movement.ID,
-		Type:      movement.MovementType,
-		Reference: movement.Reference,
-		Notes:     movement.Notes,
-		CreatedAt: movement.CreatedAt,
+		ID:               movement.ID,
+		Type:             movement.MovementType,
+		UnitCost:         movement.UnitCost,
+		TotalCost:        movement.TotalCost,
+		ValueChange:      movement.ValueChange,
+		ValueAfter:       movement.ValueAfter,
+		BatchNo:          movement.BatchNo,
+		SerialNo:         movement.SerialNo,
+		Reference:        movement.Reference,
+		Notes:            movement.Notes,
+		CreatedAt:        movement.CreatedAt,
+		QuantityChange:   movement.QuantityChange,
+		BalanceAfter:     movement.BalanceAfter,
+		UOM:              movement.UOM,
+		UOMQuantity:      movement.UOMQuantity,
+		ConversionFactor: movement.ConversionFactor,
 	}
 
 	if movement.Quantity != nil {
@@ -1265,6 +1523,12 @@ func (s *MovementServiceImpl) convertToMovementResponse(movement *models.Movemen
 			UpdatedAt:   warehouse.UpdatedAt,
 		}
 	}
+	if movement.LocationID != nil {
+		response.Location = dto.LocationResponse{ID: *movement.LocationID}
+		if movement.WarehouseID != nil {
+			response.Location.WarehouseID = *movement.WarehouseID
+		}
+	}
 
 	return response
 }
@@ -0,0 +1,814 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// ABC 分析默认参数
+const (
+	DefaultABCDays       = 365
+	DefaultABCAThreshold = 80.0
+	DefaultABCBThreshold = 95.0
+)
+
+// 库存账龄与呆滞库存默认参数
+const (
+	DefaultAgingBuckets               = "30,60,90,180"
+	DefaultSlowMovingDays             = 90
+	DefaultDeadStockDays              = 180
+	DefaultSlowMovingWriteDownPercent = 50.0
+	DefaultDeadStockWriteDownPercent  = 100.0
+	DefaultWriteDownExpenseCode       = "5711" // 存货减值损失科目（营业外支出）
+	DefaultWriteDownProvisionCode     = "1471" // 存货跌价准备科目
+)
+
+// 呆滞库存状态
+const (
+	StockStatusSlowMoving = "slow_moving"
+	StockStatusDead       = "dead"
+)
+
+// InventoryReportService 库存报表服务接口
+type InventoryReportService interface {
+	GetInventoryStats(ctx context.Context, req *dto.InventoryStatsRequest) (*dto.InventoryStatsResponse, error)
+	GetInventoryReport(ctx context.Context, req *dto.InventoryReportRequest) ([]dto.InventoryReportLine, int64, error)
+	GetABCAnalysis(ctx context.Context, req *dto.ABCAnalysisRequest) (*dto.ABCAnalysisResponse, error)
+	ExportStats(ctx context.Context, req *dto.InventoryStatsRequest, format string) ([]byte, error)
+	ExportReport(ctx context.Context, filter *dto.InventoryReportFilter, format string) ([]byte, error)
+	ExportABCAnalysis(ctx context.Context, req *dto.ABCAnalysisRequest, format string) ([]byte, error)
+	GetInventoryAging(ctx context.Context, req *dto.InventoryAgingRequest) (*dto.InventoryAgingResponse, error)
+	GetSlowMovingStock(ctx context.Context, req *dto.SlowMovingRequest) (*dto.SlowMovingResponse, error)
+	GetWriteDownProposal(ctx context.Context, req *dto.InventoryWriteDownRequest) (*dto.InventoryWriteDownResponse, error)
+	ExportAging(ctx context.Context, req *dto.InventoryAgingRequest, format string) ([]byte, error)
+	ExportSlowMoving(ctx context.Context, req *dto.SlowMovingRequest, format string) ([]byte, error)
+	ExportWriteDownProposal(ctx context.Context, req *dto.InventoryWriteDownRequest, format string) ([]byte, error)
+}
+
+// InventoryReportServiceImpl 库存报表服务实现
+type InventoryReportServiceImpl struct {
+	reportRepo  repositories.InventoryReportRepository
+	accountRepo repositories.AccountRepository
+	companyRepo repositories.CompanyRepository
+}
+
+// NewInventoryReportService 创建库存报表服务实例
+func NewInventoryReportService(reportRepo repositories.InventoryReportRepository, accountRepo repositories.AccountRepository, companyRepo repositories.CompanyRepository) InventoryReportService {
+	return &InventoryReportServiceImpl{
+		reportRepo:  reportRepo,
+		accountRepo: accountRepo,
+		companyRepo: companyRepo,
+	}
+}
+
+// GetInventoryStats 按仓库统计物料数、库存数量、库存金额和低库存物料数
+func (s *InventoryReportServiceImpl) GetInventoryStats(ctx context.Context, req *dto.InventoryStatsRequest) (*dto.InventoryStatsResponse, error) {
+	rows, _, err := s.reportRepo.GetStockRows(ctx, repositories.InventoryStockFilter{
+		WarehouseID: req.WarehouseID,
+		IncludeZero: true,
+	}, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("获取库存数据失败: %w", err)
+	}
+
+	stats := &dto.InventoryStatsResponse{Warehouses: []dto.WarehouseStockStats{}}
+	index := make(map[uint]int)
+	items := make(map[uint]bool)
+	for _, row := range rows {
+		i, ok := index[row.WarehouseID]
+		if !ok {
+			stats.Warehouses = append(stats.Warehouses, dto.WarehouseStockStats{
+				WarehouseID:   row.WarehouseID,
+				WarehouseCode: row.WarehouseCode,
+				WarehouseName: row.WarehouseName,
+			})
+			i = len(stats.Warehouses) - 1
+			index[row.WarehouseID] = i
+		}
+
+		warehouse := &stats.Warehouses[i]
+		if row.Quantity > 0 {
+			warehouse.SKUCount++
+			items[row.ItemID] = true
+		}
+		if isLowStock(row) {
+			warehouse.LowStockCount++
+		}
+		warehouse.TotalQuantity += row.Quantity
+		warehouse.TotalValue = warehouse.TotalValue.Add(row.StockValue)
+		stats.TotalQuantity += row.Quantity
+		stats.TotalValue = stats.TotalValue.Add(row.StockValue)
+	}
+	stats.SKUCount = len(items)
+
+	if req.WarehouseID != 0 {
+		// 指定仓库时按该仓库的低库存物料数统计
+		for _, warehouse := range stats.Warehouses {
+			stats.LowStockCount += warehouse.LowStockCount
+		}
+		return stats, nil
+	}
+
+	lowStock, err := s.reportRepo.CountLowStockItems(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("统计低库存物料失败: %w", err)
+	}
+	stats.LowStockCount = int(lowStock)
+	return stats, nil
+}
+
+// GetInventoryReport 分页获取库存明细报表
+func (s *InventoryReportServiceImpl) GetInventoryReport(ctx context.Context, req *dto.InventoryReportRequest) ([]dto.InventoryReportLine, int64, error) {
+	rows, total, err := s.reportRepo.GetStockRows(ctx, stockFilter(&req.InventoryReportFilter), req.GetOffset(), req.GetLimit())
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取库存报表失败: %w", err)
+	}
+	return toInventoryReportLines(rows), total, nil
+}
+
+// GetABCAnalysis 按期间出库成本对物料做 ABC 分类：
+// 消耗金额降序排列，累计占比不超过 A 类阈值为 A 类，不超过 B 类阈值为 B 类，其余及无消耗的物料为 C 类
+func (s *InventoryReportServiceImpl) GetABCAnalysis(ctx context.Context, req *dto.ABCAnalysisRequest) (*dto.ABCAnalysisResponse, error) {
+	aThreshold, bThreshold := req.AThreshold, req.BThreshold
+	if aThreshold == 0 {
+		aThreshold = DefaultABCAThreshold
+	}
+	if bThreshold == 0 {
+		bThreshold = DefaultABCBThreshold
+	}
+	if bThreshold < aThreshold {
+		return nil, errors.New("B 类阈值不能小于 A 类阈值")
+	}
+
+	startDate, endDate, err := abcAnalysisRange(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.reportRepo.GetConsumption(ctx, startDate, endDate.AddDate(0, 0, 1), req.WarehouseID, req.Category, req.GroupByTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料消耗失败: %w", err)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].ConsumptionValue != rows[j].ConsumptionValue {
+			return rows[i].ConsumptionValue > rows[j].ConsumptionValue
+		}
+		return rows[i].ItemCode < rows[j].ItemCode
+	})
+
+	response := &dto.ABCAnalysisResponse{
+		StartDate:  startDate,
+		EndDate:    endDate,
+		AThreshold: aThreshold,
+		BThreshold: bThreshold,
+		Items:      make([]dto.ABCAnalysisItem, 0, len(rows)),
+	}
+	for _, row := range rows {
+		if row.ConsumptionValue.IsPositive() {
+			response.TotalValue = response.TotalValue.Add(row.ConsumptionValue)
+		}
+	}
+
+	summary := map[string]*dto.ABCClassSummary{
+		"A": {Class: "A"},
+		"B": {Class: "B"},
+		"C": {Class: "C"},
+	}
+	var cumulative models.Money
+	for _, row := range rows {
+		item := dto.ABCAnalysisItem{
+			ItemID:           row.ItemID,
+			ItemCode:         row.ItemCode,
+			ItemName:         row.ItemName,
+			Category:         row.Category,
+			ConsumedQty:      row.ConsumedQty,
+			ConsumptionValue: row.ConsumptionValue,
+			Class:            "C",
+		}
+		if row.ConsumptionValue.IsPositive() && response.TotalValue.IsPositive() {
+			// 按累计前的占比分类，使跨越阈值的物料归入较高一类
+			previous := cumulative.Ratio(response.TotalValue) * 100
+			cumulative = cumulative.Add(row.ConsumptionValue)
+			item.Share = roundPercent(row.ConsumptionValue.Ratio(response.TotalValue) * 100)
+			item.CumulativeShare = roundPercent(cumulative.Ratio(response.TotalValue) * 100)
+			switch {
+			case previous < aThreshold:
+				item.Class = "A"
+			case previous < bThreshold:
+				item.Class = "B"
+			}
+		}
+
+		class := summary[item.Class]
+		class.ItemCount++
+		class.ConsumptionValue = class.ConsumptionValue.Add(row.ConsumptionValue)
+		response.Items = append(response.Items, item)
+	}
+
+	for _, class := range []string{"A", "B", "C"} {
+		entry := summary[class]
+		entry.Share = roundPercent(entry.ConsumptionValue.Ratio(response.TotalValue) * 100)
+		response.Summary = append(response.Summary, *entry)
+	}
+	return response, nil
+}
+
+// ExportStats 导出库存统计
+func (s *InventoryReportServiceImpl) ExportStats(ctx context.Context, req *dto.InventoryStatsRequest, format string) ([]byte, error) {
+	stats, err := s.GetInventoryStats(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]interface{}{{"仓库编码", "仓库名称", "物料数", "库存数量", "库存金额", "低库存物料数"}}
+	for _, warehouse := range stats.Warehouses {
+		rows = append(rows, []interface{}{
+			warehouse.WarehouseCode, warehouse.WarehouseName, warehouse.SKUCount,
+			warehouse.TotalQuantity, warehouse.TotalValue.Float64(), warehouse.LowStockCount,
+		})
+	}
+	rows = append(rows, []interface{}{"合计", "", stats.SKUCount, stats.TotalQuantity, stats.TotalValue.Float64(), stats.LowStockCount})
+	return encodeReport("库存统计", rows, format)
+}
+
+// ExportReport 导出库存明细报表
+func (s *InventoryReportServiceImpl) ExportReport(ctx context.Context, filter *dto.InventoryReportFilter, format string) ([]byte, error) {
+	stockRows, _, err := s.reportRepo.GetStockRows(ctx, stockFilter(filter), 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("获取库存报表失败: %w", err)
+	}
+
+	rows := [][]interface{}{{"物料编码", "物料名称", "类别", "单位", "仓库编码", "仓库名称", "库存数量", "再订货点", "单位成本", "库存金额", "低库存"}}
+	for _, line := range toInventoryReportLines(stockRows) {
+		lowStock := "否"
+		if line.IsLowStock {
+			lowStock = "是"
+		}
+		rows = append(rows, []interface{}{
+			line.ItemCode, line.ItemName, line.Category, line.Unit, line.WarehouseCode, line.WarehouseName,
+			line.Quantity, line.ReorderLevel, line.ValuationRate.Float64(), line.StockValue.Float64(), lowStock,
+		})
+	}
+	return encodeReport("库存报表", rows, format)
+}
+
+// ExportABCAnalysis 导出 ABC 分析
+func (s *InventoryReportServiceImpl) ExportABCAnalysis(ctx context.Context, req *dto.ABCAnalysisRequest, format string) ([]byte, error) {
+	analysis, err := s.GetABCAnalysis(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]interface{}{{"物料编码", "物料名称", "类别", "消耗数量", "消耗金额", "占比(%)", "累计占比(%)", "分类"}}
+	for _, item := range analysis.Items {
+		rows = append(rows, []interface{}{
+			item.ItemCode, item.ItemName, item.Category, item.ConsumedQty,
+			item.ConsumptionValue.Float64(), item.Share, item.CumulativeShare, item.Class,
+		})
+	}
+	return encodeReport("ABC分析", rows, format)
+}
+
+// GetInventoryAging 按入库时间将物料在各仓库的在库库存划入账龄段，金额按当前单位成本折算
+func (s *InventoryReportServiceImpl) GetInventoryAging(ctx context.Context, req *dto.InventoryAgingRequest) (*dto.InventoryAgingResponse, error) {
+	limits, err := parseAgingBuckets(req.Buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	asOf := time.Now()
+	stocks, err := s.loadStockAges(ctx, repositories.InventoryStockFilter{
+		ItemID:      req.ItemID,
+		WarehouseID: req.WarehouseID,
+		Category:    req.Category,
+		Keyword:     req.Keyword,
+	}, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.InventoryAgingResponse{
+		AsOf:    asOf,
+		Buckets: newAgingBuckets(limits),
+		Lines:   make([]dto.InventoryAgingLine, 0, len(stocks)),
+	}
+	for _, stock := range stocks {
+		row := stock.row
+		line := dto.InventoryAgingLine{
+			ItemID:        row.ItemID,
+			ItemCode:      row.ItemCode,
+			ItemName:      row.ItemName,
+			Category:      row.Category,
+			WarehouseID:   row.WarehouseID,
+			WarehouseCode: row.WarehouseCode,
+			WarehouseName: row.WarehouseName,
+			Quantity:      row.Quantity,
+			ValuationRate: row.ValuationRate,
+			StockValue:    row.StockValue,
+			Buckets:       newAgingBuckets(limits),
+		}
+		if oldest := stock.oldestReceipt(); !oldest.IsZero() {
+			line.OldestReceiptDate = &oldest
+		}
+
+		var weightedDays, knownQuantity float64
+		var allocated models.Money
+		last := -1
+		for _, layer := range stock.layers {
+			days := len(limits) // 入库记录不足以覆盖的数量计入最长账龄段
+			if !layer.receivedAt.IsZero() {
+				age := ageInDays(layer.receivedAt, asOf)
+				weightedDays += float64(age) * layer.quantity
+				knownQuantity += layer.quantity
+				days = agingBucketIndex(limits, age)
+			}
+			line.Buckets[days].Quantity += layer.quantity
+			if days > last {
+				last = days
+			}
+		}
+		// 按数量比例分摊库存金额，尾差计入最长的有库存账龄段
+		for i := range line.Buckets {
+			bucket := &line.Buckets[i]
+			if bucket.Quantity == 0 {
+				continue
+			}
+			value, err := row.StockValue.Mul(bucket.Quantity / row.Quantity)
+			if err != nil {
+				return nil, err
+			}
+			bucket.Value = value
+			if i == last {
+				bucket.Value = row.StockValue.Sub(allocated)
+			}
+			allocated = allocated.Add(bucket.Value)
+
+			response.Buckets[i].Quantity += bucket.Quantity
+			response.Buckets[i].Value = response.Buckets[i].Value.Add(bucket.Value)
+		}
+		if knownQuantity > 0 {
+			line.AverageAgeDays = math.Round(weightedDays/knownQuantity*100) / 100
+		}
+
+		response.Lines = append(response.Lines, line)
+		response.TotalQuantity += row.Quantity
+		response.TotalValue = response.TotalValue.Add(row.StockValue)
+	}
+	return response, nil
+}
+
+// GetSlowMovingStock 列出超过滞销天数没有出库的在库库存，超过呆滞天数的标记为呆滞；
+// 从未出库的库存按在库最早的入库时间计算闲置天数
+func (s *InventoryReportServiceImpl) GetSlowMovingStock(ctx context.Context, req *dto.SlowMovingRequest) (*dto.SlowMovingResponse, error) {
+	slowDays, deadDays := req.SlowDays, req.DeadDays
+	if slowDays == 0 {
+		slowDays = DefaultSlowMovingDays
+	}
+	if deadDays == 0 {
+		deadDays = DefaultDeadStockDays
+	}
+	if deadDays < slowDays {
+		return nil, errors.New("呆滞天数不能小于滞销天数")
+	}
+
+	asOf := time.Now()
+	filter := repositories.InventoryStockFilter{
+		ItemID:      req.ItemID,
+		WarehouseID: req.WarehouseID,
+		Category:    req.Category,
+		Keyword:     req.Keyword,
+	}
+	stocks, err := s.loadStockAges(ctx, filter, asOf)
+	if err != nil {
+		return nil, err
+	}
+	outbound, err := s.reportRepo.GetLastOutbound(ctx, filter, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("获取最近出库时间失败: %w", err)
+	}
+	lastOutbound := make(map[stockKey]time.Time, len(outbound))
+	for _, row := range outbound {
+		lastOutbound[stockKey{itemID: row.ItemID, warehouseID: row.WarehouseID}] = row.LastOutboundAt
+	}
+
+	response := &dto.SlowMovingResponse{
+		AsOf:     asOf,
+		SlowDays: slowDays,
+		DeadDays: deadDays,
+		Lines:    make([]dto.SlowMovingLine, 0),
+	}
+	for _, stock := range stocks {
+		row := stock.row
+		line := dto.SlowMovingLine{
+			ItemID:        row.ItemID,
+			ItemCode:      row.ItemCode,
+			ItemName:      row.ItemName,
+			Category:      row.Category,
+			WarehouseID:   row.WarehouseID,
+			WarehouseCode: row.WarehouseCode,
+			WarehouseName: row.WarehouseName,
+			Quantity:      row.Quantity,
+			ValuationRate: row.ValuationRate,
+			CarryingValue: row.StockValue,
+		}
+		since := stock.oldestReceipt()
+		if last, ok := lastOutbound[stockKey{itemID: row.ItemID, warehouseID: row.WarehouseID}]; ok {
+			line.LastOutboundAt = &last
+			since = last
+		}
+		if since.IsZero() {
+			// 没有任何出入库记录的库存无法判断闲置时间，按呆滞处理
+			line.IdleDays = deadDays
+		} else {
+			line.IdleDays = ageInDays(since, asOf)
+		}
+
+		switch {
+		case line.IdleDays >= deadDays:
+			line.Status = StockStatusDead
+			response.DeadStockValue = response.DeadStockValue.Add(row.StockValue)
+		case line.IdleDays >= slowDays:
+			line.Status = StockStatusSlowMoving
+			response.SlowMovingValue = response.SlowMovingValue.Add(row.StockValue)
+		default:
+			continue
+		}
+		response.Lines = append(response.Lines, line)
+	}
+	sort.SliceStable(response.Lines, func(i, j int) bool {
+		return response.Lines[i].IdleDays > response.Lines[j].IdleDays
+	})
+	return response, nil
+}
+
+// GetWriteDownProposal 按滞销、呆滞库存的账面金额与减值比例生成存货减值建议，
+// 并给出借记减值损失、贷记存货跌价准备的凭证草稿，供财务审核后提交过账
+func (s *InventoryReportServiceImpl) GetWriteDownProposal(ctx context.Context, req *dto.InventoryWriteDownRequest) (*dto.InventoryWriteDownResponse, error) {
+	slowPercent, deadPercent := req.SlowPercent, req.DeadPercent
+	if slowPercent == 0 {
+		slowPercent = DefaultSlowMovingWriteDownPercent
+	}
+	if deadPercent == 0 {
+		deadPercent = DefaultDeadStockWriteDownPercent
+	}
+
+	slowMoving, err := s.GetSlowMovingStock(ctx, &req.SlowMovingRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.InventoryWriteDownResponse{
+		AsOf:  slowMoving.AsOf,
+		Lines: make([]dto.InventoryWriteDownLine, 0, len(slowMoving.Lines)),
+	}
+	for _, line := range slowMoving.Lines {
+		percent := slowPercent
+		if line.Status == StockStatusDead {
+			percent = deadPercent
+		}
+		writeDown := dto.InventoryWriteDownLine{SlowMovingLine: line, WriteDownPercent: percent}
+		if line.CarryingValue.IsPositive() {
+			amount, err := line.CarryingValue.Percent(percent)
+			if err != nil {
+				return nil, fmt.Errorf("计算物料 %s 的减值金额失败: %w", line.ItemCode, err)
+			}
+			writeDown.WriteDownAmount = amount
+		}
+		response.Lines = append(response.Lines, writeDown)
+		response.TotalCarryingValue = response.TotalCarryingValue.Add(line.CarryingValue)
+		response.TotalWriteDown = response.TotalWriteDown.Add(writeDown.WriteDownAmount)
+	}
+	if !response.TotalWriteDown.IsPositive() {
+		return response, nil
+	}
+
+	companyID, err := resolveCompanyID(ctx, s.companyRepo, req.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	expenseCode := strings.TrimSpace(req.ExpenseAccountCode)
+	if expenseCode == "" {
+		expenseCode = DefaultWriteDownExpenseCode
+	}
+	provisionCode := strings.TrimSpace(req.ProvisionAccountCode)
+	if provisionCode == "" {
+		provisionCode = DefaultWriteDownProvisionCode
+	}
+	expenseAccount, err := s.accountRepo.GetByCode(ctx, companyID, expenseCode)
+	if err != nil || expenseAccount == nil {
+		return nil, fmt.Errorf("减值损失科目 %s 不存在", expenseCode)
+	}
+	provisionAccount, err := s.accountRepo.GetByCode(ctx, companyID, provisionCode)
+	if err != nil || provisionAccount == nil {
+		return nil, fmt.Errorf("存货跌价准备科目 %s 不存在", provisionCode)
+	}
+
+	description := fmt.Sprintf("计提存货跌价准备（截至 %s）", response.AsOf.Format("2006-01-02"))
+	response.Journal = &dto.JournalEntryCreateRequest{
+		CompanyID:   companyID,
+		Date:        truncateToDay(response.AsOf),
+		Description: description,
+		Items: []dto.JournalEntryItemRequest{
+			{AccountID: expenseAccount.ID, DebitAmount: response.TotalWriteDown, Description: description},
+			{AccountID: provisionAccount.ID, CreditAmount: response.TotalWriteDown, Description: description},
+		},
+	}
+	return response, nil
+}
+
+// ExportAging 导出库存账龄
+func (s *InventoryReportServiceImpl) ExportAging(ctx context.Context, req *dto.InventoryAgingRequest, format string) ([]byte, error) {
+	aging, err := s.GetInventoryAging(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []interface{}{"物料编码", "物料名称", "类别", "仓库编码", "仓库名称", "库存数量", "库存金额", "最早入库日期", "平均库龄(天)"}
+	for _, bucket := range aging.Buckets {
+		header = append(header, bucket.Label+"天数量", bucket.Label+"天金额")
+	}
+	rows := [][]interface{}{header}
+	for _, line := range aging.Lines {
+		oldest := ""
+		if line.OldestReceiptDate != nil {
+			oldest = line.OldestReceiptDate.Format("2006-01-02")
+		}
+		row := []interface{}{
+			line.ItemCode, line.ItemName, line.Category, line.WarehouseCode, line.WarehouseName,
+			line.Quantity, line.StockValue.Float64(), oldest, line.AverageAgeDays,
+		}
+		for _, bucket := range line.Buckets {
+			row = append(row, bucket.Quantity, bucket.Value.Float64())
+		}
+		rows = append(rows, row)
+	}
+	total := []interface{}{"合计", "", "", "", "", aging.TotalQuantity, aging.TotalValue.Float64(), "", ""}
+	for _, bucket := range aging.Buckets {
+		total = append(total, bucket.Quantity, bucket.Value.Float64())
+	}
+	rows = append(rows, total)
+	return encodeReport("库存账龄", rows, format)
+}
+
+// ExportSlowMoving 导出呆滞库存
+func (s *InventoryReportServiceImpl) ExportSlowMoving(ctx context.Context, req *dto.SlowMovingRequest, format string) ([]byte, error) {
+	slowMoving, err := s.GetSlowMovingStock(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]interface{}{{"物料编码", "物料名称", "类别", "仓库编码", "仓库名称", "库存数量", "单位成本", "账面金额", "最近出库日期", "闲置天数", "状态"}}
+	for _, line := range slowMoving.Lines {
+		rows = append(rows, slowMovingRow(line))
+	}
+	return encodeReport("呆滞库存", rows, format)
+}
+
+// ExportWriteDownProposal 导出存货减值建议
+func (s *InventoryReportServiceImpl) ExportWriteDownProposal(ctx context.Context, req *dto.InventoryWriteDownRequest, format string) ([]byte, error) {
+	proposal, err := s.GetWriteDownProposal(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]interface{}{{"物料编码", "物料名称", "类别", "仓库编码", "仓库名称", "库存数量", "单位成本", "账面金额", "最近出库日期", "闲置天数", "状态", "减值比例(%)", "减值金额"}}
+	for _, line := range proposal.Lines {
+		rows = append(rows, append(slowMovingRow(line.SlowMovingLine), line.WriteDownPercent, line.WriteDownAmount.Float64()))
+	}
+	rows = append(rows, []interface{}{"合计", "", "", "", "", "", "", proposal.TotalCarryingValue.Float64(), "", "", "", "", proposal.TotalWriteDown.Float64()})
+	return encodeReport("存货减值建议", rows, format)
+}
+
+// stockKey 物料与仓库
+type stockKey struct {
+	itemID      uint
+	warehouseID uint
+}
+
+// stockLayer 在库库存中来自同一次入库的部分，入库时间为零值表示入库记录不足以覆盖的数量
+type stockLayer struct {
+	receivedAt time.Time
+	quantity   float64
+}
+
+// stockAge 物料在仓库的在库库存及其按入库时间的构成
+type stockAge struct {
+	row    repositories.InventoryStockRow
+	layers []stockLayer
+}
+
+// oldestReceipt 返回在库库存中最早的已知入库时间
+func (a *stockAge) oldestReceipt() time.Time {
+	var oldest time.Time
+	for _, layer := range a.layers {
+		if !layer.receivedAt.IsZero() && (oldest.IsZero() || layer.receivedAt.Before(oldest)) {
+			oldest = layer.receivedAt
+		}
+	}
+	return oldest
+}
+
+// loadStockAges 获取正数库存，并按先进先出假设将在库数量从最近的入库往前依次归属
+func (s *InventoryReportServiceImpl) loadStockAges(ctx context.Context, filter repositories.InventoryStockFilter, asOf time.Time) ([]stockAge, error) {
+	rows, _, err := s.reportRepo.GetStockRows(ctx, filter, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("获取库存数据失败: %w", err)
+	}
+	inbound, err := s.reportRepo.GetInboundMovements(ctx, filter, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("获取入库记录失败: %w", err)
+	}
+	receipts := make(map[stockKey][]repositories.InboundMovementRow)
+	for _, movement := range inbound {
+		key := stockKey{itemID: movement.ItemID, warehouseID: movement.WarehouseID}
+		receipts[key] = append(receipts[key], movement)
+	}
+
+	stocks := make([]stockAge, 0, len(rows))
+	for _, row := range rows {
+		if row.Quantity <= stockQuantityTolerance {
+			continue
+		}
+		stock := stockAge{row: row}
+		remaining := row.Quantity
+		for _, receipt := range receipts[stockKey{itemID: row.ItemID, warehouseID: row.WarehouseID}] {
+			if remaining <= stockQuantityTolerance {
+				break
+			}
+			quantity := math.Min(receipt.Quantity, remaining)
+			stock.layers = append(stock.layers, stockLayer{receivedAt: receipt.ReceivedAt, quantity: quantity})
+			remaining -= quantity
+		}
+		if remaining > stockQuantityTolerance {
+			stock.layers = append(stock.layers, stockLayer{quantity: remaining})
+		}
+		stocks = append(stocks, stock)
+	}
+	return stocks, nil
+}
+
+// parseAgingBuckets 解析逗号分隔的账龄段上限天数，须为递增的正整数
+func parseAgingBuckets(value string) ([]int, error) {
+	if strings.TrimSpace(value) == "" {
+		value = DefaultAgingBuckets
+	}
+	var limits []int
+	for _, part := range strings.Split(value, ",") {
+		days, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || days <= 0 {
+			return nil, fmt.Errorf("账龄段天数 %q 无效", part)
+		}
+		if len(limits) > 0 && days <= limits[len(limits)-1] {
+			return nil, errors.New("账龄段天数必须递增")
+		}
+		limits = append(limits, days)
+	}
+	return limits, nil
+}
+
+// newAgingBuckets 按上限天数生成账龄段，最后追加不设上限的账龄段
+func newAgingBuckets(limits []int) []dto.InventoryAgingBucket {
+	buckets := make([]dto.InventoryAgingBucket, 0, len(limits)+1)
+	minDays := 0
+	for _, maxDays := range limits {
+		buckets = append(buckets, dto.InventoryAgingBucket{
+			Label:   fmt.Sprintf("%d-%d", minDays, maxDays),
+			MinDays: minDays,
+			MaxDays: maxDays,
+		})
+		minDays = maxDays + 1
+	}
+	return append(buckets, dto.InventoryAgingBucket{Label: fmt.Sprintf("%d+", minDays-1), MinDays: minDays})
+}
+
+// agingBucketIndex 返回库龄所属的账龄段
+func agingBucketIndex(limits []int, days int) int {
+	for i, maxDays := range limits {
+		if days <= maxDays {
+			return i
+		}
+	}
+	return len(limits)
+}
+
+// ageInDays 计算 from 到 asOf 经过的整天数
+func ageInDays(from, asOf time.Time) int {
+	days := int(asOf.Sub(from).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// slowMovingRow 呆滞库存导出行
+func slowMovingRow(line dto.SlowMovingLine) []interface{} {
+	lastOutbound := ""
+	if line.LastOutboundAt != nil {
+		lastOutbound = line.LastOutboundAt.Format("2006-01-02")
+	}
+	status := "滞销"
+	if line.Status == StockStatusDead {
+		status = "呆滞"
+	}
+	return []interface{}{
+		line.ItemCode, line.ItemName, line.Category, line.WarehouseCode, line.WarehouseName,
+		line.Quantity, line.ValuationRate.Float64(), line.CarryingValue.Float64(), lastOutbound, line.IdleDays, status,
+	}
+}
+
+// encodeReport 将报表行编码为 CSV 或 XLSX
+func encodeReport(sheetName string, rows [][]interface{}, format string) ([]byte, error) {
+	if format == "xlsx" {
+		return utils.WriteXLSX(sheetName, rows)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = fmt.Sprint(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// abcAnalysisRange 计算 ABC 分析的统计期间，未指定开始日期时按截止日期往前 days 天
+func abcAnalysisRange(req *dto.ABCAnalysisRequest) (time.Time, time.Time, error) {
+	endDate := req.EndDate
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+	startDate := req.StartDate
+	if startDate.IsZero() {
+		days := req.Days
+		if days == 0 {
+			days = DefaultABCDays
+		}
+		startDate = endDate.AddDate(0, 0, 1-days)
+	}
+	return trialBalanceRange(startDate, endDate)
+}
+
+// stockFilter 将报表筛选条件转换为仓储筛选条件
+func stockFilter(filter *dto.InventoryReportFilter) repositories.InventoryStockFilter {
+	return repositories.InventoryStockFilter{
+		ItemID:          filter.ItemID,
+		WarehouseID:     filter.WarehouseID,
+		Category:        filter.Category,
+		Keyword:         filter.Keyword,
+		LowStockOnly:    filter.LowStockOnly,
+		IncludeZero:     filter.IncludeZero,
+		GroupByTemplate: filter.GroupByTemplate,
+	}
+}
+
+// toInventoryReportLines 转换库存明细为报表行
+func toInventoryReportLines(rows []repositories.InventoryStockRow) []dto.InventoryReportLine {
+	lines := make([]dto.InventoryReportLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, dto.InventoryReportLine{
+			ItemID:        row.ItemID,
+			ItemCode:      row.ItemCode,
+			ItemName:      row.ItemName,
+			Category:      row.Category,
+			Unit:          row.Unit,
+			WarehouseID:   row.WarehouseID,
+			WarehouseCode: row.WarehouseCode,
+			WarehouseName: row.WarehouseName,
+			Quantity:      row.Quantity,
+			ReorderLevel:  row.ReorderLevel,
+			ValuationRate: row.ValuationRate,
+			StockValue:    row.StockValue,
+			IsLowStock:    isLowStock(row),
+		})
+	}
+	return lines
+}
+
+// isLowStock 库存不高于再订货点视为低库存，未设置再订货点的物料不参与
+func isLowStock(row repositories.InventoryStockRow) bool {
+	return row.ReorderLevel > 0 && row.Quantity <= float64(row.ReorderLevel)
+}
+
+// roundPercent 百分比保留两位小数
+func roundPercent(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,553 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+)
+
+// maxVariantsPerGeneration 单次生成变体的数量上限，防止属性取值过多时误生成大量物料
+const maxVariantsPerGeneration = 500
+
+// ItemAttributeService 物料属性服务接口
+type ItemAttributeService interface {
+	CreateAttribute(ctx context.Context, req *dto.ItemAttributeCreateRequest) (*dto.ItemAttributeResponse, error)
+	GetAttribute(ctx context.Context, id uint) (*dto.ItemAttributeResponse, error)
+	ListAttributes(ctx context.Context) ([]dto.ItemAttributeResponse, error)
+	UpdateAttribute(ctx context.Context, id uint, req *dto.ItemAttributeUpdateRequest) (*dto.ItemAttributeResponse, error)
+	DeleteAttribute(ctx context.Context, id uint) error
+	AddAttributeValue(ctx context.Context, attributeID uint, req *dto.ItemAttributeValueRequest) (*dto.ItemAttributeResponse, error)
+	DeleteAttributeValue(ctx context.Context, attributeID, valueID uint) error
+}
+
+// ItemAttributeServiceImpl 物料属性服务实现
+type ItemAttributeServiceImpl struct {
+	variantRepo repositories.ItemVariantRepository
+}
+
+// NewItemAttributeService 创建物料属性服务实例
+func NewItemAttributeService(variantRepo repositories.ItemVariantRepository) ItemAttributeService {
+	return &ItemAttributeServiceImpl{variantRepo: variantRepo}
+}
+
+// CreateAttribute 创建物料属性及其取值
+func (s *ItemAttributeServiceImpl) CreateAttribute(ctx context.Context, req *dto.ItemAttributeCreateRequest) (*dto.ItemAttributeResponse, error) {
+	code := strings.TrimSpace(req.Code)
+	existing, err := s.variantRepo.GetAttributeByCode(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料属性失败: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("物料属性编码 %s 已存在", code)
+	}
+
+	attribute := &models.ItemAttribute{
+		Code:        code,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	seen := make(map[string]bool, len(req.Values))
+	for i, valueReq := range req.Values {
+		value := newAttributeValue(&valueReq)
+		if seen[strings.ToLower(value.Value)] {
+			return nil, fmt.Errorf("物料属性取值 %s 重复", value.Value)
+		}
+		seen[strings.ToLower(value.Value)] = true
+		if value.SortOrder == 0 {
+			value.SortOrder = i + 1
+		}
+		attribute.Values = append(attribute.Values, *value)
+	}
+
+	if err := s.variantRepo.Create(ctx, attribute); err != nil {
+		return nil, fmt.Errorf("创建物料属性失败: %w", err)
+	}
+	return s.GetAttribute(ctx, attribute.ID)
+}
+
+// GetAttribute 获取物料属性
+func (s *ItemAttributeServiceImpl) GetAttribute(ctx context.Context, id uint) (*dto.ItemAttributeResponse, error) {
+	attribute, err := s.getAttribute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	response := toItemAttributeResponse(attribute)
+	return &response, nil
+}
+
+// ListAttributes 获取全部物料属性
+func (s *ItemAttributeServiceImpl) ListAttributes(ctx context.Context) ([]dto.ItemAttributeResponse, error) {
+	attributes, err := s.variantRepo.ListAttributes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料属性失败: %w", err)
+	}
+	responses := make([]dto.ItemAttributeResponse, 0, len(attributes))
+	for _, attribute := range attributes {
+		responses = append(responses, toItemAttributeResponse(attribute))
+	}
+	return responses, nil
+}
+
+// UpdateAttribute 更新物料属性名称与描述
+func (s *ItemAttributeServiceImpl) UpdateAttribute(ctx context.Context, id uint, req *dto.ItemAttributeUpdateRequest) (*dto.ItemAttributeResponse, error) {
+	attribute, err := s.getAttribute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		attribute.Name = *req.Name
+	}
+	if req.Description != nil {
+		attribute.Description = *req.Description
+	}
+	if err := s.variantRepo.SaveAttribute(ctx, attribute); err != nil {
+		return nil, fmt.Errorf("更新物料属性失败: %w", err)
+	}
+	response := toItemAttributeResponse(attribute)
+	return &response, nil
+}
+
+// DeleteAttribute 删除未被模板物料使用的物料属性
+func (s *ItemAttributeServiceImpl) DeleteAttribute(ctx context.Context, id uint) error {
+	if _, err := s.getAttribute(ctx, id); err != nil {
+		return err
+	}
+	count, err := s.variantRepo.CountAttributeUsage(ctx, id)
+	if err != nil {
+		return fmt.Errorf("获取物料属性使用情况失败: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("物料属性已被 %d 个模板物料使用，不能删除", count)
+	}
+	if err := s.variantRepo.DeleteAttribute(ctx, id); err != nil {
+		return fmt.Errorf("删除物料属性失败: %w", err)
+	}
+	return nil
+}
+
+// AddAttributeValue 为物料属性增加取值，已有模板可再次生成变体以补充新取值的组合
+func (s *ItemAttributeServiceImpl) AddAttributeValue(ctx context.Context, attributeID uint, req *dto.ItemAttributeValueRequest) (*dto.ItemAttributeResponse, error) {
+	attribute, err := s.getAttribute(ctx, attributeID)
+	if err != nil {
+		return nil, err
+	}
+	value := newAttributeValue(req)
+	for _, existing := range attribute.Values {
+		if strings.EqualFold(existing.Value, value.Value) {
+			return nil, fmt.Errorf("物料属性 %s 已有取值 %s", attribute.Code, value.Value)
+		}
+	}
+	value.AttributeID = attribute.ID
+	if value.SortOrder == 0 {
+		value.SortOrder = len(attribute.Values) + 1
+	}
+	if err := s.variantRepo.CreateAttributeValue(ctx, value); err != nil {
+		return nil, fmt.Errorf("创建物料属性取值失败: %w", err)
+	}
+	return s.GetAttribute(ctx, attribute.ID)
+}
+
+// DeleteAttributeValue 删除未被变体使用的属性取值
+func (s *ItemAttributeServiceImpl) DeleteAttributeValue(ctx context.Context, attributeID, valueID uint) error {
+	value, err := s.variantRepo.GetAttributeValue(ctx, valueID)
+	if err != nil {
+		return fmt.Errorf("获取物料属性取值失败: %w", err)
+	}
+	if value == nil || value.AttributeID != attributeID {
+		return errors.New("物料属性取值不存在")
+	}
+	count, err := s.variantRepo.CountAttributeValueUsage(ctx, valueID)
+	if err != nil {
+		return fmt.Errorf("获取物料属性取值使用情况失败: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("物料属性取值 %s 已被 %d 个变体使用，不能删除", value.Value, count)
+	}
+	if err := s.variantRepo.DeleteAttributeValue(ctx, valueID); err != nil {
+		return fmt.Errorf("删除物料属性取值失败: %w", err)
+	}
+	return nil
+}
+
+// getAttribute 获取物料属性，不存在时返回错误
+func (s *ItemAttributeServiceImpl) getAttribute(ctx context.Context, id uint) (*models.ItemAttribute, error) {
+	attribute, err := s.variantRepo.GetAttribute(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料属性失败: %w", err)
+	}
+	if attribute == nil {
+		return nil, errors.New("物料属性不存在")
+	}
+	return attribute, nil
+}
+
+// SetTemplateAttributes 设置模板物料使用的属性并将物料标记为模板；
+// 已有变体的模板不能再修改属性，有库存的普通物料不能转为模板
+func (s *ItemServiceImpl) SetTemplateAttributes(ctx context.Context, id uint, req *dto.ItemTemplateAttributesRequest) (*dto.ItemTemplateResponse, error) {
+	item, err := s.itemRepo.GetByID(ctx, id)
+	if err != nil || item == nil {
+		return nil, errors.New("物料不存在")
+	}
+	if item.VariantOf != nil {
+		return nil, fmt.Errorf("物料 %s 是变体物料，不能作为模板", item.Code)
+	}
+	count, err := s.variantRepo.CountVariants(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料变体失败: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("模板物料 %s 已有 %d 个变体，不能修改属性", item.Code, count)
+	}
+	if !item.HasVariants {
+		quantity, err := s.itemRepo.GetStockQuantity(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("获取物料库存失败: %w", err)
+		}
+		if quantity != 0 {
+			return nil, fmt.Errorf("物料仍有库存 %.2f，不能设为模板物料", quantity)
+		}
+	}
+
+	seen := make(map[uint]bool, len(req.AttributeIDs))
+	for _, attributeID := range req.AttributeIDs {
+		if seen[attributeID] {
+			return nil, fmt.Errorf("物料属性 %d 重复", attributeID)
+		}
+		seen[attributeID] = true
+		attribute, err := s.variantRepo.GetAttribute(ctx, attributeID)
+		if err != nil {
+			return nil, fmt.Errorf("获取物料属性失败: %w", err)
+		}
+		if attribute == nil {
+			return nil, fmt.Errorf("物料属性 %d 不存在", attributeID)
+		}
+		if len(attribute.Values) == 0 {
+			return nil, fmt.Errorf("物料属性 %s 没有取值", attribute.Code)
+		}
+	}
+
+	if err := s.variantRepo.ReplaceTemplateAttributes(ctx, id, req.AttributeIDs); err != nil {
+		return nil, fmt.Errorf("设置模板属性失败: %w", err)
+	}
+	s.DeleteFromCache(ctx, fmt.Sprintf("item_%d", id))
+	s.DeleteFromCache(ctx, "items_list")
+
+	return s.GetItemTemplate(ctx, id)
+}
+
+// GetItemTemplate 获取模板物料的属性、变体及各变体库存
+func (s *ItemServiceImpl) GetItemTemplate(ctx context.Context, id uint) (*dto.ItemTemplateResponse, error) {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	templateAttributes, err := s.variantRepo.GetTemplateAttributes(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取模板属性失败: %w", err)
+	}
+	variants, err := s.variantRepo.ListVariants(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料变体失败: %w", err)
+	}
+	itemIDs := make([]uint, 0, len(variants))
+	for _, variant := range variants {
+		itemIDs = append(itemIDs, variant.ID)
+	}
+	quantities, err := s.variantRepo.GetStockQuantities(ctx, itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取变体库存失败: %w", err)
+	}
+
+	response := &dto.ItemTemplateResponse{
+		TemplateID:   template.ID,
+		TemplateCode: template.Code,
+		TemplateName: template.Name,
+		Attributes:   make([]dto.ItemAttributeResponse, 0, len(templateAttributes)),
+		Variants:     make([]dto.ItemVariantResponse, 0, len(variants)),
+	}
+	for _, templateAttribute := range templateAttributes {
+		if templateAttribute.Attribute != nil {
+			response.Attributes = append(response.Attributes, toItemAttributeResponse(templateAttribute.Attribute))
+		}
+	}
+	for _, variant := range variants {
+		variantResponse := toItemVariantResponse(variant)
+		variantResponse.StockQty = quantities[variant.ID]
+		response.TotalStock += variantResponse.StockQty
+		response.Variants = append(response.Variants, variantResponse)
+	}
+	return response, nil
+}
+
+// GenerateVariants 按模板属性取值的组合生成变体物料：编码为模板编码加各取值缩写，名称为模板名称加各取值，
+// 其余主数据与单位换算沿用模板；已存在的取值组合跳过，可在增加属性取值后再次生成
+func (s *ItemServiceImpl) GenerateVariants(ctx context.Context, id uint, req *dto.ItemVariantGenerateRequest) (*dto.ItemVariantGenerateResponse, error) {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	templateAttributes, err := s.variantRepo.GetTemplateAttributes(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取模板属性失败: %w", err)
+	}
+
+	selected := make(map[uint]map[uint]bool, len(req.Selections))
+	for _, selection := range req.Selections {
+		valueIDs := make(map[uint]bool, len(selection.ValueIDs))
+		for _, valueID := range selection.ValueIDs {
+			valueIDs[valueID] = true
+		}
+		selected[selection.AttributeID] = valueIDs
+	}
+
+	// 按模板属性顺序确定各属性参与组合的取值
+	valueSets := make([][]models.ItemAttributeValue, 0, len(templateAttributes))
+	for _, templateAttribute := range templateAttributes {
+		attribute := templateAttribute.Attribute
+		if attribute == nil {
+			return nil, fmt.Errorf("物料属性 %d 不存在", templateAttribute.AttributeID)
+		}
+		values := attribute.Values
+		if valueIDs, ok := selected[attribute.ID]; ok {
+			values = nil
+			for _, value := range attribute.Values {
+				if valueIDs[value.ID] {
+					values = append(values, value)
+					delete(valueIDs, value.ID)
+				}
+			}
+			if len(valueIDs) > 0 {
+				return nil, fmt.Errorf("所选取值不属于物料属性 %s", attribute.Code)
+			}
+			delete(selected, attribute.ID)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("物料属性 %s 没有可用的取值", attribute.Code)
+		}
+		valueSets = append(valueSets, values)
+	}
+	for attributeID := range selected {
+		return nil, fmt.Errorf("物料属性 %d 不是模板 %s 的属性", attributeID, template.Code)
+	}
+
+	combinations := 1
+	for _, values := range valueSets {
+		combinations *= len(values)
+		if combinations > maxVariantsPerGeneration {
+			return nil, fmt.Errorf("取值组合超过单次生成上限 %d，请缩小取值范围", maxVariantsPerGeneration)
+		}
+	}
+
+	existing, err := s.variantRepo.ListVariants(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料变体失败: %w", err)
+	}
+	existingKeys := make(map[string]bool, len(existing))
+	for _, variant := range existing {
+		valueByAttribute := make(map[uint]uint, len(variant.VariantAttributes))
+		for _, attribute := range variant.VariantAttributes {
+			valueByAttribute[attribute.AttributeID] = attribute.AttributeValueID
+		}
+		valueIDs := make([]uint, 0, len(templateAttributes))
+		for _, templateAttribute := range templateAttributes {
+			valueIDs = append(valueIDs, valueByAttribute[templateAttribute.AttributeID])
+		}
+		existingKeys[variantKey(valueIDs)] = true
+	}
+
+	response := &dto.ItemVariantGenerateResponse{Created: make([]dto.ItemVariantResponse, 0)}
+	var variants []*models.Item
+	var codes []string
+	for _, combination := range cartesianValues(valueSets) {
+		valueIDs := make([]uint, len(combination))
+		abbreviations := make([]string, len(combination))
+		names := make([]string, len(combination))
+		attributes := make([]models.ItemVariantAttribute, len(combination))
+		for i, value := range combination {
+			valueIDs[i] = value.ID
+			abbreviations[i] = value.Abbreviation
+			names[i] = value.Value
+			attributes[i] = models.ItemVariantAttribute{AttributeID: value.AttributeID, AttributeValueID: value.ID}
+		}
+		if existingKeys[variantKey(valueIDs)] {
+			response.Skipped++
+			continue
+		}
+
+		variant := &models.Item{
+			Code:                template.Code + "-" + strings.Join(abbreviations, "-"),
+			Name:                template.Name + " " + strings.Join(names, " "),
+			Description:         template.Description,
+			Category:            template.Category,
+			Unit:                template.Unit,
+			Cost:                template.Cost,
+			Price:               template.Price,
+			ReorderLevel:        template.ReorderLevel,
+			ReorderQty:          template.ReorderQty,
+			MaxLevel:            template.MaxLevel,
+			PreferredSupplierID: template.PreferredSupplierID,
+			IsActive:            template.IsActive,
+			ValuationMethod:     template.ValuationMethod,
+			TrackingMode:        template.TrackingMode,
+			VariantOf:           &template.ID,
+			VariantAttributes:   attributes,
+		}
+		if req.UnitCost != nil {
+			variant.Cost = *req.UnitCost
+		}
+		if req.SalePrice != nil {
+			variant.Price = *req.SalePrice
+		}
+		variants = append(variants, variant)
+		codes = append(codes, variant.Code)
+	}
+
+	if len(variants) == 0 {
+		return response, nil
+	}
+	taken, err := s.variantRepo.GetExistingItemCodes(ctx, codes)
+	if err != nil {
+		return nil, fmt.Errorf("检查物料编码失败: %w", err)
+	}
+	if len(taken) > 0 {
+		return nil, fmt.Errorf("物料编码 %s 已存在", strings.Join(taken, ", "))
+	}
+	if err := s.variantRepo.CreateVariants(ctx, template.ID, variants); err != nil {
+		return nil, fmt.Errorf("生成物料变体失败: %w", err)
+	}
+	s.DeleteFromCache(ctx, "items_list")
+
+	// 重新加载以附带属性取值
+	created, err := s.variantRepo.ListVariants(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料变体失败: %w", err)
+	}
+	createdCodes := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		createdCodes[code] = true
+	}
+	for _, variant := range created {
+		if createdCodes[variant.Code] {
+			response.Created = append(response.Created, toItemVariantResponse(variant))
+		}
+	}
+	return response, nil
+}
+
+// getTemplate 获取模板物料，非模板物料返回错误
+func (s *ItemServiceImpl) getTemplate(ctx context.Context, id uint) (*models.Item, error) {
+	item, err := s.itemRepo.GetByID(ctx, id)
+	if err != nil || item == nil {
+		return nil, errors.New("物料不存在")
+	}
+	if !item.HasVariants {
+		return nil, fmt.Errorf("物料 %s 不是模板物料，请先设置模板属性", item.Code)
+	}
+	return item, nil
+}
+
+// newAttributeValue 由请求构造属性取值，缩写为空时使用取值本身
+func newAttributeValue(req *dto.ItemAttributeValueRequest) *models.ItemAttributeValue {
+	value := strings.TrimSpace(req.Value)
+	abbreviation := strings.TrimSpace(req.Abbreviation)
+	if abbreviation == "" {
+		abbreviation = value
+	}
+	return &models.ItemAttributeValue{
+		Value:        value,
+		Abbreviation: strings.ToUpper(abbreviation),
+		SortOrder:    req.SortOrder,
+	}
+}
+
+// cartesianValues 按属性顺序生成取值的全部组合
+func cartesianValues(valueSets [][]models.ItemAttributeValue) [][]models.ItemAttributeValue {
+	combinations := [][]models.ItemAttributeValue{{}}
+	for _, values := range valueSets {
+		next := make([][]models.ItemAttributeValue, 0, len(combinations)*len(values))
+		for _, combination := range combinations {
+			for _, value := range values {
+				extended := make([]models.ItemAttributeValue, len(combination), len(combination)+1)
+				copy(extended, combination)
+				next = append(next, append(extended, value))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// variantKey 由按模板属性顺序排列的取值ID构造组合键
+func variantKey(valueIDs []uint) string {
+	parts := make([]string, len(valueIDs))
+	for i, valueID := range valueIDs {
+		parts[i] = strconv.FormatUint(uint64(valueID), 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+// toItemAttributeResponse 转换为物料属性响应
+func toItemAttributeResponse(attribute *models.ItemAttribute) dto.ItemAttributeResponse {
+	response := dto.ItemAttributeResponse{
+		ID:          attribute.ID,
+		Code:        attribute.Code,
+		Name:        attribute.Name,
+		Description: attribute.Description,
+		Values:      make([]dto.ItemAttributeValueResponse, 0, len(attribute.Values)),
+		CreatedAt:   attribute.CreatedAt,
+		UpdatedAt:   attribute.UpdatedAt,
+	}
+	for _, value := range attribute.Values {
+		response.Values = append(response.Values, dto.ItemAttributeValueResponse{
+			ID:           value.ID,
+			Value:        value.Value,
+			Abbreviation: value.Abbreviation,
+			SortOrder:    value.SortOrder,
+		})
+	}
+	return response
+}
+
+// toItemVariantResponse 转换为变体物料响应
+func toItemVariantResponse(variant *models.Item) dto.ItemVariantResponse {
+	return dto.ItemVariantResponse{
+		ID:         variant.ID,
+		Code:       variant.Code,
+		Name:       variant.Name,
+		SalePrice:  variant.Price,
+		UnitCost:   variant.Cost,
+		IsActive:   variant.IsActive,
+		Attributes: toVariantAttributeResponses(variant.VariantAttributes),
+	}
+}
+
+// toVariantAttributeResponses 转换变体的属性取值，按属性ID排序
+func toVariantAttributeResponses(attributes []models.ItemVariantAttribute) []dto.ItemVariantAttributeResponse {
+	if len(attributes) == 0 {
+		return nil
+	}
+	responses := make([]dto.ItemVariantAttributeResponse, 0, len(attributes))
+	for _, attribute := range attributes {
+		response := dto.ItemVariantAttributeResponse{
+			AttributeID: attribute.AttributeID,
+			ValueID:     attribute.AttributeValueID,
+		}
+		if attribute.Attribute != nil {
+			response.AttributeCode = attribute.Attribute.Code
+			response.AttributeName = attribute.Attribute.Name
+		}
+		if attribute.AttributeValue != nil {
+			response.Value = attribute.AttributeValue.Value
+		}
+		responses = append(responses, response)
+	}
+	sort.Slice(responses, func(i, j int) bool { return responses[i].AttributeID < responses[j].AttributeID })
+	return responses
+}
@@ -0,0 +1,455 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// 到岸成本默认科目
+const (
+	DefaultLandedCostChargeAccountCode  = "2202" // 费用贷方科目（应付账款）
+	DefaultLandedCostExpenseAccountCode = "5401" // 已出库部分计入的科目（主营业务成本）
+)
+
+// landedCostChargeNames 到岸成本费用类型名称，用于凭证摘要
+var landedCostChargeNames = map[string]string{
+	models.LandedCostChargeFreight:   "运费",
+	models.LandedCostChargeCustoms:   "关税",
+	models.LandedCostChargeInsurance: "保险费",
+	models.LandedCostChargeOther:     "其他费用",
+}
+
+// landedCostMethodNames 分摊方式名称，用于错误提示
+var landedCostMethodNames = map[string]string{
+	models.LandedCostAllocateByQuantity: "数量",
+	models.LandedCostAllocateByValue:    "金额",
+	models.LandedCostAllocateByWeight:   "重量",
+	models.LandedCostAllocateManual:     "手工金额",
+}
+
+// LandedCostService 到岸成本服务接口
+type LandedCostService interface {
+	CreateLandedCost(ctx context.Context, req *dto.LandedCostCreateRequest, userID uint) (*dto.LandedCostResponse, error)
+	GetLandedCost(ctx context.Context, id uint) (*dto.LandedCostResponse, error)
+	ListLandedCosts(ctx context.Context, req *dto.LandedCostListRequest) ([]dto.LandedCostResponse, int64, error)
+	SubmitLandedCost(ctx context.Context, id uint, req *dto.LandedCostSubmitRequest) (*dto.LandedCostResponse, error)
+	CancelLandedCost(ctx context.Context, id uint) (*dto.LandedCostResponse, error)
+}
+
+// LandedCostServiceImpl 到岸成本服务实现
+type LandedCostServiceImpl struct {
+	landedCostRepo repositories.LandedCostRepository
+	accountRepo    repositories.AccountRepository
+	companyRepo    repositories.CompanyRepository
+}
+
+// NewLandedCostService 创建到岸成本服务实例
+func NewLandedCostService(
+	landedCostRepo repositories.LandedCostRepository,
+	accountRepo repositories.AccountRepository,
+	companyRepo repositories.CompanyRepository,
+) LandedCostService {
+	return &LandedCostServiceImpl{
+		landedCostRepo: landedCostRepo,
+		accountRepo:    accountRepo,
+		companyRepo:    companyRepo,
+	}
+}
+
+// CreateLandedCost 创建到岸成本单：读取收货单各明细的入库数量与金额，按分摊方式将费用合计分摊到各明细
+func (s *LandedCostServiceImpl) CreateLandedCost(ctx context.Context, req *dto.LandedCostCreateRequest, userID uint) (*dto.LandedCostResponse, error) {
+	companyID, err := resolveCompanyID(ctx, s.companyRepo, req.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	if req.AllocationMethod != models.LandedCostAllocateManual && len(req.Allocations) > 0 {
+		return nil, errors.New("只有手工分摊才能指定各收货明细的分摊金额")
+	}
+
+	receiptIDs := make([]uint, 0, len(req.PurchaseReceiptIDs))
+	seen := make(map[uint]bool, len(req.PurchaseReceiptIDs))
+	receipts := make([]models.LandedCostReceipt, 0, len(req.PurchaseReceiptIDs))
+	for _, receiptID := range req.PurchaseReceiptIDs {
+		if seen[receiptID] {
+			continue
+		}
+		seen[receiptID] = true
+		receipt, err := s.landedCostRepo.GetPurchaseReceipt(ctx, receiptID)
+		if err != nil {
+			return nil, fmt.Errorf("获取采购收货单失败: %w", err)
+		}
+		if receipt == nil {
+			return nil, fmt.Errorf("采购收货单 %d 不存在", receiptID)
+		}
+		if receipt.CompanyID != companyID {
+			return nil, fmt.Errorf("采购收货单 %s 不属于当前公司", receipt.ReceiptNumber)
+		}
+		receiptIDs = append(receiptIDs, receiptID)
+		receipts = append(receipts, models.LandedCostReceipt{PurchaseReceiptID: receiptID})
+	}
+
+	rows, err := s.landedCostRepo.GetReceiptStockRows(ctx, receiptIDs)
+	if err != nil {
+		return nil, fmt.Errorf("读取收货入库记录失败: %w", err)
+	}
+	received := make(map[uint]bool, len(receiptIDs))
+	itemIDs := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		received[row.PurchaseReceiptID] = true
+		itemIDs = append(itemIDs, row.ItemID)
+	}
+	for _, receiptID := range receiptIDs {
+		if !received[receiptID] {
+			return nil, fmt.Errorf("采购收货单 %d 尚未入库，不能分摊到岸成本", receiptID)
+		}
+	}
+	items, err := s.landedCostRepo.GetItemsByIDs(ctx, itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料失败: %w", err)
+	}
+
+	lines := make([]models.LandedCostItem, 0, len(rows))
+	for _, row := range rows {
+		line := models.LandedCostItem{
+			PurchaseReceiptID:     row.PurchaseReceiptID,
+			PurchaseReceiptItemID: row.PurchaseReceiptItemID,
+			ItemID:                row.ItemID,
+			WarehouseID:           row.WarehouseID,
+			Quantity:              row.Quantity,
+			Amount:                row.Amount,
+		}
+		if item := items[row.ItemID]; item != nil {
+			line.Weight = item.Weight * row.Quantity
+		}
+		lines = append(lines, line)
+	}
+
+	charges := make([]models.LandedCostCharge, 0, len(req.Charges))
+	var total models.Money
+	for _, charge := range req.Charges {
+		accountCode := strings.TrimSpace(charge.AccountCode)
+		if accountCode == "" {
+			accountCode = DefaultLandedCostChargeAccountCode
+		}
+		charges = append(charges, models.LandedCostCharge{
+			ChargeType:  charge.ChargeType,
+			Description: charge.Description,
+			Amount:      charge.Amount,
+			AccountCode: accountCode,
+			SupplierID:  charge.SupplierID,
+		})
+		total = total.Add(charge.Amount)
+	}
+
+	if err := allocateLandedCost(req.AllocationMethod, lines, total, req.Allocations); err != nil {
+		return nil, err
+	}
+
+	postingDate := truncateToDay(time.Now())
+	if req.PostingDate != nil {
+		postingDate = truncateToDay(*req.PostingDate)
+	}
+	now := time.Now()
+	voucher := &models.LandedCostVoucher{
+		CompanyID:        companyID,
+		VoucherNumber:    fmt.Sprintf("LCV%s%06d", now.Format("20060102"), now.UnixNano()/1000%1000000),
+		PostingDate:      postingDate,
+		AllocationMethod: req.AllocationMethod,
+		Status:           models.LandedCostStatusDraft,
+		TotalCharges:     total,
+		Notes:            req.Notes,
+		Receipts:         receipts,
+		Charges:          charges,
+		Items:            lines,
+	}
+	if userID != 0 {
+		voucher.CreatedBy = &userID
+	}
+	if err := s.landedCostRepo.Create(ctx, voucher); err != nil {
+		return nil, fmt.Errorf("创建到岸成本单失败: %w", err)
+	}
+
+	utils.Info("到岸成本单已创建",
+		utils.Uint("landed_cost_voucher_id", voucher.ID),
+		utils.String("voucher_number", voucher.VoucherNumber),
+		utils.String("allocation_method", voucher.AllocationMethod),
+		utils.Int("lines", len(lines)),
+	)
+	return s.GetLandedCost(ctx, voucher.ID)
+}
+
+// GetLandedCost 获取到岸成本单详情
+func (s *LandedCostServiceImpl) GetLandedCost(ctx context.Context, id uint) (*dto.LandedCostResponse, error) {
+	voucher, err := s.loadVoucher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toLandedCostResponse(voucher, true), nil
+}
+
+// ListLandedCosts 分页获取到岸成本单
+func (s *LandedCostServiceImpl) ListLandedCosts(ctx context.Context, req *dto.LandedCostListRequest) ([]dto.LandedCostResponse, int64, error) {
+	filter := repositories.LandedCostFilter{
+		Status:            req.Status,
+		PurchaseReceiptID: req.PurchaseReceiptID,
+	}
+	vouchers, total, err := s.landedCostRepo.ListVouchers(ctx, filter, req.GetOffset(), req.GetLimit())
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取到岸成本单失败: %w", err)
+	}
+	responses := make([]dto.LandedCostResponse, 0, len(vouchers))
+	for _, voucher := range vouchers {
+		responses = append(responses, *toLandedCostResponse(voucher, false))
+	}
+	return responses, total, nil
+}
+
+// SubmitLandedCost 提交到岸成本单：调整收货物料的库存金额与先进先出成本层，并生成费用资本化的总账凭证
+func (s *LandedCostServiceImpl) SubmitLandedCost(ctx context.Context, id uint, req *dto.LandedCostSubmitRequest) (*dto.LandedCostResponse, error) {
+	voucher, err := s.loadVoucher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if voucher.Status != models.LandedCostStatusDraft {
+		return nil, errors.New("只有草稿状态的到岸成本单才能提交")
+	}
+	if err := ensurePeriodOpen(ctx, s.companyRepo, voucher.CompanyID, voucher.PostingDate); err != nil {
+		return nil, err
+	}
+
+	inventoryCode := strings.TrimSpace(req.InventoryAccountCode)
+	if inventoryCode == "" {
+		inventoryCode = DefaultInventoryAccountCode
+	}
+	expenseCode := strings.TrimSpace(req.ExpenseAccountCode)
+	if expenseCode == "" {
+		expenseCode = DefaultLandedCostExpenseAccountCode
+	}
+	inventoryAccount, err := s.accountRepo.GetByCode(ctx, voucher.CompanyID, inventoryCode)
+	if err != nil || inventoryAccount == nil {
+		return nil, fmt.Errorf("存货科目 %s 不存在", inventoryCode)
+	}
+	expenseAccount, err := s.accountRepo.GetByCode(ctx, voucher.CompanyID, expenseCode)
+	if err != nil || expenseAccount == nil {
+		return nil, fmt.Errorf("销售成本科目 %s 不存在", expenseCode)
+	}
+
+	journal := &repositories.LandedCostJournal{
+		CompanyID:          voucher.CompanyID,
+		InventoryAccountID: inventoryAccount.ID,
+		ExpenseAccountID:   expenseAccount.ID,
+		Date:               voucher.PostingDate,
+		Description:        fmt.Sprintf("到岸成本 %s", voucher.VoucherNumber),
+	}
+	for _, charge := range voucher.Charges {
+		account, err := s.accountRepo.GetByCode(ctx, voucher.CompanyID, charge.AccountCode)
+		if err != nil || account == nil {
+			return nil, fmt.Errorf("费用科目 %s 不存在", charge.AccountCode)
+		}
+		description := charge.Description
+		if description == "" {
+			description = landedCostChargeNames[charge.ChargeType]
+		}
+		journal.Credits = append(journal.Credits, repositories.LandedCostCredit{
+			AccountID:   account.ID,
+			Amount:      charge.Amount,
+			Description: description,
+		})
+	}
+
+	if err := s.landedCostRepo.SubmitVoucher(ctx, voucher, journal); err != nil {
+		return nil, fmt.Errorf("提交到岸成本单失败: %w", err)
+	}
+
+	utils.Info("到岸成本单已提交",
+		utils.Uint("landed_cost_voucher_id", voucher.ID),
+		utils.String("voucher_number", voucher.VoucherNumber),
+	)
+	return s.GetLandedCost(ctx, id)
+}
+
+// CancelLandedCost 取消草稿状态的到岸成本单
+func (s *LandedCostServiceImpl) CancelLandedCost(ctx context.Context, id uint) (*dto.LandedCostResponse, error) {
+	voucher, err := s.loadVoucher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if voucher.Status != models.LandedCostStatusDraft {
+		return nil, errors.New("只有草稿状态的到岸成本单才能取消")
+	}
+	if err := s.landedCostRepo.UpdateVoucher(ctx, voucher, map[string]interface{}{
+		"status": models.LandedCostStatusCancelled,
+	}); err != nil {
+		return nil, fmt.Errorf("取消到岸成本单失败: %w", err)
+	}
+	return s.GetLandedCost(ctx, id)
+}
+
+// loadVoucher 获取到岸成本单，不存在时返回错误
+func (s *LandedCostServiceImpl) loadVoucher(ctx context.Context, id uint) (*models.LandedCostVoucher, error) {
+	voucher, err := s.landedCostRepo.GetVoucher(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取到岸成本单失败: %w", err)
+	}
+	if voucher == nil {
+		return nil, errors.New("到岸成本单不存在")
+	}
+	return voucher, nil
+}
+
+// allocateLandedCost 按分摊依据将费用合计分摊到各分摊行，尾差计入最后一个分摊依据不为 0 的行。
+// 手工分摊时各收货明细的金额按入库数量拆分到其入库仓库
+func allocateLandedCost(method string, lines []models.LandedCostItem, total models.Money, manual []dto.LandedCostManualAllocation) error {
+	bases := make([]float64, len(lines))
+	switch method {
+	case models.LandedCostAllocateByQuantity:
+		for i, line := range lines {
+			bases[i] = line.Quantity
+		}
+	case models.LandedCostAllocateByValue:
+		for i, line := range lines {
+			bases[i] = line.Amount.Float64()
+		}
+	case models.LandedCostAllocateByWeight:
+		for i, line := range lines {
+			bases[i] = line.Weight
+		}
+	case models.LandedCostAllocateManual:
+		receiptLineQty := make(map[uint]float64, len(lines))
+		for _, line := range lines {
+			receiptLineQty[line.PurchaseReceiptItemID] += line.Quantity
+		}
+		amounts := make(map[uint]models.Money, len(manual))
+		var manualTotal models.Money
+		for _, allocation := range manual {
+			if _, ok := receiptLineQty[allocation.PurchaseReceiptItemID]; !ok {
+				return fmt.Errorf("收货明细 %d 不属于所选收货单或尚未入库", allocation.PurchaseReceiptItemID)
+			}
+			amounts[allocation.PurchaseReceiptItemID] = amounts[allocation.PurchaseReceiptItemID].Add(allocation.Amount)
+			manualTotal = manualTotal.Add(allocation.Amount)
+		}
+		if manualTotal != total {
+			return fmt.Errorf("手工分摊金额合计 %s 与费用合计 %s 不一致", manualTotal, total)
+		}
+		for i, line := range lines {
+			bases[i] = amounts[line.PurchaseReceiptItemID].Float64() * line.Quantity / receiptLineQty[line.PurchaseReceiptItemID]
+		}
+	default:
+		return fmt.Errorf("不支持的分摊方式: %s", method)
+	}
+
+	var sum float64
+	last := -1
+	for i, basis := range bases {
+		if basis < 0 {
+			bases[i] = 0
+			continue
+		}
+		if basis > 0 {
+			sum += basis
+			last = i
+		}
+	}
+	if sum <= 0 {
+		return fmt.Errorf("收货明细的%s合计为 0，不能按%s分摊", landedCostMethodNames[method], landedCostMethodNames[method])
+	}
+
+	var allocated models.Money
+	for i := range lines {
+		if bases[i] == 0 {
+			lines[i].AllocatedAmount = 0
+			continue
+		}
+		if i == last {
+			lines[i].AllocatedAmount = total.Sub(allocated)
+			continue
+		}
+		amount, err := total.Mul(bases[i] / sum)
+		if err != nil {
+			return err
+		}
+		lines[i].AllocatedAmount = amount
+		allocated = allocated.Add(lines[i].AllocatedAmount)
+	}
+	return nil
+}
+
+// toLandedCostResponse 转换为到岸成本单响应，withLines 为 false 时不返回费用与分摊行
+func toLandedCostResponse(voucher *models.LandedCostVoucher, withLines bool) *dto.LandedCostResponse {
+	response := &dto.LandedCostResponse{
+		ID:                voucher.ID,
+		CompanyID:         voucher.CompanyID,
+		VoucherNumber:     voucher.VoucherNumber,
+		PostingDate:       voucher.PostingDate,
+		AllocationMethod:  voucher.AllocationMethod,
+		Status:            voucher.Status,
+		TotalCharges:      voucher.TotalCharges,
+		CapitalizedAmount: voucher.CapitalizedAmount,
+		ExpensedAmount:    voucher.ExpensedAmount,
+		SubmittedAt:       voucher.SubmittedAt,
+		TransactionID:     voucher.TransactionID,
+		Notes:             voucher.Notes,
+		Receipts:          make([]dto.LandedCostReceiptResponse, 0, len(voucher.Receipts)),
+		CreatedAt:         voucher.CreatedAt,
+		UpdatedAt:         voucher.UpdatedAt,
+	}
+	for _, receipt := range voucher.Receipts {
+		receiptResponse := dto.LandedCostReceiptResponse{PurchaseReceiptID: receipt.PurchaseReceiptID}
+		if receipt.PurchaseReceipt != nil {
+			receiptResponse.ReceiptNumber = receipt.PurchaseReceipt.ReceiptNumber
+			receiptResponse.SupplierID = receipt.PurchaseReceipt.SupplierID
+			receiptResponse.Date = receipt.PurchaseReceipt.Date
+		}
+		response.Receipts = append(response.Receipts, receiptResponse)
+	}
+	if !withLines {
+		return response
+	}
+
+	for _, charge := range voucher.Charges {
+		response.Charges = append(response.Charges, dto.LandedCostChargeResponse{
+			ID:          charge.ID,
+			ChargeType:  charge.ChargeType,
+			Description: charge.Description,
+			Amount:      charge.Amount,
+			AccountCode: charge.AccountCode,
+			SupplierID:  charge.SupplierID,
+		})
+	}
+	for _, line := range voucher.Items {
+		lineResponse := dto.LandedCostItemResponse{
+			ID:                    line.ID,
+			PurchaseReceiptID:     line.PurchaseReceiptID,
+			PurchaseReceiptItemID: line.PurchaseReceiptItemID,
+			ItemID:                line.ItemID,
+			WarehouseID:           line.WarehouseID,
+			Quantity:              line.Quantity,
+			Amount:                line.Amount,
+			Weight:                line.Weight,
+			AllocatedAmount:       line.AllocatedAmount,
+			CapitalizedAmount:     line.CapitalizedAmount,
+			ExpensedAmount:        line.ExpensedAmount,
+			MovementID:            line.MovementID,
+		}
+		if line.Quantity > stockQuantityTolerance {
+			// 到岸单价仅用于展示，超出金额范围时不返回
+			if unitCost, err := line.Amount.Add(line.AllocatedAmount).Div(line.Quantity); err == nil {
+				lineResponse.LandedUnitCost = unitCost
+			}
+		}
+		if line.Item != nil {
+			lineResponse.ItemCode = line.Item.Code
+			lineResponse.ItemName = line.Item.Name
+		}
+		response.Items = append(response.Items, lineResponse)
+	}
+	return response
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/galaxyerp/galaxyErp/internal/models"
+)
+
+// lineTotals 单据明细行金额，各项均已按币种精度舍入
+type lineTotals struct {
+	amount   models.Money // 单价 × 数量
+	discount models.Money // 折扣金额
+	net      models.Money // 折后金额
+	tax      models.Money // 按折后金额计算的税额
+	netRate  models.Money // 含税折后单价
+}
+
+// total 含税折后合计
+func (l lineTotals) total() models.Money {
+	return l.net + l.tax
+}
+
+// calculateLineTotals 按单价、数量、折扣率与税率计算明细行金额，逐项按币种精度舍入；
+// 数量或单价过大导致金额超出范围时返回错误
+func calculateLineTotals(rate models.Money, quantity, discountRate, taxRate float64, currency string) (lineTotals, error) {
+	var line lineTotals
+	amount, err := rate.Mul(quantity)
+	if err != nil {
+		return line, fmt.Errorf("明细金额计算失败: %w", err)
+	}
+	line.amount = amount.RoundCurrency(currency)
+
+	discount, err := line.amount.Percent(discountRate)
+	if err != nil {
+		return line, fmt.Errorf("明细折扣计算失败: %w", err)
+	}
+	line.discount = discount.RoundCurrency(currency)
+	line.net = line.amount - line.discount
+
+	tax, err := line.net.Percent(taxRate)
+	if err != nil {
+		return line, fmt.Errorf("明细税额计算失败: %w", err)
+	}
+	line.tax = tax.RoundCurrency(currency)
+
+	if line.netRate, err = line.total().Div(quantity); err != nil {
+		return line, fmt.Errorf("明细单价计算失败: %w", err)
+	}
+	return line, nil
+}
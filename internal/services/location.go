@@ -0,0 +1,845 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// 上架规则类型
+const (
+	PutawayRuleTypeFixedBin = "fixed_bin"
+	PutawayRuleTypeCategory = "category"
+	PutawayRuleTypeGeneral  = "general"
+	PutawayRuleTypeCapacity = "capacity" // 无适用规则时按库位顺序与剩余容量分配
+)
+
+// ExpiryQuarantineReference 过期批次移入隔离库位的移库参考号
+const ExpiryQuarantineReference = "expiry-quarantine"
+
+// LocationService 库位、上架与拣货服务接口
+type LocationService interface {
+	CreateLocation(ctx context.Context, warehouseID uint, req *dto.LocationCreateRequest) (*dto.LocationResponse, error)
+	GetLocation(ctx context.Context, warehouseID, id uint) (*dto.LocationResponse, error)
+	UpdateLocation(ctx context.Context, warehouseID, id uint, req *dto.LocationUpdateRequest) (*dto.LocationResponse, error)
+	DeleteLocation(ctx context.Context, warehouseID, id uint) error
+	ListLocations(ctx context.Context, warehouseID uint) ([]dto.LocationResponse, error)
+	GetLocationStock(ctx context.Context, warehouseID uint, req *dto.LocationStockRequest) (*dto.LocationStockResponse, error)
+	CreatePutawayRule(ctx context.Context, req *dto.PutawayRuleCreateRequest) (*dto.PutawayRuleResponse, error)
+	UpdatePutawayRule(ctx context.Context, id uint, req *dto.PutawayRuleUpdateRequest) (*dto.PutawayRuleResponse, error)
+	DeletePutawayRule(ctx context.Context, id uint) error
+	ListPutawayRules(ctx context.Context, req *dto.PutawayRuleListRequest) ([]dto.PutawayRuleResponse, error)
+	SuggestPutaway(ctx context.Context, warehouseID uint, req *dto.PutawaySuggestionRequest) (*dto.PutawaySuggestionResponse, error)
+	MoveStock(ctx context.Context, req *dto.LocationMoveRequest) (*dto.LocationMoveResponse, error)
+	ListLocationMoves(ctx context.Context, req *dto.LocationMoveListRequest) ([]dto.LocationMoveResponse, int64, error)
+	QuarantineExpiredStock(ctx context.Context, req *dto.ExpiryQuarantineRequest) (*dto.ExpiryQuarantineResponse, error)
+	GetPickingList(ctx context.Context, deliveryNoteID uint, req *dto.PickingListRequest) (*dto.PickingListResponse, error)
+}
+
+// LocationServiceImpl 库位服务实现
+type LocationServiceImpl struct {
+	locationRepo     repositories.LocationRepository
+	warehouseRepo    repositories.WarehouseRepository
+	itemRepo         repositories.ItemRepository
+	batchRepo        repositories.BatchRepository
+	deliveryNoteRepo repositories.DeliveryNoteRepository
+}
+
+// NewLocationService 创建库位服务实例
+func NewLocationService(locationRepo repositories.LocationRepository, warehouseRepo repositories.WarehouseRepository, itemRepo repositories.ItemRepository, batchRepo repositories.BatchRepository, deliveryNoteRepo repositories.DeliveryNoteRepository) LocationService {
+	return &LocationServiceImpl{
+		locationRepo:     locationRepo,
+		warehouseRepo:    warehouseRepo,
+		itemRepo:         itemRepo,
+		batchRepo:        batchRepo,
+		deliveryNoteRepo: deliveryNoteRepo,
+	}
+}
+
+// CreateLocation 在仓库下创建库位，编码在仓库内唯一
+func (s *LocationServiceImpl) CreateLocation(ctx context.Context, warehouseID uint, req *dto.LocationCreateRequest) (*dto.LocationResponse, error) {
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("仓库 %d 不存在", warehouseID)
+	}
+	if warehouse.IsTransit {
+		return nil, errors.New("在途仓库不能创建库位")
+	}
+	existing, err := s.locationRepo.GetByCode(ctx, warehouseID, req.Code)
+	if err != nil {
+		return nil, fmt.Errorf("检查库位编码失败: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("仓库中已存在库位编码 %s", req.Code)
+	}
+
+	location := &models.Location{
+		WarehouseID:  warehouseID,
+		Code:         req.Code,
+		Name:         req.Name,
+		LocationType: req.Type,
+		Status:       models.LocationStatusActive,
+		Description:  req.Description,
+		Capacity:     req.Capacity,
+		Sequence:     req.Sequence,
+		Zone:         strings.TrimSpace(req.Zone),
+	}
+	if err := s.locationRepo.Create(ctx, location); err != nil {
+		return nil, fmt.Errorf("创建库位失败: %w", err)
+	}
+
+	return toLocationResponse(location, 0), nil
+}
+
+// GetLocation 获取库位详情及已占用数量
+func (s *LocationServiceImpl) GetLocation(ctx context.Context, warehouseID, id uint) (*dto.LocationResponse, error) {
+	location, err := s.getLocation(ctx, warehouseID, id)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.locationRepo.GetLocationUsage(ctx, []uint{id})
+	if err != nil {
+		return nil, fmt.Errorf("获取库位占用失败: %w", err)
+	}
+	return toLocationResponse(location, usage[id]), nil
+}
+
+// UpdateLocation 更新库位，容量不能小于已占用数量
+func (s *LocationServiceImpl) UpdateLocation(ctx context.Context, warehouseID, id uint, req *dto.LocationUpdateRequest) (*dto.LocationResponse, error) {
+	location, err := s.getLocation(ctx, warehouseID, id)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.locationRepo.GetLocationUsage(ctx, []uint{id})
+	if err != nil {
+		return nil, fmt.Errorf("获取库位占用失败: %w", err)
+	}
+	used := usage[id]
+
+	if req.Name != "" {
+		location.Name = req.Name
+	}
+	if req.Type != "" {
+		location.LocationType = req.Type
+	}
+	if req.Description != "" {
+		location.Description = req.Description
+	}
+	if req.IsActive != nil {
+		location.Status = models.LocationStatusInactive
+		if *req.IsActive {
+			location.Status = models.LocationStatusActive
+		}
+	}
+	if req.Capacity != nil {
+		if *req.Capacity > 0 && *req.Capacity < used {
+			return nil, fmt.Errorf("库位已存放 %.2f，容量不能小于已占用数量", used)
+		}
+		location.Capacity = *req.Capacity
+	}
+	if req.Sequence != nil {
+		location.Sequence = *req.Sequence
+	}
+	if req.Zone != nil {
+		location.Zone = strings.TrimSpace(*req.Zone)
+	}
+
+	if err := s.locationRepo.Update(ctx, location); err != nil {
+		return nil, fmt.Errorf("更新库位失败: %w", err)
+	}
+	return toLocationResponse(location, used), nil
+}
+
+// DeleteLocation 删除库位，库位仍有库存或被上架规则引用时不能删除
+func (s *LocationServiceImpl) DeleteLocation(ctx context.Context, warehouseID, id uint) error {
+	if _, err := s.getLocation(ctx, warehouseID, id); err != nil {
+		return err
+	}
+	usage, err := s.locationRepo.GetLocationUsage(ctx, []uint{id})
+	if err != nil {
+		return fmt.Errorf("获取库位占用失败: %w", err)
+	}
+	if math.Abs(usage[id]) > stockQuantityTolerance {
+		return fmt.Errorf("库位仍有库存 %.2f，不能删除", usage[id])
+	}
+	rules, err := s.locationRepo.ListPutawayRules(ctx, warehouseID)
+	if err != nil {
+		return fmt.Errorf("获取上架规则失败: %w", err)
+	}
+	for _, rule := range rules {
+		if rule.LocationID == id {
+			return fmt.Errorf("库位被上架规则 %d 引用，不能删除", rule.ID)
+		}
+	}
+
+	if err := s.locationRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("删除库位失败: %w", err)
+	}
+	return nil
+}
+
+// ListLocations 获取仓库的库位及已占用数量
+func (s *LocationServiceImpl) ListLocations(ctx context.Context, warehouseID uint) ([]dto.LocationResponse, error) {
+	if _, err := s.warehouseRepo.GetByID(ctx, warehouseID); err != nil {
+		return nil, fmt.Errorf("仓库 %d 不存在", warehouseID)
+	}
+	locations, err := s.locationRepo.ListByWarehouse(ctx, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("获取库位列表失败: %w", err)
+	}
+	ids := make([]uint, 0, len(locations))
+	for _, location := range locations {
+		ids = append(ids, location.ID)
+	}
+	usage, err := s.locationRepo.GetLocationUsage(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("获取库位占用失败: %w", err)
+	}
+
+	responses := make([]dto.LocationResponse, 0, len(locations))
+	for _, location := range locations {
+		responses = append(responses, *toLocationResponse(location, usage[location.ID]))
+	}
+	return responses, nil
+}
+
+// GetLocationStock 获取仓库按库位、批次的库存，未指定库位时附带未分配库位的库存
+func (s *LocationServiceImpl) GetLocationStock(ctx context.Context, warehouseID uint, req *dto.LocationStockRequest) (*dto.LocationStockResponse, error) {
+	if _, err := s.warehouseRepo.GetByID(ctx, warehouseID); err != nil {
+		return nil, fmt.Errorf("仓库 %d 不存在", warehouseID)
+	}
+	rows, err := s.locationRepo.GetLocationStocks(ctx, repositories.LocationStockFilter{
+		WarehouseID: warehouseID,
+		LocationID:  req.LocationID,
+		ItemID:      req.ItemID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取库位库存失败: %w", err)
+	}
+
+	response := &dto.LocationStockResponse{
+		WarehouseID: warehouseID,
+		Lines:       make([]dto.LocationStockLine, 0, len(rows)),
+	}
+	for _, row := range rows {
+		response.Lines = append(response.Lines, dto.LocationStockLine{
+			ItemID:          row.ItemID,
+			ItemCode:        row.ItemCode,
+			ItemName:        row.ItemName,
+			LocationID:      row.LocationID,
+			LocationCode:    row.LocationCode,
+			LocationName:    row.LocationName,
+			BatchID:         row.BatchID,
+			BatchNo:         row.BatchNo,
+			ExpiryDate:      row.ExpiryDate,
+			Quantity:        row.Quantity,
+			FirstReceivedAt: row.FirstReceivedAt,
+		})
+		response.LocatedQuantity += row.Quantity
+	}
+
+	if req.LocationID == 0 {
+		unassigned, err := s.locationRepo.GetUnassignedStocks(ctx, warehouseID, req.ItemID)
+		if err != nil {
+			return nil, fmt.Errorf("获取未分配库位库存失败: %w", err)
+		}
+		for _, row := range unassigned {
+			response.Lines = append(response.Lines, dto.LocationStockLine{
+				ItemID:   row.ItemID,
+				ItemCode: row.ItemCode,
+				ItemName: row.ItemName,
+				Quantity: row.Quantity,
+			})
+			response.UnassignedQuantity += row.Quantity
+		}
+	}
+	return response, nil
+}
+
+// CreatePutawayRule 创建上架规则
+func (s *LocationServiceImpl) CreatePutawayRule(ctx context.Context, req *dto.PutawayRuleCreateRequest) (*dto.PutawayRuleResponse, error) {
+	if _, err := s.getLocation(ctx, req.WarehouseID, req.LocationID); err != nil {
+		return nil, err
+	}
+	if req.ItemID != nil {
+		if req.Category != "" {
+			return nil, errors.New("固定库位规则不能同时指定物料类别")
+		}
+		if _, err := s.itemRepo.GetByID(ctx, *req.ItemID); err != nil {
+			return nil, fmt.Errorf("物料 %d 不存在", *req.ItemID)
+		}
+	}
+
+	rule := &models.PutawayRule{
+		WarehouseID: req.WarehouseID,
+		LocationID:  req.LocationID,
+		ItemID:      req.ItemID,
+		Category:    req.Category,
+		Priority:    req.Priority,
+		IsActive:    true,
+		Notes:       req.Notes,
+	}
+	if err := s.locationRepo.CreatePutawayRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("创建上架规则失败: %w", err)
+	}
+	return s.getPutawayRule(ctx, rule.ID)
+}
+
+// UpdatePutawayRule 更新上架规则
+func (s *LocationServiceImpl) UpdatePutawayRule(ctx context.Context, id uint, req *dto.PutawayRuleUpdateRequest) (*dto.PutawayRuleResponse, error) {
+	rule, err := s.locationRepo.GetPutawayRule(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取上架规则失败: %w", err)
+	}
+	if rule == nil {
+		return nil, errors.New("上架规则不存在")
+	}
+
+	if req.LocationID != nil {
+		if _, err := s.getLocation(ctx, rule.WarehouseID, *req.LocationID); err != nil {
+			return nil, err
+		}
+		rule.LocationID = *req.LocationID
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	if req.Notes != nil {
+		rule.Notes = *req.Notes
+	}
+
+	if err := s.locationRepo.SavePutawayRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("更新上架规则失败: %w", err)
+	}
+	return s.getPutawayRule(ctx, id)
+}
+
+// DeletePutawayRule 删除上架规则
+func (s *LocationServiceImpl) DeletePutawayRule(ctx context.Context, id uint) error {
+	rule, err := s.locationRepo.GetPutawayRule(ctx, id)
+	if err != nil {
+		return fmt.Errorf("获取上架规则失败: %w", err)
+	}
+	if rule == nil {
+		return errors.New("上架规则不存在")
+	}
+	if err := s.locationRepo.DeletePutawayRule(ctx, id); err != nil {
+		return fmt.Errorf("删除上架规则失败: %w", err)
+	}
+	return nil
+}
+
+// ListPutawayRules 获取上架规则
+func (s *LocationServiceImpl) ListPutawayRules(ctx context.Context, req *dto.PutawayRuleListRequest) ([]dto.PutawayRuleResponse, error) {
+	rules, err := s.locationRepo.ListPutawayRules(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("获取上架规则失败: %w", err)
+	}
+	responses := make([]dto.PutawayRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		responses = append(responses, *toPutawayRuleResponse(rule))
+	}
+	return responses, nil
+}
+
+// SuggestPutaway 按上架规则为入库数量分配库位：固定库位、物料类别、通用规则依次匹配，
+// 库位容量不足时顺延到下一库位；没有适用规则时按库位顺序与剩余容量分配到存储库位
+func (s *LocationServiceImpl) SuggestPutaway(ctx context.Context, warehouseID uint, req *dto.PutawaySuggestionRequest) (*dto.PutawaySuggestionResponse, error) {
+	if _, err := s.warehouseRepo.GetByID(ctx, warehouseID); err != nil {
+		return nil, fmt.Errorf("仓库 %d 不存在", warehouseID)
+	}
+	item, err := s.itemRepo.GetByID(ctx, req.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("物料 %d 不存在", req.ItemID)
+	}
+
+	type candidate struct {
+		location *models.Location
+		ruleID   *uint
+		ruleType string
+	}
+	var candidates []candidate
+	seen := make(map[uint]bool)
+
+	rules, err := s.locationRepo.GetMatchingPutawayRules(ctx, warehouseID, item.ID, item.Category)
+	if err != nil {
+		return nil, fmt.Errorf("获取上架规则失败: %w", err)
+	}
+	for _, rule := range rules {
+		if rule.Location == nil || rule.Location.Status != models.LocationStatusActive || seen[rule.LocationID] {
+			continue
+		}
+		seen[rule.LocationID] = true
+		ruleID := rule.ID
+		candidates = append(candidates, candidate{location: rule.Location, ruleID: &ruleID, ruleType: putawayRuleType(rule)})
+	}
+	if len(rules) == 0 {
+		locations, err := s.locationRepo.ListByWarehouse(ctx, warehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("获取库位列表失败: %w", err)
+		}
+		for _, location := range locations {
+			if location.Status != models.LocationStatusActive || (location.LocationType != "" && location.LocationType != "storage") {
+				continue
+			}
+			candidates = append(candidates, candidate{location: location, ruleType: PutawayRuleTypeCapacity})
+		}
+	}
+
+	ids := make([]uint, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.location.ID)
+	}
+	usage, err := s.locationRepo.GetLocationUsage(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("获取库位占用失败: %w", err)
+	}
+
+	response := &dto.PutawaySuggestionResponse{
+		WarehouseID: warehouseID,
+		ItemID:      item.ID,
+		Quantity:    req.Quantity,
+		Allocations: make([]dto.PutawayAllocation, 0),
+	}
+	remaining := req.Quantity
+	for _, c := range candidates {
+		if remaining <= stockQuantityTolerance {
+			break
+		}
+		available := -1.0
+		quantity := remaining
+		if c.location.Capacity > 0 {
+			available = c.location.Capacity - usage[c.location.ID]
+			if available <= stockQuantityTolerance {
+				continue
+			}
+			quantity = math.Min(remaining, available)
+		}
+		response.Allocations = append(response.Allocations, dto.PutawayAllocation{
+			LocationID:   c.location.ID,
+			LocationCode: c.location.Code,
+			Quantity:     quantity,
+			RuleID:       c.ruleID,
+			RuleType:     c.ruleType,
+			Available:    available,
+		})
+		remaining -= quantity
+	}
+	if remaining > stockQuantityTolerance {
+		response.UnallocatedQty = remaining
+	}
+	return response, nil
+}
+
+// MoveStock 仓库内库位间移库，只调整库位库存
+func (s *LocationServiceImpl) MoveStock(ctx context.Context, req *dto.LocationMoveRequest) (*dto.LocationMoveResponse, error) {
+	if req.FromLocationID == nil && req.ToLocationID == nil {
+		return nil, errors.New("调出库位与调入库位不能同时为空")
+	}
+	if req.FromLocationID != nil && req.ToLocationID != nil && *req.FromLocationID == *req.ToLocationID {
+		return nil, errors.New("调出库位与调入库位不能相同")
+	}
+	item, err := s.itemRepo.GetByID(ctx, req.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("物料 %d 不存在", req.ItemID)
+	}
+
+	move := &models.LocationMove{
+		ItemID:         item.ID,
+		WarehouseID:    req.WarehouseID,
+		FromLocationID: req.FromLocationID,
+		ToLocationID:   req.ToLocationID,
+		Quantity:       req.Quantity,
+		Reference:      req.Reference,
+		Notes:          req.Notes,
+	}
+	if req.BatchNo != "" {
+		batch, err := s.batchRepo.GetByBatchNo(ctx, item.ID, req.BatchNo)
+		if err != nil {
+			return nil, fmt.Errorf("获取批次失败: %w", err)
+		}
+		if batch == nil {
+			return nil, fmt.Errorf("物料 %s 的批次 %s 不存在", item.Code, req.BatchNo)
+		}
+		move.BatchID = batch.ID
+	} else if item.TrackingMode == models.TrackingModeBatch {
+		return nil, fmt.Errorf("物料 %s 启用了批次管理，移库必须指定批次号", item.Code)
+	}
+
+	if err := s.locationRepo.MoveStock(ctx, move); err != nil {
+		return nil, fmt.Errorf("移库失败: %w", err)
+	}
+	move.Item = item
+	return toLocationMoveResponse(move), nil
+}
+
+// ListLocationMoves 分页获取移库记录
+func (s *LocationServiceImpl) ListLocationMoves(ctx context.Context, req *dto.LocationMoveListRequest) ([]dto.LocationMoveResponse, int64, error) {
+	filter := repositories.LocationMoveFilter{
+		WarehouseID: req.WarehouseID,
+		ItemID:      req.ItemID,
+		LocationID:  req.LocationID,
+	}
+	moves, total, err := s.locationRepo.ListLocationMoves(ctx, filter, req.GetOffset(), req.GetLimit())
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取移库记录失败: %w", err)
+	}
+	responses := make([]dto.LocationMoveResponse, 0, len(moves))
+	for _, move := range moves {
+		responses = append(responses, *toLocationMoveResponse(move))
+	}
+	return responses, total, nil
+}
+
+// QuarantineExpiredStock 将已过期批次的库存移入所在仓库的隔离库位：先移出其他库位的库存，
+// 再将未分配库位的过期库存上架到隔离库位；未设置隔离库位的仓库跳过，单笔移库失败不影响其他库存
+func (s *LocationServiceImpl) QuarantineExpiredStock(ctx context.Context, req *dto.ExpiryQuarantineRequest) (*dto.ExpiryQuarantineResponse, error) {
+	now := time.Now()
+	today := models.StartOfDay(now)
+	batchRows, err := s.batchRepo.GetBatchStocks(ctx, repositories.BatchStockFilter{
+		WarehouseID:  req.WarehouseID,
+		ExpiryBefore: &today,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取过期批次库存失败: %w", err)
+	}
+	locationRows, err := s.locationRepo.GetExpiredLocationStocks(ctx, req.WarehouseID, now)
+	if err != nil {
+		return nil, fmt.Errorf("获取过期批次的库位库存失败: %w", err)
+	}
+
+	response := &dto.ExpiryQuarantineResponse{AsOf: today, Moves: make([]dto.LocationMoveResponse, 0)}
+	quarantines := make(map[uint]*models.Location)
+	quarantineOf := func(warehouseID uint) (*models.Location, error) {
+		if location, ok := quarantines[warehouseID]; ok {
+			return location, nil
+		}
+		location, err := s.locationRepo.GetQuarantineLocation(ctx, warehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("获取仓库 %d 的隔离库位失败: %w", warehouseID, err)
+		}
+		quarantines[warehouseID] = location
+		if location == nil {
+			response.SkippedWarehouses = append(response.SkippedWarehouses, warehouseID)
+		}
+		return location, nil
+	}
+
+	for _, row := range locationRows {
+		location, err := quarantineOf(row.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		if location == nil {
+			continue
+		}
+		fromLocationID := row.LocationID
+		s.moveToQuarantine(ctx, response, location, &fromLocationID, row.LocationCode, row.ItemID, row.ItemCode, row.BatchID, row.BatchNo, row.ExpiryDate, row.Quantity)
+	}
+	for _, row := range batchRows {
+		location, err := quarantineOf(row.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		if location == nil {
+			continue
+		}
+		batchID := row.BatchID
+		unassigned, err := s.locationRepo.GetUnassignedQuantity(ctx, row.ItemID, row.WarehouseID, &batchID)
+		if err != nil {
+			return nil, fmt.Errorf("获取未分配库位的库存失败: %w", err)
+		}
+		if unassigned <= stockQuantityTolerance {
+			continue
+		}
+		s.moveToQuarantine(ctx, response, location, nil, "", row.ItemID, row.ItemCode, row.BatchID, row.BatchNo, row.ExpiryDate, unassigned)
+	}
+	return response, nil
+}
+
+// moveToQuarantine 将一笔过期批次库存移入隔离库位，结果记入 response
+func (s *LocationServiceImpl) moveToQuarantine(ctx context.Context, response *dto.ExpiryQuarantineResponse, location *models.Location, fromLocationID *uint, fromLocationCode string, itemID uint, itemCode string, batchID uint, batchNo string, expiryDate *time.Time, quantity float64) {
+	move := &models.LocationMove{
+		ItemID:         itemID,
+		WarehouseID:    location.WarehouseID,
+		FromLocationID: fromLocationID,
+		ToLocationID:   &location.ID,
+		BatchID:        batchID,
+		Quantity:       quantity,
+		Reference:      ExpiryQuarantineReference,
+		Notes:          fmt.Sprintf("批次 %s 已于 %s 过期，移入隔离库位", batchNo, expiryDate.Format("2006-01-02")),
+	}
+	if err := s.locationRepo.MoveStock(ctx, move); err != nil {
+		response.Failed = append(response.Failed, dto.ExpiryQuarantineFailure{
+			WarehouseID:    location.WarehouseID,
+			ItemCode:       itemCode,
+			BatchNo:        batchNo,
+			FromLocationID: fromLocationID,
+			Quantity:       quantity,
+			Error:          err.Error(),
+		})
+		return
+	}
+
+	move.ToLocation = location
+	moveResponse := toLocationMoveResponse(move)
+	moveResponse.ItemCode = itemCode
+	moveResponse.FromLocationCode = fromLocationCode
+	response.Moves = append(response.Moves, *moveResponse)
+	response.TotalQuantity += quantity
+}
+
+// GetPickingList 为送货单生成拣货建议：按策略跨库位分配发货数量，库位库存不足时再从未分配库位的库存拣货，
+// 多行同一物料共用库位库存
+func (s *LocationServiceImpl) GetPickingList(ctx context.Context, deliveryNoteID uint, req *dto.PickingListRequest) (*dto.PickingListResponse, error) {
+	if _, err := s.deliveryNoteRepo.GetByID(ctx, deliveryNoteID); err != nil {
+		return nil, errors.New("送货单不存在")
+	}
+	lines, err := s.locationRepo.GetDeliveryNoteItems(ctx, deliveryNoteID)
+	if err != nil {
+		return nil, fmt.Errorf("获取送货单明细失败: %w", err)
+	}
+
+	response := &dto.PickingListResponse{
+		DeliveryNoteID: deliveryNoteID,
+		Lines:          make([]dto.PickingLine, 0, len(lines)),
+		Complete:       true,
+	}
+	picked := make(map[string]float64)
+	for _, line := range lines {
+		if line.WarehouseID == nil {
+			return nil, fmt.Errorf("送货单明细 %d 未指定发货仓库", line.ID)
+		}
+		item, err := s.itemRepo.GetByID(ctx, line.ItemID)
+		if err != nil {
+			return nil, fmt.Errorf("物料 %d 不存在", line.ItemID)
+		}
+		strategy := req.Strategy
+		if strategy == "" {
+			strategy = models.PickingStrategyFIFO
+			if item.TrackingMode == models.TrackingModeBatch || item.TrackingMode == models.TrackingModeSerial {
+				strategy = models.PickingStrategyFEFO
+			}
+		}
+
+		var batchID *uint
+		if line.BatchNo != "" {
+			batch, err := s.batchRepo.GetByBatchNo(ctx, item.ID, line.BatchNo)
+			if err != nil {
+				return nil, fmt.Errorf("获取批次失败: %w", err)
+			}
+			if batch == nil {
+				return nil, fmt.Errorf("物料 %s 的批次 %s 不存在", item.Code, line.BatchNo)
+			}
+			batchID = &batch.ID
+		}
+
+		candidates, err := s.locationRepo.GetPickCandidates(ctx, item.ID, *line.WarehouseID, batchID, strategy)
+		if err != nil {
+			return nil, fmt.Errorf("获取库位库存失败: %w", err)
+		}
+
+		pickingLine := dto.PickingLine{
+			LineID:      line.ID,
+			ItemID:      item.ID,
+			ItemCode:    item.Code,
+			WarehouseID: *line.WarehouseID,
+			Quantity:    line.StockQuantity(),
+			Strategy:    strategy,
+			Suggestions: make([]dto.PickingSuggestion, 0),
+		}
+		remaining := line.StockQuantity()
+		for _, candidate := range candidates {
+			if remaining <= stockQuantityTolerance {
+				break
+			}
+			key := fmt.Sprintf("%d:%d:%d", item.ID, candidate.LocationID, candidate.BatchID)
+			available := candidate.Quantity - picked[key]
+			if available <= stockQuantityTolerance {
+				continue
+			}
+			quantity := math.Min(remaining, available)
+			picked[key] += quantity
+			remaining -= quantity
+			pickingLine.Suggestions = append(pickingLine.Suggestions, dto.PickingSuggestion{
+				LocationID:   candidate.LocationID,
+				LocationCode: candidate.LocationCode,
+				BatchID:      candidate.BatchID,
+				BatchNo:      candidate.BatchNo,
+				ExpiryDate:   candidate.ExpiryDate,
+				Quantity:     quantity,
+			})
+		}
+
+		if remaining > stockQuantityTolerance {
+			unassigned, err := s.locationRepo.GetUnassignedQuantity(ctx, item.ID, *line.WarehouseID, batchID)
+			if err != nil {
+				return nil, fmt.Errorf("获取未分配库位库存失败: %w", err)
+			}
+			key := fmt.Sprintf("%d:%d:unassigned:%d", item.ID, *line.WarehouseID, derefUint(batchID))
+			available := unassigned - picked[key]
+			if available > stockQuantityTolerance {
+				quantity := math.Min(remaining, available)
+				picked[key] += quantity
+				remaining -= quantity
+				suggestion := dto.PickingSuggestion{Quantity: quantity, BatchNo: line.BatchNo}
+				if batchID != nil {
+					suggestion.BatchID = *batchID
+				}
+				pickingLine.Suggestions = append(pickingLine.Suggestions, suggestion)
+			}
+		}
+
+		if remaining > stockQuantityTolerance {
+			pickingLine.ShortageQty = remaining
+			response.Complete = false
+		}
+		response.Lines = append(response.Lines, pickingLine)
+	}
+	return response, nil
+}
+
+// getLocation 获取库位并校验其属于指定仓库
+func (s *LocationServiceImpl) getLocation(ctx context.Context, warehouseID, id uint) (*models.Location, error) {
+	location, err := s.locationRepo.GetLocation(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取库位失败: %w", err)
+	}
+	if location == nil || location.WarehouseID != warehouseID {
+		return nil, fmt.Errorf("仓库 %d 中不存在库位 %d", warehouseID, id)
+	}
+	return location, nil
+}
+
+func (s *LocationServiceImpl) getPutawayRule(ctx context.Context, id uint) (*dto.PutawayRuleResponse, error) {
+	rule, err := s.locationRepo.GetPutawayRule(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取上架规则失败: %w", err)
+	}
+	if rule == nil {
+		return nil, errors.New("上架规则不存在")
+	}
+	return toPutawayRuleResponse(rule), nil
+}
+
+func putawayRuleType(rule *models.PutawayRule) string {
+	switch {
+	case rule.ItemID != nil:
+		return PutawayRuleTypeFixedBin
+	case rule.Category != "":
+		return PutawayRuleTypeCategory
+	default:
+		return PutawayRuleTypeGeneral
+	}
+}
+
+func derefUint(value *uint) uint {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func toLocationResponse(location *models.Location, used float64) *dto.LocationResponse {
+	return &dto.LocationResponse{
+		ID:           location.ID,
+		Name:         location.Name,
+		Code:         location.Code,
+		Type:         location.LocationType,
+		Description:  location.Description,
+		IsActive:     location.Status == models.LocationStatusActive,
+		WarehouseID:  location.WarehouseID,
+		Capacity:     location.Capacity,
+		UsedQuantity: used,
+		Sequence:     location.Sequence,
+		Zone:         location.Zone,
+		CreatedAt:    location.CreatedAt,
+		UpdatedAt:    location.UpdatedAt,
+	}
+}
+
+func toPutawayRuleResponse(rule *models.PutawayRule) *dto.PutawayRuleResponse {
+	response := &dto.PutawayRuleResponse{
+		ID:          rule.ID,
+		WarehouseID: rule.WarehouseID,
+		LocationID:  rule.LocationID,
+		ItemID:      rule.ItemID,
+		Category:    rule.Category,
+		RuleType:    putawayRuleType(rule),
+		Priority:    rule.Priority,
+		IsActive:    rule.IsActive,
+		Notes:       rule.Notes,
+		CreatedAt:   rule.CreatedAt,
+		UpdatedAt:   rule.UpdatedAt,
+	}
+	if rule.Location != nil {
+		response.LocationCode = rule.Location.Code
+	}
+	if rule.Item != nil {
+		response.ItemCode = rule.Item.Code
+	}
+	return response
+}
+
+func toLocationMoveResponse(move *models.LocationMove) *dto.LocationMoveResponse {
+	response := &dto.LocationMoveResponse{
+		ID:             move.ID,
+		WarehouseID:    move.WarehouseID,
+		ItemID:         move.ItemID,
+		FromLocationID: move.FromLocationID,
+		ToLocationID:   move.ToLocationID,
+		BatchID:        move.BatchID,
+		Quantity:       move.Quantity,
+		Reference:      move.Reference,
+		Notes:          move.Notes,
+		CreatedAt:      move.CreatedAt,
+	}
+	if move.Item != nil {
+		response.ItemCode = move.Item.Code
+	}
+	if move.FromLocation != nil {
+		response.FromLocationCode = move.FromLocation.Code
+	}
+	if move.ToLocation != nil {
+		response.ToLocationCode = move.ToLocation.Code
+	}
+	return response
+}
+
+// NewExpiryQuarantineScheduler 创建过期批次隔离定时任务，按固定间隔将过期批次移入隔离库位，
+// 记录移库结果与失败明细
+func NewExpiryQuarantineScheduler(service LocationService, interval time.Duration) *PeriodicJob {
+	return NewPeriodicJob("过期批次隔离", interval, func(ctx context.Context) error {
+		result, err := service.QuarantineExpiredStock(ctx, &dto.ExpiryQuarantineRequest{})
+		if err != nil {
+			return err
+		}
+
+		if len(result.Moves) > 0 {
+			utils.Info("过期批次已移入隔离库位",
+				utils.Int("moves", len(result.Moves)),
+				utils.Float64("quantity", result.TotalQuantity))
+		}
+		for _, warehouseID := range result.SkippedWarehouses {
+			utils.Warn("仓库未设置隔离库位，过期批次未隔离", utils.Uint("warehouse_id", warehouseID))
+		}
+		for _, failure := range result.Failed {
+			utils.Warn("过期批次移入隔离库位失败",
+				utils.String("item", failure.ItemCode),
+				utils.String("batch", failure.BatchNo),
+				utils.String("error", failure.Error))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,581 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/common"
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+)
+
+// PaymentEntryService 付款记录服务接口
+type PaymentEntryService interface {
+	CreatePaymentEntry(ctx context.Context, payment *models.PaymentEntry) error
+	GetPaymentEntry(ctx context.Context, id uint) (*models.PaymentEntry, error)
+	UpdatePaymentEntry(ctx context.Context, payment *models.PaymentEntry) error
+	DeletePaymentEntry(ctx context.Context, id uint) error
+	ListPaymentEntries(ctx context.Context, page, pageSize int) ([]*models.PaymentEntry, int64, error)
+	GetPaymentEntriesByParty(ctx context.Context, partyType string, partyID uint, page, pageSize int) ([]*models.PaymentEntry, int64, error)
+
+	CreatePaymentEntryFromDTO(ctx context.Context, req *dto.PaymentEntryCreateRequest) (*dto.PaymentEntryResponse, error)
+	GetPaymentEntryDetail(ctx context.Context, id uint) (*dto.PaymentEntryResponse, error)
+	ListPaymentEntriesFromDTO(ctx context.Context, req *dto.PaymentEntryListRequest) ([]dto.PaymentEntryResponse, int64, error)
+	AllocatePayment(ctx context.Context, id uint, req *dto.PaymentAllocateRequest) (*dto.PaymentEntryResponse, error)
+	SubmitPaymentEntry(ctx context.Context, id uint) (*dto.PaymentEntryResponse, error)
+	CancelPaymentEntry(ctx context.Context, id uint) (*dto.PaymentEntryResponse, error)
+	ListUnallocatedPayments(ctx context.Context, req *dto.UnallocatedPaymentsRequest) ([]dto.PaymentEntryResponse, error)
+}
+
+// PaymentEntryServiceImpl 付款记录服务实现
+type PaymentEntryServiceImpl struct {
+	paymentRepo repositories.PaymentEntryRepository
+	accountRepo repositories.AccountRepository
+	companyRepo repositories.CompanyRepository
+}
+
+// NewPaymentEntryService 创建付款记录服务实例
+func NewPaymentEntryService(paymentRepo repositories.PaymentEntryRepository, accountRepo repositories.AccountRepository, companyRepo repositories.CompanyRepository) PaymentEntryService {
+	return &PaymentEntryServiceImpl{
+		paymentRepo: paymentRepo,
+		accountRepo: accountRepo,
+		companyRepo: companyRepo,
+	}
+}
+
+// CreatePaymentEntry 创建付款记录
+func (s *PaymentEntryServiceImpl) CreatePaymentEntry(ctx context.Context, payment *models.PaymentEntry) error {
+	// 验证必填字段
+	if payment.PaymentType == "" {
+		return errors.New("付款类型不能为空")
+	}
+	if payment.PartyType == "" {
+		return errors.New("关联方类型不能为空")
+	}
+	if payment.PartyID == 0 {
+		return errors.New("关联方ID不能为空")
+	}
+	if payment.PaidAmount < 0 || payment.ReceivedAmount < 0 {
+		return errors.New("付款金额不能为负数")
+	}
+
+	payment.CreatedAt = time.Now()
+	payment.UpdatedAt = time.Now()
+	if payment.Status == "" {
+		payment.Status = "draft"
+	}
+
+	return s.paymentRepo.Create(ctx, payment)
+}
+
+// GetPaymentEntry 获取付款记录
+func (s *PaymentEntryServiceImpl) GetPaymentEntry(ctx context.Context, id uint) (*models.PaymentEntry, error) {
+	if id == 0 {
+		return nil, errors.New("付款记录ID不能为空")
+	}
+	return s.paymentRepo.GetByID(ctx, id)
+}
+
+// UpdatePaymentEntry 更新草稿付款记录，已提交或已取消的付款不能修改，状态只能通过提交与取消变更
+func (s *PaymentEntryServiceImpl) UpdatePaymentEntry(ctx context.Context, payment *models.PaymentEntry) error {
+	// 检查记录是否存在
+	existing, err := s.paymentRepo.GetByID(ctx, payment.ID)
+	if err != nil {
+		return fmt.Errorf("检查付款记录失败: %w", err)
+	}
+	if existing == nil {
+		return errors.New("付款记录不存在")
+	}
+	if existing.Status != "draft" {
+		return errors.New("只有草稿状态的付款才能修改")
+	}
+	payment.Status = existing.Status
+	// 分配金额按付款币种计量，已有分配时不能修改币种
+	if payment.Currency != existing.Currency && existing.AllocatedAmount != 0 {
+		return errors.New("付款已有分配，不能修改币种")
+	}
+
+	// 验证必填字段
+	if payment.PaymentType == "" {
+		return errors.New("付款类型不能为空")
+	}
+	if payment.PartyType == "" {
+		return errors.New("关联方类型不能为空")
+	}
+	if payment.PartyID == 0 {
+		return errors.New("关联方ID不能为空")
+	}
+	if payment.PaidAmount < 0 || payment.ReceivedAmount < 0 {
+		return errors.New("付款金额不能为负数")
+	}
+
+	payment.UpdatedAt = time.Now()
+	return s.paymentRepo.Update(ctx, payment)
+}
+
+// DeletePaymentEntry 删除草稿付款记录，已提交的付款须先取消以冲回凭证与核销
+func (s *PaymentEntryServiceImpl) DeletePaymentEntry(ctx context.Context, id uint) error {
+	// 检查记录是否存在
+	payment, err := s.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("检查付款记录失败: %w", err)
+	}
+	if payment == nil {
+		return errors.New("付款记录不存在")
+	}
+	if payment.Status != "draft" {
+		return errors.New("只有草稿状态的付款才能删除")
+	}
+
+	return s.paymentRepo.Delete(ctx, id)
+}
+
+// ListPaymentEntries 获取付款记录列表
+func (s *PaymentEntryServiceImpl) ListPaymentEntries(ctx context.Context, page, pageSize int) ([]*models.PaymentEntry, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	options := &common.QueryOptions{
+		Pagination: &dto.PaginationRequest{
+			Page:     page,
+			PageSize: pageSize,
+		},
+	}
+	return s.paymentRepo.List(ctx, options)
+}
+
+// GetPaymentEntriesByParty 根据关联方获取付款记录
+func (s *PaymentEntryServiceImpl) GetPaymentEntriesByParty(ctx context.Context, partyType string, partyID uint, page, pageSize int) ([]*models.PaymentEntry, int64, error) {
+	if partyType == "" {
+		return nil, 0, errors.New("关联方类型不能为空")
+	}
+	if partyID == 0 {
+		return nil, 0, errors.New("关联方ID不能为空")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	return s.paymentRepo.GetByParty(ctx, partyType, partyID, offset, pageSize)
+}
+
+// CreatePaymentEntryFromDTO 创建草稿付款，可同时指定待核销的发票或应付单据
+func (s *PaymentEntryServiceImpl) CreatePaymentEntryFromDTO(ctx context.Context, req *dto.PaymentEntryCreateRequest) (*dto.PaymentEntryResponse, error) {
+	companyID, err := resolveCompanyID(ctx, s.companyRepo, req.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 收款对应客户，付款对应供应商
+	if expected := paymentPartyType(req.PaymentType); req.PartyType != expected {
+		return nil, fmt.Errorf("%s 类型的付款关联方必须是 %s", req.PaymentType, expected)
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	exchangeRate := req.ExchangeRate
+	if exchangeRate == 0 {
+		exchangeRate = 1
+	}
+
+	payment := &models.PaymentEntry{
+		CompanyID:         companyID,
+		PaymentNumber:     fmt.Sprintf("PE%s%06d", time.Now().Format("20060102"), time.Now().UnixNano()%1000000),
+		PaymentType:       req.PaymentType,
+		PartyType:         req.PartyType,
+		PartyID:           req.PartyID,
+		PostingDate:       req.PostingDate,
+		UnallocatedAmount: req.Amount,
+		Currency:          currency,
+		ExchangeRate:      exchangeRate,
+		PaymentMethod:     req.PaymentMethod,
+		BankAccountID:     req.BankAccountID,
+		CashAccountID:     req.CashAccountID,
+		PartyAccountID:    req.PartyAccountID,
+		Reference:         req.Reference,
+		Remarks:           req.Remarks,
+		Status:            "draft",
+	}
+	if req.PaymentType == models.PaymentTypeReceive {
+		payment.ReceivedAmount = req.Amount
+	} else {
+		payment.PaidAmount = req.Amount
+	}
+
+	if err := s.validatePaymentAccounts(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	allocations, err := s.buildAllocations(ctx, payment, req.Allocations)
+	if err != nil {
+		return nil, err
+	}
+	for _, allocation := range allocations {
+		payment.AllocatedAmount += allocation.AllocatedAmount
+		payment.Allocations = append(payment.Allocations, *allocation)
+	}
+	payment.UnallocatedAmount = payment.Amount() - payment.AllocatedAmount
+
+	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+		return nil, fmt.Errorf("创建付款记录失败: %w", err)
+	}
+
+	return s.GetPaymentEntryDetail(ctx, payment.ID)
+}
+
+// GetPaymentEntryDetail 获取付款详情及分配明细
+func (s *PaymentEntryServiceImpl) GetPaymentEntryDetail(ctx context.Context, id uint) (*dto.PaymentEntryResponse, error) {
+	payment, err := s.paymentRepo.GetWithAllocations(ctx, id)
+	if err != nil {
+		return nil, errors.New("付款记录不存在")
+	}
+	return toPaymentEntryResponse(payment), nil
+}
+
+// ListPaymentEntriesFromDTO 分页获取付款列表，可按关联方筛选
+func (s *PaymentEntryServiceImpl) ListPaymentEntriesFromDTO(ctx context.Context, req *dto.PaymentEntryListRequest) ([]dto.PaymentEntryResponse, int64, error) {
+	var payments []*models.PaymentEntry
+	var total int64
+	var err error
+	if req.PartyType != "" && req.PartyID != 0 {
+		payments, total, err = s.GetPaymentEntriesByParty(ctx, req.PartyType, req.PartyID, req.Page, req.GetLimit())
+	} else {
+		payments, total, err = s.ListPaymentEntries(ctx, req.Page, req.GetLimit())
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取付款列表失败: %w", err)
+	}
+
+	responses := make([]dto.PaymentEntryResponse, 0, len(payments))
+	for _, payment := range payments {
+		responses = append(responses, *toPaymentEntryResponse(payment))
+	}
+	return responses, total, nil
+}
+
+// AllocatePayment 分配付款金额；已提交付款立即核销，用于冲抵预收/预付余额
+func (s *PaymentEntryServiceImpl) AllocatePayment(ctx context.Context, id uint, req *dto.PaymentAllocateRequest) (*dto.PaymentEntryResponse, error) {
+	payment, err := s.paymentRepo.GetWithAllocations(ctx, id)
+	if err != nil {
+		return nil, errors.New("付款记录不存在")
+	}
+	if payment.Status == "cancelled" {
+		return nil, errors.New("已取消的付款不能分配")
+	}
+
+	allocations, err := s.buildAllocations(ctx, payment, req.Allocations)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.paymentRepo.AddAllocations(ctx, payment, allocations); err != nil {
+		return nil, fmt.Errorf("分配付款失败: %w", err)
+	}
+
+	return s.GetPaymentEntryDetail(ctx, payment.ID)
+}
+
+// SubmitPaymentEntry 提交付款：生成过账凭证并核销待核销分配
+func (s *PaymentEntryServiceImpl) SubmitPaymentEntry(ctx context.Context, id uint) (*dto.PaymentEntryResponse, error) {
+	payment, err := s.paymentRepo.GetWithAllocations(ctx, id)
+	if err != nil {
+		return nil, errors.New("付款记录不存在")
+	}
+	if payment.Status != "draft" {
+		return nil, errors.New("只有草稿状态的付款才能提交")
+	}
+
+	// 已关闭的会计期间不允许过账
+	if err := ensurePeriodOpen(ctx, s.companyRepo, payment.CompanyID, payment.PostingDate); err != nil {
+		return nil, err
+	}
+
+	cashAccountID, err := s.resolveCashAccountID(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	if payment.PartyAccountID == nil {
+		return nil, errors.New("提交付款前必须指定应收/应付科目")
+	}
+
+	// 内部往来单位的付款在合并报表中抵销
+	representsCompanyID, err := s.paymentRepo.GetPartyRepresentsCompany(ctx, payment.PartyType, payment.PartyID)
+	if err != nil {
+		return nil, fmt.Errorf("获取关联方失败: %w", err)
+	}
+
+	// 凭证按公司本位币记账，外币付款按付款汇率折算
+	baseCurrency, err := companyBaseCurrency(ctx, s.companyRepo, payment.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	amount := payment.Amount()
+	baseAmount := amount
+	if payment.Currency != baseCurrency && payment.ExchangeRate > 0 {
+		if baseAmount, err = amount.Mul(payment.ExchangeRate); err != nil {
+			return nil, fmt.Errorf("折算本位币金额失败: %w", err)
+		}
+		baseAmount = baseAmount.RoundCurrency(baseCurrency)
+	}
+	description := fmt.Sprintf("付款 %s", payment.PaymentNumber)
+	cashEntry := &models.JournalEntry{AccountID: cashAccountID, Description: description}
+	partyEntry := &models.JournalEntry{
+		AccountID:             *payment.PartyAccountID,
+		Description:           description,
+		IntercompanyCompanyID: representsCompanyID,
+	}
+	transactionType := "income"
+	if payment.PaymentType == models.PaymentTypeReceive {
+		// 收款：借 现金/银行，贷 应收账款
+		cashEntry.Debit = baseAmount
+		partyEntry.Credit = baseAmount
+	} else {
+		// 付款：借 应付账款，贷 现金/银行
+		partyEntry.Debit = baseAmount
+		cashEntry.Credit = baseAmount
+		transactionType = "expense"
+	}
+
+	transaction := &models.Transaction{
+		CompanyID:         payment.CompanyID,
+		TransactionNumber: fmt.Sprintf("TXN-%d", time.Now().UnixNano()),
+		TransactionDate:   payment.PostingDate,
+		TransactionType:   transactionType,
+		Amount:            amount,
+		Currency:          payment.Currency,
+		ExchangeRate:      payment.ExchangeRate,
+		Description:       description,
+		ReferenceType:     "payment",
+		ReferenceID:       &payment.ID,
+		Status:            "completed",
+	}
+
+	if err := s.paymentRepo.Submit(ctx, payment, transaction, []*models.JournalEntry{cashEntry, partyEntry}); err != nil {
+		return nil, fmt.Errorf("提交付款失败: %w", err)
+	}
+
+	return s.GetPaymentEntryDetail(ctx, payment.ID)
+}
+
+// CancelPaymentEntry 取消付款；已提交的付款冲回凭证及已核销金额
+func (s *PaymentEntryServiceImpl) CancelPaymentEntry(ctx context.Context, id uint) (*dto.PaymentEntryResponse, error) {
+	payment, err := s.paymentRepo.GetWithAllocations(ctx, id)
+	if err != nil {
+		return nil, errors.New("付款记录不存在")
+	}
+	if payment.Status == "cancelled" {
+		return nil, errors.New("付款已取消")
+	}
+	if payment.IsPosted {
+		if err := ensurePeriodOpen(ctx, s.companyRepo, payment.CompanyID, payment.PostingDate); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.paymentRepo.Cancel(ctx, payment); err != nil {
+		return nil, fmt.Errorf("取消付款失败: %w", err)
+	}
+
+	return s.GetPaymentEntryDetail(ctx, payment.ID)
+}
+
+// ListUnallocatedPayments 获取关联方尚未核销完的已提交付款（预收/预付余额）
+func (s *PaymentEntryServiceImpl) ListUnallocatedPayments(ctx context.Context, req *dto.UnallocatedPaymentsRequest) ([]dto.PaymentEntryResponse, error) {
+	payments, err := s.paymentRepo.GetUnallocated(ctx, req.CompanyID, req.PartyType, req.PartyID)
+	if err != nil {
+		return nil, fmt.Errorf("获取未核销付款失败: %w", err)
+	}
+
+	responses := make([]dto.PaymentEntryResponse, 0, len(payments))
+	for _, payment := range payments {
+		responses = append(responses, *toPaymentEntryResponse(payment))
+	}
+	return responses, nil
+}
+
+// buildAllocations 校验分配请求并生成分配明细
+func (s *PaymentEntryServiceImpl) buildAllocations(ctx context.Context, payment *models.PaymentEntry, items []dto.PaymentAllocationRequest) ([]*models.PaymentAllocation, error) {
+	var total models.Money
+	requested := make(map[string]models.Money)
+	// 尚未核销的分配已占用单据的未付金额
+	for _, existing := range payment.Allocations {
+		if existing.Status == "pending" {
+			requested[fmt.Sprintf("%s:%d", existing.ReferenceType, existing.ReferenceID)] += existing.AllocatedAmount
+		}
+	}
+	allocations := make([]*models.PaymentAllocation, 0, len(items))
+	for _, item := range items {
+		total += item.Amount
+		key := fmt.Sprintf("%s:%d", item.ReferenceType, item.ReferenceID)
+		requested[key] += item.Amount
+
+		referenceNumber, outstanding, err := s.checkAllocationReference(ctx, payment, item)
+		if err != nil {
+			return nil, err
+		}
+		if requested[key] > outstanding {
+			return nil, fmt.Errorf("单据 %s 的分配金额 %s 超过未付金额 %s", referenceNumber, requested[key], outstanding)
+		}
+
+		allocations = append(allocations, &models.PaymentAllocation{
+			ReferenceType:   item.ReferenceType,
+			ReferenceID:     item.ReferenceID,
+			ReferenceNumber: referenceNumber,
+			AllocatedAmount: item.Amount,
+			AllocationDate:  time.Now(),
+			Status:          "pending",
+		})
+	}
+
+	if available := payment.Amount() - payment.AllocatedAmount; total > available {
+		return nil, fmt.Errorf("分配金额 %s 超过付款可分配余额 %s", total, available)
+	}
+	return allocations, nil
+}
+
+// checkAllocationReference 校验分配单据属于付款的关联方与公司且币种与付款一致，返回单据编号与未付金额
+func (s *PaymentEntryServiceImpl) checkAllocationReference(ctx context.Context, payment *models.PaymentEntry, item dto.PaymentAllocationRequest) (string, models.Money, error) {
+	switch item.ReferenceType {
+	case models.AllocationRefSalesInvoice:
+		if payment.PartyType != "Customer" {
+			return "", 0, errors.New("销售发票只能由客户收款核销")
+		}
+		invoice, err := s.paymentRepo.GetSalesInvoice(ctx, item.ReferenceID)
+		if err != nil {
+			return "", 0, fmt.Errorf("销售发票 %d 不存在", item.ReferenceID)
+		}
+		if invoice.CustomerID != payment.PartyID || invoice.CompanyID != payment.CompanyID {
+			return "", 0, fmt.Errorf("销售发票 %s 不属于该客户或公司", invoice.InvoiceNumber)
+		}
+		if invoice.DocStatus != "Submitted" {
+			return "", 0, fmt.Errorf("销售发票 %s 未提交，不能核销", invoice.InvoiceNumber)
+		}
+		if invoice.Currency != payment.Currency {
+			return "", 0, fmt.Errorf("销售发票 %s 的币种 %s 与付款币种 %s 不一致，不能核销", invoice.InvoiceNumber, invoice.Currency, payment.Currency)
+		}
+		return invoice.InvoiceNumber, invoice.OutstandingAmount, nil
+
+	case models.AllocationRefPayable:
+		if payment.PartyType != "Supplier" {
+			return "", 0, errors.New("应付单据只能由供应商付款核销")
+		}
+		payable, err := s.paymentRepo.GetPayable(ctx, item.ReferenceID)
+		if err != nil {
+			return "", 0, fmt.Errorf("应付单据 %d 不存在", item.ReferenceID)
+		}
+		if payable.SupplierID != payment.PartyID || payable.CompanyID != payment.CompanyID {
+			return "", 0, fmt.Errorf("应付单据 %s 不属于该供应商或公司", payable.InvoiceNumber)
+		}
+		if payable.Currency != payment.Currency {
+			return "", 0, fmt.Errorf("应付单据 %s 的币种 %s 与付款币种 %s 不一致，不能核销", payable.InvoiceNumber, payable.Currency, payment.Currency)
+		}
+		return payable.InvoiceNumber, payable.Amount - payable.AmountPaid, nil
+	}
+	return "", 0, fmt.Errorf("不支持的分配单据类型: %s", item.ReferenceType)
+}
+
+// validatePaymentAccounts 校验付款涉及的科目与银行账户属于付款所在公司
+func (s *PaymentEntryServiceImpl) validatePaymentAccounts(ctx context.Context, payment *models.PaymentEntry) error {
+	for _, accountID := range []*uint{payment.CashAccountID, payment.PartyAccountID} {
+		if accountID == nil {
+			continue
+		}
+		account, err := s.accountRepo.GetByID(ctx, *accountID)
+		if err != nil || account == nil {
+			return fmt.Errorf("科目ID %d 不存在", *accountID)
+		}
+		if account.CompanyID != payment.CompanyID {
+			return fmt.Errorf("科目 %s 不属于付款所在公司", account.Code)
+		}
+	}
+	if payment.BankAccountID != nil {
+		bankAccount, err := s.paymentRepo.GetBankAccount(ctx, *payment.BankAccountID)
+		if err != nil {
+			return fmt.Errorf("银行账户 %d 不存在", *payment.BankAccountID)
+		}
+		if bankAccount.CompanyID != payment.CompanyID {
+			return fmt.Errorf("银行账户 %s 不属于付款所在公司", bankAccount.AccountName)
+		}
+	}
+	return nil
+}
+
+// resolveCashAccountID 确定付款的现金/银行科目：优先使用现金科目，其次使用银行账户关联的科目
+func (s *PaymentEntryServiceImpl) resolveCashAccountID(ctx context.Context, payment *models.PaymentEntry) (uint, error) {
+	if payment.CashAccountID != nil {
+		return *payment.CashAccountID, nil
+	}
+	if payment.BankAccountID != nil {
+		bankAccount, err := s.paymentRepo.GetBankAccount(ctx, *payment.BankAccountID)
+		if err != nil {
+			return 0, fmt.Errorf("银行账户 %d 不存在", *payment.BankAccountID)
+		}
+		if bankAccount.AccountID != nil {
+			return *bankAccount.AccountID, nil
+		}
+	}
+	return 0, errors.New("提交付款前必须指定现金科目或关联了会计科目的银行账户")
+}
+
+// paymentPartyType 返回付款类型对应的关联方类型
+func paymentPartyType(paymentType string) string {
+	if paymentType == models.PaymentTypeReceive {
+		return "Customer"
+	}
+	return "Supplier"
+}
+
+// toPaymentEntryResponse 转换付款响应
+func toPaymentEntryResponse(payment *models.PaymentEntry) *dto.PaymentEntryResponse {
+	response := &dto.PaymentEntryResponse{
+		ID:                payment.ID,
+		CompanyID:         payment.CompanyID,
+		PaymentNumber:     payment.PaymentNumber,
+		PaymentType:       payment.PaymentType,
+		PartyType:         payment.PartyType,
+		PartyID:           payment.PartyID,
+		PostingDate:       payment.PostingDate,
+		Amount:            payment.Amount(),
+		AllocatedAmount:   payment.AllocatedAmount,
+		UnallocatedAmount: payment.UnallocatedAmount,
+		Currency:          payment.Currency,
+		PaymentMethod:     payment.PaymentMethod,
+		BankAccountID:     payment.BankAccountID,
+		CashAccountID:     payment.CashAccountID,
+		PartyAccountID:    payment.PartyAccountID,
+		TransactionID:     payment.TransactionID,
+		Reference:         payment.Reference,
+		Remarks:           payment.Remarks,
+		Status:            payment.Status,
+		IsPosted:          payment.IsPosted,
+		PostedAt:          payment.PostedAt,
+		Allocations:       make([]dto.PaymentAllocationResponse, 0, len(payment.Allocations)),
+		CreatedAt:         payment.CreatedAt,
+		UpdatedAt:         payment.UpdatedAt,
+	}
+	for _, allocation := range payment.Allocations {
+		response.Allocations = append(response.Allocations, dto.PaymentAllocationResponse{
+			ID:              allocation.ID,
+			ReferenceType:   allocation.ReferenceType,
+			ReferenceID:     allocation.ReferenceID,
+			ReferenceNumber: allocation.ReferenceNumber,
+			AllocatedAmount: allocation.AllocatedAmount,
+			AllocationDate:  allocation.AllocationDate,
+			Status:          allocation.Status,
+		})
+	}
+	return response
+}
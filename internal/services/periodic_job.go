@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// defaultJobInterval 未配置执行间隔时定时任务每天执行一次
+const defaultJobInterval = 24 * time.Hour
+
+// PeriodicJob 定时任务，按固定间隔执行 run，启动后立即执行一次；run 返回的错误或 panic 只记录日志，不影响下一次执行
+type PeriodicJob struct {
+	name     string
+	interval time.Duration
+	run      func(context.Context) error
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewPeriodicJob 创建定时任务，name 用于日志，interval 不大于 0 时按每天执行
+func NewPeriodicJob(name string, interval time.Duration, run func(context.Context) error) *PeriodicJob {
+	if interval <= 0 {
+		interval = defaultJobInterval
+	}
+	return &PeriodicJob{
+		name:     name,
+		interval: interval,
+		run:      run,
+	}
+}
+
+// Start 启动定时任务，启动后立即执行一次
+func (j *PeriodicJob) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		j.runOnce(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				j.runOnce(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	utils.Info("定时任务已启动", utils.String("job", j.name), utils.String("interval", j.interval.String()))
+}
+
+// Stop 停止定时任务，取消正在执行任务的上下文并等待其结束
+func (j *PeriodicJob) Stop() {
+	if j.cancel != nil {
+		j.cancel()
+	}
+	j.wg.Wait()
+}
+
+// runOnce 执行一次任务并记录失败；任务 panic 时恢复并记录，避免定时任务拖垮整个服务进程
+func (j *PeriodicJob) runOnce(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.LogError("定时任务执行异常", utils.String("job", j.name), utils.Any("panic", r), utils.String("stack", string(debug.Stack())))
+		}
+	}()
+
+	if err := j.run(ctx); err != nil {
+		utils.LogError("定时任务执行失败", utils.String("job", j.name), utils.ErrorField(err))
+	}
+}
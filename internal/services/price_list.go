@@ -0,0 +1,717 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// 取价来源
+const (
+	PriceSourcePriceList     = "price_list"
+	PriceSourceCustomer      = "customer"
+	PriceSourceCustomerGroup = "customer_group"
+	PriceSourceSupplier      = "supplier"
+	PriceSourceDefault       = "default"
+	PriceSourceItem          = "item" // 没有适用的价格表价格时取物料销售价
+)
+
+// PriceListService 价格表服务接口
+type PriceListService interface {
+	CreatePriceList(ctx context.Context, req *dto.PriceListCreateRequest) (*dto.PriceListResponse, error)
+	GetPriceList(ctx context.Context, id uint) (*dto.PriceListResponse, error)
+	ListPriceLists(ctx context.Context, req *dto.PriceListListRequest) ([]dto.PriceListResponse, int64, error)
+	UpdatePriceList(ctx context.Context, id uint, req *dto.PriceListUpdateRequest) (*dto.PriceListResponse, error)
+	DeletePriceList(ctx context.Context, id uint) error
+	AddItemPrice(ctx context.Context, priceListID uint, req *dto.ItemPriceRequest) (*dto.ItemPriceResponse, error)
+	UpdateItemPrice(ctx context.Context, priceListID, id uint, req *dto.ItemPriceRequest) (*dto.ItemPriceResponse, error)
+	DeleteItemPrice(ctx context.Context, priceListID, id uint) error
+	ListItemPrices(ctx context.Context, priceListID uint, req *dto.ItemPriceListRequest) ([]dto.ItemPriceResponse, int64, error)
+	AssignPriceList(ctx context.Context, priceListID uint, req *dto.PriceListAssignmentRequest) (*dto.PriceListAssignmentResponse, error)
+	ListAssignments(ctx context.Context, priceListID uint) ([]dto.PriceListAssignmentResponse, error)
+	DeleteAssignment(ctx context.Context, priceListID, id uint) error
+	ResolvePrice(ctx context.Context, req *dto.PriceResolveRequest) (*dto.PriceResolveResult, error)
+}
+
+// PriceListServiceImpl 价格表服务实现
+type PriceListServiceImpl struct {
+	priceListRepo repositories.PriceListRepository
+	itemRepo      repositories.ItemRepository
+	uomService    UOMService
+}
+
+// NewPriceListService 创建价格表服务实例
+func NewPriceListService(priceListRepo repositories.PriceListRepository, itemRepo repositories.ItemRepository, uomService UOMService) PriceListService {
+	return &PriceListServiceImpl{
+		priceListRepo: priceListRepo,
+		itemRepo:      itemRepo,
+		uomService:    uomService,
+	}
+}
+
+// CreatePriceList 创建价格表
+func (s *PriceListServiceImpl) CreatePriceList(ctx context.Context, req *dto.PriceListCreateRequest) (*dto.PriceListResponse, error) {
+	code := strings.TrimSpace(req.Code)
+	existing, err := s.priceListRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("获取价格表失败: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("价格表编码 %s 已存在", code)
+	}
+
+	priceList := &models.PriceList{
+		Code:          code,
+		Name:          req.Name,
+		PriceListType: req.PriceListType,
+		Currency:      strings.ToUpper(req.Currency),
+		ValidFrom:     req.ValidFrom,
+		ValidTo:       req.ValidTo,
+		IsDefault:     req.IsDefault,
+		IsActive:      true,
+		Description:   req.Description,
+	}
+	if priceList.Currency == "" {
+		priceList.Currency = models.DefaultCurrency
+	}
+	if err := validateValidity(priceList.ValidFrom, priceList.ValidTo); err != nil {
+		return nil, err
+	}
+
+	if err := s.priceListRepo.SavePriceList(ctx, priceList); err != nil {
+		return nil, fmt.Errorf("创建价格表失败: %w", err)
+	}
+	utils.Info("创建价格表", utils.Uint("price_list_id", priceList.ID), utils.String("code", priceList.Code))
+	return toPriceListResponse(priceList), nil
+}
+
+// GetPriceList 获取价格表
+func (s *PriceListServiceImpl) GetPriceList(ctx context.Context, id uint) (*dto.PriceListResponse, error) {
+	priceList, err := s.getPriceList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toPriceListResponse(priceList), nil
+}
+
+// ListPriceLists 分页获取价格表
+func (s *PriceListServiceImpl) ListPriceLists(ctx context.Context, req *dto.PriceListListRequest) ([]dto.PriceListResponse, int64, error) {
+	filter := repositories.PriceListFilter{PriceListType: req.PriceListType, IsActive: req.IsActive}
+	priceLists, total, err := s.priceListRepo.ListPriceLists(ctx, filter, req.GetOffset(), req.GetLimit())
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取价格表列表失败: %w", err)
+	}
+
+	responses := make([]dto.PriceListResponse, 0, len(priceLists))
+	for _, priceList := range priceLists {
+		responses = append(responses, *toPriceListResponse(priceList))
+	}
+	return responses, total, nil
+}
+
+// UpdatePriceList 更新价格表
+func (s *PriceListServiceImpl) UpdatePriceList(ctx context.Context, id uint, req *dto.PriceListUpdateRequest) (*dto.PriceListResponse, error) {
+	priceList, err := s.getPriceList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		priceList.Name = *req.Name
+	}
+	if req.Currency != nil {
+		priceList.Currency = strings.ToUpper(*req.Currency)
+	}
+	if req.ValidFrom != nil {
+		priceList.ValidFrom = req.ValidFrom
+	}
+	if req.ValidTo != nil {
+		priceList.ValidTo = req.ValidTo
+	}
+	if req.IsDefault != nil {
+		priceList.IsDefault = *req.IsDefault
+	}
+	if req.IsActive != nil {
+		priceList.IsActive = *req.IsActive
+	}
+	if req.Description != nil {
+		priceList.Description = *req.Description
+	}
+	if err := validateValidity(priceList.ValidFrom, priceList.ValidTo); err != nil {
+		return nil, err
+	}
+
+	if err := s.priceListRepo.SavePriceList(ctx, priceList); err != nil {
+		return nil, fmt.Errorf("更新价格表失败: %w", err)
+	}
+	return toPriceListResponse(priceList), nil
+}
+
+// DeletePriceList 删除价格表及其物料价格与分配；已被单据引用的价格表只能停用
+func (s *PriceListServiceImpl) DeletePriceList(ctx context.Context, id uint) error {
+	if _, err := s.getPriceList(ctx, id); err != nil {
+		return err
+	}
+	used, err := s.priceListRepo.CountPriceListUsage(ctx, id)
+	if err != nil {
+		return fmt.Errorf("检查价格表引用失败: %w", err)
+	}
+	if used > 0 {
+		return fmt.Errorf("价格表已被 %d 张单据引用，不能删除，请改为停用", used)
+	}
+	if err := s.priceListRepo.DeletePriceList(ctx, id); err != nil {
+		return fmt.Errorf("删除价格表失败: %w", err)
+	}
+	return nil
+}
+
+// AddItemPrice 添加物料价格，同一物料、单位、起订数量与生效日期的价格不能重复
+func (s *PriceListServiceImpl) AddItemPrice(ctx context.Context, priceListID uint, req *dto.ItemPriceRequest) (*dto.ItemPriceResponse, error) {
+	if _, err := s.getPriceList(ctx, priceListID); err != nil {
+		return nil, err
+	}
+	price := &models.ItemPrice{PriceListID: priceListID}
+	if err := s.applyItemPrice(ctx, price, req); err != nil {
+		return nil, err
+	}
+	if err := s.priceListRepo.CreateItemPrice(ctx, price); err != nil {
+		return nil, fmt.Errorf("添加物料价格失败: %w", err)
+	}
+	return s.toItemPriceResponse(ctx, price), nil
+}
+
+// UpdateItemPrice 更新物料价格
+func (s *PriceListServiceImpl) UpdateItemPrice(ctx context.Context, priceListID, id uint, req *dto.ItemPriceRequest) (*dto.ItemPriceResponse, error) {
+	price, err := s.getItemPrice(ctx, priceListID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyItemPrice(ctx, price, req); err != nil {
+		return nil, err
+	}
+	if err := s.priceListRepo.SaveItemPrice(ctx, price); err != nil {
+		return nil, fmt.Errorf("更新物料价格失败: %w", err)
+	}
+	return s.toItemPriceResponse(ctx, price), nil
+}
+
+// DeleteItemPrice 删除物料价格
+func (s *PriceListServiceImpl) DeleteItemPrice(ctx context.Context, priceListID, id uint) error {
+	if _, err := s.getItemPrice(ctx, priceListID, id); err != nil {
+		return err
+	}
+	if err := s.priceListRepo.DeleteItemPrice(ctx, id); err != nil {
+		return fmt.Errorf("删除物料价格失败: %w", err)
+	}
+	return nil
+}
+
+// ListItemPrices 分页获取价格表中的物料价格
+func (s *PriceListServiceImpl) ListItemPrices(ctx context.Context, priceListID uint, req *dto.ItemPriceListRequest) ([]dto.ItemPriceResponse, int64, error) {
+	if _, err := s.getPriceList(ctx, priceListID); err != nil {
+		return nil, 0, err
+	}
+	filter := repositories.ItemPriceFilter{PriceListID: priceListID, ItemID: req.ItemID}
+	prices, total, err := s.priceListRepo.ListItemPrices(ctx, filter, req.GetOffset(), req.GetLimit())
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取物料价格失败: %w", err)
+	}
+
+	responses := make([]dto.ItemPriceResponse, 0, len(prices))
+	for _, price := range prices {
+		responses = append(responses, *s.toItemPriceResponse(ctx, price))
+	}
+	return responses, total, nil
+}
+
+// applyItemPrice 校验并写入物料价格：单位必须能换算为库存单位，价格单位为库存单位时记为空
+func (s *PriceListServiceImpl) applyItemPrice(ctx context.Context, price *models.ItemPrice, req *dto.ItemPriceRequest) error {
+	if err := validateValidity(req.ValidFrom, req.ValidTo); err != nil {
+		return err
+	}
+	conversion, err := s.uomService.ConvertToStock(ctx, req.ItemID, req.UOM, 1)
+	if err != nil {
+		return err
+	}
+	uom := conversion.UOM
+	if item, err := s.itemRepo.GetByID(ctx, req.ItemID); err == nil && item.Unit == uom {
+		uom = ""
+	}
+
+	prices, err := s.priceListRepo.GetItemPrices(ctx, price.PriceListID, req.ItemID)
+	if err != nil {
+		return fmt.Errorf("获取物料价格失败: %w", err)
+	}
+	for _, other := range prices {
+		if other.ID != price.ID && other.UOM == uom && other.MinQty == req.MinQty && sameDate(other.ValidFrom, req.ValidFrom) {
+			return fmt.Errorf("物料在该价格表中已有相同单位、起订数量与生效日期的价格（ID %d）", other.ID)
+		}
+	}
+
+	price.ItemID = req.ItemID
+	price.UOM = uom
+	price.MinQty = req.MinQty
+	price.Rate = req.Rate
+	price.ValidFrom = req.ValidFrom
+	price.ValidTo = req.ValidTo
+	return nil
+}
+
+// AssignPriceList 将价格表分配给客户、客户组或供应商；对象已分配同类型价格表时改为新价格表
+func (s *PriceListServiceImpl) AssignPriceList(ctx context.Context, priceListID uint, req *dto.PriceListAssignmentRequest) (*dto.PriceListAssignmentResponse, error) {
+	priceList, err := s.getPriceList(ctx, priceListID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment := &models.PriceListAssignment{PriceListID: priceListID, PartyType: req.PartyType}
+	switch req.PartyType {
+	case models.PriceListPartyCustomerGroup:
+		group := strings.TrimSpace(req.CustomerGroup)
+		if group == "" {
+			return nil, errors.New("请填写客户组")
+		}
+		assignment.CustomerGroup = group
+	default:
+		if req.PartyID == 0 {
+			return nil, errors.New("请填写客户或供应商ID")
+		}
+		partyID := req.PartyID
+		assignment.PartyID = &partyID
+	}
+
+	wantType := models.PriceListTypeSelling
+	if req.PartyType == models.PriceListPartySupplier {
+		wantType = models.PriceListTypeBuying
+	}
+	if priceList.PriceListType != wantType {
+		return nil, fmt.Errorf("价格表 %s 为%s价格表，不能分配给%s", priceList.Code, priceListTypeName(priceList.PriceListType), partyTypeName(req.PartyType))
+	}
+
+	partyName, err := s.partyName(ctx, assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.priceListRepo.FindAssignment(ctx, req.PartyType, req.PartyID, assignment.CustomerGroup, priceList.PriceListType)
+	if err != nil {
+		return nil, fmt.Errorf("获取价格表分配失败: %w", err)
+	}
+	if existing != nil {
+		if existing.PriceListID == priceListID {
+			return toPriceListAssignmentResponse(existing, partyName), nil
+		}
+		if err := s.priceListRepo.DeleteAssignment(ctx, existing.ID); err != nil {
+			return nil, fmt.Errorf("取消原价格表分配失败: %w", err)
+		}
+	}
+	if err := s.priceListRepo.CreateAssignment(ctx, assignment); err != nil {
+		return nil, fmt.Errorf("分配价格表失败: %w", err)
+	}
+	return toPriceListAssignmentResponse(assignment, partyName), nil
+}
+
+// ListAssignments 获取价格表的分配
+func (s *PriceListServiceImpl) ListAssignments(ctx context.Context, priceListID uint) ([]dto.PriceListAssignmentResponse, error) {
+	if _, err := s.getPriceList(ctx, priceListID); err != nil {
+		return nil, err
+	}
+	assignments, err := s.priceListRepo.ListAssignments(ctx, priceListID)
+	if err != nil {
+		return nil, fmt.Errorf("获取价格表分配失败: %w", err)
+	}
+
+	responses := make([]dto.PriceListAssignmentResponse, 0, len(assignments))
+	for _, assignment := range assignments {
+		name, err := s.partyName(ctx, assignment)
+		if err != nil {
+			name = ""
+		}
+		responses = append(responses, *toPriceListAssignmentResponse(assignment, name))
+	}
+	return responses, nil
+}
+
+// DeleteAssignment 取消价格表分配
+func (s *PriceListServiceImpl) DeleteAssignment(ctx context.Context, priceListID, id uint) error {
+	assignment, err := s.priceListRepo.GetAssignment(ctx, id)
+	if err != nil {
+		return fmt.Errorf("获取价格表分配失败: %w", err)
+	}
+	if assignment == nil || assignment.PriceListID != priceListID {
+		return errors.New("价格表分配不存在")
+	}
+	if err := s.priceListRepo.DeleteAssignment(ctx, id); err != nil {
+		return fmt.Errorf("取消价格表分配失败: %w", err)
+	}
+	return nil
+}
+
+// priceCandidate 取价时依次尝试的价格表
+type priceCandidate struct {
+	priceList *models.PriceList
+	source    string
+}
+
+// ResolvePrice 按单据日期、数量与单位取价。指定价格表时只在该价格表中取价；否则依次尝试
+// 客户分配的价格表、客户组分配的价格表（采购为供应商分配的价格表）与默认价格表，
+// 跳过已停用、不在有效期内或币种与单据不同的价格表，取第一个有该物料适用价格的价格表；
+// 销售取价都没有价格时取物料销售价
+func (s *PriceListServiceImpl) ResolvePrice(ctx context.Context, req *dto.PriceResolveRequest) (*dto.PriceResolveResult, error) {
+	item, err := s.itemRepo.GetByID(ctx, req.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("物料 %d 不存在", req.ItemID)
+	}
+	date := time.Now()
+	if req.Date != nil && !req.Date.IsZero() {
+		date = *req.Date
+	}
+	quantity := req.Quantity
+	if quantity <= 0 {
+		quantity = 1
+	}
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	conversion, err := s.uomService.ConvertToStock(ctx, item.ID, req.UOM, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.priceCandidates(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		priceList := candidate.priceList
+		if !priceList.IsValidAt(date) || !strings.EqualFold(priceList.Currency, currency) {
+			continue
+		}
+		result, err := s.pickItemPrice(ctx, priceList, item, conversion, date)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			result.Source = candidate.source
+			return result, nil
+		}
+	}
+
+	result := &dto.PriceResolveResult{UOM: conversion.UOM}
+	if req.PriceListType == models.PriceListTypeSelling && req.PriceListID == 0 && item.Price > 0 && currency == models.DefaultCurrency {
+		result.Found = true
+		result.Source = PriceSourceItem
+		result.Currency = currency
+		result.PriceUOM = item.Unit
+		result.PriceRate = item.Price
+		rate, err := item.Price.Mul(conversion.ConversionFactor)
+		if err != nil {
+			return nil, fmt.Errorf("换算单价失败: %w", err)
+		}
+		result.Rate = rate
+	}
+	return result, nil
+}
+
+// priceCandidates 返回取价时依次尝试的价格表
+func (s *PriceListServiceImpl) priceCandidates(ctx context.Context, req *dto.PriceResolveRequest) ([]priceCandidate, error) {
+	if req.PriceListID != 0 {
+		priceList, err := s.getPriceList(ctx, req.PriceListID)
+		if err != nil {
+			return nil, err
+		}
+		if priceList.PriceListType != req.PriceListType {
+			return nil, fmt.Errorf("价格表 %s 不是%s价格表", priceList.Code, priceListTypeName(req.PriceListType))
+		}
+		return []priceCandidate{{priceList: priceList, source: PriceSourcePriceList}}, nil
+	}
+
+	var candidates []priceCandidate
+	add := func(assignment *models.PriceListAssignment, source string) {
+		if assignment != nil && assignment.PriceList != nil {
+			candidates = append(candidates, priceCandidate{priceList: assignment.PriceList, source: source})
+		}
+	}
+	switch req.PriceListType {
+	case models.PriceListTypeSelling:
+		if req.CustomerID != 0 {
+			customer, err := s.priceListRepo.GetCustomer(ctx, req.CustomerID)
+			if err != nil {
+				return nil, fmt.Errorf("获取客户失败: %w", err)
+			}
+			if customer == nil {
+				return nil, fmt.Errorf("客户 %d 不存在", req.CustomerID)
+			}
+			assignment, err := s.priceListRepo.FindAssignment(ctx, models.PriceListPartyCustomer, customer.ID, "", models.PriceListTypeSelling)
+			if err != nil {
+				return nil, fmt.Errorf("获取价格表分配失败: %w", err)
+			}
+			add(assignment, PriceSourceCustomer)
+			if customer.CustomerGroup != "" {
+				assignment, err = s.priceListRepo.FindAssignment(ctx, models.PriceListPartyCustomerGroup, 0, customer.CustomerGroup, models.PriceListTypeSelling)
+				if err != nil {
+					return nil, fmt.Errorf("获取价格表分配失败: %w", err)
+				}
+				add(assignment, PriceSourceCustomerGroup)
+			}
+		}
+	case models.PriceListTypeBuying:
+		if req.SupplierID != 0 {
+			assignment, err := s.priceListRepo.FindAssignment(ctx, models.PriceListPartySupplier, req.SupplierID, "", models.PriceListTypeBuying)
+			if err != nil {
+				return nil, fmt.Errorf("获取价格表分配失败: %w", err)
+			}
+			add(assignment, PriceSourceSupplier)
+		}
+	}
+
+	defaultList, err := s.priceListRepo.GetDefaultPriceList(ctx, req.PriceListType)
+	if err != nil {
+		return nil, fmt.Errorf("获取默认价格表失败: %w", err)
+	}
+	if defaultList != nil {
+		candidates = append(candidates, priceCandidate{priceList: defaultList, source: PriceSourceDefault})
+	}
+	return candidates, nil
+}
+
+// pickItemPrice 在价格表中选取适用的物料价格：优先取与请求单位相同的价格，选满足数量且起订数量最大的一条；
+// 没有同单位价格时按其他单位的价格换算，起订数量换算为库存单位后比较。没有适用价格时返回 nil
+func (s *PriceListServiceImpl) pickItemPrice(ctx context.Context, priceList *models.PriceList, item *models.Item, conversion *dto.UOMConversionResult, date time.Time) (*dto.PriceResolveResult, error) {
+	prices, err := s.priceListRepo.GetItemPrices(ctx, priceList.ID, item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料价格失败: %w", err)
+	}
+
+	var best *models.ItemPrice
+	var bestFactor, bestThreshold float64
+	for _, price := range prices {
+		if !price.IsValidAt(date) {
+			continue
+		}
+		priceUOM := price.UOM
+		if priceUOM == "" {
+			priceUOM = item.Unit
+		}
+		if priceUOM == conversion.UOM {
+			if price.MinQty <= conversion.Quantity+stockQuantityTolerance {
+				// 价格按起订数量降序排列，第一条满足数量的即为最优阶梯
+				best, bestFactor = price, conversion.ConversionFactor
+				break
+			}
+			continue
+		}
+
+		priceConversion, err := s.uomService.ConvertToStock(ctx, item.ID, price.UOM, 1)
+		if err != nil {
+			continue
+		}
+		threshold := price.MinQty * priceConversion.ConversionFactor
+		if threshold > conversion.StockQuantity+stockQuantityTolerance {
+			continue
+		}
+		if best == nil || threshold > bestThreshold {
+			best, bestFactor, bestThreshold = price, priceConversion.ConversionFactor, threshold
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	result := &dto.PriceResolveResult{
+		Found:         true,
+		PriceListID:   priceList.ID,
+		PriceListCode: priceList.Code,
+		Currency:      priceList.Currency,
+		ItemPriceID:   best.ID,
+		UOM:           conversion.UOM,
+		PriceUOM:      best.UOM,
+		PriceRate:     best.Rate,
+		MinQty:        best.MinQty,
+		Rate:          best.Rate,
+	}
+	if result.PriceUOM == "" {
+		result.PriceUOM = item.Unit
+	}
+	if result.PriceUOM != conversion.UOM && bestFactor > 0 {
+		rate, err := best.Rate.Mul(conversion.ConversionFactor / bestFactor)
+		if err != nil {
+			return nil, fmt.Errorf("换算单价失败: %w", err)
+		}
+		result.Rate = rate
+	}
+	return result, nil
+}
+
+// partyName 返回分配对象的名称，客户与供应商不存在时返回错误
+func (s *PriceListServiceImpl) partyName(ctx context.Context, assignment *models.PriceListAssignment) (string, error) {
+	switch assignment.PartyType {
+	case models.PriceListPartyCustomer:
+		customer, err := s.priceListRepo.GetCustomer(ctx, *assignment.PartyID)
+		if err != nil {
+			return "", fmt.Errorf("获取客户失败: %w", err)
+		}
+		if customer == nil {
+			return "", fmt.Errorf("客户 %d 不存在", *assignment.PartyID)
+		}
+		return customer.Name, nil
+	case models.PriceListPartySupplier:
+		supplier, err := s.priceListRepo.GetSupplier(ctx, *assignment.PartyID)
+		if err != nil {
+			return "", fmt.Errorf("获取供应商失败: %w", err)
+		}
+		if supplier == nil {
+			return "", fmt.Errorf("供应商 %d 不存在", *assignment.PartyID)
+		}
+		return supplier.Name, nil
+	default:
+		return assignment.CustomerGroup, nil
+	}
+}
+
+// getPriceList 获取价格表，不存在时返回错误
+func (s *PriceListServiceImpl) getPriceList(ctx context.Context, id uint) (*models.PriceList, error) {
+	priceList, err := s.priceListRepo.GetPriceList(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取价格表失败: %w", err)
+	}
+	if priceList == nil {
+		return nil, errors.New("价格表不存在")
+	}
+	return priceList, nil
+}
+
+// getItemPrice 获取价格表中的物料价格，不存在或不属于该价格表时返回错误
+func (s *PriceListServiceImpl) getItemPrice(ctx context.Context, priceListID, id uint) (*models.ItemPrice, error) {
+	price, err := s.priceListRepo.GetItemPrice(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料价格失败: %w", err)
+	}
+	if price == nil || price.PriceListID != priceListID {
+		return nil, errors.New("物料价格不存在")
+	}
+	return price, nil
+}
+
+// toItemPriceResponse 转换物料价格响应
+func (s *PriceListServiceImpl) toItemPriceResponse(ctx context.Context, price *models.ItemPrice) *dto.ItemPriceResponse {
+	response := &dto.ItemPriceResponse{
+		ID:          price.ID,
+		PriceListID: price.PriceListID,
+		ItemID:      price.ItemID,
+		UOM:         price.UOM,
+		MinQty:      price.MinQty,
+		Rate:        price.Rate,
+		ValidFrom:   price.ValidFrom,
+		ValidTo:     price.ValidTo,
+		UpdatedAt:   price.UpdatedAt,
+	}
+	item := price.Item
+	if item == nil {
+		item, _ = s.itemRepo.GetByID(ctx, price.ItemID)
+	}
+	if item != nil {
+		response.ItemCode = item.Code
+		response.ItemName = item.Name
+		if response.UOM == "" {
+			response.UOM = item.Unit
+		}
+	}
+	return response
+}
+
+// validateValidity 校验有效期的起止日期
+func validateValidity(from, to *time.Time) error {
+	if from != nil && to != nil && to.Before(*from) {
+		return errors.New("有效期截止日期不能早于开始日期")
+	}
+	return nil
+}
+
+// sameDate 两个可为空的日期是否为同一天
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return models.StartOfDay(*a).Equal(models.StartOfDay(*b))
+}
+
+// priceListTypeName 返回价格表类型的中文名称
+func priceListTypeName(priceListType string) string {
+	if priceListType == models.PriceListTypeBuying {
+		return "采购"
+	}
+	return "销售"
+}
+
+// partyTypeName 返回分配对象类型的中文名称
+func partyTypeName(partyType string) string {
+	switch partyType {
+	case models.PriceListPartyCustomerGroup:
+		return "客户组"
+	case models.PriceListPartySupplier:
+		return "供应商"
+	default:
+		return "客户"
+	}
+}
+
+// toPriceListResponse 转换价格表响应
+func toPriceListResponse(priceList *models.PriceList) *dto.PriceListResponse {
+	return &dto.PriceListResponse{
+		ID:            priceList.ID,
+		Code:          priceList.Code,
+		Name:          priceList.Name,
+		PriceListType: priceList.PriceListType,
+		Currency:      priceList.Currency,
+		ValidFrom:     priceList.ValidFrom,
+		ValidTo:       priceList.ValidTo,
+		IsDefault:     priceList.IsDefault,
+		IsActive:      priceList.IsActive,
+		Description:   priceList.Description,
+		CreatedAt:     priceList.CreatedAt,
+		UpdatedAt:     priceList.UpdatedAt,
+	}
+}
+
+// toPriceListAssignmentResponse 转换价格表分配响应
+func toPriceListAssignmentResponse(assignment *models.PriceListAssignment, partyName string) *dto.PriceListAssignmentResponse {
+	return &dto.PriceListAssignmentResponse{
+		ID:            assignment.ID,
+		PriceListID:   assignment.PriceListID,
+		PartyType:     assignment.PartyType,
+		PartyID:       assignment.PartyID,
+		PartyName:     partyName,
+		CustomerGroup: assignment.CustomerGroup,
+		CreatedAt:     assignment.CreatedAt,
+	}
+}
+
+// resolveLineRate 为单据明细取价。填写了单价时按填写的单价成交，并记录价格表价格供比对；
+// 未填写单价时按价格表价格成交，取不到价格时返回错误。返回成交单价、价格表价格与取价的价格表
+func resolveLineRate(ctx context.Context, priceLists PriceListService, req *dto.PriceResolveRequest, unitPrice models.Money) (models.Money, models.Money, *uint, error) {
+	result, err := priceLists.ResolvePrice(ctx, req)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	var priceListID *uint
+	if result.Found && result.PriceListID != 0 {
+		id := result.PriceListID
+		priceListID = &id
+	}
+	if unitPrice > 0 {
+		return unitPrice, result.Rate, priceListID, nil
+	}
+	if !result.Found || result.Rate <= 0 {
+		return 0, 0, nil, fmt.Errorf("物料 %d 没有适用的价格，请填写单价", req.ItemID)
+	}
+	return result.Rate, result.Rate, priceListID, nil
+}
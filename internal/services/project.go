@@ -645,7 +645,10 @@ func NewTimeEntryService(timeEntryRepo repositories.TimeEntryRepository) TimeEnt
 func (s *TimeEntryServiceImpl) CreateTimeEntry(ctx context.Context, req *dto.TimeEntryCreateRequest) (*dto.TimeEntryResponse, error) {
 	// 计算工时
 	hours := req.EndTime.Sub(req.StartTime).Hours()
-	amount := hours * req.HourlyRate
+	amount, err := req.HourlyRate.Mul(hours)
+	if err != nil {
+		return nil, fmt.Errorf("计算工时金额失败: %w", err)
+	}
 
 	timeEntry := &models.TimeEntry{
 		ProjectID:   req.ProjectID,
@@ -712,7 +715,11 @@ func (s *TimeEntryServiceImpl) UpdateTimeEntry(ctx context.Context, id uint, req
 
 	// 重新计算工时和金额
 	timeEntry.Hours = timeEntry.EndTime.Sub(timeEntry.StartTime).Hours()
-	timeEntry.Amount = timeEntry.Hours * timeEntry.HourlyRate
+	amount, err := timeEntry.HourlyRate.Mul(timeEntry.Hours)
+	if err != nil {
+		return nil, fmt.Errorf("计算工时金额失败: %w", err)
+	}
+	timeEntry.Amount = amount
 
 	if err := s.timeEntryRepo.Update(ctx, timeEntry); err != nil {
 		return nil, err
@@ -35,13 +35,14 @@ func NewSupplierService(supplierRepo repositories.SupplierRepository) SupplierSe
 // CreateSupplier 创建供应商
 func (s *SupplierServiceImpl) CreateSupplier(ctx context.Context, req *dto.SupplierCreateRequest) (*dto.SupplierResponse, error) {
 	supplier := &models.Supplier{
-		Code:          req.Code,
-		Name:          req.Name,
-		Email:         req.Email,
-		Phone:         req.Phone,
-		Address:       req.Address,
-		ContactPerson: req.ContactName,
-		CreditLimit:   req.CreditLimit,
+		RepresentsCompanyID: req.RepresentsCompanyID,
+		Code:                req.Code,
+		Name:                req.Name,
+		Email:               req.Email,
+		Phone:               req.Phone,
+		Address:             req.Address,
+		ContactPerson:       req.ContactName,
+		CreditLimit:         req.CreditLimit,
 	}
 
 	if err := s.supplierRepo.Create(ctx, supplier); err != nil {
@@ -87,6 +88,9 @@ func (s *SupplierServiceImpl) UpdateSupplier(ctx context.Context, id uint, req *
 	if req.CreditLimit != nil && *req.CreditLimit > 0 {
 		supplier.CreditLimit = *req.CreditLimit
 	}
+	if req.RepresentsCompanyID != nil {
+		supplier.RepresentsCompanyID = req.RepresentsCompanyID
+	}
 
 	if err := s.supplierRepo.Update(ctx, supplier); err != nil {
 		return nil, fmt.Errorf("更新供应商失败: %w", err)
@@ -152,14 +156,15 @@ func (s *SupplierServiceImpl) convertToSupplierResponse(supplier *models.Supplie
 			CreatedAt: supplier.CreatedAt,
 			UpdatedAt: supplier.UpdatedAt,
 		},
-		Code:        supplier.Code,
-		Name:        supplier.Name,
-		Email:       supplier.Email,
-		Phone:       supplier.Phone,
-		Address:     supplier.Address,
-		ContactName: supplier.ContactPerson,
-		CreditLimit: supplier.CreditLimit,
-		IsActive:    true, // 默认为活跃状态
+		RepresentsCompanyID: supplier.RepresentsCompanyID,
+		Code:                supplier.Code,
+		Name:                supplier.Name,
+		Email:               supplier.Email,
+		Phone:               supplier.Phone,
+		Address:             supplier.Address,
+		ContactName:         supplier.ContactPerson,
+		CreditLimit:         supplier.CreditLimit,
+		IsActive:            true, // 默认为活跃状态
 	}
 }
 
@@ -178,12 +183,14 @@ type PurchaseRequestService interface {
 // PurchaseRequestServiceImpl 采购申请服务实现
 type PurchaseRequestServiceImpl struct {
 	purchaseRequestRepo repositories.PurchaseRequestRepository
+	uomService          UOMService
 }
 
 // NewPurchaseRequestService 创建采购申请服务实例
-func NewPurchaseRequestService(purchaseRequestRepo repositories.PurchaseRequestRepository) PurchaseRequestService {
+func NewPurchaseRequestService(purchaseRequestRepo repositories.PurchaseRequestRepository, uomService UOMService) PurchaseRequestService {
 	return &PurchaseRequestServiceImpl{
 		purchaseRequestRepo: purchaseRequestRepo,
+		uomService:          uomService,
 	}
 }
 
@@ -195,16 +202,25 @@ func (s *PurchaseRequestServiceImpl) CreatePurchaseRequest(ctx context.Context,
 	// 创建采购申请明细
 	var items []models.PurchaseRequestItem
 	for _, itemReq := range req.Items {
+		// 申请单位必须已定义且可换算为库存单位，补货按换算后的库存数量计算已申请量
+		conversion, err := s.uomService.ConvertToStock(ctx, itemReq.ItemID, itemReq.UOM, itemReq.Quantity)
+		if err != nil {
+			return nil, err
+		}
 		item := models.PurchaseRequestItem{
-			ItemID:        itemReq.ItemID,
-			Quantity:      itemReq.Quantity,
-			EstimatedCost: itemReq.UnitPrice,
-			Notes:         itemReq.Notes,
+			ItemID:           itemReq.ItemID,
+			Quantity:         conversion.Quantity,
+			UOM:              conversion.UOM,
+			ConversionFactor: conversion.ConversionFactor,
+			EstimatedCost:    itemReq.UnitPrice,
+			WarehouseID:      itemReq.WarehouseID,
+			Notes:            itemReq.Notes,
 		}
 		items = append(items, item)
 	}
 
 	purchaseRequest := &models.PurchaseRequest{
+		CompanyID:     req.CompanyID,
 		RequestNumber: requestNumber,
 		Title:         req.Title,
 		Description:   req.Description,
@@ -212,6 +228,8 @@ func (s *PurchaseRequestServiceImpl) CreatePurchaseRequest(ctx context.Context,
 		RequestDate:   time.Now(),
 		RequiredBy:    req.RequiredDate,
 		Status:        "draft",
+		Source:        models.PurchaseRequestSourceManual,
+		SupplierID:    req.SupplierID,
 		Items:         items, // GORM 会自动创建关联的明细项
 	}
 
@@ -395,17 +413,25 @@ func (s *PurchaseRequestServiceImpl) RejectPurchaseRequest(ctx context.Context,
 func (s *PurchaseRequestServiceImpl) convertToPurchaseRequestResponse(purchaseRequest *models.PurchaseRequest) *dto.PurchaseRequestResponse {
 	// 转换采购申请项目
 	var items []dto.PurchaseRequestItemResponse
-	var totalAmount float64
+	var totalAmount models.Money
 	for _, item := range purchaseRequest.Items {
-		amount := item.Quantity * item.EstimatedCost
+		// 估算金额仅用于展示，超出金额范围时按 0 计
+		amount, err := item.EstimatedCost.Mul(item.Quantity)
+		if err != nil {
+			amount = 0
+		}
+		amount = amount.RoundCurrency(models.DefaultCurrency)
 		totalAmount += amount
 
 		itemResponse := dto.PurchaseRequestItemResponse{
-			ID:        item.ID,
-			Quantity:  item.Quantity,
-			UnitPrice: item.EstimatedCost,
-			Amount:    amount,
-			Notes:     item.Notes,
+			ID:               item.ID,
+			Quantity:         item.Quantity,
+			UOM:              item.UOM,
+			ConversionFactor: item.ConversionFactor,
+			UnitPrice:        item.EstimatedCost,
+			Amount:           amount,
+			WarehouseID:      item.WarehouseID,
+			Notes:            item.Notes,
 			Item: dto.ItemResponse{
 				ID:          item.ItemID,
 				Name:        item.Description, // 使用描述作为名称
@@ -417,11 +443,14 @@ func (s *PurchaseRequestServiceImpl) convertToPurchaseRequestResponse(purchaseRe
 
 	return &dto.PurchaseRequestResponse{
 		ID:           purchaseRequest.ID,
+		CompanyID:    purchaseRequest.CompanyID,
 		Number:       purchaseRequest.RequestNumber,
 		Title:        purchaseRequest.Title,
 		Description:  purchaseRequest.Description,
 		Priority:     purchaseRequest.Priority,
 		Status:       purchaseRequest.Status,
+		Source:       purchaseRequest.Source,
+		SupplierID:   purchaseRequest.SupplierID,
 		Department:   purchaseRequest.Department,
 		RequiredDate: purchaseRequest.RequiredBy,
 		TotalAmount:  totalAmount,
@@ -451,12 +480,14 @@ type PurchaseOrderService interface {
 // PurchaseOrderServiceImpl 采购订单服务实现
 type PurchaseOrderServiceImpl struct {
 	purchaseOrderRepo repositories.PurchaseOrderRepository
+	priceListService  PriceListService
 }
 
 // NewPurchaseOrderService 创建采购订单服务实例
-func NewPurchaseOrderService(purchaseOrderRepo repositories.PurchaseOrderRepository) PurchaseOrderService {
+func NewPurchaseOrderService(purchaseOrderRepo repositories.PurchaseOrderRepository, priceListService PriceListService) PurchaseOrderService {
 	return &PurchaseOrderServiceImpl{
 		purchaseOrderRepo: purchaseOrderRepo,
+		priceListService:  priceListService,
 	}
 }
 
@@ -472,33 +503,56 @@ func (s *PurchaseOrderServiceImpl) CreatePurchaseOrder(ctx context.Context, req
 	}
 
 	// 计算总金额
-	var totalAmount float64
+	var totalAmount models.Money
 	var items []models.PurchaseOrderItem
+	priceListID := req.PriceListID
 
 	for _, itemReq := range req.Items {
-		amount := itemReq.Quantity * itemReq.UnitPrice
-		taxAmount := amount * itemReq.TaxRate / 100
+		// 按采购价格表取价，未填写单价时以价格表价格成交
+		rate, priceListRate, lineListID, err := resolveLineRate(ctx, s.priceListService, &dto.PriceResolveRequest{
+			PriceListType: models.PriceListTypeBuying,
+			ItemID:        itemReq.ItemID,
+			SupplierID:    req.SupplierID,
+			PriceListID:   derefUint(req.PriceListID),
+			Quantity:      itemReq.Quantity,
+			Date:          &req.OrderDate,
+		}, itemReq.UnitPrice)
+		if err != nil {
+			return nil, err
+		}
+		if priceListID == nil {
+			priceListID = lineListID
+		}
+
+		totals, err := calculateLineTotals(rate, itemReq.Quantity, 0, itemReq.TaxRate, models.DefaultCurrency)
+		if err != nil {
+			return nil, err
+		}
+		amount, taxAmount := totals.amount, totals.tax
 		totalAmount += amount + taxAmount
 
 		item := models.PurchaseOrderItem{
-			ItemID:      itemReq.ItemID,
-			Description: itemReq.Notes,
-			Quantity:    itemReq.Quantity,
-			Rate:        itemReq.UnitPrice,
-			Amount:      amount,
-			TaxRate:     itemReq.TaxRate,
-			TaxAmount:   taxAmount,
-			TotalAmount: amount + taxAmount,
+			ItemID:        itemReq.ItemID,
+			Description:   itemReq.Notes,
+			Quantity:      itemReq.Quantity,
+			PriceListRate: priceListRate,
+			Rate:          rate,
+			Amount:        amount,
+			TaxRate:       itemReq.TaxRate,
+			TaxAmount:     taxAmount,
+			TotalAmount:   amount + taxAmount,
 		}
 		items = append(items, item)
 	}
 
 	purchaseOrder := &models.PurchaseOrder{
+		CompanyID:    req.CompanyID,
 		OrderNumber:  orderNumber,
 		SupplierID:   req.SupplierID,
 		OrderDate:    req.OrderDate,
 		DeliveryDate: deliveryDate,
 		Status:       "draft",
+		PriceListID:  priceListID,
 		Terms:        req.PaymentTerms,
 		TotalAmount:  totalAmount,
 		GrandTotal:   totalAmount,
@@ -661,15 +715,16 @@ func (s *PurchaseOrderServiceImpl) convertToPurchaseOrderResponse(purchaseOrder
 	items := make([]dto.PurchaseOrderItemResponse, len(purchaseOrder.Items))
 	for i, item := range purchaseOrder.Items {
 		items[i] = dto.PurchaseOrderItemResponse{
-			ID:          item.ID,
-			Quantity:    item.Quantity,
-			UnitPrice:   item.Rate,
-			TaxRate:     item.TaxRate,
-			TaxAmount:   item.TaxAmount,
-			Amount:      item.Amount,
-			ReceivedQty: item.ReceivedQty,
-			Notes:       item.Description,
-			Item:        s.convertToItemResponse(&item.Item),
+			ID:            item.ID,
+			Quantity:      item.Quantity,
+			PriceListRate: item.PriceListRate,
+			UnitPrice:     item.Rate,
+			TaxRate:       item.TaxRate,
+			TaxAmount:     item.TaxAmount,
+			Amount:        item.Amount,
+			ReceivedQty:   item.ReceivedQty,
+			Notes:         item.Description,
+			Item:          s.convertToItemResponse(&item.Item),
 		}
 	}
 
@@ -699,6 +754,7 @@ func (s *PurchaseOrderServiceImpl) convertToPurchaseOrderResponse(purchaseOrder
 			CreatedAt: purchaseOrder.CreatedAt,
 			UpdatedAt: purchaseOrder.UpdatedAt,
 		},
+		CompanyID:     purchaseOrder.CompanyID,
 		OrderNumber:   purchaseOrder.OrderNumber,
 		SupplierID:    purchaseOrder.SupplierID,
 		OrderDate:     purchaseOrder.OrderDate,
@@ -710,6 +766,7 @@ func (s *PurchaseOrderServiceImpl) convertToPurchaseOrderResponse(purchaseOrder
 		PaymentTerms:  purchaseOrder.Terms, // 使用Terms作为PaymentTerms
 		Terms:         purchaseOrder.Terms,
 		Notes:         purchaseOrder.Notes,
+		PriceListID:   purchaseOrder.PriceListID,
 		SubTotal:      purchaseOrder.TotalAmount,
 		TotalDiscount: purchaseOrder.DiscountAmount,
 		TotalTax:      purchaseOrder.TaxAmount,
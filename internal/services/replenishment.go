@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// defaultReplenishmentLeadDays 补货申请默认需求日期距今天数
+const defaultReplenishmentLeadDays = 7
+
+// ReplenishmentService 再订货点补货服务接口
+type ReplenishmentService interface {
+	PreviewReplenishment(ctx context.Context, req *dto.ReplenishmentRequest) (*dto.ReplenishmentPreviewResponse, error)
+	RunReplenishment(ctx context.Context, req *dto.ReplenishmentRequest) (*dto.ReplenishmentRunResponse, error)
+}
+
+// ReplenishmentServiceImpl 补货服务实现
+type ReplenishmentServiceImpl struct {
+	replenishmentRepo repositories.ReplenishmentRepository
+}
+
+// NewReplenishmentService 创建补货服务实例
+func NewReplenishmentService(replenishmentRepo repositories.ReplenishmentRepository) ReplenishmentService {
+	return &ReplenishmentServiceImpl{
+		replenishmentRepo: replenishmentRepo,
+	}
+}
+
+// replenishmentKey 物料与仓库
+type replenishmentKey struct {
+	itemID      uint
+	warehouseID uint
+}
+
+// PreviewReplenishment 计算补货建议：预计库存 = 现有库存 + 采购在途 + 未转单的采购申请 − 销售订单占用，
+// 预计库存不高于再订货点时按最高库存或补货数量给出建议数量，并按首选供应商分组
+func (s *ReplenishmentServiceImpl) PreviewReplenishment(ctx context.Context, req *dto.ReplenishmentRequest) (*dto.ReplenishmentPreviewResponse, error) {
+	suggestions, err := s.calculate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ReplenishmentPreviewResponse{
+		Groups:     groupReplenishmentSuggestions(suggestions),
+		TotalLines: len(suggestions),
+	}
+	for _, group := range response.Groups {
+		response.EstimatedAmount += group.EstimatedAmount
+	}
+	return response, nil
+}
+
+// RunReplenishment 按补货建议为每个首选供应商生成一张草稿采购申请
+func (s *ReplenishmentServiceImpl) RunReplenishment(ctx context.Context, req *dto.ReplenishmentRequest) (*dto.ReplenishmentRunResponse, error) {
+	suggestions, err := s.calculate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	groups := groupReplenishmentSuggestions(suggestions)
+
+	response := &dto.ReplenishmentRunResponse{
+		PurchaseRequests: make([]dto.ReplenishmentRequestSummary, 0, len(groups)),
+		TotalLines:       len(suggestions),
+	}
+	if len(groups) == 0 {
+		return response, nil
+	}
+
+	now := time.Now()
+	requiredBy := now.AddDate(0, 0, defaultReplenishmentLeadDays)
+	if req.RequiredBy != nil {
+		requiredBy = *req.RequiredBy
+	}
+	priority := req.Priority
+	if priority == "" {
+		priority = "medium"
+	}
+
+	requests := make([]*models.PurchaseRequest, 0, len(groups))
+	for i, group := range groups {
+		supplierName := group.SupplierName
+		if supplierName == "" {
+			supplierName = "未指定供应商"
+		}
+		request := &models.PurchaseRequest{
+			RequestNumber: fmt.Sprintf("PR%s%06d", now.Format("20060102"), (now.UnixNano()/1000+int64(i))%1000000),
+			Title:         fmt.Sprintf("补货申请 - %s", supplierName),
+			Description:   "按再订货点自动生成",
+			Priority:      priority,
+			RequestDate:   now,
+			RequiredBy:    requiredBy,
+			Status:        "draft",
+			Source:        models.PurchaseRequestSourceReplenishment,
+			SupplierID:    group.SupplierID,
+		}
+		for _, line := range group.Lines {
+			warehouseID := line.WarehouseID
+			request.Items = append(request.Items, models.PurchaseRequestItem{
+				ItemID:           line.ItemID,
+				Description:      line.ItemName,
+				Quantity:         line.SuggestedQty,
+				UOM:              line.Unit,
+				ConversionFactor: 1,
+				EstimatedCost:    line.EstimatedCost,
+				WarehouseID:      &warehouseID,
+				Notes:            fmt.Sprintf("仓库 %s 预计库存 %.2f，再订货点 %.2f", line.WarehouseCode, line.ProjectedQty, line.ReorderLevel),
+			})
+		}
+		requests = append(requests, request)
+	}
+
+	if err := s.replenishmentRepo.CreatePurchaseRequests(ctx, requests); err != nil {
+		return nil, fmt.Errorf("创建补货采购申请失败: %w", err)
+	}
+
+	for i, request := range requests {
+		response.PurchaseRequests = append(response.PurchaseRequests, dto.ReplenishmentRequestSummary{
+			ID:              request.ID,
+			RequestNumber:   request.RequestNumber,
+			SupplierID:      request.SupplierID,
+			SupplierName:    groups[i].SupplierName,
+			LineCount:       len(request.Items),
+			EstimatedAmount: groups[i].EstimatedAmount,
+		})
+	}
+	return response, nil
+}
+
+// calculate 计算各物料、仓库的补货建议
+func (s *ReplenishmentServiceImpl) calculate(ctx context.Context, req *dto.ReplenishmentRequest) ([]dto.ReplenishmentSuggestion, error) {
+	items, err := s.replenishmentRepo.GetItems(ctx, repositories.ReplenishmentFilter{
+		ItemID:     req.ItemID,
+		SupplierID: req.SupplierID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取补货物料失败: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	warehouses, err := s.replenishmentRepo.GetWarehouses(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("获取仓库失败: %w", err)
+	}
+	if req.WarehouseID != 0 && len(warehouses) == 0 {
+		return nil, fmt.Errorf("仓库 %d 不存在或未启用", req.WarehouseID)
+	}
+
+	itemIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ItemID)
+	}
+
+	// 未指定仓库时只评估有库存、采购或销售需求记录的物料与仓库
+	pairs := make(map[replenishmentKey]bool)
+	load := func(name string, fetch func(context.Context, []uint, uint) ([]repositories.ReplenishmentQuantityRow, error)) (map[replenishmentKey]float64, error) {
+		rows, err := fetch(ctx, itemIDs, req.WarehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("获取%s失败: %w", name, err)
+		}
+		quantities := make(map[replenishmentKey]float64, len(rows))
+		for _, row := range rows {
+			key := replenishmentKey{itemID: row.ItemID, warehouseID: row.WarehouseID}
+			quantities[key] += row.Quantity
+			pairs[key] = true
+		}
+		return quantities, nil
+	}
+	onHand, err := load("现有库存", s.replenishmentRepo.GetOnHand)
+	if err != nil {
+		return nil, err
+	}
+	onOrder, err := load("采购在途数量", s.replenishmentRepo.GetOnOrder)
+	if err != nil {
+		return nil, err
+	}
+	requested, err := load("采购申请数量", s.replenishmentRepo.GetRequested)
+	if err != nil {
+		return nil, err
+	}
+	reserved, err := load("销售订单预留数量", s.replenishmentRepo.GetReserved)
+	if err != nil {
+		return nil, err
+	}
+
+	var suggestions []dto.ReplenishmentSuggestion
+	for _, item := range items {
+		for _, warehouse := range warehouses {
+			key := replenishmentKey{itemID: item.ItemID, warehouseID: warehouse.ID}
+			if req.WarehouseID == 0 && !pairs[key] {
+				continue
+			}
+
+			projected := onHand[key] + onOrder[key] + requested[key] - reserved[key]
+			reorderLevel := float64(item.ReorderLevel)
+			if projected > reorderLevel+stockQuantityTolerance {
+				continue
+			}
+			quantity := replenishmentQuantity(projected, reorderLevel, item.ReorderQty, item.MaxLevel)
+			if quantity <= stockQuantityTolerance {
+				continue
+			}
+			estimatedAmount, err := item.Cost.Mul(quantity)
+			if err != nil {
+				return nil, fmt.Errorf("计算物料 %s 的补货估算金额失败: %w", item.ItemCode, err)
+			}
+
+			suggestions = append(suggestions, dto.ReplenishmentSuggestion{
+				ItemID:          item.ItemID,
+				ItemCode:        item.ItemCode,
+				ItemName:        item.ItemName,
+				Unit:            item.Unit,
+				WarehouseID:     warehouse.ID,
+				WarehouseCode:   warehouse.Code,
+				OnHandQty:       onHand[key],
+				OnOrderQty:      onOrder[key],
+				RequestedQty:    requested[key],
+				ReservedQty:     reserved[key],
+				ProjectedQty:    projected,
+				ReorderLevel:    reorderLevel,
+				ReorderQty:      item.ReorderQty,
+				MaxLevel:        item.MaxLevel,
+				SuggestedQty:    quantity,
+				SupplierID:      item.PreferredSupplierID,
+				SupplierName:    item.SupplierName,
+				EstimatedCost:   item.Cost,
+				EstimatedAmount: estimatedAmount.RoundCurrency(models.DefaultCurrency),
+			})
+		}
+	}
+	return suggestions, nil
+}
+
+// replenishmentQuantity 计算建议补货数量：设置最高库存时补至最高库存；设置补货数量时按其整数倍补至再订货点以上；
+// 均未设置时补至再订货点
+func replenishmentQuantity(projected, reorderLevel, reorderQty, maxLevel float64) float64 {
+	shortage := reorderLevel - projected
+	switch {
+	case maxLevel > reorderLevel:
+		return maxLevel - projected
+	case reorderQty > 0:
+		return (math.Floor(shortage/reorderQty) + 1) * reorderQty
+	default:
+		return shortage
+	}
+}
+
+// groupReplenishmentSuggestions 按首选供应商分组，未指定供应商的建议排在最后
+func groupReplenishmentSuggestions(suggestions []dto.ReplenishmentSuggestion) []dto.ReplenishmentGroup {
+	index := make(map[uint]int)
+	groups := make([]dto.ReplenishmentGroup, 0)
+	for _, suggestion := range suggestions {
+		var supplierID uint
+		if suggestion.SupplierID != nil {
+			supplierID = *suggestion.SupplierID
+		}
+		i, ok := index[supplierID]
+		if !ok {
+			i = len(groups)
+			index[supplierID] = i
+			groups = append(groups, dto.ReplenishmentGroup{
+				SupplierID:   suggestion.SupplierID,
+				SupplierName: suggestion.SupplierName,
+			})
+		}
+		groups[i].Lines = append(groups[i].Lines, suggestion)
+		groups[i].EstimatedAmount += suggestion.EstimatedAmount
+	}
+
+	sort.SliceStable(groups, func(a, b int) bool {
+		if (groups[a].SupplierID == nil) != (groups[b].SupplierID == nil) {
+			return groups[b].SupplierID == nil
+		}
+		return groups[a].SupplierName < groups[b].SupplierName
+	})
+	return groups
+}
+
+// NewReplenishmentScheduler 创建补货定时任务，按固定间隔执行 RunReplenishment 并记录生成的采购申请
+func NewReplenishmentScheduler(service ReplenishmentService, interval time.Duration) *PeriodicJob {
+	return NewPeriodicJob("补货", interval, func(ctx context.Context) error {
+		result, err := service.RunReplenishment(ctx, &dto.ReplenishmentRequest{})
+		if err != nil {
+			return err
+		}
+
+		for _, request := range result.PurchaseRequests {
+			utils.Info("已生成补货采购申请",
+				utils.String("request_number", request.RequestNumber),
+				utils.String("supplier", request.SupplierName),
+				utils.Int("lines", request.LineCount))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+)
+
+// ReservationService 库存预留服务接口
+type ReservationService interface {
+	ListReservations(ctx context.Context, req *dto.StockReservationListRequest) ([]dto.StockReservationResponse, int64, error)
+	GetSalesOrderReservations(ctx context.Context, salesOrderID uint) ([]dto.StockReservationResponse, error)
+	Reallocate(ctx context.Context, req *dto.StockReservationReallocateRequest) (*dto.StockReservationReallocateResult, error)
+}
+
+// ReservationServiceImpl 库存预留服务实现
+type ReservationServiceImpl struct {
+	reservationRepo repositories.ReservationRepository
+	salesOrderRepo  repositories.SalesOrderRepository
+}
+
+// NewReservationService 创建库存预留服务实例
+func NewReservationService(reservationRepo repositories.ReservationRepository, salesOrderRepo repositories.SalesOrderRepository) ReservationService {
+	return &ReservationServiceImpl{
+		reservationRepo: reservationRepo,
+		salesOrderRepo:  salesOrderRepo,
+	}
+}
+
+// ListReservations 分页获取库存预留
+func (s *ReservationServiceImpl) ListReservations(ctx context.Context, req *dto.StockReservationListRequest) ([]dto.StockReservationResponse, int64, error) {
+	filter := repositories.ReservationFilter{
+		ItemID:       req.ItemID,
+		WarehouseID:  req.WarehouseID,
+		SalesOrderID: req.SalesOrderID,
+		Status:       req.Status,
+		ShortageOnly: req.ShortageOnly,
+	}
+	reservations, total, err := s.reservationRepo.ListReservations(ctx, filter, req.GetOffset(), req.GetLimit())
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取库存预留失败: %w", err)
+	}
+	return toStockReservationResponses(reservations), total, nil
+}
+
+// GetSalesOrderReservations 获取销售订单各行的库存预留
+func (s *ReservationServiceImpl) GetSalesOrderReservations(ctx context.Context, salesOrderID uint) ([]dto.StockReservationResponse, error) {
+	salesOrder, err := s.salesOrderRepo.GetByID(ctx, salesOrderID)
+	if err != nil || salesOrder == nil {
+		return nil, errors.New("销售订单不存在")
+	}
+	reservations, err := s.reservationRepo.GetSalesOrderReservations(ctx, salesOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("获取库存预留失败: %w", err)
+	}
+	return toStockReservationResponses(reservations), nil
+}
+
+// Reallocate 按优先级重新分配库存预留，用于调整优先级后或修复历史数据
+func (s *ReservationServiceImpl) Reallocate(ctx context.Context, req *dto.StockReservationReallocateRequest) (*dto.StockReservationReallocateResult, error) {
+	pairs, err := s.reservationRepo.Reallocate(ctx, req.ItemID, req.WarehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("重新分配库存预留失败: %w", err)
+	}
+	return &dto.StockReservationReallocateResult{Pairs: pairs}, nil
+}
+
+// toStockReservationResponses 转换为库存预留响应格式
+func toStockReservationResponses(reservations []*models.StockReservation) []dto.StockReservationResponse {
+	responses := make([]dto.StockReservationResponse, 0, len(reservations))
+	for _, reservation := range reservations {
+		response := dto.StockReservationResponse{
+			ID:               reservation.ID,
+			SalesOrderID:     reservation.SalesOrderID,
+			SalesOrderItemID: reservation.SalesOrderItemID,
+			ItemID:           reservation.ItemID,
+			WarehouseID:      reservation.WarehouseID,
+			Quantity:         reservation.Quantity,
+			ReservedQty:      reservation.ReservedQty,
+			ShortageQty:      reservation.ShortageQty(),
+			DeliveredQty:     reservation.DeliveredQty,
+			Status:           reservation.Status,
+			ReleasedAt:       reservation.ReleasedAt,
+			CreatedAt:        reservation.CreatedAt,
+			UpdatedAt:        reservation.UpdatedAt,
+		}
+		if reservation.SalesOrder != nil {
+			response.SalesOrderNumber = reservation.SalesOrder.OrderNumber
+			response.Priority = reservation.SalesOrder.Priority
+			deliveryDate := reservation.SalesOrder.DeliveryDate
+			response.DeliveryDate = &deliveryDate
+		}
+		if reservation.Item != nil {
+			response.ItemCode = reservation.Item.Code
+			response.ItemName = reservation.Item.Name
+		}
+		if reservation.Warehouse != nil {
+			response.WarehouseCode = reservation.Warehouse.Code
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
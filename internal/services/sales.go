@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"github.com/galaxyerp/galaxyErp/internal/common"
 	"github.com/galaxyerp/galaxyErp/internal/dto"
 	"github.com/galaxyerp/galaxyErp/internal/models"
@@ -54,11 +56,12 @@ func (s *CustomerServiceImpl) Create(ctx context.Context, req *dto.CustomerCreat
 
 	// 创建客户
 	customer := &models.Customer{
-		Email:         req.Email,
-		Phone:         req.Phone,
-		Address:       req.Address,
-		ContactPerson: req.ContactName,
-		CreditLimit:   req.CreditLimit,
+		RepresentsCompanyID: req.RepresentsCompanyID,
+		Email:               req.Email,
+		Phone:               req.Phone,
+		Address:             req.Address,
+		ContactPerson:       req.ContactName,
+		CreditLimit:         req.CreditLimit,
 	}
 
 	// 设置CodeModel字段
@@ -171,6 +174,9 @@ func (s *CustomerServiceImpl) Update(ctx context.Context, id uint, req *dto.Cust
 	if req.CreditLimit != nil && *req.CreditLimit != 0 {
 		customer.CreditLimit = *req.CreditLimit
 	}
+	if req.RepresentsCompanyID != nil {
+		customer.RepresentsCompanyID = req.RepresentsCompanyID
+	}
 
 	// 更新客户
 	if err := s.customerRepo.Update(ctx, customer); err != nil {
@@ -297,14 +303,15 @@ func (s *CustomerServiceImpl) SearchCustomers(ctx context.Context, req *dto.Sear
 // toCustomerResponse 转换为客户响应格式
 func (s *CustomerServiceImpl) toCustomerResponse(customer *models.Customer) *dto.CustomerResponse {
 	return &dto.CustomerResponse{
-		ID:          customer.ID,
-		Name:        customer.Name,
-		Code:        customer.Code,
-		Email:       customer.Email,
-		Phone:       customer.Phone,
-		Address:     customer.Address,
-		ContactName: customer.ContactPerson,
-		CreditLimit: customer.CreditLimit,
+		ID:                  customer.ID,
+		RepresentsCompanyID: customer.RepresentsCompanyID,
+		Name:                customer.Name,
+		Code:                customer.Code,
+		Email:               customer.Email,
+		Phone:               customer.Phone,
+		Address:             customer.Address,
+		ContactName:         customer.ContactPerson,
+		CreditLimit:         customer.CreditLimit,
 		// PaymentTerms: customer.PaymentTerms, // 字段不存在
 		IsActive:  customer.IsActive,
 		CreatedAt: customer.CreatedAt,
@@ -320,20 +327,28 @@ type SalesOrderService interface {
 	DeleteSalesOrder(ctx context.Context, id uint) error
 	ListSalesOrders(ctx context.Context, req *dto.PaginationRequest) (*dto.PaginatedResponse[dto.SalesOrderResponse], error)
 	GetSalesOrdersByCustomer(ctx context.Context, customerID uint, req *dto.PaginationRequest) (*dto.PaginatedResponse[dto.SalesOrderResponse], error)
-	UpdateOrderStatus(ctx context.Context, id uint, status string) error
+	UpdateOrderStatus(ctx context.Context, id uint, req *dto.SalesOrderStatusUpdateRequest, userID uint) error
+	SyncFulfilmentStatus(ctx context.Context, id uint, userID uint) error
+	GetStatusLogs(ctx context.Context, id uint) ([]dto.SalesOrderStatusLogResponse, error)
 }
 
 // SalesOrderServiceImpl 销售订单服务实现
 type SalesOrderServiceImpl struct {
-	salesOrderRepo repositories.SalesOrderRepository
-	customerRepo   repositories.CustomerRepository
+	salesOrderRepo   repositories.SalesOrderRepository
+	customerRepo     repositories.CustomerRepository
+	reservationRepo  repositories.ReservationRepository
+	priceListService PriceListService
+	auditLogService  AuditLogService
 }
 
 // NewSalesOrderService 创建销售订单服务实例
-func NewSalesOrderService(salesOrderRepo repositories.SalesOrderRepository, customerRepo repositories.CustomerRepository) SalesOrderService {
+func NewSalesOrderService(salesOrderRepo repositories.SalesOrderRepository, customerRepo repositories.CustomerRepository, reservationRepo repositories.ReservationRepository, priceListService PriceListService, auditLogService AuditLogService) SalesOrderService {
 	return &SalesOrderServiceImpl{
-		salesOrderRepo: salesOrderRepo,
-		customerRepo:   customerRepo,
+		salesOrderRepo:   salesOrderRepo,
+		customerRepo:     customerRepo,
+		reservationRepo:  reservationRepo,
+		priceListService: priceListService,
+		auditLogService:  auditLogService,
 	}
 }
 
@@ -349,28 +364,56 @@ func (s *SalesOrderServiceImpl) CreateSalesOrder(ctx context.Context, req *dto.S
 		deliveryDate = req.ExpectedDate // 使用期望日期作为交货日期
 	}
 
+	currency := req.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	exchangeRate := req.ExchangeRate
+	if exchangeRate <= 0 {
+		exchangeRate = 1
+	}
+
 	// 计算订单总金额
-	var totalAmount, discountAmount, taxAmount, grandTotal float64
+	var totalAmount, discountAmount, taxAmount, grandTotal models.Money
 	var orderItems []models.SalesOrderItem
+	priceListID := req.PriceListID
 
 	for _, itemReq := range req.Items {
-		// 计算行金额
-		lineAmount := itemReq.Quantity * itemReq.UnitPrice
-		lineDiscountAmount := lineAmount * (itemReq.Discount / 100)
-		lineNetAmount := lineAmount - lineDiscountAmount
-		lineTaxAmount := lineNetAmount * (itemReq.TaxRate / 100)
-		lineTotalAmount := lineNetAmount + lineTaxAmount
+		// 按价格表取价，未填写单价时以价格表价格成交
+		rate, priceListRate, lineListID, err := resolveLineRate(ctx, s.priceListService, &dto.PriceResolveRequest{
+			PriceListType: models.PriceListTypeSelling,
+			ItemID:        itemReq.ItemID,
+			CustomerID:    req.CustomerID,
+			PriceListID:   derefUint(req.PriceListID),
+			Quantity:      itemReq.Quantity,
+			Date:          &req.OrderDate,
+		}, itemReq.UnitPrice)
+		if err != nil {
+			return nil, err
+		}
+		if priceListID == nil {
+			priceListID = lineListID
+		}
+
+		// 计算行金额，逐行按币种精度舍入后再汇总
+		totals, err := calculateLineTotals(rate, itemReq.Quantity, itemReq.Discount, itemReq.TaxRate, currency)
+		if err != nil {
+			return nil, err
+		}
+		lineAmount, lineDiscountAmount, lineTaxAmount, lineTotalAmount := totals.amount, totals.discount, totals.tax, totals.total()
 
 		orderItem := models.SalesOrderItem{
 			ItemID:         itemReq.ItemID,
 			Quantity:       itemReq.Quantity,
-			Rate:           itemReq.UnitPrice,
+			PriceListRate:  priceListRate,
+			Rate:           rate,
 			Amount:         lineAmount,
 			DiscountRate:   itemReq.Discount,
 			DiscountAmount: lineDiscountAmount,
 			TaxRate:        itemReq.TaxRate,
 			TaxAmount:      lineTaxAmount,
 			TotalAmount:    lineTotalAmount,
+			WarehouseID:    itemReq.WarehouseID,
 		}
 
 		orderItems = append(orderItems, orderItem)
@@ -381,12 +424,17 @@ func (s *SalesOrderServiceImpl) CreateSalesOrder(ctx context.Context, req *dto.S
 	}
 
 	salesOrder := &models.SalesOrder{
+		CompanyID:      req.CompanyID,
 		OrderNumber:    orderNumber,
 		CustomerID:     req.CustomerID,
 		Date:           req.OrderDate,
 		DeliveryDate:   deliveryDate,
 		Status:         "draft",
+		Priority:       req.Priority,
 		QuotationID:    req.QuotationID,
+		PriceListID:    priceListID,
+		Currency:       currency,
+		ExchangeRate:   exchangeRate,
 		TotalAmount:    totalAmount,
 		DiscountAmount: discountAmount,
 		TaxAmount:      taxAmount,
@@ -403,7 +451,7 @@ func (s *SalesOrderServiceImpl) CreateSalesOrder(ctx context.Context, req *dto.S
 	}
 
 	// 重新从数据库加载订单以获取完整的预加载信息
-	fullSalesOrder, err := s.salesOrderRepo.GetByID(ctx, salesOrder.ID)
+	fullSalesOrder, err := s.salesOrderRepo.GetWithItems(ctx, salesOrder.ID)
 	if err != nil {
 		return nil, fmt.Errorf("重新加载销售订单失败: %w", err)
 	}
@@ -413,7 +461,7 @@ func (s *SalesOrderServiceImpl) CreateSalesOrder(ctx context.Context, req *dto.S
 		utils.Uint("order_id", salesOrder.ID),
 		utils.String("order_number", salesOrder.OrderNumber),
 		utils.Uint("customer_id", salesOrder.CustomerID),
-		utils.Float64("grand_total", salesOrder.GrandTotal),
+		utils.String("grand_total", salesOrder.GrandTotal.String()),
 		utils.Uint("created_by", userID),
 		utils.String("operation", "create_sales_order"),
 	)
@@ -423,7 +471,7 @@ func (s *SalesOrderServiceImpl) CreateSalesOrder(ctx context.Context, req *dto.S
 
 // GetSalesOrder 获取销售订单
 func (s *SalesOrderServiceImpl) GetSalesOrder(ctx context.Context, id uint) (*dto.SalesOrderResponse, error) {
-	salesOrder, err := s.salesOrderRepo.GetByID(ctx, id)
+	salesOrder, err := s.salesOrderRepo.GetWithItems(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("获取销售订单失败: %w", err)
 	}
@@ -451,11 +499,15 @@ func (s *SalesOrderServiceImpl) UpdateSalesOrder(ctx context.Context, id uint, r
 	if req.OrderDate != nil {
 		salesOrder.Date = *req.OrderDate
 	}
+	// 优先级或交货日期变化会影响预留分配顺序
+	reallocate := false
 	if req.DeliveryDate != nil {
+		reallocate = reallocate || !salesOrder.DeliveryDate.Equal(*req.DeliveryDate)
 		salesOrder.DeliveryDate = *req.DeliveryDate
 	}
-	if req.Status != nil && *req.Status != "" {
-		salesOrder.Status = *req.Status
+	if req.Priority != nil {
+		reallocate = reallocate || salesOrder.Priority != *req.Priority
+		salesOrder.Priority = *req.Priority
 	}
 	if req.Notes != nil && *req.Notes != "" {
 		salesOrder.Notes = *req.Notes
@@ -472,6 +524,34 @@ func (s *SalesOrderServiceImpl) UpdateSalesOrder(ctx context.Context, id uint, r
 		return fmt.Errorf("更新销售订单失败: %w", err)
 	}
 
+	// 状态变更需要校验状态机并同步预留，统一走 UpdateOrderStatus
+	if req.Status != nil && *req.Status != "" && NormalizeSalesOrderStatus(*req.Status) != NormalizeSalesOrderStatus(salesOrder.Status) {
+		return s.UpdateOrderStatus(ctx, id, &dto.SalesOrderStatusUpdateRequest{Status: *req.Status}, 0)
+	}
+
+	if reallocate && NormalizeSalesOrderStatus(salesOrder.Status) == models.SalesOrderStatusConfirmed {
+		if err := s.reallocateSalesOrder(ctx, id); err != nil {
+			return fmt.Errorf("重新分配库存预留失败: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// reallocateSalesOrder 重新分配销售订单涉及的物料与仓库的预留
+func (s *SalesOrderServiceImpl) reallocateSalesOrder(ctx context.Context, id uint) error {
+	reservations, err := s.reservationRepo.GetSalesOrderReservations(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, reservation := range reservations {
+		if reservation.Status != models.ReservationStatusActive {
+			continue
+		}
+		if _, err := s.reservationRepo.Reallocate(ctx, reservation.ItemID, reservation.WarehouseID); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -559,33 +639,25 @@ func (s *SalesOrderServiceImpl) GetSalesOrdersByCustomer(ctx context.Context, cu
 	}, nil
 }
 
-// UpdateOrderStatus 更新订单状态
-func (s *SalesOrderServiceImpl) UpdateOrderStatus(ctx context.Context, id uint, status string) error {
-	salesOrder, err := s.salesOrderRepo.GetByID(ctx, id)
-	if err != nil {
-		return fmt.Errorf("获取销售订单失败: %w", err)
-	}
-	if salesOrder == nil {
-		return errors.New("销售订单不存在")
-	}
-
-	salesOrder.Status = status
-	return s.salesOrderRepo.Update(ctx, salesOrder)
-}
-
 // toSalesOrderResponse 转换为销售订单响应格式
 func (s *SalesOrderServiceImpl) toSalesOrderResponse(salesOrder *models.SalesOrder) *dto.SalesOrderResponse {
 	response := &dto.SalesOrderResponse{
 		ID:              salesOrder.ID,
+		CompanyID:       salesOrder.CompanyID,
 		Number:          salesOrder.OrderNumber,
 		OrderNumber:     salesOrder.OrderNumber, // 前端期望的字段名
 		Status:          salesOrder.Status,
+		Priority:        salesOrder.Priority,
 		OrderDate:       salesOrder.Date,         // 前端期望的订单日期字段
 		DeliveryDate:    salesOrder.DeliveryDate, // 前端期望的交付日期字段
 		ExpectedDate:    salesOrder.DeliveryDate, // 使用DeliveryDate作为ExpectedDate
 		PaymentTerms:    salesOrder.Terms,        // 使用Terms字段
 		ShippingAddress: "",                      // 模型中没有ShippingAddress字段
+		NextStatuses:    NextSalesOrderStatuses(salesOrder.Status),
 		Notes:           salesOrder.Notes,
+		PriceListID:     salesOrder.PriceListID,
+		Currency:        salesOrder.Currency,
+		ExchangeRate:    salesOrder.ExchangeRate,
 		SubTotal:        salesOrder.TotalAmount,
 		DiscountAmount:  salesOrder.DiscountAmount,
 		TaxAmount:       salesOrder.TaxAmount,
@@ -637,9 +709,11 @@ func (s *SalesOrderServiceImpl) toSalesOrderResponse(salesOrder *models.SalesOrd
 		for i, item := range salesOrder.Items {
 			itemResponse := dto.SalesOrderItemResponse{
 				ID:             item.ID,
-				SalesOrderID:   item.SalesOrderID,
+				SalesOrderID:    item.SalesOrderID,
+				QuotationItemID: item.QuotationItemID,
 				ItemID:         item.ItemID,
 				Quantity:       item.Quantity,
+				PriceListRate:  item.PriceListRate,
 				UnitPrice:      item.Rate,         // 使用Rate字段作为UnitPrice
 				Discount:       item.DiscountRate, // 使用DiscountRate字段作为Discount
 				DiscountAmount: item.DiscountAmount,
@@ -647,6 +721,8 @@ func (s *SalesOrderServiceImpl) toSalesOrderResponse(salesOrder *models.SalesOrd
 				TaxAmount:      item.TaxAmount,
 				LineTotal:      item.TotalAmount, // 使用TotalAmount字段作为LineTotal
 				DeliveredQty:   item.DeliveredQty,
+				BilledQty:      item.BilledQty,
+				WarehouseID:    item.WarehouseID,
 				Description:    item.Description,
 				CreatedAt:      item.CreatedAt,
 				UpdatedAt:      item.UpdatedAt,
@@ -686,20 +762,24 @@ type QuotationService interface {
 	ListQuotations(ctx context.Context, req *dto.PaginationRequest) (*dto.PaginatedResponse[dto.QuotationResponse], error)
 	GetQuotationsByCustomer(ctx context.Context, customerID uint, req *dto.PaginationRequest) (*dto.PaginatedResponse[dto.QuotationResponse], error)
 	SearchQuotations(ctx context.Context, req *dto.SearchRequest) (*dto.PaginatedResponse[dto.QuotationResponse], error)
-	ConvertToSalesOrder(ctx context.Context, quotationID uint) (*dto.SalesOrderResponse, error)
+	ConvertToSalesOrder(ctx context.Context, quotationID uint, req *dto.QuotationConvertRequest, userID uint) (*dto.SalesOrderResponse, error)
 }
 
 // QuotationServiceImpl 报价服务实现
 type QuotationServiceImpl struct {
-	quotationRepo repositories.QuotationRepository
-	customerRepo  repositories.CustomerRepository
+	quotationRepo     repositories.QuotationRepository
+	customerRepo      repositories.CustomerRepository
+	priceListService  PriceListService
+	salesOrderService SalesOrderService
 }
 
 // NewQuotationService 创建报价单
-func NewQuotationService(quotationRepo repositories.QuotationRepository, customerRepo repositories.CustomerRepository) QuotationService {
+func NewQuotationService(quotationRepo repositories.QuotationRepository, customerRepo repositories.CustomerRepository, priceListService PriceListService, salesOrderService SalesOrderService) QuotationService {
 	return &QuotationServiceImpl{
-		quotationRepo: quotationRepo,
-		customerRepo:  customerRepo,
+		quotationRepo:     quotationRepo,
+		customerRepo:      customerRepo,
+		priceListService:  priceListService,
+		salesOrderService: salesOrderService,
 	}
 }
 
@@ -707,16 +787,70 @@ func NewQuotationService(quotationRepo repositories.QuotationRepository, custome
 func (s *QuotationServiceImpl) CreateQuotation(ctx context.Context, req *dto.QuotationCreateRequest) (*dto.QuotationResponse, error) {
 	// 生成报价单编号
 	quotationNumber := fmt.Sprintf("QT%s%06d", time.Now().Format("20060102"), time.Now().Unix()%1000000)
+	quotationDate := time.Now()
+
+	// 计算报价明细，逐行按币种精度舍入后再汇总
+	var totalAmount, discountAmount, taxAmount, grandTotal models.Money
+	var quotationItems []models.QuotationItem
+	priceListID := req.PriceListID
+
+	for _, itemReq := range req.Items {
+		rate, priceListRate, lineListID, err := resolveLineRate(ctx, s.priceListService, &dto.PriceResolveRequest{
+			PriceListType: models.PriceListTypeSelling,
+			ItemID:        itemReq.ItemID,
+			CustomerID:    req.CustomerID,
+			PriceListID:   derefUint(req.PriceListID),
+			Quantity:      itemReq.Quantity,
+			Date:          &quotationDate,
+		}, itemReq.UnitPrice)
+		if err != nil {
+			return nil, err
+		}
+		if priceListID == nil {
+			priceListID = lineListID
+		}
+
+		totals, err := calculateLineTotals(rate, itemReq.Quantity, itemReq.Discount, itemReq.TaxRate, models.DefaultCurrency)
+		if err != nil {
+			return nil, err
+		}
+		lineAmount, lineDiscountAmount, lineTaxAmount, lineTotalAmount := totals.amount, totals.discount, totals.tax, totals.total()
+
+		quotationItems = append(quotationItems, models.QuotationItem{
+			ItemID:         itemReq.ItemID,
+			Description:    itemReq.Notes,
+			Quantity:       itemReq.Quantity,
+			PriceListRate:  priceListRate,
+			Rate:           rate,
+			Amount:         lineAmount,
+			DiscountRate:   itemReq.Discount,
+			DiscountAmount: lineDiscountAmount,
+			TaxRate:        itemReq.TaxRate,
+			TaxAmount:      lineTaxAmount,
+			TotalAmount:    lineTotalAmount,
+		})
+		totalAmount += lineAmount
+		discountAmount += lineDiscountAmount
+		taxAmount += lineTaxAmount
+		grandTotal += lineTotalAmount
+	}
 
 	quotation := &models.Quotation{
+		CompanyID:       req.CompanyID,
 		QuotationNumber: quotationNumber,
 		CustomerID:      req.CustomerID,
-		Date:            time.Now(),     // 使用Date字段
+		PriceListID:     priceListID,
+		Date:            quotationDate,  // 使用Date字段
 		ValidTill:       req.ValidUntil, // 使用ValidUntil字段
 		Status:          "draft",
 		Subject:         req.Title,        // 使用Title作为Subject
 		Terms:           req.PaymentTerms, // 使用PaymentTerms作为Terms
 		Notes:           req.Notes,
+		TotalAmount:     totalAmount,
+		DiscountAmount:  discountAmount,
+		TaxAmount:       taxAmount,
+		GrandTotal:      grandTotal,
+		Items:           quotationItems, // GORM会自动创建报价明细
 	}
 
 	if err := s.quotationRepo.Create(ctx, quotation); err != nil {
@@ -728,7 +862,7 @@ func (s *QuotationServiceImpl) CreateQuotation(ctx context.Context, req *dto.Quo
 
 // GetQuotation 获取报价
 func (s *QuotationServiceImpl) GetQuotation(ctx context.Context, id uint) (*dto.QuotationResponse, error) {
-	quotation, err := s.quotationRepo.GetByID(ctx, id)
+	quotation, err := s.quotationRepo.GetWithItems(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("获取报价失败: %w", err)
 	}
@@ -902,11 +1036,218 @@ func (s *QuotationServiceImpl) SearchQuotations(ctx context.Context, req *dto.Se
 	}, nil
 }
 
-// ConvertToSalesOrder 将报价转换为销售订单
-func (s *QuotationServiceImpl) ConvertToSalesOrder(ctx context.Context, quotationID uint) (*dto.SalesOrderResponse, error) {
-	// 这个方法需要与SalesOrderService协作实现
-	// 暂时返回错误，提示需要实现
-	return nil, errors.New("报价转销售订单功能暂未实现")
+// quotationConvertLine 转入销售订单的报价明细与数量
+type quotationConvertLine struct {
+	line        models.QuotationVersionLine
+	quantity    float64
+	warehouseID *uint
+}
+
+// ConvertToSalesOrder 将报价转换为销售订单。按活跃版本的明细、价格与条款生成订单，没有版本数据时按报价单当前明细；
+// 可只转入部分明细与数量，全部明细下单后报价单标记为已下单，否则为部分下单。已过期的报价单不能转换
+func (s *QuotationServiceImpl) ConvertToSalesOrder(ctx context.Context, quotationID uint, req *dto.QuotationConvertRequest, userID uint) (*dto.SalesOrderResponse, error) {
+	quotation, err := s.quotationRepo.GetWithItems(ctx, quotationID)
+	if err != nil {
+		return nil, fmt.Errorf("获取报价单失败: %w", err)
+	}
+	if quotation == nil {
+		return nil, errors.New("报价单不存在")
+	}
+	if strings.ToLower(quotation.Status) == models.QuotationStatusCancelled {
+		return nil, errors.New("报价单已取消，不能转为销售订单")
+	}
+	if models.StartOfDay(quotation.ValidTill).Before(models.StartOfDay(time.Now())) {
+		return nil, fmt.Errorf("报价单已于 %s 过期，不能转为销售订单", quotation.ValidTill.Format("2006-01-02"))
+	}
+
+	data, err := s.quotationVersionData(ctx, quotation)
+	if err != nil {
+		return nil, err
+	}
+	ordered, err := s.quotationRepo.GetOrderedQuantities(ctx, quotation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取报价单已下单数量失败: %w", err)
+	}
+
+	picks, err := selectQuotationLines(data.Items, ordered, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	orderDate := time.Now()
+	if req.OrderDate != nil && !req.OrderDate.IsZero() {
+		orderDate = *req.OrderDate
+	}
+	deliveryDate := orderDate
+	if req.DeliveryDate != nil && !req.DeliveryDate.IsZero() {
+		deliveryDate = *req.DeliveryDate
+	}
+	notes := data.Notes
+	if req.Notes != "" {
+		notes = req.Notes
+	}
+
+	// 按报价的单价、折扣与税率计算订单明细，逐行按币种精度舍入后再汇总
+	var totalAmount, discountAmount, taxAmount, grandTotal models.Money
+	orderItems := make([]models.SalesOrderItem, 0, len(picks))
+	for _, pick := range picks {
+		line := pick.line
+		totals, err := calculateLineTotals(line.Rate, pick.quantity, line.DiscountRate, line.TaxRate, models.DefaultCurrency)
+		if err != nil {
+			return nil, err
+		}
+		lineAmount, lineDiscountAmount, lineTaxAmount, lineTotalAmount := totals.amount, totals.discount, totals.tax, totals.total()
+
+		quotationItemID := line.QuotationItemID
+		orderItems = append(orderItems, models.SalesOrderItem{
+			QuotationItemID: &quotationItemID,
+			ItemID:          line.ItemID,
+			Description:     line.Description,
+			Quantity:        pick.quantity,
+			PriceListRate:   line.PriceListRate,
+			Rate:            line.Rate,
+			Amount:          lineAmount,
+			DiscountRate:    line.DiscountRate,
+			DiscountAmount:  lineDiscountAmount,
+			TaxRate:         line.TaxRate,
+			TaxAmount:       lineTaxAmount,
+			TotalAmount:     lineTotalAmount,
+			WarehouseID:     pick.warehouseID,
+		})
+		totalAmount += lineAmount
+		discountAmount += lineDiscountAmount
+		taxAmount += lineTaxAmount
+		grandTotal += lineTotalAmount
+	}
+
+	// 事务中按报价数量重新校验未下单数量，并回写全部明细的已下单数量，已取消订单释放的数量同时更新
+	quoted := make(map[uint]float64, len(data.Items))
+	for _, line := range data.Items {
+		quoted[line.QuotationItemID] = line.Quantity
+	}
+
+	salesOrder := &models.SalesOrder{
+		CompanyID:      quotation.CompanyID,
+		OrderNumber:    fmt.Sprintf("SO%s%06d", time.Now().Format("20060102"), time.Now().Unix()%1000000),
+		CustomerID:     quotation.CustomerID,
+		Date:           orderDate,
+		DeliveryDate:   deliveryDate,
+		Status:         "draft",
+		Priority:       req.Priority,
+		QuotationID:    &quotation.ID,
+		PriceListID:    data.PriceListID,
+		TotalAmount:    totalAmount,
+		DiscountAmount: discountAmount,
+		TaxAmount:      taxAmount,
+		GrandTotal:     grandTotal,
+		Terms:          data.Terms,
+		Notes:          notes,
+		CreatedBy:      userID,
+		Items:          orderItems,
+	}
+	status, err := s.quotationRepo.CreateSalesOrderFromQuotation(ctx, salesOrder, quoted)
+	if err != nil {
+		return nil, fmt.Errorf("报价单转销售订单失败: %w", err)
+	}
+
+	utils.Info("报价单转销售订单",
+		utils.Uint("quotation_id", quotation.ID),
+		utils.Uint("order_id", salesOrder.ID),
+		utils.String("order_number", salesOrder.OrderNumber),
+		utils.String("quotation_status", status),
+		utils.Uint("created_by", userID),
+	)
+
+	return s.salesOrderService.GetSalesOrder(ctx, salesOrder.ID)
+}
+
+// quotationVersionData 返回报价单活跃版本的数据，没有活跃版本或版本未保存数据时取报价单当前的条款与明细
+func (s *QuotationServiceImpl) quotationVersionData(ctx context.Context, quotation *models.Quotation) (*models.QuotationVersionData, error) {
+	version, err := s.quotationRepo.GetActiveVersion(ctx, quotation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取报价单活跃版本失败: %w", err)
+	}
+	if version == nil || strings.TrimSpace(version.VersionData) == "" {
+		return newQuotationVersionData(quotation), nil
+	}
+
+	var data models.QuotationVersionData
+	if err := json.Unmarshal([]byte(version.VersionData), &data); err != nil {
+		return nil, fmt.Errorf("解析报价单版本 %d 数据失败: %w", version.VersionNumber, err)
+	}
+	return &data, nil
+}
+
+// newQuotationVersionData 按报价单当前的条款与明细生成版本数据
+func newQuotationVersionData(quotation *models.Quotation) *models.QuotationVersionData {
+	data := &models.QuotationVersionData{
+		Subject:     quotation.Subject,
+		Terms:       quotation.Terms,
+		Notes:       quotation.Notes,
+		PriceListID: quotation.PriceListID,
+		Items:       make([]models.QuotationVersionLine, 0, len(quotation.Items)),
+	}
+	for _, item := range quotation.Items {
+		data.Items = append(data.Items, models.QuotationVersionLine{
+			QuotationItemID: item.ID,
+			ItemID:          item.ItemID,
+			Description:     item.Description,
+			Quantity:        item.Quantity,
+			PriceListRate:   item.PriceListRate,
+			Rate:            item.Rate,
+			DiscountRate:    item.DiscountRate,
+			TaxRate:         item.TaxRate,
+		})
+	}
+	return data
+}
+
+// selectQuotationLines 确定转入销售订单的明细与数量：未选择明细时转入全部未下单数量，
+// 选择的数量为空时转入该行全部未下单数量，不能超过未下单数量
+func selectQuotationLines(lines []models.QuotationVersionLine, ordered map[uint]float64, selected []dto.QuotationConvertItemRequest) ([]quotationConvertLine, error) {
+	var picks []quotationConvertLine
+	if len(selected) == 0 {
+		for _, line := range lines {
+			remaining := line.Quantity - ordered[line.QuotationItemID]
+			if remaining > stockQuantityTolerance {
+				picks = append(picks, quotationConvertLine{line: line, quantity: remaining})
+			}
+		}
+		if len(picks) == 0 {
+			return nil, errors.New("报价单没有未下单的明细")
+		}
+		return picks, nil
+	}
+
+	byID := make(map[uint]models.QuotationVersionLine, len(lines))
+	for _, line := range lines {
+		byID[line.QuotationItemID] = line
+	}
+	seen := make(map[uint]bool, len(selected))
+	for _, sel := range selected {
+		line, ok := byID[sel.QuotationItemID]
+		if !ok {
+			return nil, fmt.Errorf("报价明细 %d 不属于该报价单", sel.QuotationItemID)
+		}
+		if seen[sel.QuotationItemID] {
+			return nil, fmt.Errorf("报价明细 %d 重复", sel.QuotationItemID)
+		}
+		seen[sel.QuotationItemID] = true
+
+		remaining := line.Quantity - ordered[line.QuotationItemID]
+		if remaining <= stockQuantityTolerance {
+			return nil, fmt.Errorf("报价明细 %d 已全部下单", sel.QuotationItemID)
+		}
+		quantity := sel.Quantity
+		if quantity == 0 {
+			quantity = remaining
+		}
+		if quantity > remaining+stockQuantityTolerance {
+			return nil, fmt.Errorf("报价明细 %d 转入数量 %g 超过未下单数量 %g", sel.QuotationItemID, quantity, remaining)
+		}
+		picks = append(picks, quotationConvertLine{line: line, quantity: quantity, warehouseID: sel.WarehouseID})
+	}
+	return picks, nil
 }
 
 // toQuotationResponse 转换为报价响应格式
@@ -929,8 +1270,14 @@ func (s *QuotationServiceImpl) toQuotationResponse(quotation *models.Quotation)
 		}
 	}
 
+	items := make([]dto.QuotationItemResponse, 0, len(quotation.Items))
+	for _, item := range quotation.Items {
+		items = append(items, s.toQuotationItemResponse(item))
+	}
+
 	return &dto.QuotationResponse{
 		ID:              quotation.ID,
+		CompanyID:       quotation.CompanyID,
 		Number:          quotation.QuotationNumber,
 		QuotationNumber: quotation.QuotationNumber, // 前端期望的字段名
 		Title:           quotation.Subject,         // 使用Subject作为Title
@@ -942,12 +1289,14 @@ func (s *QuotationServiceImpl) toQuotationResponse(quotation *models.Quotation)
 		Date:            quotation.CreatedAt, // 前端期望的字段名
 		PaymentTerms:    quotation.Terms,     // 使用Terms字段
 		Notes:           quotation.Notes,
+		PriceListID:     quotation.PriceListID,
 		SubTotal:        quotation.TotalAmount,
 		DiscountAmount:  quotation.DiscountAmount,
 		TaxAmount:       quotation.TaxAmount,
 		TotalAmount:     quotation.TotalAmount, // 前端表单期望的字段名，应该是基础总金额
 		GrandTotal:      quotation.GrandTotal,  // 前端表格期望的字段名，是最终总计金额
 		Customer:        customerResponse,      // 包含客户信息
+		Items:           items,
 		CreatedAt:       quotation.CreatedAt,
 		UpdatedAt:       quotation.UpdatedAt,
 	}
@@ -955,11 +1304,18 @@ func (s *QuotationServiceImpl) toQuotationResponse(quotation *models.Quotation)
 
 func (s *QuotationServiceImpl) toQuotationItemResponse(item models.QuotationItem) dto.QuotationItemResponse {
 	return dto.QuotationItemResponse{
-		ID:        item.ID,
-		Quantity:  item.Quantity,
-		UnitPrice: item.Rate, // 使用Rate字段作为UnitPrice
-		Amount:    item.Amount,
-		// Notes字段在QuotationItem模型中不存在，使用Description
+		ID:             item.ID,
+		ItemID:         item.ItemID,
+		Quantity:       item.Quantity,
+		OrderedQty:     item.OrderedQty,
+		PriceListRate:  item.PriceListRate,
+		UnitPrice:      item.Rate, // 使用Rate字段作为UnitPrice
+		Discount:       item.DiscountRate,
+		DiscountAmount: item.DiscountAmount,
+		TaxRate:        item.TaxRate,
+		TaxAmount:      item.TaxAmount,
+		Amount:         item.Amount,
+		Notes:          item.Description, // Notes字段在QuotationItem模型中不存在，使用Description
 	}
 }
 
@@ -1183,13 +1539,14 @@ func (s *QuotationTemplateServiceImpl) CreateQuotationFromTemplate(ctx context.C
 
 	// 创建报价单项目
 	var quotationItems []models.QuotationItem
-	var totalAmount float64
+	var totalAmount models.Money
 
 	for _, templateItem := range template.Items {
-		amount := templateItem.Quantity * templateItem.Rate
-		discountAmount := amount * templateItem.DiscountRate / 100
-		taxAmount := (amount - discountAmount) * templateItem.TaxRate / 100
-		itemTotal := amount - discountAmount + taxAmount
+		totals, err := calculateLineTotals(templateItem.Rate, templateItem.Quantity, templateItem.DiscountRate, templateItem.TaxRate, models.DefaultCurrency)
+		if err != nil {
+			return nil, err
+		}
+		amount, discountAmount, taxAmount, itemTotal := totals.amount, totals.discount, totals.tax, totals.total()
 
 		quotationItem := models.QuotationItem{
 			ItemID:         templateItem.ItemID,
@@ -1221,6 +1578,7 @@ func (s *QuotationTemplateServiceImpl) CreateQuotationFromTemplate(ctx context.C
 	// 转换为响应格式
 	return &dto.QuotationResponse{
 		ID:             quotation.ID,
+		CompanyID:      quotation.CompanyID,
 		Number:         quotation.QuotationNumber,
 		Title:          quotation.Subject,
 		Status:         quotation.Status,
@@ -1303,7 +1661,7 @@ func NewQuotationVersionService(versionRepo repositories.QuotationVersionReposit
 // CreateVersion 创建新版本
 func (s *QuotationVersionServiceImpl) CreateVersion(ctx context.Context, req *dto.QuotationVersionCreateRequest) (*dto.QuotationVersionResponse, error) {
 	// 验证报价单是否存在
-	quotation, err := s.quotationRepo.GetByID(ctx, req.QuotationID)
+	quotation, err := s.quotationRepo.GetWithItems(ctx, req.QuotationID)
 	if err != nil {
 		return nil, fmt.Errorf("获取报价单失败: %w", err)
 	}
@@ -1311,6 +1669,12 @@ func (s *QuotationVersionServiceImpl) CreateVersion(ctx context.Context, req *dt
 		return nil, errors.New("报价单不存在")
 	}
 
+	// 保存报价单当前的条款与明细，转为销售订单时按活跃版本的数据生成订单
+	versionData, err := json.Marshal(newQuotationVersionData(quotation))
+	if err != nil {
+		return nil, fmt.Errorf("序列化版本数据失败: %w", err)
+	}
+
 	// 获取下一个版本号
 	nextVersion, err := s.versionRepo.GetNextVersionNumber(ctx, req.QuotationID)
 	if err != nil {
@@ -1325,7 +1689,7 @@ func (s *QuotationVersionServiceImpl) CreateVersion(ctx context.Context, req *dt
 		ChangeReason:  req.ChangeReason,
 		CreatedBy:     1,     // 暂时硬编码，后续从上下文获取
 		IsActive:      false, // 新版本默认不激活
-		VersionData:   "",    // 暂时为空，后续实现版本数据序列化
+		VersionData:   string(versionData),
 	}
 
 	err = s.versionRepo.Create(ctx, version)
@@ -19,6 +19,8 @@ import (
 // SalesInvoiceService 销售发票服务接口
 type SalesInvoiceService interface {
 	CreateSalesInvoice(ctx *gin.Context, req *dto.SalesInvoiceCreateRequest) (*dto.SalesInvoiceResponse, error)
+	CreateFromSalesOrder(ctx context.Context, req *dto.SalesInvoiceFromSalesOrderRequest, userID uint) (*dto.SalesInvoiceResponse, error)
+	CreateFromDeliveryNotes(ctx context.Context, req *dto.SalesInvoiceFromDeliveryNoteRequest, userID uint) (*dto.SalesInvoiceResponse, error)
 	GetSalesInvoice(id uint) (*dto.SalesInvoiceResponse, error)
 	UpdateSalesInvoice(ctx *gin.Context, id uint, req *dto.SalesInvoiceUpdateRequest) (*dto.SalesInvoiceResponse, error)
 	SubmitSalesInvoice(ctx *gin.Context, id uint) (*dto.SalesInvoiceResponse, error)
@@ -31,24 +33,33 @@ type SalesInvoiceService interface {
 
 // SalesInvoiceServiceImpl 销售发票服务实现
 type SalesInvoiceServiceImpl struct {
-	repository          repositories.SalesInvoiceRepository
-	customerRepository  repositories.CustomerRepository
-	salesOrderRepository repositories.SalesOrderRepository
-	paymentEntryService PaymentEntryService
+	repository             repositories.SalesInvoiceRepository
+	customerRepository     repositories.CustomerRepository
+	salesOrderRepository   repositories.SalesOrderRepository
+	paymentEntryService    PaymentEntryService
+	uomService             UOMService
+	deliveryNoteRepository repositories.DeliveryNoteRepository
+	salesOrderService      SalesOrderService
 }
 
 // NewSalesInvoiceService 创建销售发票服务实例
 func NewSalesInvoiceService(
-	repository repositories.SalesInvoiceRepository, 
+	repository repositories.SalesInvoiceRepository,
 	customerRepository repositories.CustomerRepository,
 	salesOrderRepository repositories.SalesOrderRepository,
 	paymentEntryService PaymentEntryService,
+	uomService UOMService,
+	deliveryNoteRepository repositories.DeliveryNoteRepository,
+	salesOrderService SalesOrderService,
 ) SalesInvoiceService {
 	return &SalesInvoiceServiceImpl{
-		repository:           repository,
-		customerRepository:   customerRepository,
-		salesOrderRepository: salesOrderRepository,
-		paymentEntryService:  paymentEntryService,
+		repository:             repository,
+		customerRepository:     customerRepository,
+		salesOrderRepository:   salesOrderRepository,
+		paymentEntryService:    paymentEntryService,
+		uomService:             uomService,
+		deliveryNoteRepository: deliveryNoteRepository,
+		salesOrderService:      salesOrderService,
 	}
 }
 
@@ -91,6 +102,7 @@ func (s *SalesInvoiceServiceImpl) CreateSalesInvoice(ctx *gin.Context, req *dto.
 
 	// 创建销售发票
 	invoice := &models.SalesInvoice{
+		CompanyID:        req.CompanyID,
 		InvoiceNumber:    invoiceNumber,
 		CustomerID:       req.CustomerID,
 		SalesOrderID:     req.SalesOrderID,
@@ -121,13 +133,21 @@ func (s *SalesInvoiceServiceImpl) CreateSalesInvoice(ctx *gin.Context, req *dto.
 	}
 
 	// 计算发票明细和总金额
-	var totalAmount float64
+	var totalAmount models.Money
 	for _, itemReq := range req.Items {
-		// 计算金额
-		amount := itemReq.Quantity * itemReq.Rate
-		discountAmount := amount * itemReq.DiscountPercentage / 100
-		netAmount := amount - discountAmount
-		taxAmount := netAmount * itemReq.TaxRate / 100
+		// 开票单位必须已定义且可换算为库存单位
+		conversion, err := s.uomService.ConvertToStock(ctx, itemReq.ItemID, itemReq.UOM, itemReq.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		itemReq.Quantity = conversion.Quantity
+
+		// 计算金额，逐行按币种精度舍入
+		totals, err := calculateLineTotals(itemReq.Rate, itemReq.Quantity, itemReq.DiscountPercentage, itemReq.TaxRate, invoice.Currency)
+		if err != nil {
+			return nil, err
+		}
+		amount, discountAmount, netAmount, taxAmount := totals.amount, totals.discount, totals.net, totals.tax
 
 		invoiceItem := models.SalesInvoiceItem{
 			SalesOrderItemID:   itemReq.SalesOrderItemID,
@@ -135,7 +155,9 @@ func (s *SalesInvoiceServiceImpl) CreateSalesInvoice(ctx *gin.Context, req *dto.
 			ItemID:             itemReq.ItemID,
 			Description:        itemReq.Description,
 			Quantity:           itemReq.Quantity,
-			UOM:                itemReq.UOM,
+			UOM:                conversion.UOM,
+			ConversionFactor:   conversion.ConversionFactor,
+			StockUOM:           conversion.StockUOM,
 			Rate:               itemReq.Rate,
 			Amount:             amount,
 			DiscountPercentage: itemReq.DiscountPercentage,
@@ -143,7 +165,7 @@ func (s *SalesInvoiceServiceImpl) CreateSalesInvoice(ctx *gin.Context, req *dto.
 			TaxCategory:        itemReq.TaxCategory,
 			TaxRate:            itemReq.TaxRate,
 			TaxAmount:          taxAmount,
-			NetRate:            (netAmount + taxAmount) / itemReq.Quantity,
+			NetRate:            totals.netRate,
 			NetAmount:          netAmount + taxAmount,
 			WarehouseID:        itemReq.WarehouseID,
 			BatchNo:            itemReq.BatchNo,
@@ -172,13 +194,13 @@ func (s *SalesInvoiceServiceImpl) CreateSalesInvoice(ctx *gin.Context, req *dto.
 
 // GetSalesInvoice 获取销售发票详情
 func (s *SalesInvoiceServiceImpl) GetSalesInvoice(id uint) (*dto.SalesInvoiceResponse, error) {
-	invoice, err := s.repository.GetByID(nil, id)
+	invoice, err := s.repository.GetWithItems(context.Background(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("销售发票不存在")
-		}
 		return nil, fmt.Errorf("获取销售发票失败: %v", err)
 	}
+	if invoice == nil {
+		return nil, errors.New("销售发票不存在")
+	}
 
 	return s.convertToSalesInvoiceResponse(invoice), nil
 }
@@ -261,13 +283,21 @@ func (s *SalesInvoiceServiceImpl) UpdateSalesInvoice(ctx *gin.Context, id uint,
 		invoice.Items = nil
 		
 		// 重新创建明细
-		var totalAmount float64
+		var totalAmount models.Money
 		for _, itemReq := range req.Items {
-			// 计算金额
-			amount := itemReq.Quantity * itemReq.Rate
-			discountAmount := amount * itemReq.DiscountPercentage / 100
-			netAmount := amount - discountAmount
-			taxAmount := netAmount * itemReq.TaxRate / 100
+			// 开票单位必须已定义且可换算为库存单位
+			conversion, err := s.uomService.ConvertToStock(ctx, itemReq.ItemID, itemReq.UOM, itemReq.Quantity)
+			if err != nil {
+				return nil, err
+			}
+			itemReq.Quantity = conversion.Quantity
+
+			// 计算金额，逐行按币种精度舍入
+			totals, err := calculateLineTotals(itemReq.Rate, itemReq.Quantity, itemReq.DiscountPercentage, itemReq.TaxRate, invoice.Currency)
+			if err != nil {
+				return nil, err
+			}
+			amount, discountAmount, netAmount, taxAmount := totals.amount, totals.discount, totals.net, totals.tax
 
 			invoiceItem := models.SalesInvoiceItem{
 				SalesOrderItemID:   itemReq.SalesOrderItemID,
@@ -275,7 +305,9 @@ func (s *SalesInvoiceServiceImpl) UpdateSalesInvoice(ctx *gin.Context, id uint,
 				ItemID:             itemReq.ItemID,
 				Description:        itemReq.Description,
 				Quantity:           itemReq.Quantity,
-				UOM:                itemReq.UOM,
+				UOM:                conversion.UOM,
+				ConversionFactor:   conversion.ConversionFactor,
+				StockUOM:           conversion.StockUOM,
 				Rate:               itemReq.Rate,
 				Amount:             amount,
 				DiscountPercentage: itemReq.DiscountPercentage,
@@ -283,7 +315,7 @@ func (s *SalesInvoiceServiceImpl) UpdateSalesInvoice(ctx *gin.Context, id uint,
 				TaxCategory:        itemReq.TaxCategory,
 				TaxRate:            itemReq.TaxRate,
 				TaxAmount:          taxAmount,
-				NetRate:            (netAmount + taxAmount) / itemReq.Quantity,
+				NetRate:            totals.netRate,
 				NetAmount:          netAmount + taxAmount,
 				WarehouseID:        itemReq.WarehouseID,
 				BatchNo:            itemReq.BatchNo,
@@ -330,28 +362,55 @@ func (s *SalesInvoiceServiceImpl) CancelSalesInvoice(ctx *gin.Context, id uint)
 	return s.updateInvoiceStatus(id, "Cancelled", userID, "发票取消")
 }
 
-// updateInvoiceStatus 更新发票状态
+// updateInvoiceStatus 更新发票状态，提交与取消已提交的发票时同步来源明细的开票数量与销售订单履约状态
 func (s *SalesInvoiceServiceImpl) updateInvoiceStatus(id uint, status string, userID uint, reason string) (*dto.SalesInvoiceResponse, error) {
-	invoice, err := s.repository.GetByID(nil, id)
+	ctx := context.Background()
+	invoice, err := s.repository.GetWithItems(ctx, id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("销售发票不存在")
-		}
 		return nil, fmt.Errorf("获取销售发票失败: %v", err)
 	}
+	if invoice == nil {
+		return nil, errors.New("销售发票不存在")
+	}
 
 	// 检查状态转换是否合法
 	if !s.isValidStatusTransition(invoice.DocStatus, status) {
 		return nil, fmt.Errorf("不能从 %s 状态转换到 %s 状态", invoice.DocStatus, status)
 	}
 
-	if err := s.repository.UpdateStatus(nil, id, status); err != nil {
+	if err := s.repository.ChangeDocStatus(ctx, invoice, status, userID, reason); err != nil {
+		if errors.Is(err, repositories.ErrOverBilling) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("更新发票状态失败: %v", err)
 	}
 
+	// 草稿发票的取消不影响开票数量
+	if status == "Submitted" || invoice.DocStatus == "Submitted" {
+		s.syncSalesOrders(ctx, invoice.ID, userID)
+	}
+
 	return s.GetSalesInvoice(id)
 }
 
+// syncSalesOrders 按最新的开票数量同步发票所涉及销售订单的履约状态，发票状态已变更，同步失败只记录日志
+func (s *SalesInvoiceServiceImpl) syncSalesOrders(ctx context.Context, invoiceID, userID uint) {
+	salesOrderIDs, err := s.repository.GetSalesOrderIDs(ctx, invoiceID)
+	if err != nil {
+		utils.LogError("获取发票关联的销售订单失败", utils.Uint("invoice_id", invoiceID), utils.ErrorField(err))
+		return
+	}
+	for _, salesOrderID := range salesOrderIDs {
+		if err := s.salesOrderService.SyncFulfilmentStatus(ctx, salesOrderID, userID); err != nil {
+			utils.LogError("同步销售订单履约状态失败",
+				utils.Uint("invoice_id", invoiceID),
+				utils.Uint("sales_order_id", salesOrderID),
+				utils.ErrorField(err),
+			)
+		}
+	}
+}
+
 // isValidStatusTransition 检查状态转换是否合法
 func (s *SalesInvoiceServiceImpl) isValidStatusTransition(from, to string) bool {
 	validTransitions := map[string][]string{
@@ -382,6 +441,7 @@ func (s *SalesInvoiceServiceImpl) generateInvoiceNumber() (string, error) {
 func (s *SalesInvoiceServiceImpl) convertToSalesInvoiceResponse(invoice *models.SalesInvoice) *dto.SalesInvoiceResponse {
 	response := &dto.SalesInvoiceResponse{
 		ID:                invoice.ID,
+		CompanyID:         invoice.CompanyID,
 		InvoiceNumber:     invoice.InvoiceNumber,
 		CustomerID:        invoice.CustomerID,
 		SalesOrderID:      invoice.SalesOrderID,
@@ -444,6 +504,8 @@ func (s *SalesInvoiceServiceImpl) convertToSalesInvoiceResponse(invoice *models.
 			Description:        item.Description,
 			Quantity:           item.Quantity,
 			UOM:                item.UOM,
+			ConversionFactor:   item.ConversionFactor,
+			StockUOM:           item.StockUOM,
 			Rate:               item.Rate,
 			Amount:             item.Amount,
 			DiscountPercentage: item.DiscountPercentage,
@@ -604,19 +666,30 @@ func (s *SalesInvoiceServiceImpl) AddPayment(ctx *gin.Context, invoiceID uint, r
 
 	// 创建 PaymentEntry 记录
 	paymentEntry := &models.PaymentEntry{
-		PaymentType:    "Receive", // 销售发票收款
-		PartyType:      "Customer",
-		PartyID:        invoice.CustomerID,
-		PostingDate:    req.PaymentDate,
-		PaidAmount:     0,          // 我们收到的金额
-		ReceivedAmount: req.Amount, // 客户支付的金额
-		Currency:       req.Currency,
-		ExchangeRate:   req.ExchangeRate,
-		BankAccountID:  req.BankAccountID,
-		Reference:      req.ReferenceNumber,
-		Remarks:        req.Notes,
-		Status:         "submitted",
-		IsPosted:       true,
+		CompanyID:       invoice.CompanyID,
+		PaymentType:     models.PaymentTypeReceive, // 销售发票收款
+		PartyType:       "Customer",
+		PartyID:         invoice.CustomerID,
+		PostingDate:     req.PaymentDate,
+		PaidAmount:      0,          // 我们收到的金额
+		ReceivedAmount:  req.Amount, // 客户支付的金额
+		AllocatedAmount: req.Amount, // 全额核销到本发票
+		Currency:        req.Currency,
+		ExchangeRate:    req.ExchangeRate,
+		PaymentMethod:   req.PaymentMethod,
+		BankAccountID:   req.BankAccountID,
+		Reference:       req.ReferenceNumber,
+		Remarks:         req.Notes,
+		Status:          "submitted",
+		IsPosted:        true,
+		Allocations: []models.PaymentAllocation{{
+			ReferenceType:   models.AllocationRefSalesInvoice,
+			ReferenceID:     invoiceID,
+			ReferenceNumber: invoice.InvoiceNumber,
+			AllocatedAmount: req.Amount,
+			AllocationDate:  req.PaymentDate,
+			Status:          "applied",
+		}},
 	}
 
 	// 设置过账时间
@@ -0,0 +1,514 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// defaultPaymentTermsDays 未指定付款期限时按 30 天推算到期日，与发票模型默认值一致
+const defaultPaymentTermsDays = 30
+
+// invoiceSourceLine 可开票的来源明细：按订单开票时为订单明细，按送货单开票时为送货单明细及其订单明细
+type invoiceSourceLine struct {
+	sourceID     uint
+	orderItem    *models.SalesOrderItem
+	deliveryItem *models.DeliveryNoteItem
+	unbilled     float64
+	quantity     float64
+}
+
+// invoiceHeader 由来源单据带出的发票抬头信息
+type invoiceHeader struct {
+	companyID      uint
+	customer       *models.Customer
+	salesOrderID   *uint
+	deliveryNoteID *uint
+	currency       string
+	exchangeRate   float64
+	paymentTerms   string
+	notes          string
+	salesOrders    map[uint]*models.SalesOrder // 分摊整单折扣与整单税额的来源订单
+	pendingOrder   map[uint]float64            // 订单明细被其他草稿发票占用的数量，用于判断本次开票后订单是否全部开票
+}
+
+// CreateFromSalesOrder 按销售订单开具草稿销售发票：未选择明细时开具全部未开票数量，
+// 带出订单明细的单价、折扣、税率与付款条款。已开票数量与其他草稿发票占用的数量不再开具
+func (s *SalesInvoiceServiceImpl) CreateFromSalesOrder(ctx context.Context, req *dto.SalesInvoiceFromSalesOrderRequest, userID uint) (*dto.SalesInvoiceResponse, error) {
+	salesOrder, err := s.salesOrderRepository.GetWithItems(ctx, req.SalesOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("获取销售订单失败: %w", err)
+	}
+	if salesOrder == nil {
+		return nil, errors.New("销售订单不存在")
+	}
+	if !IsSalesOrderBillable(salesOrder.Status) {
+		return nil, fmt.Errorf("销售订单状态为 %s，不能开票", salesOrder.Status)
+	}
+
+	itemIDs := make([]uint, 0, len(salesOrder.Items))
+	for _, item := range salesOrder.Items {
+		itemIDs = append(itemIDs, item.ID)
+	}
+	pending, err := s.repository.GetDraftQuantitiesBySalesOrderItem(ctx, itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取草稿发票开票数量失败: %w", err)
+	}
+
+	lines := make([]invoiceSourceLine, 0, len(salesOrder.Items))
+	for i := range salesOrder.Items {
+		item := &salesOrder.Items[i]
+		lines = append(lines, invoiceSourceLine{
+			sourceID:  item.ID,
+			orderItem: item,
+			unbilled:  billableQuantity(item) - item.BilledQty - pending[item.ID],
+		})
+	}
+	picks, err := selectInvoiceLines(lines, req.Items, "销售订单")
+	if err != nil {
+		return nil, err
+	}
+
+	header := invoiceHeader{
+		companyID:    salesOrder.CompanyID,
+		customer:     &salesOrder.Customer,
+		salesOrderID: &salesOrder.ID,
+		currency:     salesOrder.Currency,
+		exchangeRate: salesOrder.ExchangeRate,
+		paymentTerms: salesOrder.Terms,
+		notes:        req.Notes,
+		salesOrders:  map[uint]*models.SalesOrder{salesOrder.ID: salesOrder},
+		pendingOrder: pending,
+	}
+	invoice, err := s.buildSourceInvoice(ctx, header, picks, req.InvoiceDate, req.PostingDate, req.DueDate, req.PaymentTermsDays, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.Create(ctx, invoice); err != nil {
+		return nil, fmt.Errorf("创建销售发票失败: %w", err)
+	}
+
+	utils.Info("按销售订单开具销售发票",
+		utils.Uint("sales_order_id", salesOrder.ID),
+		utils.Uint("invoice_id", invoice.ID),
+		utils.String("invoice_number", invoice.InvoiceNumber),
+		utils.Uint("created_by", userID),
+	)
+
+	return s.GetSalesInvoice(invoice.ID)
+}
+
+// CreateFromDeliveryNotes 按已提交的送货单开具草稿销售发票，多张送货单须属于同一客户并合并为一张发票。
+// 开票数量不超过送货单明细与对应订单明细的未开票数量，单价、折扣与税率取自订单明细
+func (s *SalesInvoiceServiceImpl) CreateFromDeliveryNotes(ctx context.Context, req *dto.SalesInvoiceFromDeliveryNoteRequest, userID uint) (*dto.SalesInvoiceResponse, error) {
+	var deliveryNotes []*models.DeliveryNote
+	seen := make(map[uint]bool, len(req.DeliveryNoteIDs))
+	for _, id := range req.DeliveryNoteIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		deliveryNote, err := s.deliveryNoteRepository.GetWithItems(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("获取送货单失败: %w", err)
+		}
+		if deliveryNote == nil {
+			return nil, fmt.Errorf("送货单 %d 不存在", id)
+		}
+		if deliveryNote.Status != models.DeliveryNoteStatusSubmitted && deliveryNote.Status != models.DeliveryNoteStatusDelivered {
+			return nil, fmt.Errorf("送货单 %s 状态为 %s，只有已提交或已送达的送货单才能开票", deliveryNote.DeliveryNumber, deliveryNote.Status)
+		}
+		if len(deliveryNotes) > 0 && deliveryNote.CustomerID != deliveryNotes[0].CustomerID {
+			return nil, fmt.Errorf("送货单 %s 与 %s 不属于同一客户，不能合并开票", deliveryNote.DeliveryNumber, deliveryNotes[0].DeliveryNumber)
+		}
+		deliveryNotes = append(deliveryNotes, deliveryNote)
+	}
+
+	// 加载送货单对应的销售订单，开票价格与付款条款取自订单
+	salesOrders := make(map[uint]*models.SalesOrder)
+	orderItems := make(map[uint]*models.SalesOrderItem)
+	var deliveryItemIDs, orderItemIDs []uint
+	for _, deliveryNote := range deliveryNotes {
+		if deliveryNote.SalesOrderID == nil {
+			return nil, fmt.Errorf("送货单 %s 未关联销售订单，无法带出开票价格", deliveryNote.DeliveryNumber)
+		}
+		if _, ok := salesOrders[*deliveryNote.SalesOrderID]; !ok {
+			salesOrder, err := s.salesOrderRepository.GetWithItems(ctx, *deliveryNote.SalesOrderID)
+			if err != nil {
+				return nil, fmt.Errorf("获取销售订单失败: %w", err)
+			}
+			if salesOrder == nil {
+				return nil, fmt.Errorf("送货单 %s 关联的销售订单不存在", deliveryNote.DeliveryNumber)
+			}
+			if !IsSalesOrderBillable(salesOrder.Status) {
+				return nil, fmt.Errorf("销售订单 %s 状态为 %s，不能开票", salesOrder.OrderNumber, salesOrder.Status)
+			}
+			salesOrders[salesOrder.ID] = salesOrder
+			for i := range salesOrder.Items {
+				orderItems[salesOrder.Items[i].ID] = &salesOrder.Items[i]
+				orderItemIDs = append(orderItemIDs, salesOrder.Items[i].ID)
+			}
+		}
+		for _, item := range deliveryNote.Items {
+			deliveryItemIDs = append(deliveryItemIDs, item.ID)
+		}
+	}
+
+	// 合并开票的订单须使用相同币种与付款条款，汇率取第一张送货单对应的订单
+	first := salesOrders[*deliveryNotes[0].SalesOrderID]
+	header := invoiceHeader{
+		companyID:    deliveryNotes[0].CompanyID,
+		customer:     &deliveryNotes[0].Customer,
+		currency:     first.Currency,
+		exchangeRate: first.ExchangeRate,
+		paymentTerms: first.Terms,
+		notes:        req.Notes,
+		salesOrders:  salesOrders,
+	}
+	for _, salesOrder := range salesOrders {
+		if salesOrder.Currency != first.Currency {
+			return nil, fmt.Errorf("销售订单 %s 币种 %s 与 %s 币种 %s 不一致，不能合并开票",
+				salesOrder.OrderNumber, salesOrder.Currency, first.OrderNumber, first.Currency)
+		}
+		if salesOrder.Terms != first.Terms {
+			return nil, errors.New("送货单对应的销售订单付款条款不一致，不能合并开票")
+		}
+	}
+	if len(salesOrders) == 1 {
+		header.salesOrderID = deliveryNotes[0].SalesOrderID
+	}
+	if len(deliveryNotes) == 1 {
+		header.deliveryNoteID = &deliveryNotes[0].ID
+	} else if header.notes == "" {
+		numbers := make([]string, 0, len(deliveryNotes))
+		for _, deliveryNote := range deliveryNotes {
+			numbers = append(numbers, deliveryNote.DeliveryNumber)
+		}
+		header.notes = "合并开票送货单：" + strings.Join(numbers, ", ")
+	}
+
+	pendingDelivery, err := s.repository.GetDraftQuantitiesByDeliveryNoteItem(ctx, deliveryItemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取草稿发票开票数量失败: %w", err)
+	}
+	pendingOrder, err := s.repository.GetDraftQuantitiesBySalesOrderItem(ctx, orderItemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取草稿发票开票数量失败: %w", err)
+	}
+
+	// 同一订单明细可能分多张送货单发出，订单明细的剩余可开票数量按送货单明细顺序依次分配
+	orderRemaining := make(map[uint]float64, len(orderItems))
+	for id, item := range orderItems {
+		orderRemaining[id] = billableQuantity(item) - item.BilledQty - pendingOrder[id]
+	}
+	var lines []invoiceSourceLine
+	for _, deliveryNote := range deliveryNotes {
+		for i := range deliveryNote.Items {
+			item := &deliveryNote.Items[i]
+			if item.SalesOrderItemID == nil {
+				return nil, fmt.Errorf("送货单 %s 的明细 %d 未关联销售订单明细，无法带出开票价格", deliveryNote.DeliveryNumber, item.ID)
+			}
+			orderItem, ok := orderItems[*item.SalesOrderItemID]
+			if !ok || orderItem.SalesOrderID != *deliveryNote.SalesOrderID {
+				return nil, fmt.Errorf("送货单 %s 的明细 %d 不属于关联的销售订单", deliveryNote.DeliveryNumber, item.ID)
+			}
+
+			unbilled := item.StockQuantity() - item.BilledQty - pendingDelivery[item.ID]
+			if remaining := orderRemaining[orderItem.ID]; remaining < unbilled {
+				unbilled = remaining
+			}
+			if unbilled > 0 {
+				orderRemaining[orderItem.ID] -= unbilled
+			}
+			lines = append(lines, invoiceSourceLine{
+				sourceID:     item.ID,
+				orderItem:    orderItem,
+				deliveryItem: item,
+				unbilled:     unbilled,
+			})
+		}
+	}
+	picks, err := selectInvoiceLines(lines, req.Items, "送货单")
+	if err != nil {
+		return nil, err
+	}
+
+	header.pendingOrder = pendingOrder
+	invoice, err := s.buildSourceInvoice(ctx, header, picks, req.InvoiceDate, req.PostingDate, req.DueDate, req.PaymentTermsDays, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.Create(ctx, invoice); err != nil {
+		return nil, fmt.Errorf("创建销售发票失败: %w", err)
+	}
+
+	utils.Info("按送货单开具销售发票",
+		utils.Int("delivery_note_count", len(deliveryNotes)),
+		utils.Uint("invoice_id", invoice.ID),
+		utils.String("invoice_number", invoice.InvoiceNumber),
+		utils.Uint("created_by", userID),
+	)
+
+	return s.GetSalesInvoice(invoice.ID)
+}
+
+// billableQuantity 订单明细的可开票数量为订单数量与已发货数量中的较大者，超发部分同样可以开票
+func billableQuantity(item *models.SalesOrderItem) float64 {
+	if item.DeliveredQty > item.Quantity {
+		return item.DeliveredQty
+	}
+	return item.Quantity
+}
+
+// selectInvoiceLines 确定开票的来源明细与数量：未选择明细时开具全部未开票数量，
+// 选择的数量为空时开具该行全部未开票数量，不能超过未开票数量
+func selectInvoiceLines(lines []invoiceSourceLine, selected []dto.SalesInvoiceSourceItemRequest, sourceName string) ([]invoiceSourceLine, error) {
+	var picks []invoiceSourceLine
+	if len(selected) == 0 {
+		for _, line := range lines {
+			if line.unbilled > stockQuantityTolerance {
+				line.quantity = line.unbilled
+				picks = append(picks, line)
+			}
+		}
+		if len(picks) == 0 {
+			return nil, fmt.Errorf("%s没有未开票的明细", sourceName)
+		}
+		return picks, nil
+	}
+
+	byID := make(map[uint]invoiceSourceLine, len(lines))
+	for _, line := range lines {
+		byID[line.sourceID] = line
+	}
+	seen := make(map[uint]bool, len(selected))
+	for _, sel := range selected {
+		line, ok := byID[sel.SourceItemID]
+		if !ok {
+			return nil, fmt.Errorf("明细 %d 不属于所选%s", sel.SourceItemID, sourceName)
+		}
+		if seen[sel.SourceItemID] {
+			return nil, fmt.Errorf("%s明细 %d 重复", sourceName, sel.SourceItemID)
+		}
+		seen[sel.SourceItemID] = true
+
+		if line.unbilled <= stockQuantityTolerance {
+			return nil, fmt.Errorf("%s明细 %d 已全部开票", sourceName, sel.SourceItemID)
+		}
+		line.quantity = sel.Quantity
+		if line.quantity == 0 {
+			line.quantity = line.unbilled
+		}
+		if line.quantity > line.unbilled+stockQuantityTolerance {
+			return nil, fmt.Errorf("%s明细 %d 开票数量 %g 超过未开票数量 %g", sourceName, sel.SourceItemID, line.quantity, line.unbilled)
+		}
+		picks = append(picks, line)
+	}
+	return picks, nil
+}
+
+// buildSourceInvoice 按来源明细生成草稿发票：币种、汇率取自订单，单价、折扣与税率取自订单明细，逐行按币种精度舍入后再汇总；
+// 订单的整单折扣与整单税额按开票金额占订单金额的比例分摊，使订单全部开票的发票承担其余未分摊的部分，避免舍入差额遗留。
+// 到期日未指定时按开票日期加付款期限天数推算
+func (s *SalesInvoiceServiceImpl) buildSourceInvoice(ctx context.Context, header invoiceHeader, picks []invoiceSourceLine, invoiceDate, postingDate, dueDate *time.Time, paymentTermsDays *int, userID uint) (*models.SalesInvoice, error) {
+	invoiceNumber, err := s.generateInvoiceNumber()
+	if err != nil {
+		return nil, fmt.Errorf("生成发票编号失败: %v", err)
+	}
+
+	invoice := &models.SalesInvoice{
+		CompanyID:        header.companyID,
+		InvoiceNumber:    invoiceNumber,
+		CustomerID:       header.customer.ID,
+		SalesOrderID:     header.salesOrderID,
+		DeliveryNoteID:   header.deliveryNoteID,
+		InvoiceDate:      time.Now(),
+		DocStatus:        "Draft",
+		PaymentStatus:    "Unpaid",
+		Currency:         header.currency,
+		ExchangeRate:     header.exchangeRate,
+		BillingAddress:   header.customer.Address,
+		PaymentTerms:     header.paymentTerms,
+		PaymentTermsDays: defaultPaymentTermsDays,
+		Territory:        header.customer.Territory,
+		Notes:            header.notes,
+		CreatedBy:        userID,
+		AuditableModel: models.AuditableModel{
+			CreatedBy: userID,
+			UpdatedBy: userID,
+		},
+	}
+	if invoiceDate != nil && !invoiceDate.IsZero() {
+		invoice.InvoiceDate = *invoiceDate
+	}
+	invoice.PostingDate = invoice.InvoiceDate
+	if postingDate != nil && !postingDate.IsZero() {
+		invoice.PostingDate = *postingDate
+	}
+	if paymentTermsDays != nil {
+		invoice.PaymentTermsDays = *paymentTermsDays
+	}
+	invoice.DueDate = invoice.InvoiceDate.AddDate(0, 0, invoice.PaymentTermsDays)
+	if dueDate != nil && !dueDate.IsZero() {
+		if models.StartOfDay(*dueDate).Before(models.StartOfDay(invoice.InvoiceDate)) {
+			return nil, errors.New("到期日不能早于开票日期")
+		}
+		invoice.DueDate = *dueDate
+	}
+	if invoice.Currency == "" {
+		invoice.Currency = models.DefaultCurrency
+	}
+	if invoice.ExchangeRate <= 0 {
+		invoice.ExchangeRate = 1
+	}
+
+	billedAmounts := make(map[uint]models.Money, len(header.salesOrders))
+	pickedQuantities := make(map[uint]float64, len(picks))
+	for _, pick := range picks {
+		orderItem := pick.orderItem
+		rate := orderItem.Rate
+		if rate.IsZero() {
+			rate = orderItem.PriceListRate
+		}
+		totals, err := calculateLineTotals(rate, pick.quantity, orderItem.DiscountRate, orderItem.TaxRate, invoice.Currency)
+		if err != nil {
+			return nil, err
+		}
+		amount, discountAmount, netAmount, taxAmount := totals.amount, totals.discount, totals.net, totals.tax
+
+		orderItemID := orderItem.ID
+		item := models.SalesInvoiceItem{
+			SalesOrderItemID:   &orderItemID,
+			ItemID:             orderItem.ItemID,
+			ItemCode:           orderItem.Item.Code,
+			ItemName:           orderItem.Item.Name,
+			Description:        orderItem.Description,
+			Quantity:           pick.quantity,
+			UOM:                orderItem.Item.Unit,
+			ConversionFactor:   1,
+			StockUOM:           orderItem.Item.Unit,
+			Rate:               rate,
+			PriceListRate:      orderItem.PriceListRate,
+			Amount:             amount,
+			DiscountPercentage: orderItem.DiscountRate,
+			DiscountAmount:     discountAmount,
+			TaxRate:            orderItem.TaxRate,
+			TaxAmount:          taxAmount,
+			NetRate:            totals.netRate,
+			NetAmount:          netAmount + taxAmount,
+			WarehouseID:        orderItem.WarehouseID,
+			AuditableModel: models.AuditableModel{
+				CreatedBy: userID,
+				UpdatedBy: userID,
+			},
+		}
+		if deliveryItem := pick.deliveryItem; deliveryItem != nil {
+			deliveryItemID := deliveryItem.ID
+			item.DeliveryNoteItemID = &deliveryItemID
+			item.BatchNo = deliveryItem.BatchNo
+			item.SerialNo = deliveryItem.SerialNo
+			if deliveryItem.WarehouseID != nil {
+				item.WarehouseID = deliveryItem.WarehouseID
+			}
+			if deliveryItem.Description != "" {
+				item.Description = deliveryItem.Description
+			}
+		}
+
+		invoice.Items = append(invoice.Items, item)
+		invoice.SubTotal += amount
+		invoice.DiscountAmount += discountAmount
+		invoice.TaxAmount += taxAmount
+		invoice.GrandTotal += netAmount + taxAmount
+		billedAmounts[orderItem.SalesOrderID] += amount
+		pickedQuantities[orderItem.ID] += pick.quantity
+	}
+
+	for orderID, billedAmount := range billedAmounts {
+		salesOrder, ok := header.salesOrders[orderID]
+		if !ok || salesOrder.TotalAmount.IsZero() {
+			continue
+		}
+		orderDiscount, orderTax := orderLevelAdjustments(salesOrder)
+		var discountAmount, taxAmount models.Money
+		if orderFullyBilled(salesOrder, header.pendingOrder, pickedQuantities) {
+			billedDiscount, billedTax, err := s.billedOrderAdjustments(ctx, salesOrder, orderDiscount, orderTax, invoice.Currency)
+			if err != nil {
+				return nil, err
+			}
+			discountAmount, taxAmount = orderDiscount-billedDiscount, orderTax-billedTax
+		} else {
+			discountAmount, taxAmount, err = prorateOrderAdjustments(orderDiscount, orderTax, billedAmount, salesOrder.TotalAmount, invoice.Currency)
+			if err != nil {
+				return nil, err
+			}
+		}
+		invoice.DiscountAmount += discountAmount
+		invoice.TaxAmount += taxAmount
+		invoice.GrandTotal += taxAmount - discountAmount
+	}
+	invoice.OutstandingAmount = invoice.GrandTotal
+
+	return invoice, nil
+}
+
+// prorateOrderAdjustments 按开票金额占订单金额的比例分摊整单折扣与整单税额，按币种精度舍入
+func prorateOrderAdjustments(orderDiscount, orderTax, billedAmount, orderTotal models.Money, currency string) (discount, tax models.Money, err error) {
+	share := billedAmount.Ratio(orderTotal)
+	if discount, err = orderDiscount.Mul(share); err != nil {
+		return 0, 0, err
+	}
+	if tax, err = orderTax.Mul(share); err != nil {
+		return 0, 0, err
+	}
+	return discount.RoundCurrency(currency), tax.RoundCurrency(currency), nil
+}
+
+// orderFullyBilled 计入已开票、其他草稿发票占用与本次开票的数量后，订单每行是否均已全部开票
+func orderFullyBilled(salesOrder *models.SalesOrder, pending, picked map[uint]float64) bool {
+	for i := range salesOrder.Items {
+		item := &salesOrder.Items[i]
+		if item.BilledQty+pending[item.ID]+picked[item.ID] < billableQuantity(item)-stockQuantityTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// billedOrderAdjustments 按订单其他未取消发票（含草稿）的开票金额重新计算其已分摊的整单折扣与整单税额
+func (s *SalesInvoiceServiceImpl) billedOrderAdjustments(ctx context.Context, salesOrder *models.SalesOrder, orderDiscount, orderTax models.Money, currency string) (discount, tax models.Money, err error) {
+	itemIDs := make([]uint, 0, len(salesOrder.Items))
+	for _, item := range salesOrder.Items {
+		itemIDs = append(itemIDs, item.ID)
+	}
+	amounts, err := s.repository.GetBilledAmountsByInvoice(ctx, itemIDs)
+	if err != nil {
+		return 0, 0, fmt.Errorf("获取销售订单已开票金额失败: %w", err)
+	}
+	for _, amount := range amounts {
+		invoiceDiscount, invoiceTax, err := prorateOrderAdjustments(orderDiscount, orderTax, amount, salesOrder.TotalAmount, currency)
+		if err != nil {
+			return 0, 0, err
+		}
+		discount += invoiceDiscount
+		tax += invoiceTax
+	}
+	return discount, tax, nil
+}
+
+// orderLevelAdjustments 订单抬头折扣与税额超出明细合计的部分，即整单折扣与整单税额
+func orderLevelAdjustments(salesOrder *models.SalesOrder) (discount, tax models.Money) {
+	var lineDiscount, lineTax models.Money
+	for _, item := range salesOrder.Items {
+		lineDiscount += item.DiscountAmount
+		lineTax += item.TaxAmount
+	}
+	return salesOrder.DiscountAmount - lineDiscount, salesOrder.TaxAmount - lineTax
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// salesOrderTransitions 销售订单允许的状态转换，履约进度状态只能随发货与开票进度向前推进
+var salesOrderTransitions = map[string][]string{
+	models.SalesOrderStatusDraft: {models.SalesOrderStatusConfirmed, models.SalesOrderStatusCancelled},
+	models.SalesOrderStatusConfirmed: {
+		models.SalesOrderStatusPartiallyDelivered, models.SalesOrderStatusDelivered, models.SalesOrderStatusPartiallyBilled,
+		models.SalesOrderStatusCompleted, models.SalesOrderStatusOnHold, models.SalesOrderStatusClosed, models.SalesOrderStatusCancelled,
+	},
+	models.SalesOrderStatusPartiallyDelivered: {
+		models.SalesOrderStatusDelivered, models.SalesOrderStatusPartiallyBilled, models.SalesOrderStatusCompleted,
+		models.SalesOrderStatusOnHold, models.SalesOrderStatusClosed,
+	},
+	models.SalesOrderStatusDelivered: {
+		models.SalesOrderStatusPartiallyBilled, models.SalesOrderStatusCompleted, models.SalesOrderStatusOnHold, models.SalesOrderStatusClosed,
+	},
+	models.SalesOrderStatusPartiallyBilled: {models.SalesOrderStatusCompleted, models.SalesOrderStatusOnHold, models.SalesOrderStatusClosed},
+	models.SalesOrderStatusOnHold: {
+		models.SalesOrderStatusConfirmed, models.SalesOrderStatusPartiallyDelivered, models.SalesOrderStatusDelivered,
+		models.SalesOrderStatusPartiallyBilled, models.SalesOrderStatusClosed, models.SalesOrderStatusCancelled,
+	},
+	models.SalesOrderStatusCompleted: {}, // 已完成、已关闭、已取消为终态
+	models.SalesOrderStatusClosed:    {},
+	models.SalesOrderStatusCancelled: {},
+}
+
+// salesOrderOpenStatuses 占用客户信用额度的订单状态
+var salesOrderOpenStatuses = []string{
+	models.SalesOrderStatusConfirmed, models.SalesOrderStatusOnHold, models.SalesOrderStatusPartiallyDelivered,
+	models.SalesOrderStatusDelivered, models.SalesOrderStatusPartiallyBilled,
+}
+
+// NormalizeSalesOrderStatus 规范化销售订单状态，如 "Partially Delivered" 转为 "partially_delivered"，空状态视为草稿
+func NormalizeSalesOrderStatus(status string) string {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status == "" {
+		return models.SalesOrderStatusDraft
+	}
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(status)
+}
+
+// NextSalesOrderStatuses 返回销售订单当前状态允许变更到的状态
+func NextSalesOrderStatuses(status string) []string {
+	return salesOrderTransitions[NormalizeSalesOrderStatus(status)]
+}
+
+// IsSalesOrderDeliverable 订单已确认且尚未全部发货时才能创建送货单
+func IsSalesOrderDeliverable(status string) bool {
+	switch NormalizeSalesOrderStatus(status) {
+	case models.SalesOrderStatusConfirmed, models.SalesOrderStatusPartiallyDelivered, models.SalesOrderStatusPartiallyBilled:
+		return true
+	}
+	return false
+}
+
+// IsSalesOrderBillable 订单已确认且未结束时才能按订单或送货单开具销售发票
+func IsSalesOrderBillable(status string) bool {
+	switch NormalizeSalesOrderStatus(status) {
+	case models.SalesOrderStatusConfirmed, models.SalesOrderStatusPartiallyDelivered, models.SalesOrderStatusDelivered, models.SalesOrderStatusPartiallyBilled:
+		return true
+	}
+	return false
+}
+
+// isSalesOrderProgressStatus 由发货与开票进度决定的状态
+func isSalesOrderProgressStatus(status string) bool {
+	switch status {
+	case models.SalesOrderStatusConfirmed, models.SalesOrderStatusPartiallyDelivered, models.SalesOrderStatusDelivered,
+		models.SalesOrderStatusPartiallyBilled, models.SalesOrderStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// releasesReservation 订单暂停、取消、关闭或完成时释放剩余预留
+func releasesReservation(status string) bool {
+	switch status {
+	case models.SalesOrderStatusOnHold, models.SalesOrderStatusCancelled, models.SalesOrderStatusClosed, models.SalesOrderStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// validateSalesOrderTransition 验证销售订单状态转换
+func validateSalesOrderTransition(currentStatus, newStatus string) error {
+	allowedStatuses, exists := salesOrderTransitions[currentStatus]
+	if !exists {
+		return fmt.Errorf("无效的当前状态: %s", currentStatus)
+	}
+	if _, known := salesOrderTransitions[newStatus]; !known {
+		return fmt.Errorf("无效的目标状态: %s", newStatus)
+	}
+	if currentStatus == newStatus {
+		return fmt.Errorf("销售订单已是 %s 状态", currentStatus)
+	}
+
+	for _, status := range allowedStatuses {
+		if status == newStatus {
+			return nil
+		}
+	}
+	return fmt.Errorf("不能从状态 %s 转换到 %s", currentStatus, newStatus)
+}
+
+// fulfilmentStatus 按订单行的发货数量与开票数量推算履约进度状态
+func fulfilmentStatus(order *models.SalesOrder) string {
+	anyDelivered, allDelivered := false, len(order.Items) > 0
+	anyBilled, allBilled := false, len(order.Items) > 0
+	for _, item := range order.Items {
+		if item.DeliveredQty > stockQuantityTolerance {
+			anyDelivered = true
+		}
+		if item.Quantity-item.DeliveredQty > stockQuantityTolerance {
+			allDelivered = false
+		}
+		if item.BilledQty > stockQuantityTolerance {
+			anyBilled = true
+		}
+		if item.Quantity-item.BilledQty > stockQuantityTolerance {
+			allBilled = false
+		}
+	}
+
+	switch {
+	case allDelivered && allBilled:
+		return models.SalesOrderStatusCompleted
+	case anyBilled:
+		return models.SalesOrderStatusPartiallyBilled
+	case allDelivered:
+		return models.SalesOrderStatusDelivered
+	case anyDelivered:
+		return models.SalesOrderStatusPartiallyDelivered
+	}
+	return models.SalesOrderStatusConfirmed
+}
+
+// UpdateOrderStatus 按状态机变更销售订单状态，校验前置条件、执行预留与下游单据处理并记录状态日志
+func (s *SalesOrderServiceImpl) UpdateOrderStatus(ctx context.Context, id uint, req *dto.SalesOrderStatusUpdateRequest, userID uint) error {
+	salesOrder, err := s.salesOrderRepo.GetWithItems(ctx, id)
+	if err != nil {
+		return fmt.Errorf("获取销售订单失败: %w", err)
+	}
+	if salesOrder == nil {
+		return errors.New("销售订单不存在")
+	}
+
+	from := NormalizeSalesOrderStatus(salesOrder.Status)
+	to := NormalizeSalesOrderStatus(req.Status)
+	if err := validateSalesOrderTransition(from, to); err != nil {
+		return err
+	}
+	if err := s.checkStatusGuard(ctx, salesOrder, from, to); err != nil {
+		return err
+	}
+	return s.applyStatus(ctx, salesOrder, from, to, req.Reason, req.Notes, userID)
+}
+
+// SyncFulfilmentStatus 发货、开票或其冲销后按履约进度更新订单状态，冲销时可退回较早的进度状态；
+// 暂停及终态的订单不处理
+func (s *SalesOrderServiceImpl) SyncFulfilmentStatus(ctx context.Context, id uint, userID uint) error {
+	salesOrder, err := s.salesOrderRepo.GetWithItems(ctx, id)
+	if err != nil {
+		return fmt.Errorf("获取销售订单失败: %w", err)
+	}
+	if salesOrder == nil {
+		return errors.New("销售订单不存在")
+	}
+
+	from := NormalizeSalesOrderStatus(salesOrder.Status)
+	if !isSalesOrderProgressStatus(from) || from == models.SalesOrderStatusCompleted {
+		return nil
+	}
+	to := fulfilmentStatus(salesOrder)
+	if to == from {
+		return nil
+	}
+	return s.applyStatus(ctx, salesOrder, from, to, "根据发货与开票进度自动更新", "", userID)
+}
+
+// GetStatusLogs 获取销售订单的状态变更日志
+func (s *SalesOrderServiceImpl) GetStatusLogs(ctx context.Context, id uint) ([]dto.SalesOrderStatusLogResponse, error) {
+	logs, err := s.salesOrderRepo.GetStatusLogs(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取状态日志失败: %w", err)
+	}
+
+	responses := make([]dto.SalesOrderStatusLogResponse, 0, len(logs))
+	for _, log := range logs {
+		responses = append(responses, dto.SalesOrderStatusLogResponse{
+			ID:           log.ID,
+			SalesOrderID: log.SalesOrderID,
+			FromStatus:   log.FromStatus,
+			ToStatus:     log.ToStatus,
+			ChangedBy:    log.ChangedBy,
+			ChangedAt:    log.ChangedAt,
+			Reason:       log.Reason,
+			Notes:        log.Notes,
+		})
+	}
+	return responses, nil
+}
+
+// checkStatusGuard 校验状态转换的前置条件
+func (s *SalesOrderServiceImpl) checkStatusGuard(ctx context.Context, salesOrder *models.SalesOrder, from, to string) error {
+	switch {
+	case to == models.SalesOrderStatusConfirmed && from == models.SalesOrderStatusDraft:
+		if len(salesOrder.Items) == 0 {
+			return errors.New("销售订单没有明细，不能确认")
+		}
+		return s.checkCreditLimit(ctx, salesOrder)
+	case to == models.SalesOrderStatusCancelled:
+		for _, item := range salesOrder.Items {
+			if item.DeliveredQty > stockQuantityTolerance {
+				return errors.New("销售订单已有发货，不能取消，请改为关闭")
+			}
+		}
+		deliveries, invoices, err := s.salesOrderRepo.CountSubmittedDocuments(ctx, salesOrder.ID)
+		if err != nil {
+			return fmt.Errorf("检查下游单据失败: %w", err)
+		}
+		if deliveries > 0 || invoices > 0 {
+			return fmt.Errorf("销售订单已有 %d 张已提交的送货单和 %d 张已提交的销售发票，不能取消", deliveries, invoices)
+		}
+	case isSalesOrderProgressStatus(to):
+		// 履约进度状态（含暂停后恢复）必须与实际发货、开票进度一致
+		if actual := fulfilmentStatus(salesOrder); actual != to {
+			return fmt.Errorf("销售订单当前履约进度为 %s，不能设置为 %s", actual, to)
+		}
+	}
+	return nil
+}
+
+// checkCreditLimit 确认订单前检查客户信用额度，信用额度为 0 表示不限制
+func (s *SalesOrderServiceImpl) checkCreditLimit(ctx context.Context, salesOrder *models.SalesOrder) error {
+	customer := salesOrder.Customer
+	if !customer.CreditLimit.IsPositive() {
+		return nil
+	}
+
+	exposure, err := s.salesOrderRepo.GetCreditExposure(ctx, customer.ID, salesOrder.ID, salesOrderOpenStatuses)
+	if err != nil {
+		return fmt.Errorf("计算客户信用占用失败: %w", err)
+	}
+	if exposure.Add(salesOrder.GrandTotal) > customer.CreditLimit {
+		return fmt.Errorf("客户信用额度不足：信用额度 %s，已占用 %s，本单金额 %s",
+			customer.CreditLimit.StringFixed(2), exposure.StringFixed(2), salesOrder.GrandTotal.StringFixed(2))
+	}
+	return nil
+}
+
+// applyStatus 在同一事务中执行状态转换及其预留与下游单据处理，随后写入审计日志
+func (s *SalesOrderServiceImpl) applyStatus(ctx context.Context, salesOrder *models.SalesOrder, from, to, reason, notes string, userID uint) error {
+	id := salesOrder.ID
+	change := &repositories.SalesOrderStatusChange{
+		Log: &models.SalesOrderStatusLog{
+			SalesOrderID: id,
+			FromStatus:   from,
+			ToStatus:     to,
+			ChangedBy:    userID,
+			ChangedAt:    time.Now(),
+			Reason:       reason,
+			Notes:        notes,
+		},
+		CurrentStatus: salesOrder.Status,
+		// 确认或恢复订单时为指定了发货仓库的未发货订单行预留库存；暂停、取消、关闭或完成时释放剩余预留
+		ReserveStock:          to == models.SalesOrderStatusConfirmed || (from == models.SalesOrderStatusOnHold && isSalesOrderProgressStatus(to)),
+		ReleaseReservations:   releasesReservation(to),
+		CancelDraftDeliveries: to == models.SalesOrderStatusCancelled || to == models.SalesOrderStatusClosed,
+		CancelDraftInvoices:   to == models.SalesOrderStatusCancelled,
+	}
+	reservations, err := s.salesOrderRepo.ChangeStatus(ctx, change)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSalesOrderStatusChanged) {
+			return err
+		}
+		return fmt.Errorf("更新订单状态失败: %w", err)
+	}
+
+	if change.ReserveStock {
+		reserved := make(map[uint]bool, len(reservations))
+		for _, reservation := range reservations {
+			reserved[reservation.SalesOrderItemID] = true
+		}
+		for _, item := range salesOrder.Items {
+			if item.Quantity-item.DeliveredQty > stockQuantityTolerance && !reserved[item.ID] {
+				utils.Warn("销售订单行未指定发货仓库，未预留库存",
+					utils.Uint("order_id", id),
+					utils.Uint("order_item_id", item.ID),
+					utils.Uint("item_id", item.ItemID),
+				)
+			}
+		}
+	}
+
+	utils.Info("销售订单状态变更",
+		utils.Uint("order_id", id),
+		utils.String("from_status", from),
+		utils.String("to_status", to),
+		utils.Uint("user_id", userID),
+	)
+
+	if s.auditLogService != nil {
+		description := fmt.Sprintf("销售订单 %s 状态变更: %s -> %s", salesOrder.OrderNumber, from, to)
+		oldValues := map[string]interface{}{"status": from}
+		newValues := map[string]interface{}{"status": to, "reason": reason}
+		if err := s.auditLogService.LogAction(ctx, userID, "", "STATUS_CHANGE", "SALES_ORDER", fmt.Sprintf("%d", id), description, oldValues, newValues); err != nil {
+			utils.LogError("记录审计日志失败", utils.ErrorField(err))
+		}
+	}
+	return nil
+}
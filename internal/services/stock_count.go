@@ -0,0 +1,1045 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// 盘点默认参数
+const (
+	DefaultStockCountApprovalValue   = 1000.0 // 单行差异金额超过该值需要审批
+	DefaultStockCountApprovalPercent = 10.0   // 单行差异数量超过冻结数量的百分比需要审批
+	DefaultStockCountDifferenceCode  = "1901" // 盘点差异科目（待处理财产损溢）
+	DefaultCycleCountADays           = 30
+	DefaultCycleCountBDays           = 90
+	DefaultCycleCountCDays           = 180
+	DefaultCycleCountMaxItems        = 50
+)
+
+// StockCountService 盘点服务接口
+type StockCountService interface {
+	CreateStockCount(ctx context.Context, req *dto.StockCountCreateRequest, userID uint) (*dto.StockCountResponse, error)
+	GetStockCount(ctx context.Context, id uint) (*dto.StockCountResponse, error)
+	ListStockCounts(ctx context.Context, req *dto.StockCountListRequest) ([]dto.StockCountResponse, int64, error)
+	EnterCounts(ctx context.Context, id uint, req *dto.StockCountEntryRequest, userID uint) (*dto.StockCountResponse, error)
+	ImportScans(ctx context.Context, id uint, req *dto.StockCountScanRequest, data []byte, userID uint) (*dto.StockCountScanResult, error)
+	SubmitStockCount(ctx context.Context, id uint, userID uint) (*dto.StockCountResponse, error)
+	ApproveStockCount(ctx context.Context, id uint, req *dto.StockCountApprovalRequest, userID uint) (*dto.StockCountResponse, error)
+	RejectStockCount(ctx context.Context, id uint, req *dto.StockCountApprovalRequest) (*dto.StockCountResponse, error)
+	PostStockCount(ctx context.Context, id uint, req *dto.StockCountPostRequest) (*dto.StockCountResponse, error)
+	CancelStockCount(ctx context.Context, id uint) (*dto.StockCountResponse, error)
+	PreviewCycleCount(ctx context.Context, req *dto.CycleCountRequest) (*dto.CycleCountResponse, error)
+	GenerateCycleCounts(ctx context.Context, req *dto.CycleCountRequest) (*dto.CycleCountResponse, error)
+}
+
+// StockCountServiceImpl 盘点服务实现
+type StockCountServiceImpl struct {
+	stockCountRepo repositories.StockCountRepository
+	locationRepo   repositories.LocationRepository
+	accountRepo    repositories.AccountRepository
+	companyRepo    repositories.CompanyRepository
+	reportService  InventoryReportService
+}
+
+// NewStockCountService 创建盘点服务实例
+func NewStockCountService(
+	stockCountRepo repositories.StockCountRepository,
+	locationRepo repositories.LocationRepository,
+	accountRepo repositories.AccountRepository,
+	companyRepo repositories.CompanyRepository,
+	reportService InventoryReportService,
+) StockCountService {
+	return &StockCountServiceImpl{
+		stockCountRepo: stockCountRepo,
+		locationRepo:   locationRepo,
+		accountRepo:    accountRepo,
+		companyRepo:    companyRepo,
+		reportService:  reportService,
+	}
+}
+
+// stockCountLineKey 盘点行的物料、库位与批次
+type stockCountLineKey struct {
+	itemID     uint
+	locationID uint
+	batchNo    string
+}
+
+// stockCountDraft 生成盘点单的参数
+type stockCountDraft struct {
+	companyID      uint
+	warehouseID    uint
+	countType      string
+	zone           string
+	abcClass       string
+	itemIDs        []uint
+	includeZero    bool
+	countDate      time.Time
+	valueThreshold models.Money
+	percent        float64
+	notes          string
+	createdBy      *uint
+}
+
+// CreateStockCount 生成盘点单：按范围读取当前账面数量并冻结，之后录入的实盘数量与冻结数量比较得出差异
+func (s *StockCountServiceImpl) CreateStockCount(ctx context.Context, req *dto.StockCountCreateRequest, userID uint) (*dto.StockCountResponse, error) {
+	companyID, err := resolveCompanyID(ctx, s.companyRepo, req.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	req.Zone = strings.TrimSpace(req.Zone)
+	switch req.CountType {
+	case models.StockCountTypeZone:
+		if req.Zone == "" {
+			return nil, errors.New("按库区盘点必须指定库区")
+		}
+	case models.StockCountTypeABC:
+		if req.ABCClass == "" {
+			return nil, errors.New("按 ABC 分类盘点必须指定分类")
+		}
+	}
+
+	draft := stockCountDraft{
+		companyID:      companyID,
+		warehouseID:    req.WarehouseID,
+		countType:      req.CountType,
+		itemIDs:        req.ItemIDs,
+		includeZero:    req.IncludeZero,
+		countDate:      truncateToDay(time.Now()),
+		valueThreshold: models.NewMoneyFromFloat(DefaultStockCountApprovalValue),
+		percent:        DefaultStockCountApprovalPercent,
+		notes:          req.Notes,
+		createdBy:      stockCountUser(userID),
+	}
+	if req.CountType == models.StockCountTypeZone {
+		draft.zone = req.Zone
+	}
+	if req.CountType == models.StockCountTypeABC {
+		draft.abcClass = req.ABCClass
+	}
+	if req.CountDate != nil {
+		draft.countDate = truncateToDay(*req.CountDate)
+	}
+	if req.ApprovalValueThreshold != nil {
+		draft.valueThreshold = *req.ApprovalValueThreshold
+	}
+	if req.ApprovalPercentThreshold != nil {
+		draft.percent = *req.ApprovalPercentThreshold
+	}
+
+	count, err := s.createCount(ctx, draft)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetStockCount(ctx, count.ID)
+}
+
+// createCount 校验仓库、冻结账面数量并保存盘点单
+func (s *StockCountServiceImpl) createCount(ctx context.Context, draft stockCountDraft) (*models.StockCount, error) {
+	warehouses, err := s.stockCountRepo.GetCountWarehouses(ctx, draft.warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("获取仓库失败: %w", err)
+	}
+	if len(warehouses) == 0 {
+		return nil, errors.New("仓库不存在或未启用")
+	}
+
+	lines, err := s.stockCountRepo.GetSnapshotLines(ctx, repositories.StockCountSnapshotFilter{
+		WarehouseID: draft.warehouseID,
+		Zone:        draft.zone,
+		ItemIDs:     draft.itemIDs,
+		IncludeZero: draft.includeZero,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取账面库存失败: %w", err)
+	}
+
+	classes, err := s.abcClasses(ctx, draft.warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]models.StockCountItem, 0, len(lines))
+	for _, line := range lines {
+		line.ABCClass = itemABCClass(classes, line.ItemID)
+		if draft.abcClass != "" && line.ABCClass != draft.abcClass {
+			continue
+		}
+		filtered = append(filtered, line)
+	}
+	if len(filtered) == 0 {
+		return nil, errors.New("盘点范围内没有需要盘点的库存")
+	}
+
+	now := time.Now()
+	count := &models.StockCount{
+		CompanyID:                draft.companyID,
+		CountNumber:              fmt.Sprintf("SC%s%06d", now.Format("20060102"), now.UnixNano()/1000%1000000),
+		WarehouseID:              draft.warehouseID,
+		CountType:                draft.countType,
+		Zone:                     draft.zone,
+		ABCClass:                 draft.abcClass,
+		Status:                   models.StockCountStatusCounting,
+		SnapshotAt:               now,
+		CountDate:                draft.countDate,
+		ApprovalValueThreshold:   draft.valueThreshold,
+		ApprovalPercentThreshold: draft.percent,
+		Notes:                    draft.notes,
+		CreatedBy:                draft.createdBy,
+		Items:                    filtered,
+	}
+	if err := s.stockCountRepo.Create(ctx, count); err != nil {
+		return nil, fmt.Errorf("创建盘点单失败: %w", err)
+	}
+
+	utils.Info("盘点单已生成",
+		utils.Uint("stock_count_id", count.ID),
+		utils.String("count_number", count.CountNumber),
+		utils.String("count_type", count.CountType),
+		utils.Uint("warehouse_id", count.WarehouseID),
+		utils.Int("lines", len(filtered)),
+	)
+	return count, nil
+}
+
+// GetStockCount 获取盘点单详情
+func (s *StockCountServiceImpl) GetStockCount(ctx context.Context, id uint) (*dto.StockCountResponse, error) {
+	count, err := s.loadCount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toStockCountResponse(count, true), nil
+}
+
+// ListStockCounts 分页获取盘点单
+func (s *StockCountServiceImpl) ListStockCounts(ctx context.Context, req *dto.StockCountListRequest) ([]dto.StockCountResponse, int64, error) {
+	filter := repositories.StockCountFilter{
+		WarehouseID: req.WarehouseID,
+		CountType:   req.CountType,
+		Status:      req.Status,
+	}
+	counts, total, err := s.stockCountRepo.ListStockCounts(ctx, filter, req.GetOffset(), req.GetLimit())
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取盘点单失败: %w", err)
+	}
+	responses := make([]dto.StockCountResponse, 0, len(counts))
+	for _, count := range counts {
+		responses = append(responses, *toStockCountResponse(count, false))
+	}
+	return responses, total, nil
+}
+
+// EnterCounts 录入实盘数量，覆盖盘点行已录入的数量；盘点单中没有的物料追加为账面数量 0 的盘点行
+func (s *StockCountServiceImpl) EnterCounts(ctx context.Context, id uint, req *dto.StockCountEntryRequest, userID uint) (*dto.StockCountResponse, error) {
+	count, err := s.loadCountForCounting(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[*models.StockCountItem]bool)
+	for i, entry := range req.Lines {
+		var line *models.StockCountItem
+		if entry.LineID != 0 {
+			line = findStockCountLine(count, entry.LineID)
+			if line == nil {
+				return nil, fmt.Errorf("第 %d 行: 盘点行 %d 不属于该盘点单", i+1, entry.LineID)
+			}
+		} else {
+			if entry.ItemID == 0 {
+				return nil, fmt.Errorf("第 %d 行: 必须指定盘点行或物料", i+1)
+			}
+			key := stockCountLineKey{itemID: entry.ItemID, locationID: derefUint(entry.LocationID), batchNo: strings.TrimSpace(entry.BatchNo)}
+			line, err = s.findOrAppendLine(ctx, count, key)
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 行: %w", i+1, err)
+			}
+		}
+
+		if err := setCountedQty(line, entry.CountedQty, userID); err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", i+1, err)
+		}
+		if entry.SerialNo != "" {
+			line.SerialNo = strings.Join(models.ParseSerialNos(entry.SerialNo), ",")
+		}
+		if entry.Notes != "" {
+			line.Notes = entry.Notes
+		}
+		changed[line] = true
+	}
+
+	if err := s.saveLines(ctx, count, changed); err != nil {
+		return nil, err
+	}
+	return s.GetStockCount(ctx, id)
+}
+
+// ImportScans 导入扫描枪数据：每条记录按物料编码、库位编码与批次号匹配盘点行，add 模式累加数量，
+// replace 模式以同一盘点行的上传数量合计覆盖实盘数量；无法识别的记录返回错误信息，不影响其余记录
+func (s *StockCountServiceImpl) ImportScans(ctx context.Context, id uint, req *dto.StockCountScanRequest, data []byte, userID uint) (*dto.StockCountScanResult, error) {
+	count, err := s.loadCountForCounting(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := parseStockCountScans(req.Format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.StockCountScanResult{Rows: len(rows)}
+	items := make(map[string]*models.Item)
+	locations := make(map[string]*models.Location)
+	scanned := make(map[*models.StockCountItem]float64)
+	var order []*models.StockCountItem
+	existing := len(count.Items)
+	for i, row := range rows {
+		code := strings.TrimSpace(row.ItemCode)
+		if code == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("第 %d 行: 缺少物料编码", i+1))
+			continue
+		}
+		quantity := 1.0
+		if row.Quantity != nil {
+			quantity = *row.Quantity
+		}
+		if quantity < 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("第 %d 行: 数量不能为负数", i+1))
+			continue
+		}
+
+		item, ok := items[code]
+		if !ok {
+			if item, err = s.stockCountRepo.GetItemByCode(ctx, code); err != nil {
+				return nil, fmt.Errorf("获取物料失败: %w", err)
+			}
+			items[code] = item
+		}
+		if item == nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("第 %d 行: 物料 %s 不存在", i+1, code))
+			continue
+		}
+
+		key := stockCountLineKey{itemID: item.ID, batchNo: strings.TrimSpace(row.BatchNo)}
+		if locationCode := strings.TrimSpace(row.LocationCode); locationCode != "" {
+			location, ok := locations[locationCode]
+			if !ok {
+				if location, err = s.locationRepo.GetByCode(ctx, count.WarehouseID, locationCode); err != nil {
+					return nil, fmt.Errorf("获取库位失败: %w", err)
+				}
+				locations[locationCode] = location
+			}
+			if location == nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("第 %d 行: 库位 %s 不存在", i+1, locationCode))
+				continue
+			}
+			key.locationID = location.ID
+		}
+
+		line, err := s.findOrAppendLine(ctx, count, key)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("第 %d 行: %s", i+1, err.Error()))
+			continue
+		}
+		if _, seen := scanned[line]; !seen {
+			order = append(order, line)
+		}
+		scanned[line] += quantity
+		result.Applied++
+	}
+
+	changed := make(map[*models.StockCountItem]bool, len(order))
+	for _, line := range order {
+		quantity := scanned[line]
+		if req.Mode != "replace" && line.CountedQty != nil {
+			quantity += *line.CountedQty
+		}
+		if err := setCountedQty(line, quantity, userID); err != nil {
+			return nil, err
+		}
+		changed[line] = true
+	}
+	if err := s.saveLines(ctx, count, changed); err != nil {
+		return nil, err
+	}
+	result.NewLines = len(count.Items) - existing
+	return result, nil
+}
+
+// SubmitStockCount 提交盘点结果：计算差异与估算金额，差异超过审批阈值时等待审批，否则自动确认
+func (s *StockCountServiceImpl) SubmitStockCount(ctx context.Context, id uint, userID uint) (*dto.StockCountResponse, error) {
+	count, err := s.loadCountForCounting(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var counted int
+	var gain, loss models.Money
+	approvalRequired := false
+	for i := range count.Items {
+		line := &count.Items[i]
+		if !line.IsCounted() {
+			continue
+		}
+		counted++
+		if line.VarianceQty == 0 {
+			continue
+		}
+		if err := validateStockCountLine(line); err != nil {
+			return nil, err
+		}
+		if line.VarianceValue.IsPositive() {
+			gain = gain.Add(line.VarianceValue)
+		} else {
+			loss = loss.Add(line.VarianceValue.Neg())
+		}
+		if exceedsStockCountThreshold(count, line) {
+			approvalRequired = true
+		}
+	}
+	if counted == 0 {
+		return nil, errors.New("尚未录入任何实盘数量")
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":            models.StockCountStatusApproved,
+		"approval_required": approvalRequired,
+		"gain_value":        gain,
+		"loss_value":        loss,
+		"submitted_at":      now,
+		"approved_by":       stockCountUser(userID),
+		"approved_at":       now,
+	}
+	if approvalRequired {
+		updates["status"] = models.StockCountStatusPendingApproval
+		updates["approved_by"] = nil
+		updates["approved_at"] = nil
+	}
+	if err := s.stockCountRepo.UpdateStockCount(ctx, count, updates); err != nil {
+		return nil, fmt.Errorf("提交盘点单失败: %w", err)
+	}
+	return s.GetStockCount(ctx, id)
+}
+
+// ApproveStockCount 审批通过超过阈值的盘点差异
+func (s *StockCountServiceImpl) ApproveStockCount(ctx context.Context, id uint, req *dto.StockCountApprovalRequest, userID uint) (*dto.StockCountResponse, error) {
+	count, err := s.loadCount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if count.Status != models.StockCountStatusPendingApproval {
+		return nil, errors.New("只有待审批的盘点单才能审批")
+	}
+
+	updates := map[string]interface{}{
+		"status":      models.StockCountStatusApproved,
+		"approved_by": stockCountUser(userID),
+		"approved_at": time.Now(),
+	}
+	if req.Notes != "" {
+		updates["notes"] = appendStockCountNote(count.Notes, "审批: "+req.Notes)
+	}
+	if err := s.stockCountRepo.UpdateStockCount(ctx, count, updates); err != nil {
+		return nil, fmt.Errorf("审批盘点单失败: %w", err)
+	}
+	return s.GetStockCount(ctx, id)
+}
+
+// RejectStockCount 驳回盘点差异，盘点单退回盘点中以便复盘
+func (s *StockCountServiceImpl) RejectStockCount(ctx context.Context, id uint, req *dto.StockCountApprovalRequest) (*dto.StockCountResponse, error) {
+	count, err := s.loadCount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if count.Status != models.StockCountStatusPendingApproval {
+		return nil, errors.New("只有待审批的盘点单才能驳回")
+	}
+
+	updates := map[string]interface{}{
+		"status":       models.StockCountStatusCounting,
+		"submitted_at": nil,
+	}
+	if req.Notes != "" {
+		updates["notes"] = appendStockCountNote(count.Notes, "驳回: "+req.Notes)
+	}
+	if err := s.stockCountRepo.UpdateStockCount(ctx, count, updates); err != nil {
+		return nil, fmt.Errorf("驳回盘点单失败: %w", err)
+	}
+	return s.GetStockCount(ctx, id)
+}
+
+// PostStockCount 将已确认的盘点差异过账为库存调整移动，并按实际调整金额生成总账凭证
+func (s *StockCountServiceImpl) PostStockCount(ctx context.Context, id uint, req *dto.StockCountPostRequest) (*dto.StockCountResponse, error) {
+	count, err := s.loadCount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if count.Status != models.StockCountStatusApproved {
+		return nil, errors.New("只有已确认的盘点单才能过账")
+	}
+
+	var voucher *repositories.StockCountVoucher
+	if count.GainValue.IsPositive() || count.LossValue.IsPositive() {
+		if err := ensurePeriodOpen(ctx, s.companyRepo, count.CompanyID, count.CountDate); err != nil {
+			return nil, err
+		}
+		inventoryCode := strings.TrimSpace(req.InventoryAccountCode)
+		if inventoryCode == "" {
+			inventoryCode = DefaultInventoryAccountCode
+		}
+		differenceCode := strings.TrimSpace(req.DifferenceAccountCode)
+		if differenceCode == "" {
+			differenceCode = DefaultStockCountDifferenceCode
+		}
+		inventoryAccount, err := s.accountRepo.GetByCode(ctx, count.CompanyID, inventoryCode)
+		if err != nil || inventoryAccount == nil {
+			return nil, fmt.Errorf("存货科目 %s 不存在", inventoryCode)
+		}
+		differenceAccount, err := s.accountRepo.GetByCode(ctx, count.CompanyID, differenceCode)
+		if err != nil || differenceAccount == nil {
+			return nil, fmt.Errorf("盘点差异科目 %s 不存在", differenceCode)
+		}
+		voucher = &repositories.StockCountVoucher{
+			CompanyID:           count.CompanyID,
+			InventoryAccountID:  inventoryAccount.ID,
+			DifferenceAccountID: differenceAccount.ID,
+			Date:                count.CountDate,
+			Description:         fmt.Sprintf("盘点差异 %s", count.CountNumber),
+		}
+	}
+
+	if err := s.stockCountRepo.PostStockCount(ctx, count, voucher); err != nil {
+		return nil, fmt.Errorf("盘点差异过账失败: %w", err)
+	}
+
+	utils.Info("盘点差异已过账",
+		utils.Uint("stock_count_id", count.ID),
+		utils.String("count_number", count.CountNumber),
+	)
+	return s.GetStockCount(ctx, id)
+}
+
+// CancelStockCount 取消未过账的盘点单
+func (s *StockCountServiceImpl) CancelStockCount(ctx context.Context, id uint) (*dto.StockCountResponse, error) {
+	count, err := s.loadCount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if count.Status == models.StockCountStatusPosted || count.Status == models.StockCountStatusCancelled {
+		return nil, errors.New("已过账或已取消的盘点单不能取消")
+	}
+	if err := s.stockCountRepo.UpdateStockCount(ctx, count, map[string]interface{}{
+		"status": models.StockCountStatusCancelled,
+	}); err != nil {
+		return nil, fmt.Errorf("取消盘点单失败: %w", err)
+	}
+	return s.GetStockCount(ctx, id)
+}
+
+// PreviewCycleCount 按 ABC 分类盘点周期列出各仓库到期应盘点的物料
+func (s *StockCountServiceImpl) PreviewCycleCount(ctx context.Context, req *dto.CycleCountRequest) (*dto.CycleCountResponse, error) {
+	return s.planCycleCount(ctx, req)
+}
+
+// GenerateCycleCounts 为每个有到期物料的仓库生成一张循环盘点单
+func (s *StockCountServiceImpl) GenerateCycleCounts(ctx context.Context, req *dto.CycleCountRequest) (*dto.CycleCountResponse, error) {
+	plan, err := s.planCycleCount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	countDate := truncateToDay(time.Now())
+	if req.CountDate != nil {
+		countDate = truncateToDay(*req.CountDate)
+	}
+	for i := range plan.Warehouses {
+		warehouse := &plan.Warehouses[i]
+		if len(warehouse.Items) == 0 {
+			continue
+		}
+		itemIDs := make([]uint, 0, len(warehouse.Items))
+		for _, item := range warehouse.Items {
+			itemIDs = append(itemIDs, item.ItemID)
+		}
+		count, err := s.createCount(ctx, stockCountDraft{
+			companyID:      models.DefaultCompanyID,
+			warehouseID:    warehouse.WarehouseID,
+			countType:      models.StockCountTypeCycle,
+			itemIDs:        itemIDs,
+			countDate:      countDate,
+			valueThreshold: models.NewMoneyFromFloat(DefaultStockCountApprovalValue),
+			percent:        DefaultStockCountApprovalPercent,
+			notes:          "循环盘点",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("仓库 %s 生成循环盘点单失败: %w", warehouse.WarehouseCode, err)
+		}
+		warehouse.StockCountID = &count.ID
+		warehouse.CountNumber = count.CountNumber
+		plan.Created++
+	}
+	return plan, nil
+}
+
+// planCycleCount 计算循环盘点计划：A、B、C 类物料分别按各自周期盘点，从未盘点或距上次盘点已满周期的物料到期；
+// 已在未完成盘点单中的物料跳过。按 A、B、C 类及逾期天数排序，每个仓库最多取 MaxItems 个
+func (s *StockCountServiceImpl) planCycleCount(ctx context.Context, req *dto.CycleCountRequest) (*dto.CycleCountResponse, error) {
+	response := &dto.CycleCountResponse{
+		ADays:      defaultInt(req.ADays, DefaultCycleCountADays),
+		BDays:      defaultInt(req.BDays, DefaultCycleCountBDays),
+		CDays:      defaultInt(req.CDays, DefaultCycleCountCDays),
+		Warehouses: []dto.CycleCountWarehousePlan{},
+	}
+	maxItems := defaultInt(req.MaxItems, DefaultCycleCountMaxItems)
+	frequency := map[string]int{"A": response.ADays, "B": response.BDays, "C": response.CDays}
+
+	warehouses, err := s.stockCountRepo.GetCountWarehouses(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("获取仓库失败: %w", err)
+	}
+	if req.WarehouseID != 0 && len(warehouses) == 0 {
+		return nil, errors.New("仓库不存在或未启用")
+	}
+
+	now := time.Now()
+	for _, warehouse := range warehouses {
+		items, err := s.stockCountRepo.GetStockedItems(ctx, warehouse.ID)
+		if err != nil {
+			return nil, fmt.Errorf("获取库存物料失败: %w", err)
+		}
+		if len(items) == 0 {
+			continue
+		}
+		classes, err := s.abcClasses(ctx, warehouse.ID)
+		if err != nil {
+			return nil, err
+		}
+		lastCounted, err := s.stockCountRepo.GetLastCountedAt(ctx, warehouse.ID)
+		if err != nil {
+			return nil, fmt.Errorf("获取盘点记录失败: %w", err)
+		}
+		open, err := s.stockCountRepo.GetOpenCountItemIDs(ctx, warehouse.ID)
+		if err != nil {
+			return nil, fmt.Errorf("获取未完成盘点单失败: %w", err)
+		}
+
+		var due []dto.CycleCountDueItem
+		for _, item := range items {
+			if open[item.ID] {
+				continue
+			}
+			class := itemABCClass(classes, item.ID)
+			dueItem := dto.CycleCountDueItem{
+				ItemID:   item.ID,
+				ItemCode: item.Code,
+				ItemName: item.Name,
+				ABCClass: class,
+			}
+			if last, ok := lastCounted[item.ID]; ok {
+				elapsed := int(now.Sub(last).Hours() / 24)
+				if elapsed < frequency[class] {
+					continue
+				}
+				lastAt := last
+				dueItem.LastCountedAt = &lastAt
+				dueItem.OverdueDays = elapsed - frequency[class]
+			}
+			due = append(due, dueItem)
+		}
+		if len(due) == 0 {
+			continue
+		}
+
+		sort.SliceStable(due, func(i, j int) bool {
+			if due[i].ABCClass != due[j].ABCClass {
+				return due[i].ABCClass < due[j].ABCClass
+			}
+			if (due[i].LastCountedAt == nil) != (due[j].LastCountedAt == nil) {
+				return due[i].LastCountedAt == nil
+			}
+			if due[i].OverdueDays != due[j].OverdueDays {
+				return due[i].OverdueDays > due[j].OverdueDays
+			}
+			return due[i].ItemCode < due[j].ItemCode
+		})
+		if len(due) > maxItems {
+			due = due[:maxItems]
+		}
+		response.Warehouses = append(response.Warehouses, dto.CycleCountWarehousePlan{
+			WarehouseID:   warehouse.ID,
+			WarehouseCode: warehouse.Code,
+			Items:         due,
+		})
+	}
+	return response, nil
+}
+
+// abcClasses 按仓库出库成本计算物料的 ABC 分类，没有消耗的物料不在结果中，视为 C 类
+func (s *StockCountServiceImpl) abcClasses(ctx context.Context, warehouseID uint) (map[uint]string, error) {
+	analysis, err := s.reportService.GetABCAnalysis(ctx, &dto.ABCAnalysisRequest{WarehouseID: warehouseID})
+	if err != nil {
+		return nil, fmt.Errorf("计算 ABC 分类失败: %w", err)
+	}
+	classes := make(map[uint]string, len(analysis.Items))
+	for _, item := range analysis.Items {
+		classes[item.ItemID] = item.Class
+	}
+	return classes, nil
+}
+
+// loadCount 获取盘点单，不存在时返回错误
+func (s *StockCountServiceImpl) loadCount(ctx context.Context, id uint) (*models.StockCount, error) {
+	count, err := s.stockCountRepo.GetStockCount(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取盘点单失败: %w", err)
+	}
+	if count == nil {
+		return nil, errors.New("盘点单不存在")
+	}
+	return count, nil
+}
+
+// loadCountForCounting 获取盘点中的盘点单
+func (s *StockCountServiceImpl) loadCountForCounting(ctx context.Context, id uint) (*models.StockCount, error) {
+	count, err := s.loadCount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if count.Status != models.StockCountStatusCounting {
+		return nil, errors.New("只有盘点中的盘点单才能录入或提交实盘数量")
+	}
+	return count, nil
+}
+
+// findOrAppendLine 按物料、库位与批次匹配盘点行，匹配不到时追加账面数量为 0 的账外盘点行
+func (s *StockCountServiceImpl) findOrAppendLine(ctx context.Context, count *models.StockCount, key stockCountLineKey) (*models.StockCountItem, error) {
+	for i := range count.Items {
+		line := &count.Items[i]
+		if line.ItemID == key.itemID && derefUint(line.LocationID) == key.locationID && line.BatchNo == key.batchNo {
+			return line, nil
+		}
+	}
+	// 按库区盘点时未指定库位的记录可匹配物料唯一的盘点行
+	if key.locationID == 0 {
+		var match *models.StockCountItem
+		for i := range count.Items {
+			line := &count.Items[i]
+			if line.ItemID == key.itemID && line.BatchNo == key.batchNo {
+				if match != nil {
+					return nil, errors.New("物料在多个库位有盘点行，请指定库位")
+				}
+				match = line
+			}
+		}
+		if match != nil {
+			return match, nil
+		}
+	}
+
+	item, err := s.stockCountRepo.GetItemByID(ctx, key.itemID)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料失败: %w", err)
+	}
+	if item == nil {
+		return nil, fmt.Errorf("物料 %d 不存在", key.itemID)
+	}
+	if key.locationID != 0 {
+		location, err := s.locationRepo.GetLocation(ctx, key.locationID)
+		if err != nil {
+			return nil, fmt.Errorf("获取库位失败: %w", err)
+		}
+		if location == nil || location.WarehouseID != count.WarehouseID {
+			return nil, errors.New("库位不属于盘点仓库")
+		}
+	}
+
+	line := models.StockCountItem{
+		StockCountID:  count.ID,
+		ItemID:        item.ID,
+		BatchNo:       key.batchNo,
+		ValuationRate: item.Cost,
+		Item:          item,
+	}
+	if key.locationID != 0 {
+		locationID := key.locationID
+		line.LocationID = &locationID
+	}
+	count.Items = append(count.Items, line)
+	return &count.Items[len(count.Items)-1], nil
+}
+
+// saveLines 保存变更的盘点行
+func (s *StockCountServiceImpl) saveLines(ctx context.Context, count *models.StockCount, changed map[*models.StockCountItem]bool) error {
+	lines := make([]*models.StockCountItem, 0, len(changed))
+	for i := range count.Items {
+		if changed[&count.Items[i]] {
+			lines = append(lines, &count.Items[i])
+		}
+	}
+	if err := s.stockCountRepo.SaveCountLines(ctx, lines); err != nil {
+		return fmt.Errorf("保存实盘数量失败: %w", err)
+	}
+	return nil
+}
+
+// setCountedQty 设置实盘数量并按冻结单价计算差异，差异金额超出范围时返回错误且不修改盘点行
+func setCountedQty(line *models.StockCountItem, quantity float64, userID uint) error {
+	varianceQty := quantity - line.ExpectedQty
+	if math.Abs(varianceQty) <= stockQuantityTolerance {
+		varianceQty = 0
+	}
+	varianceValue, err := line.ValuationRate.Mul(varianceQty)
+	if err != nil {
+		return fmt.Errorf("物料 %d 的盘点差异金额计算失败: %w", line.ItemID, err)
+	}
+
+	now := time.Now()
+	line.CountedQty = &quantity
+	line.VarianceQty = varianceQty
+	line.VarianceValue = varianceValue
+	line.CountedAt = &now
+	line.CountedBy = stockCountUser(userID)
+	return nil
+}
+
+// validateStockCountLine 校验有差异的盘点行能够过账：批次管理物料须有批次号，序列号管理物料的序列号数量须与差异一致
+func validateStockCountLine(line *models.StockCountItem) error {
+	if line.Item == nil {
+		return nil
+	}
+	switch line.Item.TrackingMode {
+	case models.TrackingModeBatch:
+		if line.BatchNo == "" {
+			return fmt.Errorf("物料 %s 按批次管理，盘点差异必须指定批次号", line.Item.Code)
+		}
+	case models.TrackingModeSerial:
+		serials := models.ParseSerialNos(line.SerialNo)
+		if float64(len(serials)) != math.Abs(line.VarianceQty) {
+			return fmt.Errorf("物料 %s 按序列号管理，盘点差异 %.0f 需对应相同数量的序列号", line.Item.Code, line.VarianceQty)
+		}
+	}
+	return nil
+}
+
+// exceedsStockCountThreshold 判断盘点行差异是否超过审批阈值；账面数量为 0 时任何差异均视为超过百分比阈值
+func exceedsStockCountThreshold(count *models.StockCount, line *models.StockCountItem) bool {
+	if count.ApprovalValueThreshold.IsPositive() && line.VarianceValue.Abs().Sub(count.ApprovalValueThreshold).IsPositive() {
+		return true
+	}
+	if count.ApprovalPercentThreshold > 0 {
+		if math.Abs(line.ExpectedQty) <= stockQuantityTolerance {
+			return true
+		}
+		return math.Abs(line.VarianceQty)/math.Abs(line.ExpectedQty)*100 > count.ApprovalPercentThreshold
+	}
+	return false
+}
+
+// findStockCountLine 按ID查找盘点行
+func findStockCountLine(count *models.StockCount, lineID uint) *models.StockCountItem {
+	for i := range count.Items {
+		if count.Items[i].ID == lineID {
+			return &count.Items[i]
+		}
+	}
+	return nil
+}
+
+// itemABCClass 物料的 ABC 分类，未参与分析的物料为 C 类
+func itemABCClass(classes map[uint]string, itemID uint) string {
+	if class, ok := classes[itemID]; ok {
+		return class
+	}
+	return "C"
+}
+
+// appendStockCountNote 追加审批意见
+func appendStockCountNote(notes, note string) string {
+	if notes == "" {
+		return note
+	}
+	return notes + "\n" + note
+}
+
+// stockCountUser 操作人ID，0 表示系统操作
+func stockCountUser(userID uint) *uint {
+	if userID == 0 {
+		return nil
+	}
+	return &userID
+}
+
+// defaultInt 为 0 时返回默认值
+func defaultInt(value, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// parseStockCountScans 解析 CSV 或 JSON 格式的扫描记录；CSV 须包含 item_code 列，
+// 可选 location_code、batch_no、quantity 列
+func parseStockCountScans(format string, data []byte) ([]dto.StockCountScanRow, error) {
+	if format == "json" {
+		var rows []dto.StockCountScanRow
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("解析 JSON 失败: %w", err)
+		}
+		return rows, nil
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取 CSV 表头失败: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["item_code"]; !ok {
+		return nil, errors.New("CSV 缺少 item_code 列")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []dto.StockCountScanRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 CSV 失败: %w", err)
+		}
+		row := dto.StockCountScanRow{
+			ItemCode:     field(record, "item_code"),
+			LocationCode: field(record, "location_code"),
+			BatchNo:      field(record, "batch_no"),
+		}
+		if value := field(record, "quantity"); value != "" {
+			quantity, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 行数量无效: %s", line, value)
+			}
+			row.Quantity = &quantity
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// toStockCountResponse 转换为盘点单响应，withLines 为 false 时只返回汇总
+func toStockCountResponse(count *models.StockCount, withLines bool) *dto.StockCountResponse {
+	response := &dto.StockCountResponse{
+		ID:                       count.ID,
+		CompanyID:                count.CompanyID,
+		CountNumber:              count.CountNumber,
+		WarehouseID:              count.WarehouseID,
+		CountType:                count.CountType,
+		Zone:                     count.Zone,
+		ABCClass:                 count.ABCClass,
+		Status:                   count.Status,
+		SnapshotAt:               count.SnapshotAt,
+		CountDate:                count.CountDate,
+		ApprovalValueThreshold:   count.ApprovalValueThreshold,
+		ApprovalPercentThreshold: count.ApprovalPercentThreshold,
+		ApprovalRequired:         count.ApprovalRequired,
+		GainValue:                count.GainValue,
+		LossValue:                count.LossValue,
+		SubmittedAt:              count.SubmittedAt,
+		ApprovedBy:               count.ApprovedBy,
+		ApprovedAt:               count.ApprovedAt,
+		PostedAt:                 count.PostedAt,
+		TransactionID:            count.TransactionID,
+		Notes:                    count.Notes,
+		CreatedAt:                count.CreatedAt,
+		UpdatedAt:                count.UpdatedAt,
+	}
+	if count.Warehouse != nil {
+		response.WarehouseCode = count.Warehouse.Code
+	}
+
+	response.TotalLines = len(count.Items)
+	for _, line := range count.Items {
+		if line.IsCounted() {
+			response.CountedLines++
+			if line.VarianceQty != 0 {
+				response.VarianceLines++
+			}
+		}
+		if !withLines {
+			continue
+		}
+		lineResponse := dto.StockCountLineResponse{
+			ID:            line.ID,
+			ItemID:        line.ItemID,
+			LocationID:    line.LocationID,
+			BatchNo:       line.BatchNo,
+			ABCClass:      line.ABCClass,
+			ExpectedQty:   line.ExpectedQty,
+			CountedQty:    line.CountedQty,
+			VarianceQty:   line.VarianceQty,
+			ValuationRate: line.ValuationRate,
+			VarianceValue: line.VarianceValue,
+			SerialNo:      line.SerialNo,
+			CountedAt:     line.CountedAt,
+			MovementID:    line.MovementID,
+			Notes:         line.Notes,
+		}
+		if line.Item != nil {
+			lineResponse.ItemCode = line.Item.Code
+			lineResponse.ItemName = line.Item.Name
+			lineResponse.Unit = line.Item.Unit
+		}
+		if line.Location != nil {
+			lineResponse.LocationCode = line.Location.Code
+		}
+		response.Lines = append(response.Lines, lineResponse)
+	}
+	return response
+}
+
+// NewCycleCountScheduler 创建循环盘点定时任务，按固定间隔为到期物料生成循环盘点单，
+// request 指定各分类的盘点周期与每单物料上限
+func NewCycleCountScheduler(service StockCountService, interval time.Duration, request dto.CycleCountRequest) *PeriodicJob {
+	return NewPeriodicJob("循环盘点", interval, func(ctx context.Context) error {
+		req := request
+		result, err := service.GenerateCycleCounts(ctx, &req)
+		if err != nil {
+			return err
+		}
+
+		for _, warehouse := range result.Warehouses {
+			if warehouse.StockCountID == nil {
+				continue
+			}
+			utils.Info("已生成循环盘点单",
+				utils.String("count_number", warehouse.CountNumber),
+				utils.String("warehouse", warehouse.WarehouseCode),
+				utils.Int("items", len(warehouse.Items)))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+	"github.com/galaxyerp/galaxyErp/internal/utils"
+)
+
+// stockQuantityTolerance 库存数量比较的浮点容差
+const stockQuantityTolerance = 1e-6
+
+// movementIdempotencyKey 生成库存移动的幂等键：优先使用请求指定的键，
+// 否则按来源单据行生成；两者都没有时返回 nil，表示不做幂等控制
+func movementIdempotencyKey(req *dto.MovementCreateRequest) *string {
+	if req.IdempotencyKey != "" {
+		key := req.IdempotencyKey
+		return &key
+	}
+	if req.ReferenceType == "" || req.ReferenceID == nil {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s:%d", req.ReferenceType, *req.ReferenceID)
+	if req.ReferenceLineID != nil {
+		key = fmt.Sprintf("%s:%d", key, *req.ReferenceLineID)
+	}
+	key = fmt.Sprintf("%s:%s", key, req.Type)
+	return &key
+}
+
+// postStockAdjustment 通过台账将库存调整到指定数量
+func postStockAdjustment(ctx context.Context, ledgerRepo repositories.StockLedgerRepository, itemID, warehouseID uint, quantity float64, reference string) (*models.Movement, error) {
+	movement := &models.Movement{
+		ItemID:       &itemID,
+		WarehouseID:  &warehouseID,
+		Quantity:     &quantity,
+		MovementType: models.MovementTypeAdjustment,
+		Reference:    reference,
+	}
+	if _, err := ledgerRepo.PostMovement(ctx, movement); err != nil {
+		return nil, fmt.Errorf("过账库存调整失败: %w", err)
+	}
+	return movement, nil
+}
+
+// ReconcileStock 按台账汇总重算库存余额并报告差异，Apply 为 true 时修正库存表
+func (s *StockServiceImpl) ReconcileStock(ctx context.Context, req *dto.StockReconcileRequest) (*dto.StockReconcileResult, error) {
+	ledgerBalances, err := s.ledgerRepo.GetLedgerBalances(ctx, req.ItemID, req.WarehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("汇总库存台账失败: %w", err)
+	}
+	stockBalances, err := s.ledgerRepo.GetStockBalances(ctx, req.ItemID, req.WarehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("获取库存余额失败: %w", err)
+	}
+
+	type stockPair struct{ itemID, warehouseID uint }
+	pairs := make([]stockPair, 0, len(stockBalances))
+	stockQty := make(map[stockPair]float64, len(stockBalances))
+	for _, balance := range stockBalances {
+		pair := stockPair{balance.ItemID, balance.WarehouseID}
+		if _, seen := stockQty[pair]; !seen {
+			pairs = append(pairs, pair)
+		}
+		stockQty[pair] += balance.Quantity
+	}
+	ledgerQty := make(map[stockPair]float64, len(ledgerBalances))
+	for _, balance := range ledgerBalances {
+		pair := stockPair{balance.ItemID, balance.WarehouseID}
+		if _, seen := stockQty[pair]; !seen {
+			pairs = append(pairs, pair)
+			stockQty[pair] = 0
+		}
+		ledgerQty[pair] = balance.Quantity
+	}
+
+	result := &dto.StockReconcileResult{
+		CheckedAt:     time.Now(),
+		Checked:       len(pairs),
+		Applied:       req.Apply,
+		Discrepancies: []dto.StockDiscrepancy{},
+	}
+	for _, pair := range pairs {
+		difference := stockQty[pair] - ledgerQty[pair]
+		if math.Abs(difference) < stockQuantityTolerance {
+			continue
+		}
+
+		discrepancy := dto.StockDiscrepancy{
+			ItemID:         pair.itemID,
+			WarehouseID:    pair.warehouseID,
+			StockQuantity:  stockQty[pair],
+			LedgerQuantity: ledgerQty[pair],
+			Difference:     difference,
+		}
+		if req.Apply {
+			if _, err := s.ledgerRepo.RebuildStock(ctx, pair.itemID, pair.warehouseID); err != nil {
+				return nil, fmt.Errorf("重算物料 %d 在仓库 %d 的库存失败: %w", pair.itemID, pair.warehouseID, err)
+			}
+			discrepancy.Fixed = true
+			s.DeleteFromCache(ctx, fmt.Sprintf("stock:item:%d", pair.itemID))
+		}
+		result.Discrepancies = append(result.Discrepancies, discrepancy)
+	}
+	if req.Apply && len(result.Discrepancies) > 0 {
+		s.DeleteFromCache(ctx, "stock:list")
+	}
+
+	return result, nil
+}
+
+// EnsureOpeningBalances 为尚无台账记录的库存补录期初移动
+func (s *StockServiceImpl) EnsureOpeningBalances(ctx context.Context) (int, error) {
+	created, err := s.ledgerRepo.EnsureOpeningBalances(ctx)
+	if err != nil {
+		return created, fmt.Errorf("补录期初库存失败: %w", err)
+	}
+	if created > 0 {
+		utils.Info("已补录期初库存台账", utils.Int("count", created))
+	}
+	return created, nil
+}
+
+// NewStockReconcileScheduler 创建库存对账定时任务，按固定间隔执行 ReconcileStock，
+// autoFix 为 true 时自动修正差异，发现差异时记录警告日志
+func NewStockReconcileScheduler(service StockService, interval time.Duration, autoFix bool) *PeriodicJob {
+	return NewPeriodicJob("库存对账", interval, func(ctx context.Context) error {
+		result, err := service.ReconcileStock(ctx, &dto.StockReconcileRequest{Apply: autoFix})
+		if err != nil {
+			return err
+		}
+
+		for _, discrepancy := range result.Discrepancies {
+			utils.Warn("库存余额与台账不一致",
+				utils.Uint("item_id", discrepancy.ItemID),
+				utils.Uint("warehouse_id", discrepancy.WarehouseID),
+				utils.Float64("stock_quantity", discrepancy.StockQuantity),
+				utils.Float64("ledger_quantity", discrepancy.LedgerQuantity),
+				utils.Bool("fixed", discrepancy.Fixed))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,491 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+)
+
+// StockTransferService 库存调拨服务接口
+type StockTransferService interface {
+	CreateTransfer(ctx context.Context, req *dto.StockTransferCreateRequest) (*dto.StockTransferResponse, error)
+	GetTransfer(ctx context.Context, id uint) (*dto.StockTransferResponse, error)
+	ListTransfers(ctx context.Context, req *dto.StockTransferListRequest) ([]dto.StockTransferResponse, int64, error)
+	ShipTransfer(ctx context.Context, id uint) (*dto.StockTransferResponse, error)
+	ReceiveTransfer(ctx context.Context, id uint, req *dto.StockTransferReceiveRequest) (*dto.StockTransferResponse, error)
+	CancelTransfer(ctx context.Context, id uint) (*dto.StockTransferResponse, error)
+}
+
+// StockTransferServiceImpl 库存调拨服务实现
+type StockTransferServiceImpl struct {
+	transferRepo  repositories.StockTransferRepository
+	itemRepo      repositories.ItemRepository
+	warehouseRepo repositories.WarehouseRepository
+	uomService    UOMService
+}
+
+// NewStockTransferService 创建库存调拨服务实例
+func NewStockTransferService(transferRepo repositories.StockTransferRepository, itemRepo repositories.ItemRepository, warehouseRepo repositories.WarehouseRepository, uomService UOMService) StockTransferService {
+	return &StockTransferServiceImpl{
+		transferRepo:  transferRepo,
+		itemRepo:      itemRepo,
+		warehouseRepo: warehouseRepo,
+		uomService:    uomService,
+	}
+}
+
+// CreateTransfer 创建草稿状态的调拨单
+func (s *StockTransferServiceImpl) CreateTransfer(ctx context.Context, req *dto.StockTransferCreateRequest) (*dto.StockTransferResponse, error) {
+	if req.FromWarehouseID == req.ToWarehouseID {
+		return nil, errors.New("调出仓库与调入仓库不能相同")
+	}
+	for _, warehouseID := range []uint{req.FromWarehouseID, req.ToWarehouseID} {
+		warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("仓库 %d 不存在", warehouseID)
+		}
+		if warehouse.IsTransit {
+			return nil, errors.New("在途仓库不能作为调拨的调出或调入仓库")
+		}
+		if !warehouse.IsActive {
+			return nil, fmt.Errorf("仓库 %s 已停用", warehouse.Name)
+		}
+	}
+	if err := s.checkLocation(ctx, req.FromLocationID, req.FromWarehouseID); err != nil {
+		return nil, err
+	}
+	if err := s.checkLocation(ctx, req.ToLocationID, req.ToWarehouseID); err != nil {
+		return nil, err
+	}
+
+	transferNumber, err := s.transferRepo.GenerateTransferNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("生成调拨单号失败: %w", err)
+	}
+
+	transfer := &models.StockTransfer{
+		TransferNumber:  transferNumber,
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		FromLocationID:  req.FromLocationID,
+		ToLocationID:    req.ToLocationID,
+		TransferDate:    time.Now(),
+		Status:          models.StockTransferStatusDraft,
+		Notes:           req.Notes,
+	}
+	if req.TransferDate != nil {
+		transfer.TransferDate = *req.TransferDate
+	}
+
+	for _, itemReq := range req.Items {
+		if _, err := s.itemRepo.GetByID(ctx, itemReq.ItemID); err != nil {
+			return nil, fmt.Errorf("物料 %d 不存在", itemReq.ItemID)
+		}
+		conversion, err := s.uomService.ConvertToStock(ctx, itemReq.ItemID, itemReq.UOM, itemReq.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		line := models.StockTransferItem{
+			ItemID:           itemReq.ItemID,
+			FromLocationID:   itemReq.FromLocationID,
+			ToLocationID:     itemReq.ToLocationID,
+			Quantity:         conversion.StockQuantity,
+			UOM:              conversion.UOM,
+			UOMQuantity:      conversion.Quantity,
+			ConversionFactor: conversion.ConversionFactor,
+			BatchNo:          itemReq.BatchNo,
+			SerialNo:         strings.Join(models.ParseSerialNos(itemReq.SerialNo), ","),
+			Notes:            itemReq.Notes,
+		}
+		if line.FromLocationID == nil {
+			line.FromLocationID = req.FromLocationID
+		} else if err := s.checkLocation(ctx, line.FromLocationID, req.FromWarehouseID); err != nil {
+			return nil, err
+		}
+		if line.ToLocationID == nil {
+			line.ToLocationID = req.ToLocationID
+		} else if err := s.checkLocation(ctx, line.ToLocationID, req.ToWarehouseID); err != nil {
+			return nil, err
+		}
+		transfer.Items = append(transfer.Items, line)
+	}
+
+	if err := s.transferRepo.Create(ctx, transfer); err != nil {
+		return nil, fmt.Errorf("创建调拨单失败: %w", err)
+	}
+
+	return s.GetTransfer(ctx, transfer.ID)
+}
+
+// GetTransfer 获取调拨单详情
+func (s *StockTransferServiceImpl) GetTransfer(ctx context.Context, id uint) (*dto.StockTransferResponse, error) {
+	transfer, err := s.transferRepo.GetWithItems(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取调拨单失败: %w", err)
+	}
+	if transfer == nil {
+		return nil, errors.New("调拨单不存在")
+	}
+	return s.toTransferResponse(transfer), nil
+}
+
+// ListTransfers 分页获取调拨单
+func (s *StockTransferServiceImpl) ListTransfers(ctx context.Context, req *dto.StockTransferListRequest) ([]dto.StockTransferResponse, int64, error) {
+	filter := repositories.StockTransferFilter{
+		Status:          req.Status,
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+	}
+	transfers, total, err := s.transferRepo.ListTransfers(ctx, filter, req.GetOffset(), req.GetLimit())
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取调拨单列表失败: %w", err)
+	}
+
+	responses := make([]dto.StockTransferResponse, 0, len(transfers))
+	for _, transfer := range transfers {
+		responses = append(responses, *s.toTransferResponse(transfer))
+	}
+	return responses, total, nil
+}
+
+// ShipTransfer 调拨发货：全部明细从调出仓转入在途仓
+func (s *StockTransferServiceImpl) ShipTransfer(ctx context.Context, id uint) (*dto.StockTransferResponse, error) {
+	transit, err := s.transferRepo.GetTransitWarehouse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取在途仓库失败: %w", err)
+	}
+
+	_, err = s.transferRepo.Transition(ctx, id, func(transfer *models.StockTransfer) ([]*models.Movement, error) {
+		if transfer.Status != models.StockTransferStatusDraft {
+			return nil, fmt.Errorf("调拨单状态为 %s，只有草稿状态可以发货", transfer.Status)
+		}
+		if len(transfer.Items) == 0 {
+			return nil, errors.New("调拨单没有明细")
+		}
+
+		now := time.Now()
+		transfer.Status = models.StockTransferStatusInTransit
+		transfer.ShippedAt = &now
+		transfer.TransitWarehouseID = &transit.ID
+
+		movements := make([]*models.Movement, 0, len(transfer.Items)*2)
+		for i := range transfer.Items {
+			line := &transfer.Items[i]
+			line.ShippedQty = line.Quantity
+			movements = append(movements,
+				transferPair(
+					transferMovement(transfer, line, transfer.FromWarehouseID, models.MovementTypeTransferOut, line.Quantity, "调拨发货", "ship_out"),
+					transferMovement(transfer, line, transit.ID, models.MovementTypeTransferIn, line.Quantity, "调拨在途", "ship_in"),
+				)...)
+		}
+		return movements, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("调拨发货失败: %w", err)
+	}
+
+	return s.GetTransfer(ctx, id)
+}
+
+// ReceiveTransfer 调拨收货：在途数量转入调入仓，支持分批收货；
+// 实收超过在途的部分按溢收直接计入调入仓，结束收货时短收数量按处理方式报损或退回调出仓
+func (s *StockTransferServiceImpl) ReceiveTransfer(ctx context.Context, id uint, req *dto.StockTransferReceiveRequest) (*dto.StockTransferResponse, error) {
+	if len(req.Items) == 0 && !req.Close {
+		return nil, errors.New("收货明细不能为空")
+	}
+
+	_, err := s.transferRepo.Transition(ctx, id, func(transfer *models.StockTransfer) ([]*models.Movement, error) {
+		if transfer.Status != models.StockTransferStatusInTransit && transfer.Status != models.StockTransferStatusPartiallyReceived {
+			return nil, fmt.Errorf("调拨单状态为 %s，不能收货", transfer.Status)
+		}
+		if transfer.TransitWarehouseID == nil {
+			return nil, errors.New("调拨单缺少在途仓库")
+		}
+		transitID := *transfer.TransitWarehouseID
+
+		lines := make(map[uint]*models.StockTransferItem, len(transfer.Items))
+		for i := range transfer.Items {
+			lines[transfer.Items[i].ID] = &transfer.Items[i]
+		}
+
+		var movements []*models.Movement
+		for _, itemReq := range req.Items {
+			line, ok := lines[itemReq.LineID]
+			if !ok {
+				return nil, fmt.Errorf("调拨明细 %d 不属于该调拨单", itemReq.LineID)
+			}
+			if itemReq.Quantity <= 0 {
+				continue
+			}
+
+			fromTransit := math.Min(itemReq.Quantity, line.PendingQty())
+			if line.SerialNo != "" && itemReq.Quantity > fromTransit {
+				return nil, fmt.Errorf("调拨明细 %d 为序列号管理物料，不能溢收", line.ID)
+			}
+			if fromTransit > 0 {
+				pair := transferPair(
+					transferMovement(transfer, line, transitID, models.MovementTypeTransferOut, fromTransit, "调拨收货", ""),
+					transferMovement(transfer, line, transfer.ToWarehouseID, models.MovementTypeTransferIn, fromTransit, "调拨收货", ""),
+				)
+				if line.SerialNo != "" {
+					serialNos, err := receiveSerialNos(line, itemReq.SerialNo, fromTransit)
+					if err != nil {
+						return nil, err
+					}
+					setSerialNos(pair, serialNos)
+				}
+				movements = append(movements, pair...)
+			}
+			if surplus := itemReq.Quantity - fromTransit; surplus > 0 {
+				movements = append(movements,
+					transferMovement(transfer, line, transfer.ToWarehouseID, models.MovementTypeIn, surplus, "调拨溢收", ""))
+				line.DiscrepancyResolution = models.StockTransferResolutionSurplus
+				line.DiscrepancyReason = firstNonEmpty(itemReq.Reason, req.Reason)
+			}
+			line.ReceivedQty += itemReq.Quantity
+		}
+
+		if req.Close {
+			for i := range transfer.Items {
+				line := &transfer.Items[i]
+				pending := line.PendingQty()
+				if pending <= 0 {
+					continue
+				}
+
+				var closing []*models.Movement
+				switch req.Resolution {
+				case models.StockTransferResolutionWriteOff:
+					closing = []*models.Movement{
+						transferMovement(transfer, line, transitID, models.MovementTypeOut, pending, "调拨短收报损", ""),
+					}
+				case models.StockTransferResolutionReturn:
+					closing = transferPair(
+						transferMovement(transfer, line, transitID, models.MovementTypeTransferOut, pending, "调拨短收退回", ""),
+						transferMovement(transfer, line, transfer.FromWarehouseID, models.MovementTypeTransferIn, pending, "调拨短收退回", ""),
+					)
+				default:
+					return nil, errors.New("存在未收货的在途数量，结束收货时需指定差异处理方式(write_off/return)")
+				}
+				if line.SerialNo != "" {
+					setSerialNos(closing, line.PendingSerialNos())
+				}
+				movements = append(movements, closing...)
+				line.DiscrepancyResolution = req.Resolution
+				line.DiscrepancyReason = req.Reason
+			}
+		}
+
+		complete := true
+		transfer.HasDiscrepancy = false
+		for i := range transfer.Items {
+			line := &transfer.Items[i]
+			if line.PendingQty() > 0 {
+				complete = false
+			}
+			if line.DiscrepancyResolution != "" {
+				line.DiscrepancyQty = line.ReceivedQty - line.ShippedQty
+			}
+			if math.Abs(line.DiscrepancyQty) > stockQuantityTolerance {
+				transfer.HasDiscrepancy = true
+			}
+		}
+
+		if complete {
+			receivedAt := time.Now()
+			if req.ReceivedAt != nil {
+				receivedAt = *req.ReceivedAt
+			}
+			transfer.Status = models.StockTransferStatusReceived
+			transfer.ReceivedAt = &receivedAt
+		} else {
+			transfer.Status = models.StockTransferStatusPartiallyReceived
+		}
+		return movements, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("调拨收货失败: %w", err)
+	}
+
+	return s.GetTransfer(ctx, id)
+}
+
+// CancelTransfer 取消调拨单，已发货的调拨单需通过收货结束处理
+func (s *StockTransferServiceImpl) CancelTransfer(ctx context.Context, id uint) (*dto.StockTransferResponse, error) {
+	_, err := s.transferRepo.Transition(ctx, id, func(transfer *models.StockTransfer) ([]*models.Movement, error) {
+		if transfer.Status != models.StockTransferStatusDraft {
+			return nil, fmt.Errorf("调拨单状态为 %s，只有草稿状态可以取消", transfer.Status)
+		}
+		transfer.Status = models.StockTransferStatusCancelled
+		return nil, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("取消调拨单失败: %w", err)
+	}
+
+	return s.GetTransfer(ctx, id)
+}
+
+// checkLocation 校验库位存在且属于指定仓库
+func (s *StockTransferServiceImpl) checkLocation(ctx context.Context, locationID *uint, warehouseID uint) error {
+	if locationID == nil {
+		return nil
+	}
+	location, err := s.transferRepo.GetLocation(ctx, *locationID)
+	if err != nil {
+		return fmt.Errorf("获取库位失败: %w", err)
+	}
+	if location == nil {
+		return fmt.Errorf("库位 %d 不存在", *locationID)
+	}
+	if location.WarehouseID != warehouseID {
+		return fmt.Errorf("库位 %s 不属于仓库 %d", location.Code, warehouseID)
+	}
+	return nil
+}
+
+// transferMovement 构建调拨单明细对应的库存移动；step 非空时生成幂等键
+func transferMovement(transfer *models.StockTransfer, line *models.StockTransferItem, warehouseID uint, movementType string, quantity float64, notes, step string) *models.Movement {
+	itemID := line.ItemID
+	lineID := line.ID
+	transferID := transfer.ID
+	movement := &models.Movement{
+		ItemID:          &itemID,
+		WarehouseID:     &warehouseID,
+		Quantity:        &quantity,
+		MovementType:    movementType,
+		Reference:       transfer.TransferNumber,
+		Notes:           notes,
+		ReferenceType:   "stock_transfer",
+		ReferenceID:     &transferID,
+		ReferenceLineID: &lineID,
+		BatchNo:         line.BatchNo,
+		SerialNo:        line.SerialNo,
+	}
+	if step != "" {
+		key := fmt.Sprintf("stock_transfer:%d:%d:%s", transfer.ID, line.ID, step)
+		movement.IdempotencyKey = &key
+	}
+	return movement
+}
+
+// transferPair 组合一对调出、调入库存移动，调入成本沿用调出时结转的成本
+func transferPair(out, in *models.Movement) []*models.Movement {
+	in.SourceMovement = out
+	return []*models.Movement{out, in}
+}
+
+// receiveSerialNos 确定本次收货的序列号并记入明细已收序列号：指定序列号时须全部在途且数量一致，
+// 未指定时按发出顺序取在途序列号
+func receiveSerialNos(line *models.StockTransferItem, requested string, quantity float64) ([]string, error) {
+	pending := line.PendingSerialNos()
+	serialNos := models.ParseSerialNos(requested)
+	if len(serialNos) == 0 {
+		count := int(quantity)
+		if float64(count) != quantity || count > len(pending) {
+			return nil, fmt.Errorf("调拨明细 %d 收货数量 %.2f 与在途序列号数量 %d 不匹配", line.ID, quantity, len(pending))
+		}
+		serialNos = pending[:count]
+	} else {
+		if float64(len(serialNos)) != quantity {
+			return nil, fmt.Errorf("调拨明细 %d 收货序列号数量 %d 与收货数量 %.2f 不一致", line.ID, len(serialNos), quantity)
+		}
+		inTransit := make(map[string]bool, len(pending))
+		for _, serialNo := range pending {
+			inTransit[serialNo] = true
+		}
+		for _, serialNo := range serialNos {
+			if !inTransit[serialNo] {
+				return nil, fmt.Errorf("序列号 %s 不在调拨明细 %d 的在途序列号中", serialNo, line.ID)
+			}
+		}
+	}
+
+	received := append(models.ParseSerialNos(line.ReceivedSerialNo), serialNos...)
+	line.ReceivedSerialNo = strings.Join(received, ",")
+	return serialNos, nil
+}
+
+// setSerialNos 设置一组库存移动涉及的序列号
+func setSerialNos(movements []*models.Movement, serialNos []string) {
+	value := strings.Join(serialNos, ",")
+	for _, movement := range movements {
+		movement.SerialNo = value
+	}
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// toTransferResponse 转换为调拨单响应
+func (s *StockTransferServiceImpl) toTransferResponse(transfer *models.StockTransfer) *dto.StockTransferResponse {
+	response := &dto.StockTransferResponse{
+		ID:                 transfer.ID,
+		TransferNumber:     transfer.TransferNumber,
+		FromWarehouseID:    transfer.FromWarehouseID,
+		ToWarehouseID:      transfer.ToWarehouseID,
+		FromLocationID:     transfer.FromLocationID,
+		ToLocationID:       transfer.ToLocationID,
+		TransitWarehouseID: transfer.TransitWarehouseID,
+		TransferDate:       transfer.TransferDate,
+		ShippedAt:          transfer.ShippedAt,
+		ReceivedAt:         transfer.ReceivedAt,
+		Status:             transfer.Status,
+		HasDiscrepancy:     transfer.HasDiscrepancy,
+		Notes:              transfer.Notes,
+		Items:              make([]dto.StockTransferItemResponse, 0, len(transfer.Items)),
+		CreatedAt:          transfer.CreatedAt,
+		UpdatedAt:          transfer.UpdatedAt,
+	}
+	if transfer.FromWarehouse != nil {
+		response.FromWarehouseName = transfer.FromWarehouse.Name
+	}
+	if transfer.ToWarehouse != nil {
+		response.ToWarehouseName = transfer.ToWarehouse.Name
+	}
+
+	for i := range transfer.Items {
+		line := &transfer.Items[i]
+		item := dto.StockTransferItemResponse{
+			ID:                    line.ID,
+			ItemID:                line.ItemID,
+			FromLocationID:        line.FromLocationID,
+			ToLocationID:          line.ToLocationID,
+			Quantity:              line.Quantity,
+			UOM:                   line.UOM,
+			UOMQuantity:           line.UOMQuantity,
+			ConversionFactor:      line.ConversionFactor,
+			ShippedQty:            line.ShippedQty,
+			ReceivedQty:           line.ReceivedQty,
+			InTransitQty:          line.PendingQty(),
+			DiscrepancyQty:        line.DiscrepancyQty,
+			DiscrepancyResolution: line.DiscrepancyResolution,
+			DiscrepancyReason:     line.DiscrepancyReason,
+			BatchNo:               line.BatchNo,
+			SerialNo:              line.SerialNo,
+			ReceivedSerialNo:      line.ReceivedSerialNo,
+			Notes:                 line.Notes,
+		}
+		if line.Item != nil {
+			item.ItemCode = line.Item.Code
+			item.ItemName = line.Item.Name
+		}
+		response.Items = append(response.Items, item)
+	}
+	return response
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+)
+
+// DefaultInventoryAccountCode 库存估值报表默认核对的存货科目（库存商品）
+const DefaultInventoryAccountCode = "1405"
+
+// StockValuationService 库存估值服务接口
+type StockValuationService interface {
+	GetValuation(ctx context.Context, req *dto.StockValuationRequest) (*dto.StockValuationResponse, error)
+	GetValuationReport(ctx context.Context, req *dto.StockValuationReportRequest) (*dto.StockValuationReportResponse, error)
+	GetCostOfGoodsSold(ctx context.Context, req *dto.CostOfGoodsSoldRequest) (*dto.CostOfGoodsSoldResponse, error)
+}
+
+// StockValuationServiceImpl 库存估值服务实现
+type StockValuationServiceImpl struct {
+	valuationRepo repositories.StockValuationRepository
+	journalRepo   repositories.JournalEntryRepository
+	companyRepo   repositories.CompanyRepository
+}
+
+// NewStockValuationService 创建库存估值服务实例
+func NewStockValuationService(valuationRepo repositories.StockValuationRepository, journalRepo repositories.JournalEntryRepository, companyRepo repositories.CompanyRepository) StockValuationService {
+	return &StockValuationServiceImpl{
+		valuationRepo: valuationRepo,
+		journalRepo:   journalRepo,
+		companyRepo:   companyRepo,
+	}
+}
+
+// GetValuation 按台账计算指定日期结束时各物料、仓库的库存数量与金额
+func (s *StockValuationServiceImpl) GetValuation(ctx context.Context, req *dto.StockValuationRequest) (*dto.StockValuationResponse, error) {
+	rows, err := s.valuationRepo.GetValuationRows(ctx, valuationFilter(req))
+	if err != nil {
+		return nil, fmt.Errorf("获取库存估值失败: %w", err)
+	}
+
+	response := &dto.StockValuationResponse{
+		AsOf:  asOfDate(req.AsOf),
+		Lines: make([]dto.StockValuationLine, 0, len(rows)),
+	}
+	for _, row := range rows {
+		line := dto.StockValuationLine{
+			ItemID:          row.ItemID,
+			ItemCode:        row.ItemCode,
+			ItemName:        row.ItemName,
+			Category:        row.Category,
+			ValuationMethod: row.ValuationMethod,
+			WarehouseID:     row.WarehouseID,
+			WarehouseCode:   row.WarehouseCode,
+			WarehouseName:   row.WarehouseName,
+			Quantity:        row.Quantity,
+			StockValue:      row.StockValue,
+		}
+		if row.Quantity != 0 {
+			rate, err := row.StockValue.Div(row.Quantity)
+			if err != nil {
+				return nil, err
+			}
+			line.ValuationRate = rate
+		}
+		response.Lines = append(response.Lines, line)
+		response.TotalQuantity += row.Quantity
+		response.TotalValue = response.TotalValue.Add(row.StockValue)
+	}
+	return response, nil
+}
+
+// GetValuationReport 按仓库和物料类别汇总库存估值，并与存货科目在截止日的总账余额核对。
+// 仓库不区分公司，总账余额取请求公司（默认公司）的科目余额
+func (s *StockValuationServiceImpl) GetValuationReport(ctx context.Context, req *dto.StockValuationReportRequest) (*dto.StockValuationReportResponse, error) {
+	companyID, err := resolveCompanyID(ctx, s.companyRepo, req.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.valuationRepo.GetValuationRows(ctx, valuationFilter(&req.StockValuationRequest))
+	if err != nil {
+		return nil, fmt.Errorf("获取库存估值失败: %w", err)
+	}
+
+	report := &dto.StockValuationReportResponse{
+		AsOf:         asOfDate(req.AsOf),
+		CompanyID:    companyID,
+		Groups:       []dto.StockValuationGroup{},
+		AccountCodes: parseAccountCodes(req.AccountCodes),
+	}
+
+	groupIndex := make(map[string]*dto.StockValuationGroup)
+	warehouseTotals := make(map[string]*dto.StockValuationTotal)
+	categoryTotals := make(map[string]*dto.StockValuationTotal)
+	var groupKeys, warehouseKeys, categoryKeys []string
+	for _, row := range rows {
+		groupKey := fmt.Sprintf("%s\x00%s", row.WarehouseCode, row.Category)
+		group, ok := groupIndex[groupKey]
+		if !ok {
+			group = &dto.StockValuationGroup{
+				WarehouseID:   row.WarehouseID,
+				WarehouseCode: row.WarehouseCode,
+				WarehouseName: row.WarehouseName,
+				Category:      row.Category,
+			}
+			groupIndex[groupKey] = group
+			groupKeys = append(groupKeys, groupKey)
+		}
+		group.ItemCount++
+		group.Quantity += row.Quantity
+		group.StockValue = group.StockValue.Add(row.StockValue)
+
+		warehouse, ok := warehouseTotals[row.WarehouseCode]
+		if !ok {
+			warehouse = &dto.StockValuationTotal{Key: row.WarehouseCode, Name: row.WarehouseName}
+			warehouseTotals[row.WarehouseCode] = warehouse
+			warehouseKeys = append(warehouseKeys, row.WarehouseCode)
+		}
+		warehouse.Quantity += row.Quantity
+		warehouse.StockValue = warehouse.StockValue.Add(row.StockValue)
+
+		category, ok := categoryTotals[row.Category]
+		if !ok {
+			category = &dto.StockValuationTotal{Key: row.Category}
+			categoryTotals[row.Category] = category
+			categoryKeys = append(categoryKeys, row.Category)
+		}
+		category.Quantity += row.Quantity
+		category.StockValue = category.StockValue.Add(row.StockValue)
+
+		report.TotalValue = report.TotalValue.Add(row.StockValue)
+	}
+
+	sort.Strings(groupKeys)
+	sort.Strings(warehouseKeys)
+	sort.Strings(categoryKeys)
+	for _, key := range groupKeys {
+		report.Groups = append(report.Groups, *groupIndex[key])
+	}
+	report.WarehouseTotals = make([]dto.StockValuationTotal, 0, len(warehouseKeys))
+	for _, key := range warehouseKeys {
+		report.WarehouseTotals = append(report.WarehouseTotals, *warehouseTotals[key])
+	}
+	report.CategoryTotals = make([]dto.StockValuationTotal, 0, len(categoryKeys))
+	for _, key := range categoryKeys {
+		report.CategoryTotals = append(report.CategoryTotals, *categoryTotals[key])
+	}
+
+	glBalance, err := s.inventoryAccountBalance(ctx, companyID, report.AccountCodes, req.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	report.GLBalance = glBalance
+	report.Difference = report.TotalValue.Sub(glBalance)
+	report.IsReconciled = report.Difference.IsZero()
+	return report, nil
+}
+
+// GetCostOfGoodsSold 按送货单汇总结转的发货成本
+func (s *StockValuationServiceImpl) GetCostOfGoodsSold(ctx context.Context, req *dto.CostOfGoodsSoldRequest) (*dto.CostOfGoodsSoldResponse, error) {
+	filter := repositories.DeliveryCostFilter{DeliveryNoteID: req.DeliveryNoteID}
+	if !req.StartDate.IsZero() {
+		filter.StartDate = truncateToDay(req.StartDate)
+	}
+	if !req.EndDate.IsZero() {
+		filter.EndDate = truncateToDay(req.EndDate).AddDate(0, 0, 1)
+		if !filter.StartDate.IsZero() && !filter.EndDate.After(filter.StartDate) {
+			return nil, errors.New("结束日期不能早于开始日期")
+		}
+	}
+
+	rows, err := s.valuationRepo.GetDeliveryCosts(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("获取发货成本失败: %w", err)
+	}
+
+	response := &dto.CostOfGoodsSoldResponse{Deliveries: []dto.DeliveryCostOfGoodsSold{}}
+	index := make(map[uint]int)
+	for _, row := range rows {
+		i, ok := index[row.DeliveryNoteID]
+		if !ok {
+			response.Deliveries = append(response.Deliveries, dto.DeliveryCostOfGoodsSold{
+				DeliveryNoteID: row.DeliveryNoteID,
+				DeliveryNumber: row.DeliveryNumber,
+			})
+			i = len(response.Deliveries) - 1
+			index[row.DeliveryNoteID] = i
+		}
+
+		line := dto.CostOfGoodsSoldLine{
+			ItemID:      row.ItemID,
+			ItemCode:    row.ItemCode,
+			ItemName:    row.ItemName,
+			WarehouseID: row.WarehouseID,
+			Quantity:    row.Quantity,
+			TotalCost:   row.TotalCost,
+		}
+		if row.Quantity != 0 {
+			unitCost, err := row.TotalCost.Div(row.Quantity)
+			if err != nil {
+				return nil, err
+			}
+			line.UnitCost = unitCost
+		}
+		delivery := &response.Deliveries[i]
+		delivery.Lines = append(delivery.Lines, line)
+		delivery.TotalCost = delivery.TotalCost.Add(row.TotalCost)
+		response.TotalCost = response.TotalCost.Add(row.TotalCost)
+	}
+	return response, nil
+}
+
+// inventoryAccountBalance 汇总存货科目截至估值日结束时的总账借方余额
+func (s *StockValuationServiceImpl) inventoryAccountBalance(ctx context.Context, companyID uint, accountCodes []string, asOf time.Time) (models.Money, error) {
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+	end := truncateToDay(asOf).AddDate(0, 0, 1)
+
+	rows, err := s.journalRepo.GetTrialBalanceRows(ctx, []uint{companyID}, nil, end, end)
+	if err != nil {
+		return 0, fmt.Errorf("获取存货科目余额失败: %w", err)
+	}
+
+	codes := make(map[string]bool, len(accountCodes))
+	for _, code := range accountCodes {
+		codes[code] = true
+	}
+	var balance models.Money
+	for _, row := range rows {
+		if codes[row.AccountCode] {
+			balance = balance.Add(row.OpeningBalance).Add(row.Debit).Sub(row.Credit)
+		}
+	}
+	return balance, nil
+}
+
+// valuationFilter 将估值请求转换为台账筛选条件，估值日包含当天全部移动
+func valuationFilter(req *dto.StockValuationRequest) repositories.StockValuationFilter {
+	filter := repositories.StockValuationFilter{
+		ItemID:      req.ItemID,
+		WarehouseID: req.WarehouseID,
+		Category:    req.Category,
+	}
+	if !req.AsOf.IsZero() {
+		filter.Before = truncateToDay(req.AsOf).AddDate(0, 0, 1)
+	}
+	return filter
+}
+
+// asOfDate 返回估值日，未指定时为 nil 表示当前
+func asOfDate(asOf time.Time) *time.Time {
+	if asOf.IsZero() {
+		return nil
+	}
+	date := truncateToDay(asOf)
+	return &date
+}
+
+// parseAccountCodes 解析逗号分隔的科目编码，为空时使用默认存货科目
+func parseAccountCodes(value string) []string {
+	var codes []string
+	for _, code := range strings.Split(value, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		codes = []string{DefaultInventoryAccountCode}
+	}
+	return codes
+}
@@ -0,0 +1,447 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/galaxyerp/galaxyErp/internal/dto"
+	"github.com/galaxyerp/galaxyErp/internal/models"
+	"github.com/galaxyerp/galaxyErp/internal/repositories"
+)
+
+// UOMService 计量单位服务接口
+type UOMService interface {
+	CreateCategory(ctx context.Context, req *dto.UOMCategoryCreateRequest) (*dto.UOMCategoryResponse, error)
+	UpdateCategory(ctx context.Context, id uint, req *dto.UOMCategoryUpdateRequest) (*dto.UOMCategoryResponse, error)
+	DeleteCategory(ctx context.Context, id uint) error
+	ListCategories(ctx context.Context) ([]dto.UOMCategoryResponse, error)
+	CreateUOM(ctx context.Context, req *dto.UOMCreateRequest) (*dto.UOMResponse, error)
+	UpdateUOM(ctx context.Context, id uint, req *dto.UOMUpdateRequest) (*dto.UOMResponse, error)
+	DeleteUOM(ctx context.Context, id uint) error
+	GetUOM(ctx context.Context, id uint) (*dto.UOMResponse, error)
+	ListUOMs(ctx context.Context, req *dto.UOMListRequest) ([]dto.UOMResponse, error)
+	SetItemConversion(ctx context.Context, itemID uint, req *dto.ItemUOMConversionRequest) (*dto.ItemUOMConversionResponse, error)
+	ListItemConversions(ctx context.Context, itemID uint) ([]dto.ItemUOMConversionResponse, error)
+	DeleteItemConversion(ctx context.Context, itemID, id uint) error
+	ConvertToStock(ctx context.Context, itemID uint, uom string, quantity float64) (*dto.UOMConversionResult, error)
+}
+
+// UOMServiceImpl 计量单位服务实现
+type UOMServiceImpl struct {
+	uomRepo  repositories.UOMRepository
+	itemRepo repositories.ItemRepository
+}
+
+// NewUOMService 创建计量单位服务实例
+func NewUOMService(uomRepo repositories.UOMRepository, itemRepo repositories.ItemRepository) UOMService {
+	return &UOMServiceImpl{
+		uomRepo:  uomRepo,
+		itemRepo: itemRepo,
+	}
+}
+
+// CreateCategory 创建单位类别
+func (s *UOMServiceImpl) CreateCategory(ctx context.Context, req *dto.UOMCategoryCreateRequest) (*dto.UOMCategoryResponse, error) {
+	code := strings.TrimSpace(req.Code)
+	existing, err := s.uomRepo.GetCategoryByCode(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("获取单位类别失败: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("单位类别 %s 已存在", code)
+	}
+
+	category := &models.UOMCategory{
+		Code:        code,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.uomRepo.CreateCategory(ctx, category); err != nil {
+		return nil, fmt.Errorf("创建单位类别失败: %w", err)
+	}
+	return toUOMCategoryResponse(category), nil
+}
+
+// UpdateCategory 更新单位类别
+func (s *UOMServiceImpl) UpdateCategory(ctx context.Context, id uint, req *dto.UOMCategoryUpdateRequest) (*dto.UOMCategoryResponse, error) {
+	category, err := s.uomRepo.GetCategory(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取单位类别失败: %w", err)
+	}
+	if category == nil {
+		return nil, errors.New("单位类别不存在")
+	}
+
+	if req.Name != nil {
+		category.Name = *req.Name
+	}
+	if req.Description != nil {
+		category.Description = *req.Description
+	}
+	if err := s.uomRepo.SaveCategory(ctx, category); err != nil {
+		return nil, fmt.Errorf("更新单位类别失败: %w", err)
+	}
+	return toUOMCategoryResponse(category), nil
+}
+
+// DeleteCategory 删除没有计量单位的单位类别
+func (s *UOMServiceImpl) DeleteCategory(ctx context.Context, id uint) error {
+	category, err := s.uomRepo.GetCategory(ctx, id)
+	if err != nil {
+		return fmt.Errorf("获取单位类别失败: %w", err)
+	}
+	if category == nil {
+		return errors.New("单位类别不存在")
+	}
+	count, err := s.uomRepo.CountCategoryUOMs(ctx, id)
+	if err != nil {
+		return fmt.Errorf("获取类别下的计量单位失败: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("单位类别 %s 下仍有 %d 个计量单位，不能删除", category.Code, count)
+	}
+	if err := s.uomRepo.DeleteCategory(ctx, id); err != nil {
+		return fmt.Errorf("删除单位类别失败: %w", err)
+	}
+	return nil
+}
+
+// ListCategories 获取全部单位类别
+func (s *UOMServiceImpl) ListCategories(ctx context.Context) ([]dto.UOMCategoryResponse, error) {
+	categories, err := s.uomRepo.ListCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取单位类别失败: %w", err)
+	}
+	responses := make([]dto.UOMCategoryResponse, 0, len(categories))
+	for _, category := range categories {
+		responses = append(responses, *toUOMCategoryResponse(category))
+	}
+	return responses, nil
+}
+
+// CreateUOM 创建计量单位
+func (s *UOMServiceImpl) CreateUOM(ctx context.Context, req *dto.UOMCreateRequest) (*dto.UOMResponse, error) {
+	code := strings.TrimSpace(req.Code)
+	existing, err := s.uomRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("获取计量单位失败: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("计量单位 %s 已存在", code)
+	}
+	category, err := s.uomRepo.GetCategory(ctx, req.CategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("获取单位类别失败: %w", err)
+	}
+	if category == nil {
+		return nil, errors.New("单位类别不存在")
+	}
+
+	uom := &models.UOM{
+		Code:        code,
+		Name:        req.Name,
+		CategoryID:  req.CategoryID,
+		BaseFactor:  1,
+		Precision:   req.Precision,
+		Rounding:    req.Rounding,
+		Description: req.Description,
+		IsActive:    true,
+	}
+	if req.BaseFactor != nil {
+		uom.BaseFactor = *req.BaseFactor
+	}
+	if uom.Rounding == "" {
+		uom.Rounding = models.UOMRoundingHalfUp
+	}
+	if err := s.uomRepo.Create(ctx, uom); err != nil {
+		return nil, fmt.Errorf("创建计量单位失败: %w", err)
+	}
+	return s.GetUOM(ctx, uom.ID)
+}
+
+// UpdateUOM 更新计量单位，已过账单据行保存了当时的换算系数，修改不影响历史数量
+func (s *UOMServiceImpl) UpdateUOM(ctx context.Context, id uint, req *dto.UOMUpdateRequest) (*dto.UOMResponse, error) {
+	uom, err := s.uomRepo.GetUOM(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取计量单位失败: %w", err)
+	}
+	if uom == nil {
+		return nil, errors.New("计量单位不存在")
+	}
+
+	if req.Name != nil {
+		uom.Name = *req.Name
+	}
+	if req.BaseFactor != nil {
+		uom.BaseFactor = *req.BaseFactor
+	}
+	if req.Precision != nil {
+		uom.Precision = *req.Precision
+	}
+	if req.Rounding != nil {
+		uom.Rounding = *req.Rounding
+	}
+	if req.Description != nil {
+		uom.Description = *req.Description
+	}
+	if req.IsActive != nil {
+		uom.IsActive = *req.IsActive
+	}
+	if err := s.uomRepo.SaveUOM(ctx, uom); err != nil {
+		return nil, fmt.Errorf("更新计量单位失败: %w", err)
+	}
+	return s.GetUOM(ctx, id)
+}
+
+// DeleteUOM 删除未被物料或物料换算引用的计量单位
+func (s *UOMServiceImpl) DeleteUOM(ctx context.Context, id uint) error {
+	uom, err := s.uomRepo.GetUOM(ctx, id)
+	if err != nil {
+		return fmt.Errorf("获取计量单位失败: %w", err)
+	}
+	if uom == nil {
+		return errors.New("计量单位不存在")
+	}
+	usage, err := s.uomRepo.CountUOMUsage(ctx, uom)
+	if err != nil {
+		return fmt.Errorf("获取计量单位引用失败: %w", err)
+	}
+	if usage > 0 {
+		return fmt.Errorf("计量单位 %s 已被物料或单位换算引用，只能停用", uom.Code)
+	}
+	if err := s.uomRepo.DeleteUOM(ctx, id); err != nil {
+		return fmt.Errorf("删除计量单位失败: %w", err)
+	}
+	return nil
+}
+
+// GetUOM 获取计量单位
+func (s *UOMServiceImpl) GetUOM(ctx context.Context, id uint) (*dto.UOMResponse, error) {
+	uom, err := s.uomRepo.GetUOM(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取计量单位失败: %w", err)
+	}
+	if uom == nil {
+		return nil, errors.New("计量单位不存在")
+	}
+	return toUOMResponse(uom), nil
+}
+
+// ListUOMs 获取计量单位
+func (s *UOMServiceImpl) ListUOMs(ctx context.Context, req *dto.UOMListRequest) ([]dto.UOMResponse, error) {
+	uoms, err := s.uomRepo.ListUOMs(ctx, req.CategoryID, req.ActiveOnly)
+	if err != nil {
+		return nil, fmt.Errorf("获取计量单位失败: %w", err)
+	}
+	responses := make([]dto.UOMResponse, 0, len(uoms))
+	for _, uom := range uoms {
+		responses = append(responses, *toUOMResponse(uom))
+	}
+	return responses, nil
+}
+
+// SetItemConversion 设置物料的单位换算，如 1 箱 = 12 个；已存在时更新换算系数
+func (s *UOMServiceImpl) SetItemConversion(ctx context.Context, itemID uint, req *dto.ItemUOMConversionRequest) (*dto.ItemUOMConversionResponse, error) {
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("物料 %d 不存在", itemID)
+	}
+	if item.Unit == "" {
+		return nil, fmt.Errorf("物料 %s 未设置库存单位", item.Code)
+	}
+	code := strings.TrimSpace(req.UOM)
+	if code == item.Unit {
+		return nil, errors.New("库存单位与自身的换算系数固定为 1，无需设置")
+	}
+	uom, err := s.activeUOM(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	conversion, err := s.uomRepo.GetItemConversion(ctx, itemID, uom.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料单位换算失败: %w", err)
+	}
+	if conversion == nil {
+		conversion = &models.ItemUOMConversion{ItemID: itemID, UOMID: uom.ID}
+	}
+	conversion.Factor = req.Factor
+	if err := s.uomRepo.SaveConversion(ctx, conversion); err != nil {
+		return nil, fmt.Errorf("保存物料单位换算失败: %w", err)
+	}
+	conversion.UOM = uom
+	return toItemUOMConversionResponse(conversion, item), nil
+}
+
+// ListItemConversions 获取物料的单位换算
+func (s *UOMServiceImpl) ListItemConversions(ctx context.Context, itemID uint) ([]dto.ItemUOMConversionResponse, error) {
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("物料 %d 不存在", itemID)
+	}
+	conversions, err := s.uomRepo.ListItemConversions(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料单位换算失败: %w", err)
+	}
+	responses := make([]dto.ItemUOMConversionResponse, 0, len(conversions))
+	for _, conversion := range conversions {
+		responses = append(responses, *toItemUOMConversionResponse(conversion, item))
+	}
+	return responses, nil
+}
+
+// DeleteItemConversion 删除物料的单位换算
+func (s *UOMServiceImpl) DeleteItemConversion(ctx context.Context, itemID, id uint) error {
+	conversion, err := s.uomRepo.GetConversion(ctx, id)
+	if err != nil {
+		return fmt.Errorf("获取物料单位换算失败: %w", err)
+	}
+	if conversion == nil || conversion.ItemID != itemID {
+		return errors.New("物料单位换算不存在")
+	}
+	if err := s.uomRepo.DeleteConversion(ctx, id); err != nil {
+		return fmt.Errorf("删除物料单位换算失败: %w", err)
+	}
+	return nil
+}
+
+// ConvertToStock 将单据行数量换算为物料库存单位数量。单位为空时按库存单位处理；
+// 指定的单位必须在计量单位中定义并启用，换算系数优先取物料单位换算，其次取同类别单位的基准系数之比；
+// 单据数量按单据单位舍入，换算结果按库存单位舍入
+func (s *UOMServiceImpl) ConvertToStock(ctx context.Context, itemID uint, uom string, quantity float64) (*dto.UOMConversionResult, error) {
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("物料 %d 不存在", itemID)
+	}
+
+	result := &dto.UOMConversionResult{
+		ItemID:           item.ID,
+		UOM:              strings.TrimSpace(uom),
+		Quantity:         quantity,
+		ConversionFactor: 1,
+		StockUOM:         item.Unit,
+		StockQuantity:    quantity,
+	}
+
+	var stockUOM *models.UOM
+	if item.Unit != "" {
+		if stockUOM, err = s.uomRepo.GetByCode(ctx, item.Unit); err != nil {
+			return nil, fmt.Errorf("获取计量单位失败: %w", err)
+		}
+	}
+
+	// 未指定单位时按库存单位处理，兼容库存单位尚未在计量单位中定义的物料
+	if result.UOM == "" || result.UOM == item.Unit {
+		if result.UOM != "" && stockUOM == nil {
+			return nil, fmt.Errorf("计量单位 %s 未定义", result.UOM)
+		}
+		result.UOM = item.Unit
+		if stockUOM != nil {
+			result.Quantity = stockUOM.Round(quantity)
+			result.StockQuantity = result.Quantity
+		}
+		return checkConvertedQuantity(result, quantity, item)
+	}
+
+	lineUOM, err := s.activeUOM(ctx, result.UOM)
+	if err != nil {
+		return nil, err
+	}
+	if stockUOM == nil {
+		if item.Unit == "" {
+			return nil, fmt.Errorf("物料 %s 未设置库存单位，不能按 %s 换算", item.Code, result.UOM)
+		}
+		return nil, fmt.Errorf("物料 %s 的库存单位 %s 未定义", item.Code, item.Unit)
+	}
+
+	conversion, err := s.uomRepo.GetItemConversion(ctx, item.ID, lineUOM.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取物料单位换算失败: %w", err)
+	}
+	switch {
+	case conversion != nil:
+		result.ConversionFactor = conversion.Factor
+	case lineUOM.CategoryID == stockUOM.CategoryID && stockUOM.BaseFactor > 0:
+		result.ConversionFactor = lineUOM.BaseFactor / stockUOM.BaseFactor
+	default:
+		return nil, fmt.Errorf("物料 %s 没有从 %s 到 %s 的单位换算", item.Code, lineUOM.Code, stockUOM.Code)
+	}
+
+	result.Quantity = lineUOM.Round(quantity)
+	result.StockQuantity = stockUOM.Round(result.Quantity * result.ConversionFactor)
+	return checkConvertedQuantity(result, quantity, item)
+}
+
+// activeUOM 获取启用的计量单位
+func (s *UOMServiceImpl) activeUOM(ctx context.Context, code string) (*models.UOM, error) {
+	uom, err := s.uomRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("获取计量单位失败: %w", err)
+	}
+	if uom == nil {
+		return nil, fmt.Errorf("计量单位 %s 未定义", code)
+	}
+	if !uom.IsActive {
+		return nil, fmt.Errorf("计量单位 %s 已停用", code)
+	}
+	return uom, nil
+}
+
+// checkConvertedQuantity 舍入后数量为 0 时拒绝，避免非零的单据数量被舍入为不移动库存
+func checkConvertedQuantity(result *dto.UOMConversionResult, quantity float64, item *models.Item) (*dto.UOMConversionResult, error) {
+	if quantity > 0 && (result.Quantity <= 0 || result.StockQuantity <= 0) {
+		return nil, fmt.Errorf("物料 %s 的数量 %g %s 按单位舍入后为 0", item.Code, quantity, result.UOM)
+	}
+	return result, nil
+}
+
+// toUOMCategoryResponse 转换为单位类别响应
+func toUOMCategoryResponse(category *models.UOMCategory) *dto.UOMCategoryResponse {
+	return &dto.UOMCategoryResponse{
+		ID:          category.ID,
+		Code:        category.Code,
+		Name:        category.Name,
+		Description: category.Description,
+		CreatedAt:   category.CreatedAt,
+		UpdatedAt:   category.UpdatedAt,
+	}
+}
+
+// toUOMResponse 转换为计量单位响应
+func toUOMResponse(uom *models.UOM) *dto.UOMResponse {
+	response := &dto.UOMResponse{
+		ID:          uom.ID,
+		Code:        uom.Code,
+		Name:        uom.Name,
+		CategoryID:  uom.CategoryID,
+		BaseFactor:  uom.BaseFactor,
+		Precision:   uom.Precision,
+		Rounding:    uom.Rounding,
+		Description: uom.Description,
+		IsActive:    uom.IsActive,
+		CreatedAt:   uom.CreatedAt,
+		UpdatedAt:   uom.UpdatedAt,
+	}
+	if uom.Category != nil {
+		response.CategoryCode = uom.Category.Code
+	}
+	return response
+}
+
+// toItemUOMConversionResponse 转换为物料单位换算响应
+func toItemUOMConversionResponse(conversion *models.ItemUOMConversion, item *models.Item) *dto.ItemUOMConversionResponse {
+	response := &dto.ItemUOMConversionResponse{
+		ID:       conversion.ID,
+		ItemID:   conversion.ItemID,
+		UOMID:    conversion.UOMID,
+		Factor:   conversion.Factor,
+		StockUOM: item.Unit,
+	}
+	if conversion.UOM != nil {
+		response.UOM = conversion.UOM.Code
+		response.UOMName = conversion.UOM.Name
+	}
+	return response
+}
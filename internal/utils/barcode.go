@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// code128Patterns Code 128 各码值的条、空宽度（模块数），依次为条、空交替；106 为终止符
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// Code 128 特殊码值
+const (
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128FNC1   = 102
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// EncodeCode128 将数据编码为 Code 128 的条、空宽度序列（以条开始、条空交替，不含静区）。
+// 连续 4 位以上的数字使用 C 字符集压缩，其余使用 B 字符集；ASCII 29 编码为 FNC1。
+// gs1 为 true 时在起始符后加 FNC1，生成 GS1-128 条码
+func EncodeCode128(data string, gs1 bool) ([]int, error) {
+	if gs1 {
+		data = GS1GroupSeparator + data
+	}
+	if data == "" {
+		return nil, errors.New("条码内容为空")
+	}
+
+	var values []int
+	set := 0
+	use := func(target int) {
+		switch {
+		case set == target:
+		case set == 0 && target == code128CodeC:
+			values = append(values, code128StartC)
+		case set == 0:
+			values = append(values, code128StartB)
+		default:
+			values = append(values, target)
+		}
+		set = target
+	}
+
+	for i := 0; i < len(data); {
+		if data[i] == GS1GroupSeparator[0] {
+			if set == 0 && digitRun(data, i+1) >= 4 {
+				use(code128CodeC)
+			} else if set == 0 {
+				use(code128CodeB)
+			}
+			values = append(values, code128FNC1)
+			i++
+			continue
+		}
+
+		run := digitRun(data, i)
+		if run >= 4 || (set == code128CodeC && run >= 2) {
+			if run%2 == 1 && set != code128CodeC {
+				use(code128CodeB)
+				values = append(values, int(data[i]-' '))
+				i++
+				run--
+			}
+			use(code128CodeC)
+			for ; run >= 2; run -= 2 {
+				values = append(values, int(data[i]-'0')*10+int(data[i+1]-'0'))
+				i += 2
+			}
+			continue
+		}
+
+		c := data[i]
+		if c < ' ' || c > '~' {
+			return nil, fmt.Errorf("条码内容包含 Code 128 不支持的字符 %q", c)
+		}
+		use(code128CodeB)
+		values = append(values, int(c-' '))
+		i++
+	}
+
+	checksum := values[0]
+	for i := 1; i < len(values); i++ {
+		checksum += i * values[i]
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	var widths []int
+	for _, value := range values {
+		for _, w := range code128Patterns[value] {
+			widths = append(widths, int(w-'0'))
+		}
+	}
+	return widths, nil
+}
+
+// digitRun 返回从 start 开始的连续数字个数
+func digitRun(data string, start int) int {
+	n := 0
+	for i := start; i < len(data) && data[i] >= '0' && data[i] <= '9'; i++ {
+		n++
+	}
+	return n
+}
+
+// EAN-13 数据字符的 L、G、R 编码及首位数字决定的左半部分奇偶组合
+var (
+	ean13L      = [...]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	ean13G      = [...]string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+	ean13R      = [...]string{"1110010", "1100110", "1101100", "1000010", "1011100", "1001110", "1010000", "1000100", "1001000", "1110100"}
+	ean13Parity = [...]string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+)
+
+// EncodeEAN13 将 EAN-13 或 UPC-A 编码为 95 个模块（'1' 为条、'0' 为空，不含静区），
+// 输入 12 位（UPC-A 视为首位补 0 的 EAN-13）或 13 位数字并校验校验位
+func EncodeEAN13(code string) (string, error) {
+	if len(code) == 12 {
+		code = "0" + code
+	}
+	if len(code) != 13 || !ValidGTIN(code) {
+		return "", fmt.Errorf("%s 不是有效的 EAN-13/UPC-A 条码", code)
+	}
+
+	var modules strings.Builder
+	modules.WriteString("101")
+	parity := ean13Parity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		digit := code[i] - '0'
+		if parity[i-1] == 'L' {
+			modules.WriteString(ean13L[digit])
+		} else {
+			modules.WriteString(ean13G[digit])
+		}
+	}
+	modules.WriteString("01010")
+	for i := 7; i <= 12; i++ {
+		modules.WriteString(ean13R[code[i]-'0'])
+	}
+	modules.WriteString("101")
+	return modules.String(), nil
+}
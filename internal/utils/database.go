@@ -13,6 +13,10 @@ import (
 
 var DB *gorm.DB
 
+// sqliteDSN SQLite 连接串：写事务以 BEGIN IMMEDIATE 开始并在锁冲突时等待，
+// 保证库存过账等读后写事务串行执行
+const sqliteDSN = "galaxyerp.db?_pragma=busy_timeout(5000)&_txlock=immediate"
+
 // ConnectDatabase establishes a connection to the database
 func ConnectDatabase() {
 	var err error
@@ -27,7 +31,7 @@ func ConnectDatabase() {
 	case "dev":
 		// Use SQLite for development
 		zap.L().Info("Using SQLite for development")
-		DB, err = gorm.Open(sqlite.Open("galaxyerp.db"), &gorm.Config{})
+		DB, err = gorm.Open(sqlite.Open(sqliteDSN), &gorm.Config{})
 		if err != nil {
 			zap.L().Fatal("Failed to connect to SQLite database", zap.Error(err))
 		}
@@ -52,7 +56,7 @@ func ConnectDatabase() {
 	default:
 		// Default to SQLite for development
 		zap.L().Info("Using SQLite for development (default)")
-		DB, err = gorm.Open(sqlite.Open("galaxyerp.db"), &gorm.Config{})
+		DB, err = gorm.Open(sqlite.Open(sqliteDSN), &gorm.Config{})
 		if err != nil {
 			zap.L().Fatal("Failed to connect to SQLite database", zap.Error(err))
 		}
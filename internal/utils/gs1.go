@@ -0,0 +1,338 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GS1GroupSeparator GS1 条码中变长数据之后的分隔符（FNC1 扫描后输出为 ASCII 29）
+const GS1GroupSeparator = "\x1d"
+
+// GS1Element GS1 应用标识符及其数据
+type GS1Element struct {
+	AI    string `json:"ai"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// GS1Data 解析后的 GS1 条码内容
+type GS1Data struct {
+	Elements       []GS1Element `json:"elements"`
+	SSCC           string       `json:"sscc,omitempty"`
+	GTIN           string       `json:"gtin,omitempty"`
+	BatchNo        string       `json:"batch_no,omitempty"`
+	SerialNo       string       `json:"serial_no,omitempty"`
+	ProductionDate *time.Time   `json:"production_date,omitempty"`
+	ExpiryDate     *time.Time   `json:"expiry_date,omitempty"` // AI 17 有效期，没有时取 AI 15 保质期
+	Quantity       float64      `json:"quantity,omitempty"`    // AI 30/37 数量
+	NetWeight      float64      `json:"net_weight,omitempty"`  // AI 310n 净重（千克）
+	GLN            string       `json:"gln,omitempty"`         // AI 414 位置码
+}
+
+// gs1AI 应用标识符定义；length 为定长数据的长度，为 0 时数据变长，最长 max 位，以分隔符或条码结尾结束
+type gs1AI struct {
+	name   string
+	length int
+	max    int
+}
+
+// gs1AIs 支持的应用标识符，310n 的第 4 位为小数位数，单独处理
+var gs1AIs = map[string]gs1AI{
+	"00":  {"SSCC", 18, 18},
+	"01":  {"GTIN", 14, 14},
+	"02":  {"CONTENT", 14, 14},
+	"10":  {"BATCH/LOT", 0, 20},
+	"11":  {"PROD DATE", 6, 6},
+	"13":  {"PACK DATE", 6, 6},
+	"15":  {"BEST BEFORE", 6, 6},
+	"17":  {"USE BY", 6, 6},
+	"21":  {"SERIAL", 0, 20},
+	"30":  {"VAR. COUNT", 0, 8},
+	"37":  {"COUNT", 0, 8},
+	"240": {"ADDITIONAL ID", 0, 30},
+	"400": {"ORDER NUMBER", 0, 30},
+	"414": {"LOC No", 13, 13},
+}
+
+// gs1SymbologyPrefixes 扫描枪可能附加的符号标识符
+var gs1SymbologyPrefixes = []string{"]C1", "]e0", "]d2", "]Q3"}
+
+// gs1BracketPattern 人工可读格式中的应用标识符，如 (01)09501101020917(10)ABC
+var gs1BracketPattern = regexp.MustCompile(`\((\d{2,4})\)([^(]*)`)
+
+// IsGS1 判断扫描内容是否为 GS1 格式：带 GS1 符号标识符、人工可读的括号格式、含分隔符，
+// 或以 SSCC/GTIN 应用标识符开头且长于单独的商品条码
+func IsGS1(code string) bool {
+	for _, prefix := range gs1SymbologyPrefixes {
+		if strings.HasPrefix(code, prefix) {
+			return true
+		}
+	}
+	if gs1BracketPattern.MatchString(code) && strings.HasPrefix(code, "(") {
+		return true
+	}
+	if strings.Contains(code, GS1GroupSeparator) {
+		return true
+	}
+	if len(code) > 14 && (strings.HasPrefix(code, "00") || strings.HasPrefix(code, "01") || strings.HasPrefix(code, "02")) {
+		_, err := ParseGS1(code)
+		return err == nil
+	}
+	return false
+}
+
+// ParseGS1 解析 GS1-128、GS1 DataMatrix 等扫描内容，支持原始格式（变长数据以 ASCII 29 分隔）与括号格式
+func ParseGS1(code string) (*GS1Data, error) {
+	code = strings.TrimSpace(code)
+	for _, prefix := range gs1SymbologyPrefixes {
+		code = strings.TrimPrefix(code, prefix)
+	}
+	if code == "" {
+		return nil, errors.New("条码内容为空")
+	}
+
+	var elements []GS1Element
+	if strings.HasPrefix(code, "(") {
+		matches := gs1BracketPattern.FindAllStringSubmatch(code, -1)
+		if len(matches) == 0 {
+			return nil, errors.New("无法识别的 GS1 括号格式")
+		}
+		for _, match := range matches {
+			name, err := gs1AIName(match[1])
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, GS1Element{AI: match[1], Name: name, Value: strings.TrimSpace(match[2])})
+		}
+	} else {
+		parsed, err := parseRawGS1(strings.TrimPrefix(code, GS1GroupSeparator))
+		if err != nil {
+			return nil, err
+		}
+		elements = parsed
+	}
+
+	data := &GS1Data{Elements: elements}
+	var bestBefore *time.Time
+	for _, element := range elements {
+		if err := applyGS1Element(data, element, &bestBefore); err != nil {
+			return nil, err
+		}
+	}
+	if data.ExpiryDate == nil {
+		data.ExpiryDate = bestBefore
+	}
+	return data, nil
+}
+
+// parseRawGS1 按应用标识符定义逐段解析原始格式
+func parseRawGS1(code string) ([]GS1Element, error) {
+	var elements []GS1Element
+	for len(code) > 0 {
+		if strings.HasPrefix(code, GS1GroupSeparator) {
+			code = code[len(GS1GroupSeparator):]
+			continue
+		}
+		ai, def, err := matchGS1AI(code)
+		if err != nil {
+			return nil, err
+		}
+		code = code[len(ai):]
+
+		var value string
+		if def.length > 0 {
+			if len(code) < def.length {
+				return nil, fmt.Errorf("应用标识符 (%s) 的数据长度不足 %d 位", ai, def.length)
+			}
+			value, code = code[:def.length], code[def.length:]
+		} else {
+			end := strings.Index(code, GS1GroupSeparator)
+			if end < 0 {
+				end = len(code)
+			}
+			value, code = code[:end], code[end:]
+			if len(value) > def.max {
+				return nil, fmt.Errorf("应用标识符 (%s) 的数据超过 %d 位，可能缺少分隔符", ai, def.max)
+			}
+		}
+		elements = append(elements, GS1Element{AI: ai, Name: def.name, Value: value})
+	}
+	if len(elements) == 0 {
+		return nil, errors.New("条码中没有应用标识符")
+	}
+	return elements, nil
+}
+
+// matchGS1AI 匹配数据开头的应用标识符
+func matchGS1AI(code string) (string, gs1AI, error) {
+	if len(code) >= 4 && strings.HasPrefix(code, "310") && code[3] >= '0' && code[3] <= '9' {
+		return code[:4], gs1AI{name: "NET WEIGHT (kg)", length: 6, max: 6}, nil
+	}
+	for _, size := range []int{2, 3} {
+		if len(code) < size {
+			break
+		}
+		if def, ok := gs1AIs[code[:size]]; ok {
+			return code[:size], def, nil
+		}
+	}
+	prefix := code
+	if len(prefix) > 4 {
+		prefix = prefix[:4]
+	}
+	return "", gs1AI{}, fmt.Errorf("不支持的应用标识符: %s", prefix)
+}
+
+// gs1AIName 返回应用标识符名称，不支持的应用标识符返回错误
+func gs1AIName(ai string) (string, error) {
+	if def, ok := gs1AIs[ai]; ok {
+		return def.name, nil
+	}
+	if len(ai) == 4 && strings.HasPrefix(ai, "310") {
+		return "NET WEIGHT (kg)", nil
+	}
+	return "", fmt.Errorf("不支持的应用标识符: %s", ai)
+}
+
+// applyGS1Element 将应用标识符数据写入解析结果
+func applyGS1Element(data *GS1Data, element GS1Element, bestBefore **time.Time) error {
+	switch element.AI {
+	case "00":
+		data.SSCC = element.Value
+	case "01", "02":
+		if !ValidGTIN(element.Value) {
+			return fmt.Errorf("GTIN %s 校验位错误", element.Value)
+		}
+		data.GTIN = element.Value
+	case "10":
+		data.BatchNo = element.Value
+	case "21":
+		data.SerialNo = element.Value
+	case "11", "13", "15", "17":
+		date, err := parseGS1Date(element.Value)
+		if err != nil {
+			return fmt.Errorf("应用标识符 (%s) 日期格式错误: %w", element.AI, err)
+		}
+		switch element.AI {
+		case "11":
+			data.ProductionDate = &date
+		case "15":
+			*bestBefore = &date
+		case "17":
+			data.ExpiryDate = &date
+		}
+	case "30", "37":
+		quantity, err := strconv.ParseFloat(element.Value, 64)
+		if err != nil {
+			return fmt.Errorf("应用标识符 (%s) 数量格式错误", element.AI)
+		}
+		data.Quantity = quantity
+	case "414":
+		data.GLN = element.Value
+	default:
+		if strings.HasPrefix(element.AI, "310") {
+			weight, err := strconv.ParseFloat(element.Value, 64)
+			if err != nil {
+				return fmt.Errorf("应用标识符 (%s) 重量格式错误", element.AI)
+			}
+			decimals := float64(element.AI[3] - '0')
+			data.NetWeight = weight / math.Pow(10, decimals)
+		}
+	}
+	return nil
+}
+
+// parseGS1Date 解析 YYMMDD 日期：日为 00 表示当月最后一天；
+// 年份按 GS1 规则取离当前年份最近的世纪（比当前年份晚 51 年以上视为上个世纪）
+func parseGS1Date(value string) (time.Time, error) {
+	if len(value) != 6 {
+		return time.Time{}, errors.New("日期应为 6 位 YYMMDD")
+	}
+	yy, err1 := strconv.Atoi(value[0:2])
+	month, err2 := strconv.Atoi(value[2:4])
+	day, err3 := strconv.Atoi(value[4:6])
+	if err1 != nil || err2 != nil || err3 != nil || month < 1 || month > 12 || day > 31 {
+		return time.Time{}, fmt.Errorf("无效日期 %s", value)
+	}
+
+	current := time.Now().Year()
+	year := current/100*100 + yy
+	switch diff := yy - current%100; {
+	case diff >= 51:
+		year -= 100
+	case diff <= -50:
+		year += 100
+	}
+	if day == 0 {
+		return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.Local), nil
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
+	if date.Day() != day {
+		return time.Time{}, fmt.Errorf("无效日期 %s", value)
+	}
+	return date, nil
+}
+
+// ValidGTIN 校验 GTIN-8、UPC-A（GTIN-12）、EAN-13（GTIN-13）与 GTIN-14 的长度与校验位
+func ValidGTIN(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return GTINCheckDigit(code[:len(code)-1]) == code[len(code)-1]
+}
+
+// GTINCheckDigit 按模 10 算法计算 GTIN 校验位，body 为不含校验位的数字
+func GTINCheckDigit(body string) byte {
+	sum := 0
+	for i := 0; i < len(body); i++ {
+		digit := int(body[len(body)-1-i] - '0')
+		if i%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// NormalizeGTIN 将有效的 GTIN 左补零为 14 位，便于 EAN-13、UPC-A 与 GS1 条码中的 GTIN 互相匹配；
+// 不是有效 GTIN 时返回空字符串
+func NormalizeGTIN(code string) string {
+	if !ValidGTIN(code) {
+		return ""
+	}
+	return strings.Repeat("0", 14-len(code)) + code
+}
+
+// FormatGS1 将应用标识符与数据拼接为人工可读的括号格式
+func FormatGS1(elements []GS1Element) string {
+	var builder strings.Builder
+	for _, element := range elements {
+		builder.WriteString("(" + element.AI + ")" + element.Value)
+	}
+	return builder.String()
+}
+
+// EncodeGS1 将应用标识符与数据拼接为 GS1-128 编码数据，变长数据之后以 ASCII 29 表示 FNC1 分隔
+func EncodeGS1(elements []GS1Element) string {
+	var builder strings.Builder
+	for i, element := range elements {
+		builder.WriteString(element.AI + element.Value)
+		ai, def, err := matchGS1AI(element.AI + element.Value)
+		if err == nil && ai == element.AI && def.length == 0 && i < len(elements)-1 {
+			builder.WriteString(GS1GroupSeparator)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// 标签条码类型
+const (
+	LabelSymbologyEAN13   = "ean13"
+	LabelSymbologyCode128 = "code128"
+	LabelSymbologyGS1128  = "gs1-128"
+)
+
+// Label 一张标签：标题、若干文字行与一个条码
+type Label struct {
+	Title     string
+	Lines     []string
+	Symbology string
+	Barcode   string // 条码数据；GS1-128 为 EncodeGS1 生成的编码数据
+	Text      string // 条码下方的人工可读文字，GS1-128 为括号格式
+}
+
+// 标签尺寸：4 × 2 英寸，ZPL 按 203 dpi 计算点数，PDF 按 72 点/英寸
+const (
+	labelZPLWidth  = 812
+	labelZPLHeight = 406
+	labelPDFWidth  = 288.0
+	labelPDFHeight = 144.0
+)
+
+// RenderZPL 生成斑马打印机 ZPL 指令，每张标签打印 copies 份。
+// 文字以 UTF-8 输出（^CI28），打印中文需要打印机已加载中文字体
+func RenderZPL(labels []Label, copies int) []byte {
+	if copies < 1 {
+		copies = 1
+	}
+	var buf bytes.Buffer
+	for _, label := range labels {
+		buf.WriteString("^XA\n^CI28\n")
+		fmt.Fprintf(&buf, "^PW%d\n^LL%d\n", labelZPLWidth, labelZPLHeight)
+		fmt.Fprintf(&buf, "^FO30,20^A0N,40,40^FH^FD%s^FS\n", zplEscape(label.Title))
+		y := 70
+		for _, line := range label.Lines {
+			fmt.Fprintf(&buf, "^FO30,%d^A0N,28,28^FH^FD%s^FS\n", y, zplEscape(line))
+			y += 34
+		}
+		y += 10
+		switch label.Symbology {
+		case LabelSymbologyEAN13:
+			code := label.Barcode
+			if len(code) == 12 {
+				code = "0" + code
+			}
+			// ^BE 只接收前 12 位，校验位由打印机计算
+			fmt.Fprintf(&buf, "^FO60,%d^BY3^BEN,100,Y,N^FD%s^FS\n", y, code[:12])
+		case LabelSymbologyGS1128:
+			// D 模式按括号格式识别应用标识符并自动插入 FNC1
+			fmt.Fprintf(&buf, "^FO30,%d^BY2^BCN,100,Y,N,N,D^FD%s^FS\n", y, label.Text)
+		default:
+			fmt.Fprintf(&buf, "^FO30,%d^BY2^BCN,100,Y,N,N^FH^FD%s^FS\n", y, zplEscape(label.Barcode))
+		}
+		fmt.Fprintf(&buf, "^PQ%d\n^XZ\n", copies)
+	}
+	return buf.Bytes()
+}
+
+// zplEscape 按 ^FH 的十六进制转义写出 ZPL 控制字符
+func zplEscape(text string) string {
+	replacer := strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E")
+	return replacer.Replace(text)
+}
+
+// RenderLabelPDF 生成标签 PDF，每张标签一页，每张标签输出 copies 页。
+// 文字使用 PDF 阅读器内置的 STSong-Light 中文字体，不嵌入字体文件；条码以矩形绘制
+func RenderLabelPDF(labels []Label, copies int) ([]byte, error) {
+	if copies < 1 {
+		copies = 1
+	}
+	var pages []string
+	for _, label := range labels {
+		content, err := labelPDFContent(label)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < copies; i++ {
+			pages = append(pages, content)
+		}
+	}
+	return writeLabelPDF(pages), nil
+}
+
+// labelPDFContent 生成一张标签的页面内容流
+func labelPDFContent(label Label) (string, error) {
+	var content strings.Builder
+	pdfText(&content, 12, labelPDFHeight-24, 14, label.Title)
+	y := labelPDFHeight - 40
+	for _, line := range label.Lines {
+		pdfText(&content, 12, y, 9, line)
+		y -= 12
+	}
+
+	// 条码区域：底部 14 点留给人工可读文字，左右各留 12 点
+	const barBottom, margin = 18.0, 12.0
+	barHeight := y - 4 - barBottom
+	if barHeight < 20 {
+		barHeight = 20
+	}
+	available := labelPDFWidth - 2*margin
+
+	switch label.Symbology {
+	case LabelSymbologyEAN13:
+		modules, err := EncodeEAN13(label.Barcode)
+		if err != nil {
+			return "", err
+		}
+		module := available / float64(len(modules))
+		if module > 2 {
+			module = 2
+		}
+		x := margin
+		for _, bit := range modules {
+			if bit == '1' {
+				fmt.Fprintf(&content, "%.3f %.3f %.3f %.3f re\n", x, barBottom, module, barHeight)
+			}
+			x += module
+		}
+	default:
+		widths, err := EncodeCode128(label.Barcode, label.Symbology == LabelSymbologyGS1128)
+		if err != nil {
+			return "", err
+		}
+		total := 0
+		for _, w := range widths {
+			total += w
+		}
+		module := available / float64(total)
+		if module > 2 {
+			module = 2
+		}
+		x := margin
+		for i, w := range widths {
+			if i%2 == 0 {
+				fmt.Fprintf(&content, "%.3f %.3f %.3f %.3f re\n", x, barBottom, module*float64(w), barHeight)
+			}
+			x += module * float64(w)
+		}
+	}
+	content.WriteString("f\n")
+
+	text := label.Text
+	if text == "" {
+		text = label.Barcode
+	}
+	pdfText(&content, margin, 6, 9, text)
+	return content.String(), nil
+}
+
+// pdfText 在指定位置写一行文字，文字按 UCS-2 大端十六进制编码
+func pdfText(content *strings.Builder, x, y, size float64, text string) {
+	fmt.Fprintf(content, "BT /F1 %.1f Tf %.2f %.2f Td <", size, x, y)
+	for _, unit := range utf16.Encode([]rune(text)) {
+		if unit >= 0xD800 && unit <= 0xDFFF {
+			unit = '?'
+		}
+		fmt.Fprintf(content, "%04X", unit)
+	}
+	content.WriteString("> Tj ET\n")
+}
+
+// writeLabelPDF 组装 PDF 文件：目录、页面树、中文字体及每页的页面对象与内容流
+func writeLabelPDF(pages []string) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+i*2)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	object("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 4 >> /FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	object("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			labelPDFWidth, labelPDFHeight, 7+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
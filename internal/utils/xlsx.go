@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// xlsxStaticParts 仅含一个工作表的 XLSX 文件固定部件
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`},
+}
+
+// WriteXLSX 生成只含一个工作表的 XLSX 文件。
+// 单元格为 int、int64、float64 时写为数值，其余按字符串写入
+func WriteXLSX(sheetName string, rows [][]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for _, part := range xlsxStaticParts {
+		if err := writeZipPart(zw, part.name, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	var workbook bytes.Buffer
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`)
+	if err := xml.EscapeText(&workbook, []byte(sheetName)); err != nil {
+		return nil, err
+	}
+	workbook.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+	if err := writeZipPart(zw, "xl/workbook.xml", workbook.String()); err != nil {
+		return nil, err
+	}
+
+	var sheet bytes.Buffer
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, r+1)
+		for c, value := range row {
+			ref := xlsxColumnName(c) + strconv.Itoa(r+1)
+			if err := writeXLSXCell(&sheet, ref, value); err != nil {
+				return nil, err
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+	if err := writeZipPart(zw, "xl/worksheets/sheet1.xml", sheet.String()); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeXLSXCell 写入单元格，数值直接写入，字符串使用内联字符串
+func writeXLSXCell(w io.Writer, ref string, value interface{}) error {
+	var number string
+	switch v := value.(type) {
+	case int:
+		number = strconv.Itoa(v)
+	case int64:
+		number = strconv.FormatInt(v, 10)
+	case float64:
+		number = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	if number != "" {
+		_, err := fmt.Fprintf(w, `<c r="%s"><v>%s</v></c>`, ref, number)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref); err != nil {
+		return err
+	}
+	if err := xml.EscapeText(w, []byte(fmt.Sprint(value))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, `</t></is></c>`)
+	return err
+}
+
+// writeZipPart 向压缩包写入一个文件
+func writeZipPart(zw *zip.Writer, name, content string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+	return err
+}
+
+// xlsxColumnName 将从 0 开始的列序号转换为 A、B、…、AA 形式的列名
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
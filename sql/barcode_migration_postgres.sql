@@ -0,0 +1,28 @@
+-- ============================================================================
+-- GalaxyERP 物料条码迁移 - PostgreSQL 脚本
+-- 说明: 物料可按不同单位登记多个条码（EAN-13、UPC-A、EAN-8、GTIN-14、Code 128），
+--       GTIN 类条码另存补零为 14 位的 GTIN，用于匹配 GS1-128 条码中的 (01) 应用标识符
+-- ============================================================================
+
+BEGIN;
+
+-- item_barcodes: 物料条码
+CREATE TABLE IF NOT EXISTS item_barcodes (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  item_id INTEGER NOT NULL,
+  barcode VARCHAR(100) NOT NULL,
+  gtin VARCHAR(14) NULL,
+  barcode_type VARCHAR(20) NOT NULL,
+  uom VARCHAR(50) NULL,
+  is_primary BOOLEAN DEFAULT FALSE,
+  description VARCHAR(255) NULL
+);
+CREATE INDEX IF NOT EXISTS idx_item_barcodes_deleted_at ON item_barcodes (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_item_barcodes_item_id ON item_barcodes (item_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_barcodes_barcode ON item_barcodes (barcode);
+CREATE INDEX IF NOT EXISTS idx_item_barcodes_gtin ON item_barcodes (gtin);
+
+COMMIT;
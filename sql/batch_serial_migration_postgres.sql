@@ -0,0 +1,92 @@
+-- ============================================================================
+-- GalaxyERP 批次与序列号跟踪迁移 - PostgreSQL 脚本
+-- 说明: 物料可按批次或序列号跟踪；库存移动同步维护批次库存与序列号所在仓库，
+--       并记录每笔移动涉及的序列号，用于批次、序列号的正反向追溯
+-- ============================================================================
+
+BEGIN;
+
+-- items: 跟踪方式 none / batch / serial
+ALTER TABLE IF EXISTS items
+  ADD COLUMN IF NOT EXISTS tracking_mode VARCHAR(20) DEFAULT 'none';
+UPDATE items SET tracking_mode = 'none' WHERE tracking_mode IS NULL OR tracking_mode = '';
+
+-- batches: 批次主数据
+CREATE TABLE IF NOT EXISTS batches (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  item_id INTEGER NOT NULL,
+  batch_no VARCHAR(100) NOT NULL,
+  manufacture_date TIMESTAMP WITH TIME ZONE NULL,
+  expiry_date TIMESTAMP WITH TIME ZONE NULL,
+  supplier_id INTEGER NULL,
+  supplier_lot_no VARCHAR(100) NULL,
+  notes TEXT NULL,
+  is_active BOOLEAN DEFAULT TRUE
+);
+CREATE INDEX IF NOT EXISTS idx_batches_deleted_at ON batches (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_batches_item_batch ON batches (item_id, batch_no);
+CREATE INDEX IF NOT EXISTS idx_batches_expiry_date ON batches (expiry_date);
+CREATE INDEX IF NOT EXISTS idx_batches_supplier_id ON batches (supplier_id);
+
+-- batch_stocks: 批次在仓库的库存余额
+CREATE TABLE IF NOT EXISTS batch_stocks (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  item_id INTEGER NOT NULL,
+  warehouse_id INTEGER NOT NULL,
+  batch_id INTEGER NOT NULL,
+  quantity NUMERIC DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_batch_stocks_deleted_at ON batch_stocks (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_batch_stocks_item_warehouse_batch ON batch_stocks (item_id, warehouse_id, batch_id);
+CREATE INDEX IF NOT EXISTS idx_batch_stocks_batch_id ON batch_stocks (batch_id);
+
+-- serial_numbers: 序列号主数据，出库后 warehouse_id 为空
+CREATE TABLE IF NOT EXISTS serial_numbers (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  item_id INTEGER NOT NULL,
+  serial_no VARCHAR(100) NOT NULL,
+  batch_id INTEGER NULL,
+  warehouse_id INTEGER NULL,
+  status VARCHAR(20) DEFAULT 'in_stock',
+  manufacture_date TIMESTAMP WITH TIME ZONE NULL,
+  expiry_date TIMESTAMP WITH TIME ZONE NULL,
+  supplier_id INTEGER NULL,
+  last_movement_id INTEGER NULL
+);
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_deleted_at ON serial_numbers (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_serial_numbers_item_serial ON serial_numbers (item_id, serial_no);
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_batch_id ON serial_numbers (batch_id);
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_warehouse_id ON serial_numbers (warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_status ON serial_numbers (status);
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_supplier_id ON serial_numbers (supplier_id);
+
+-- movement_serial_nos: 库存移动涉及的序列号
+CREATE TABLE IF NOT EXISTS movement_serial_nos (
+  id SERIAL PRIMARY KEY,
+  movement_id INTEGER NOT NULL,
+  serial_number_id INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_movement_serial_nos_movement_id ON movement_serial_nos (movement_id);
+CREATE INDEX IF NOT EXISTS idx_movement_serial_nos_serial_number_id ON movement_serial_nos (serial_number_id);
+
+-- movements: 批次
+ALTER TABLE IF EXISTS movements
+  ADD COLUMN IF NOT EXISTS batch_id INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_movements_batch_id ON movements (batch_id);
+
+-- stock_transfer_items: 调拨的批次与序列号
+ALTER TABLE IF EXISTS stock_transfer_items
+  ADD COLUMN IF NOT EXISTS batch_no VARCHAR(100) NULL,
+  ADD COLUMN IF NOT EXISTS serial_no TEXT NULL,
+  ADD COLUMN IF NOT EXISTS received_serial_no TEXT NULL;
+
+COMMIT;
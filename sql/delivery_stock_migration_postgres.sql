@@ -0,0 +1,11 @@
+-- ============================================================================
+-- GalaxyERP 送货单出库迁移 - PostgreSQL 脚本
+-- 说明: 物料允许超出销售订单数量发货的百分比；送货单提交时按明细出库并回写
+--       销售订单明细的已发货数量，取消时冲销
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE IF EXISTS items ADD COLUMN IF NOT EXISTS over_delivery_allowance DOUBLE PRECISION DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,13 @@
+-- ============================================================================
+-- GalaxyERP 批次效期迁移 - PostgreSQL 脚本
+-- 说明: 物料保质期天数用于推算新批次的有效期；隔离库位使用 location_type = 'quarantine'，
+--       过期批次不能出库，定时任务将其移入隔离库位
+-- ============================================================================
+
+BEGIN;
+
+-- items: 保质期天数
+ALTER TABLE IF EXISTS items
+  ADD COLUMN IF NOT EXISTS shelf_life_days BIGINT DEFAULT 0;
+
+COMMIT;
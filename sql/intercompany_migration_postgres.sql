@@ -0,0 +1,181 @@
+-- ============================================================================
+-- GalaxyERP 多公司账簿与内部交易迁移 - PostgreSQL 脚本
+-- 说明: 为科目、凭证与业务单据增加 company_id，历史数据归属默认公司（id = 1）；
+--       科目编码与财政年度改为公司内唯一；新增内部往来标记与内部交易关联表
+-- ============================================================================
+
+BEGIN;
+
+-- companies: 集团层级与本位币
+ALTER TABLE IF EXISTS companies
+  ADD COLUMN IF NOT EXISTS parent_id INTEGER NULL,
+  ADD COLUMN IF NOT EXISTS default_currency VARCHAR(10) DEFAULT 'CNY';
+CREATE INDEX IF NOT EXISTS idx_companies_parent_id ON companies (parent_id);
+
+-- 默认公司，历史数据归属于此
+INSERT INTO companies (id, created_at, updated_at, is_active, code, name, default_currency)
+SELECT 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, TRUE, 'DEFAULT', '默认公司', 'CNY'
+WHERE NOT EXISTS (SELECT 1 FROM companies);
+SELECT setval(pg_get_serial_sequence('companies', 'id'), GREATEST((SELECT MAX(id) FROM companies), 1));
+
+-- ----------------------------------------------------------------------------
+-- 财务会计
+-- ----------------------------------------------------------------------------
+
+-- accounts: 科目编码改为公司内唯一
+ALTER TABLE IF EXISTS accounts
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE IF EXISTS accounts DROP CONSTRAINT IF EXISTS uq_accounts_code;
+DROP INDEX IF EXISTS idx_accounts_code;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_company_code ON accounts (company_id, code);
+
+-- transactions
+ALTER TABLE IF EXISTS transactions
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_transactions_company_id ON transactions (company_id);
+
+-- journal_entries: 所属公司与内部往来对方公司
+ALTER TABLE IF EXISTS journal_entries
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1,
+  ADD COLUMN IF NOT EXISTS intercompany_company_id INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_journal_entries_company_id ON journal_entries (company_id);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_intercompany_company_id ON journal_entries (intercompany_company_id);
+
+-- payments
+ALTER TABLE IF EXISTS payments
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_payments_company_id ON payments (company_id);
+
+-- budgets
+ALTER TABLE IF EXISTS budgets
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_budgets_company_id ON budgets (company_id);
+
+-- fixed_assets
+ALTER TABLE IF EXISTS fixed_assets
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_fixed_assets_company_id ON fixed_assets (company_id);
+
+-- tax_entries
+ALTER TABLE IF EXISTS tax_entries
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_tax_entries_company_id ON tax_entries (company_id);
+
+-- bank_accounts
+ALTER TABLE IF EXISTS bank_accounts
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_bank_accounts_company_id ON bank_accounts (company_id);
+
+-- receivables
+ALTER TABLE IF EXISTS receivables
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_receivables_company_id ON receivables (company_id);
+
+-- payables
+ALTER TABLE IF EXISTS payables
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_payables_company_id ON payables (company_id);
+
+-- payment_entries
+ALTER TABLE IF EXISTS payment_entries
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_payment_entries_company_id ON payment_entries (company_id);
+
+-- fiscal_years: 财政年度改为公司内唯一
+ALTER TABLE IF EXISTS fiscal_years
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+DROP INDEX IF EXISTS idx_fiscal_years_year;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fiscal_years_company_year ON fiscal_years (company_id, year);
+
+-- accounting_periods: 以 company_id 取代原 company 文本列，并关联财政年度
+ALTER TABLE IF EXISTS accounting_periods
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1,
+  ADD COLUMN IF NOT EXISTS fiscal_year_id INTEGER NULL;
+ALTER TABLE IF EXISTS accounting_periods DROP COLUMN IF EXISTS company;
+CREATE INDEX IF NOT EXISTS idx_accounting_periods_company_id ON accounting_periods (company_id);
+CREATE INDEX IF NOT EXISTS idx_accounting_periods_fiscal_year_id ON accounting_periods (fiscal_year_id);
+
+-- ----------------------------------------------------------------------------
+-- 销售与采购单据
+-- ----------------------------------------------------------------------------
+
+-- customers / suppliers: 代表集团内其他公司的内部往来单位
+ALTER TABLE IF EXISTS customers
+  ADD COLUMN IF NOT EXISTS represents_company_id INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_customers_represents_company_id ON customers (represents_company_id);
+
+ALTER TABLE IF EXISTS suppliers
+  ADD COLUMN IF NOT EXISTS represents_company_id INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_suppliers_represents_company_id ON suppliers (represents_company_id);
+
+-- quotations
+ALTER TABLE IF EXISTS quotations
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_quotations_company_id ON quotations (company_id);
+
+-- sales_orders
+ALTER TABLE IF EXISTS sales_orders
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_sales_orders_company_id ON sales_orders (company_id);
+
+-- delivery_notes
+ALTER TABLE IF EXISTS delivery_notes
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_delivery_notes_company_id ON delivery_notes (company_id);
+
+-- sales_invoices
+ALTER TABLE IF EXISTS sales_invoices
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_sales_invoices_company_id ON sales_invoices (company_id);
+
+-- purchase_requests
+ALTER TABLE IF EXISTS purchase_requests
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_purchase_requests_company_id ON purchase_requests (company_id);
+
+-- purchase_orders
+ALTER TABLE IF EXISTS purchase_orders
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_company_id ON purchase_orders (company_id);
+
+-- purchase_receipts
+ALTER TABLE IF EXISTS purchase_receipts
+  ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_purchase_receipts_company_id ON purchase_receipts (company_id);
+
+-- ----------------------------------------------------------------------------
+-- 内部交易关联表
+-- ----------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS intercompany_transactions (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  created_by INTEGER NULL,
+  updated_by INTEGER NULL,
+  source_company_id INTEGER NOT NULL,
+  target_company_id INTEGER NOT NULL,
+  source_doc_type VARCHAR(50) NOT NULL,
+  source_doc_id INTEGER NOT NULL,
+  source_doc_number VARCHAR(100) NULL,
+  target_doc_type VARCHAR(50) NOT NULL,
+  target_doc_id INTEGER NOT NULL,
+  target_doc_number VARCHAR(100) NULL,
+  amount NUMERIC(20,4) DEFAULT 0,
+  currency VARCHAR(10) DEFAULT 'CNY',
+  transaction_date TIMESTAMP WITH TIME ZONE NOT NULL,
+  status VARCHAR(50) DEFAULT 'linked',
+  CONSTRAINT fk_intercompany_source_company FOREIGN KEY (source_company_id) REFERENCES companies(id),
+  CONSTRAINT fk_intercompany_target_company FOREIGN KEY (target_company_id) REFERENCES companies(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_intercompany_source ON intercompany_transactions (source_doc_type, source_doc_id);
+CREATE INDEX IF NOT EXISTS idx_intercompany_transactions_deleted_at ON intercompany_transactions (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_intercompany_transactions_source_company_id ON intercompany_transactions (source_company_id);
+CREATE INDEX IF NOT EXISTS idx_intercompany_transactions_target_company_id ON intercompany_transactions (target_company_id);
+CREATE INDEX IF NOT EXISTS idx_intercompany_transactions_target_doc_type ON intercompany_transactions (target_doc_type);
+CREATE INDEX IF NOT EXISTS idx_intercompany_transactions_target_doc_id ON intercompany_transactions (target_doc_id);
+CREATE INDEX IF NOT EXISTS idx_intercompany_transactions_transaction_date ON intercompany_transactions (transaction_date);
+CREATE INDEX IF NOT EXISTS idx_intercompany_transactions_status ON intercompany_transactions (status);
+
+COMMIT;
@@ -0,0 +1,69 @@
+-- ============================================================================
+-- GalaxyERP 物料变体迁移 - PostgreSQL 脚本
+-- 说明: 物料属性维护可选取值，模板物料选用属性后按取值组合生成变体物料；
+--       模板物料不持有库存，库存报表与 ABC 分析可将变体汇总到模板物料
+-- ============================================================================
+
+BEGIN;
+
+-- items: 模板标记与所属模板
+ALTER TABLE IF EXISTS items
+  ADD COLUMN IF NOT EXISTS has_variants BOOLEAN DEFAULT FALSE,
+  ADD COLUMN IF NOT EXISTS variant_of INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_items_variant_of ON items (variant_of);
+
+-- item_attributes: 物料属性
+CREATE TABLE IF NOT EXISTS item_attributes (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  code VARCHAR(50) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  description TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_item_attributes_deleted_at ON item_attributes (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_attributes_code ON item_attributes (code);
+
+-- item_attribute_values: 物料属性取值
+CREATE TABLE IF NOT EXISTS item_attribute_values (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  attribute_id INTEGER NOT NULL,
+  value VARCHAR(100) NOT NULL,
+  abbreviation VARCHAR(20) NOT NULL,
+  sort_order BIGINT DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_item_attribute_values_deleted_at ON item_attribute_values (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_attribute_values_attribute_value ON item_attribute_values (attribute_id, value);
+
+-- item_template_attributes: 模板物料使用的属性
+CREATE TABLE IF NOT EXISTS item_template_attributes (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  item_id INTEGER NOT NULL,
+  attribute_id INTEGER NOT NULL,
+  sort_order BIGINT DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_item_template_attributes_deleted_at ON item_template_attributes (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_template_attributes_item_attribute ON item_template_attributes (item_id, attribute_id);
+
+-- item_variant_attributes: 变体物料的属性取值
+CREATE TABLE IF NOT EXISTS item_variant_attributes (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  item_id INTEGER NOT NULL,
+  attribute_id INTEGER NOT NULL,
+  attribute_value_id INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_item_variant_attributes_deleted_at ON item_variant_attributes (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_variant_attributes_item_attribute ON item_variant_attributes (item_id, attribute_id);
+CREATE INDEX IF NOT EXISTS idx_item_variant_attributes_attribute_value_id ON item_variant_attributes (attribute_value_id);
+
+COMMIT;
@@ -0,0 +1,91 @@
+-- ============================================================================
+-- GalaxyERP 到岸成本迁移 - PostgreSQL 脚本
+-- 说明: 到岸成本单将运费、关税、保险等费用按数量、金额、重量或手工金额分摊到采购收货明细，
+--       提交时仍在库的部分以 revaluation 库存移动计入库存金额，已出库部分计入销售成本，并生成总账凭证
+-- ============================================================================
+
+BEGIN;
+
+-- items: 单位重量，按重量分摊到岸成本时使用
+ALTER TABLE IF EXISTS items
+  ADD COLUMN IF NOT EXISTS weight DOUBLE PRECISION DEFAULT 0;
+
+-- landed_cost_vouchers: 到岸成本单
+CREATE TABLE IF NOT EXISTS landed_cost_vouchers (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  company_id INTEGER NOT NULL DEFAULT 1,
+  voucher_number VARCHAR(50) NOT NULL,
+  posting_date TIMESTAMP WITH TIME ZONE NOT NULL,
+  allocation_method VARCHAR(20) NOT NULL,
+  status VARCHAR(20) DEFAULT 'draft',
+  total_charges NUMERIC(20,4) DEFAULT 0,
+  capitalized_amount NUMERIC(20,4) DEFAULT 0,
+  expensed_amount NUMERIC(20,4) DEFAULT 0,
+  submitted_at TIMESTAMP WITH TIME ZONE NULL,
+  transaction_id INTEGER NULL,
+  notes TEXT NULL,
+  created_by INTEGER NULL
+);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_vouchers_deleted_at ON landed_cost_vouchers (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_landed_cost_vouchers_voucher_number ON landed_cost_vouchers (voucher_number);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_vouchers_company_id ON landed_cost_vouchers (company_id);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_vouchers_posting_date ON landed_cost_vouchers (posting_date);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_vouchers_status ON landed_cost_vouchers (status);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_vouchers_transaction_id ON landed_cost_vouchers (transaction_id);
+
+-- landed_cost_receipts: 到岸成本单引用的采购收货单
+CREATE TABLE IF NOT EXISTS landed_cost_receipts (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  landed_cost_voucher_id INTEGER NOT NULL,
+  purchase_receipt_id INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_receipts_deleted_at ON landed_cost_receipts (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_receipts_landed_cost_voucher_id ON landed_cost_receipts (landed_cost_voucher_id);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_receipts_purchase_receipt_id ON landed_cost_receipts (purchase_receipt_id);
+
+-- landed_cost_charges: 到岸成本费用
+CREATE TABLE IF NOT EXISTS landed_cost_charges (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  landed_cost_voucher_id INTEGER NOT NULL,
+  charge_type VARCHAR(20) NOT NULL,
+  description TEXT NULL,
+  amount NUMERIC(20,4) NOT NULL,
+  account_code VARCHAR(50) NULL,
+  supplier_id INTEGER NULL
+);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_charges_deleted_at ON landed_cost_charges (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_charges_landed_cost_voucher_id ON landed_cost_charges (landed_cost_voucher_id);
+
+-- landed_cost_items: 到岸成本分摊行
+CREATE TABLE IF NOT EXISTS landed_cost_items (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  landed_cost_voucher_id INTEGER NOT NULL,
+  purchase_receipt_id INTEGER NOT NULL,
+  purchase_receipt_item_id INTEGER NOT NULL,
+  item_id INTEGER NOT NULL,
+  warehouse_id INTEGER NOT NULL,
+  quantity DOUBLE PRECISION DEFAULT 0,
+  amount NUMERIC(20,4) DEFAULT 0,
+  weight DOUBLE PRECISION DEFAULT 0,
+  allocated_amount NUMERIC(20,4) DEFAULT 0,
+  capitalized_amount NUMERIC(20,4) DEFAULT 0,
+  expensed_amount NUMERIC(20,4) DEFAULT 0,
+  movement_id INTEGER NULL
+);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_items_deleted_at ON landed_cost_items (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_items_landed_cost_voucher_id ON landed_cost_items (landed_cost_voucher_id);
+CREATE INDEX IF NOT EXISTS idx_landed_cost_items_purchase_receipt_item_id ON landed_cost_items (purchase_receipt_item_id);
+
+COMMIT;
@@ -0,0 +1,80 @@
+-- ============================================================================
+-- GalaxyERP 库位管理迁移 - PostgreSQL 脚本
+-- 说明: 库存移动可指定库位，按物料、库位、批次维护库位库存；
+--       新增上架规则与库位间移库记录，用于上架建议与送货单拣货建议
+-- ============================================================================
+
+BEGIN;
+
+-- locations: 描述、容量（0 表示不限）与拣货顺序号
+ALTER TABLE IF EXISTS locations
+  ADD COLUMN IF NOT EXISTS description TEXT NULL,
+  ADD COLUMN IF NOT EXISTS capacity DOUBLE PRECISION DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS sequence INTEGER DEFAULT 0;
+
+-- movements: 库存移动的库位
+ALTER TABLE IF EXISTS movements
+  ADD COLUMN IF NOT EXISTS location_id INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_movements_location_id ON movements (location_id);
+
+-- location_stocks: 库位库存余额（无批次时 batch_id 为 0）
+CREATE TABLE IF NOT EXISTS location_stocks (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  item_id INTEGER NOT NULL,
+  warehouse_id INTEGER NOT NULL,
+  location_id INTEGER NOT NULL,
+  batch_id INTEGER DEFAULT 0,
+  quantity DOUBLE PRECISION DEFAULT 0,
+  first_received_at TIMESTAMP WITH TIME ZONE NULL
+);
+CREATE INDEX IF NOT EXISTS idx_location_stocks_deleted_at ON location_stocks (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_location_stocks_item_location_batch ON location_stocks (item_id, location_id, batch_id);
+CREATE INDEX IF NOT EXISTS idx_location_stocks_item_warehouse ON location_stocks (item_id, warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_location_stocks_location_id ON location_stocks (location_id);
+
+-- putaway_rules: 上架规则（固定库位 / 物料类别 / 通用）
+CREATE TABLE IF NOT EXISTS putaway_rules (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  warehouse_id INTEGER NOT NULL,
+  location_id INTEGER NOT NULL,
+  item_id INTEGER NULL,
+  category VARCHAR(100) NULL,
+  priority INTEGER DEFAULT 0,
+  is_active BOOLEAN DEFAULT TRUE,
+  notes TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_putaway_rules_deleted_at ON putaway_rules (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_putaway_rules_warehouse_id ON putaway_rules (warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_putaway_rules_location_id ON putaway_rules (location_id);
+CREATE INDEX IF NOT EXISTS idx_putaway_rules_item_id ON putaway_rules (item_id);
+CREATE INDEX IF NOT EXISTS idx_putaway_rules_category ON putaway_rules (category);
+
+-- location_moves: 库位间移库记录
+CREATE TABLE IF NOT EXISTS location_moves (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  item_id INTEGER NOT NULL,
+  warehouse_id INTEGER NOT NULL,
+  from_location_id INTEGER NULL,
+  to_location_id INTEGER NULL,
+  batch_id INTEGER DEFAULT 0,
+  quantity DOUBLE PRECISION NOT NULL,
+  reference VARCHAR(100) NULL,
+  notes TEXT NULL,
+  created_by INTEGER NULL
+);
+CREATE INDEX IF NOT EXISTS idx_location_moves_deleted_at ON location_moves (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_location_moves_item_id ON location_moves (item_id);
+CREATE INDEX IF NOT EXISTS idx_location_moves_warehouse_id ON location_moves (warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_location_moves_from_location_id ON location_moves (from_location_id);
+CREATE INDEX IF NOT EXISTS idx_location_moves_to_location_id ON location_moves (to_location_id);
+
+COMMIT;
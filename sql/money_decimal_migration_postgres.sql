@@ -0,0 +1,254 @@
+-- ============================================================================
+-- GalaxyERP 金额字段定点小数迁移 - PostgreSQL 脚本
+-- 说明: 将所有金额列由 DOUBLE PRECISION / DECIMAL(15,2) 统一调整为 NUMERIC(20,4)，
+--       对应 /internal/models/money.go 中的 Money 类型；历史数据按 4 位小数四舍五入
+-- ============================================================================
+
+BEGIN;
+
+-- accounts
+ALTER TABLE IF EXISTS accounts
+  ALTER COLUMN balance TYPE NUMERIC(20,4) USING ROUND(balance::numeric, 4);
+
+-- journal_entries
+ALTER TABLE IF EXISTS journal_entries
+  ALTER COLUMN debit TYPE NUMERIC(20,4) USING ROUND(debit::numeric, 4),
+  ALTER COLUMN credit TYPE NUMERIC(20,4) USING ROUND(credit::numeric, 4);
+
+-- payments
+ALTER TABLE IF EXISTS payments
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4);
+
+-- bank_accounts
+ALTER TABLE IF EXISTS bank_accounts
+  ALTER COLUMN balance TYPE NUMERIC(20,4) USING ROUND(balance::numeric, 4);
+
+-- budgets
+ALTER TABLE IF EXISTS budgets
+  ALTER COLUMN total_amount TYPE NUMERIC(20,4) USING ROUND(total_amount::numeric, 4),
+  ALTER COLUMN used_amount TYPE NUMERIC(20,4) USING ROUND(used_amount::numeric, 4),
+  ALTER COLUMN remaining_amount TYPE NUMERIC(20,4) USING ROUND(remaining_amount::numeric, 4);
+
+-- budget_items
+ALTER TABLE IF EXISTS budget_items
+  ALTER COLUMN budget_amount TYPE NUMERIC(20,4) USING ROUND(budget_amount::numeric, 4),
+  ALTER COLUMN actual_amount TYPE NUMERIC(20,4) USING ROUND(actual_amount::numeric, 4),
+  ALTER COLUMN variance_amount TYPE NUMERIC(20,4) USING ROUND(variance_amount::numeric, 4);
+
+-- financial_report_items
+ALTER TABLE IF EXISTS financial_report_items
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4);
+
+-- transactions
+ALTER TABLE IF EXISTS transactions
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4);
+
+-- receivables
+ALTER TABLE IF EXISTS receivables
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4),
+  ALTER COLUMN amount_paid TYPE NUMERIC(20,4) USING ROUND(amount_paid::numeric, 4);
+
+-- payables
+ALTER TABLE IF EXISTS payables
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4),
+  ALTER COLUMN amount_paid TYPE NUMERIC(20,4) USING ROUND(amount_paid::numeric, 4);
+
+-- fixed_assets
+ALTER TABLE IF EXISTS fixed_assets
+  ALTER COLUMN purchase_price TYPE NUMERIC(20,4) USING ROUND(purchase_price::numeric, 4),
+  ALTER COLUMN current_value TYPE NUMERIC(20,4) USING ROUND(current_value::numeric, 4);
+
+-- depreciation_entries
+ALTER TABLE IF EXISTS depreciation_entries
+  ALTER COLUMN depreciation_amount TYPE NUMERIC(20,4) USING ROUND(depreciation_amount::numeric, 4),
+  ALTER COLUMN accumulated_amount TYPE NUMERIC(20,4) USING ROUND(accumulated_amount::numeric, 4),
+  ALTER COLUMN book_value TYPE NUMERIC(20,4) USING ROUND(book_value::numeric, 4);
+
+-- tax_entries
+ALTER TABLE IF EXISTS tax_entries
+  ALTER COLUMN taxable_amount TYPE NUMERIC(20,4) USING ROUND(taxable_amount::numeric, 4),
+  ALTER COLUMN tax_amount TYPE NUMERIC(20,4) USING ROUND(tax_amount::numeric, 4);
+
+-- payment_entries
+ALTER TABLE IF EXISTS payment_entries
+  ALTER COLUMN paid_amount TYPE NUMERIC(20,4) USING ROUND(paid_amount::numeric, 4),
+  ALTER COLUMN received_amount TYPE NUMERIC(20,4) USING ROUND(received_amount::numeric, 4);
+
+-- payrolls
+ALTER TABLE IF EXISTS payrolls
+  ALTER COLUMN basic_salary TYPE NUMERIC(20,4) USING ROUND(basic_salary::numeric, 4),
+  ALTER COLUMN overtime_pay TYPE NUMERIC(20,4) USING ROUND(overtime_pay::numeric, 4),
+  ALTER COLUMN allowance TYPE NUMERIC(20,4) USING ROUND(allowance::numeric, 4),
+  ALTER COLUMN bonus TYPE NUMERIC(20,4) USING ROUND(bonus::numeric, 4),
+  ALTER COLUMN deductions TYPE NUMERIC(20,4) USING ROUND(deductions::numeric, 4),
+  ALTER COLUMN social_insurance TYPE NUMERIC(20,4) USING ROUND(social_insurance::numeric, 4),
+  ALTER COLUMN housing_fund TYPE NUMERIC(20,4) USING ROUND(housing_fund::numeric, 4),
+  ALTER COLUMN tax TYPE NUMERIC(20,4) USING ROUND(tax::numeric, 4),
+  ALTER COLUMN net_pay TYPE NUMERIC(20,4) USING ROUND(net_pay::numeric, 4);
+
+-- trainings
+ALTER TABLE IF EXISTS trainings
+  ALTER COLUMN cost TYPE NUMERIC(20,4) USING ROUND(cost::numeric, 4);
+
+-- items
+ALTER TABLE IF EXISTS items
+  ALTER COLUMN cost TYPE NUMERIC(20,4) USING ROUND(cost::numeric, 4),
+  ALTER COLUMN price TYPE NUMERIC(20,4) USING ROUND(price::numeric, 4);
+
+-- movements
+ALTER TABLE IF EXISTS movements
+  ALTER COLUMN unit_cost TYPE NUMERIC(20,4) USING ROUND(unit_cost::numeric, 4),
+  ALTER COLUMN total_cost TYPE NUMERIC(20,4) USING ROUND(total_cost::numeric, 4);
+
+-- stock_adjustment_items
+ALTER TABLE IF EXISTS stock_adjustment_items
+  ALTER COLUMN unit_cost TYPE NUMERIC(20,4) USING ROUND(unit_cost::numeric, 4),
+  ALTER COLUMN total_cost TYPE NUMERIC(20,4) USING ROUND(total_cost::numeric, 4);
+
+-- suppliers
+ALTER TABLE IF EXISTS suppliers
+  ALTER COLUMN credit_limit TYPE NUMERIC(20,4) USING ROUND(credit_limit::numeric, 4);
+
+-- purchase_request_items
+ALTER TABLE IF EXISTS purchase_request_items
+  ALTER COLUMN estimated_cost TYPE NUMERIC(20,4) USING ROUND(estimated_cost::numeric, 4);
+
+-- purchase_orders
+ALTER TABLE IF EXISTS purchase_orders
+  ALTER COLUMN total_amount TYPE NUMERIC(20,4) USING ROUND(total_amount::numeric, 4),
+  ALTER COLUMN discount_amount TYPE NUMERIC(20,4) USING ROUND(discount_amount::numeric, 4),
+  ALTER COLUMN tax_amount TYPE NUMERIC(20,4) USING ROUND(tax_amount::numeric, 4),
+  ALTER COLUMN grand_total TYPE NUMERIC(20,4) USING ROUND(grand_total::numeric, 4);
+
+-- purchase_order_items
+ALTER TABLE IF EXISTS purchase_order_items
+  ALTER COLUMN rate TYPE NUMERIC(20,4) USING ROUND(rate::numeric, 4),
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4),
+  ALTER COLUMN discount_amount TYPE NUMERIC(20,4) USING ROUND(discount_amount::numeric, 4),
+  ALTER COLUMN tax_amount TYPE NUMERIC(20,4) USING ROUND(tax_amount::numeric, 4),
+  ALTER COLUMN total_amount TYPE NUMERIC(20,4) USING ROUND(total_amount::numeric, 4);
+
+-- customers
+ALTER TABLE IF EXISTS customers
+  ALTER COLUMN credit_limit TYPE NUMERIC(20,4) USING ROUND(credit_limit::numeric, 4);
+
+-- quotations
+ALTER TABLE IF EXISTS quotations
+  ALTER COLUMN total_amount TYPE NUMERIC(20,4) USING ROUND(total_amount::numeric, 4),
+  ALTER COLUMN discount_amount TYPE NUMERIC(20,4) USING ROUND(discount_amount::numeric, 4),
+  ALTER COLUMN tax_amount TYPE NUMERIC(20,4) USING ROUND(tax_amount::numeric, 4),
+  ALTER COLUMN grand_total TYPE NUMERIC(20,4) USING ROUND(grand_total::numeric, 4);
+
+-- quotation_items
+ALTER TABLE IF EXISTS quotation_items
+  ALTER COLUMN rate TYPE NUMERIC(20,4) USING ROUND(rate::numeric, 4),
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4),
+  ALTER COLUMN discount_amount TYPE NUMERIC(20,4) USING ROUND(discount_amount::numeric, 4),
+  ALTER COLUMN tax_amount TYPE NUMERIC(20,4) USING ROUND(tax_amount::numeric, 4),
+  ALTER COLUMN total_amount TYPE NUMERIC(20,4) USING ROUND(total_amount::numeric, 4);
+
+-- sales_orders
+ALTER TABLE IF EXISTS sales_orders
+  ALTER COLUMN total_amount TYPE NUMERIC(20,4) USING ROUND(total_amount::numeric, 4),
+  ALTER COLUMN discount_amount TYPE NUMERIC(20,4) USING ROUND(discount_amount::numeric, 4),
+  ALTER COLUMN tax_amount TYPE NUMERIC(20,4) USING ROUND(tax_amount::numeric, 4),
+  ALTER COLUMN grand_total TYPE NUMERIC(20,4) USING ROUND(grand_total::numeric, 4);
+
+-- sales_order_items
+ALTER TABLE IF EXISTS sales_order_items
+  ALTER COLUMN rate TYPE NUMERIC(20,4) USING ROUND(rate::numeric, 4),
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4),
+  ALTER COLUMN discount_amount TYPE NUMERIC(20,4) USING ROUND(discount_amount::numeric, 4),
+  ALTER COLUMN tax_amount TYPE NUMERIC(20,4) USING ROUND(tax_amount::numeric, 4),
+  ALTER COLUMN total_amount TYPE NUMERIC(20,4) USING ROUND(total_amount::numeric, 4);
+
+-- quotation_template_items
+ALTER TABLE IF EXISTS quotation_template_items
+  ALTER COLUMN rate TYPE NUMERIC(20,4) USING ROUND(rate::numeric, 4);
+
+-- sales_invoices
+ALTER TABLE IF EXISTS sales_invoices
+  ALTER COLUMN sub_total TYPE NUMERIC(20,4) USING ROUND(sub_total::numeric, 4),
+  ALTER COLUMN discount_amount TYPE NUMERIC(20,4) USING ROUND(discount_amount::numeric, 4),
+  ALTER COLUMN tax_amount TYPE NUMERIC(20,4) USING ROUND(tax_amount::numeric, 4),
+  ALTER COLUMN shipping_amount TYPE NUMERIC(20,4) USING ROUND(shipping_amount::numeric, 4),
+  ALTER COLUMN grand_total TYPE NUMERIC(20,4) USING ROUND(grand_total::numeric, 4),
+  ALTER COLUMN outstanding_amount TYPE NUMERIC(20,4) USING ROUND(outstanding_amount::numeric, 4),
+  ALTER COLUMN paid_amount TYPE NUMERIC(20,4) USING ROUND(paid_amount::numeric, 4);
+
+-- sales_invoice_items
+ALTER TABLE IF EXISTS sales_invoice_items
+  ALTER COLUMN rate TYPE NUMERIC(20,4) USING ROUND(rate::numeric, 4),
+  ALTER COLUMN price_list_rate TYPE NUMERIC(20,4) USING ROUND(price_list_rate::numeric, 4),
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4),
+  ALTER COLUMN discount_amount TYPE NUMERIC(20,4) USING ROUND(discount_amount::numeric, 4),
+  ALTER COLUMN tax_amount TYPE NUMERIC(20,4) USING ROUND(tax_amount::numeric, 4),
+  ALTER COLUMN net_rate TYPE NUMERIC(20,4) USING ROUND(net_rate::numeric, 4),
+  ALTER COLUMN net_amount TYPE NUMERIC(20,4) USING ROUND(net_amount::numeric, 4);
+
+-- invoice_payments
+ALTER TABLE IF EXISTS invoice_payments
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4);
+
+-- pricing_rules
+ALTER TABLE IF EXISTS pricing_rules
+  ALTER COLUMN min_amount TYPE NUMERIC(20,4) USING ROUND(min_amount::numeric, 4),
+  ALTER COLUMN max_amount TYPE NUMERIC(20,4) USING ROUND(max_amount::numeric, 4),
+  ALTER COLUMN rate TYPE NUMERIC(20,4) USING ROUND(rate::numeric, 4),
+  ALTER COLUMN discount_amount TYPE NUMERIC(20,4) USING ROUND(discount_amount::numeric, 4);
+
+-- products
+ALTER TABLE IF EXISTS products
+  ALTER COLUMN price TYPE NUMERIC(20,4) USING ROUND(price::numeric, 4),
+  ALTER COLUMN cost TYPE NUMERIC(20,4) USING ROUND(cost::numeric, 4);
+
+-- bom_items
+ALTER TABLE IF EXISTS bom_items
+  ALTER COLUMN unit_cost TYPE NUMERIC(20,4) USING ROUND(unit_cost::numeric, 4),
+  ALTER COLUMN total_cost TYPE NUMERIC(20,4) USING ROUND(total_cost::numeric, 4);
+
+-- operations
+ALTER TABLE IF EXISTS operations
+  ALTER COLUMN standard_cost TYPE NUMERIC(20,4) USING ROUND(standard_cost::numeric, 4);
+
+-- work_centers
+ALTER TABLE IF EXISTS work_centers
+  ALTER COLUMN cost_per_hour TYPE NUMERIC(20,4) USING ROUND(cost_per_hour::numeric, 4);
+
+-- work_order_materials
+ALTER TABLE IF EXISTS work_order_materials
+  ALTER COLUMN unit_cost TYPE NUMERIC(20,4) USING ROUND(unit_cost::numeric, 4),
+  ALTER COLUMN total_cost TYPE NUMERIC(20,4) USING ROUND(total_cost::numeric, 4);
+
+-- equipment_maintenance
+ALTER TABLE IF EXISTS equipment_maintenance
+  ALTER COLUMN cost TYPE NUMERIC(20,4) USING ROUND(cost::numeric, 4);
+
+-- equipment_maintenances
+ALTER TABLE IF EXISTS equipment_maintenances
+  ALTER COLUMN cost TYPE NUMERIC(20,4) USING ROUND(cost::numeric, 4);
+
+-- equipment_failures
+ALTER TABLE IF EXISTS equipment_failures
+  ALTER COLUMN repair_cost TYPE NUMERIC(20,4) USING ROUND(repair_cost::numeric, 4);
+
+-- projects
+ALTER TABLE IF EXISTS projects
+  ALTER COLUMN budget TYPE NUMERIC(20,4) USING ROUND(budget::numeric, 4),
+  ALTER COLUMN actual_cost TYPE NUMERIC(20,4) USING ROUND(actual_cost::numeric, 4);
+
+-- time_entries
+ALTER TABLE IF EXISTS time_entries
+  ALTER COLUMN hourly_rate TYPE NUMERIC(20,4) USING ROUND(hourly_rate::numeric, 4),
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4);
+
+-- project_expenses
+ALTER TABLE IF EXISTS project_expenses
+  ALTER COLUMN amount TYPE NUMERIC(20,4) USING ROUND(amount::numeric, 4);
+
+-- project_resources
+ALTER TABLE IF EXISTS project_resources
+  ALTER COLUMN cost_per_unit TYPE NUMERIC(20,4) USING ROUND(cost_per_unit::numeric, 4),
+  ALTER COLUMN total_cost TYPE NUMERIC(20,4) USING ROUND(total_cost::numeric, 4);
+
+COMMIT;
@@ -0,0 +1,250 @@
+-- ============================================================================
+-- GalaxyERP 金额字段定点小数迁移 - SQLite 脚本
+-- 说明: SQLite 不支持 ALTER COLUMN，列类型由 GORM AutoMigrate 重建为 decimal(20,4)；
+--       本脚本仅将历史 REAL 数据规整为 4 位小数，Money.Scan 兼容 REAL/TEXT 旧数据
+-- ============================================================================
+
+BEGIN TRANSACTION;
+
+-- accounts
+UPDATE accounts SET
+  balance = ROUND(balance, 4);
+
+-- journal_entries
+UPDATE journal_entries SET
+  debit = ROUND(debit, 4),
+  credit = ROUND(credit, 4);
+
+-- payments
+UPDATE payments SET
+  amount = ROUND(amount, 4);
+
+-- bank_accounts
+UPDATE bank_accounts SET
+  balance = ROUND(balance, 4);
+
+-- budgets
+UPDATE budgets SET
+  total_amount = ROUND(total_amount, 4),
+  used_amount = ROUND(used_amount, 4),
+  remaining_amount = ROUND(remaining_amount, 4);
+
+-- budget_items
+UPDATE budget_items SET
+  budget_amount = ROUND(budget_amount, 4),
+  actual_amount = ROUND(actual_amount, 4),
+  variance_amount = ROUND(variance_amount, 4);
+
+-- financial_report_items
+UPDATE financial_report_items SET
+  amount = ROUND(amount, 4);
+
+-- transactions
+UPDATE transactions SET
+  amount = ROUND(amount, 4);
+
+-- receivables
+UPDATE receivables SET
+  amount = ROUND(amount, 4),
+  amount_paid = ROUND(amount_paid, 4);
+
+-- payables
+UPDATE payables SET
+  amount = ROUND(amount, 4),
+  amount_paid = ROUND(amount_paid, 4);
+
+-- fixed_assets
+UPDATE fixed_assets SET
+  purchase_price = ROUND(purchase_price, 4),
+  current_value = ROUND(current_value, 4);
+
+-- depreciation_entries
+UPDATE depreciation_entries SET
+  depreciation_amount = ROUND(depreciation_amount, 4),
+  accumulated_amount = ROUND(accumulated_amount, 4),
+  book_value = ROUND(book_value, 4);
+
+-- tax_entries
+UPDATE tax_entries SET
+  taxable_amount = ROUND(taxable_amount, 4),
+  tax_amount = ROUND(tax_amount, 4);
+
+-- payment_entries
+UPDATE payment_entries SET
+  paid_amount = ROUND(paid_amount, 4),
+  received_amount = ROUND(received_amount, 4);
+
+-- payrolls
+UPDATE payrolls SET
+  basic_salary = ROUND(basic_salary, 4),
+  overtime_pay = ROUND(overtime_pay, 4),
+  allowance = ROUND(allowance, 4),
+  bonus = ROUND(bonus, 4),
+  deductions = ROUND(deductions, 4),
+  social_insurance = ROUND(social_insurance, 4),
+  housing_fund = ROUND(housing_fund, 4),
+  tax = ROUND(tax, 4),
+  net_pay = ROUND(net_pay, 4);
+
+-- trainings
+UPDATE trainings SET
+  cost = ROUND(cost, 4);
+
+-- items
+UPDATE items SET
+  cost = ROUND(cost, 4),
+  price = ROUND(price, 4);
+
+-- movements
+UPDATE movements SET
+  unit_cost = ROUND(unit_cost, 4),
+  total_cost = ROUND(total_cost, 4);
+
+-- stock_adjustment_items
+UPDATE stock_adjustment_items SET
+  unit_cost = ROUND(unit_cost, 4),
+  total_cost = ROUND(total_cost, 4);
+
+-- suppliers
+UPDATE suppliers SET
+  credit_limit = ROUND(credit_limit, 4);
+
+-- purchase_request_items
+UPDATE purchase_request_items SET
+  estimated_cost = ROUND(estimated_cost, 4);
+
+-- purchase_orders
+UPDATE purchase_orders SET
+  total_amount = ROUND(total_amount, 4),
+  discount_amount = ROUND(discount_amount, 4),
+  tax_amount = ROUND(tax_amount, 4),
+  grand_total = ROUND(grand_total, 4);
+
+-- purchase_order_items
+UPDATE purchase_order_items SET
+  rate = ROUND(rate, 4),
+  amount = ROUND(amount, 4),
+  discount_amount = ROUND(discount_amount, 4),
+  tax_amount = ROUND(tax_amount, 4),
+  total_amount = ROUND(total_amount, 4);
+
+-- customers
+UPDATE customers SET
+  credit_limit = ROUND(credit_limit, 4);
+
+-- quotations
+UPDATE quotations SET
+  total_amount = ROUND(total_amount, 4),
+  discount_amount = ROUND(discount_amount, 4),
+  tax_amount = ROUND(tax_amount, 4),
+  grand_total = ROUND(grand_total, 4);
+
+-- quotation_items
+UPDATE quotation_items SET
+  rate = ROUND(rate, 4),
+  amount = ROUND(amount, 4),
+  discount_amount = ROUND(discount_amount, 4),
+  tax_amount = ROUND(tax_amount, 4),
+  total_amount = ROUND(total_amount, 4);
+
+-- sales_orders
+UPDATE sales_orders SET
+  total_amount = ROUND(total_amount, 4),
+  discount_amount = ROUND(discount_amount, 4),
+  tax_amount = ROUND(tax_amount, 4),
+  grand_total = ROUND(grand_total, 4);
+
+-- sales_order_items
+UPDATE sales_order_items SET
+  rate = ROUND(rate, 4),
+  amount = ROUND(amount, 4),
+  discount_amount = ROUND(discount_amount, 4),
+  tax_amount = ROUND(tax_amount, 4),
+  total_amount = ROUND(total_amount, 4);
+
+-- quotation_template_items
+UPDATE quotation_template_items SET
+  rate = ROUND(rate, 4);
+
+-- sales_invoices
+UPDATE sales_invoices SET
+  sub_total = ROUND(sub_total, 4),
+  discount_amount = ROUND(discount_amount, 4),
+  tax_amount = ROUND(tax_amount, 4),
+  shipping_amount = ROUND(shipping_amount, 4),
+  grand_total = ROUND(grand_total, 4),
+  outstanding_amount = ROUND(outstanding_amount, 4),
+  paid_amount = ROUND(paid_amount, 4);
+
+-- sales_invoice_items
+UPDATE sales_invoice_items SET
+  rate = ROUND(rate, 4),
+  price_list_rate = ROUND(price_list_rate, 4),
+  amount = ROUND(amount, 4),
+  discount_amount = ROUND(discount_amount, 4),
+  tax_amount = ROUND(tax_amount, 4),
+  net_rate = ROUND(net_rate, 4),
+  net_amount = ROUND(net_amount, 4);
+
+-- invoice_payments
+UPDATE invoice_payments SET
+  amount = ROUND(amount, 4);
+
+-- pricing_rules
+UPDATE pricing_rules SET
+  min_amount = ROUND(min_amount, 4),
+  max_amount = ROUND(max_amount, 4),
+  rate = ROUND(rate, 4),
+  discount_amount = ROUND(discount_amount, 4);
+
+-- products
+UPDATE products SET
+  price = ROUND(price, 4),
+  cost = ROUND(cost, 4);
+
+-- bom_items
+UPDATE bom_items SET
+  unit_cost = ROUND(unit_cost, 4),
+  total_cost = ROUND(total_cost, 4);
+
+-- operations
+UPDATE operations SET
+  standard_cost = ROUND(standard_cost, 4);
+
+-- work_centers
+UPDATE work_centers SET
+  cost_per_hour = ROUND(cost_per_hour, 4);
+
+-- work_order_materials
+UPDATE work_order_materials SET
+  unit_cost = ROUND(unit_cost, 4),
+  total_cost = ROUND(total_cost, 4);
+
+-- equipment_maintenances
+UPDATE equipment_maintenances SET
+  cost = ROUND(cost, 4);
+
+-- equipment_failures
+UPDATE equipment_failures SET
+  repair_cost = ROUND(repair_cost, 4);
+
+-- projects
+UPDATE projects SET
+  budget = ROUND(budget, 4),
+  actual_cost = ROUND(actual_cost, 4);
+
+-- time_entries
+UPDATE time_entries SET
+  hourly_rate = ROUND(hourly_rate, 4),
+  amount = ROUND(amount, 4);
+
+-- project_expenses
+UPDATE project_expenses SET
+  amount = ROUND(amount, 4);
+
+-- project_resources
+UPDATE project_resources SET
+  cost_per_unit = ROUND(cost_per_unit, 4),
+  total_cost = ROUND(total_cost, 4);
+
+COMMIT;
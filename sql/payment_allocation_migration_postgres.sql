@@ -0,0 +1,44 @@
+-- ============================================================================
+-- GalaxyERP 收付款过账与核销迁移 - PostgreSQL 脚本
+-- 说明: 付款记录增加编号、核销金额、应收/应付科目与过账凭证；
+--       新增付款分配表，记录付款核销到销售发票或应付单据的金额
+-- ============================================================================
+
+BEGIN;
+
+-- payment_entries: 核销金额与过账信息
+ALTER TABLE IF EXISTS payment_entries
+  ADD COLUMN IF NOT EXISTS payment_number VARCHAR(100) NULL,
+  ADD COLUMN IF NOT EXISTS allocated_amount NUMERIC(20,4) DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS unallocated_amount NUMERIC(20,4) DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS payment_method VARCHAR(50) NULL,
+  ADD COLUMN IF NOT EXISTS party_account_id INTEGER NULL,
+  ADD COLUMN IF NOT EXISTS transaction_id INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_payment_entries_payment_number ON payment_entries (payment_number);
+
+-- 历史付款视为已全额核销
+UPDATE payment_entries
+SET allocated_amount = CASE WHEN payment_type = 'Receive' THEN received_amount ELSE paid_amount END
+WHERE allocated_amount = 0;
+
+-- payment_allocations: 付款分配明细
+CREATE TABLE IF NOT EXISTS payment_allocations (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  payment_entry_id INTEGER NOT NULL,
+  reference_type VARCHAR(50) NOT NULL,
+  reference_id INTEGER NOT NULL,
+  reference_number VARCHAR(100) NULL,
+  allocated_amount NUMERIC(20,4) NOT NULL,
+  allocation_date TIMESTAMP WITH TIME ZONE NOT NULL,
+  status VARCHAR(20) DEFAULT 'pending',
+  CONSTRAINT fk_payment_allocations_payment_entry FOREIGN KEY (payment_entry_id) REFERENCES payment_entries(id)
+);
+CREATE INDEX IF NOT EXISTS idx_payment_allocations_deleted_at ON payment_allocations (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_payment_allocations_payment_entry_id ON payment_allocations (payment_entry_id);
+CREATE INDEX IF NOT EXISTS idx_payment_allocations_reference ON payment_allocations (reference_type, reference_id);
+CREATE INDEX IF NOT EXISTS idx_payment_allocations_status ON payment_allocations (status);
+
+COMMIT;
@@ -0,0 +1,78 @@
+-- ============================================================================
+-- GalaxyERP 价格表迁移 - PostgreSQL 脚本
+-- 说明: 销售与采购价格表（币种、有效期、默认价格表），按单位与起订数量登记的物料价格，
+--       价格表对客户、客户组与供应商的分配；报价单、销售订单与采购订单记录取价的价格表，
+--       明细记录价格表价格
+-- ============================================================================
+
+BEGIN;
+
+-- price_lists: 价格表
+CREATE TABLE IF NOT EXISTS price_lists (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  code VARCHAR(50) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  price_list_type VARCHAR(20) NOT NULL,
+  currency VARCHAR(10) DEFAULT 'CNY',
+  valid_from TIMESTAMP WITH TIME ZONE NULL,
+  valid_to TIMESTAMP WITH TIME ZONE NULL,
+  is_default BOOLEAN DEFAULT FALSE,
+  is_active BOOLEAN DEFAULT TRUE,
+  description TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_price_lists_deleted_at ON price_lists (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_lists_code ON price_lists (code);
+CREATE INDEX IF NOT EXISTS idx_price_lists_price_list_type ON price_lists (price_list_type);
+
+-- item_prices: 物料价格
+CREATE TABLE IF NOT EXISTS item_prices (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  price_list_id INTEGER NOT NULL,
+  item_id INTEGER NOT NULL,
+  uom VARCHAR(50) NULL,
+  min_qty DOUBLE PRECISION DEFAULT 0,
+  rate NUMERIC(20,4) NOT NULL,
+  valid_from TIMESTAMP WITH TIME ZONE NULL,
+  valid_to TIMESTAMP WITH TIME ZONE NULL
+);
+CREATE INDEX IF NOT EXISTS idx_item_prices_deleted_at ON item_prices (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_item_prices_price_list_id ON item_prices (price_list_id);
+CREATE INDEX IF NOT EXISTS idx_item_prices_item_id ON item_prices (item_id);
+
+-- price_list_assignments: 价格表分配
+CREATE TABLE IF NOT EXISTS price_list_assignments (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  price_list_id INTEGER NOT NULL,
+  party_type VARCHAR(20) NOT NULL,
+  party_id INTEGER NULL,
+  customer_group VARCHAR(100) NULL
+);
+CREATE INDEX IF NOT EXISTS idx_price_list_assignments_deleted_at ON price_list_assignments (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_price_list_assignments_price_list_id ON price_list_assignments (price_list_id);
+CREATE INDEX IF NOT EXISTS idx_price_list_assignments_party_type ON price_list_assignments (party_type);
+CREATE INDEX IF NOT EXISTS idx_price_list_assignments_party_id ON price_list_assignments (party_id);
+CREATE INDEX IF NOT EXISTS idx_price_list_assignments_customer_group ON price_list_assignments (customer_group);
+
+-- 单据记录取价的价格表
+ALTER TABLE IF EXISTS quotations ADD COLUMN IF NOT EXISTS price_list_id INTEGER NULL;
+ALTER TABLE IF EXISTS sales_orders ADD COLUMN IF NOT EXISTS price_list_id INTEGER NULL;
+ALTER TABLE IF EXISTS purchase_orders ADD COLUMN IF NOT EXISTS price_list_id INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_quotations_price_list_id ON quotations (price_list_id);
+CREATE INDEX IF NOT EXISTS idx_sales_orders_price_list_id ON sales_orders (price_list_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_price_list_id ON purchase_orders (price_list_id);
+
+-- 明细记录价格表价格
+ALTER TABLE IF EXISTS quotation_items ADD COLUMN IF NOT EXISTS price_list_rate NUMERIC(20,4) DEFAULT 0;
+ALTER TABLE IF EXISTS sales_order_items ADD COLUMN IF NOT EXISTS price_list_rate NUMERIC(20,4) DEFAULT 0;
+ALTER TABLE IF EXISTS purchase_order_items ADD COLUMN IF NOT EXISTS price_list_rate NUMERIC(20,4) DEFAULT 0;
+
+COMMIT;
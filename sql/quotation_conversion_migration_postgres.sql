@@ -0,0 +1,17 @@
+-- ============================================================================
+-- GalaxyERP 报价单转销售订单迁移 - PostgreSQL 脚本
+-- 说明: 销售订单明细记录来源报价明细，报价明细记录已转为销售订单的数量，
+--       用于部分转单与报价单已下单、部分下单状态；报价单记录首次转单前的状态，
+--       关联订单全部取消后据此恢复
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE IF EXISTS quotations ADD COLUMN IF NOT EXISTS status_before_order VARCHAR(50) DEFAULT '';
+
+ALTER TABLE IF EXISTS quotation_items ADD COLUMN IF NOT EXISTS ordered_qty DOUBLE PRECISION DEFAULT 0;
+
+ALTER TABLE IF EXISTS sales_order_items ADD COLUMN IF NOT EXISTS quotation_item_id INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_sales_order_items_quotation_item_id ON sales_order_items (quotation_item_id);
+
+COMMIT;
@@ -0,0 +1,29 @@
+-- ============================================================================
+-- GalaxyERP 再订货点补货迁移 - PostgreSQL 脚本
+-- 说明: 物料增加补货数量、最高库存与首选供应商；补货按首选供应商生成草稿采购申请，
+--       采购申请记录来源、建议供应商与需求仓库，用于计算预计库存时扣除已申请数量
+-- ============================================================================
+
+BEGIN;
+
+-- items: 补货参数
+ALTER TABLE IF EXISTS items
+  ADD COLUMN IF NOT EXISTS reorder_qty DOUBLE PRECISION DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS max_level DOUBLE PRECISION DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS preferred_supplier_id INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_items_preferred_supplier_id ON items (preferred_supplier_id);
+
+-- purchase_requests: 来源 manual / replenishment 与建议供应商
+ALTER TABLE IF EXISTS purchase_requests
+  ADD COLUMN IF NOT EXISTS source VARCHAR(20) DEFAULT 'manual',
+  ADD COLUMN IF NOT EXISTS supplier_id INTEGER NULL;
+UPDATE purchase_requests SET source = 'manual' WHERE source IS NULL OR source = '';
+CREATE INDEX IF NOT EXISTS idx_purchase_requests_source ON purchase_requests (source);
+CREATE INDEX IF NOT EXISTS idx_purchase_requests_supplier_id ON purchase_requests (supplier_id);
+
+-- purchase_request_items: 需求仓库
+ALTER TABLE IF EXISTS purchase_request_items
+  ADD COLUMN IF NOT EXISTS warehouse_id INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_purchase_request_items_warehouse_id ON purchase_request_items (warehouse_id);
+
+COMMIT;
@@ -0,0 +1,39 @@
+-- ============================================================================
+-- GalaxyERP 库存预留迁移 - PostgreSQL 脚本
+-- 说明: 销售订单确认时按订单行发货仓库预留库存，库存不足时按订单优先级、交货日期与确认先后分配；
+--       订单取消或关闭时释放预留，送货单发货后核销
+-- ============================================================================
+
+BEGIN;
+
+-- sales_orders: 预留分配优先级
+ALTER TABLE IF EXISTS sales_orders
+  ADD COLUMN IF NOT EXISTS priority INTEGER DEFAULT 0;
+
+-- sales_order_items: 发货仓库
+ALTER TABLE IF EXISTS sales_order_items
+  ADD COLUMN IF NOT EXISTS warehouse_id INTEGER NULL;
+
+-- stock_reservations: 销售订单行的库存预留
+CREATE TABLE IF NOT EXISTS stock_reservations (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  sales_order_id INTEGER NOT NULL,
+  sales_order_item_id INTEGER NOT NULL,
+  item_id INTEGER NOT NULL,
+  warehouse_id INTEGER NOT NULL,
+  quantity DOUBLE PRECISION DEFAULT 0,
+  reserved_qty DOUBLE PRECISION DEFAULT 0,
+  delivered_qty DOUBLE PRECISION DEFAULT 0,
+  status VARCHAR(20) DEFAULT 'active',
+  released_at TIMESTAMP WITH TIME ZONE NULL
+);
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_deleted_at ON stock_reservations (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_sales_order_id ON stock_reservations (sales_order_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_reservations_sales_order_item_id ON stock_reservations (sales_order_item_id);
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_item_warehouse ON stock_reservations (item_id, warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_status ON stock_reservations (status);
+
+COMMIT;
@@ -0,0 +1,37 @@
+-- ============================================================================
+-- GalaxyERP 销售开票迁移 - PostgreSQL 脚本
+-- 说明: 销售订单明细与送货单明细记录已提交销售发票的开票数量（库存单位），防止重复开票；
+--       销售订单记录币种与汇率，开票时带入发票；
+--       按现有已提交发票明细换算为库存单位后回填开票数量
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE IF EXISTS sales_order_items ADD COLUMN IF NOT EXISTS billed_qty DOUBLE PRECISION DEFAULT 0;
+ALTER TABLE IF EXISTS delivery_note_items ADD COLUMN IF NOT EXISTS billed_qty DOUBLE PRECISION DEFAULT 0;
+ALTER TABLE IF EXISTS sales_orders ADD COLUMN IF NOT EXISTS currency VARCHAR(10) DEFAULT 'CNY';
+ALTER TABLE IF EXISTS sales_orders ADD COLUMN IF NOT EXISTS exchange_rate DOUBLE PRECISION DEFAULT 1;
+
+UPDATE sales_order_items soi
+SET billed_qty = billed.quantity
+FROM (
+    SELECT sii.sales_order_item_id, SUM(sii.quantity * CASE WHEN sii.conversion_factor > 0 THEN sii.conversion_factor ELSE 1 END) AS quantity
+    FROM sales_invoice_items sii
+    JOIN sales_invoices si ON si.id = sii.sales_invoice_id AND si.deleted_at IS NULL
+    WHERE si.doc_status = 'Submitted' AND sii.deleted_at IS NULL AND sii.sales_order_item_id IS NOT NULL
+    GROUP BY sii.sales_order_item_id
+) billed
+WHERE soi.id = billed.sales_order_item_id;
+
+UPDATE delivery_note_items dni
+SET billed_qty = billed.quantity
+FROM (
+    SELECT sii.delivery_note_item_id, SUM(sii.quantity * CASE WHEN sii.conversion_factor > 0 THEN sii.conversion_factor ELSE 1 END) AS quantity
+    FROM sales_invoice_items sii
+    JOIN sales_invoices si ON si.id = sii.sales_invoice_id AND si.deleted_at IS NULL
+    WHERE si.doc_status = 'Submitted' AND sii.deleted_at IS NULL AND sii.delivery_note_item_id IS NOT NULL
+    GROUP BY sii.delivery_note_item_id
+) billed
+WHERE dni.id = billed.delivery_note_item_id;
+
+COMMIT;
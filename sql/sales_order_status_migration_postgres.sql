@@ -0,0 +1,31 @@
+-- ============================================================================
+-- GalaxyERP 销售订单状态机迁移 - PostgreSQL 脚本
+-- 说明: 销售订单状态变更日志；历史订单状态规范化为小写下划线写法
+--       （draft、confirmed、on_hold、partially_delivered、delivered、partially_billed、
+--       completed、closed、cancelled）
+-- ============================================================================
+
+BEGIN;
+
+-- sales_order_status_logs: 销售订单状态变更日志
+CREATE TABLE IF NOT EXISTS sales_order_status_logs (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  sales_order_id INTEGER NOT NULL,
+  from_status VARCHAR(50) NULL,
+  to_status VARCHAR(50) NOT NULL,
+  changed_by INTEGER DEFAULT 0,
+  changed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  reason TEXT NULL,
+  notes TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sales_order_status_logs_deleted_at ON sales_order_status_logs (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_sales_order_status_logs_sales_order_id ON sales_order_status_logs (sales_order_id);
+
+-- 历史订单状态规范化
+UPDATE sales_orders SET status = LOWER(REPLACE(TRIM(status), ' ', '_')) WHERE status <> LOWER(REPLACE(TRIM(status), ' ', '_'));
+UPDATE sales_orders SET status = 'draft' WHERE status IS NULL OR status = '';
+
+COMMIT;
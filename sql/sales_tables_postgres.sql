@@ -518,6 +518,68 @@ CREATE INDEX idx_pricing_rules_valid_to ON pricing_rules (valid_to);
 CREATE INDEX idx_pricing_rules_is_active ON pricing_rules (is_active);
 CREATE INDEX idx_pricing_rules_priority ON pricing_rules (priority);
 
+-- ============================================================================
+-- 催款管理
+-- ============================================================================
+
+-- 催款级别表
+CREATE TABLE IF NOT EXISTS dunning_levels (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  created_by INTEGER,
+  updated_by INTEGER,
+  level INTEGER NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  days_overdue INTEGER NOT NULL,
+  fee_amount NUMERIC(20,4) DEFAULT 0,
+  interest_rate DOUBLE PRECISION DEFAULT 0,
+  subject_template VARCHAR(500),
+  body_template TEXT,
+  is_active BOOLEAN DEFAULT TRUE,
+  CONSTRAINT uq_dunning_levels_level UNIQUE (level)
+);
+CREATE INDEX IF NOT EXISTS idx_dunning_levels_deleted_at ON dunning_levels (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_dunning_levels_is_active ON dunning_levels (is_active);
+
+-- 催款函记录表
+CREATE TABLE IF NOT EXISTS dunning_letters (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  created_by INTEGER,
+  updated_by INTEGER,
+  letter_number VARCHAR(50) NOT NULL,
+  customer_id INTEGER NOT NULL,
+  sales_invoice_id INTEGER NOT NULL,
+  dunning_level_id INTEGER NOT NULL,
+  level INTEGER NOT NULL,
+  letter_date TIMESTAMP WITH TIME ZONE NOT NULL,
+  overdue_days INTEGER NOT NULL,
+  currency VARCHAR(10) DEFAULT 'CNY',
+  outstanding_amount NUMERIC(20,4) NOT NULL,
+  fee_amount NUMERIC(20,4) DEFAULT 0,
+  interest_amount NUMERIC(20,4) DEFAULT 0,
+  total_due NUMERIC(20,4) NOT NULL,
+  subject VARCHAR(500),
+  content TEXT,
+  trigger_type VARCHAR(20) DEFAULT 'manual',
+  status VARCHAR(20) DEFAULT 'sent',
+  sent_at TIMESTAMP WITH TIME ZONE NULL,
+  CONSTRAINT uq_dunning_letters_letter_number UNIQUE (letter_number),
+  CONSTRAINT fk_dunning_letters_customer FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE,
+  CONSTRAINT fk_dunning_letters_sales_invoice FOREIGN KEY (sales_invoice_id) REFERENCES sales_invoices(id) ON DELETE CASCADE,
+  CONSTRAINT fk_dunning_letters_dunning_level FOREIGN KEY (dunning_level_id) REFERENCES dunning_levels(id)
+);
+CREATE INDEX IF NOT EXISTS idx_dunning_letters_deleted_at ON dunning_letters (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_dunning_letters_customer_id ON dunning_letters (customer_id);
+CREATE INDEX IF NOT EXISTS idx_dunning_letters_sales_invoice_id ON dunning_letters (sales_invoice_id);
+CREATE INDEX IF NOT EXISTS idx_dunning_letters_level ON dunning_letters (level);
+CREATE INDEX IF NOT EXISTS idx_dunning_letters_letter_date ON dunning_letters (letter_date);
+CREATE INDEX IF NOT EXISTS idx_dunning_letters_trigger_type ON dunning_letters (trigger_type);
+
 -- ============================================================================
 -- 初始数据插入
 -- ============================================================================
@@ -0,0 +1,79 @@
+-- ============================================================================
+-- GalaxyERP 库存盘点迁移 - PostgreSQL 脚本
+-- 说明: 盘点单按整仓、库区或 ABC 分类生成并冻结账面数量，录入实盘数量（含扫描数据）后计算差异，
+--       差异超过阈值需审批，过账时生成 adjustment 库存移动与盘盈盘亏总账凭证；循环盘点按 ABC 分类周期生成
+-- ============================================================================
+
+BEGIN;
+
+-- locations: 所属库区
+ALTER TABLE IF EXISTS locations
+  ADD COLUMN IF NOT EXISTS zone VARCHAR(50) NULL;
+CREATE INDEX IF NOT EXISTS idx_locations_zone ON locations (zone);
+
+-- stock_counts: 盘点单
+CREATE TABLE IF NOT EXISTS stock_counts (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  company_id INTEGER NOT NULL DEFAULT 1,
+  count_number VARCHAR(50) NOT NULL,
+  warehouse_id INTEGER NOT NULL,
+  count_type VARCHAR(20) NOT NULL,
+  zone VARCHAR(50) NULL,
+  abc_class VARCHAR(1) NULL,
+  status VARCHAR(20) DEFAULT 'counting',
+  snapshot_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  count_date TIMESTAMP WITH TIME ZONE NOT NULL,
+  approval_value_threshold NUMERIC(20,4) DEFAULT 0,
+  approval_percent_threshold DOUBLE PRECISION DEFAULT 0,
+  approval_required BOOLEAN DEFAULT FALSE,
+  gain_value NUMERIC(20,4) DEFAULT 0,
+  loss_value NUMERIC(20,4) DEFAULT 0,
+  submitted_at TIMESTAMP WITH TIME ZONE NULL,
+  approved_by INTEGER NULL,
+  approved_at TIMESTAMP WITH TIME ZONE NULL,
+  posted_at TIMESTAMP WITH TIME ZONE NULL,
+  transaction_id INTEGER NULL,
+  notes TEXT NULL,
+  created_by INTEGER NULL
+);
+CREATE INDEX IF NOT EXISTS idx_stock_counts_deleted_at ON stock_counts (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_counts_count_number ON stock_counts (count_number);
+CREATE INDEX IF NOT EXISTS idx_stock_counts_company_id ON stock_counts (company_id);
+CREATE INDEX IF NOT EXISTS idx_stock_counts_warehouse_id ON stock_counts (warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_counts_count_type ON stock_counts (count_type);
+CREATE INDEX IF NOT EXISTS idx_stock_counts_status ON stock_counts (status);
+CREATE INDEX IF NOT EXISTS idx_stock_counts_count_date ON stock_counts (count_date);
+CREATE INDEX IF NOT EXISTS idx_stock_counts_transaction_id ON stock_counts (transaction_id);
+
+-- stock_count_items: 盘点行
+CREATE TABLE IF NOT EXISTS stock_count_items (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  stock_count_id INTEGER NOT NULL,
+  item_id INTEGER NOT NULL,
+  location_id INTEGER NULL,
+  batch_id INTEGER NULL,
+  batch_no VARCHAR(100) NULL,
+  abc_class VARCHAR(1) NULL,
+  expected_qty DOUBLE PRECISION DEFAULT 0,
+  valuation_rate NUMERIC(20,4) DEFAULT 0,
+  counted_qty DOUBLE PRECISION NULL,
+  variance_qty DOUBLE PRECISION DEFAULT 0,
+  variance_value NUMERIC(20,4) DEFAULT 0,
+  serial_no TEXT NULL,
+  counted_at TIMESTAMP WITH TIME ZONE NULL,
+  counted_by INTEGER NULL,
+  movement_id INTEGER NULL,
+  notes TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_stock_count_items_deleted_at ON stock_count_items (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_stock_count_items_stock_count_id ON stock_count_items (stock_count_id);
+CREATE INDEX IF NOT EXISTS idx_stock_count_items_item_id ON stock_count_items (item_id);
+CREATE INDEX IF NOT EXISTS idx_stock_count_items_location_id ON stock_count_items (location_id);
+
+COMMIT;
@@ -0,0 +1,53 @@
+-- ============================================================================
+-- GalaxyERP 库存台账迁移 - PostgreSQL 脚本
+-- 说明: 库存移动增加带符号数量、过账后余额与幂等键，成为只追加的库存台账；
+--       合并同一物料与仓库的重复库存行并增加唯一约束，保证余额行级锁定
+-- ============================================================================
+
+BEGIN;
+
+-- movements: 台账字段
+ALTER TABLE IF EXISTS movements
+  ADD COLUMN IF NOT EXISTS quantity_change NUMERIC DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS balance_after NUMERIC DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS reference_line_id INTEGER NULL,
+  ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(191) NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_movements_idempotency_key ON movements (idempotency_key);
+
+-- stocks: 合并重复库存行，保留每组最小 ID，数量为未删除行之和
+UPDATE stocks s
+SET quantity = d.total,
+    deleted_at = CASE WHEN d.live > 0 THEN NULL ELSE s.deleted_at END
+FROM (
+  SELECT MIN(id) AS keep_id,
+         COALESCE(SUM(quantity) FILTER (WHERE deleted_at IS NULL), 0) AS total,
+         COUNT(*) FILTER (WHERE deleted_at IS NULL) AS live
+  FROM stocks
+  GROUP BY item_id, warehouse_id
+  HAVING COUNT(*) > 1
+) d
+WHERE s.id = d.keep_id;
+
+DELETE FROM stocks s
+USING stocks k
+WHERE s.item_id = k.item_id
+  AND s.warehouse_id = k.warehouse_id
+  AND s.id > k.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stocks_item_warehouse ON stocks (item_id, warehouse_id);
+
+-- 历史库存补录期初台账，使台账汇总与库存余额一致
+INSERT INTO movements (created_at, updated_at, item_id, warehouse_id, quantity, quantity_change, balance_after,
+                       movement_type, reference, reference_type, idempotency_key)
+SELECT CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, s.item_id, s.warehouse_id, s.quantity, s.quantity, s.quantity,
+       'opening', '期初库存', 'opening', 'opening:' || s.item_id || ':' || s.warehouse_id
+FROM stocks s
+WHERE s.deleted_at IS NULL
+  AND s.quantity <> 0
+  AND NOT EXISTS (
+    SELECT 1 FROM movements m
+    WHERE m.item_id = s.item_id AND m.warehouse_id = s.warehouse_id AND m.quantity_change <> 0
+  )
+ON CONFLICT (idempotency_key) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,77 @@
+-- ============================================================================
+-- GalaxyERP 库存调拨单迁移 - PostgreSQL 脚本
+-- 说明: 调拨单改为多行单据，发货时转入在途虚拟仓，收货时转入调入仓；
+--       支持分批收货与短收、溢收差异处理
+-- ============================================================================
+
+BEGIN;
+
+-- warehouses: 在途虚拟仓标记
+ALTER TABLE IF EXISTS warehouses
+  ADD COLUMN IF NOT EXISTS is_transit BOOLEAN DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_warehouses_is_transit ON warehouses (is_transit);
+
+-- stock_transfers: 单据头字段
+ALTER TABLE IF EXISTS stock_transfers
+  ADD COLUMN IF NOT EXISTS created_by INTEGER NULL,
+  ADD COLUMN IF NOT EXISTS updated_by INTEGER NULL,
+  ADD COLUMN IF NOT EXISTS transfer_number VARCHAR(100) NULL,
+  ADD COLUMN IF NOT EXISTS from_location_id INTEGER NULL,
+  ADD COLUMN IF NOT EXISTS to_location_id INTEGER NULL,
+  ADD COLUMN IF NOT EXISTS transit_warehouse_id INTEGER NULL,
+  ADD COLUMN IF NOT EXISTS transfer_date TIMESTAMP WITH TIME ZONE NULL,
+  ADD COLUMN IF NOT EXISTS shipped_at TIMESTAMP WITH TIME ZONE NULL,
+  ADD COLUMN IF NOT EXISTS received_at TIMESTAMP WITH TIME ZONE NULL,
+  ADD COLUMN IF NOT EXISTS status VARCHAR(50) DEFAULT 'draft',
+  ADD COLUMN IF NOT EXISTS has_discrepancy BOOLEAN DEFAULT FALSE;
+
+-- stock_transfer_items: 调拨明细
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  transfer_id INTEGER NOT NULL,
+  item_id INTEGER NOT NULL,
+  from_location_id INTEGER NULL,
+  to_location_id INTEGER NULL,
+  quantity NUMERIC NOT NULL,
+  shipped_qty NUMERIC DEFAULT 0,
+  received_qty NUMERIC DEFAULT 0,
+  discrepancy_qty NUMERIC DEFAULT 0,
+  discrepancy_resolution VARCHAR(50) NULL,
+  discrepancy_reason VARCHAR(255) NULL,
+  notes TEXT NULL,
+  CONSTRAINT fk_stock_transfer_items_transfer FOREIGN KEY (transfer_id) REFERENCES stock_transfers(id)
+);
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_items_deleted_at ON stock_transfer_items (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_items_transfer_id ON stock_transfer_items (transfer_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_items_item_id ON stock_transfer_items (item_id);
+
+-- 历史单行调拨从未影响库存，转为草稿调拨单及一条明细
+INSERT INTO stock_transfer_items (created_at, updated_at, transfer_id, item_id, quantity, notes)
+SELECT t.created_at, t.updated_at, t.id, t.item_id, t.quantity, t.notes
+FROM stock_transfers t
+WHERE t.item_id IS NOT NULL
+  AND t.quantity IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM stock_transfer_items i WHERE i.transfer_id = t.id);
+
+UPDATE stock_transfers
+SET transfer_number = COALESCE(transfer_number, 'ST-LEGACY-' || LPAD(id::TEXT, 6, '0')),
+    transfer_date = COALESCE(transfer_date, created_at, CURRENT_TIMESTAMP),
+    status = COALESCE(status, 'draft');
+
+ALTER TABLE stock_transfers
+  ALTER COLUMN transfer_number SET NOT NULL,
+  ALTER COLUMN transfer_date SET NOT NULL,
+  ALTER COLUMN from_warehouse_id SET NOT NULL,
+  ALTER COLUMN to_warehouse_id SET NOT NULL,
+  DROP COLUMN IF EXISTS item_id,
+  DROP COLUMN IF EXISTS quantity;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_transfers_transfer_number ON stock_transfers (transfer_number);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_from_warehouse_id ON stock_transfers (from_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_to_warehouse_id ON stock_transfers (to_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_transfer_date ON stock_transfers (transfer_date);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_status ON stock_transfers (status);
+
+COMMIT;
@@ -0,0 +1,74 @@
+-- ============================================================================
+-- GalaxyERP 存货计价迁移 - PostgreSQL 脚本
+-- 说明: 物料支持先进先出、移动加权平均、标准成本三种计价方法；
+--       库存台账记录每笔移动的金额变动，库存表记录库存金额与单位成本
+-- ============================================================================
+
+BEGIN;
+
+-- items: 计价方法，Cost 作为标准成本及无成本入库的默认成本
+ALTER TABLE IF EXISTS items
+  ADD COLUMN IF NOT EXISTS valuation_method VARCHAR(20) DEFAULT 'moving_average';
+UPDATE items SET valuation_method = 'moving_average' WHERE valuation_method IS NULL OR valuation_method = '';
+
+-- stocks: 库存金额与单位成本
+ALTER TABLE IF EXISTS stocks
+  ADD COLUMN IF NOT EXISTS stock_value NUMERIC(20,4) DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS valuation_rate NUMERIC(20,4) DEFAULT 0;
+
+-- movements: 金额变动与过账后库存金额
+ALTER TABLE IF EXISTS movements
+  ADD COLUMN IF NOT EXISTS value_change NUMERIC(20,4) DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS value_after NUMERIC(20,4) DEFAULT 0;
+
+-- stock_cost_layers: 先进先出成本层
+CREATE TABLE IF NOT EXISTS stock_cost_layers (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  item_id INTEGER NOT NULL,
+  warehouse_id INTEGER NOT NULL,
+  movement_id INTEGER NOT NULL,
+  quantity NUMERIC NOT NULL,
+  remaining_qty NUMERIC NOT NULL,
+  unit_cost NUMERIC(20,4) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_stock_cost_layers_deleted_at ON stock_cost_layers (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_stock_cost_layers_item_warehouse ON stock_cost_layers (item_id, warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_cost_layers_movement_id ON stock_cost_layers (movement_id);
+
+-- 历史台账按物料成本补记金额，使库存金额等于台账金额变动之和
+UPDATE movements m
+SET value_change = ROUND((m.quantity_change * i.cost)::NUMERIC, 4),
+    unit_cost = i.cost,
+    total_cost = ROUND((ABS(m.quantity_change) * i.cost)::NUMERIC, 4)
+FROM items i
+WHERE i.id = m.item_id
+  AND m.value_change = 0
+  AND m.quantity_change <> 0;
+
+UPDATE movements m
+SET value_after = running.value_after
+FROM (
+  SELECT id, SUM(value_change) OVER (PARTITION BY item_id, warehouse_id ORDER BY id) AS value_after
+  FROM movements
+  WHERE deleted_at IS NULL
+) running
+WHERE running.id = m.id;
+
+UPDATE stocks s
+SET stock_value = COALESCE((
+  SELECT SUM(m.value_change)
+  FROM movements m
+  WHERE m.deleted_at IS NULL
+    AND m.item_id = s.item_id
+    AND m.warehouse_id = s.warehouse_id
+), 0);
+
+UPDATE stocks s
+SET valuation_rate = CASE WHEN s.quantity <> 0 THEN ROUND(s.stock_value / s.quantity::NUMERIC, 4) ELSE i.cost END
+FROM items i
+WHERE i.id = s.item_id;
+
+COMMIT;
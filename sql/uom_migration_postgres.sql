@@ -0,0 +1,77 @@
+-- ============================================================================
+-- GalaxyERP 计量单位迁移 - PostgreSQL 脚本
+-- 说明: 计量单位按类别维护基准系数、小数位数与舍入方式，物料可定义单位换算；
+--       库存移动、调拨、采购申请、发货单与销售发票行记录单据单位与换算系数，库存按库存单位过账
+-- ============================================================================
+
+BEGIN;
+
+-- uom_categories: 单位类别
+CREATE TABLE IF NOT EXISTS uom_categories (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  code VARCHAR(50) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  description TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_uom_categories_deleted_at ON uom_categories (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_uom_categories_code ON uom_categories (code);
+
+-- uoms: 计量单位
+CREATE TABLE IF NOT EXISTS uoms (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  code VARCHAR(50) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  category_id INTEGER NOT NULL,
+  base_factor DOUBLE PRECISION DEFAULT 1,
+  precision BIGINT DEFAULT 0,
+  rounding VARCHAR(20) DEFAULT 'half_up',
+  description TEXT NULL,
+  is_active BOOLEAN DEFAULT TRUE
+);
+CREATE INDEX IF NOT EXISTS idx_uoms_deleted_at ON uoms (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_uoms_code ON uoms (code);
+CREATE INDEX IF NOT EXISTS idx_uoms_category_id ON uoms (category_id);
+
+-- item_uom_conversions: 物料单位换算
+CREATE TABLE IF NOT EXISTS item_uom_conversions (
+  id SERIAL PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE NULL,
+  item_id INTEGER NOT NULL,
+  uom_id INTEGER NOT NULL,
+  factor DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_item_uom_conversions_deleted_at ON item_uom_conversions (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_uom_conversions_item_uom ON item_uom_conversions (item_id, uom_id);
+
+-- movements: 单据单位与换算系数
+ALTER TABLE IF EXISTS movements
+  ADD COLUMN IF NOT EXISTS uom VARCHAR(50) NULL,
+  ADD COLUMN IF NOT EXISTS uom_quantity DOUBLE PRECISION DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS conversion_factor DOUBLE PRECISION DEFAULT 1;
+
+-- stock_transfer_items: 单据单位与换算系数
+ALTER TABLE IF EXISTS stock_transfer_items
+  ADD COLUMN IF NOT EXISTS uom VARCHAR(50) NULL,
+  ADD COLUMN IF NOT EXISTS uom_quantity DOUBLE PRECISION DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS conversion_factor DOUBLE PRECISION DEFAULT 1;
+
+-- purchase_request_items: 换算系数
+ALTER TABLE IF EXISTS purchase_request_items
+  ADD COLUMN IF NOT EXISTS conversion_factor DOUBLE PRECISION DEFAULT 1;
+
+-- delivery_note_items: 发货单位、换算系数与库存单位数量，历史明细按发货数量回填
+ALTER TABLE IF EXISTS delivery_note_items
+  ADD COLUMN IF NOT EXISTS uom VARCHAR(50) NULL,
+  ADD COLUMN IF NOT EXISTS conversion_factor DOUBLE PRECISION DEFAULT 1,
+  ADD COLUMN IF NOT EXISTS stock_qty DOUBLE PRECISION NULL;
+UPDATE delivery_note_items SET stock_qty = quantity WHERE stock_qty IS NULL;
+
+COMMIT;